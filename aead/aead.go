@@ -0,0 +1,91 @@
+package aead
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+type AEAD = cipher.AEAD
+
+// Constructor builds a complete algorithm, such as aes-gcm, from a key.
+type Constructor func(key []byte) (AEAD, error)
+
+// ModeConstructor builds a mode, such as gcm, on top of any block cipher.
+type ModeConstructor func(ctx context.Context, block cipher.BlockCipher, key []byte) (AEAD, error)
+
+// A name in the registry can carry either kind of constructor, or both.
+type registration struct {
+	constructor Constructor
+	mode        ModeConstructor
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]registration)
+)
+
+func Register(name string, constructor Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	r := registry[name]
+	r.constructor = constructor
+	registry[name] = r
+}
+
+func RegisterMode(name string, mode ModeConstructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	r := registry[name]
+	r.mode = mode
+	registry[name] = r
+}
+
+func New(name string, key []byte) (AEAD, error) {
+	registryMu.RLock()
+	constructor := registry[name].constructor
+	registryMu.RUnlock()
+
+	if constructor == nil {
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", name)
+	}
+	return constructor(key)
+}
+
+func NewMode(ctx context.Context, name string, block cipher.BlockCipher, key []byte) (AEAD, error) {
+	registryMu.RLock()
+	mode := registry[name].mode
+	registryMu.RUnlock()
+
+	if mode == nil {
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", name)
+	}
+	return mode(ctx, block, key)
+}
+
+// Names lists the algorithms New accepts.
+func Names() []string {
+	return names(func(r registration) bool { return r.constructor != nil })
+}
+
+// Modes lists the modes NewMode accepts.
+func Modes() []string {
+	return names(func(r registration) bool { return r.mode != nil })
+}
+
+func names(keep func(registration) bool) []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name, r := range registry {
+		if keep(r) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,63 @@
+package aead
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	assert.Contains(t, Names(), ChaCha20Poly1305Name)
+
+	_, err := New("rot13-poly", nil)
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+
+	_, err = New(ChaCha20Poly1305Name, make([]byte, 16))
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
+
+func TestRegisteredAEADs(t *testing.T) {
+	assert.Equal(t, []string{AESCCMName, AESEAXName, AESGCMName, AESOCBName, AESSIVName, ChaCha20Poly1305Name}, Names())
+
+	var _ AEAD = (*CCM)(nil)
+	var _ AEAD = (*EAX)(nil)
+	var _ AEAD = (*GCM)(nil)
+	var _ AEAD = (*OCB)(nil)
+	var _ AEAD = (*SIV)(nil)
+	var _ AEAD = (*ChaCha20Poly1305)(nil)
+	var _ AEAD = (*Committing)(nil)
+}
+
+func TestBlockCipherModes(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, []string{CCMModeName, EAXModeName, GCMModeName, OCBModeName}, Modes())
+
+	_, err := NewMode(ctx, "ocb9", des.NewDES(), nil)
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+	_, err = NewMode(ctx, AESGCMName, des.NewDES(), nil)
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+	_, err = New(GCMModeName, make([]byte, 16))
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+
+	_, err = NewMode(ctx, GCMModeName, des.NewDES(), []byte("8bytekey"))
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+
+	for _, name := range Modes() {
+		block, err := rijndael.NewRijndael(16, 16, 0x1B)
+		require.NoError(t, err)
+		a, err := NewMode(ctx, name, block, make([]byte, 16))
+		require.NoError(t, err)
+
+		nonce := make([]byte, a.NonceSize())
+		sealed, err := a.Seal(ctx, nonce, []byte("generic"), nil)
+		require.NoError(t, err)
+		opened, err := a.Open(ctx, nonce, sealed, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("generic"), opened)
+	}
+}
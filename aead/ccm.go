@@ -0,0 +1,211 @@
+package aead
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	AESCCMName  = "aes-ccm"
+	CCMModeName = "ccm"
+
+	ccmBlockSize        = 16
+	ccmDefaultNonceSize = 12
+	ccmDefaultTagSize   = 16
+)
+
+func init() {
+	Register(AESCCMName, func(key []byte) (AEAD, error) {
+		block, err := rijndael.NewRijndael(ccmBlockSize, len(key), 0x1B)
+		if err != nil {
+			return nil, err
+		}
+		return NewCCM(context.Background(), block, key, ccmDefaultNonceSize, ccmDefaultTagSize)
+	})
+	RegisterMode(CCMModeName, func(ctx context.Context, block cipher.BlockCipher, key []byte) (AEAD, error) {
+		return NewCCM(ctx, block, key, ccmDefaultNonceSize, ccmDefaultTagSize)
+	})
+}
+
+type CCM struct {
+	block     cipher.BlockCipher
+	nonceSize int
+	tagSize   int
+}
+
+func NewCCM(ctx context.Context, block cipher.BlockCipher, key []byte, nonceSize, tagSize int) (*CCM, error) {
+	if block.BlockSize() != ccmBlockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	if nonceSize < 7 || nonceSize > 13 {
+		return nil, errors.ErrInvalidNonceSize
+	}
+	if tagSize < 4 || tagSize > 16 || tagSize%2 != 0 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "tag size must be even and between 4 and 16: %w")
+	}
+	if err := block.SetKey(ctx, key); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+
+	return &CCM{block: block, nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+func (c *CCM) NonceSize() int {
+	return c.nonceSize
+}
+
+func (c *CCM) Overhead() int {
+	return c.tagSize
+}
+
+func (c *CCM) lengthSize() int {
+	return 15 - c.nonceSize
+}
+
+func (c *CCM) Seal(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != c.nonceSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+	if c.lengthSize() < 8 && uint64(len(plaintext)) >= 1<<(8*c.lengthSize()) {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	tag, err := c.mac(ctx, nonce, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := c.counterMode(ctx, nonce, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedTag, err := c.maskTag(ctx, nonce, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ciphertext, encryptedTag...), nil
+}
+
+func (c *CCM) Open(ctx context.Context, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != c.nonceSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+	if len(ciphertext) < c.tagSize {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	body := ciphertext[:len(ciphertext)-c.tagSize]
+	plaintext, err := c.counterMode(ctx, nonce, body)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := c.mac(ctx, nonce, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := c.maskTag(ctx, nonce, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare(expected, ciphertext[len(body):]) != 1 {
+		return nil, errors.ErrAuthenticationFailed
+	}
+	return plaintext, nil
+}
+
+func (c *CCM) mac(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	l := c.lengthSize()
+
+	b0 := make([]byte, ccmBlockSize)
+	b0[0] = byte(8*((c.tagSize-2)/2) + (l - 1))
+	if len(additionalData) > 0 {
+		b0[0] |= 0x40
+	}
+	copy(b0[1:], nonce)
+	putLength(b0[1+c.nonceSize:], uint64(len(plaintext)))
+
+	data := append([]byte{}, b0...)
+	if len(additionalData) > 0 {
+		data = append(data, encodeAADLength(len(additionalData))...)
+		data = append(data, additionalData...)
+		data = append(data, make([]byte, padding16(len(data)))...)
+	}
+	data = append(data, plaintext...)
+	data = append(data, make([]byte, padding16(len(data)))...)
+
+	state := make([]byte, ccmBlockSize)
+	for i := 0; i < len(data); i += ccmBlockSize {
+		for j := range state {
+			state[j] ^= data[i+j]
+		}
+		encrypted, err := c.block.Encrypt(ctx, state)
+		if err != nil {
+			return nil, err
+		}
+		state = encrypted
+	}
+
+	return state[:c.tagSize], nil
+}
+
+func (c *CCM) counterBlock(nonce []byte, counter uint64) []byte {
+	block := make([]byte, ccmBlockSize)
+	block[0] = byte(c.lengthSize() - 1)
+	copy(block[1:], nonce)
+	putLength(block[1+c.nonceSize:], counter)
+	return block
+}
+
+func (c *CCM) counterMode(ctx context.Context, nonce, data []byte) ([]byte, error) {
+	result := make([]byte, len(data))
+	for i := 0; i < len(data); i += ccmBlockSize {
+		keystream, err := c.block.Encrypt(ctx, c.counterBlock(nonce, uint64(i/ccmBlockSize)+1))
+		if err != nil {
+			return nil, err
+		}
+		for j := i; j < min(i+ccmBlockSize, len(data)); j++ {
+			result[j] = data[j] ^ keystream[j-i]
+		}
+	}
+	return result, nil
+}
+
+func (c *CCM) maskTag(ctx context.Context, nonce, tag []byte) ([]byte, error) {
+	s0, err := c.block.Encrypt(ctx, c.counterBlock(nonce, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	masked := make([]byte, len(tag))
+	for i := range tag {
+		masked[i] = tag[i] ^ s0[i]
+	}
+	return masked, nil
+}
+
+func putLength(dst []byte, length uint64) {
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = byte(length)
+		length >>= 8
+	}
+}
+
+func encodeAADLength(length int) []byte {
+	switch {
+	case length < 0xFF00:
+		return binary.BigEndian.AppendUint16(nil, uint16(length))
+	case uint64(length) <= 0xFFFFFFFF:
+		return binary.BigEndian.AppendUint32([]byte{0xFF, 0xFE}, uint32(length))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{0xFF, 0xFF}, uint64(length))
+	}
+}
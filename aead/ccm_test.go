@@ -0,0 +1,77 @@
+package aead
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAESCCM(t *testing.T, key []byte, nonceSize, tagSize int) *CCM {
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	c, err := NewCCM(context.Background(), block, key, nonceSize, tagSize)
+	require.NoError(t, err)
+	return c
+}
+
+func TestCCMRFC3610Vector(t *testing.T) {
+	ctx := context.Background()
+	key, _ := hex.DecodeString("c0c1c2c3c4c5c6c7c8c9cacbcccdcecf")
+	nonce, _ := hex.DecodeString("00000003020100a0a1a2a3a4a5")
+	aad, _ := hex.DecodeString("0001020304050607")
+	plaintext, _ := hex.DecodeString("08090a0b0c0d0e0f101112131415161718191a1b1c1d1e")
+
+	c := newAESCCM(t, key, 13, 8)
+	sealed, err := c.Seal(ctx, nonce, plaintext, aad)
+	require.NoError(t, err)
+	assert.Equal(t, "588c979a61c663d2f066d0c2c0f989806d5f6b61dac38417e8d12cfdf926e0", hex.EncodeToString(sealed))
+
+	opened, err := c.Open(ctx, nonce, sealed, aad)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+}
+
+func TestCCMTampering(t *testing.T) {
+	ctx := context.Background()
+	a, err := New(AESCCMName, []byte("0123456789abcdef"))
+	require.NoError(t, err)
+	assert.Equal(t, 12, a.NonceSize())
+	assert.Equal(t, 16, a.Overhead())
+
+	nonce := make([]byte, 12)
+	sealed, err := a.Seal(ctx, nonce, []byte("constrained device reading"), nil)
+	require.NoError(t, err)
+
+	opened, err := a.Open(ctx, nonce, sealed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("constrained device reading"), opened)
+
+	sealed[len(sealed)-1] ^= 1
+	_, err = a.Open(ctx, nonce, sealed, nil)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+}
+
+func TestCCMParameters(t *testing.T) {
+	ctx := context.Background()
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	key := make([]byte, 16)
+
+	_, err = NewCCM(ctx, block, key, 6, 16)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+	_, err = NewCCM(ctx, block, key, 12, 5)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	c := newAESCCM(t, key, 13, 4)
+	_, err = c.Seal(ctx, make([]byte, 12), nil, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+
+	long := make([]byte, 1<<16)
+	_, err = c.Seal(ctx, make([]byte, 13), long, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
@@ -0,0 +1,100 @@
+package aead
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/cipher/chacha20"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/mac/poly1305"
+)
+
+const ChaCha20Poly1305Name = "chacha20-poly1305"
+
+func init() {
+	Register(ChaCha20Poly1305Name, func(key []byte) (AEAD, error) {
+		return NewChaCha20Poly1305(key)
+	})
+}
+
+type ChaCha20Poly1305 struct {
+	key []byte
+}
+
+func NewChaCha20Poly1305(key []byte) (*ChaCha20Poly1305, error) {
+	if len(key) != chacha20.KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	return &ChaCha20Poly1305{key: append([]byte{}, key...)}, nil
+}
+
+func (c *ChaCha20Poly1305) NonceSize() int {
+	return chacha20.NonceSize
+}
+
+func (c *ChaCha20Poly1305) Overhead() int {
+	return poly1305.TagSize
+}
+
+func (c *ChaCha20Poly1305) Seal(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	stream, macKey, err := c.setup(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, len(plaintext), len(plaintext)+poly1305.TagSize)
+	if err := stream.XORKeyStream(ciphertext, plaintext); err != nil {
+		return nil, err
+	}
+
+	tag, err := poly1305.Sum(macKey, macData(additionalData, ciphertext))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ciphertext, tag...), nil
+}
+
+func (c *ChaCha20Poly1305) Open(ctx context.Context, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < poly1305.TagSize {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	stream, macKey, err := c.setup(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	body := ciphertext[:len(ciphertext)-poly1305.TagSize]
+	tag := ciphertext[len(body):]
+	if err := poly1305.Verify(macKey, macData(additionalData, body), tag); err != nil {
+		return nil, errors.ErrAuthenticationFailed
+	}
+
+	plaintext := make([]byte, len(body))
+	if err := stream.XORKeyStream(plaintext, body); err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+func (c *ChaCha20Poly1305) setup(nonce []byte) (*chacha20.ChaCha20, []byte, error) {
+	stream, err := chacha20.New(c.key, nonce, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stream, stream.Block(0)[:poly1305.KeySize], nil
+}
+
+func macData(additionalData, ciphertext []byte) []byte {
+	data := append([]byte{}, additionalData...)
+	data = append(data, make([]byte, padding16(len(data)))...)
+	data = append(data, ciphertext...)
+	data = append(data, make([]byte, padding16(len(ciphertext)))...)
+	data = binary.LittleEndian.AppendUint64(data, uint64(len(additionalData)))
+	return binary.LittleEndian.AppendUint64(data, uint64(len(ciphertext)))
+}
+
+func padding16(n int) int {
+	return (16 - n%16) % 16
+}
@@ -0,0 +1,56 @@
+package aead
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rfcVector(t *testing.T) (AEAD, []byte, []byte, []byte) {
+	key, _ := hex.DecodeString("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	nonce, _ := hex.DecodeString("070000004041424344454647")
+	aad, _ := hex.DecodeString("50515253c0c1c2c3c4c5c6c7")
+
+	a, err := New(ChaCha20Poly1305Name, key)
+	require.NoError(t, err)
+	return a, nonce, aad, []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+}
+
+func TestChaCha20Poly1305Vector(t *testing.T) {
+	ctx := context.Background()
+	a, nonce, aad, plaintext := rfcVector(t)
+
+	sealed, err := a.Seal(ctx, nonce, plaintext, aad)
+	require.NoError(t, err)
+	require.Len(t, sealed, len(plaintext)+a.Overhead())
+
+	assert.Equal(t, "d31a8d34648e60db7b86afbc53ef7ec2", hex.EncodeToString(sealed[:16]))
+	assert.Equal(t, "1ae10b594f09e26a7e902ecbd0600691", hex.EncodeToString(sealed[len(plaintext):]))
+
+	opened, err := a.Open(ctx, nonce, sealed, aad)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+}
+
+func TestChaCha20Poly1305Tampering(t *testing.T) {
+	ctx := context.Background()
+	a, nonce, aad, plaintext := rfcVector(t)
+
+	sealed, err := a.Seal(ctx, nonce, plaintext, aad)
+	require.NoError(t, err)
+
+	tampered := append([]byte{}, sealed...)
+	tampered[0] ^= 1
+	_, err = a.Open(ctx, nonce, tampered, aad)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = a.Open(ctx, nonce, sealed, []byte("other aad"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = a.Open(ctx, nonce, sealed[:8], aad)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
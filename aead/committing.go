@@ -0,0 +1,69 @@
+package aead
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/kdf/hkdf"
+	"github.com/masterkusok/crypto/mac/hmac"
+)
+
+const commitmentSize = sha256.Size
+
+type Committing struct {
+	inner     AEAD
+	commitKey []byte
+}
+
+func NewCommitting(name string, key []byte) (*Committing, error) {
+	if len(key) == 0 {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	encryptionKey, err := hkdf.Key(sha256.New, key, nil, []byte("aead encryption key"), len(key))
+	if err != nil {
+		return nil, err
+	}
+	commitKey, err := hkdf.Key(sha256.New, key, nil, []byte("aead commitment key"), commitmentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	inner, err := New(name, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Committing{inner: inner, commitKey: commitKey}, nil
+}
+
+func (c *Committing) NonceSize() int {
+	return c.inner.NonceSize()
+}
+
+func (c *Committing) Overhead() int {
+	return commitmentSize + c.inner.Overhead()
+}
+
+func (c *Committing) Seal(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	sealed, err := c.inner.Seal(ctx, nonce, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return append(c.commitment(nonce), sealed...), nil
+}
+
+func (c *Committing) Open(ctx context.Context, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < c.Overhead() {
+		return nil, errors.ErrInvalidDataLength
+	}
+	if subtle.ConstantTimeCompare(c.commitment(nonce), ciphertext[:commitmentSize]) != 1 {
+		return nil, errors.ErrAuthenticationFailed
+	}
+	return c.inner.Open(ctx, nonce, ciphertext[commitmentSize:], additionalData)
+}
+
+func (c *Committing) commitment(nonce []byte) []byte {
+	return hmac.Sum(sha256.New, c.commitKey, nonce)
+}
@@ -0,0 +1,55 @@
+package aead
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommittingRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	plaintext := []byte("committed to exactly one key")
+	aad := []byte("header")
+
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			key := make([]byte, 32)
+			for i := range key {
+				key[i] = byte(i)
+			}
+
+			c, err := NewCommitting(name, key)
+			require.NoError(t, err)
+			nonce := make([]byte, c.NonceSize())
+
+			sealed, err := c.Seal(ctx, nonce, plaintext, aad)
+			require.NoError(t, err)
+			assert.Len(t, sealed, len(plaintext)+c.Overhead())
+
+			opened, err := c.Open(ctx, nonce, sealed, aad)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, opened)
+
+			key[0] ^= 1
+			other, err := NewCommitting(name, key)
+			require.NoError(t, err)
+			_, err = other.Open(ctx, nonce, sealed, aad)
+			assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+			sealed[0] ^= 1
+			_, err = c.Open(ctx, nonce, sealed, aad)
+			assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+			_, err = c.Open(ctx, nonce, sealed[:c.Overhead()-1], aad)
+			assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+		})
+	}
+}
+
+func TestCommittingUnknownAlgorithm(t *testing.T) {
+	_, err := NewCommitting("rot13", make([]byte, 16))
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+}
@@ -0,0 +1,156 @@
+package aead
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/mac"
+)
+
+const (
+	AESEAXName  = "aes-eax"
+	EAXModeName = "eax"
+
+	eaxBlockSize = 16
+	eaxNonceSize = 16
+)
+
+func init() {
+	Register(AESEAXName, func(key []byte) (AEAD, error) {
+		block, err := rijndael.NewRijndael(eaxBlockSize, len(key), 0x1B)
+		if err != nil {
+			return nil, err
+		}
+		return NewEAX(context.Background(), block, key)
+	})
+	RegisterMode(EAXModeName, func(ctx context.Context, block cipher.BlockCipher, key []byte) (AEAD, error) {
+		return NewEAX(ctx, block, key)
+	})
+}
+
+type EAX struct {
+	block cipher.BlockCipher
+	cmac  *mac.CMAC
+}
+
+func NewEAX(ctx context.Context, block cipher.BlockCipher, key []byte) (*EAX, error) {
+	cmac, err := mac.NewCMAC(ctx, block, key)
+	if err != nil {
+		return nil, err
+	}
+	return &EAX{block: block, cmac: cmac}, nil
+}
+
+func (e *EAX) NonceSize() int {
+	return eaxNonceSize
+}
+
+func (e *EAX) Overhead() int {
+	return e.block.BlockSize()
+}
+
+func (e *EAX) Seal(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	n, h, err := e.header(ctx, nonce, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := e.counterMode(ctx, n, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := e.tag(ctx, n, h, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return append(ciphertext, tag...), nil
+}
+
+func (e *EAX) Open(ctx context.Context, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < e.Overhead() {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	n, h, err := e.header(ctx, nonce, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	body := ciphertext[:len(ciphertext)-e.Overhead()]
+	expected, err := e.tag(ctx, n, h, body)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(expected, ciphertext[len(body):]) != 1 {
+		return nil, errors.ErrAuthenticationFailed
+	}
+
+	return e.counterMode(ctx, n, body)
+}
+
+func (e *EAX) header(ctx context.Context, nonce, additionalData []byte) ([]byte, []byte, error) {
+	if len(nonce) == 0 {
+		return nil, nil, errors.ErrInvalidNonceSize
+	}
+
+	n, err := e.omac(ctx, 0, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := e.omac(ctx, 1, additionalData)
+	if err != nil {
+		return nil, nil, err
+	}
+	return n, h, nil
+}
+
+func (e *EAX) tag(ctx context.Context, n, h, ciphertext []byte) ([]byte, error) {
+	c, err := e.omac(ctx, 2, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := make([]byte, len(n))
+	for i := range tag {
+		tag[i] = n[i] ^ h[i] ^ c[i]
+	}
+	return tag, nil
+}
+
+func (e *EAX) omac(ctx context.Context, domain byte, data []byte) ([]byte, error) {
+	size := e.block.BlockSize()
+	message := make([]byte, size, size+len(data))
+	message[size-1] = domain
+	return e.cmac.Sum(ctx, append(message, data...))
+}
+
+func (e *EAX) counterMode(ctx context.Context, n, data []byte) ([]byte, error) {
+	return counterMode(ctx, e.block, n, data)
+}
+
+func counterMode(ctx context.Context, block cipher.BlockCipher, iv, data []byte) ([]byte, error) {
+	size := block.BlockSize()
+	counter := append([]byte{}, iv...)
+	result := make([]byte, len(data))
+
+	for i := 0; i < len(data); i += size {
+		keystream, err := block.Encrypt(ctx, counter)
+		if err != nil {
+			return nil, err
+		}
+		for j := i; j < min(i+size, len(data)); j++ {
+			result[j] = data[j] ^ keystream[j-i]
+		}
+		for j := size - 1; j >= 0; j-- {
+			counter[j]++
+			if counter[j] != 0 {
+				break
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package aead
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEAXVectors(t *testing.T) {
+	ctx := context.Background()
+	vectors := []struct {
+		key, nonce, header, message, sealed string
+	}{
+		{
+			"233952dee4d5ed5f9b9c6d6ff80ff478", "62ec67f9c3a4a407fcb2a8c49031a8b3", "6bfb914fd07eae6b",
+			"", "e037830e8389f27b025a2d6527e79d01",
+		},
+		{
+			"91945d3f4dcbee0bf45ef52255f095a4", "becaf043b0a23d843194ba972c66debd", "fa3bfd4806eb53fa",
+			"f7fb", "19dd5c4c9331049d0bdab0277408f67967e5",
+		},
+	}
+
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.key)
+		nonce, _ := hex.DecodeString(v.nonce)
+		header, _ := hex.DecodeString(v.header)
+		message, _ := hex.DecodeString(v.message)
+
+		block, err := rijndael.NewRijndael(16, 16, 0x1B)
+		require.NoError(t, err)
+		e, err := NewEAX(ctx, block, key)
+		require.NoError(t, err)
+
+		sealed, err := e.Seal(ctx, nonce, message, header)
+		require.NoError(t, err)
+		assert.Equal(t, v.sealed, hex.EncodeToString(sealed))
+
+		opened, err := e.Open(ctx, nonce, sealed, header)
+		require.NoError(t, err)
+		assert.Equal(t, message, opened)
+	}
+}
+
+func TestEAXWithDES(t *testing.T) {
+	ctx := context.Background()
+	e, err := NewEAX(ctx, des.NewDES(), []byte("8bytekey"))
+	require.NoError(t, err)
+	assert.Equal(t, 8, e.Overhead())
+
+	nonce := []byte("any length nonce")
+	plaintext := []byte("spans several 64-bit blocks of data")
+	sealed, err := e.Seal(ctx, nonce, plaintext, []byte("ad"))
+	require.NoError(t, err)
+
+	opened, err := e.Open(ctx, nonce, sealed, []byte("ad"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	sealed[0] ^= 1
+	_, err = e.Open(ctx, nonce, sealed, []byte("ad"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+}
@@ -0,0 +1,87 @@
+package aead
+
+import (
+	"context"
+	"encoding/binary"
+	"hash"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/mac/hmac"
+)
+
+// EncryptThenMAC turns a cipher context into an AEAD: the IV is the nonce
+// and an HMAC over the additional data, IV and ciphertext is appended. Open
+// checks the tag before decrypting, so a modified ciphertext never reaches
+// the padding check and the context cannot serve as a padding oracle.
+type EncryptThenMAC struct {
+	c         *cipher.CipherContext
+	blockSize int
+	newHash   func() hash.Hash
+	macKey    []byte
+	tagSize   int
+}
+
+func NewEncryptThenMAC(block cipher.BlockCipher, mode cipher.CipherMode, padding cipher.PaddingScheme, encryptionKey []byte, newHash func() hash.Hash, macKey []byte) (*EncryptThenMAC, error) {
+	if len(macKey) == 0 {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	c, err := cipher.NewCipherContext(block, encryptionKey, mode, padding)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptThenMAC{
+		c:         c,
+		blockSize: block.BlockSize(),
+		newHash:   newHash,
+		macKey:    append([]byte{}, macKey...),
+		tagSize:   newHash().Size(),
+	}, nil
+}
+
+func (e *EncryptThenMAC) NonceSize() int {
+	return e.blockSize
+}
+
+// Overhead is the tag size; padding may add up to a block on top of it.
+func (e *EncryptThenMAC) Overhead() int {
+	return e.tagSize
+}
+
+func (e *EncryptThenMAC) Seal(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != e.blockSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+
+	ciphertext, err := e.c.EncryptWithIV(ctx, plaintext, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return append(ciphertext, hmac.Sum(e.newHash, e.macKey, e.macInput(nonce, ciphertext, additionalData))...), nil
+}
+
+func (e *EncryptThenMAC) Open(ctx context.Context, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != e.blockSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+	if len(ciphertext) < e.tagSize {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	body := ciphertext[:len(ciphertext)-e.tagSize]
+	if err := hmac.Verify(e.newHash, e.macKey, e.macInput(nonce, body, additionalData), ciphertext[len(body):]); err != nil {
+		return nil, errors.ErrAuthenticationFailed
+	}
+
+	return e.c.DecryptWithIV(ctx, body, nonce)
+}
+
+// macInput length-prefixes the additional data so that it cannot be
+// shifted into the IV.
+func (e *EncryptThenMAC) macInput(nonce, ciphertext, additionalData []byte) []byte {
+	input := binary.BigEndian.AppendUint64(nil, uint64(len(additionalData)))
+	input = append(input, additionalData...)
+	input = append(input, nonce...)
+	return append(input, ciphertext...)
+}
@@ -0,0 +1,44 @@
+package aead
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptThenMAC(t *testing.T) {
+	ctx := context.Background()
+	e, err := NewEncryptThenMAC(des.NewDES(), &cipher.CBCMode{}, cipher.PKCS7, []byte("8bytekey"), sha256.New, []byte("mac key"))
+	require.NoError(t, err)
+	var _ AEAD = e
+
+	nonce := []byte("iv iv iv")
+	plaintext := []byte("authenticated CBC")
+	sealed, err := e.Seal(ctx, nonce, plaintext, []byte("header"))
+	require.NoError(t, err)
+	assert.Len(t, sealed, 24+e.Overhead())
+
+	opened, err := e.Open(ctx, nonce, sealed, []byte("header"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	_, err = e.Open(ctx, []byte("IV iv iv"), sealed, []byte("header"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+	_, err = e.Open(ctx, nonce, sealed, []byte("Header"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	sealed[len(sealed)-e.Overhead()-1] ^= 0x01
+	_, err = e.Open(ctx, nonce, sealed, []byte("header"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = e.Seal(ctx, nonce[:4], plaintext, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+	_, err = NewEncryptThenMAC(des.NewDES(), &cipher.CBCMode{}, cipher.PKCS7, []byte("8bytekey"), sha256.New, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
@@ -0,0 +1,185 @@
+package aead
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+const (
+	AESGCMName  = "aes-gcm"
+	GCMModeName = "gcm"
+
+	gcmBlockSize = 16
+	gcmNonceSize = 12
+	gcmTagSize   = 16
+)
+
+func init() {
+	Register(AESGCMName, func(key []byte) (AEAD, error) {
+		block, err := rijndael.NewRijndael(gcmBlockSize, len(key), 0x1B)
+		if err != nil {
+			return nil, err
+		}
+		return NewGCM(context.Background(), block, key)
+	})
+	RegisterMode(GCMModeName, func(ctx context.Context, block cipher.BlockCipher, key []byte) (AEAD, error) {
+		return NewGCM(ctx, block, key)
+	})
+}
+
+type GCM struct {
+	block cipher.BlockCipher
+	h     [2]uint64
+}
+
+func NewGCM(ctx context.Context, block cipher.BlockCipher, key []byte) (*GCM, error) {
+	if block.BlockSize() != gcmBlockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	if err := block.SetKey(ctx, key); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+
+	h, err := block.Encrypt(ctx, make([]byte, gcmBlockSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GCM{block: block, h: toElement(h)}, nil
+}
+
+func (g *GCM) NonceSize() int {
+	return gcmNonceSize
+}
+
+func (g *GCM) Overhead() int {
+	return gcmTagSize
+}
+
+func (g *GCM) Seal(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	j0, err := g.initialCounter(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := g.counterMode(ctx, j0, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := g.tag(ctx, j0, additionalData, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ciphertext, tag...), nil
+}
+
+func (g *GCM) Open(ctx context.Context, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < gcmTagSize {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	j0, err := g.initialCounter(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	body := ciphertext[:len(ciphertext)-gcmTagSize]
+	expected, err := g.tag(ctx, j0, additionalData, body)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(expected, ciphertext[len(body):]) != 1 {
+		return nil, errors.ErrAuthenticationFailed
+	}
+
+	return g.counterMode(ctx, j0, body)
+}
+
+func (g *GCM) initialCounter(nonce []byte) ([]byte, error) {
+	if len(nonce) == 0 {
+		return nil, errors.ErrInvalidNonceSize
+	}
+
+	if len(nonce) == gcmNonceSize {
+		j0 := make([]byte, gcmBlockSize)
+		copy(j0, nonce)
+		j0[gcmBlockSize-1] = 1
+		return j0, nil
+	}
+
+	lengths := make([]byte, gcmBlockSize)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(nonce))*8)
+	return g.ghash(nonce, lengths), nil
+}
+
+func (g *GCM) counterMode(ctx context.Context, j0, data []byte) ([]byte, error) {
+	result := make([]byte, len(data))
+	counter := append([]byte{}, j0...)
+
+	for i := 0; i < len(data); i += gcmBlockSize {
+		increment32(counter)
+		keystream, err := g.block.Encrypt(ctx, counter)
+		if err != nil {
+			return nil, err
+		}
+		for j := i; j < min(i+gcmBlockSize, len(data)); j++ {
+			result[j] = data[j] ^ keystream[j-i]
+		}
+	}
+
+	return result, nil
+}
+
+func (g *GCM) tag(ctx context.Context, j0, additionalData, ciphertext []byte) ([]byte, error) {
+	lengths := make([]byte, gcmBlockSize)
+	binary.BigEndian.PutUint64(lengths, uint64(len(additionalData))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+
+	s := g.ghash(additionalData, ciphertext, lengths)
+	mask, err := g.block.Encrypt(ctx, j0)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range s {
+		s[i] ^= mask[i]
+	}
+	return s, nil
+}
+
+func (g *GCM) ghash(parts ...[]byte) []byte {
+	var y [2]uint64
+	for _, part := range parts {
+		for i := 0; i < len(part); i += gcmBlockSize {
+			block := make([]byte, gcmBlockSize)
+			copy(block, part[i:min(i+gcmBlockSize, len(part))])
+
+			x := toElement(block)
+			y[0] ^= x[0]
+			y[1] ^= x[1]
+			y = cryptoMath.GF128Mul(y, g.h)
+		}
+	}
+
+	out := make([]byte, gcmBlockSize)
+	binary.BigEndian.PutUint64(out, y[0])
+	binary.BigEndian.PutUint64(out[8:], y[1])
+	return out
+}
+
+func toElement(b []byte) [2]uint64 {
+	return [2]uint64{binary.BigEndian.Uint64(b), binary.BigEndian.Uint64(b[8:])}
+}
+
+func increment32(counter []byte) {
+	n := binary.BigEndian.Uint32(counter[len(counter)-4:])
+	binary.BigEndian.PutUint32(counter[len(counter)-4:], n+1)
+}
@@ -0,0 +1,58 @@
+package aead
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCMVectors(t *testing.T) {
+	ctx := context.Background()
+	a, err := New(AESGCMName, make([]byte, 16))
+	require.NoError(t, err)
+	nonce := make([]byte, 12)
+
+	empty, err := a.Seal(ctx, nonce, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "58e2fccefa7e3061367f1d57a4e7455a", hex.EncodeToString(empty))
+
+	sealed, err := a.Seal(ctx, nonce, make([]byte, 16), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "0388dace60b6a392f328c2b971b2fe78"+"ab6e47d42cec13bdf53a67b21257bddf", hex.EncodeToString(sealed))
+
+	opened, err := a.Open(ctx, nonce, sealed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 16), opened)
+}
+
+func TestGCMRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	a, err := New(AESGCMName, []byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	plaintext := []byte("an odd-length message that spans several blocks")
+	aad := []byte("header")
+
+	for _, nonce := range [][]byte{make([]byte, 12), []byte("a longer, non-standard nonce")} {
+		sealed, err := a.Seal(ctx, nonce, plaintext, aad)
+		require.NoError(t, err)
+
+		opened, err := a.Open(ctx, nonce, sealed, aad)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, opened)
+
+		sealed[3] ^= 0x40
+		_, err = a.Open(ctx, nonce, sealed, aad)
+		assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+	}
+}
+
+func TestGCMRejectsNarrowBlocks(t *testing.T) {
+	_, err := NewGCM(context.Background(), des.NewDES(), []byte("01234567"))
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
@@ -0,0 +1,225 @@
+package aead
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+// GCMAppender extends a GCM ciphertext in place. Every tag it publishes
+// covers the whole ciphertext so far but is masked for its own epoch: two
+// tags under one mask would differ by a known polynomial in H and give the
+// hash key away. Epoch 0 uses the GCM mask, so a file tagged only once
+// opens with Open; later epochs must be stored next to the tag and checked
+// with OpenAppended.
+type GCMAppender struct {
+	gcm    *GCM
+	nonce  []byte
+	j0     []byte
+	epoch  uint64
+	adLen  uint64
+	y      [2]uint64
+	tail   []byte
+	length uint64
+}
+
+func (g *GCM) NewAppender(nonce, additionalData []byte) (*GCMAppender, error) {
+	j0, err := g.initialCounter(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GCMAppender{
+		gcm:   g,
+		nonce: append([]byte{}, nonce...),
+		j0:    j0,
+		adLen: uint64(len(additionalData)),
+		y:     toElement(g.ghash(additionalData)),
+	}, nil
+}
+
+// ResumeAppender continues a file whose last published tag belongs to
+// epoch. The next tag is masked for epoch+1.
+func (g *GCM) ResumeAppender(ctx context.Context, nonce, additionalData []byte, length uint64, tail, tag []byte, epoch uint64) (*GCMAppender, error) {
+	if len(tag) != gcmTagSize {
+		return nil, errors.ErrInvalidMAC
+	}
+	if uint64(len(tail)) != length%gcmBlockSize {
+		return nil, errors.Annotate(errors.ErrInvalidDataLength, "tail must hold the last %d ciphertext bytes: %w", length%gcmBlockSize)
+	}
+
+	a, err := g.NewAppender(nonce, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	a.length = length
+	a.tail = append([]byte{}, tail...)
+	a.epoch = epoch + 1
+
+	mask, err := g.epochMask(ctx, a.nonce, a.j0, epoch)
+	if err != nil {
+		return nil, err
+	}
+	s := make([]byte, gcmTagSize)
+	for i := range s {
+		s[i] = tag[i] ^ mask[i]
+	}
+
+	hInv, err := cryptoMath.GF128Inv(g.h)
+	if err != nil {
+		return nil, err
+	}
+
+	y := cryptoMath.GF128Mul(toElement(s), hInv)
+	lengths := toElement(a.lengths())
+	y[0] ^= lengths[0]
+	y[1] ^= lengths[1]
+
+	if len(tail) > 0 {
+		y = cryptoMath.GF128Mul(y, hInv)
+		block := make([]byte, gcmBlockSize)
+		copy(block, tail)
+		x := toElement(block)
+		y[0] ^= x[0]
+		y[1] ^= x[1]
+	}
+
+	a.y = y
+	return a, nil
+}
+
+func (a *GCMAppender) Len() uint64 {
+	return a.length
+}
+
+func (a *GCMAppender) Append(ctx context.Context, plaintext []byte) ([]byte, error) {
+	result := make([]byte, len(plaintext))
+
+	for i := 0; i < len(plaintext); {
+		offset := int(a.length % gcmBlockSize)
+		keystream, err := a.keystream(ctx, a.length/gcmBlockSize)
+		if err != nil {
+			return nil, err
+		}
+
+		n := min(gcmBlockSize-offset, len(plaintext)-i)
+		for j := 0; j < n; j++ {
+			result[i+j] = plaintext[i+j] ^ keystream[offset+j]
+		}
+		a.tail = append(a.tail, result[i:i+n]...)
+		a.length += uint64(n)
+		i += n
+
+		if len(a.tail) == gcmBlockSize {
+			a.absorb(a.tail)
+			a.tail = a.tail[:0]
+		}
+	}
+
+	return result, nil
+}
+
+// Tag returns the tag of the ciphertext so far and the epoch it is masked
+// for, which must be stored with it. Every call starts a new epoch.
+func (a *GCMAppender) Tag(ctx context.Context) ([]byte, uint64, error) {
+	y := a.y
+	if len(a.tail) > 0 {
+		block := make([]byte, gcmBlockSize)
+		copy(block, a.tail)
+		x := toElement(block)
+		y[0] ^= x[0]
+		y[1] ^= x[1]
+		y = cryptoMath.GF128Mul(y, a.gcm.h)
+	}
+
+	lengths := toElement(a.lengths())
+	y[0] ^= lengths[0]
+	y[1] ^= lengths[1]
+	y = cryptoMath.GF128Mul(y, a.gcm.h)
+
+	epoch := a.epoch
+	mask, err := a.gcm.epochMask(ctx, a.nonce, a.j0, epoch)
+	if err != nil {
+		return nil, 0, err
+	}
+	a.epoch++
+
+	tag := make([]byte, gcmTagSize)
+	binary.BigEndian.PutUint64(tag, y[0])
+	binary.BigEndian.PutUint64(tag[8:], y[1])
+	for i := range tag {
+		tag[i] ^= mask[i]
+	}
+	return tag, epoch, nil
+}
+
+// OpenAppended is Open for a ciphertext whose tag was published by a
+// GCMAppender in the given epoch.
+func (g *GCM) OpenAppended(ctx context.Context, nonce, ciphertext, additionalData []byte, epoch uint64) ([]byte, error) {
+	if len(ciphertext) < gcmTagSize {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	j0, err := g.initialCounter(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	body := ciphertext[:len(ciphertext)-gcmTagSize]
+	lengths := make([]byte, gcmBlockSize)
+	binary.BigEndian.PutUint64(lengths, uint64(len(additionalData))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(body))*8)
+
+	expected := g.ghash(additionalData, body, lengths)
+	mask, err := g.epochMask(ctx, nonce, j0, epoch)
+	if err != nil {
+		return nil, err
+	}
+	for i := range expected {
+		expected[i] ^= mask[i]
+	}
+	if subtle.ConstantTimeCompare(expected, ciphertext[len(body):]) != 1 {
+		return nil, errors.ErrAuthenticationFailed
+	}
+
+	return g.counterMode(ctx, j0, body)
+}
+
+// epochMask encrypts J0 for epoch 0, as GCM does, and for later epochs a
+// counter block derived like the J0 of the nonce nonce || epoch || label.
+func (g *GCM) epochMask(ctx context.Context, nonce, j0 []byte, epoch uint64) ([]byte, error) {
+	if epoch == 0 {
+		return g.block.Encrypt(ctx, j0)
+	}
+
+	derived := binary.BigEndian.AppendUint64(append([]byte{}, nonce...), epoch)
+	counter, err := g.initialCounter(append(derived, "gcm-append-epoch"...))
+	if err != nil {
+		return nil, err
+	}
+	return g.block.Encrypt(ctx, counter)
+}
+
+func (a *GCMAppender) keystream(ctx context.Context, block uint64) ([]byte, error) {
+	counter := append([]byte{}, a.j0...)
+	n := binary.BigEndian.Uint32(counter[gcmBlockSize-4:])
+	binary.BigEndian.PutUint32(counter[gcmBlockSize-4:], n+uint32(block)+1)
+	return a.gcm.block.Encrypt(ctx, counter)
+}
+
+func (a *GCMAppender) absorb(block []byte) {
+	x := toElement(block)
+	a.y[0] ^= x[0]
+	a.y[1] ^= x[1]
+	a.y = cryptoMath.GF128Mul(a.y, a.gcm.h)
+}
+
+func (a *GCMAppender) lengths() []byte {
+	lengths := make([]byte, gcmBlockSize)
+	binary.BigEndian.PutUint64(lengths, a.adLen*8)
+	binary.BigEndian.PutUint64(lengths[8:], a.length*8)
+	return lengths
+}
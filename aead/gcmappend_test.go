@@ -0,0 +1,177 @@
+package aead
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGCM(t *testing.T) *GCM {
+	block, err := rijndael.NewRijndael(gcmBlockSize, 16, 0x1B)
+	require.NoError(t, err)
+	g, err := NewGCM(context.Background(), block, []byte("0123456789abcdef"))
+	require.NoError(t, err)
+	return g
+}
+
+func TestGCMAppenderMatchesSeal(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGCM(t)
+	nonce := []byte("log-file-001")
+	aad := []byte("audit.log")
+	entries := [][]byte{[]byte("first entry\n"), []byte("a second, longer entry that crosses blocks\n"), nil, []byte("x"), []byte("0123456789abcdef")}
+
+	a, err := g.NewAppender(nonce, aad)
+	require.NoError(t, err)
+
+	var plaintext, ciphertext []byte
+	for i, entry := range entries {
+		out, err := a.Append(ctx, entry)
+		require.NoError(t, err)
+		plaintext = append(plaintext, entry...)
+		ciphertext = append(ciphertext, out...)
+
+		tag, epoch, err := a.Tag(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i), epoch)
+		sealed, err := g.Seal(ctx, nonce, plaintext, aad)
+		require.NoError(t, err)
+		assert.Equal(t, sealed[:len(ciphertext)], ciphertext)
+
+		file := append(append([]byte{}, ciphertext...), tag...)
+		opened, err := g.OpenAppended(ctx, nonce, file, aad, epoch)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, opened)
+
+		// Only the first tag shares the GCM mask.
+		if epoch == 0 {
+			assert.Equal(t, sealed, file)
+		} else {
+			_, err = g.Open(ctx, nonce, file, aad)
+			assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+		}
+	}
+	assert.Equal(t, uint64(len(plaintext)), a.Len())
+}
+
+func TestGCMAppenderResume(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGCM(t)
+	nonce := make([]byte, gcmNonceSize)
+	aad := []byte("header")
+
+	for _, size := range []int{0, 5, 16, 37} {
+		existing := make([]byte, size)
+		for i := range existing {
+			existing[i] = byte(i)
+		}
+		sealed, err := g.Seal(ctx, nonce, existing, aad)
+		require.NoError(t, err)
+		body, tag := sealed[:size], sealed[size:]
+
+		tail := body[size/gcmBlockSize*gcmBlockSize:]
+		a, err := g.ResumeAppender(ctx, nonce, aad, uint64(size), tail, tag, 0)
+		require.NoError(t, err)
+
+		tagAgain, epoch, err := a.Tag(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), epoch)
+		_, err = g.OpenAppended(ctx, nonce, append(append([]byte{}, body...), tagAgain...), aad, epoch)
+		require.NoError(t, err)
+
+		extra := []byte("appended without re-reading the file")
+		out, err := a.Append(ctx, extra)
+		require.NoError(t, err)
+		newTag, epoch, err := a.Tag(ctx)
+		require.NoError(t, err)
+
+		file := append(append(append([]byte{}, body...), out...), newTag...)
+		opened, err := g.OpenAppended(ctx, nonce, file, aad, epoch)
+		require.NoError(t, err)
+		assert.Equal(t, append(existing, extra...), opened)
+
+		resumed, err := g.ResumeAppender(ctx, nonce, aad, uint64(len(file)-gcmTagSize), out[len(out)-(size+len(extra))%gcmBlockSize:], newTag, epoch)
+		require.NoError(t, err)
+		final, finalEpoch, err := resumed.Tag(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, epoch+1, finalEpoch)
+		_, err = g.OpenAppended(ctx, nonce, append(file[:len(file)-gcmTagSize], final...), aad, finalEpoch)
+		require.NoError(t, err)
+	}
+}
+
+func TestGCMAppenderForgedTag(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGCM(t)
+	nonce := make([]byte, gcmNonceSize)
+
+	sealed, err := g.Seal(ctx, nonce, []byte("original contents"), nil)
+	require.NoError(t, err)
+	body, tag := sealed[:17], append([]byte{}, sealed[17:]...)
+	tag[0] ^= 1
+
+	a, err := g.ResumeAppender(ctx, nonce, nil, 17, body[16:], tag, 0)
+	require.NoError(t, err)
+	out, err := a.Append(ctx, []byte("more"))
+	require.NoError(t, err)
+	newTag, epoch, err := a.Tag(ctx)
+	require.NoError(t, err)
+
+	_, err = g.OpenAppended(ctx, nonce, append(append(append([]byte{}, body...), out...), newTag...), nil, epoch)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = g.ResumeAppender(ctx, nonce, nil, 17, nil, tag, 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
+
+func TestGCMAppenderTagsUnrelated(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGCM(t)
+	nonce := make([]byte, gcmNonceSize)
+	aad := []byte("audit.log")
+
+	a, err := g.NewAppender(nonce, aad)
+	require.NoError(t, err)
+
+	// unmasked is GHASH over the ciphertext so far; with a shared mask the
+	// XOR of two tags would equal the XOR of these, a known polynomial in H.
+	var ciphertext []byte
+	unmasked := func() []byte {
+		lengths := make([]byte, gcmBlockSize)
+		binary.BigEndian.PutUint64(lengths, uint64(len(aad))*8)
+		binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+		return g.ghash(aad, ciphertext, lengths)
+	}
+	xor := func(a, b []byte) []byte {
+		out := make([]byte, len(a))
+		for i := range a {
+			out[i] = a[i] ^ b[i]
+		}
+		return out
+	}
+
+	out, err := a.Append(ctx, []byte("first entry\n"))
+	require.NoError(t, err)
+	ciphertext = append(ciphertext, out...)
+	first, _, err := a.Tag(ctx)
+	require.NoError(t, err)
+	firstHash := unmasked()
+
+	out, err = a.Append(ctx, []byte("second entry\n"))
+	require.NoError(t, err)
+	ciphertext = append(ciphertext, out...)
+	second, _, err := a.Tag(ctx)
+	require.NoError(t, err)
+	secondHash := unmasked()
+
+	assert.NotEqual(t, xor(firstHash, secondHash), xor(first, second))
+
+	again, _, err := a.Tag(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, second, again)
+}
@@ -0,0 +1,239 @@
+package aead
+
+import (
+	"context"
+	"crypto/subtle"
+	"math/bits"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	AESOCBName  = "aes-ocb"
+	OCBModeName = "ocb"
+
+	ocbBlockSize      = 16
+	ocbNonceSize      = 12
+	ocbMaxNonceSize   = 15
+	ocbDefaultTagSize = 16
+)
+
+func init() {
+	Register(AESOCBName, func(key []byte) (AEAD, error) {
+		block, err := rijndael.NewRijndael(ocbBlockSize, len(key), 0x1B)
+		if err != nil {
+			return nil, err
+		}
+		return NewOCB(context.Background(), block, key, ocbDefaultTagSize)
+	})
+	RegisterMode(OCBModeName, func(ctx context.Context, block cipher.BlockCipher, key []byte) (AEAD, error) {
+		return NewOCB(ctx, block, key, ocbDefaultTagSize)
+	})
+}
+
+// OCB is OCB3 as specified in RFC 7253. Seal accepts nonces of 1 to 15
+// bytes; NonceSize reports the recommended 12.
+type OCB struct {
+	block   cipher.BlockCipher
+	tagSize int
+	lStar   []byte
+	lDollar []byte
+	// l[i] is L_i, indexed by the number of trailing zeros of the block
+	// number, which is below 64 for any message that fits in memory.
+	l [64][]byte
+}
+
+func NewOCB(ctx context.Context, block cipher.BlockCipher, key []byte, tagSize int) (*OCB, error) {
+	if block.BlockSize() != ocbBlockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	if tagSize < 8 || tagSize > ocbBlockSize {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "tag size must be between 8 and 16: %w")
+	}
+	if err := block.SetKey(ctx, key); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+
+	lStar, err := block.Encrypt(ctx, make([]byte, ocbBlockSize))
+	if err != nil {
+		return nil, err
+	}
+
+	o := &OCB{block: block, tagSize: tagSize, lStar: lStar, lDollar: doubleBlock(lStar, 0x87)}
+	o.l[0] = doubleBlock(o.lDollar, 0x87)
+	for i := 1; i < len(o.l); i++ {
+		o.l[i] = doubleBlock(o.l[i-1], 0x87)
+	}
+	return o, nil
+}
+
+func (o *OCB) NonceSize() int {
+	return ocbNonceSize
+}
+
+func (o *OCB) Overhead() int {
+	return o.tagSize
+}
+
+func (o *OCB) Seal(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	ciphertext, tag, err := o.crypt(ctx, nonce, plaintext, additionalData, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(ciphertext, tag[:o.tagSize]...), nil
+}
+
+func (o *OCB) Open(ctx context.Context, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < o.tagSize {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	body := ciphertext[:len(ciphertext)-o.tagSize]
+	plaintext, tag, err := o.crypt(ctx, nonce, body, additionalData, false)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(tag[:o.tagSize], ciphertext[len(body):]) != 1 {
+		return nil, errors.ErrAuthenticationFailed
+	}
+	return plaintext, nil
+}
+
+// crypt runs OCB-ENCRYPT or OCB-DECRYPT over in and returns the output and
+// the full-width tag.
+func (o *OCB) crypt(ctx context.Context, nonce, in, additionalData []byte, encrypt bool) ([]byte, []byte, error) {
+	offset, err := o.initialOffset(ctx, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make([]byte, len(in))
+	checksum := make([]byte, ocbBlockSize)
+	full := len(in) / ocbBlockSize * ocbBlockSize
+
+	for i := 0; i < full; i += ocbBlockSize {
+		subtle.XORBytes(offset, offset, o.l[bits.TrailingZeros(uint(i/ocbBlockSize+1))])
+
+		input := make([]byte, ocbBlockSize)
+		subtle.XORBytes(input, in[i:], offset)
+		var output []byte
+		if encrypt {
+			output, err = o.block.Encrypt(ctx, input)
+		} else {
+			output, err = o.block.Decrypt(ctx, input)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		subtle.XORBytes(out[i:], output, offset)
+
+		if encrypt {
+			subtle.XORBytes(checksum, checksum, in[i:i+ocbBlockSize])
+		} else {
+			subtle.XORBytes(checksum, checksum, out[i:i+ocbBlockSize])
+		}
+	}
+
+	if rest := len(in) - full; rest > 0 {
+		subtle.XORBytes(offset, offset, o.lStar)
+		pad, err := o.block.Encrypt(ctx, offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		subtle.XORBytes(out[full:], in[full:], pad[:rest])
+
+		last := in[full:]
+		if !encrypt {
+			last = out[full:]
+		}
+		subtle.XORBytes(checksum, checksum, padBlock(last))
+	}
+
+	subtle.XORBytes(checksum, checksum, offset)
+	subtle.XORBytes(checksum, checksum, o.lDollar)
+	tag, err := o.block.Encrypt(ctx, checksum)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	auth, err := o.hash(ctx, additionalData)
+	if err != nil {
+		return nil, nil, err
+	}
+	subtle.XORBytes(tag, tag, auth)
+
+	return out, tag, nil
+}
+
+// initialOffset derives Offset_0 from the nonce through the stretch
+// construction of RFC 7253, section 4.2.
+func (o *OCB) initialOffset(ctx context.Context, nonce []byte) ([]byte, error) {
+	if len(nonce) == 0 || len(nonce) > ocbMaxNonceSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+
+	n := make([]byte, ocbBlockSize)
+	n[0] = byte(o.tagSize*8%128) << 1
+	n[ocbBlockSize-1-len(nonce)] |= 1
+	copy(n[ocbBlockSize-len(nonce):], nonce)
+
+	bottom := int(n[ocbBlockSize-1] & 0x3F)
+	n[ocbBlockSize-1] &^= 0x3F
+
+	ktop, err := o.block.Encrypt(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	stretch := make([]byte, ocbBlockSize+8)
+	copy(stretch, ktop)
+	subtle.XORBytes(stretch[ocbBlockSize:], ktop[:8], ktop[1:9])
+
+	offset := make([]byte, ocbBlockSize)
+	shift, bit := bottom/8, uint(bottom%8)
+	for i := range offset {
+		offset[i] = stretch[i+shift]<<bit | stretch[i+shift+1]>>(8-bit)
+	}
+	return offset, nil
+}
+
+func (o *OCB) hash(ctx context.Context, additionalData []byte) ([]byte, error) {
+	sum := make([]byte, ocbBlockSize)
+	offset := make([]byte, ocbBlockSize)
+	full := len(additionalData) / ocbBlockSize * ocbBlockSize
+
+	for i := 0; i < full; i += ocbBlockSize {
+		subtle.XORBytes(offset, offset, o.l[bits.TrailingZeros(uint(i/ocbBlockSize+1))])
+
+		input := make([]byte, ocbBlockSize)
+		subtle.XORBytes(input, additionalData[i:], offset)
+		output, err := o.block.Encrypt(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(sum, sum, output)
+	}
+
+	if full < len(additionalData) {
+		subtle.XORBytes(offset, offset, o.lStar)
+		input := padBlock(additionalData[full:])
+		subtle.XORBytes(input, input, offset)
+		output, err := o.block.Encrypt(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(sum, sum, output)
+	}
+
+	return sum, nil
+}
+
+// padBlock returns a partial block followed by a single one bit and zeros.
+func padBlock(partial []byte) []byte {
+	block := make([]byte, ocbBlockSize)
+	copy(block, partial)
+	block[len(partial)] = 0x80
+	return block
+}
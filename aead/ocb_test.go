@@ -0,0 +1,127 @@
+package aead
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAESOCB(t *testing.T, key []byte, tagSize int) *OCB {
+	block, err := rijndael.NewRijndael(16, len(key), 0x1B)
+	require.NoError(t, err)
+	o, err := NewOCB(context.Background(), block, key, tagSize)
+	require.NoError(t, err)
+	return o
+}
+
+// Sample results from RFC 7253, appendix A.
+func TestOCBRFC7253Vectors(t *testing.T) {
+	ctx := context.Background()
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	o := newAESOCB(t, key, 16)
+
+	tests := []struct {
+		nonce, aad, plaintext, ciphertext string
+	}{
+		{"bbaa99887766554433221100", "", "", "785407bfffc8ad9edcc5520ac9111ee6"},
+		{"bbaa99887766554433221101", "0001020304050607", "0001020304050607", "6820b3657b6f615a5725bda0d3b4eb3a257c9af1f8f03009"},
+		{"bbaa99887766554433221102", "0001020304050607", "", "81017f8203f081277152fade694a0a00"},
+		{"bbaa99887766554433221103", "", "0001020304050607", "45dd69f8f5aae72414054cd1f35d82760b2cd00d2f99bfa9"},
+		{"bbaa99887766554433221104", "000102030405060708090a0b0c0d0e0f", "000102030405060708090a0b0c0d0e0f", "571d535b60b277188be5147170a9a22c3ad7a4ff3835b8c5701c1ccec8fc3358"},
+		{"bbaa99887766554433221105", "000102030405060708090a0b0c0d0e0f", "", "8cf761b6902ef764462ad86498ca6b97"},
+		{"bbaa99887766554433221106", "", "000102030405060708090a0b0c0d0e0f", "5ce88ec2e0692706a915c00aeb8b2396f40e1c743f52436bdf06d8fa1eca343d"},
+		{"bbaa99887766554433221107", "000102030405060708090a0b0c0d0e0f1011121314151617", "000102030405060708090a0b0c0d0e0f1011121314151617", "1ca2207308c87c010756104d8840ce1952f09673a448a122c92c62241051f57356d7f3c90bb0e07f"},
+	}
+
+	for _, tt := range tests {
+		nonce, _ := hex.DecodeString(tt.nonce)
+		aad, _ := hex.DecodeString(tt.aad)
+		plaintext, _ := hex.DecodeString(tt.plaintext)
+
+		sealed, err := o.Seal(ctx, nonce, plaintext, aad)
+		require.NoError(t, err)
+		assert.Equal(t, tt.ciphertext, hex.EncodeToString(sealed), tt.nonce)
+
+		opened, err := o.Open(ctx, nonce, sealed, aad)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, opened, tt.nonce)
+	}
+}
+
+// TestOCBRFC7253AllLengths runs the iterated test of RFC 7253, appendix A,
+// which covers every message length up to 127 bytes and each tag length.
+func TestOCBRFC7253AllLengths(t *testing.T) {
+	ctx := context.Background()
+	expected := map[int]string{
+		16: "67e944d23256c5e0b6c61fa22fdf1ea2",
+		12: "77a3d8e73589158d25d01209",
+		8:  "192c9b7bd90ba06a",
+	}
+
+	for tagSize, want := range expected {
+		key := make([]byte, 16)
+		key[15] = byte(tagSize * 8)
+		o := newAESOCB(t, key, tagSize)
+
+		nonce := func(n int) []byte {
+			b := make([]byte, 12)
+			binary.BigEndian.PutUint32(b[8:], uint32(n))
+			return b
+		}
+
+		var c []byte
+		n := 0
+		for i := 0; i < 128; i++ {
+			s := make([]byte, i)
+			for _, run := range []struct{ aad, plaintext []byte }{{s, s}, {nil, s}, {s, nil}} {
+				n++
+				sealed, err := o.Seal(ctx, nonce(n), run.plaintext, run.aad)
+				require.NoError(t, err)
+				c = append(c, sealed...)
+			}
+		}
+
+		final, err := o.Seal(ctx, nonce(n+1), nil, c)
+		require.NoError(t, err)
+		assert.Equal(t, want, hex.EncodeToString(final), "tag size %d", tagSize)
+	}
+}
+
+func TestOCBTampering(t *testing.T) {
+	ctx := context.Background()
+	a, err := New(AESOCBName, []byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	nonce := make([]byte, a.NonceSize())
+	sealed, err := a.Seal(ctx, nonce, []byte("an odd-length message spanning blocks"), []byte("header"))
+	require.NoError(t, err)
+
+	_, err = a.Open(ctx, nonce, sealed, []byte("Header"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	sealed[5] ^= 0x01
+	_, err = a.Open(ctx, nonce, sealed, []byte("header"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = a.Open(ctx, nonce, sealed[:15], nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+	_, err = a.Seal(ctx, make([]byte, 16), nil, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+}
+
+func TestOCBParameters(t *testing.T) {
+	_, err := NewOCB(context.Background(), des.NewDES(), []byte("01234567"), 16)
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	_, err = NewOCB(context.Background(), block, make([]byte, 16), 4)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
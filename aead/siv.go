@@ -0,0 +1,167 @@
+package aead
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/mac"
+)
+
+const (
+	AESSIVName = "aes-siv"
+
+	sivBlockSize = 16
+	sivNonceSize = 16
+	sivRb        = 0x87
+)
+
+func init() {
+	Register(AESSIVName, func(key []byte) (AEAD, error) {
+		macBlock, err := rijndael.NewRijndael(sivBlockSize, len(key)/2, 0x1B)
+		if err != nil {
+			return nil, err
+		}
+		ctrBlock, err := rijndael.NewRijndael(sivBlockSize, len(key)/2, 0x1B)
+		if err != nil {
+			return nil, err
+		}
+		return NewSIV(context.Background(), macBlock, ctrBlock, key)
+	})
+}
+
+type SIV struct {
+	cmac *mac.CMAC
+	ctr  cipher.BlockCipher
+}
+
+func NewSIV(ctx context.Context, macBlock, ctrBlock cipher.BlockCipher, key []byte) (*SIV, error) {
+	if macBlock.BlockSize() != sivBlockSize || ctrBlock.BlockSize() != sivBlockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	if len(key) == 0 || len(key)%2 != 0 {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	half := len(key) / 2
+	cmac, err := mac.NewCMAC(ctx, macBlock, key[:half])
+	if err != nil {
+		return nil, err
+	}
+	if err := ctrBlock.SetKey(ctx, key[half:]); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+	return &SIV{cmac: cmac, ctr: ctrBlock}, nil
+}
+
+func (s *SIV) NonceSize() int {
+	return sivNonceSize
+}
+
+func (s *SIV) Overhead() int {
+	return sivBlockSize
+}
+
+func (s *SIV) Seal(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	return s.seal(ctx, s.components(nonce, additionalData), plaintext)
+}
+
+func (s *SIV) Open(ctx context.Context, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	return s.open(ctx, s.components(nonce, additionalData), ciphertext)
+}
+
+func (s *SIV) components(nonce, additionalData []byte) [][]byte {
+	components := [][]byte{additionalData}
+	if len(nonce) > 0 {
+		components = append(components, nonce)
+	}
+	return components
+}
+
+func (s *SIV) seal(ctx context.Context, components [][]byte, plaintext []byte) ([]byte, error) {
+	v, err := s.s2v(ctx, components, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := counterMode(ctx, s.ctr, sivCounter(v), plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(v, ciphertext...), nil
+}
+
+func (s *SIV) open(ctx context.Context, components [][]byte, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < sivBlockSize {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	v := ciphertext[:sivBlockSize]
+	plaintext, err := counterMode(ctx, s.ctr, sivCounter(v), ciphertext[sivBlockSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	expected, err := s.s2v(ctx, components, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(expected, v) != 1 {
+		return nil, errors.ErrAuthenticationFailed
+	}
+	return plaintext, nil
+}
+
+func (s *SIV) s2v(ctx context.Context, components [][]byte, plaintext []byte) ([]byte, error) {
+	d, err := s.cmac.Sum(ctx, make([]byte, sivBlockSize))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, component := range components {
+		tag, err := s.cmac.Sum(ctx, component)
+		if err != nil {
+			return nil, err
+		}
+		d = doubleBlock(d, sivRb)
+		for i := range d {
+			d[i] ^= tag[i]
+		}
+	}
+
+	var t []byte
+	if len(plaintext) >= sivBlockSize {
+		t = append([]byte{}, plaintext...)
+		offset := len(t) - sivBlockSize
+		for i := range d {
+			t[offset+i] ^= d[i]
+		}
+	} else {
+		t = doubleBlock(d, sivRb)
+		t[len(plaintext)] ^= 0x80
+		for i := range plaintext {
+			t[i] ^= plaintext[i]
+		}
+	}
+
+	return s.cmac.Sum(ctx, t)
+}
+
+func sivCounter(v []byte) []byte {
+	q := append([]byte{}, v...)
+	q[8] &= 0x7F
+	q[12] &= 0x7F
+	return q
+}
+
+func doubleBlock(b []byte, rb byte) []byte {
+	out := make([]byte, len(b))
+	carry := b[0] >> 7
+	for i := 0; i < len(b)-1; i++ {
+		out[i] = b[i]<<1 | b[i+1]>>7
+	}
+	out[len(b)-1] = b[len(b)-1]<<1 ^ rb*carry
+	return out
+}
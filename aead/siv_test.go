@@ -0,0 +1,62 @@
+package aead
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestSIVDeterministicVector(t *testing.T) {
+	ctx := context.Background()
+	key := decodeHex(t, "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	ad := decodeHex(t, "101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext := decodeHex(t, "112233445566778899aabbccddee")
+
+	siv, err := New(AESSIVName, key)
+	require.NoError(t, err)
+
+	sealed, err := siv.Seal(ctx, nil, plaintext, ad)
+	require.NoError(t, err)
+	assert.Equal(t, "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c", hex.EncodeToString(sealed))
+
+	opened, err := siv.Open(ctx, nil, sealed, ad)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	sealed[len(sealed)-1] ^= 1
+	_, err = siv.Open(ctx, nil, sealed, ad)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+}
+
+func TestSIVNonceBasedVector(t *testing.T) {
+	ctx := context.Background()
+	key := decodeHex(t, "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f")
+
+	siv, err := New(AESSIVName, key)
+	require.NoError(t, err)
+
+	components := [][]byte{
+		decodeHex(t, "00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100"),
+		decodeHex(t, "102030405060708090a0"),
+		decodeHex(t, "09f911029d74e35bd84156c5635688c0"),
+	}
+	plaintext := decodeHex(t, "7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553")
+
+	sealed, err := siv.(*SIV).seal(ctx, components, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d", hex.EncodeToString(sealed))
+
+	opened, err := siv.(*SIV).open(ctx, components, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+}
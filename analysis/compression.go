@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type CompressionLeak struct {
+	Samples         int
+	MinLength       int
+	MaxLength       int
+	DistinctLengths int
+	EntropyBits     float64
+	Shortest        [][]byte
+}
+
+func (l *CompressionLeak) Leaks() bool {
+	return l.DistinctLengths > 1
+}
+
+func MeasureCompressionLeak(oracle EncryptionOracle, prefixes [][]byte) (*CompressionLeak, error) {
+	if len(prefixes) < 2 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "at least two prefixes are required: %w")
+	}
+	for _, prefix := range prefixes[1:] {
+		if len(prefix) != len(prefixes[0]) {
+			return nil, errors.Annotate(errors.ErrInvalidParameters, "prefixes must have equal length: %w")
+		}
+	}
+
+	leak := &CompressionLeak{Samples: len(prefixes)}
+	lengths := make([]int, len(prefixes))
+	counts := make(map[int]int)
+
+	for i, prefix := range prefixes {
+		ciphertext, err := oracle(prefix)
+		if err != nil {
+			return nil, errors.Annotate(err, "oracle failed: %w")
+		}
+
+		lengths[i] = len(ciphertext)
+		counts[lengths[i]]++
+		if i == 0 || lengths[i] < leak.MinLength {
+			leak.MinLength = lengths[i]
+		}
+		leak.MaxLength = max(leak.MaxLength, lengths[i])
+	}
+
+	for i, prefix := range prefixes {
+		if lengths[i] == leak.MinLength {
+			leak.Shortest = append(leak.Shortest, prefix)
+		}
+	}
+
+	leak.DistinctLengths = len(counts)
+	for _, count := range counts {
+		p := float64(count) / float64(len(prefixes))
+		leak.EntropyBits -= p * math.Log2(p)
+	}
+
+	return leak, nil
+}
+
+func GuessNextByte(oracle EncryptionOracle, known, alphabet []byte) ([]byte, error) {
+	if len(alphabet) < 2 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "alphabet needs at least two symbols: %w")
+	}
+
+	prefixes := make([][]byte, len(alphabet))
+	for i, symbol := range alphabet {
+		prefixes[i] = append(append([]byte{}, known...), symbol)
+	}
+
+	leak, err := MeasureCompressionLeak(oracle, prefixes)
+	if err != nil {
+		return nil, err
+	}
+	if !leak.Leaks() {
+		return append([]byte{}, alphabet...), nil
+	}
+
+	candidates := make([]byte, len(leak.Shortest))
+	for i, prefix := range leak.Shortest {
+		candidates[i] = prefix[len(prefix)-1]
+	}
+	return candidates, nil
+}
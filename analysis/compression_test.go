@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"bytes"
+	"compress/flate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher/chacha20"
+)
+
+const compressionSecret = "session=7f3a9c2e51"
+
+func newCompressionOracle(t *testing.T, compress bool) EncryptionOracle {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	nonce := make([]byte, 12)
+
+	return func(prefix []byte) ([]byte, error) {
+		request := append([]byte("GET /search?q="), prefix...)
+		request = append(request, "\r\nCookie: "+compressionSecret+"\r\n"...)
+
+		if compress {
+			var buf bytes.Buffer
+			w, err := flate.NewWriter(&buf, flate.BestCompression)
+			require.NoError(t, err)
+			_, err = w.Write(request)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			request = buf.Bytes()
+		}
+
+		c, err := chacha20.New(key, nonce, 0)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(request))
+		if err := c.XORKeyStream(out, request); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+}
+
+func TestCompressionLeak(t *testing.T) {
+	prefixes := [][]byte{[]byte("session=7f3a"), []byte("xqzjkwvbnmpl")}
+
+	leak, err := MeasureCompressionLeak(newCompressionOracle(t, true), prefixes)
+	require.NoError(t, err)
+	assert.True(t, leak.Leaks())
+	assert.Equal(t, 2, leak.DistinctLengths)
+	assert.InDelta(t, 1.0, leak.EntropyBits, 1e-9)
+	assert.Equal(t, [][]byte{[]byte("session=7f3a")}, leak.Shortest)
+	assert.Less(t, leak.MinLength, leak.MaxLength)
+
+	leak, err = MeasureCompressionLeak(newCompressionOracle(t, false), prefixes)
+	require.NoError(t, err)
+	assert.False(t, leak.Leaks())
+	assert.Zero(t, leak.EntropyBits)
+	assert.Len(t, leak.Shortest, 2)
+}
+
+func TestGuessNextByte(t *testing.T) {
+	oracle := newCompressionOracle(t, true)
+
+	candidates, err := GuessNextByte(oracle, []byte("session=7f3a9"), []byte("0123456789abcdef"))
+	require.NoError(t, err)
+	assert.Contains(t, candidates, byte('c'))
+	assert.Less(t, len(candidates), 16)
+
+	candidates, err = GuessNextByte(newCompressionOracle(t, false), []byte("session="), []byte("0123456789abcdef"))
+	require.NoError(t, err)
+	assert.Len(t, candidates, 16)
+}
+
+func TestCompressionLeakValidation(t *testing.T) {
+	oracle := newCompressionOracle(t, true)
+
+	_, err := MeasureCompressionLeak(oracle, [][]byte{[]byte("a")})
+	require.Error(t, err)
+
+	_, err = MeasureCompressionLeak(oracle, [][]byte{[]byte("a"), []byte("bb")})
+	require.Error(t, err)
+
+	_, err = GuessNextByte(oracle, nil, []byte("a"))
+	require.Error(t, err)
+}
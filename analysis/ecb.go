@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"github.com/masterkusok/crypto/errors"
+)
+
+const ECBThreshold = 0.0
+
+type EncryptionOracle func(plaintext []byte) ([]byte, error)
+
+type DetectedMode int
+
+const (
+	ModeUnknown DetectedMode = iota
+	ModeECB
+	ModeChained
+)
+
+func (m DetectedMode) String() string {
+	switch m {
+	case ModeECB:
+		return "ECB"
+	case ModeChained:
+		return "chained"
+	default:
+		return "unknown"
+	}
+}
+
+func DetectECB(ciphertext []byte, blockSize int) (float64, error) {
+	if blockSize <= 0 {
+		return 0, errors.ErrInvalidBlockSize
+	}
+	if len(ciphertext)%blockSize != 0 {
+		return 0, errors.ErrInvalidDataLength
+	}
+
+	numBlocks := len(ciphertext) / blockSize
+	if numBlocks < 2 {
+		return 0, nil
+	}
+
+	seen := make(map[string]int, numBlocks)
+	for i := 0; i < numBlocks; i++ {
+		seen[string(ciphertext[i*blockSize:(i+1)*blockSize])]++
+	}
+
+	repeated := numBlocks - len(seen)
+	return float64(repeated) / float64(numBlocks-1), nil
+}
+
+func DetectMode(oracle EncryptionOracle, blockSize int) (DetectedMode, error) {
+	if blockSize <= 0 {
+		return ModeUnknown, errors.ErrInvalidBlockSize
+	}
+
+	probe := make([]byte, 4*blockSize)
+	ciphertext, err := oracle(probe)
+	if err != nil {
+		return ModeUnknown, errors.Annotate(err, "oracle failed: %w")
+	}
+
+	ciphertext = ciphertext[:len(ciphertext)-len(ciphertext)%blockSize]
+	score, err := DetectECB(ciphertext, blockSize)
+	if err != nil {
+		return ModeUnknown, err
+	}
+
+	if score > ECBThreshold {
+		return ModeECB, nil
+	}
+	return ModeChained, nil
+}
@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOracle(t *testing.T, mode cipher.CipherMode) EncryptionOracle {
+	ctx := context.Background()
+	block := des.NewDES()
+	require.NoError(t, block.SetKey(ctx, []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}))
+	iv := []byte("initvect")
+
+	return func(plaintext []byte) ([]byte, error) {
+		data := append([]byte("prefix"), plaintext...)
+		padded, err := cipher.Pad(data, block.BlockSize(), cipher.PKCS7)
+		if err != nil {
+			return nil, err
+		}
+		return mode.Encrypt(ctx, block, padded, iv)
+	}
+}
+
+func TestDetectECB(t *testing.T) {
+	structured := bytes.Repeat([]byte("ROW:0001"), 8)
+
+	ecb, err := newOracle(t, &cipher.ECBMode{})(structured)
+	require.NoError(t, err)
+	score, err := DetectECB(ecb, 8)
+	require.NoError(t, err)
+	assert.Greater(t, score, 0.5)
+
+	cbc, err := newOracle(t, &cipher.CBCMode{})(structured)
+	require.NoError(t, err)
+	score, err = DetectECB(cbc, 8)
+	require.NoError(t, err)
+	assert.Zero(t, score)
+}
+
+func TestDetectECBInvalid(t *testing.T) {
+	_, err := DetectECB(make([]byte, 10), 8)
+	assert.Error(t, err)
+
+	_, err = DetectECB(make([]byte, 8), 0)
+	assert.Error(t, err)
+}
+
+func TestDetectMode(t *testing.T) {
+	mode, err := DetectMode(newOracle(t, &cipher.ECBMode{}), 8)
+	require.NoError(t, err)
+	assert.Equal(t, ModeECB, mode)
+
+	mode, err = DetectMode(newOracle(t, &cipher.CBCMode{}), 8)
+	require.NoError(t, err)
+	assert.Equal(t, ModeChained, mode)
+}
@@ -0,0 +1,92 @@
+package analysis
+
+import (
+	"math/bits"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+func DifferenceTable(sbox []byte, inBits, outBits int) ([][]int, error) {
+	if err := checkSBox(sbox, inBits, outBits); err != nil {
+		return nil, err
+	}
+
+	table := make([][]int, 1<<inBits)
+	for dx := range table {
+		table[dx] = make([]int, 1<<outBits)
+		for x := range sbox {
+			table[dx][sbox[x]^sbox[x^dx]]++
+		}
+	}
+	return table, nil
+}
+
+func DifferentialUniformity(sbox []byte, inBits, outBits int) (int, error) {
+	table, err := DifferenceTable(sbox, inBits, outBits)
+	if err != nil {
+		return 0, err
+	}
+
+	best := 0
+	for dx := 1; dx < len(table); dx++ {
+		for _, count := range table[dx] {
+			best = max(best, count)
+		}
+	}
+	return best, nil
+}
+
+func LinearityTable(sbox []byte, inBits, outBits int) ([][]int, error) {
+	if err := checkSBox(sbox, inBits, outBits); err != nil {
+		return nil, err
+	}
+
+	table := make([][]int, 1<<inBits)
+	for a := range table {
+		table[a] = make([]int, 1<<outBits)
+		for b := range table[a] {
+			matches := 0
+			for x := range sbox {
+				if bits.OnesCount(uint(x&a))%2 == bits.OnesCount(uint(int(sbox[x])&b))%2 {
+					matches++
+				}
+			}
+			table[a][b] = matches - len(sbox)/2
+		}
+	}
+	return table, nil
+}
+
+func MaxLinearBias(sbox []byte, inBits, outBits int) (int, error) {
+	table, err := LinearityTable(sbox, inBits, outBits)
+	if err != nil {
+		return 0, err
+	}
+
+	best := 0
+	for a := range table {
+		for b := 1; b < len(table[a]); b++ {
+			bias := table[a][b]
+			if bias < 0 {
+				bias = -bias
+			}
+			best = max(best, bias)
+		}
+	}
+	return best, nil
+}
+
+func checkSBox(sbox []byte, inBits, outBits int) error {
+	if inBits <= 0 || inBits > 16 || outBits <= 0 || outBits > 8 {
+		return errors.ErrInvalidParameters
+	}
+	if len(sbox) != 1<<inBits {
+		return errors.Annotate(errors.ErrInvalidDataLength, "S-box must have %d entries: %w", 1<<inBits)
+	}
+	for _, v := range sbox {
+		if int(v) >= 1<<outBits {
+			return errors.Annotate(errors.ErrInvalidParameters, "S-box output %d exceeds %d bits: %w", v, outBits)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher/des"
+)
+
+func TestDESSBoxStrength(t *testing.T) {
+	tables := des.DefaultTables()
+
+	for i := range tables.SBoxes {
+		sbox, err := tables.SBoxFunction(i)
+		require.NoError(t, err)
+
+		uniformity, err := DifferentialUniformity(sbox, 6, 4)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, uniformity, 16)
+
+		bias, err := MaxLinearBias(sbox, 6, 4)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, bias, 20)
+	}
+}
+
+func TestLinearSBoxIsWeak(t *testing.T) {
+	sbox := make([]byte, 16)
+	for x := range sbox {
+		sbox[x] = byte(x)
+	}
+
+	uniformity, err := DifferentialUniformity(sbox, 4, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 16, uniformity)
+
+	bias, err := MaxLinearBias(sbox, 4, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 8, bias)
+}
+
+func TestDifferenceTableRows(t *testing.T) {
+	sbox := []byte{0xC, 0x5, 0x6, 0xB, 0x9, 0x0, 0xA, 0xD, 0x3, 0xE, 0xF, 0x8, 0x4, 0x7, 0x1, 0x2}
+
+	table, err := DifferenceTable(sbox, 4, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 16, table[0][0])
+	for _, row := range table {
+		sum := 0
+		for _, count := range row {
+			sum += count
+		}
+		assert.Equal(t, 16, sum)
+	}
+
+	uniformity, err := DifferentialUniformity(sbox, 4, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, uniformity)
+}
+
+func TestSBoxValidation(t *testing.T) {
+	_, err := DifferentialUniformity(make([]byte, 15), 4, 4)
+	require.Error(t, err)
+
+	_, err = MaxLinearBias([]byte{0x10, 0, 0, 0}, 2, 4)
+	require.Error(t, err)
+}
@@ -0,0 +1,347 @@
+package archive
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/mac/hmac"
+	"github.com/masterkusok/crypto/sign"
+)
+
+const (
+	Version   = 3
+	ChunkSize = 64 * 1024
+	TagSize   = sha256.Size
+
+	maxHeaderSize = 64 * 1024 * 1024
+)
+
+// MAC domains keep the manifest tag and the entry tags from being swapped
+// for one another.
+const (
+	domainManifest byte = iota
+	domainEntry
+)
+
+var (
+	magic  = []byte("MKARCH03")
+	macKDF = []byte("mkarch mac key")
+)
+
+type Entry struct {
+	Path    string      `json:"path"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mod_time"`
+	Size    int64       `json:"size"`
+	Offset  uint64      `json:"offset"`
+}
+
+type Manifest struct {
+	Version int     `json:"version"`
+	IV      []byte  `json:"iv"`
+	Entries []Entry `json:"entries"`
+}
+
+func Create(ctx context.Context, w io.Writer, block cipher.BlockCipher, key []byte, signer sign.Signer, root string, paths []string) error {
+	return create(ctx, w, block, key, nil, signer, root, paths)
+}
+
+func CreateForRecipients(ctx context.Context, w io.Writer, block cipher.BlockCipher, keySize int, signer sign.Signer, root string, paths []string, recipients ...Recipient) error {
+	if len(recipients) == 0 || keySize <= 0 || keySize > maxDataKeySize {
+		return errors.ErrInvalidParameters
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return errors.Annotate(err, "generating data key: %w")
+	}
+
+	stanzas, err := wrapDataKey(dataKey, recipients)
+	if err != nil {
+		return err
+	}
+
+	return create(ctx, w, block, dataKey, stanzas, signer, root, paths)
+}
+
+func create(ctx context.Context, w io.Writer, block cipher.BlockCipher, key []byte, stanzas []Stanza, signer sign.Signer, root string, paths []string) error {
+	if err := block.SetKey(ctx, key); err != nil {
+		return errors.Annotate(err, "failed to set key: %w")
+	}
+
+	manifest, err := buildManifest(root, paths, block.BlockSize())
+	if err != nil {
+		return err
+	}
+
+	macKey := deriveMACKey(key)
+	if err := writeSealedHeader(ctx, w, block, macKey, stanzas, manifest, signer); err != nil {
+		return err
+	}
+
+	stream := newKeystream(block, manifest.IV, macKey)
+	for _, entry := range manifest.Entries {
+		if err := writeFile(ctx, w, stream, filepath.Join(root, filepath.FromSlash(entry.Path)), &entry); err != nil {
+			return errors.Annotate(err, "%s: %w", entry.Path)
+		}
+	}
+
+	return nil
+}
+
+func deriveMACKey(key []byte) []byte {
+	return hmac.Sum(sha256.New, key, macKDF)
+}
+
+// writeSealedHeader encrypts the manifest under the data key and signs the
+// sealed form together with the recipient stanzas, so that paths and sizes
+// stay private while the signature can still be checked before any key is
+// available.
+func writeSealedHeader(ctx context.Context, w io.Writer, block cipher.BlockCipher, macKey []byte, stanzas []Stanza, manifest *Manifest, signer sign.Signer) error {
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Annotate(err, "encoding manifest: %w")
+	}
+
+	sealed, err := sealManifest(ctx, block, macKey, encoded)
+	if err != nil {
+		return errors.Annotate(err, "encrypting manifest: %w")
+	}
+
+	h := &header{stanzas: stanzas, manifest: sealed}
+	if err := h.sign(signer); err != nil {
+		return err
+	}
+	return writeHeader(w, h)
+}
+
+// sealManifest returns nonce || CTR ciphertext || HMAC tag.
+func sealManifest(ctx context.Context, block cipher.BlockCipher, macKey, manifest []byte) ([]byte, error) {
+	nonce := make([]byte, block.BlockSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Annotate(err, "generating nonce: %w")
+	}
+
+	ciphertext, err := newKeystream(block, nonce, macKey).xor(ctx, 0, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := append(nonce, ciphertext...)
+	return append(sealed, hmac.Sum(sha256.New, macKey, append([]byte{domainManifest}, sealed...))...), nil
+}
+
+func openManifest(ctx context.Context, block cipher.BlockCipher, macKey, sealed []byte) ([]byte, error) {
+	blockSize := block.BlockSize()
+	if len(sealed) < blockSize+TagSize {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	body, tag := sealed[:len(sealed)-TagSize], sealed[len(sealed)-TagSize:]
+	if err := hmac.Verify(sha256.New, macKey, append([]byte{domainManifest}, body...), tag); err != nil {
+		return nil, errors.Annotate(err, "manifest: %w")
+	}
+
+	return newKeystream(block, body[:blockSize], macKey).xor(ctx, 0, body[blockSize:])
+}
+
+func buildManifest(root string, paths []string, blockSize int) (*Manifest, error) {
+	iv := make([]byte, blockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, errors.Annotate(err, "generating IV: %w")
+	}
+
+	manifest := &Manifest{Version: Version, IV: iv}
+	var offset uint64
+
+	for _, path := range paths {
+		rel := filepath.ToSlash(filepath.Clean(path))
+		if !fs.ValidPath(rel) {
+			return nil, errors.ErrUnsafePath
+		}
+
+		full := filepath.Join(root, path)
+		info, err := os.Stat(full)
+		if err != nil {
+			return nil, errors.Annotate(err, "stat %s: %w", path)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, errors.Annotate(errors.ErrInvalidParameters, "%s is not a regular file: %w", path)
+		}
+
+		manifest.Entries = append(manifest.Entries, Entry{
+			Path:    rel,
+			Mode:    info.Mode().Perm(),
+			ModTime: info.ModTime().UTC(),
+			Size:    info.Size(),
+			Offset:  offset,
+		})
+		offset += uint64(paddedSize(info.Size(), blockSize) / int64(blockSize))
+	}
+
+	return manifest, nil
+}
+
+type header struct {
+	stanzas    []Stanza
+	rawStanzas []byte
+	manifest   []byte
+	signature  []byte
+}
+
+// sign encodes the stanzas and signs them with the sealed manifest.
+func (h *header) sign(signer sign.Signer) error {
+	raw, err := json.Marshal(h.stanzas)
+	if err != nil {
+		return errors.Annotate(err, "encoding recipients: %w")
+	}
+	h.rawStanzas = raw
+
+	if h.signature, err = signer.Sign(h.signed()); err != nil {
+		return errors.Annotate(err, "signing manifest: %w")
+	}
+	return nil
+}
+
+func (h *header) verify(verifier sign.Verifier) error {
+	if err := verifier.Verify(h.signed(), h.signature); err != nil {
+		return errors.Annotate(errors.ErrInvalidSignature, "manifest: %w")
+	}
+	return nil
+}
+
+// signed is the stanza blob as stored, length-prefixed, followed by the
+// sealed manifest.
+func (h *header) signed() []byte {
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(h.rawStanzas)))
+	out = append(out, h.rawStanzas...)
+	return append(out, h.manifest...)
+}
+
+func writeHeader(w io.Writer, h *header) error {
+	buf := append([]byte{}, magic...)
+	for _, blob := range [][]byte{h.rawStanzas, h.manifest, h.signature} {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(blob)))
+		buf = append(buf, blob...)
+	}
+
+	if _, err := w.Write(buf); err != nil {
+		return errors.Annotate(err, "writing header: %w")
+	}
+	return nil
+}
+
+func writeFile(ctx context.Context, w io.Writer, stream *keystream, path string, entry *Entry) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Annotate(err, "opening file: %w")
+	}
+	defer f.Close()
+
+	return writeEntry(ctx, w, stream, f, entry)
+}
+
+// writeEntry encrypts entry.Size bytes of src and appends an HMAC tag over
+// the ciphertext (encrypt-then-MAC).
+func writeEntry(ctx context.Context, w io.Writer, stream *keystream, src io.Reader, entry *Entry) error {
+	blockSize := stream.block.BlockSize()
+	buf := make([]byte, ChunkSize)
+	counter := entry.Offset
+	remaining := entry.Size
+	mac := stream.entryMAC(entry)
+
+	for remaining > 0 {
+		n, err := io.ReadFull(src, buf[:min(int64(ChunkSize), remaining)])
+		if err != nil {
+			return errors.Annotate(err, "reading file: %w")
+		}
+		remaining -= int64(n)
+
+		chunk := buf[:paddedSize(int64(n), blockSize)]
+		clear(chunk[n:])
+
+		encrypted, err := stream.xor(ctx, counter, chunk)
+		if err != nil {
+			return err
+		}
+		counter += uint64(len(chunk) / blockSize)
+		mac.Write(encrypted)
+
+		if _, err := w.Write(encrypted); err != nil {
+			return errors.Annotate(err, "writing archive: %w")
+		}
+	}
+
+	if _, err := w.Write(mac.Sum(nil)); err != nil {
+		return errors.Annotate(err, "writing archive: %w")
+	}
+	return nil
+}
+
+func paddedSize(size int64, blockSize int) int64 {
+	bs := int64(blockSize)
+	return (size + bs - 1) / bs * bs
+}
+
+type keystream struct {
+	block  cipher.BlockCipher
+	iv     []byte
+	macKey []byte
+}
+
+func newKeystream(block cipher.BlockCipher, iv, macKey []byte) *keystream {
+	return &keystream{block: block, iv: iv, macKey: macKey}
+}
+
+// entryMAC binds the tag to the archive IV and to the entry position, so
+// entries cannot be reordered, truncated or moved between archives.
+func (k *keystream) entryMAC(entry *Entry) *hmac.HMAC {
+	mac := hmac.New(sha256.New, k.macKey)
+	mac.Write([]byte{domainEntry})
+	mac.Write(k.iv)
+	mac.Write(binary.BigEndian.AppendUint64(nil, entry.Offset))
+	mac.Write(binary.BigEndian.AppendUint64(nil, uint64(entry.Size)))
+	return mac
+}
+
+func (k *keystream) xor(ctx context.Context, counter uint64, data []byte) ([]byte, error) {
+	blockSize := k.block.BlockSize()
+	result := make([]byte, len(data))
+
+	for start := 0; start < len(data); start += blockSize {
+		pad, err := k.block.Encrypt(ctx, counterBlock(k.iv, counter))
+		if err != nil {
+			return nil, err
+		}
+		for i := start; i < start+blockSize && i < len(data); i++ {
+			result[i] = data[i] ^ pad[i-start]
+		}
+		counter++
+	}
+
+	return result, nil
+}
+
+func counterBlock(iv []byte, counter uint64) []byte {
+	block := make([]byte, len(iv))
+	copy(block, iv)
+
+	carry := counter
+	for i := len(block) - 1; i >= 0 && carry > 0; i-- {
+		sum := uint64(block[i]) + carry&0xFF
+		block[i] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+
+	return block
+}
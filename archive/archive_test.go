@@ -0,0 +1,174 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ed25519Signer struct {
+	priv ed25519.PrivateKey
+}
+
+func (s ed25519Signer) Sign(message []byte) ([]byte, error) {
+	return ed25519.Sign(s.priv, message), nil
+}
+
+type ed25519Verifier struct {
+	pub ed25519.PublicKey
+}
+
+func (v ed25519Verifier) Verify(message, signature []byte) error {
+	if !ed25519.Verify(v.pub, message, signature) {
+		return errors.ErrInvalidSignature
+	}
+	return nil
+}
+
+var key = []byte("01234567")
+
+func newFixture(t *testing.T) (string, []string, ed25519Signer, ed25519Verifier) {
+	root := t.TempDir()
+	files := map[string][]byte{
+		"readme.txt":      []byte("hello archive"),
+		"data/large.bin":  bytes.Repeat([]byte{0xAB}, ChunkSize+100),
+		"data/empty.txt":  nil,
+		"data/nested/a.b": []byte("x"),
+	}
+
+	var paths []string
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, content, 0o640))
+		paths = append(paths, name)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	return root, paths, ed25519Signer{priv}, ed25519Verifier{pub}
+}
+
+func newCipher(t *testing.T) *des.DES {
+	return des.NewDES()
+}
+
+func TestCreateAndExtract(t *testing.T) {
+	ctx := context.Background()
+	root, paths, signer, verifier := newFixture(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, Create(ctx, &buf, newCipher(t), key, signer, root, paths))
+
+	out := t.TempDir()
+	require.NoError(t, Extract(ctx, bytes.NewReader(buf.Bytes()), newCipher(t), key, verifier, out))
+
+	for _, path := range paths {
+		want, err := os.ReadFile(filepath.Join(root, path))
+		require.NoError(t, err)
+		got, err := os.ReadFile(filepath.Join(out, path))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, path)
+
+		info, err := os.Stat(filepath.Join(out, path))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	}
+}
+
+func TestStreamingRead(t *testing.T) {
+	ctx := context.Background()
+	root, paths, signer, verifier := newFixture(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, Create(ctx, &buf, newCipher(t), key, signer, root, paths))
+
+	archive, err := Open(ctx, &buf, newCipher(t), key, verifier)
+	require.NoError(t, err)
+	assert.Len(t, archive.Manifest().Entries, len(paths))
+
+	count := 0
+	for {
+		entry, content, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		count++
+
+		if entry.Path == "readme.txt" {
+			data, err := io.ReadAll(content)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("hello archive"), data)
+		}
+	}
+	assert.Equal(t, len(paths), count)
+}
+
+func TestTamperedManifest(t *testing.T) {
+	ctx := context.Background()
+	root, paths, signer, verifier := newFixture(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, Create(ctx, &buf, newCipher(t), key, signer, root, paths))
+
+	// magic || len || "null" stanzas || len || sealed manifest
+	data := buf.Bytes()
+	data[len(magic)+4+len("null")+4+newCipher(t).BlockSize()] ^= 0x01
+
+	_, err := Open(ctx, bytes.NewReader(data), newCipher(t), key, verifier)
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+}
+
+func TestManifestEncrypted(t *testing.T) {
+	ctx := context.Background()
+	root, paths, signer, verifier := newFixture(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, Create(ctx, &buf, newCipher(t), key, signer, root, paths))
+	assert.False(t, bytes.Contains(buf.Bytes(), []byte("readme.txt")))
+	assert.False(t, bytes.Contains(buf.Bytes(), []byte("hello archive")))
+
+	_, err := Open(ctx, bytes.NewReader(buf.Bytes()), newCipher(t), []byte("76543210"), verifier)
+	assert.ErrorIs(t, err, errors.ErrInvalidMAC)
+}
+
+func TestTamperedPayload(t *testing.T) {
+	ctx := context.Background()
+	root, _, signer, verifier := newFixture(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, Create(ctx, &buf, newCipher(t), key, signer, root, []string{"readme.txt"}))
+
+	for _, offset := range []int{TagSize + 8, 8} {
+		data := bytes.Clone(buf.Bytes())
+		data[len(data)-offset] ^= 0x01
+
+		out := t.TempDir()
+		err := Extract(ctx, bytes.NewReader(data), newCipher(t), key, verifier, out)
+		assert.ErrorIs(t, err, errors.ErrInvalidMAC)
+		assert.NoFileExists(t, filepath.Join(out, "readme.txt"))
+	}
+
+	truncated := buf.Bytes()[:buf.Len()-1]
+	err := Extract(ctx, bytes.NewReader(truncated), newCipher(t), key, verifier, t.TempDir())
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
+
+func TestUnsafePath(t *testing.T) {
+	ctx := context.Background()
+	root, _, signer, _ := newFixture(t)
+
+	err := Create(ctx, io.Discard, newCipher(t), key, signer, filepath.Join(root, "data"), []string{"../readme.txt"})
+	assert.ErrorIs(t, err, errors.ErrUnsafePath)
+}
@@ -0,0 +1,304 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/mac/hmac"
+	"github.com/masterkusok/crypto/sign"
+)
+
+type Reader struct {
+	ctx      context.Context
+	r        io.Reader
+	manifest *Manifest
+	stream   *keystream
+	next     int
+	current  *entryReader
+}
+
+func Open(ctx context.Context, r io.Reader, block cipher.BlockCipher, key []byte, verifier sign.Verifier) (*Reader, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(ctx, r, h, block, key, verifier)
+}
+
+func OpenWithIdentity(ctx context.Context, r io.Reader, block cipher.BlockCipher, identity Identity, verifier sign.Verifier) (*Reader, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := unwrapAny(identity, h.stanzas)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(ctx, r, h, block, key, verifier)
+}
+
+func open(ctx context.Context, r io.Reader, h *header, block cipher.BlockCipher, key []byte, verifier sign.Verifier) (*Reader, error) {
+	if err := h.verify(verifier); err != nil {
+		return nil, err
+	}
+
+	if err := block.SetKey(ctx, key); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+
+	macKey := deriveMACKey(key)
+	encoded, err := openManifest(ctx, block, macKey, h.manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(encoded, &manifest); err != nil {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "decoding manifest: %w")
+	}
+	if manifest.Version != Version || len(manifest.IV) != block.BlockSize() {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	return &Reader{
+		ctx:      ctx,
+		r:        r,
+		manifest: &manifest,
+		stream:   newKeystream(block, manifest.IV, macKey),
+	}, nil
+}
+
+func readHeader(r io.Reader) (*header, error) {
+	prefix := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, prefix); err != nil || !bytes.Equal(prefix, magic) {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	blobs := make([][]byte, 3)
+	for i := range blobs {
+		blob, err := readBlob(r)
+		if err != nil {
+			return nil, err
+		}
+		blobs[i] = blob
+	}
+
+	h := &header{rawStanzas: blobs[0], manifest: blobs[1], signature: blobs[2]}
+	if err := json.Unmarshal(blobs[0], &h.stanzas); err != nil {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "decoding recipients: %w")
+	}
+
+	return h, nil
+}
+
+func readBlob(r io.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, errors.ErrInvalidFormat
+	}
+	if size > maxHeaderSize {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	blob := make([]byte, size)
+	if _, err := io.ReadFull(r, blob); err != nil {
+		return nil, errors.ErrInvalidFormat
+	}
+	return blob, nil
+}
+
+func (r *Reader) Manifest() *Manifest {
+	return r.manifest
+}
+
+func (r *Reader) Next() (*Entry, io.Reader, error) {
+	if r.current != nil {
+		if _, err := io.Copy(io.Discard, r.current); err != nil {
+			return nil, nil, err
+		}
+		r.current = nil
+	}
+
+	if r.next >= len(r.manifest.Entries) {
+		return nil, nil, io.EOF
+	}
+
+	entry := &r.manifest.Entries[r.next]
+	r.next++
+
+	r.current = &entryReader{
+		reader:    r,
+		entry:     entry,
+		counter:   entry.Offset,
+		remaining: entry.Size,
+		cipherLen: paddedSize(entry.Size, r.stream.block.BlockSize()),
+		mac:       r.stream.entryMAC(entry),
+	}
+
+	return entry, r.current, nil
+}
+
+// drain reads every remaining entry, checking its tag.
+func (r *Reader) drain() error {
+	for {
+		_, content, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(io.Discard, content); err != nil {
+			return err
+		}
+	}
+}
+
+// rewind restarts the entries from the first one, reading the payload from
+// src.
+func (r *Reader) rewind(src io.Reader) {
+	r.r = src
+	r.next = 0
+	r.current = nil
+}
+
+type entryReader struct {
+	reader    *Reader
+	entry     *Entry
+	counter   uint64
+	remaining int64
+	cipherLen int64
+	buf       []byte
+	mac       *hmac.HMAC
+	err       error
+}
+
+func (e *entryReader) Read(p []byte) (int, error) {
+	for len(e.buf) == 0 {
+		if e.err != nil {
+			return 0, e.err
+		}
+		if e.remaining == 0 {
+			e.err = e.verify()
+			continue
+		}
+		if err := e.fill(); err != nil {
+			e.err = err
+		}
+	}
+
+	n := copy(p, e.buf)
+	e.buf = e.buf[n:]
+	return n, nil
+}
+
+func (e *entryReader) fill() error {
+	blockSize := e.reader.stream.block.BlockSize()
+	chunk := make([]byte, min(int64(ChunkSize), e.cipherLen))
+	if _, err := io.ReadFull(e.reader.r, chunk); err != nil {
+		return errors.Annotate(errors.ErrInvalidFormat, "truncated entry: %w")
+	}
+	e.cipherLen -= int64(len(chunk))
+	e.mac.Write(chunk)
+
+	plaintext, err := e.reader.stream.xor(e.reader.ctx, e.counter, chunk)
+	if err != nil {
+		return err
+	}
+	e.counter += uint64(len(chunk) / blockSize)
+
+	plaintext = plaintext[:min(int64(len(plaintext)), e.remaining)]
+	e.remaining -= int64(len(plaintext))
+	e.buf = plaintext
+
+	return nil
+}
+
+// verify reads the entry tag and reports io.EOF only if it authenticates the
+// ciphertext. Content already returned must be discarded on any other error.
+func (e *entryReader) verify() error {
+	tag := make([]byte, TagSize)
+	if _, err := io.ReadFull(e.reader.r, tag); err != nil {
+		return errors.Annotate(errors.ErrInvalidFormat, "truncated entry: %w")
+	}
+	if err := e.mac.Verify(tag); err != nil {
+		return errors.Annotate(err, "%s: %w", e.entry.Path)
+	}
+	return io.EOF
+}
+
+func Extract(ctx context.Context, r io.Reader, block cipher.BlockCipher, key []byte, verifier sign.Verifier, dir string) error {
+	archive, err := Open(ctx, r, block, key, verifier)
+	if err != nil {
+		return err
+	}
+	return archive.ExtractAll(dir)
+}
+
+func ExtractWithIdentity(ctx context.Context, r io.Reader, block cipher.BlockCipher, identity Identity, verifier sign.Verifier, dir string) error {
+	archive, err := OpenWithIdentity(ctx, r, block, identity, verifier)
+	if err != nil {
+		return err
+	}
+	return archive.ExtractAll(dir)
+}
+
+func (r *Reader) ExtractAll(dir string) error {
+	for {
+		entry, content, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := extractEntry(dir, entry, content); err != nil {
+			return err
+		}
+	}
+}
+
+func extractEntry(dir string, entry *Entry, content io.Reader) error {
+	if !fs.ValidPath(entry.Path) {
+		return errors.Annotate(errors.ErrUnsafePath, "%s: %w", entry.Path)
+	}
+
+	target := filepath.Join(dir, filepath.FromSlash(entry.Path))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return errors.Annotate(err, "creating directory: %w")
+	}
+
+	tmp := target + ".partial"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, entry.Mode.Perm())
+	if err != nil {
+		return errors.Annotate(err, "creating file: %w")
+	}
+
+	if _, err := io.Copy(out, content); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return errors.Annotate(err, "closing file: %w")
+	}
+
+	if err := os.Rename(tmp, target); err != nil {
+		return errors.Annotate(err, "renaming file: %w")
+	}
+
+	return os.Chtimes(target, entry.ModTime, entry.ModTime)
+}
@@ -0,0 +1,353 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/subtle"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/sign"
+)
+
+const (
+	stanzaRSA = "rsa"
+	stanzaDH  = "dh"
+
+	maxDataKeySize = sha256.Size
+	fingerprintLen = 8
+
+	oaepHash = crypto.SHA256
+)
+
+type Stanza struct {
+	Type string   `json:"type"`
+	ID   []byte   `json:"id"`
+	Args [][]byte `json:"args,omitempty"`
+	Body []byte   `json:"body"`
+}
+
+type Recipient interface {
+	Wrap(dataKey []byte) (*Stanza, error)
+}
+
+type Identity interface {
+	Unwrap(stanza *Stanza) ([]byte, error)
+}
+
+func wrapDataKey(dataKey []byte, recipients []Recipient) ([]Stanza, error) {
+	stanzas := make([]Stanza, 0, len(recipients))
+	for _, recipient := range recipients {
+		stanza, err := recipient.Wrap(dataKey)
+		if err != nil {
+			return nil, errors.Annotate(err, "wrapping data key: %w")
+		}
+		stanzas = append(stanzas, *stanza)
+	}
+	return stanzas, nil
+}
+
+func unwrapAny(identity Identity, stanzas []Stanza) ([]byte, error) {
+	for i := range stanzas {
+		key, err := identity.Unwrap(&stanzas[i])
+		if err == nil {
+			return key, nil
+		}
+		if err != errors.ErrNoMatchingRecipient {
+			return nil, err
+		}
+	}
+	return nil, errors.ErrNoMatchingRecipient
+}
+
+// label is the stanza header, used as the OAEP label so that a wrapped key
+// only unwraps under the type and recipient it was issued for.
+func (s *Stanza) label() []byte {
+	return append(append([]byte(s.Type), 0), s.ID...)
+}
+
+func fingerprint(parts ...*big.Int) []byte {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p.Bytes())
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)[:fingerprintLen]
+}
+
+type RSARecipient struct {
+	pub *rsa.PublicKey
+}
+
+func NewRSARecipient(pub *rsa.PublicKey) *RSARecipient {
+	return &RSARecipient{pub: pub}
+}
+
+func (r *RSARecipient) Wrap(dataKey []byte) (*Stanza, error) {
+	stanza := &Stanza{Type: stanzaRSA, ID: fingerprint(r.pub.N, r.pub.E)}
+
+	body, err := rsa.EncryptOAEP(r.pub, oaepHash, dataKey, stanza.label())
+	if err != nil {
+		return nil, err
+	}
+	stanza.Body = body
+	return stanza, nil
+}
+
+type RSAIdentity struct {
+	priv *rsa.PrivateKey
+}
+
+func NewRSAIdentity(priv *rsa.PrivateKey) *RSAIdentity {
+	return &RSAIdentity{priv: priv}
+}
+
+func (i *RSAIdentity) ID() []byte {
+	return fingerprint(i.priv.N, i.priv.E)
+}
+
+func (i *RSAIdentity) Unwrap(stanza *Stanza) ([]byte, error) {
+	if stanza.Type != stanzaRSA || !bytes.Equal(stanza.ID, i.ID()) {
+		return nil, errors.ErrNoMatchingRecipient
+	}
+
+	dataKey, err := rsa.DecryptOAEP(i.priv, oaepHash, stanza.Body, stanza.label())
+	if err != nil {
+		return nil, err
+	}
+	if len(dataKey) == 0 || len(dataKey) > maxDataKeySize {
+		return nil, errors.ErrInvalidFormat
+	}
+	return dataKey, nil
+}
+
+type DHRecipient struct {
+	pub *dh.PublicKey
+}
+
+func NewDHRecipient(pub *dh.PublicKey) *DHRecipient {
+	return &DHRecipient{pub: pub}
+}
+
+func (r *DHRecipient) Wrap(dataKey []byte) (*Stanza, error) {
+	if len(dataKey) > maxDataKeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	ephemeralPriv, ephemeralPub, err := dh.GenerateKey(r.pub.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := dh.ComputeSharedSecret(ephemeralPriv, r.pub)
+	if err != nil {
+		return nil, err
+	}
+
+	pad, check := deriveKEK(secret, ephemeralPub.Y)
+	body := make([]byte, len(dataKey))
+	subtle.XORBytes(body, dataKey, pad[:len(dataKey)])
+
+	return &Stanza{
+		Type: stanzaDH,
+		ID:   fingerprint(r.pub.Params.P, r.pub.Y),
+		Args: [][]byte{ephemeralPub.Y.Bytes(), check},
+		Body: body,
+	}, nil
+}
+
+type DHIdentity struct {
+	priv *dh.PrivateKey
+	pub  *dh.PublicKey
+}
+
+func NewDHIdentity(priv *dh.PrivateKey, pub *dh.PublicKey) *DHIdentity {
+	return &DHIdentity{priv: priv, pub: pub}
+}
+
+func (i *DHIdentity) ID() []byte {
+	return fingerprint(i.pub.Params.P, i.pub.Y)
+}
+
+func (i *DHIdentity) Unwrap(stanza *Stanza) ([]byte, error) {
+	if stanza.Type != stanzaDH || !bytes.Equal(stanza.ID, i.ID()) {
+		return nil, errors.ErrNoMatchingRecipient
+	}
+	if len(stanza.Args) != 2 || len(stanza.Body) > maxDataKeySize {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	ephemeral := &dh.PublicKey{Params: i.priv.Params, Y: new(big.Int).SetBytes(stanza.Args[0])}
+	secret, err := dh.ComputeSharedSecret(i.priv, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	pad, check := deriveKEK(secret, ephemeral.Y)
+	if subtle.ConstantTimeCompare(check, stanza.Args[1]) != 1 {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	dataKey := make([]byte, len(stanza.Body))
+	subtle.XORBytes(dataKey, stanza.Body, pad[:len(stanza.Body)])
+	return dataKey, nil
+}
+
+func deriveKEK(secret, ephemeral *big.Int) ([]byte, []byte) {
+	kek := sha256.Sum256(append(secret.Bytes(), ephemeral.Bytes()...))
+	pad := sha256.Sum256(append(kek[:], "wrap"...))
+	check := sha256.Sum256(append(kek[:], "check"...))
+	return pad[:], check[:16]
+}
+
+// AddRecipient wraps the data key, unwrapped with identity, for one more
+// recipient. The stanzas are covered by the signature, so the header is
+// checked with verifier and signed again with signer.
+func AddRecipient(r io.Reader, w io.Writer, identity Identity, verifier sign.Verifier, signer sign.Signer, recipient Recipient) error {
+	h, err := readHeader(r)
+	if err != nil {
+		return err
+	}
+	if err := h.verify(verifier); err != nil {
+		return err
+	}
+
+	dataKey, err := unwrapAny(identity, h.stanzas)
+	if err != nil {
+		return err
+	}
+
+	stanza, err := recipient.Wrap(dataKey)
+	if err != nil {
+		return errors.Annotate(err, "wrapping data key: %w")
+	}
+	h.stanzas = append(h.stanzas, *stanza)
+	if err := h.sign(signer); err != nil {
+		return err
+	}
+
+	return rewriteHeader(r, w, h)
+}
+
+// RemoveRecipient revokes the recipient with the given ID. Dropping its
+// stanza is not enough, since the revoked recipient may have kept the data
+// key, so the archive is re-encrypted under a fresh data key wrapped only for
+// remaining. The archive is read with source, keyed by the identity's data
+// key, and written with target, a second instance of the same cipher. The
+// rewritten manifest is signed with signer after the original passed
+// verifier.
+func RemoveRecipient(ctx context.Context, r io.Reader, w io.Writer, source, target cipher.BlockCipher, identity Identity, verifier sign.Verifier, signer sign.Signer, id []byte, remaining ...Recipient) error {
+	if len(remaining) == 0 || source.BlockSize() != target.BlockSize() {
+		return errors.ErrInvalidParameters
+	}
+
+	h, err := readHeader(r)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, s := range h.stanzas {
+		found = found || bytes.Equal(s.ID, id)
+	}
+	if !found {
+		return errors.ErrNoMatchingRecipient
+	}
+
+	oldKey, err := unwrapAny(identity, h.stanzas)
+	if err != nil {
+		return err
+	}
+
+	archive, err := open(ctx, r, h, source, oldKey, verifier)
+	if err != nil {
+		return err
+	}
+
+	dataKey := make([]byte, len(oldKey))
+	if _, err := rand.Read(dataKey); err != nil {
+		return errors.Annotate(err, "generating data key: %w")
+	}
+
+	stanzas, err := wrapDataKey(dataKey, remaining)
+	if err != nil {
+		return err
+	}
+	for _, s := range stanzas {
+		if bytes.Equal(s.ID, id) {
+			return errors.Annotate(errors.ErrInvalidParameters, "removed recipient is still listed: %w")
+		}
+	}
+
+	return rekey(ctx, w, archive, target, dataKey, stanzas, signer)
+}
+
+// rekey re-encrypts every entry of archive under dataKey with a fresh IV.
+// The source payload is spooled to a temporary file while every entry tag is
+// checked, and nothing is written until all of them pass, so a tampered
+// archive fails instead of being partly re-authenticated under the new key.
+func rekey(ctx context.Context, w io.Writer, archive *Reader, block cipher.BlockCipher, dataKey []byte, stanzas []Stanza, signer sign.Signer) error {
+	spool, err := os.CreateTemp("", "mkarch-rekey-*")
+	if err != nil {
+		return errors.Annotate(err, "creating spool: %w")
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	archive.r = io.TeeReader(archive.r, spool)
+	if err := archive.drain(); err != nil {
+		return err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return errors.Annotate(err, "rewinding spool: %w")
+	}
+	archive.rewind(spool)
+
+	if err := block.SetKey(ctx, dataKey); err != nil {
+		return errors.Annotate(err, "failed to set key: %w")
+	}
+
+	manifest := *archive.manifest
+	manifest.IV = make([]byte, block.BlockSize())
+	if _, err := rand.Read(manifest.IV); err != nil {
+		return errors.Annotate(err, "generating IV: %w")
+	}
+
+	macKey := deriveMACKey(dataKey)
+	if err := writeSealedHeader(ctx, w, block, macKey, stanzas, &manifest, signer); err != nil {
+		return err
+	}
+
+	stream := newKeystream(block, manifest.IV, macKey)
+	for {
+		entry, content, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := writeEntry(ctx, w, stream, content, entry); err != nil {
+			return errors.Annotate(err, "%s: %w", entry.Path)
+		}
+	}
+}
+
+func rewriteHeader(r io.Reader, w io.Writer, h *header) error {
+	if err := writeHeader(w, h); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return errors.Annotate(err, "copying payload: %w")
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRSAIdentity(t *testing.T) (*RSARecipient, *RSAIdentity) {
+	r := rsa.NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 1024)
+	require.NoError(t, r.GenerateKeyPair())
+	return NewRSARecipient(r.GetPublicKey()), NewRSAIdentity(r.GetPrivateKey())
+}
+
+func newDHIdentity(t *testing.T, params *dh.Parameters) (*DHRecipient, *DHIdentity) {
+	priv, pub, err := dh.GenerateKey(params)
+	require.NoError(t, err)
+	return NewDHRecipient(pub), NewDHIdentity(priv, pub)
+}
+
+func newDHParams(t *testing.T) *dh.Parameters {
+	params, err := dh.GenerateParameters(128, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+	return params
+}
+
+func assertExtracted(t *testing.T, root, out string, paths []string) {
+	for _, path := range paths {
+		want, err := os.ReadFile(filepath.Join(root, path))
+		require.NoError(t, err)
+		got, err := os.ReadFile(filepath.Join(out, path))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, path)
+	}
+}
+
+func TestMultipleRecipients(t *testing.T) {
+	ctx := context.Background()
+	root, paths, signer, verifier := newFixture(t)
+	params := newDHParams(t)
+
+	rsaRecipient, rsaIdentity := newRSAIdentity(t)
+	dhRecipient, dhIdentity := newDHIdentity(t, params)
+
+	var buf bytes.Buffer
+	require.NoError(t, CreateForRecipients(ctx, &buf, newCipher(t), len(key), signer, root, paths, rsaRecipient, dhRecipient))
+
+	for _, identity := range []Identity{rsaIdentity, dhIdentity} {
+		out := t.TempDir()
+		require.NoError(t, ExtractWithIdentity(ctx, bytes.NewReader(buf.Bytes()), newCipher(t), identity, verifier, out))
+		assertExtracted(t, root, out, paths)
+	}
+
+	_, stranger := newDHIdentity(t, params)
+	_, err := OpenWithIdentity(ctx, bytes.NewReader(buf.Bytes()), newCipher(t), stranger, verifier)
+	assert.ErrorIs(t, err, errors.ErrNoMatchingRecipient)
+}
+
+func TestAddAndRemoveRecipient(t *testing.T) {
+	ctx := context.Background()
+	root, paths, signer, verifier := newFixture(t)
+	params := newDHParams(t)
+
+	ownerRecipient, owner := newDHIdentity(t, params)
+	guestRecipient, guest := newDHIdentity(t, params)
+
+	var original bytes.Buffer
+	require.NoError(t, CreateForRecipients(ctx, &original, newCipher(t), len(key), signer, root, paths, ownerRecipient))
+
+	_, err := OpenWithIdentity(ctx, bytes.NewReader(original.Bytes()), newCipher(t), guest, verifier)
+	require.ErrorIs(t, err, errors.ErrNoMatchingRecipient)
+
+	var added bytes.Buffer
+	require.NoError(t, AddRecipient(bytes.NewReader(original.Bytes()), &added, owner, verifier, signer, guestRecipient))
+
+	out := t.TempDir()
+	require.NoError(t, ExtractWithIdentity(ctx, bytes.NewReader(added.Bytes()), newCipher(t), guest, verifier, out))
+	assertExtracted(t, root, out, paths)
+
+	var removed bytes.Buffer
+	require.NoError(t, RemoveRecipient(ctx, bytes.NewReader(added.Bytes()), &removed, newCipher(t), newCipher(t), owner, verifier, signer, guest.ID(), ownerRecipient))
+
+	_, err = OpenWithIdentity(ctx, bytes.NewReader(removed.Bytes()), newCipher(t), guest, verifier)
+	assert.ErrorIs(t, err, errors.ErrNoMatchingRecipient)
+
+	out = t.TempDir()
+	require.NoError(t, ExtractWithIdentity(ctx, bytes.NewReader(removed.Bytes()), newCipher(t), owner, verifier, out))
+	assertExtracted(t, root, out, paths)
+
+	// A data key kept by the removed recipient no longer opens the archive.
+	h, err := readHeader(bytes.NewReader(added.Bytes()))
+	require.NoError(t, err)
+	guestKey, err := unwrapAny(guest, h.stanzas)
+	require.NoError(t, err)
+	_, err = Open(ctx, bytes.NewReader(removed.Bytes()), newCipher(t), guestKey, verifier)
+	assert.ErrorIs(t, err, errors.ErrInvalidMAC)
+
+	err = RemoveRecipient(ctx, bytes.NewReader(removed.Bytes()), &bytes.Buffer{}, newCipher(t), newCipher(t), owner, verifier, signer, owner.ID())
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+	err = RemoveRecipient(ctx, bytes.NewReader(added.Bytes()), &bytes.Buffer{}, newCipher(t), newCipher(t), owner, verifier, signer, guest.ID(), ownerRecipient, guestRecipient)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+	err = RemoveRecipient(ctx, bytes.NewReader(removed.Bytes()), &bytes.Buffer{}, newCipher(t), newCipher(t), owner, verifier, signer, guest.ID(), ownerRecipient)
+	assert.ErrorIs(t, err, errors.ErrNoMatchingRecipient)
+}
+
+func TestRemoveRecipientTampered(t *testing.T) {
+	ctx := context.Background()
+	root, paths, signer, verifier := newFixture(t)
+	params := newDHParams(t)
+
+	ownerRecipient, owner := newDHIdentity(t, params)
+	guestRecipient, guest := newDHIdentity(t, params)
+
+	var buf bytes.Buffer
+	require.NoError(t, CreateForRecipients(ctx, &buf, newCipher(t), len(key), signer, root, paths, ownerRecipient, guestRecipient))
+
+	data := buf.Bytes()
+	data[len(data)-TagSize-1] ^= 0x01
+
+	// Only the last entry is damaged; nothing may be written before it is
+	// checked.
+	var out bytes.Buffer
+	err := RemoveRecipient(ctx, bytes.NewReader(data), &out, newCipher(t), newCipher(t), owner, verifier, signer, guest.ID(), ownerRecipient)
+	assert.ErrorIs(t, err, errors.ErrInvalidMAC)
+	assert.Zero(t, out.Len())
+}
+
+func TestStanzasSigned(t *testing.T) {
+	ctx := context.Background()
+	root, paths, signer, verifier := newFixture(t)
+	params := newDHParams(t)
+
+	ownerRecipient, owner := newDHIdentity(t, params)
+	guestRecipient, guest := newDHIdentity(t, params)
+
+	var buf bytes.Buffer
+	require.NoError(t, CreateForRecipients(ctx, &buf, newCipher(t), len(key), signer, root, paths, ownerRecipient))
+
+	r := bytes.NewReader(buf.Bytes())
+	h, err := readHeader(r)
+	require.NoError(t, err)
+	dataKey, err := unwrapAny(owner, h.stanzas)
+	require.NoError(t, err)
+	stanza, err := guestRecipient.Wrap(dataKey)
+	require.NoError(t, err)
+
+	// A stanza spliced in without a new signature is rejected.
+	h.stanzas = append(h.stanzas, *stanza)
+	h.rawStanzas, err = json.Marshal(h.stanzas)
+	require.NoError(t, err)
+	var spliced bytes.Buffer
+	require.NoError(t, rewriteHeader(r, &spliced, h))
+
+	_, err = OpenWithIdentity(ctx, bytes.NewReader(spliced.Bytes()), newCipher(t), guest, verifier)
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+	err = AddRecipient(bytes.NewReader(spliced.Bytes()), io.Discard, owner, verifier, signer, guestRecipient)
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+}
+
+func TestRSAStanza(t *testing.T) {
+	recipient, identity := newRSAIdentity(t)
+	dataKey := []byte("0123456789abcdef")
+
+	first, err := recipient.Wrap(dataKey)
+	require.NoError(t, err)
+	second, err := recipient.Wrap(dataKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Body, second.Body)
+
+	unwrapped, err := identity.Unwrap(first)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	// The stanza header is the OAEP label.
+	relabeled := *first
+	relabeled.ID = []byte("other id")
+	_, err = rsa.DecryptOAEP(identity.priv, oaepHash, relabeled.Body, relabeled.label())
+	assert.ErrorIs(t, err, errors.ErrDecryptionFailed)
+
+	tampered := *first
+	tampered.Body = bytes.Clone(first.Body)
+	tampered.Body[0] ^= 0x01
+	_, err = identity.Unwrap(&tampered)
+	assert.ErrorIs(t, err, errors.ErrDecryptionFailed)
+}
@@ -0,0 +1,65 @@
+package bitflip
+
+import (
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Opener func(ciphertext []byte) ([]byte, error)
+
+func FlipCBC(iv, ciphertext []byte, blockSize, offset int, known, desired []byte) ([]byte, []byte, error) {
+	if blockSize <= 0 || len(iv) != blockSize {
+		return nil, nil, errors.ErrInvalidIVSize
+	}
+	if err := checkRange(ciphertext, offset, known, desired); err != nil {
+		return nil, nil, err
+	}
+	if offset/blockSize != (offset+len(known)-1)/blockSize {
+		return nil, nil, errors.ErrInvalidDataLength
+	}
+
+	forgedIV := append([]byte{}, iv...)
+	forged := append([]byte{}, ciphertext...)
+
+	target := forged
+	base := offset - blockSize
+	if offset < blockSize {
+		target = forgedIV
+		base = offset
+	}
+
+	for i := range known {
+		target[base+i] ^= known[i] ^ desired[i]
+	}
+
+	return forgedIV, forged, nil
+}
+
+func FlipCTR(ciphertext []byte, offset int, known, desired []byte) ([]byte, error) {
+	if err := checkRange(ciphertext, offset, known, desired); err != nil {
+		return nil, err
+	}
+
+	forged := append([]byte{}, ciphertext...)
+	for i := range known {
+		forged[offset+i] ^= known[i] ^ desired[i]
+	}
+
+	return forged, nil
+}
+
+func AssertRejected(open Opener, tampered []byte) error {
+	if _, err := open(tampered); err != nil {
+		return nil
+	}
+	return errors.ErrTamperingUndetected
+}
+
+func checkRange(ciphertext []byte, offset int, known, desired []byte) error {
+	if len(known) != len(desired) || len(known) == 0 {
+		return errors.ErrInvalidParameters
+	}
+	if offset < 0 || offset+len(known) > len(ciphertext) {
+		return errors.ErrInvalidDataLength
+	}
+	return nil
+}
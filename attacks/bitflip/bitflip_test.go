@@ -0,0 +1,131 @@
+package bitflip
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDES(t *testing.T) cipher.BlockCipher {
+	block := des.NewDES()
+	require.NoError(t, block.SetKey(context.Background(), []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}))
+	return block
+}
+
+func TestFlipCBC(t *testing.T) {
+	ctx := context.Background()
+	block := newDES(t)
+	mode := &cipher.CBCMode{}
+	iv := []byte("initvect")
+	plaintext := []byte("comment=xxxxxxx;role=usr")
+
+	ciphertext, err := mode.Encrypt(ctx, block, plaintext, iv)
+	require.NoError(t, err)
+
+	offset := bytes.Index(plaintext, []byte("usr"))
+	forgedIV, forged, err := FlipCBC(iv, ciphertext, 8, offset, []byte("usr"), []byte("adm"))
+	require.NoError(t, err)
+
+	decrypted, err := mode.Decrypt(ctx, block, forged, forgedIV)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("role=adm"), decrypted[16:])
+	assert.NotEqual(t, plaintext[8:16], decrypted[8:16])
+	assert.Equal(t, plaintext[:8], decrypted[:8])
+}
+
+func TestFlipCBCFirstBlockUsesIV(t *testing.T) {
+	ctx := context.Background()
+	block := newDES(t)
+	mode := &cipher.CBCMode{}
+	iv := []byte("initvect")
+	plaintext := []byte("pay=0010")
+
+	ciphertext, err := mode.Encrypt(ctx, block, plaintext, iv)
+	require.NoError(t, err)
+
+	forgedIV, forged, err := FlipCBC(iv, ciphertext, 8, 4, []byte("0010"), []byte("9999"))
+	require.NoError(t, err)
+	assert.Equal(t, ciphertext, forged)
+
+	decrypted, err := mode.Decrypt(ctx, block, forged, forgedIV)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("pay=9999"), decrypted)
+}
+
+func TestFlipCTR(t *testing.T) {
+	ctx := context.Background()
+	block := newDES(t)
+	mode := &cipher.CTRMode{}
+	iv := make([]byte, 8)
+	plaintext := []byte("amount=00000100;to=alice")
+
+	ciphertext, err := mode.Encrypt(ctx, block, plaintext, iv)
+	require.NoError(t, err)
+
+	forged, err := FlipCTR(ciphertext, 19, []byte("alice"), []byte("mallo"))
+	require.NoError(t, err)
+
+	decrypted, err := mode.Decrypt(ctx, block, forged, iv)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("amount=00000100;to=mallo"), decrypted)
+}
+
+func TestAssertRejected(t *testing.T) {
+	ctx := context.Background()
+	block := newDES(t)
+	mode := &cipher.CTRMode{}
+	iv := make([]byte, 8)
+	plaintext := []byte("amount=00000100")
+	ciphertext, err := mode.Encrypt(ctx, block, append(plaintext, 0), iv)
+	require.NoError(t, err)
+
+	forged, err := FlipCTR(ciphertext, 7, []byte("0"), []byte("9"))
+	require.NoError(t, err)
+
+	unauthenticated := func(c []byte) ([]byte, error) {
+		return mode.Decrypt(ctx, block, c, iv)
+	}
+	assert.ErrorIs(t, AssertRejected(unauthenticated, forged), errors.ErrTamperingUndetected)
+
+	key := []byte("0123456789abcdef")
+	gcmBlock, err := rijndael.NewRijndael(16, len(key), 0x1B)
+	require.NoError(t, err)
+	gcm, err := aead.NewGCM(ctx, gcmBlock, key)
+	require.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	sealed, err := gcm.Seal(ctx, nonce, plaintext, nil)
+	require.NoError(t, err)
+
+	// GCM encrypts in CTR mode, so the same flip lands on the digit; only
+	// the tag stands in the way.
+	forgedSealed, err := FlipCTR(sealed, 7, []byte("0"), []byte("9"))
+	require.NoError(t, err)
+
+	authenticated := func(c []byte) ([]byte, error) {
+		return gcm.Open(ctx, nonce, c, nil)
+	}
+	_, err = authenticated(forgedSealed)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+	assert.NoError(t, AssertRejected(authenticated, forgedSealed))
+
+	opened, err := authenticated(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+}
+
+func TestFlipInvalidRange(t *testing.T) {
+	_, _, err := FlipCBC(make([]byte, 8), make([]byte, 16), 8, 6, []byte("abcd"), []byte("wxyz"))
+	assert.Error(t, err)
+
+	_, err = FlipCTR(make([]byte, 4), 2, []byte("abc"), []byte("xyz"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package factor
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	DefaultECMBound  = 2000
+	DefaultECMCurves = 200
+)
+
+type ECM struct {
+	B1     int
+	Curves int
+	Rand   io.Reader
+}
+
+var _ Factorizer = (*ECM)(nil)
+
+func NewECM(b1, curves int) *ECM {
+	return &ECM{B1: b1, Curves: curves, Rand: rand.Reader}
+}
+
+func (e *ECM) Factor(ctx context.Context, n *big.Int) (*big.Int, error) {
+	if f, err := checkComposite(n); f != nil || err != nil {
+		return f, err
+	}
+
+	b1, curves, source := e.B1, e.Curves, e.Rand
+	if b1 <= 0 {
+		b1 = DefaultECMBound
+	}
+	if curves <= 0 {
+		curves = DefaultECMCurves
+	}
+	if source == nil {
+		source = rand.Reader
+	}
+
+	primes := smallPrimes(b1)
+
+	for i := 0; i < curves; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		curve, point, err := randomCurve(source, n)
+		if err != nil {
+			return nil, errors.Annotate(err, "failed to select curve: %w")
+		}
+
+		factor, err := curve.stageOne(ctx, point, primes, b1)
+		if err != nil {
+			return nil, err
+		}
+		if factor != nil {
+			return factor, nil
+		}
+	}
+
+	return nil, errors.ErrFactorNotFound
+}
+
+type point struct {
+	x, y *big.Int
+}
+
+type curve struct {
+	a, n *big.Int
+}
+
+func randomCurve(source io.Reader, n *big.Int) (*curve, *point, error) {
+	x, err := rand.Int(source, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	y, err := rand.Int(source, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := rand.Int(source, n)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &curve{a: a, n: n}, &point{x: x, y: y}, nil
+}
+
+func (c *curve) stageOne(ctx context.Context, p *point, primes []int64, b1 int) (*big.Int, error) {
+	for _, prime := range primes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var factor *big.Int
+		p, factor = c.mul(p, big.NewInt(primePower(prime, b1)))
+		if factor != nil {
+			return factor, nil
+		}
+		if p == nil {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (c *curve) mul(p *point, k *big.Int) (*point, *big.Int) {
+	var result *point
+	addend := p
+
+	for i := 0; i < k.BitLen(); i++ {
+		var factor *big.Int
+		if k.Bit(i) == 1 {
+			result, factor = c.add(result, addend)
+			if factor != nil {
+				return nil, factor
+			}
+		}
+		if i+1 < k.BitLen() {
+			addend, factor = c.add(addend, addend)
+			if factor != nil {
+				return nil, factor
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (c *curve) add(p, q *point) (*point, *big.Int) {
+	if p == nil {
+		return q, nil
+	}
+	if q == nil {
+		return p, nil
+	}
+
+	var num, den *big.Int
+	if p.x.Cmp(q.x) == 0 {
+		sum := new(big.Int).Add(p.y, q.y)
+		if sum.Mod(sum, c.n).Sign() == 0 {
+			return nil, nil
+		}
+		num = new(big.Int).Mul(p.x, p.x)
+		num.Mul(num, big.NewInt(3))
+		num.Add(num, c.a)
+		den = new(big.Int).Lsh(p.y, 1)
+	} else {
+		num = new(big.Int).Sub(q.y, p.y)
+		den = new(big.Int).Sub(q.x, p.x)
+	}
+
+	den.Mod(den, c.n)
+	inv := new(big.Int).ModInverse(den, c.n)
+	if inv == nil {
+		g := new(big.Int).GCD(nil, nil, den, c.n)
+		if g.Cmp(c.n) == 0 {
+			return nil, nil
+		}
+		return nil, g
+	}
+
+	lambda := num.Mul(num, inv)
+	lambda.Mod(lambda, c.n)
+
+	x := new(big.Int).Mul(lambda, lambda)
+	x.Sub(x, p.x)
+	x.Sub(x, q.x)
+	x.Mod(x, c.n)
+
+	y := new(big.Int).Sub(p.x, x)
+	y.Mul(y, lambda)
+	y.Sub(y, p.y)
+	y.Mod(y, c.n)
+
+	return &point{x: x, y: y}, nil
+}
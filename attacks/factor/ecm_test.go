@@ -0,0 +1,51 @@
+package factor
+
+import (
+	"context"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECMFindsMediumFactor(t *testing.T) {
+	p := big.NewInt(1000000007)
+	q, _ := new(big.Int).SetString("2305843009213693951", 10)
+	n := new(big.Int).Mul(p, q)
+
+	ecm := NewECM(5000, 500)
+	ecm.Rand = rand.NewChaCha8([32]byte{42})
+
+	factor, err := ecm.Factor(context.Background(), n)
+	require.NoError(t, err)
+
+	assert.Zero(t, new(big.Int).Mod(n, factor).Sign())
+	assert.NotEqual(t, 0, factor.Cmp(big.NewInt(1)))
+	assert.NotEqual(t, 0, factor.Cmp(n))
+}
+
+func TestECMEvenAndPrime(t *testing.T) {
+	ecm := NewECM(0, 0)
+
+	factor, err := ecm.Factor(context.Background(), big.NewInt(1<<20))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), factor)
+
+	_, err = ecm.Factor(context.Background(), big.NewInt(104729))
+	assert.ErrorIs(t, err, errors.ErrFactorNotFound)
+
+	_, err = ecm.Factor(context.Background(), big.NewInt(3))
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
+
+func TestECMCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n := new(big.Int).Mul(big.NewInt(1000000007), big.NewInt(998244353))
+	_, err := NewECM(0, 0).Factor(ctx, n)
+	assert.ErrorIs(t, err, context.Canceled)
+}
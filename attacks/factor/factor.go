@@ -0,0 +1,48 @@
+package factor
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Factorizer interface {
+	Factor(ctx context.Context, n *big.Int) (*big.Int, error)
+}
+
+func checkComposite(n *big.Int) (*big.Int, error) {
+	if n == nil || n.Cmp(big.NewInt(4)) < 0 {
+		return nil, errors.ErrInvalidParameters
+	}
+	if n.Bit(0) == 0 {
+		return big.NewInt(2), nil
+	}
+	if n.ProbablyPrime(20) {
+		return nil, errors.ErrFactorNotFound
+	}
+	return nil, nil
+}
+
+func smallPrimes(bound int) []int64 {
+	sieve := make([]bool, bound+1)
+	var primes []int64
+	for i := 2; i <= bound; i++ {
+		if sieve[i] {
+			continue
+		}
+		primes = append(primes, int64(i))
+		for j := i * i; j <= bound; j += i {
+			sieve[j] = true
+		}
+	}
+	return primes
+}
+
+func primePower(p int64, bound int) int64 {
+	pe := p
+	for pe*p <= int64(bound) {
+		pe *= p
+	}
+	return pe
+}
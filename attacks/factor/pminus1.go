@@ -0,0 +1,143 @@
+package factor
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	DefaultPMinusOneBound = 100000
+
+	// pMinusOneBatch is how many primes are processed between gcds.
+	pMinusOneBatch = 64
+)
+
+// PMinusOne is Pollard's p-1 method. It finds a prime factor p of n when
+// p-1 is B1-smooth, or B1-smooth except for a single prime up to B2, no
+// matter how large p is. RSA primes should therefore not be chosen with
+// smooth p-1.
+type PMinusOne struct {
+	// B1 bounds the prime powers of stage one.
+	B1 int
+	// B2 bounds the single larger prime of stage two; zero means 100*B1
+	// and a value not above B1 skips stage two.
+	B2 int
+}
+
+var _ Factorizer = (*PMinusOne)(nil)
+
+func NewPMinusOne(b1, b2 int) *PMinusOne {
+	return &PMinusOne{B1: b1, B2: b2}
+}
+
+func (m *PMinusOne) Factor(ctx context.Context, n *big.Int) (*big.Int, error) {
+	if f, err := checkComposite(n); f != nil || err != nil {
+		return f, err
+	}
+
+	b1, b2 := m.B1, m.B2
+	if b1 <= 0 {
+		b1 = DefaultPMinusOneBound
+	}
+	if b2 == 0 {
+		b2 = 100 * b1
+	}
+	primes := smallPrimes(max(b1, b2))
+
+	// Stage one: a = 2^M with M the product of all prime powers up to B1.
+	a := big.NewInt(2)
+	stageOne := primes
+	for len(stageOne) > 0 && stageOne[len(stageOne)-1] > int64(b1) {
+		stageOne = stageOne[:len(stageOne)-1]
+	}
+	for start := 0; start < len(stageOne); start += pMinusOneBatch {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		saved := new(big.Int).Set(a)
+		batch := stageOne[start:min(start+pMinusOneBatch, len(stageOne))]
+		for _, prime := range batch {
+			a.Exp(a, big.NewInt(primePower(prime, b1)), n)
+		}
+		factor, overshot := pMinusOneGCD(a, n)
+		if factor != nil {
+			return factor, nil
+		}
+		if overshot {
+			// Every factor was caught in the same batch; retry it prime
+			// by prime to separate them.
+			return pMinusOneReplay(saved, n, batch, b1)
+		}
+	}
+
+	// Stage two: try a^q for every prime q in (B1, B2], stepping between
+	// consecutive primes with cached powers a^gap.
+	stageTwo := primes[len(stageOne):]
+	if len(stageTwo) == 0 {
+		return nil, errors.ErrFactorNotFound
+	}
+	gaps := map[int64]*big.Int{}
+	power := new(big.Int).Exp(a, big.NewInt(stageTwo[0]), n)
+	product, diff := big.NewInt(1), new(big.Int)
+	for i, prime := range stageTwo {
+		if i > 0 {
+			gap := prime - stageTwo[i-1]
+			step, ok := gaps[gap]
+			if !ok {
+				step = new(big.Int).Exp(a, big.NewInt(gap), n)
+				gaps[gap] = step
+			}
+			power.Mul(power, step)
+			power.Mod(power, n)
+		}
+		product.Mul(product, diff.Sub(power, big.NewInt(1)))
+		product.Mod(product, n)
+
+		if (i+1)%pMinusOneBatch == 0 || i == len(stageTwo)-1 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if g := new(big.Int).GCD(nil, nil, product, n); g.Cmp(big.NewInt(1)) != 0 {
+				if g.Cmp(n) == 0 {
+					return nil, errors.ErrFactorNotFound
+				}
+				return g, nil
+			}
+		}
+	}
+
+	return nil, errors.ErrFactorNotFound
+}
+
+// pMinusOneGCD checks gcd(a-1, n); overshot reports that it is n itself.
+func pMinusOneGCD(a, n *big.Int) (*big.Int, bool) {
+	g := new(big.Int).GCD(nil, nil, new(big.Int).Sub(a, big.NewInt(1)), n)
+	switch {
+	case g.Cmp(big.NewInt(1)) == 0:
+		return nil, false
+	case g.Cmp(n) == 0:
+		return nil, true
+	default:
+		return g, false
+	}
+}
+
+func pMinusOneReplay(a, n *big.Int, batch []int64, b1 int) (*big.Int, error) {
+	for _, prime := range batch {
+		pe := primePower(prime, b1)
+		for power := prime; power <= pe; power *= prime {
+			a.Exp(a, big.NewInt(prime), n)
+			factor, overshot := pMinusOneGCD(a, n)
+			if factor != nil {
+				return factor, nil
+			}
+			if overshot {
+				return nil, errors.ErrFactorNotFound
+			}
+		}
+	}
+	return nil, errors.ErrFactorNotFound
+}
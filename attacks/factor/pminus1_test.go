@@ -0,0 +1,67 @@
+package factor
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	mathrand "math/rand/v2"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smoothPrime returns a prime p of about bits bits with p-1 = 2*large*s,
+// where s is a product of distinct odd primes up to bound.
+func smoothPrime(bits int, bound int, large int64) *big.Int {
+	rng := mathrand.New(mathrand.NewChaCha8([32]byte{byte(bits), byte(large)}))
+	primes := smallPrimes(bound)[1:]
+	for {
+		p := big.NewInt(2 * large)
+		for _, i := range rng.Perm(len(primes)) {
+			if p.BitLen() >= bits {
+				break
+			}
+			p.Mul(p, big.NewInt(primes[i]))
+		}
+		p.Add(p, big.NewInt(1))
+		if p.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
+
+func TestPMinusOneStageOne(t *testing.T) {
+	p := smoothPrime(160, 1000, 1)
+	q, err := rand.Prime(rand.Reader, 160)
+	require.NoError(t, err)
+	n := new(big.Int).Mul(p, q)
+
+	factor, err := NewPMinusOne(1000, 1000).Factor(context.Background(), n)
+	require.NoError(t, err)
+	assert.Equal(t, 0, factor.Cmp(p))
+}
+
+func TestPMinusOneStageTwo(t *testing.T) {
+	p := smoothPrime(160, 1000, 100003)
+	q, err := rand.Prime(rand.Reader, 160)
+	require.NoError(t, err)
+	n := new(big.Int).Mul(p, q)
+
+	_, err = NewPMinusOne(1000, 1000).Factor(context.Background(), n)
+	assert.ErrorIs(t, err, errors.ErrFactorNotFound)
+
+	factor, err := NewPMinusOne(1000, 200000).Factor(context.Background(), n)
+	require.NoError(t, err)
+	assert.Equal(t, 0, factor.Cmp(p))
+}
+
+func TestPMinusOneCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n := new(big.Int).Mul(big.NewInt(1000000007), big.NewInt(998244353))
+	_, err := NewPMinusOne(0, 0).Factor(ctx, n)
+	assert.ErrorIs(t, err, context.Canceled)
+}
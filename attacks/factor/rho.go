@@ -0,0 +1,124 @@
+package factor
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	DefaultRhoIterations = 1 << 22
+
+	// rhoBatch is how many differences are multiplied together before
+	// taking a single gcd.
+	rhoBatch = 128
+)
+
+// Rho is Pollard's rho method with Floyd cycle detection. It finds a factor
+// p in about sqrt(p) steps whatever its structure, which makes it the tool
+// for moduli with one small prime factor.
+type Rho struct {
+	// MaxIterations bounds the total number of steps over all restarts.
+	MaxIterations int
+	Rand          io.Reader
+}
+
+var _ Factorizer = (*Rho)(nil)
+
+func NewRho(maxIterations int) *Rho {
+	return &Rho{MaxIterations: maxIterations, Rand: rand.Reader}
+}
+
+func (r *Rho) Factor(ctx context.Context, n *big.Int) (*big.Int, error) {
+	if f, err := checkComposite(n); f != nil || err != nil {
+		return f, err
+	}
+
+	limit, source := r.MaxIterations, r.Rand
+	if limit <= 0 {
+		limit = DefaultRhoIterations
+	}
+	if source == nil {
+		source = rand.Reader
+	}
+
+	for iterations := 0; iterations < limit; {
+		// A walk that closes its cycle without a factor is retried with a
+		// different polynomial x^2 + c and starting point.
+		c, err := rand.Int(source, new(big.Int).Sub(n, big.NewInt(3)))
+		if err != nil {
+			return nil, errors.Annotate(err, "failed to select polynomial: %w")
+		}
+		c.Add(c, big.NewInt(1))
+		x, err := rand.Int(source, n)
+		if err != nil {
+			return nil, errors.Annotate(err, "failed to select starting point: %w")
+		}
+
+		factor, steps, err := rhoWalk(ctx, n, x, c, limit-iterations)
+		if err != nil || factor != nil {
+			return factor, err
+		}
+		iterations += steps
+	}
+
+	return nil, errors.ErrFactorNotFound
+}
+
+func rhoWalk(ctx context.Context, n, x, c *big.Int, limit int) (*big.Int, int, error) {
+	step := func(v *big.Int) {
+		v.Mul(v, v)
+		v.Add(v, c)
+		v.Mod(v, n)
+	}
+
+	y := new(big.Int).Set(x)
+	savedX, savedY := new(big.Int).Set(x), new(big.Int).Set(y)
+	product, diff, g := big.NewInt(1), new(big.Int), new(big.Int)
+
+	for i := 1; i <= limit; i++ {
+		step(x)
+		step(y)
+		step(y)
+		diff.Sub(x, y)
+		product.Mul(product, diff.Abs(diff))
+		product.Mod(product, n)
+		if i%rhoBatch != 0 && i != limit {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, i, err
+		}
+		g.GCD(nil, nil, product, n)
+		if g.Cmp(big.NewInt(1)) == 0 {
+			savedX.Set(x)
+			savedY.Set(y)
+			continue
+		}
+		if g.Cmp(n) != 0 {
+			return g, i, nil
+		}
+
+		// The batch overshot, e.g. because the walk met its cycle: replay
+		// it one step at a time from the last state known to be clean.
+		for j := 0; j < rhoBatch; j++ {
+			step(savedX)
+			step(savedY)
+			step(savedY)
+			g.GCD(nil, nil, diff.Abs(diff.Sub(savedX, savedY)), n)
+			if g.Cmp(big.NewInt(1)) != 0 {
+				break
+			}
+		}
+		if g.Cmp(big.NewInt(1)) != 0 && g.Cmp(n) != 0 {
+			return g, i, nil
+		}
+		return nil, i, nil
+	}
+
+	return nil, limit, nil
+}
@@ -0,0 +1,53 @@
+package factor
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	mathrand "math/rand/v2"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRhoFindsSmallFactor(t *testing.T) {
+	p := big.NewInt(1000003)
+	q, err := rand.Prime(rand.Reader, 256)
+	require.NoError(t, err)
+	n := new(big.Int).Mul(p, q)
+
+	rho := NewRho(0)
+	rho.Rand = mathrand.NewChaCha8([32]byte{7})
+	factor, err := rho.Factor(context.Background(), n)
+	require.NoError(t, err)
+	assert.Equal(t, 0, factor.Cmp(p))
+
+	factor, err = rho.Factor(context.Background(), big.NewInt(1000003*999983))
+	require.NoError(t, err)
+	assert.Zero(t, new(big.Int).Mod(big.NewInt(1000003*999983), factor).Sign())
+	assert.NotEqual(t, 0, factor.Cmp(big.NewInt(1000003*999983)))
+}
+
+func TestRhoIterationLimit(t *testing.T) {
+	p, err := rand.Prime(rand.Reader, 128)
+	require.NoError(t, err)
+	q, err := rand.Prime(rand.Reader, 128)
+	require.NoError(t, err)
+
+	_, err = NewRho(1000).Factor(context.Background(), new(big.Int).Mul(p, q))
+	assert.ErrorIs(t, err, errors.ErrFactorNotFound)
+
+	_, err = NewRho(0).Factor(context.Background(), big.NewInt(104729))
+	assert.ErrorIs(t, err, errors.ErrFactorNotFound)
+}
+
+func TestRhoCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n := new(big.Int).Mul(big.NewInt(1000000007), big.NewInt(998244353))
+	_, err := NewRho(0).Factor(ctx, n)
+	assert.ErrorIs(t, err, context.Canceled)
+}
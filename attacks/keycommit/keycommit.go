@@ -0,0 +1,102 @@
+package keycommit
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+const (
+	gcmBlockSize = 16
+	gcmNonceSize = 12
+)
+
+func GCMCollision(ctx context.Context, block1, block2 cipher.BlockCipher, nonce []byte, blocks int) ([]byte, error) {
+	if block1.BlockSize() != gcmBlockSize || block2.BlockSize() != gcmBlockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	if len(nonce) != gcmNonceSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+	if blocks < 1 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "need at least one free block: %w")
+	}
+
+	h1, mask1, err := gcmSecrets(ctx, block1, nonce)
+	if err != nil {
+		return nil, err
+	}
+	h2, mask2, err := gcmSecrets(ctx, block2, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	lengths := make([]byte, gcmBlockSize)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(blocks*gcmBlockSize)*8)
+	l := toElement(lengths)
+
+	target := xor(xor(mask1, mask2), cryptoMath.GF128Mul(l, xor(h1, h2)))
+	coefficient := xor(power(h1, blocks+1), power(h2, blocks+1))
+	inverse, err := cryptoMath.GF128Inv(coefficient)
+	if err != nil {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "keys share a hash subkey power: %w")
+	}
+
+	ciphertext := make([]byte, blocks*gcmBlockSize)
+	putElement(ciphertext, cryptoMath.GF128Mul(target, inverse))
+
+	tag := xor(mask1, ghash(h1, ciphertext, lengths))
+	out := append(ciphertext, make([]byte, gcmBlockSize)...)
+	putElement(out[len(ciphertext):], tag)
+	return out, nil
+}
+
+func gcmSecrets(ctx context.Context, block cipher.BlockCipher, nonce []byte) ([2]uint64, [2]uint64, error) {
+	h, err := block.Encrypt(ctx, make([]byte, gcmBlockSize))
+	if err != nil {
+		return [2]uint64{}, [2]uint64{}, err
+	}
+
+	j0 := make([]byte, gcmBlockSize)
+	copy(j0, nonce)
+	j0[gcmBlockSize-1] = 1
+	mask, err := block.Encrypt(ctx, j0)
+	if err != nil {
+		return [2]uint64{}, [2]uint64{}, err
+	}
+	return toElement(h), toElement(mask), nil
+}
+
+func ghash(h [2]uint64, parts ...[]byte) [2]uint64 {
+	var y [2]uint64
+	for _, part := range parts {
+		for i := 0; i < len(part); i += gcmBlockSize {
+			y = cryptoMath.GF128Mul(xor(y, toElement(part[i:i+gcmBlockSize])), h)
+		}
+	}
+	return y
+}
+
+func power(x [2]uint64, n int) [2]uint64 {
+	result := [2]uint64{1 << 63, 0}
+	for i := 0; i < n; i++ {
+		result = cryptoMath.GF128Mul(result, x)
+	}
+	return result
+}
+
+func xor(a, b [2]uint64) [2]uint64 {
+	return [2]uint64{a[0] ^ b[0], a[1] ^ b[1]}
+}
+
+func toElement(b []byte) [2]uint64 {
+	return [2]uint64{binary.BigEndian.Uint64(b), binary.BigEndian.Uint64(b[8:])}
+}
+
+func putElement(b []byte, x [2]uint64) {
+	binary.BigEndian.PutUint64(b, x[0])
+	binary.BigEndian.PutUint64(b[8:], x[1])
+}
@@ -0,0 +1,73 @@
+package keycommit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGCM(t *testing.T, key []byte) *aead.GCM {
+	block, err := rijndael.NewRijndael(16, len(key), 0x1B)
+	require.NoError(t, err)
+	g, err := aead.NewGCM(context.Background(), block, key)
+	require.NoError(t, err)
+	return g
+}
+
+func keyedBlock(t *testing.T, key []byte) *rijndael.Rijndael {
+	block, err := rijndael.NewRijndael(16, len(key), 0x1B)
+	require.NoError(t, err)
+	require.NoError(t, block.SetKey(context.Background(), key))
+	return block
+}
+
+func TestGCMCollisionOpensUnderBothKeys(t *testing.T) {
+	ctx := context.Background()
+	key1 := []byte("alice's key 0001")
+	key2 := []byte("mallory's key 02")
+	nonce := []byte("shared nonce")
+
+	for _, blocks := range []int{1, 3} {
+		ciphertext, err := GCMCollision(ctx, keyedBlock(t, key1), keyedBlock(t, key2), nonce, blocks)
+		require.NoError(t, err)
+		assert.Len(t, ciphertext, blocks*16+16)
+
+		plain1, err := newGCM(t, key1).Open(ctx, nonce, ciphertext, nil)
+		require.NoError(t, err)
+		plain2, err := newGCM(t, key2).Open(ctx, nonce, ciphertext, nil)
+		require.NoError(t, err)
+		assert.NotEqual(t, plain1, plain2)
+	}
+}
+
+func TestCommittingAEADRejectsOtherKey(t *testing.T) {
+	ctx := context.Background()
+	nonce := []byte("shared nonce")
+
+	c1, err := aead.NewCommitting(aead.AESGCMName, []byte("alice's key 0001"))
+	require.NoError(t, err)
+	c2, err := aead.NewCommitting(aead.AESGCMName, []byte("mallory's key 02"))
+	require.NoError(t, err)
+
+	sealed, err := c1.Seal(ctx, nonce, []byte("pay bob"), nil)
+	require.NoError(t, err)
+	_, err = c2.Open(ctx, nonce, sealed, nil)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+}
+
+func TestGCMCollisionValidation(t *testing.T) {
+	ctx := context.Background()
+	block := keyedBlock(t, make([]byte, 16))
+
+	_, err := GCMCollision(ctx, block, block, []byte("short"), 1)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+	_, err = GCMCollision(ctx, block, block, make([]byte, 12), 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+	_, err = GCMCollision(ctx, block, block, make([]byte, 12), 1)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
@@ -0,0 +1,128 @@
+package paddingoracle
+
+import (
+	"context"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Oracle func(ctx context.Context, iv, ciphertext []byte) (bool, error)
+
+func NewCBCOracle(block cipher.BlockCipher) Oracle {
+	mode := &cipher.CBCMode{}
+
+	return func(ctx context.Context, iv, ciphertext []byte) (bool, error) {
+		plaintext, err := mode.Decrypt(ctx, block, ciphertext, iv)
+		if err != nil {
+			return false, err
+		}
+		return validPKCS7(plaintext, block.BlockSize()), nil
+	}
+}
+
+// NewAEADOracle answers with whether a decrypts ciphertext || tag, which
+// is how a server holding an authenticated message of the attacker's would
+// leak. Every error counts as a rejection.
+func NewAEADOracle(a cipher.AEAD, tag []byte) Oracle {
+	return func(ctx context.Context, iv, ciphertext []byte) (bool, error) {
+		sealed := append(append([]byte{}, ciphertext...), tag...)
+		_, err := a.Open(ctx, iv, sealed, nil)
+		return err == nil, nil
+	}
+}
+
+func validPKCS7(data []byte, blockSize int) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	padLen := int(data[len(data)-1])
+	if padLen == 0 || padLen > blockSize || padLen > len(data) {
+		return false
+	}
+	for _, b := range data[len(data)-padLen:] {
+		if int(b) != padLen {
+			return false
+		}
+	}
+	return true
+}
+
+func Recover(ctx context.Context, oracle Oracle, blockSize int, iv, ciphertext []byte) ([]byte, error) {
+	if blockSize <= 0 || len(iv) != blockSize {
+		return nil, errors.ErrInvalidIVSize
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%blockSize != 0 {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	plaintext := make([]byte, 0, len(ciphertext))
+	prev := iv
+
+	for start := 0; start < len(ciphertext); start += blockSize {
+		block := ciphertext[start : start+blockSize]
+
+		intermediate, err := recoverIntermediate(ctx, oracle, block)
+		if err != nil {
+			return nil, errors.Annotate(err, "block %d: %w", start/blockSize)
+		}
+
+		for i := range intermediate {
+			plaintext = append(plaintext, intermediate[i]^prev[i])
+		}
+		prev = block
+	}
+
+	return plaintext, nil
+}
+
+func recoverIntermediate(ctx context.Context, oracle Oracle, block []byte) ([]byte, error) {
+	blockSize := len(block)
+	intermediate := make([]byte, blockSize)
+	forged := make([]byte, blockSize)
+
+	for padLen := 1; padLen <= blockSize; padLen++ {
+		pos := blockSize - padLen
+		for j := pos + 1; j < blockSize; j++ {
+			forged[j] = intermediate[j] ^ byte(padLen)
+		}
+
+		found := false
+		for guess := 0; guess < 256 && !found; guess++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			forged[pos] = byte(guess)
+			valid, err := oracle(ctx, forged, block)
+			if err != nil {
+				return nil, err
+			}
+			if !valid {
+				continue
+			}
+
+			if padLen == 1 && pos > 0 {
+				forged[pos-1] ^= 0xFF
+				valid, err = oracle(ctx, forged, block)
+				forged[pos-1] ^= 0xFF
+				if err != nil {
+					return nil, err
+				}
+				if !valid {
+					continue
+				}
+			}
+
+			intermediate[pos] = byte(guess) ^ byte(padLen)
+			found = true
+		}
+
+		if !found {
+			return nil, errors.ErrInvalidDataLength
+		}
+	}
+
+	return intermediate, nil
+}
@@ -0,0 +1,106 @@
+package paddingoracle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverRijndael(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("0123456789abcdef")
+	iv := []byte("fedcba9876543210")
+	secret := []byte("attack at dawn, bring the padding oracle")
+
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+
+	cipherCtx, err := cipher.NewCipherContext(block, key, &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(iv))
+	require.NoError(t, err)
+
+	resultChan, errChan := cipherCtx.EncryptBytes(ctx, secret)
+	var ciphertext []byte
+	select {
+	case ciphertext = <-resultChan:
+	case err := <-errChan:
+		require.NoError(t, err)
+	}
+
+	recovered, err := Recover(ctx, NewCBCOracle(block), block.BlockSize(), iv, ciphertext)
+	require.NoError(t, err)
+
+	unpadded, err := cipher.Unpad(recovered, cipher.PKCS7)
+	require.NoError(t, err)
+	assert.Equal(t, secret, unpadded)
+}
+
+func TestRecoverDES(t *testing.T) {
+	ctx := context.Background()
+	key := []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}
+	iv := make([]byte, 8)
+
+	block := des.NewDES()
+	require.NoError(t, block.SetKey(ctx, key))
+
+	padded, err := cipher.Pad([]byte("short"), 8, cipher.PKCS7)
+	require.NoError(t, err)
+	ciphertext, err := (&cipher.CBCMode{}).Encrypt(ctx, block, padded, iv)
+	require.NoError(t, err)
+
+	recovered, err := Recover(ctx, NewCBCOracle(block), 8, iv, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, padded, recovered)
+}
+
+func TestEncryptThenMACBlocksOracle(t *testing.T) {
+	ctx := context.Background()
+	iv := []byte("fedcba9876543210")
+	secret := []byte("attack at dawn, bring the padding oracle")
+
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	etm, err := aead.NewEncryptThenMAC(block, &cipher.CBCMode{}, cipher.PKCS7, []byte("0123456789abcdef"), sha256.New, []byte("mac key"))
+	require.NoError(t, err)
+
+	sealed, err := etm.Seal(ctx, iv, secret, nil)
+	require.NoError(t, err)
+	ciphertext, tag := sealed[:len(sealed)-etm.Overhead()], sealed[len(sealed)-etm.Overhead():]
+
+	oracle := NewAEADOracle(etm, tag)
+	valid, err := oracle(ctx, iv, ciphertext)
+	require.NoError(t, err)
+	require.True(t, valid, "the untouched message must be accepted")
+
+	queries, accepted := 0, 0
+	counting := func(ctx context.Context, iv, ciphertext []byte) (bool, error) {
+		valid, err := oracle(ctx, iv, ciphertext)
+		queries++
+		if valid {
+			accepted++
+		}
+		return valid, err
+	}
+
+	recovered, err := Recover(ctx, counting, block.BlockSize(), iv, ciphertext)
+	assert.Error(t, err)
+	assert.Nil(t, recovered)
+	assert.Positive(t, queries)
+	assert.Zero(t, accepted, "every forged query must be rejected")
+}
+
+func TestRecoverInvalidInput(t *testing.T) {
+	oracle := func(ctx context.Context, iv, ciphertext []byte) (bool, error) { return false, nil }
+
+	_, err := Recover(context.Background(), oracle, 8, make([]byte, 8), make([]byte, 7))
+	assert.Error(t, err)
+
+	_, err = Recover(context.Background(), oracle, 8, make([]byte, 8), make([]byte, 8))
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package auditlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/sign"
+)
+
+const (
+	TypeEntry  = "entry"
+	TypeAnchor = "anchor"
+
+	DefaultInterval = 100
+)
+
+type Record struct {
+	Type      string    `json:"type"`
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Hash      []byte    `json:"hash"`
+	Signature []byte    `json:"signature,omitempty"`
+}
+
+type Writer struct {
+	mu          sync.Mutex
+	w           io.Writer
+	closer      io.Closer
+	signer      sign.Signer
+	interval    int
+	now         func() time.Time
+	seq         uint64
+	last        []byte
+	sinceAnchor int
+}
+
+func NewWriter(w io.Writer, signer sign.Signer, interval int) (*Writer, error) {
+	if signer == nil {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "signer is required: %w")
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Writer{w: w, signer: signer, interval: interval, now: time.Now, last: make([]byte, sha256.Size)}, nil
+}
+
+func Open(path string, signer sign.Signer, verifier sign.Verifier, interval int) (*Writer, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, errors.Annotate(err, "opening log: %w")
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, errors.Annotate(err, "reading log: %w")
+	}
+
+	w, err := NewWriter(file, signer, interval)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	w.closer = file
+
+	// Only a new, empty log may lack an anchor.
+	if info.Size() == 0 {
+		return w, nil
+	}
+
+	report, err := Verify(file, verifier)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return nil, errors.Annotate(err, "seeking to end of log: %w")
+	}
+	w.seq, w.last, w.sinceAnchor = report.Entries, report.Head, report.Unanchored
+	return w, nil
+}
+
+func (w *Writer) Append(message string) (*Record, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rec := &Record{Type: TypeEntry, Seq: w.seq, Time: w.now().UTC(), Message: message}
+	rec.Hash = entryHash(w.last, rec)
+	if err := w.write(rec); err != nil {
+		return nil, err
+	}
+
+	w.seq++
+	w.last = rec.Hash
+	w.sinceAnchor++
+	if w.sinceAnchor >= w.interval {
+		if err := w.anchor(); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+func (w *Writer) Anchor() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.sinceAnchor == 0 {
+		return nil
+	}
+	return w.anchor()
+}
+
+func (w *Writer) Close() error {
+	if err := w.Anchor(); err != nil {
+		return err
+	}
+	if w.closer != nil {
+		return w.closer.Close()
+	}
+	return nil
+}
+
+func (w *Writer) anchor() error {
+	rec := &Record{Type: TypeAnchor, Seq: w.seq, Hash: w.last}
+	signature, err := w.signer.Sign(anchorMessage(rec.Seq, rec.Hash))
+	if err != nil {
+		return errors.Annotate(err, "signing anchor: %w")
+	}
+	rec.Signature = signature
+
+	if err := w.write(rec); err != nil {
+		return err
+	}
+	w.sinceAnchor = 0
+	return nil
+}
+
+func (w *Writer) write(rec *Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return errors.Annotate(err, "encoding record: %w")
+	}
+	if _, err := w.w.Write(append(line, '\n')); err != nil {
+		return errors.Annotate(err, "writing record: %w")
+	}
+	return nil
+}
+
+func entryHash(prev []byte, rec *Record) []byte {
+	data := append([]byte{}, prev...)
+	data = binary.BigEndian.AppendUint64(data, rec.Seq)
+	data = binary.BigEndian.AppendUint64(data, uint64(rec.Time.UnixNano()))
+	data = binary.BigEndian.AppendUint32(data, uint32(len(rec.Message)))
+	data = append(data, rec.Message...)
+
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+func anchorMessage(seq uint64, head []byte) []byte {
+	data := binary.BigEndian.AppendUint64([]byte("auditlog anchor"), seq)
+	return append(data, head...)
+}
+
+func scanRecords(r io.Reader, fn func(line int, rec *Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return errors.Annotate(errors.ErrInvalidFormat, "line %d: %w", line)
+		}
+		if err := fn(line, &rec); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Annotate(err, "reading log: %w")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package auditlog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/jose"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/masterkusok/crypto/sign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	r := rsa.NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
+	require.NoError(t, r.GenerateKeyPair())
+	return r.GetPrivateKey()
+}
+
+func rsaSigner(t *testing.T, key *rsa.PrivateKey) sign.Signer {
+	signer, err := jose.NewRSASigner(jose.PS256, key)
+	require.NoError(t, err)
+	return signer
+}
+
+func rsaVerifier(t *testing.T, key *rsa.PrivateKey) sign.Verifier {
+	verifier, err := jose.NewRSAVerifier(jose.PS256, &key.PublicKey)
+	require.NoError(t, err)
+	return verifier
+}
+
+func TestWriterAnchorsPeriodically(t *testing.T) {
+	key := newRSAKey(t)
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, rsaSigner(t, key), 3)
+	require.NoError(t, err)
+	for i := 0; i < 7; i++ {
+		rec, err := w.Append(fmt.Sprintf("event %d", i))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i), rec.Seq)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 9)
+	assert.Contains(t, lines[3], `"type":"anchor"`)
+	assert.Contains(t, lines[7], `"type":"anchor"`)
+
+	report, err := Verify(bytes.NewReader(buf.Bytes()), rsaVerifier(t, key))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), report.Entries)
+	assert.Equal(t, 2, report.Anchors)
+	assert.Equal(t, uint64(6), report.LastAnchored)
+	assert.Equal(t, 1, report.Unanchored)
+
+	require.NoError(t, w.Close())
+	report, err = Verify(bytes.NewReader(buf.Bytes()), rsaVerifier(t, key))
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Unanchored)
+	assert.Equal(t, uint64(7), report.LastAnchored)
+}
+
+func TestOpenResumesChain(t *testing.T) {
+	key := newRSAKey(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	signer, verifier := rsaSigner(t, key), rsaVerifier(t, key)
+
+	w, err := Open(path, signer, verifier, 2)
+	require.NoError(t, err)
+	w.now = func() time.Time { return time.Unix(1_700_000_000, 0) }
+	for _, msg := range []string{"login alice", "rotate key", "logout alice"} {
+		_, err := w.Append(msg)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	w, err = Open(path, signer, verifier, 2)
+	require.NoError(t, err)
+	rec, err := w.Append("login bob")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), rec.Seq)
+	require.NoError(t, w.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	report, err := Verify(file, verifier)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), report.Entries)
+	assert.Equal(t, 0, report.Unanchored)
+}
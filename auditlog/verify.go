@@ -0,0 +1,61 @@
+package auditlog
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/sign"
+)
+
+type Report struct {
+	Entries      uint64
+	Anchors      int
+	LastAnchored uint64
+	Unanchored   int
+	Head         []byte
+}
+
+// Verify checks the hash chain and every anchor signature. A log without
+// any anchor carries no signature at all and is rejected.
+func Verify(r io.Reader, verifier sign.Verifier) (*Report, error) {
+	report := &Report{Head: make([]byte, sha256.Size)}
+
+	err := scanRecords(r, func(line int, rec *Record) error {
+		switch rec.Type {
+		case TypeEntry:
+			if rec.Seq != report.Entries {
+				return errors.Annotate(errors.ErrInvalidFormat, "line %d: expected entry %d, got %d: %w", line, report.Entries, rec.Seq)
+			}
+			if !bytes.Equal(entryHash(report.Head, rec), rec.Hash) {
+				return errors.Annotate(errors.ErrDigestMismatch, "line %d: entry %d breaks the hash chain: %w", line, rec.Seq)
+			}
+			report.Entries++
+			report.Head = rec.Hash
+			report.Unanchored++
+
+		case TypeAnchor:
+			if rec.Seq != report.Entries || !bytes.Equal(rec.Hash, report.Head) {
+				return errors.Annotate(errors.ErrDigestMismatch, "line %d: anchor does not match chain head: %w", line)
+			}
+			if err := verifier.Verify(anchorMessage(rec.Seq, rec.Hash), rec.Signature); err != nil {
+				return errors.Annotate(err, "line %d: anchor signature: %w", line)
+			}
+			report.Anchors++
+			report.LastAnchored = rec.Seq
+			report.Unanchored = 0
+
+		default:
+			return errors.Annotate(errors.ErrInvalidFormat, "line %d: unknown record type %q: %w", line, rec.Type)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if report.Anchors == 0 {
+		return nil, errors.Annotate(errors.ErrInvalidSignature, "log has no anchor: %w")
+	}
+	return report, nil
+}
@@ -0,0 +1,83 @@
+package auditlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/sign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLog(t *testing.T, signer sign.Signer, messages ...string) []string {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, signer, 2)
+	require.NoError(t, err)
+	for _, msg := range messages {
+		_, err := w.Append(msg)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return strings.Split(strings.TrimSpace(buf.String()), "\n")
+}
+
+func verifyLines(lines []string, verifier sign.Verifier) error {
+	_, err := Verify(strings.NewReader(strings.Join(lines, "\n")+"\n"), verifier)
+	return err
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	key := newRSAKey(t)
+	verifier := rsaVerifier(t, key)
+	lines := writeLog(t, rsaSigner(t, key), "grant admin to alice", "export data", "revoke admin")
+	require.NoError(t, verifyLines(lines, verifier))
+
+	edited := append([]string{}, lines...)
+	edited[0] = strings.Replace(edited[0], "alice", "mallory", 1)
+	assert.ErrorIs(t, verifyLines(edited, verifier), errors.ErrDigestMismatch)
+
+	removed := append(append([]string{}, lines[:1]...), lines[2:]...)
+	assert.ErrorIs(t, verifyLines(removed, verifier), errors.ErrDigestMismatch)
+
+	swapped := append([]string{}, lines...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	assert.ErrorIs(t, verifyLines(swapped, verifier), errors.ErrInvalidFormat)
+
+	other := newRSAKey(t)
+	assert.ErrorIs(t, verifyLines(lines, rsaVerifier(t, other)), errors.ErrInvalidSignature)
+}
+
+func TestVerifyDetectsRewrittenChain(t *testing.T) {
+	key := newRSAKey(t)
+	verifier := rsaVerifier(t, key)
+	original := writeLog(t, rsaSigner(t, key), "a", "b")
+
+	attacker := newRSAKey(t)
+	forged := writeLog(t, rsaSigner(t, attacker), "a", "c")
+	assert.ErrorIs(t, verifyLines(forged, verifier), errors.ErrInvalidSignature)
+
+	mixed := append([]string{}, forged[:2]...)
+	mixed = append(mixed, original[2])
+	assert.ErrorIs(t, verifyLines(mixed, verifier), errors.ErrDigestMismatch)
+
+	assert.ErrorIs(t, verifyLines([]string{"not json"}, verifier), errors.ErrInvalidFormat)
+}
+
+func TestVerifyRequiresAnchor(t *testing.T) {
+	key := newRSAKey(t)
+	verifier := rsaVerifier(t, key)
+	lines := writeLog(t, rsaSigner(t, key), "a", "b", "c")
+
+	unanchored := []string{}
+	for _, line := range lines {
+		if !strings.Contains(line, `"type":"anchor"`) {
+			unanchored = append(unanchored, line)
+		}
+	}
+	assert.ErrorIs(t, verifyLines(unanchored, verifier), errors.ErrInvalidSignature)
+
+	_, err := Verify(strings.NewReader(""), verifier)
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+}
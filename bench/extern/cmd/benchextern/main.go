@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/masterkusok/crypto/bench/extern"
+)
+
+func main() {
+	options := extern.DefaultOptions()
+	flag.StringVar(&options.Binary, "openssl", options.Binary, "path to the openssl binary")
+	flag.IntVar(&options.Size, "bytes", options.Size, "buffer size per operation")
+	flag.DurationVar(&options.Duration, "duration", options.Duration, "measurement time per algorithm")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results, err := extern.Compare(ctx, &options, extern.DefaultAlgorithms())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := extern.WriteTable(os.Stdout, results); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
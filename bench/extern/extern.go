@@ -0,0 +1,174 @@
+package extern
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/aes"
+	"github.com/masterkusok/crypto/cipher/chacha20"
+	"github.com/masterkusok/crypto/cipher/tripledes"
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Encryptor func(ctx context.Context, data []byte) error
+
+type Algorithm struct {
+	Name    string
+	OpenSSL string
+	New     func(ctx context.Context) (Encryptor, error)
+}
+
+type Options struct {
+	Binary   string
+	Size     int
+	Duration time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{Binary: "openssl", Size: 1024, Duration: time.Second}
+}
+
+type Result struct {
+	Algorithm string
+	Local     float64
+	OpenSSL   float64
+}
+
+func DefaultAlgorithms() []Algorithm {
+	return []Algorithm{
+		{Name: "aes-128-cbc", OpenSSL: "aes-128-cbc", New: blockEncryptor(func() cipher.BlockCipher { return aes.NewAES128() }, 16)},
+		{Name: "aes-256-cbc", OpenSSL: "aes-256-cbc", New: blockEncryptor(func() cipher.BlockCipher { return aes.NewAES256() }, 32)},
+		{Name: "3des-cbc", OpenSSL: "des-ede3-cbc", New: blockEncryptor(func() cipher.BlockCipher { return tripledes.NewTripleDES() }, 24)},
+		{Name: "chacha20", OpenSSL: "chacha20", New: chachaEncryptor},
+	}
+}
+
+func Compare(ctx context.Context, opts *Options, algorithms []Algorithm) ([]Result, error) {
+	options := DefaultOptions()
+	if opts != nil {
+		options = *opts
+	}
+	if options.Size <= 0 || options.Duration <= 0 {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	results := make([]Result, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		local, err := MeasureLocal(ctx, algorithm, options.Size, options.Duration)
+		if err != nil {
+			return nil, errors.Annotate(err, "measuring %s: %w", algorithm.Name)
+		}
+		openssl, err := MeasureOpenSSL(ctx, options.Binary, algorithm.OpenSSL, options.Size, options.Duration)
+		if err != nil {
+			return nil, errors.Annotate(err, "running openssl for %s: %w", algorithm.Name)
+		}
+		results = append(results, Result{Algorithm: algorithm.Name, Local: local, OpenSSL: openssl})
+	}
+	return results, nil
+}
+
+func MeasureLocal(ctx context.Context, algorithm Algorithm, size int, duration time.Duration) (float64, error) {
+	encrypt, err := algorithm.New(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	data := make([]byte, size)
+	var processed int64
+	start := time.Now()
+	for time.Since(start) < duration {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if err := encrypt(ctx, data); err != nil {
+			return 0, err
+		}
+		processed += int64(size)
+	}
+	return float64(processed) / time.Since(start).Seconds(), nil
+}
+
+func MeasureOpenSSL(ctx context.Context, binary, algorithm string, size int, duration time.Duration) (float64, error) {
+	seconds := max(int(duration.Round(time.Second)/time.Second), 1)
+	cmd := exec.CommandContext(ctx, binary, "speed", "-mr",
+		"-seconds", strconv.Itoa(seconds),
+		"-bytes", strconv.Itoa(size),
+		"-evp", algorithm)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return 0, errors.Annotate(err, "%s: %w", strings.TrimSpace(stderr.String()))
+	}
+	return parseSpeed(&stdout)
+}
+
+func parseSpeed(r io.Reader) (float64, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 4 || fields[0] != "+F" {
+			continue
+		}
+
+		speed, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return 0, errors.Annotate(errors.ErrInvalidFormat, "parsing speed %q: %w", fields[3])
+		}
+		return speed, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.Annotate(errors.ErrInvalidFormat, "no speed line in openssl output: %w")
+}
+
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "algorithm\tlocal MB/s\topenssl MB/s\tslowdown\t")
+	for _, result := range results {
+		slowdown := "-"
+		if result.Local > 0 {
+			slowdown = fmt.Sprintf("%.1fx", result.OpenSSL/result.Local)
+		}
+		fmt.Fprintf(tw, "%s\t%.2f\t%.2f\t%s\t\n", result.Algorithm, result.Local/1e6, result.OpenSSL/1e6, slowdown)
+	}
+	return tw.Flush()
+}
+
+func blockEncryptor(newBlock func() cipher.BlockCipher, keySize int) func(ctx context.Context) (Encryptor, error) {
+	return func(ctx context.Context) (Encryptor, error) {
+		block := newBlock()
+		if err := block.SetKey(ctx, make([]byte, keySize)); err != nil {
+			return nil, err
+		}
+
+		mode := &cipher.CBCMode{}
+		iv := make([]byte, block.BlockSize())
+		return func(ctx context.Context, data []byte) error {
+			_, err := mode.Encrypt(ctx, block, data[:len(data)/len(iv)*len(iv)], iv)
+			return err
+		}, nil
+	}
+}
+
+func chachaEncryptor(ctx context.Context) (Encryptor, error) {
+	key := make([]byte, chacha20.KeySize)
+	nonce := make([]byte, chacha20.NonceSize)
+	return func(ctx context.Context, data []byte) error {
+		stream, err := chacha20.New(key, nonce, 0)
+		if err != nil {
+			return err
+		}
+		return stream.XORKeyStream(data, data)
+	}, nil
+}
@@ -0,0 +1,75 @@
+package extern
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const speedOutput = `+DT:AES-128-CBC:1:1024
++R:1377022:AES-128-CBC:0.990000
++H:1024
++F:25:AES-128-CBC:1424313664.65
+`
+
+func TestParseSpeed(t *testing.T) {
+	speed, err := parseSpeed(strings.NewReader(speedOutput))
+	require.NoError(t, err)
+	assert.InDelta(t, 1424313664.65, speed, 0.01)
+
+	_, err = parseSpeed(strings.NewReader("+H:1024\n"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = parseSpeed(strings.NewReader("+F:25:AES:fast\n"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
+
+func TestCompareWithFakeOpenSSL(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "openssl")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\ncat <<'EOF'\n"+speedOutput+"EOF\n"), 0o755))
+
+	algorithms := DefaultAlgorithms()[3:]
+	results, err := Compare(context.Background(), &Options{Binary: script, Size: 256, Duration: 10 * time.Millisecond}, algorithms)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "chacha20", results[0].Algorithm)
+	assert.Greater(t, results[0].Local, 0.0)
+	assert.InDelta(t, 1424313664.65, results[0].OpenSSL, 0.01)
+
+	_, err = Compare(context.Background(), &Options{Binary: filepath.Join(t.TempDir(), "missing"), Size: 256, Duration: time.Millisecond}, algorithms)
+	assert.Error(t, err)
+
+	_, err = Compare(context.Background(), &Options{Binary: script}, algorithms)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
+
+func TestWriteTable(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteTable(&out, []Result{
+		{Algorithm: "aes-128-cbc", Local: 2e6, OpenSSL: 1e9},
+		{Algorithm: "chacha20", OpenSSL: 4e9},
+	}))
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "openssl MB/s")
+	assert.Contains(t, lines[1], "500.0x")
+	assert.Contains(t, lines[2], "-")
+}
+
+func TestLocalEncryptors(t *testing.T) {
+	ctx := context.Background()
+	for _, algorithm := range DefaultAlgorithms() {
+		encrypt, err := algorithm.New(ctx)
+		require.NoError(t, err, algorithm.Name)
+		assert.NoError(t, encrypt(ctx, make([]byte, 64)), algorithm.Name)
+	}
+}
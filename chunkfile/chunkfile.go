@@ -0,0 +1,282 @@
+package chunkfile
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/nonce"
+)
+
+// A container is a header followed by chunks sealed with an AEAD. Chunk i
+// is sealed under the nonce prefix || i || final, with the header as
+// associated data. Every chunk except the last holds exactly ChunkSize bytes
+// of plaintext; the last one is always shorter (possibly empty), so a
+// container that ends on a full chunk is known to be incomplete.
+const (
+	DefaultChunkSize = 64 * 1024
+	MaxChunkSize     = 16 * 1024 * 1024
+
+	counterSize  = 4
+	minNonceSize = counterSize + 1 + 4
+	maxNameSize  = 255
+	maxHeader    = 8 + 1 + maxNameSize + 4 + 64
+)
+
+var magic = []byte("MKCHK001")
+
+// Options configures a container. Prefixes, when set, supplies the nonce
+// prefix of every new container and must produce NonceSize-5 bytes for the
+// chosen AEAD; nil draws a random prefix. The prefix is all that tells
+// containers under one key apart, so deterministic sources are rejected.
+type Options struct {
+	AEAD      string
+	ChunkSize int
+	Prefixes  nonce.NonceSource
+}
+
+func DefaultOptions() Options {
+	return Options{AEAD: aead.ChaCha20Poly1305Name, ChunkSize: DefaultChunkSize}
+}
+
+type header struct {
+	aead      string
+	chunkSize int
+	prefix    []byte
+	raw       []byte
+}
+
+func newHeader(opts Options, key []byte) (*header, aead.AEAD, error) {
+	if len(opts.AEAD) > maxNameSize || opts.ChunkSize <= 0 || opts.ChunkSize > MaxChunkSize {
+		return nil, nil, errors.ErrInvalidParameters
+	}
+	cipher, err := aead.New(opts.AEAD, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cipher.NonceSize() < minNonceSize {
+		return nil, nil, errors.ErrInvalidNonceSize
+	}
+
+	prefix, err := noncePrefix(opts.Prefixes, cipher.NonceSize()-counterSize-1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw := append([]byte{}, magic...)
+	raw = append(raw, byte(len(opts.AEAD)))
+	raw = append(raw, opts.AEAD...)
+	raw = binary.BigEndian.AppendUint32(raw, uint32(opts.ChunkSize))
+	raw = append(raw, prefix...)
+
+	return &header{aead: opts.AEAD, chunkSize: opts.ChunkSize, prefix: prefix, raw: raw}, cipher, nil
+}
+
+func noncePrefix(source nonce.NonceSource, size int) ([]byte, error) {
+	if source == nil {
+		prefix := make([]byte, size)
+		if _, err := rand.Read(prefix); err != nil {
+			return nil, errors.Annotate(err, "generating nonce prefix: %w")
+		}
+		return prefix, nil
+	}
+
+	if nonce.Deterministic(source) {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "deterministic nonce prefixes repeat across containers: %w")
+	}
+	if source.NonceSize() != size {
+		return nil, errors.ErrInvalidNonceSize
+	}
+	prefix, err := source.Nonce(nil)
+	if err != nil {
+		return nil, errors.Annotate(err, "generating nonce prefix: %w")
+	}
+	return prefix, nil
+}
+
+// parseHeader decodes the header at the start of data, which may extend
+// past it.
+func parseHeader(data, key []byte) (*header, aead.AEAD, error) {
+	if len(data) < len(magic)+1 || string(data[:len(magic)]) != string(magic) {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	rest := data[len(magic):]
+	nameSize := int(rest[0])
+	if len(rest) < 1+nameSize+4 {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+	name := string(rest[1 : 1+nameSize])
+	chunkSize := int(binary.BigEndian.Uint32(rest[1+nameSize:]))
+	rest = rest[1+nameSize+4:]
+	if chunkSize <= 0 || chunkSize > MaxChunkSize {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	cipher, err := aead.New(name, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	prefixSize := cipher.NonceSize() - counterSize - 1
+	if prefixSize < 0 || len(rest) < prefixSize {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	size := len(data) - len(rest) + prefixSize
+	return &header{
+		aead:      name,
+		chunkSize: chunkSize,
+		prefix:    append([]byte{}, rest[:prefixSize]...),
+		raw:       append([]byte{}, data[:size]...),
+	}, cipher, nil
+}
+
+func readHeader(r io.ReaderAt, size int64, key []byte) (*header, aead.AEAD, error) {
+	buf := make([]byte, min(size, maxHeader))
+	if _, err := r.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return nil, nil, errors.Annotate(err, "reading header: %w")
+	}
+	return parseHeader(buf, key)
+}
+
+func (h *header) nonce(index uint64, final bool) []byte {
+	nonce := binary.BigEndian.AppendUint32(append([]byte{}, h.prefix...), uint32(index))
+	if final {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+func (h *header) sealedSize(cipher aead.AEAD) int64 {
+	return int64(h.chunkSize + cipher.Overhead())
+}
+
+// Encrypt writes r to w as a chunked container.
+func Encrypt(ctx context.Context, r io.Reader, w io.Writer, key []byte, opts *Options) error {
+	options := DefaultOptions()
+	if opts != nil {
+		options = *opts
+	}
+
+	h, cipher, err := newHeader(options, key)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(h.raw); err != nil {
+		return errors.Annotate(err, "writing header: %w")
+	}
+	return encryptChunks(ctx, r, w, h, cipher, 0)
+}
+
+func encryptChunks(ctx context.Context, r io.Reader, w io.Writer, h *header, cipher aead.AEAD, index uint64) error {
+	buf := make([]byte, h.chunkSize)
+	for ; ; index++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if index > 1<<(8*counterSize)-1 {
+			return errors.ErrNonceExhausted
+		}
+
+		n, err := io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return errors.Annotate(err, "reading input: %w")
+		}
+		final := n < len(buf)
+
+		sealed, err := cipher.Seal(ctx, h.nonce(index, final), buf[:n], h.raw)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(sealed); err != nil {
+			return errors.Annotate(err, "writing chunk %d: %w", index)
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+// EncryptFile encrypts inputPath into a container at outputPath. If
+// outputPath already holds the beginning of a container for the same key,
+// e.g. after an interrupted run, the verified chunks are kept and
+// encryption resumes at the first missing one.
+func EncryptFile(ctx context.Context, inputPath, outputPath string, key []byte, opts *Options) error {
+	in, err := os.Open(inputPath)
+	if err != nil {
+		return errors.Annotate(err, "opening input: %w")
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(outputPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return errors.Annotate(err, "opening output: %w")
+	}
+	defer out.Close()
+
+	info, err := out.Stat()
+	if err != nil {
+		return errors.Annotate(err, "inspecting output: %w")
+	}
+	if info.Size() == 0 {
+		return Encrypt(ctx, in, out, key, opts)
+	}
+
+	h, cipher, err := readHeader(out, info.Size(), key)
+	if err != nil {
+		return errors.Annotate(err, "resuming %s: %w", outputPath)
+	}
+	next, complete, err := resumePoint(ctx, out, info.Size(), h, cipher)
+	if err != nil || complete {
+		return err
+	}
+
+	end := int64(len(h.raw)) + int64(next)*h.sealedSize(cipher)
+	if err := out.Truncate(end); err != nil {
+		return errors.Annotate(err, "discarding partial chunk: %w")
+	}
+	if _, err := out.Seek(end, io.SeekStart); err != nil {
+		return errors.Annotate(err, "seeking output: %w")
+	}
+	if _, err := in.Seek(int64(next)*int64(h.chunkSize), io.SeekStart); err != nil {
+		return errors.Annotate(err, "seeking input: %w")
+	}
+	return encryptChunks(ctx, in, out, h, cipher, next)
+}
+
+// resumePoint authenticates the tail of a partially written container and
+// returns the index of the first chunk to write, or complete if the final
+// chunk is already present.
+func resumePoint(ctx context.Context, r io.ReaderAt, size int64, h *header, cipher aead.AEAD) (uint64, bool, error) {
+	sealedSize := h.sealedSize(cipher)
+	body := size - int64(len(h.raw))
+	full := uint64(body / sealedSize)
+
+	if tail := body % sealedSize; tail >= int64(cipher.Overhead()) {
+		if _, err := openChunk(ctx, r, h, cipher, full, tail, true); err == nil {
+			return 0, true, nil
+		}
+	}
+	if full > 0 {
+		if _, err := openChunk(ctx, r, h, cipher, full-1, sealedSize, false); err != nil {
+			return 0, false, errors.Annotate(err, "verifying chunk %d: %w", full-1)
+		}
+	}
+	return full, false, nil
+}
+
+func openChunk(ctx context.Context, r io.ReaderAt, h *header, cipher aead.AEAD, index uint64, size int64, final bool) ([]byte, error) {
+	sealed := make([]byte, size)
+	offset := int64(len(h.raw)) + int64(index)*h.sealedSize(cipher)
+	if n, err := r.ReadAt(sealed, offset); n < len(sealed) {
+		if err == nil || err == io.EOF {
+			err = errors.ErrInvalidFormat
+		}
+		return nil, errors.Annotate(err, "reading chunk %d: %w", index)
+	}
+	return cipher.Open(ctx, h.nonce(index, final), sealed, h.raw)
+}
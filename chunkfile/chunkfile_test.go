@@ -0,0 +1,182 @@
+package chunkfile
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/nonce"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, 32)
+
+func sequence(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i * 7)
+	}
+	return b
+}
+
+func encrypt(t *testing.T, plaintext []byte, chunkSize int) []byte {
+	opts := DefaultOptions()
+	opts.ChunkSize = chunkSize
+	var out bytes.Buffer
+	require.NoError(t, Encrypt(context.Background(), bytes.NewReader(plaintext), &out, testKey, &opts))
+	return out.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for _, size := range []int{0, 1, 15, 16, 17, 64, 100} {
+		plaintext := sequence(size)
+		container := encrypt(t, plaintext, 16)
+
+		var out bytes.Buffer
+		require.NoError(t, Decrypt(ctx, bytes.NewReader(container), int64(len(container)), &out, testKey))
+		assert.Equal(t, plaintext, append([]byte{}, out.Bytes()...), size)
+	}
+}
+
+func TestReadAt(t *testing.T) {
+	plaintext := sequence(1000)
+	container := encrypt(t, plaintext, 64)
+
+	r, err := NewReader(bytes.NewReader(container), int64(len(container)), testKey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(plaintext)), r.Size())
+	assert.Equal(t, 64, r.ChunkSize())
+
+	for _, span := range [][2]int{{0, 10}, {60, 70}, {128, 256}, {500, 999}, {990, 1000}} {
+		buf := make([]byte, span[1]-span[0])
+		n, err := r.ReadAt(buf, int64(span[0]))
+		require.NoError(t, err)
+		assert.Equal(t, len(buf), n)
+		assert.Equal(t, plaintext[span[0]:span[1]], buf)
+	}
+
+	buf := make([]byte, 20)
+	n, err := r.ReadAt(buf, 990)
+	assert.Equal(t, 10, n)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, plaintext[990:], buf[:n])
+}
+
+func TestTamperingAndTruncation(t *testing.T) {
+	ctx := context.Background()
+	container := encrypt(t, sequence(100), 16)
+
+	tampered := append([]byte{}, container...)
+	tampered[len(tampered)-40] ^= 1
+	err := Decrypt(ctx, bytes.NewReader(tampered), int64(len(tampered)), io.Discard, testKey)
+	require.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	// Dropping the short final chunk leaves the container ending on a full
+	// chunk, which NewReader must reject.
+	r, err := NewReader(bytes.NewReader(container), int64(len(container)), testKey)
+	require.NoError(t, err)
+	truncated := container[:len(container)-int(r.lastLen)]
+	_, err = NewReader(bytes.NewReader(truncated), int64(len(truncated)), testKey)
+	require.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	// Cutting inside a full chunk makes it look like a short final chunk
+	// whose tag cannot verify.
+	cut := container[:len(container)-int(r.lastLen)-5]
+	err = Decrypt(ctx, bytes.NewReader(cut), int64(len(cut)), io.Discard, testKey)
+	require.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+}
+
+func TestEncryptFileResumes(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input")
+	complete := filepath.Join(dir, "complete")
+	resumed := filepath.Join(dir, "resumed")
+	decrypted := filepath.Join(dir, "decrypted")
+
+	plaintext := sequence(300)
+	require.NoError(t, os.WriteFile(input, plaintext, 0o644))
+	opts := &Options{AEAD: DefaultOptions().AEAD, ChunkSize: 32}
+	require.NoError(t, EncryptFile(ctx, input, complete, testKey, opts))
+
+	full, err := os.ReadFile(complete)
+	require.NoError(t, err)
+
+	// Simulate a run interrupted in the middle of the fourth chunk.
+	r, err := NewReader(bytes.NewReader(full), int64(len(full)), testKey)
+	require.NoError(t, err)
+	cut := len(r.header.raw) + 3*int(r.header.sealedSize(r.cipher)) + 7
+	require.NoError(t, os.WriteFile(resumed, full[:cut], 0o600))
+
+	require.NoError(t, EncryptFile(ctx, input, resumed, testKey, opts))
+	got, err := os.ReadFile(resumed)
+	require.NoError(t, err)
+	assert.Equal(t, full, got)
+
+	// A complete container is left untouched.
+	require.NoError(t, EncryptFile(ctx, input, resumed, testKey, opts))
+	got, err = os.ReadFile(resumed)
+	require.NoError(t, err)
+	assert.Equal(t, full, got)
+
+	require.NoError(t, DecryptFile(ctx, resumed, decrypted, testKey))
+	got, err = os.ReadFile(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+}
+
+func TestEncryptFileRejectsForeignOutput(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input")
+	output := filepath.Join(dir, "output")
+	require.NoError(t, os.WriteFile(input, sequence(200), 0o644))
+
+	require.NoError(t, os.WriteFile(output, []byte("not a container"), 0o600))
+	require.ErrorIs(t, EncryptFile(ctx, input, output, testKey, nil), errors.ErrInvalidFormat)
+
+	container := encrypt(t, sequence(200), 32)
+	require.NoError(t, os.WriteFile(output, container[:len(container)-10], 0o600))
+	otherKey := bytes.Repeat([]byte{0x24}, 32)
+	require.ErrorIs(t, EncryptFile(ctx, input, output, otherKey, nil), errors.ErrAuthenticationFailed)
+}
+
+func TestCounterPrefixes(t *testing.T) {
+	ctx := context.Background()
+	counter, err := nonce.NewCounter(filepath.Join(t.TempDir(), "counter"), 7)
+	require.NoError(t, err)
+	opts := DefaultOptions()
+	opts.ChunkSize = 16
+	opts.Prefixes = counter
+
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		var out bytes.Buffer
+		require.NoError(t, Encrypt(ctx, bytes.NewReader(sequence(40)), &out, testKey, &opts))
+		h, _, err := parseHeader(out.Bytes(), testKey)
+		require.NoError(t, err)
+		assert.False(t, seen[string(h.prefix)], "prefix repeated at container %d", i)
+		seen[string(h.prefix)] = true
+
+		var plaintext bytes.Buffer
+		require.NoError(t, Decrypt(ctx, bytes.NewReader(out.Bytes()), int64(out.Len()), &plaintext, testKey))
+		assert.Equal(t, sequence(40), plaintext.Bytes())
+	}
+
+	opts.Prefixes = nonce.NewRandom(12)
+	assert.ErrorIs(t, Encrypt(ctx, bytes.NewReader(nil), io.Discard, testKey, &opts), errors.ErrInvalidNonceSize)
+
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	opts.Prefixes, err = nonce.NewDerived(ctx, block, make([]byte, 16), 7)
+	require.NoError(t, err)
+	assert.ErrorIs(t, Encrypt(ctx, bytes.NewReader(nil), io.Discard, testKey, &opts), errors.ErrInvalidParameters)
+}
@@ -0,0 +1,140 @@
+package chunkfile
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+)
+
+// Reader gives random access to the plaintext of a container: ReadAt only
+// authenticates and decrypts the chunks covering the requested range.
+type Reader struct {
+	r       io.ReaderAt
+	header  *header
+	cipher  aead.AEAD
+	chunks  uint64
+	lastLen int64
+	size    int64
+}
+
+var _ io.ReaderAt = (*Reader)(nil)
+
+func NewReader(r io.ReaderAt, size int64, key []byte) (*Reader, error) {
+	h, cipher, err := readHeader(r, size, key)
+	if err != nil {
+		return nil, err
+	}
+
+	body := size - int64(len(h.raw))
+	sealedSize := h.sealedSize(cipher)
+	overhead := int64(cipher.Overhead())
+	// The final chunk is always shorter than a full one, so its length is
+	// the remainder and the container must not end on a chunk boundary.
+	lastLen := body % sealedSize
+	if body < overhead || lastLen < overhead {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "container is truncated: %w")
+	}
+	chunks := uint64(body/sealedSize) + 1
+
+	return &Reader{
+		r:       r,
+		header:  h,
+		cipher:  cipher,
+		chunks:  chunks,
+		lastLen: lastLen,
+		size:    int64(chunks-1)*int64(h.chunkSize) + lastLen - overhead,
+	}, nil
+}
+
+// Size is the length of the plaintext.
+func (r *Reader) Size() int64 {
+	return r.size
+}
+
+func (r *Reader) ChunkSize() int {
+	return r.header.chunkSize
+}
+
+// Chunk decrypts and authenticates chunk index.
+func (r *Reader) Chunk(ctx context.Context, index uint64) ([]byte, error) {
+	if index >= r.chunks {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "chunk %d out of range: %w", index)
+	}
+
+	final := index == r.chunks-1
+	size := r.header.sealedSize(r.cipher)
+	if final {
+		size = r.lastLen
+	}
+	return openChunk(ctx, r.r, r.header, r.cipher, index, size, final)
+}
+
+func (r *Reader) ReadAt(p []byte, off int64) (int, error) {
+	return r.ReadAtContext(context.Background(), p, off)
+}
+
+func (r *Reader) ReadAtContext(ctx context.Context, p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.ErrInvalidParameters
+	}
+
+	chunkSize := int64(r.header.chunkSize)
+	n := 0
+	for n < len(p) && off < r.size {
+		plaintext, err := r.Chunk(ctx, uint64(off/chunkSize))
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], plaintext[off%chunkSize:])
+		n += copied
+		off += int64(copied)
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Decrypt writes the whole plaintext of the container to w.
+func Decrypt(ctx context.Context, r io.ReaderAt, size int64, w io.Writer, key []byte) error {
+	reader, err := NewReader(r, size, key)
+	if err != nil {
+		return err
+	}
+
+	for index := uint64(0); index < reader.chunks; index++ {
+		plaintext, err := reader.Chunk(ctx, index)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(plaintext); err != nil {
+			return errors.Annotate(err, "writing output: %w")
+		}
+	}
+	return nil
+}
+
+func DecryptFile(ctx context.Context, inputPath, outputPath string, key []byte) error {
+	in, err := os.Open(inputPath)
+	if err != nil {
+		return errors.Annotate(err, "opening input: %w")
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return errors.Annotate(err, "inspecting input: %w")
+	}
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return errors.Annotate(err, "creating output: %w")
+	}
+	defer out.Close()
+
+	return Decrypt(ctx, in, info.Size(), out, key)
+}
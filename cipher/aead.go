@@ -0,0 +1,12 @@
+package cipher
+
+import "context"
+
+// AEAD is implemented by the modes in the aead package, which also keeps
+// the registry for constructing them by name.
+type AEAD interface {
+	NonceSize() int
+	Overhead() int
+	Seal(ctx context.Context, nonce, plaintext, additionalData []byte) ([]byte, error)
+	Open(ctx context.Context, nonce, ciphertext, additionalData []byte) ([]byte, error)
+}
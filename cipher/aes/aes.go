@@ -0,0 +1,39 @@
+package aes
+
+import (
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+)
+
+const (
+	BlockSize = 16
+	Modulus   = 0x1B
+
+	KeySize128 = 16
+	KeySize192 = 24
+	KeySize256 = 32
+)
+
+type AES struct {
+	*rijndael.Rijndael
+}
+
+var _ cipher.BlockCipher = (*AES)(nil)
+
+func NewAES128() *AES {
+	return newAES(KeySize128)
+}
+
+func NewAES192() *AES {
+	return newAES(KeySize192)
+}
+
+func NewAES256() *AES {
+	return newAES(KeySize256)
+}
+
+func newAES(keySize int) *AES {
+	// The parameters are fixed and valid, so NewRijndael cannot fail here.
+	r, _ := rijndael.NewRijndael(BlockSize, keySize, Modulus)
+	return &AES{Rijndael: r}
+}
@@ -0,0 +1,61 @@
+package aes
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sequential(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestFIPS197Vectors(t *testing.T) {
+	ctx := context.Background()
+	fipsPlaintext, _ := hex.DecodeString("00112233445566778899aabbccddeeff")
+	appendixBKey, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	appendixBPlaintext, _ := hex.DecodeString("3243f6a8885a308d313198a2e0370734")
+
+	tests := []struct {
+		name       string
+		cipher     *AES
+		key        []byte
+		plaintext  []byte
+		ciphertext string
+	}{
+		{"appendix-b", NewAES128(), appendixBKey, appendixBPlaintext, "3925841d02dc09fbdc118597196a0b32"},
+		{"aes-128", NewAES128(), sequential(KeySize128), fipsPlaintext, "69c4e0d86a7b0430d8cdb78070b4c55a"},
+		{"aes-192", NewAES192(), sequential(KeySize192), fipsPlaintext, "dda97ca4864cdfe06eaf70a0ec0d7191"},
+		{"aes-256", NewAES256(), sequential(KeySize256), fipsPlaintext, "8ea2b7ca516745bfeafc49904b496089"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.cipher.SetKey(ctx, tt.key))
+			assert.Equal(t, BlockSize, tt.cipher.BlockSize())
+
+			ciphertext, err := tt.cipher.Encrypt(ctx, tt.plaintext)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ciphertext, hex.EncodeToString(ciphertext))
+
+			plaintext, err := tt.cipher.Decrypt(ctx, ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, tt.plaintext, plaintext)
+		})
+	}
+}
+
+func TestKeySizeIsPinned(t *testing.T) {
+	ctx := context.Background()
+	assert.ErrorIs(t, NewAES128().SetKey(ctx, sequential(KeySize256)), errors.ErrInvalidKeySize)
+	assert.ErrorIs(t, NewAES192().SetKey(ctx, sequential(KeySize128)), errors.ErrInvalidKeySize)
+	assert.ErrorIs(t, NewAES256().SetKey(ctx, sequential(KeySize192)), errors.ErrInvalidKeySize)
+}
@@ -0,0 +1,77 @@
+package cipher
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const calibrationSampleSize = 16 * 1024
+
+var calibrationChunkSizes = []int{256, 1024, 4 * 1024, 16 * 1024}
+
+var (
+	calibrationMu    sync.RWMutex
+	calibrationCache = make(map[string]Settings)
+)
+
+func (c *CipherContext) Calibrate(ctx context.Context) (Settings, error) {
+	best := Settings{}
+	var bestTime time.Duration
+
+	sample := make([]byte, calibrationSampleSize/c.cipher.BlockSize()*c.cipher.BlockSize())
+	iv := c.iv
+	if iv == nil {
+		iv = make([]byte, c.cipher.BlockSize())
+	}
+
+	for _, workers := range calibrationWorkers() {
+		for _, chunkSize := range calibrationChunkSizes {
+			settings := Settings{Workers: workers, ChunkSize: chunkSize}
+			runCtx := WithSettings(ctx, settings)
+
+			start := time.Now()
+			encrypted, err := c.mode.Encrypt(runCtx, c.cipher, sample, iv)
+			if err != nil {
+				return Settings{}, err
+			}
+			if _, err := c.mode.Decrypt(runCtx, c.cipher, encrypted, iv); err != nil {
+				return Settings{}, err
+			}
+			elapsed := time.Since(start)
+
+			if best.Workers == 0 || elapsed < bestTime {
+				best, bestTime = settings, elapsed
+			}
+		}
+	}
+
+	calibrationMu.Lock()
+	calibrationCache[calibrationKey(c.cipher, c.mode)] = best
+	calibrationMu.Unlock()
+
+	return best, nil
+}
+
+func CalibratedSettings(cipher BlockCipher, mode CipherMode) (Settings, bool) {
+	calibrationMu.RLock()
+	defer calibrationMu.RUnlock()
+
+	settings, ok := calibrationCache[calibrationKey(cipher, mode)]
+	return settings, ok
+}
+
+func calibrationWorkers() []int {
+	cpus := runtime.NumCPU()
+	var workers []int
+	for w := 1; w < cpus; w *= 2 {
+		workers = append(workers, w)
+	}
+	return append(workers, cpus)
+}
+
+func calibrationKey(cipher BlockCipher, mode CipherMode) string {
+	return fmt.Sprintf("%T/%T/%d", cipher, mode, cipher.BlockSize())
+}
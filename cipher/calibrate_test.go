@@ -0,0 +1,72 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalibrate(t *testing.T) {
+	ctx := context.Background()
+	key := []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}
+
+	cipherCtx, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.CTRMode{}, cipher.PKCS7, cipher.WithIV(make([]byte, 8)))
+	require.NoError(t, err)
+
+	settings, err := cipherCtx.Calibrate(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, settings.Workers)
+	assert.Positive(t, settings.ChunkSize)
+
+	cached, ok := cipher.CalibratedSettings(des.NewDES(), &cipher.CTRMode{})
+	require.True(t, ok)
+	assert.Equal(t, settings, cached)
+
+	_, ok = cipher.CalibratedSettings(des.NewDES(), &cipher.OFBMode{})
+	assert.False(t, ok)
+}
+
+func TestSettingsDoNotChangeOutput(t *testing.T) {
+	ctx := context.Background()
+	block := des.NewDES()
+	require.NoError(t, block.SetKey(ctx, []byte("01234567")))
+
+	data := bytes.Repeat([]byte("parallel"), 100)
+	iv := make([]byte, 8)
+
+	modes := map[string]cipher.CipherMode{
+		"ECB": &cipher.ECBMode{},
+		"CBC": &cipher.CBCMode{},
+		"CFB": &cipher.CFBMode{},
+		"OFB": &cipher.OFBMode{},
+		"CTR": &cipher.CTRMode{},
+	}
+
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			serial := cipher.WithSettings(ctx, cipher.Settings{Workers: 1, ChunkSize: 8})
+			parallel := cipher.WithSettings(ctx, cipher.Settings{Workers: 4, ChunkSize: 24})
+
+			want, err := mode.Encrypt(serial, block, data, iv)
+			require.NoError(t, err)
+			got, err := mode.Encrypt(parallel, block, data, iv)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+
+			decrypted, err := mode.Decrypt(parallel, block, got, iv)
+			require.NoError(t, err)
+			assert.Equal(t, data, decrypted)
+		})
+	}
+}
+
+func TestSettingsFromContextDefaults(t *testing.T) {
+	settings := cipher.SettingsFromContext(context.Background())
+	assert.Positive(t, settings.Workers)
+	assert.Equal(t, cipher.DefaultChunkSize, settings.ChunkSize)
+}
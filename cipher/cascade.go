@@ -0,0 +1,127 @@
+package cipher
+
+import (
+	"context"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Cascade struct {
+	layers []*CipherContext
+}
+
+func NewCascade(ctxs ...*CipherContext) (*Cascade, error) {
+	if len(ctxs) == 0 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "cascade needs at least one layer: %w")
+	}
+	for i, c := range ctxs {
+		if c == nil {
+			return nil, errors.Annotate(errors.ErrInvalidParameters, "cascade layer %d is nil: %w", i)
+		}
+	}
+
+	return &Cascade{layers: append([]*CipherContext{}, ctxs...)}, nil
+}
+
+func (c *Cascade) Layers() int {
+	return len(c.layers)
+}
+
+func (c *Cascade) EncryptBytes(ctx context.Context, data []byte) (<-chan []byte, <-chan error) {
+	return c.EncryptBytesWithIVs(ctx, data, nil)
+}
+
+func (c *Cascade) EncryptBytesWithIVs(ctx context.Context, data []byte, ivs [][]byte) (<-chan []byte, <-chan error) {
+	return c.run(ctx, data, ivs, c.encryptSync)
+}
+
+func (c *Cascade) DecryptBytes(ctx context.Context, data []byte) (<-chan []byte, <-chan error) {
+	return c.DecryptBytesWithIVs(ctx, data, nil)
+}
+
+func (c *Cascade) DecryptBytesWithIVs(ctx context.Context, data []byte, ivs [][]byte) (<-chan []byte, <-chan error) {
+	return c.run(ctx, data, ivs, c.decryptSync)
+}
+
+func (c *Cascade) run(ctx context.Context, data []byte, ivs [][]byte, fn func(context.Context, []byte, [][]byte) ([]byte, error)) (<-chan []byte, <-chan error) {
+	return runAsync(ctx, func(ctx context.Context) ([]byte, error) {
+		return fn(ctx, data, ivs)
+	})
+}
+
+// Encrypt is the synchronous counterpart of EncryptBytes.
+func (c *Cascade) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	return c.EncryptWithIVs(ctx, data, nil)
+}
+
+func (c *Cascade) EncryptWithIVs(ctx context.Context, data []byte, ivs [][]byte) ([]byte, error) {
+	return c.encryptSync(ctx, data, ivs)
+}
+
+func (c *Cascade) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	return c.DecryptWithIVs(ctx, data, nil)
+}
+
+func (c *Cascade) DecryptWithIVs(ctx context.Context, data []byte, ivs [][]byte) ([]byte, error) {
+	return c.decryptSync(ctx, data, ivs)
+}
+
+func (c *Cascade) encryptSync(ctx context.Context, data []byte, ivs [][]byte) ([]byte, error) {
+	if err := c.checkIVs(ivs); err != nil {
+		return nil, err
+	}
+
+	for i, layer := range c.layers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		data, err = layer.encryptSync(ctx, data, c.layerIV(i, ivs))
+		if err != nil {
+			return nil, errors.Annotate(err, "cascade layer %d: %w", i)
+		}
+	}
+	return data, nil
+}
+
+func (c *Cascade) decryptSync(ctx context.Context, data []byte, ivs [][]byte) ([]byte, error) {
+	if err := c.checkIVs(ivs); err != nil {
+		return nil, err
+	}
+
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		data, err = c.layers[i].decryptSync(ctx, data, c.layerIV(i, ivs))
+		if err != nil {
+			return nil, errors.Annotate(err, "cascade layer %d: %w", i)
+		}
+	}
+	return data, nil
+}
+
+func (c *Cascade) checkIVs(ivs [][]byte) error {
+	if ivs == nil {
+		return nil
+	}
+	if len(ivs) != len(c.layers) {
+		return errors.Annotate(errors.ErrInvalidParameters, "expected %d layer IVs: %w", len(c.layers))
+	}
+	for i, iv := range ivs {
+		if iv != nil && len(iv) != c.layers[i].cipher.BlockSize() {
+			return errors.Annotate(errors.ErrInvalidIVSize, "cascade layer %d: %w", i)
+		}
+	}
+	return nil
+}
+
+func (c *Cascade) layerIV(i int, ivs [][]byte) []byte {
+	if ivs == nil || ivs[i] == nil {
+		return c.layers[i].iv
+	}
+	return ivs[i]
+}
@@ -0,0 +1,107 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+)
+
+func collect(results <-chan []byte, errs <-chan error) ([]byte, error) {
+	result := <-results
+	return result, <-errs
+}
+
+func newCascade(t *testing.T) *cipher.Cascade {
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	inner, err := cipher.NewCipherContext(block, []byte("0123456789abcdef"), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(bytes.Repeat([]byte{1}, 16)))
+	require.NoError(t, err)
+
+	outer, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.CTRMode{}, cipher.PKCS7, cipher.WithIV(bytes.Repeat([]byte{2}, 8)))
+	require.NoError(t, err)
+
+	cascade, err := cipher.NewCascade(inner, outer)
+	require.NoError(t, err)
+	return cascade
+}
+
+func TestCascadeRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cascade := newCascade(t)
+	assert.Equal(t, 2, cascade.Layers())
+
+	for _, size := range []int{0, 1, 15, 16, 17, 100} {
+		plaintext := bytes.Repeat([]byte{0x5A}, size)
+
+		encrypted, err := collect(cascade.EncryptBytes(ctx, plaintext))
+		require.NoError(t, err)
+		assert.Zero(t, len(encrypted)%8)
+		assert.Greater(t, len(encrypted), size)
+
+		decrypted, err := collect(cascade.DecryptBytes(ctx, encrypted))
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted, "size %d", size)
+	}
+}
+
+func TestCascadeMatchesLayeredContexts(t *testing.T) {
+	ctx := context.Background()
+	plaintext := []byte("belt and suspenders")
+
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	inner, err := cipher.NewCipherContext(block, []byte("0123456789abcdef"), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(bytes.Repeat([]byte{1}, 16)))
+	require.NoError(t, err)
+	outer, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.CTRMode{}, cipher.PKCS7, cipher.WithIV(bytes.Repeat([]byte{2}, 8)))
+	require.NoError(t, err)
+
+	first, err := collect(inner.EncryptBytes(ctx, plaintext))
+	require.NoError(t, err)
+	want, err := collect(outer.EncryptBytes(ctx, first))
+	require.NoError(t, err)
+
+	cascade, err := cipher.NewCascade(inner, outer)
+	require.NoError(t, err)
+	got, err := collect(cascade.EncryptBytes(ctx, plaintext))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestCascadeLayerIVs(t *testing.T) {
+	ctx := context.Background()
+	cascade := newCascade(t)
+	plaintext := []byte("per-layer initialisation vectors")
+	ivs := [][]byte{bytes.Repeat([]byte{7}, 16), nil}
+
+	defaultIVs, err := collect(cascade.EncryptBytes(ctx, plaintext))
+	require.NoError(t, err)
+	custom, err := collect(cascade.EncryptBytesWithIVs(ctx, plaintext, ivs))
+	require.NoError(t, err)
+	assert.NotEqual(t, defaultIVs, custom)
+
+	decrypted, err := collect(cascade.DecryptBytesWithIVs(ctx, custom, ivs))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	_, err = collect(cascade.EncryptBytesWithIVs(ctx, plaintext, ivs[:1]))
+	require.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = collect(cascade.EncryptBytesWithIVs(ctx, plaintext, [][]byte{make([]byte, 8), nil}))
+	require.ErrorIs(t, err, errors.ErrInvalidIVSize)
+}
+
+func TestCascadeInvalid(t *testing.T) {
+	_, err := cipher.NewCascade()
+	require.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = cipher.NewCascade(nil)
+	require.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
@@ -0,0 +1,96 @@
+package chacha20
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	KeySize   = 32
+	NonceSize = 12
+	BlockSize = 64
+)
+
+type ChaCha20 struct {
+	state     [16]uint32
+	counter   uint32
+	exhausted bool
+	buf       []byte
+}
+
+func New(key, nonce []byte, counter uint32) (*ChaCha20, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	if len(nonce) != NonceSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+
+	c := &ChaCha20{counter: counter}
+	c.state[0], c.state[1], c.state[2], c.state[3] = 0x61707865, 0x3320646e, 0x79622d32, 0x6b206574
+	for i := 0; i < 8; i++ {
+		c.state[4+i] = binary.LittleEndian.Uint32(key[i*4:])
+	}
+	for i := 0; i < 3; i++ {
+		c.state[13+i] = binary.LittleEndian.Uint32(nonce[i*4:])
+	}
+
+	return c, nil
+}
+
+func (c *ChaCha20) Block(counter uint32) []byte {
+	x := c.state
+	x[12] = counter
+	initial := x
+
+	for i := 0; i < 10; i++ {
+		quarterRound(&x, 0, 4, 8, 12)
+		quarterRound(&x, 1, 5, 9, 13)
+		quarterRound(&x, 2, 6, 10, 14)
+		quarterRound(&x, 3, 7, 11, 15)
+		quarterRound(&x, 0, 5, 10, 15)
+		quarterRound(&x, 1, 6, 11, 12)
+		quarterRound(&x, 2, 7, 8, 13)
+		quarterRound(&x, 3, 4, 9, 14)
+	}
+
+	out := make([]byte, BlockSize)
+	for i := range x {
+		binary.LittleEndian.PutUint32(out[i*4:], x[i]+initial[i])
+	}
+	return out
+}
+
+func (c *ChaCha20) XORKeyStream(dst, src []byte) error {
+	if len(dst) < len(src) {
+		return errors.ErrInvalidDataLength
+	}
+
+	for i := range src {
+		if len(c.buf) == 0 {
+			if c.exhausted {
+				return errors.ErrNonceExhausted
+			}
+			c.buf = c.Block(c.counter)
+			c.counter++
+			c.exhausted = c.counter == 0
+		}
+		dst[i] = src[i] ^ c.buf[0]
+		c.buf = c.buf[1:]
+	}
+
+	return nil
+}
+
+func quarterRound(x *[16]uint32, a, b, c, d int) {
+	x[a] += x[b]
+	x[d] = bits.RotateLeft32(x[d]^x[a], 16)
+	x[c] += x[d]
+	x[b] = bits.RotateLeft32(x[b]^x[c], 12)
+	x[a] += x[b]
+	x[d] = bits.RotateLeft32(x[d]^x[a], 8)
+	x[c] += x[d]
+	x[b] = bits.RotateLeft32(x[b]^x[c], 7)
+}
@@ -0,0 +1,60 @@
+package chacha20
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sequentialKey() []byte {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	return key
+}
+
+func TestBlockFunction(t *testing.T) {
+	nonce, _ := hex.DecodeString("000000090000004a00000000")
+	c, err := New(sequentialKey(), nonce, 1)
+	require.NoError(t, err)
+
+	want := "10f1e7e4d13b5915500fdd1fa32071c4c7d1f4c733c068030422aa9ac3d46c4e" +
+		"d2826446079faa0914c2d705d98b02a2b5129cd1de164eb9cbd083e8a2503c4e"
+	assert.Equal(t, want, hex.EncodeToString(c.Block(1)))
+}
+
+func TestEncryption(t *testing.T) {
+	nonce, _ := hex.DecodeString("000000000000004a00000000")
+	c, err := New(sequentialKey(), nonce, 1)
+	require.NoError(t, err)
+
+	plaintext := []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+	ciphertext := make([]byte, len(plaintext))
+	require.NoError(t, c.XORKeyStream(ciphertext[:10], plaintext[:10]))
+	require.NoError(t, c.XORKeyStream(ciphertext[10:], plaintext[10:]))
+
+	want := "6e2e359a2568f98041ba0728dd0d6981e97e7aec1d4360c20a27afccfd9fae0b" +
+		"f91b65c5524733ab8f593dabcd62b3571639d624e65152ab8f530c359f0861d8" +
+		"07ca0dbf500d6a6156a38e088a22b65e52bc514d16ccf806818ce91ab7793736" +
+		"5af90bbf74a35be6b40b8eedf2785e42874d"
+	assert.Equal(t, want, hex.EncodeToString(ciphertext))
+}
+
+func TestCounterExhausted(t *testing.T) {
+	c, err := New(sequentialKey(), make([]byte, NonceSize), 0xFFFFFFFF)
+	require.NoError(t, err)
+
+	buf := make([]byte, BlockSize+1)
+	assert.ErrorIs(t, c.XORKeyStream(buf, buf), errors.ErrNonceExhausted)
+}
+
+func TestInvalidSizes(t *testing.T) {
+	_, err := New(make([]byte, 16), make([]byte, NonceSize), 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+	_, err = New(sequentialKey(), make([]byte, 8), 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+}
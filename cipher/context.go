@@ -2,9 +2,7 @@ package cipher
 
 import (
 	"context"
-	"fmt"
-	"io"
-	"os"
+	"crypto/rand"
 
 	"github.com/masterkusok/crypto/errors"
 )
@@ -14,221 +12,213 @@ type CipherContext struct {
 	mode    CipherMode
 	padding PaddingScheme
 	iv      []byte
-	params  map[string]interface{}
+	options contextOptions
 }
 
-func NewCipherContext(cipher BlockCipher, key []byte, mode CipherMode, padding PaddingScheme, iv []byte, params ...interface{}) (*CipherContext, error) {
+func NewCipherContext(cipher BlockCipher, key []byte, mode CipherMode, padding PaddingScheme, opts ...Option) (*CipherContext, error) {
 	ctx := context.Background()
 	if err := cipher.SetKey(ctx, key); err != nil {
 		return nil, errors.Annotate(err, "failed to set key: %w")
 	}
 
-	if iv != nil && len(iv) != cipher.BlockSize() {
-		return nil, errors.ErrInvalidIVSize
+	var options contextOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if err := options.validate(cipher, mode); err != nil {
+		return nil, err
 	}
 
-	paramsMap := make(map[string]interface{})
-	for i := 0; i < len(params); i += 2 {
-		if i+1 < len(params) {
-			if key, ok := params[i].(string); ok {
-				paramsMap[key] = params[i+1]
-			}
-		}
+	if options.segmentSizeSet {
+		cfb := *mode.(*CFBMode)
+		cfb.SegmentSize = options.segmentSize
+		mode = &cfb
 	}
 
 	return &CipherContext{
 		cipher:  cipher,
 		mode:    mode,
 		padding: padding,
-		iv:      iv,
-		params:  paramsMap,
+		iv:      options.iv,
+		options: options,
 	}, nil
 }
 
-func (c *CipherContext) EncryptBytes(ctx context.Context, data []byte) (<-chan []byte, <-chan error) {
-	resultChan := make(chan []byte, 1)
-	errChan := make(chan error, 1)
+// IV returns a copy of the IV set with WithIV. It is nil under
+// WithRandomIV, where every ciphertext starts with its own IV.
+func (c *CipherContext) IV() []byte {
+	if c.iv == nil {
+		return nil
+	}
+	return append([]byte{}, c.iv...)
+}
+
+// messageIV returns the IV for a new message: a fresh one under
+// WithRandomIV, the configured one otherwise.
+func (c *CipherContext) messageIV() ([]byte, error) {
+	if !c.options.randomIV {
+		return c.iv, nil
+	}
 
-	go func() {
-		defer close(resultChan)
-		defer close(errChan)
+	iv := make([]byte, c.cipher.BlockSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, errors.Annotate(err, "generating IV: %w")
+	}
+	return iv, nil
+}
 
-		select {
-		case <-ctx.Done():
-			errChan <- ctx.Err()
-			return
-		default:
-		}
+// startIV applies WithCounter to iv.
+func (c *CipherContext) startIV(iv []byte) []byte {
+	if !c.options.counterSet {
+		return iv
+	}
 
-		result, err := c.encryptSync(ctx, data)
-		if err != nil {
-			errChan <- err
-			return
-		}
-		resultChan <- result
-	}()
+	start := make([]byte, c.cipher.BlockSize())
+	copy(start, iv)
+	addCounter(start, c.options.counter)
+	return start
+}
+
+func (c *CipherContext) EncryptBytes(ctx context.Context, data []byte) (<-chan []byte, <-chan error) {
+	return runAsync(ctx, func(ctx context.Context) ([]byte, error) {
+		return c.Encrypt(ctx, data)
+	})
+}
 
-	return resultChan, errChan
+func (c *CipherContext) EncryptBytesWithIV(ctx context.Context, data, iv []byte) (<-chan []byte, <-chan error) {
+	return runAsync(ctx, func(ctx context.Context) ([]byte, error) {
+		return c.EncryptWithIV(ctx, data, iv)
+	})
 }
 
 func (c *CipherContext) DecryptBytes(ctx context.Context, data []byte) (<-chan []byte, <-chan error) {
-	resultChan := make(chan []byte, 1)
-	errChan := make(chan error, 1)
+	return runAsync(ctx, func(ctx context.Context) ([]byte, error) {
+		return c.Decrypt(ctx, data)
+	})
+}
 
-	go func() {
-		defer close(resultChan)
-		defer close(errChan)
+func (c *CipherContext) DecryptBytesWithIV(ctx context.Context, data, iv []byte) (<-chan []byte, <-chan error) {
+	return runAsync(ctx, func(ctx context.Context) ([]byte, error) {
+		return c.DecryptWithIV(ctx, data, iv)
+	})
+}
 
-		select {
-		case <-ctx.Done():
-			errChan <- ctx.Err()
-			return
-		default:
-		}
+// Encrypt is the synchronous counterpart of EncryptBytes. It runs on the
+// calling goroutine and checks ctx between blocks, which avoids the channel
+// round trip for small messages. Under WithRandomIV the output is the fresh
+// IV followed by the ciphertext.
+func (c *CipherContext) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	iv, err := c.messageIV()
+	if err != nil {
+		return nil, err
+	}
 
-		result, err := c.decryptSync(ctx, data)
-		if err != nil {
-			errChan <- err
-			return
-		}
-		resultChan <- result
-	}()
+	encrypted, err := c.EncryptWithIV(ctx, data, iv)
+	if err != nil || !c.options.randomIV {
+		return encrypted, err
+	}
+	return append(iv, encrypted...), nil
+}
 
-	return resultChan, errChan
+// EncryptWithIV encrypts with an explicit IV, which is not prepended to the
+// output even under WithRandomIV.
+func (c *CipherContext) EncryptWithIV(ctx context.Context, data, iv []byte) ([]byte, error) {
+	if err := c.checkCall(ctx, iv); err != nil {
+		return nil, err
+	}
+	return c.encryptSync(ctx, data, c.startIV(iv))
 }
 
-func (c *CipherContext) encryptSync(ctx context.Context, data []byte) ([]byte, error) {
-	padded, err := Pad(data, c.cipher.BlockSize(), c.padding)
+func (c *CipherContext) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	if !c.options.randomIV {
+		return c.DecryptWithIV(ctx, data, c.iv)
+	}
+
+	blockSize := c.cipher.BlockSize()
+	if len(data) < blockSize {
+		return nil, errors.ErrInvalidDataLength
+	}
+	return c.DecryptWithIV(ctx, data[blockSize:], data[:blockSize])
+}
+
+func (c *CipherContext) DecryptWithIV(ctx context.Context, data, iv []byte) ([]byte, error) {
+	if err := c.checkCall(ctx, iv); err != nil {
+		return nil, err
+	}
+	return c.decryptSync(ctx, data, c.startIV(iv))
+}
+
+func (c *CipherContext) checkCall(ctx context.Context, iv []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if iv != nil && len(iv) != c.cipher.BlockSize() {
+		return errors.ErrInvalidIVSize
+	}
+	return nil
+}
+
+func (c *CipherContext) encryptSync(ctx context.Context, data, iv []byte) ([]byte, error) {
+	padded, err := c.pad(data)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.mode.Encrypt(ctx, c.cipher, padded, c.iv)
+	return c.mode.Encrypt(c.withSettings(ctx), c.cipher, padded, iv)
 }
 
-func (c *CipherContext) decryptSync(ctx context.Context, data []byte) ([]byte, error) {
-	decrypted, err := c.mode.Decrypt(ctx, c.cipher, data, c.iv)
+func (c *CipherContext) decryptSync(ctx context.Context, data, iv []byte) ([]byte, error) {
+	decrypted, err := c.mode.Decrypt(c.withSettings(ctx), c.cipher, data, iv)
 	if err != nil {
 		return nil, err
 	}
 
-	return Unpad(decrypted, c.padding)
-}
-
-func (c *CipherContext) EncryptFile(ctx context.Context, inputPath, outputPath string) error {
-	errChan := make(chan error, 1)
-
-	go func() {
-		inFile, err := os.Open(inputPath)
-		if err != nil {
-			errChan <- fmt.Errorf("opening file: %w", err)
-			return
-		}
-		defer inFile.Close()
-
-		outFile, err := os.Create(outputPath)
-		if err != nil {
-			errChan <- fmt.Errorf("creating output file: %w", err)
-			return
-		}
-		defer outFile.Close()
-
-		const chunkSize = 1024 * 1024
-		buf := make([]byte, chunkSize)
-		var buffer []byte
-		for {
-			n, err := inFile.Read(buf)
-			if n > 0 {
-				buffer = append(buffer, buf[:n]...)
-			}
-
-			if err == io.EOF {
-				break
-			}
-
-			if err != nil {
-				errChan <- fmt.Errorf("reading file: %w", err)
-				return
-			}
-		}
-
-		encrypted, err := c.encryptSync(ctx, buffer)
-		if err != nil {
-			errChan <- err
-			return
-		}
-
-		if _, err := outFile.Write(encrypted); err != nil {
-			errChan <- fmt.Errorf("writing output file: %w", err)
-			return
-		}
-
-		errChan <- nil
-	}()
-
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-errChan:
-		return err
+	return c.unpad(decrypted)
+}
+
+func (c *CipherContext) pad(data []byte) ([]byte, error) {
+	if c.lengthPreserving() {
+		return data, nil
 	}
+	return Pad(data, c.cipher.BlockSize(), c.padding)
 }
 
-func (c *CipherContext) DecryptFile(ctx context.Context, inputPath, outputPath string) error {
-	errChan := make(chan error, 1)
-
-	go func() {
-		inFile, err := os.Open(inputPath)
-		if err != nil {
-			errChan <- fmt.Errorf("opening file: %w", err)
-			return
-		}
-		defer inFile.Close()
-
-		outFile, err := os.Create(outputPath)
-		if err != nil {
-			errChan <- fmt.Errorf("creating output file: %w", err)
-			return
-		}
-		defer outFile.Close()
-
-		const chunkSize = 1024 * 1024
-		buf := make([]byte, chunkSize)
-		var buffer []byte
-		for {
-			n, err := inFile.Read(buf)
-			if n > 0 {
-				buffer = append(buffer, buf[:n]...)
-			}
-
-			if err == io.EOF {
-				break
-			}
-
-			if err != nil {
-				errChan <- fmt.Errorf("reading file: %w", err)
-				return
-			}
-		}
-
-		decrypted, err := c.decryptSync(ctx, buffer)
-		if err != nil {
-			errChan <- err
-			return
-		}
-
-		if _, err := outFile.Write(decrypted); err != nil {
-			errChan <- fmt.Errorf("writing output file: %w", err)
-			return
-		}
-
-		errChan <- nil
-	}()
-
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-errChan:
-		return err
+func (c *CipherContext) unpad(data []byte) ([]byte, error) {
+	if c.lengthPreserving() {
+		return data, nil
 	}
+	return unpad(data, c.cipher.BlockSize(), c.padding, c.options.lenientPadding)
+}
+
+// lengthPreserving reports whether data of any length is passed to the mode
+// as is. CFB, OFB and CTR turn the block cipher into a stream cipher, so
+// they never pad, whatever scheme the context was given, and their
+// ciphertext is exactly as long as the plaintext.
+func (c *CipherContext) lengthPreserving() bool {
+	return isStreamMode(c.mode)
+}
+
+func isStreamMode(mode CipherMode) bool {
+	switch mode.(type) {
+	case *CTRMode, *OFBMode, *CFBMode:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *CipherContext) withSettings(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(settingsKey{}).(Settings); ok {
+		return ctx
+	}
+
+	settings, _ := CalibratedSettings(c.cipher, c.mode)
+	if c.options.workers > 0 {
+		settings.Workers = c.options.workers
+	}
+	if c.options.chunkSize > 0 {
+		settings.ChunkSize = c.options.chunkSize
+	}
+
+	return WithSettings(ctx, settings)
 }
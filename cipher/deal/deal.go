@@ -10,26 +10,64 @@ import (
 
 const (
 	dealBlockSize = 16
-	dealKeySize   = 24
-	dealRounds    = 6
+
+	desBlockSize = 8
 )
 
+// scheduleKey is the fixed DES key Knudsen's key schedule encrypts under.
+var scheduleKey = []byte{0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef}
+
 type KeyScheduler struct{}
 
+// GenerateRoundKeys implements the DEAL-128/192/256 schedule: with the key
+// split into s DES blocks K1..Ks, RK_i = E_K(K_(i mod s) ^ <c> ^ RK_(i-1)),
+// where <c> is zero on the first pass over the key blocks and 1, 2, 4, 8
+// afterwards. DEAL-128 and DEAL-192 use 6 rounds, DEAL-256 uses 8.
 func (k *KeyScheduler) GenerateRoundKeys(ctx context.Context, key []byte) ([][]byte, error) {
-	if len(key) != dealKeySize {
-		return nil, errors.ErrInvalidKeySize
+	rounds, err := Rounds(len(key))
+	if err != nil {
+		return nil, err
+	}
+
+	block := des.NewDES()
+	if err := block.SetKey(ctx, scheduleKey); err != nil {
+		return nil, errors.Annotate(err, "failed to set schedule key: %w")
 	}
 
-	roundKeys := make([][]byte, dealRounds)
-	for i := 0; i < dealRounds; i++ {
-		roundKeys[i] = make([]byte, 8)
-		copy(roundKeys[i], key[i%3*8:(i%3+1)*8])
+	s := len(key) / desBlockSize
+	roundKeys := make([][]byte, rounds)
+	previous := make([]byte, desBlockSize)
+	for i := range roundKeys {
+		input := make([]byte, desBlockSize)
+		j := i % s
+		for b := range input {
+			input[b] = key[j*desBlockSize+b] ^ previous[b]
+		}
+		if i >= s {
+			input[desBlockSize-1] ^= 1 << (i - s)
+		}
+
+		roundKeys[i], err = block.Encrypt(ctx, input)
+		if err != nil {
+			return nil, errors.Annotate(err, "round key %d: %w", i+1)
+		}
+		previous = roundKeys[i]
 	}
 
 	return roundKeys, nil
 }
 
+func Rounds(keySize int) (int, error) {
+	switch keySize {
+	case 16, 24:
+		return 6, nil
+	case 32:
+		return 8, nil
+	default:
+		return 0, errors.ErrInvalidKeySize
+	}
+}
+
 type DESAdapter struct {
 	des cipher.BlockCipher
 }
@@ -39,7 +77,7 @@ func NewDESAdapter() *DESAdapter {
 }
 
 func (a *DESAdapter) Transform(ctx context.Context, block, roundKey []byte) ([]byte, error) {
-	if len(block) != 8 {
+	if len(block) != desBlockSize {
 		return nil, errors.ErrInvalidBlockSize
 	}
 
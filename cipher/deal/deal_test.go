@@ -2,9 +2,12 @@ package deal_test
 
 import (
 	"context"
+	"encoding/hex"
 	"testing"
 
 	"github.com/masterkusok/crypto/cipher/deal"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -41,9 +44,91 @@ func TestDEALInvalidKeySize(t *testing.T) {
 
 	err := d.SetKey(ctx, []byte{0x01, 0x02, 0x03})
 	assert.Error(t, err)
+
+	for _, size := range []int{8, 20, 40} {
+		assert.ErrorIs(t, d.SetKey(ctx, make([]byte, size)), errors.ErrInvalidKeySize)
+	}
 }
 
 func TestDEALBlockSize(t *testing.T) {
 	d := deal.NewDEAL()
 	assert.Equal(t, 16, d.BlockSize())
 }
+
+func sequence(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestDEALKeySchedule(t *testing.T) {
+	ctx := context.Background()
+	fixed := des.NewDES()
+	require.NoError(t, fixed.SetKey(ctx, []byte{0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef}))
+	encrypt := func(parts ...[]byte) []byte {
+		block := make([]byte, 8)
+		for _, part := range parts {
+			for i := range block {
+				block[i] ^= part[i]
+			}
+		}
+		out, err := fixed.Encrypt(ctx, block)
+		require.NoError(t, err)
+		return out
+	}
+	constant := func(c byte) []byte { return []byte{0, 0, 0, 0, 0, 0, 0, c} }
+
+	for _, size := range []int{16, 24, 32} {
+		key := sequence(size)
+		blocks := make([][]byte, size/8)
+		for i := range blocks {
+			blocks[i] = key[8*i : 8*i+8]
+		}
+
+		roundKeys, err := (&deal.KeyScheduler{}).GenerateRoundKeys(ctx, key)
+		require.NoError(t, err)
+		rounds, err := deal.Rounds(size)
+		require.NoError(t, err)
+		require.Len(t, roundKeys, rounds)
+
+		previous := make([]byte, 8)
+		for i, rk := range roundKeys {
+			c := constant(0)
+			if i >= len(blocks) {
+				c = constant(1 << (i - len(blocks)))
+			}
+			assert.Equal(t, encrypt(blocks[i%len(blocks)], c, previous), rk, "key size %d round %d", size, i+1)
+			previous = rk
+		}
+	}
+}
+
+func TestDEALVectors(t *testing.T) {
+	ctx := context.Background()
+	vectors := []struct {
+		keySize    int
+		rounds     int
+		ciphertext string
+	}{
+		{16, 6, "db7e1fa60dedd65cf792068124c3a07e"},
+		{24, 6, "5e5248a8a868de9891d7c5ae8a11df89"},
+		{32, 8, "3256cfc3161c6984fe8d54d6c5dce6c3"},
+	}
+	plaintext := []byte("0123456789abcdef")
+
+	for _, v := range vectors {
+		d := deal.NewDEAL()
+		require.NoError(t, d.SetKey(ctx, sequence(v.keySize)))
+		assert.Len(t, d.RoundKeys, v.rounds)
+
+		encrypted, err := d.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, v.ciphertext, hex.EncodeToString(encrypted), "DEAL-%d", 8*v.keySize)
+
+		decrypted, err := d.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
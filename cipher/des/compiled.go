@@ -0,0 +1,103 @@
+package des
+
+import "encoding/binary"
+
+// permutation is a bit permutation compiled into one lookup table per input
+// byte: the output is the OR of the entries selected by each byte, so a
+// permutation costs a handful of loads instead of one bit operation per
+// output bit. Inputs and outputs are right-aligned with table index 1 as
+// the most significant bit, matching bits.MSBFirst with bits.StartFromOne.
+type permutation struct {
+	table [][256]uint64
+}
+
+func compilePermutation(pTable []int, inputBits int) *permutation {
+	p := &permutation{table: make([][256]uint64, (inputBits+7)/8)}
+	outputBits := len(pTable)
+
+	for i, pos := range pTable {
+		inBit := inputBits - pos
+		outBit := uint64(1) << (outputBits - 1 - i)
+		chunk, shift := inBit/8, inBit%8
+		for v := 0; v < 256; v++ {
+			if v>>shift&1 == 1 {
+				p.table[chunk][v] |= outBit
+			}
+		}
+	}
+	return p
+}
+
+func (p *permutation) apply(x uint64) uint64 {
+	var out uint64
+	for i := range p.table {
+		out |= p.table[i][byte(x>>(8*i))]
+	}
+	return out
+}
+
+type compiled struct {
+	ip, fp, expansion, pc1, pc2 *permutation
+	sp                          [8][64]uint32
+	shifts                      []int
+}
+
+func compileTables(t *Tables) *compiled {
+	c := &compiled{
+		ip:        compilePermutation(t.InitialPermutation, 64),
+		fp:        compilePermutation(t.FinalPermutation, 64),
+		expansion: compilePermutation(t.Expansion, 32),
+		pc1:       compilePermutation(t.PC1, 64),
+		pc2:       compilePermutation(t.PC2, 56),
+		shifts:    t.KeyShifts,
+	}
+
+	p := compilePermutation(t.Permutation, 32)
+	for i := range c.sp {
+		// SBoxFunction cannot fail for indices within the S-box array.
+		sbox, _ := t.SBoxFunction(i)
+		for x, v := range sbox {
+			c.sp[i][x] = uint32(p.apply(uint64(v) << (28 - 4*i)))
+		}
+	}
+	return c
+}
+
+func (c *compiled) roundKeys(key []byte) [numRounds]uint64 {
+	cd := c.pc1.apply(binary.BigEndian.Uint64(key))
+	left, right := uint32(cd>>28), uint32(cd&mask28)
+
+	var keys [numRounds]uint64
+	for i := range keys {
+		left = rotate28(left, c.shifts[i])
+		right = rotate28(right, c.shifts[i])
+		keys[i] = c.pc2.apply(uint64(left)<<28 | uint64(right))
+	}
+	return keys
+}
+
+func (c *compiled) feistel(r uint32, key uint64) uint32 {
+	x := c.expansion.apply(uint64(r)) ^ key
+	var out uint32
+	for i := range c.sp {
+		out |= c.sp[i][x>>(42-6*i)&0x3F]
+	}
+	return out
+}
+
+func (c *compiled) crypt(block []byte, keys *[numRounds]uint64, decrypt bool) []byte {
+	x := c.ip.apply(binary.BigEndian.Uint64(block))
+	l, r := uint32(x>>32), uint32(x)
+
+	for i := 0; i < numRounds; i++ {
+		k := keys[i]
+		if decrypt {
+			k = keys[numRounds-1-i]
+		}
+		l, r = r, l^c.feistel(r, k)
+	}
+
+	out := make([]byte, desBlockSize)
+	binary.BigEndian.PutUint64(out, c.fp.apply(uint64(r)<<32|uint64(l)))
+	return out
+}
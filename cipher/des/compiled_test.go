@@ -0,0 +1,124 @@
+package des
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	mathrand "math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/bits"
+	"github.com/masterkusok/crypto/errors"
+)
+
+func TestCompiledPermutationMatchesBits(t *testing.T) {
+	tables := DefaultTables()
+	cases := []struct {
+		table     []int
+		inputBits int
+	}{
+		{tables.InitialPermutation, 64},
+		{tables.FinalPermutation, 64},
+		{tables.Expansion, 32},
+		{tables.Permutation, 32},
+		{tables.PC1, 64},
+		{tables.PC2, 56},
+	}
+
+	for _, c := range cases {
+		p := compilePermutation(c.table, c.inputBits)
+		for i := 0; i < 32; i++ {
+			input := make([]byte, c.inputBits/8)
+			_, err := rand.Read(input)
+			require.NoError(t, err)
+
+			want, err := bits.Permute(input, c.table, bits.MSBFirst, bits.StartFromOne)
+			require.NoError(t, err)
+
+			padded := append(make([]byte, 8-len(input)), input...)
+			got := p.apply(binary.BigEndian.Uint64(padded))
+			gotBytes := binary.BigEndian.AppendUint64(nil, got<<(64-len(c.table)))
+			assert.Equal(t, want, gotBytes[:len(want)])
+		}
+	}
+}
+
+func TestCompiledMatchesReference(t *testing.T) {
+	ctx := context.Background()
+	rng := mathrand.New(mathrand.NewSource(2))
+
+	random := DefaultTables()
+	for i := range random.SBoxes {
+		for row := 0; row < 4; row++ {
+			for col, v := range rng.Perm(16) {
+				random.SBoxes[i][row*16+col] = byte(v)
+			}
+		}
+	}
+
+	for _, tables := range []*Tables{DefaultTables(), random} {
+		d, err := NewDESWithTables(tables)
+		require.NoError(t, err)
+
+		for i := 0; i < 16; i++ {
+			key := make([]byte, desKeySize)
+			_, err := rand.Read(key)
+			require.NoError(t, err)
+			require.NoError(t, d.SetKey(ctx, key))
+
+			block := make([]byte, desBlockSize)
+			_, err = rand.Read(block)
+			require.NoError(t, err)
+
+			encrypted, err := d.Encrypt(ctx, block)
+			require.NoError(t, err)
+			reference, err := d.encryptReference(ctx, block)
+			require.NoError(t, err)
+			assert.Equal(t, reference, encrypted)
+
+			decrypted, err := d.Decrypt(ctx, encrypted)
+			require.NoError(t, err)
+			reference, err = d.decryptReference(ctx, encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, reference, decrypted)
+			assert.Equal(t, block, decrypted)
+		}
+	}
+}
+
+func TestEncryptWithoutKey(t *testing.T) {
+	_, err := NewDES().Encrypt(context.Background(), make([]byte, desBlockSize))
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
+
+func benchmarkDES(b *testing.B, encrypt func(d *DES, block []byte)) {
+	d := NewDES()
+	require.NoError(b, d.SetKey(context.Background(), []byte("8bytekey")))
+
+	block := make([]byte, desBlockSize)
+	b.SetBytes(int64(len(block)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encrypt(d, block)
+	}
+}
+
+func BenchmarkEncryptCompiled(b *testing.B) {
+	benchmarkDES(b, func(d *DES, block []byte) { d.Encrypt(context.Background(), block) })
+}
+
+func BenchmarkEncryptReference(b *testing.B) {
+	benchmarkDES(b, func(d *DES, block []byte) { d.encryptReference(context.Background(), block) })
+}
+
+func BenchmarkSetKeyCompiled(b *testing.B) {
+	d := NewDES()
+	key := []byte("8bytekey")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.keys = d.compiled.roundKeys(key)
+	}
+}
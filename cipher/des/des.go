@@ -2,11 +2,11 @@ package des
 
 import (
 	"context"
+	"sync"
 
 	"github.com/masterkusok/crypto/bits"
 	"github.com/masterkusok/crypto/cipher"
 	"github.com/masterkusok/crypto/errors"
-	"github.com/masterkusok/crypto/tables"
 )
 
 const (
@@ -15,30 +15,38 @@ const (
 	numRounds    = 16
 )
 
-type KeyScheduler struct{}
+type KeyScheduler struct {
+	Tables *Tables
+}
 
 func (k *KeyScheduler) GenerateRoundKeys(ctx context.Context, key []byte) ([][]byte, error) {
 	if len(key) != desKeySize {
 		return nil, errors.ErrInvalidKeySize
 	}
+	t := orDefault(k.Tables)
 
-	permuted, err := bits.Permute(key, tables.PC1, bits.MSBFirst, bits.StartFromOne)
+	permuted, err := bits.Permute(key, t.PC1, bits.MSBFirst, bits.StartFromOne)
 	if err != nil {
 		return nil, errors.Annotate(err, "PC1 permutation failed: %w")
 	}
 
-	c := make([]byte, 4)
-	d := make([]byte, 4)
-	copy(c, permuted[:4])
-	copy(d, permuted[4:])
+	cd := uint64(0)
+	for _, b := range permuted {
+		cd = cd<<8 | uint64(b)
+	}
+	c, d := uint32(cd>>28), uint32(cd&mask28)
 
 	roundKeys := make([][]byte, numRounds)
 	for i := 0; i < numRounds; i++ {
-		c = leftShift28(c, tables.KeyShifts[i])
-		d = leftShift28(d, tables.KeyShifts[i])
+		c = rotate28(c, t.KeyShifts[i])
+		d = rotate28(d, t.KeyShifts[i])
 
-		cd := append(append([]byte{}, c...), d...)
-		roundKeys[i], err = bits.Permute(cd, tables.PC2, bits.MSBFirst, bits.StartFromOne)
+		joined := uint64(c)<<28 | uint64(d)
+		halves := make([]byte, 7)
+		for j := range halves {
+			halves[j] = byte(joined >> (8 * (6 - j)))
+		}
+		roundKeys[i], err = bits.Permute(halves, t.PC2, bits.MSBFirst, bits.StartFromOne)
 		if err != nil {
 			return nil, errors.Annotate(err, "PC2 permutation failed: %w")
 		}
@@ -47,26 +55,20 @@ func (k *KeyScheduler) GenerateRoundKeys(ctx context.Context, key []byte) ([][]b
 	return roundKeys, nil
 }
 
-func leftShift28(data []byte, shifts int) []byte {
-	result := make([]byte, len(data))
-	copy(result, data)
-
-	for s := 0; s < shifts; s++ {
-		carry := (result[0] >> 7) & 1
-		for i := 0; i < len(result)-1; i++ {
-			result[i] = (result[i] << 1) | ((result[i+1] >> 7) & 1)
-		}
-		result[len(result)-1] = (result[len(result)-1] << 1) | carry
-		result[len(result)-1] &= 0x0F
-	}
+const mask28 = 1<<28 - 1
 
-	return result
+func rotate28(x uint32, shifts int) uint32 {
+	return (x<<shifts | x>>(28-shifts)) & mask28
 }
 
-type RoundFunction struct{}
+type RoundFunction struct {
+	Tables *Tables
+}
 
 func (r *RoundFunction) Transform(ctx context.Context, block, roundKey []byte) ([]byte, error) {
-	expanded, err := bits.Permute(block, tables.ExpansionTable, bits.MSBFirst, bits.StartFromOne)
+	t := orDefault(r.Tables)
+
+	expanded, err := bits.Permute(block, t.Expansion, bits.MSBFirst, bits.StartFromOne)
 	if err != nil {
 		return nil, errors.Annotate(err, "expansion failed: %w")
 	}
@@ -79,9 +81,9 @@ func (r *RoundFunction) Transform(ctx context.Context, block, roundKey []byte) (
 	sboxOutput := make([]byte, 4)
 	for i := 0; i < 8; i++ {
 		sixBits := getSixBits(xored, i)
-		row := ((sixBits >> 5) & 1) | ((sixBits & 1) << 1)
+		row := ((sixBits >> 4) & 2) | (sixBits & 1)
 		col := (sixBits >> 1) & 0x0F
-		val := tables.SBoxes[i][row*16+col]
+		val := t.SBoxes[i][row*16+col]
 
 		if i%2 == 0 {
 			sboxOutput[i/2] |= val << 4
@@ -90,7 +92,7 @@ func (r *RoundFunction) Transform(ctx context.Context, block, roundKey []byte) (
 		}
 	}
 
-	return bits.Permute(sboxOutput, tables.PPermutation, bits.MSBFirst, bits.StartFromOne)
+	return bits.Permute(sboxOutput, t.Permutation, bits.MSBFirst, bits.StartFromOne)
 }
 
 func getSixBits(data []byte, index int) byte {
@@ -107,20 +109,85 @@ func getSixBits(data []byte, index int) byte {
 
 type DES struct {
 	*cipher.FeistelNetwork
+	tables   *Tables
+	compiled *compiled
+	keys     [numRounds]uint64
+	keyed    bool
 }
 
+var (
+	defaultCompiledOnce sync.Once
+	defaultCompiled     *compiled
+)
+
 func NewDES() *DES {
+	defaultCompiledOnce.Do(func() {
+		defaultCompiled = compileTables(defaultTables)
+	})
+	return newDES(defaultTables, defaultCompiled)
+}
+
+// NewDESWithTables compiles the tables once, so later changes to t do not
+// affect the returned cipher.
+func NewDESWithTables(t *Tables) (*DES, error) {
+	if t == nil {
+		return nil, errors.ErrInvalidParameters
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	return newDES(t, compileTables(t)), nil
+}
+
+func newDES(t *Tables, c *compiled) *DES {
 	return &DES{
-		FeistelNetwork: cipher.NewFeistelNetwork(&KeyScheduler{}, &RoundFunction{}, desBlockSize),
+		FeistelNetwork: cipher.NewFeistelNetwork(&KeyScheduler{Tables: t}, &RoundFunction{Tables: t}, desBlockSize),
+		tables:         t,
+		compiled:       c,
 	}
 }
 
+func (d *DES) SetKey(ctx context.Context, key []byte) error {
+	if err := d.FeistelNetwork.SetKey(ctx, key); err != nil {
+		return err
+	}
+
+	d.keys = d.compiled.roundKeys(key)
+	d.keyed = true
+	return nil
+}
+
 func (d *DES) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := d.check(block); err != nil {
+		return nil, err
+	}
+	return d.compiled.crypt(block, &d.keys, false), nil
+}
+
+func (d *DES) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := d.check(block); err != nil {
+		return nil, err
+	}
+	return d.compiled.crypt(block, &d.keys, true), nil
+}
+
+func (d *DES) check(block []byte) error {
+	if len(block) != desBlockSize {
+		return errors.ErrInvalidBlockSize
+	}
+	if !d.keyed {
+		return errors.ErrInvalidKeySize
+	}
+	return nil
+}
+
+func (d *DES) encryptReference(ctx context.Context, block []byte) ([]byte, error) {
 	if len(block) != desBlockSize {
 		return nil, errors.ErrInvalidBlockSize
 	}
 
-	permuted, err := bits.Permute(block, tables.InitialPermutation, bits.MSBFirst, bits.StartFromOne)
+	permuted, err := bits.Permute(block, d.tables.InitialPermutation, bits.MSBFirst, bits.StartFromOne)
 	if err != nil {
 		return nil, errors.Annotate(err, "initial permutation failed: %w")
 	}
@@ -130,23 +197,32 @@ func (d *DES) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return bits.Permute(encrypted, tables.FinalPermutation, bits.MSBFirst, bits.StartFromOne)
+	// DES outputs R16 || L16, while the network leaves the halves unswapped.
+	return bits.Permute(swapHalves(encrypted), d.tables.FinalPermutation, bits.MSBFirst, bits.StartFromOne)
 }
 
-func (d *DES) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+func (d *DES) decryptReference(ctx context.Context, block []byte) ([]byte, error) {
 	if len(block) != desBlockSize {
 		return nil, errors.ErrInvalidBlockSize
 	}
 
-	permuted, err := bits.Permute(block, tables.InitialPermutation, bits.MSBFirst, bits.StartFromOne)
+	permuted, err := bits.Permute(block, d.tables.InitialPermutation, bits.MSBFirst, bits.StartFromOne)
 	if err != nil {
 		return nil, errors.Annotate(err, "initial permutation failed: %w")
 	}
 
-	decrypted, err := d.FeistelNetwork.Decrypt(ctx, permuted)
+	decrypted, err := d.FeistelNetwork.Decrypt(ctx, swapHalves(permuted))
 	if err != nil {
 		return nil, err
 	}
 
-	return bits.Permute(decrypted, tables.FinalPermutation, bits.MSBFirst, bits.StartFromOne)
+	return bits.Permute(decrypted, d.tables.FinalPermutation, bits.MSBFirst, bits.StartFromOne)
+}
+
+func swapHalves(block []byte) []byte {
+	out := make([]byte, len(block))
+	half := len(block) / 2
+	copy(out, block[half:])
+	copy(out[half:], block[:half])
+	return out
 }
@@ -2,6 +2,7 @@ package des
 
 import (
 	"context"
+	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,3 +26,32 @@ func TestDESEncryptDecrypt(t *testing.T) {
 
 	assert.Equal(t, plaintext, decrypted)
 }
+
+func TestDESVectors(t *testing.T) {
+	ctx := context.Background()
+	vectors := []struct {
+		key, plaintext, ciphertext string
+	}{
+		{"133457799bbcdff1", "0123456789abcdef", "85e813540f0ab405"},
+		{"0e329232ea6d0d73", "8787878787878787", "0000000000000000"},
+		{"0101010101010101", "95f8a5e5dd31d900", "8000000000000000"},
+	}
+
+	for _, v := range vectors {
+		key, err := hex.DecodeString(v.key)
+		require.NoError(t, err)
+		plaintext, err := hex.DecodeString(v.plaintext)
+		require.NoError(t, err)
+
+		des := NewDES()
+		require.NoError(t, des.SetKey(ctx, key))
+
+		encrypted, err := des.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, v.ciphertext, hex.EncodeToString(encrypted))
+
+		decrypted, err := des.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
@@ -0,0 +1,99 @@
+package des
+
+import (
+	"slices"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/tables"
+)
+
+type Tables struct {
+	InitialPermutation []int
+	FinalPermutation   []int
+	Expansion          []int
+	Permutation        []int
+	PC1                []int
+	PC2                []int
+	KeyShifts          []int
+	SBoxes             [8][64]byte
+}
+
+func DefaultTables() *Tables {
+	return &Tables{
+		InitialPermutation: slices.Clone(tables.InitialPermutation),
+		FinalPermutation:   slices.Clone(tables.FinalPermutation),
+		Expansion:          slices.Clone(tables.ExpansionTable),
+		Permutation:        slices.Clone(tables.PPermutation),
+		PC1:                slices.Clone(tables.PC1),
+		PC2:                slices.Clone(tables.PC2),
+		KeyShifts:          slices.Clone(tables.KeyShifts),
+		SBoxes:             tables.SBoxes,
+	}
+}
+
+var defaultTables = DefaultTables()
+
+func (t *Tables) validate() error {
+	checks := []struct {
+		name   string
+		table  []int
+		size   int
+		inputs int
+	}{
+		{"initial permutation", t.InitialPermutation, 64, 64},
+		{"final permutation", t.FinalPermutation, 64, 64},
+		{"expansion", t.Expansion, 48, 32},
+		{"permutation", t.Permutation, 32, 32},
+		{"PC1", t.PC1, 56, 64},
+		{"PC2", t.PC2, 48, 56},
+	}
+	for _, c := range checks {
+		if len(c.table) != c.size {
+			return errors.Annotate(errors.ErrInvalidPTableSize, c.name+" must have %d entries: %w", c.size)
+		}
+		for _, idx := range c.table {
+			if idx < 1 || idx > c.inputs {
+				return errors.Annotate(errors.ErrInvalidBitIndex, c.name+" index %d out of range: %w", idx)
+			}
+		}
+	}
+
+	if len(t.KeyShifts) != numRounds {
+		return errors.Annotate(errors.ErrInvalidParameters, "key shifts must have %d entries: %w", numRounds)
+	}
+	for _, shift := range t.KeyShifts {
+		if shift < 0 || shift > 27 {
+			return errors.Annotate(errors.ErrInvalidParameters, "key shift %d out of range: %w", shift)
+		}
+	}
+
+	for i, sbox := range t.SBoxes {
+		for _, val := range sbox {
+			if val > 0x0F {
+				return errors.Annotate(errors.ErrInvalidParameters, "S-box %d has an output wider than 4 bits: %w", i+1)
+			}
+		}
+	}
+	return nil
+}
+
+func orDefault(t *Tables) *Tables {
+	if t == nil {
+		return defaultTables
+	}
+	return t
+}
+
+func (t *Tables) SBoxFunction(index int) ([]byte, error) {
+	if index < 0 || index >= len(t.SBoxes) {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	out := make([]byte, 64)
+	for x := range out {
+		row := ((x >> 4) & 2) | (x & 1)
+		col := (x >> 1) & 0x0F
+		out[x] = t.SBoxes[index][row*16+col]
+	}
+	return out, nil
+}
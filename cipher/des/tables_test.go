@@ -0,0 +1,98 @@
+package des
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+func TestDefaultTablesMatchNewDES(t *testing.T) {
+	ctx := context.Background()
+	key := []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}
+	plaintext := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}
+
+	custom, err := NewDESWithTables(DefaultTables())
+	require.NoError(t, err)
+	require.NoError(t, custom.SetKey(ctx, key))
+
+	standard := NewDES()
+	require.NoError(t, standard.SetKey(ctx, key))
+
+	want, err := standard.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	got, err := custom.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestRandomSBoxes(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(1))
+
+	tables := DefaultTables()
+	for i := range tables.SBoxes {
+		for row := 0; row < 4; row++ {
+			perm := rng.Perm(16)
+			for col, v := range perm {
+				tables.SBoxes[i][row*16+col] = byte(v)
+			}
+		}
+	}
+
+	custom, err := NewDESWithTables(tables)
+	require.NoError(t, err)
+
+	key := []byte("8bytekey")
+	plaintext := []byte("testdata")
+	require.NoError(t, custom.SetKey(ctx, key))
+
+	encrypted, err := custom.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+
+	standard := NewDES()
+	require.NoError(t, standard.SetKey(ctx, key))
+	reference, err := standard.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, reference, encrypted)
+
+	decrypted, err := custom.Decrypt(ctx, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestInvalidTables(t *testing.T) {
+	_, err := NewDESWithTables(nil)
+	require.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	tables := DefaultTables()
+	tables.Expansion = tables.Expansion[:47]
+	_, err = NewDESWithTables(tables)
+	require.ErrorIs(t, err, errors.ErrInvalidPTableSize)
+
+	tables = DefaultTables()
+	tables.PC2 = append([]int{57}, tables.PC2[1:]...)
+	_, err = NewDESWithTables(tables)
+	require.ErrorIs(t, err, errors.ErrInvalidBitIndex)
+
+	tables = DefaultTables()
+	tables.SBoxes[3][7] = 0x10
+	_, err = NewDESWithTables(tables)
+	require.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
+
+func TestSBoxFunction(t *testing.T) {
+	tables := DefaultTables()
+
+	sbox, err := tables.SBoxFunction(0)
+	require.NoError(t, err)
+	assert.Len(t, sbox, 64)
+	assert.Equal(t, tables.SBoxes[0][0], sbox[0])
+
+	_, err = tables.SBoxFunction(8)
+	require.Error(t, err)
+}
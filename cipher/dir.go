@@ -0,0 +1,339 @@
+package cipher
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	DirManifestVersion = 1
+	DirManifestName    = "manifest.enc"
+
+	maxDirManifestSize = 64 * 1024 * 1024
+)
+
+type DirOptions struct {
+	// Workers bounds the number of files processed concurrently; zero means
+	// one per CPU.
+	Workers int
+	// Atomic is passed on to every file written, see FileOptions.
+	Atomic bool
+}
+
+// DirEntry describes one regular file of an encrypted directory. Path is
+// slash-separated and relative to the root; Object is the name of the
+// ciphertext file, which deliberately reveals nothing about the original.
+type DirEntry struct {
+	Path    string      `json:"path"`
+	Object  string      `json:"object,omitempty"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mod_time"`
+	Size    int64       `json:"size"`
+}
+
+type DirManifest struct {
+	Version int        `json:"version"`
+	Entries []DirEntry `json:"entries"`
+}
+
+// EncryptDir encrypts every regular file below inputDir into its own object
+// in outputDir and records the original paths and metadata in an encrypted
+// manifest, which is written last so that its presence marks a complete
+// run.
+func (c *CipherContext) EncryptDir(ctx context.Context, inputDir, outputDir string, opts *DirOptions) error {
+	options := dirOptions(opts)
+
+	manifest, err := scanDir(inputDir)
+	if err != nil {
+		return err
+	}
+	for i := range manifest.Entries {
+		manifest.Entries[i].Object = fmt.Sprintf("%08d.enc", i)
+	}
+
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	fileOptions := &FileOptions{Atomic: options.Atomic}
+	err = parallelFor(ctx, options.Workers, len(manifest.Entries), 1, func(i int) error {
+		entry := manifest.Entries[i]
+		err := c.EncryptFileWithOptions(ctx,
+			filepath.Join(inputDir, filepath.FromSlash(entry.Path)),
+			filepath.Join(outputDir, entry.Object),
+			fileOptions)
+		return errors.Annotate(err, "%s: %w", entry.Path)
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	encrypted, err := c.Encrypt(ctx, encoded)
+	if err != nil {
+		return fmt.Errorf("encrypting manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, DirManifestName), encrypted, defaultFileMode); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	return nil
+}
+
+// DecryptDir restores a directory written by EncryptDir, including the
+// permission bits and modification times of the original files.
+func (c *CipherContext) DecryptDir(ctx context.Context, inputDir, outputDir string, opts *DirOptions) error {
+	options := dirOptions(opts)
+
+	encrypted, err := os.ReadFile(filepath.Join(inputDir, DirManifestName))
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+	decrypted, err := c.Decrypt(ctx, encrypted)
+	if err != nil {
+		return fmt.Errorf("decrypting manifest: %w", err)
+	}
+	manifest, err := decodeDirManifest(decrypted)
+	if err != nil {
+		return err
+	}
+	for _, entry := range manifest.Entries {
+		if entry.Object == "" || entry.Object != filepath.Base(entry.Object) || entry.Object == DirManifestName {
+			return errors.Annotate(errors.ErrUnsafePath, "%s: %w", entry.Object)
+		}
+	}
+
+	fileOptions := &FileOptions{Atomic: options.Atomic}
+	return parallelFor(ctx, options.Workers, len(manifest.Entries), 1, func(i int) error {
+		entry := manifest.Entries[i]
+		target, err := prepareTarget(outputDir, entry)
+		if err != nil {
+			return err
+		}
+		if err := c.DecryptFileWithOptions(ctx, filepath.Join(inputDir, entry.Object), target, fileOptions); err != nil {
+			return errors.Annotate(err, "%s: %w", entry.Path)
+		}
+		return restoreMetadata(target, entry)
+	})
+}
+
+// EncryptDirToStream packs every regular file below inputDir into a single
+// encrypted stream: a length-prefixed manifest followed by the contents of
+// the files in manifest order.
+func (c *CipherContext) EncryptDirToStream(ctx context.Context, inputDir string, w io.Writer) error {
+	manifest, err := scanDir(inputDir)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(packDir(ctx, inputDir, encoded, manifest, pw))
+	}()
+
+	err = c.EncryptStream(ctx, pr, w)
+	pr.CloseWithError(err)
+	return err
+}
+
+// DecryptDirFromStream unpacks a stream written by EncryptDirToStream into
+// outputDir.
+func (c *CipherContext) DecryptDirFromStream(ctx context.Context, r io.Reader, outputDir string) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(c.DecryptStream(ctx, r, pw))
+	}()
+
+	err := unpackDir(pr, outputDir)
+	pr.CloseWithError(err)
+	return err
+}
+
+func dirOptions(opts *DirOptions) DirOptions {
+	var options DirOptions
+	if opts != nil {
+		options = *opts
+	}
+	return options
+}
+
+// scanDir lists the regular files below root in lexical order. Anything
+// else that is not a directory, such as a symlink, is rejected rather than
+// silently dropped.
+func scanDir(root string) (*DirManifest, error) {
+	manifest := &DirManifest{Version: DirManifestVersion}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return errors.Annotate(errors.ErrInvalidParameters, "%s is not a regular file: %w", rel)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		manifest.Entries = append(manifest.Entries, DirEntry{
+			Path:    filepath.ToSlash(rel),
+			Mode:    info.Mode().Perm(),
+			ModTime: info.ModTime().UTC(),
+			Size:    info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning directory: %w", err)
+	}
+	return manifest, nil
+}
+
+func decodeDirManifest(data []byte) (*DirManifest, error) {
+	var manifest DirManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "decoding manifest: %w")
+	}
+	if manifest.Version != DirManifestVersion {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "unsupported manifest version %d: %w", manifest.Version)
+	}
+	for _, entry := range manifest.Entries {
+		if !fs.ValidPath(entry.Path) || entry.Path == "." || entry.Size < 0 {
+			return nil, errors.Annotate(errors.ErrUnsafePath, "%s: %w", entry.Path)
+		}
+	}
+	return &manifest, nil
+}
+
+func packDir(ctx context.Context, root string, encoded []byte, manifest *DirManifest, w io.Writer) error {
+	if _, err := w.Write(binary.BigEndian.AppendUint32(nil, uint32(len(encoded)))); err != nil {
+		return err
+	}
+	if _, err := w.Write(encoded); err != nil {
+		return err
+	}
+
+	for _, entry := range manifest.Entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := packFile(filepath.Join(root, filepath.FromSlash(entry.Path)), entry, w); err != nil {
+			return errors.Annotate(err, "%s: %w", entry.Path)
+		}
+	}
+	return nil
+}
+
+func packFile(path string, entry DirEntry, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+
+	// The manifest already fixed the size, so a file that changed since the
+	// scan would corrupt the stream.
+	if n, err := io.CopyN(w, f, entry.Size); err != nil {
+		if err == io.EOF {
+			return errors.Annotate(errors.ErrInvalidDataLength, "file shrank to %d bytes: %w", n)
+		}
+		return err
+	}
+	return nil
+}
+
+func unpackDir(r io.Reader, outputDir string) error {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return errors.Annotate(truncated(err), "reading manifest: %w")
+	}
+	if size > maxDirManifestSize {
+		return errors.ErrInvalidFormat
+	}
+	encoded := make([]byte, size)
+	if _, err := io.ReadFull(r, encoded); err != nil {
+		return errors.Annotate(truncated(err), "reading manifest: %w")
+	}
+	manifest, err := decodeDirManifest(encoded)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range manifest.Entries {
+		if err := unpackFile(r, outputDir, entry); err != nil {
+			return errors.Annotate(err, "%s: %w", entry.Path)
+		}
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	return nil
+}
+
+func unpackFile(r io.Reader, outputDir string, entry DirEntry) error {
+	target, err := prepareTarget(outputDir, entry)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.CopyN(out, r, entry.Size); err != nil {
+		return truncated(err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("closing file: %w", err)
+	}
+	return restoreMetadata(target, entry)
+}
+
+// truncated reports a stream that ended early as malformed while keeping
+// decryption errors, which reach the reader through the pipe, intact.
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errors.ErrInvalidFormat
+	}
+	return err
+}
+
+func prepareTarget(outputDir string, entry DirEntry) (string, error) {
+	target := filepath.Join(outputDir, filepath.FromSlash(entry.Path))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("creating directory: %w", err)
+	}
+	return target, nil
+}
+
+func restoreMetadata(path string, entry DirEntry) error {
+	if err := os.Chmod(path, entry.Mode.Perm()); err != nil {
+		return fmt.Errorf("setting permissions: %w", err)
+	}
+	if err := os.Chtimes(path, entry.ModTime, entry.ModTime); err != nil {
+		return fmt.Errorf("setting modification time: %w", err)
+	}
+	return nil
+}
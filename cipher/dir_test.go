@@ -0,0 +1,120 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/aes"
+	"github.com/masterkusok/crypto/errors"
+)
+
+var dirFixture = map[string]string{
+	"a.txt":             "alpha",
+	"empty":             "",
+	"nested/b.bin":      string(bytes.Repeat([]byte{0xAB}, 10000)),
+	"nested/deep/c.txt": "gamma",
+}
+
+func newDirFixture(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	for name, content := range dirFixture {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o640))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	return root
+}
+
+func assertDirFixture(t *testing.T, root string) {
+	t.Helper()
+
+	for name, content := range dirFixture {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, name)
+		assert.Equal(t, content, string(data), name)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o640), info.Mode().Perm(), name)
+		assert.True(t, time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC).Equal(info.ModTime()), name)
+	}
+}
+
+func TestEncryptDir(t *testing.T) {
+	ctx := context.Background()
+	root := newDirFixture(t)
+	encrypted := filepath.Join(t.TempDir(), "encrypted")
+	restored := filepath.Join(t.TempDir(), "restored")
+
+	c := newAESContext(t, &cipher.CBCMode{})
+	opts := &cipher.DirOptions{Workers: 2, Atomic: true}
+	require.NoError(t, c.EncryptDir(ctx, root, encrypted, opts))
+
+	entries, err := os.ReadDir(encrypted)
+	require.NoError(t, err)
+	assert.Len(t, entries, len(dirFixture)+1)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), "txt", "object names must not leak paths")
+	}
+
+	require.NoError(t, c.DecryptDir(ctx, encrypted, restored, opts))
+	assertDirFixture(t, restored)
+}
+
+func TestDecryptDirWrongKey(t *testing.T) {
+	ctx := context.Background()
+	encrypted := filepath.Join(t.TempDir(), "encrypted")
+	require.NoError(t, newAESContext(t, &cipher.CBCMode{}).EncryptDir(ctx, newDirFixture(t), encrypted, nil))
+
+	other, err := cipher.NewCipherContext(aes.NewAES128(), []byte("fedcba9876543210"), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(bytes.Repeat([]byte{9}, 16)))
+	require.NoError(t, err)
+	assert.Error(t, other.DecryptDir(ctx, encrypted, t.TempDir(), nil))
+}
+
+func TestEncryptDirToStream(t *testing.T) {
+	ctx := context.Background()
+	root := newDirFixture(t)
+	restored := t.TempDir()
+
+	c := newAESContext(t, &cipher.CBCMode{})
+	var stream bytes.Buffer
+	require.NoError(t, c.EncryptDirToStream(ctx, root, &stream))
+	assert.NotContains(t, stream.String(), "nested")
+
+	require.NoError(t, c.DecryptDirFromStream(ctx, &stream, restored))
+	assertDirFixture(t, restored)
+}
+
+func TestDecryptDirFromStreamTruncated(t *testing.T) {
+	ctx := context.Background()
+	c := newAESContext(t, &cipher.CBCMode{})
+
+	var stream bytes.Buffer
+	require.NoError(t, c.EncryptDirToStream(ctx, newDirFixture(t), &stream))
+	truncated := stream.Bytes()[:stream.Len()/2/16*16]
+
+	err := c.DecryptDirFromStream(ctx, bytes.NewReader(truncated), t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestEncryptDirRejectsSymlinks(t *testing.T) {
+	root := newDirFixture(t)
+	require.NoError(t, os.Symlink(filepath.Join(root, "a.txt"), filepath.Join(root, "link")))
+
+	c := newAESContext(t, &cipher.CBCMode{})
+	err := c.EncryptDir(context.Background(), root, t.TempDir(), nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
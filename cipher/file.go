@@ -0,0 +1,138 @@
+package cipher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type FileOptions struct {
+	// Atomic writes the output to a temporary file in the destination
+	// directory and renames it into place once it is complete, so readers
+	// never observe a partial file. It is required to overwrite the input.
+	Atomic bool
+	// PreserveMode copies the permission bits and modification time of the
+	// input onto the output.
+	PreserveMode bool
+}
+
+const defaultFileMode = 0o644
+
+func (c *CipherContext) EncryptFile(ctx context.Context, inputPath, outputPath string) error {
+	return c.EncryptFileWithOptions(ctx, inputPath, outputPath, nil)
+}
+
+func (c *CipherContext) EncryptFileWithOptions(ctx context.Context, inputPath, outputPath string, opts *FileOptions) error {
+	return processFile(ctx, inputPath, outputPath, opts, c.EncryptStream)
+}
+
+func (c *CipherContext) DecryptFile(ctx context.Context, inputPath, outputPath string) error {
+	return c.DecryptFileWithOptions(ctx, inputPath, outputPath, nil)
+}
+
+func (c *CipherContext) DecryptFileWithOptions(ctx context.Context, inputPath, outputPath string, opts *FileOptions) error {
+	return processFile(ctx, inputPath, outputPath, opts, c.DecryptStream)
+}
+
+func processFile(ctx context.Context, inputPath, outputPath string, opts *FileOptions, transform func(context.Context, io.Reader, io.Writer) error) error {
+	var options FileOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- writeFile(ctx, inputPath, outputPath, options, transform)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errChan:
+		return err
+	}
+}
+
+func writeFile(ctx context.Context, inputPath, outputPath string, options FileOptions, transform func(context.Context, io.Reader, io.Writer) error) error {
+	inFile, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer inFile.Close()
+
+	inInfo, err := inFile.Stat()
+	if err != nil {
+		return fmt.Errorf("inspecting file: %w", err)
+	}
+	if outInfo, err := os.Stat(outputPath); err == nil && os.SameFile(inInfo, outInfo) && !options.Atomic {
+		return errors.Annotate(errors.ErrInvalidParameters, "output is the input file, which requires atomic writes: %w")
+	}
+
+	mode := os.FileMode(defaultFileMode)
+	if options.PreserveMode {
+		mode = inInfo.Mode().Perm()
+	}
+
+	if !options.Atomic {
+		outFile, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer outFile.Close()
+
+		if err := transform(ctx, inFile, outFile); err != nil {
+			return err
+		}
+		return finishFile(outFile, outputPath, inInfo, mode, options)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(outputPath), "."+filepath.Base(outputPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := transform(ctx, inFile, tmp); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("syncing temporary file: %w", err)
+	}
+	if err := finishFile(tmp, tmp.Name(), inInfo, mode, options); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), outputPath); err != nil {
+		return fmt.Errorf("replacing output file: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// finishFile closes the output and applies the requested mode and times.
+// Atomic writes always set the mode because temporary files start as 0600.
+func finishFile(f *os.File, path string, inInfo os.FileInfo, mode os.FileMode, options FileOptions) error {
+	if options.Atomic || options.PreserveMode {
+		if err := f.Chmod(mode); err != nil {
+			return fmt.Errorf("setting permissions: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing output file: %w", err)
+	}
+	if options.PreserveMode {
+		if err := os.Chtimes(path, inInfo.ModTime(), inInfo.ModTime()); err != nil {
+			return fmt.Errorf("setting modification time: %w", err)
+		}
+	}
+	return nil
+}
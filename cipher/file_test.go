@@ -0,0 +1,97 @@
+package cipher_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+func TestEncryptFilePreservesMode(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input")
+	encrypted := filepath.Join(dir, "encrypted")
+	decrypted := filepath.Join(dir, "decrypted")
+
+	require.NoError(t, os.WriteFile(input, []byte("keep my metadata"), 0o600))
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(input, mtime, mtime))
+
+	c := newAESContext(t, &cipher.CBCMode{})
+	opts := &cipher.FileOptions{Atomic: true, PreserveMode: true}
+	require.NoError(t, c.EncryptFileWithOptions(ctx, input, encrypted, opts))
+	require.NoError(t, c.DecryptFileWithOptions(ctx, encrypted, decrypted, opts))
+
+	for _, path := range []string{encrypted, decrypted} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), path)
+		assert.True(t, mtime.Equal(info.ModTime()), path)
+	}
+
+	data, err := os.ReadFile(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("keep my metadata"), data)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "temporary files must not be left behind")
+}
+
+func TestEncryptFileInPlace(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "file")
+	plaintext := []byte("overwritten safely through a temporary file")
+	require.NoError(t, os.WriteFile(path, plaintext, 0o640))
+
+	c := newAESContext(t, &cipher.CTRMode{})
+	err := c.EncryptFile(ctx, path, path)
+	require.ErrorIs(t, err, errors.ErrInvalidParameters)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, data, "a rejected in-place write must leave the input intact")
+
+	opts := &cipher.FileOptions{Atomic: true, PreserveMode: true}
+	require.NoError(t, c.EncryptFileWithOptions(ctx, path, path, opts))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotEqual(t, plaintext, data)
+
+	require.NoError(t, c.DecryptFileWithOptions(ctx, path, path, opts))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, data)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+}
+
+func TestAtomicEncryptFileCleansUpOnFailure(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input")
+	output := filepath.Join(dir, "output")
+	require.NoError(t, os.WriteFile(input, []byte("not block aligned"), 0o644))
+	require.NoError(t, os.WriteFile(output, []byte("previous contents"), 0o644))
+
+	// Decrypting unaligned data fails midway; the old output must survive.
+	c := newAESContext(t, &cipher.CBCMode{})
+	require.Error(t, c.DecryptFileWithOptions(ctx, input, output, &cipher.FileOptions{Atomic: true}))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("previous contents"), data)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,149 @@
+package hctr
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+const (
+	blockSize   = 16
+	HashKeySize = 16
+)
+
+type HCTR struct {
+	block cipher.BlockCipher
+	h     [2]uint64
+	keyed bool
+}
+
+func NewHCTR(block cipher.BlockCipher) (*HCTR, error) {
+	if block.BlockSize() != blockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	return &HCTR{block: block}, nil
+}
+
+func (w *HCTR) SetKey(ctx context.Context, key []byte) error {
+	if len(key) <= HashKeySize {
+		return errors.ErrInvalidKeySize
+	}
+
+	split := len(key) - HashKeySize
+	if err := w.block.SetKey(ctx, key[:split]); err != nil {
+		return errors.Annotate(err, "failed to set block key: %w")
+	}
+	w.h = toElement(key[split:])
+	w.keyed = true
+	return nil
+}
+
+func (w *HCTR) Encrypt(ctx context.Context, plaintext, tweak []byte) ([]byte, error) {
+	if err := w.check(plaintext); err != nil {
+		return nil, err
+	}
+
+	mm := xorBlock(plaintext[:blockSize], w.hash(plaintext[blockSize:], tweak))
+	cc, err := w.block.Encrypt(ctx, mm)
+	if err != nil {
+		return nil, err
+	}
+
+	tail, err := w.ctr(ctx, xorBlock(mm, cc), plaintext[blockSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	return append(xorBlock(cc, w.hash(tail, tweak)), tail...), nil
+}
+
+func (w *HCTR) Decrypt(ctx context.Context, ciphertext, tweak []byte) ([]byte, error) {
+	if err := w.check(ciphertext); err != nil {
+		return nil, err
+	}
+
+	cc := xorBlock(ciphertext[:blockSize], w.hash(ciphertext[blockSize:], tweak))
+	mm, err := w.block.Decrypt(ctx, cc)
+	if err != nil {
+		return nil, err
+	}
+
+	tail, err := w.ctr(ctx, xorBlock(mm, cc), ciphertext[blockSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	return append(xorBlock(mm, w.hash(tail, tweak)), tail...), nil
+}
+
+func (w *HCTR) check(data []byte) error {
+	if !w.keyed {
+		return errors.ErrInvalidKeySize
+	}
+	if len(data) < blockSize {
+		return errors.Annotate(errors.ErrInvalidDataLength, "record must be at least %d bytes: %w", blockSize)
+	}
+	return nil
+}
+
+func (w *HCTR) hash(data, tweak []byte) []byte {
+	var y [2]uint64
+	absorb := func(b []byte) {
+		for i := 0; i < len(b); i += blockSize {
+			block := make([]byte, blockSize)
+			copy(block, b[i:])
+			x := toElement(block)
+			y[0] ^= x[0]
+			y[1] ^= x[1]
+			y = cryptoMath.GF128Mul(y, w.h)
+		}
+	}
+
+	absorb(data)
+	absorb(tweak)
+
+	lengths := make([]byte, blockSize)
+	binary.BigEndian.PutUint64(lengths, uint64(len(data))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(tweak))*8)
+	absorb(lengths)
+
+	out := make([]byte, blockSize)
+	binary.BigEndian.PutUint64(out, y[0])
+	binary.BigEndian.PutUint64(out[8:], y[1])
+	return out
+}
+
+func (w *HCTR) ctr(ctx context.Context, seed, data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	counter := make([]byte, blockSize)
+
+	for i := 0; i < len(data); i += blockSize {
+		copy(counter, seed)
+		index := binary.BigEndian.Uint64(counter[8:]) ^ uint64(i/blockSize+1)
+		binary.BigEndian.PutUint64(counter[8:], index)
+
+		keystream, err := w.block.Encrypt(ctx, counter)
+		if err != nil {
+			return nil, err
+		}
+		for j := i; j < len(data) && j < i+blockSize; j++ {
+			out[j] = data[j] ^ keystream[j-i]
+		}
+	}
+	return out, nil
+}
+
+func xorBlock(a, b []byte) []byte {
+	out := make([]byte, blockSize)
+	for i := range out {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
+
+func toElement(b []byte) [2]uint64 {
+	return [2]uint64{binary.BigEndian.Uint64(b), binary.BigEndian.Uint64(b[8:])}
+}
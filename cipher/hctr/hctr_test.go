@@ -0,0 +1,106 @@
+package hctr
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+)
+
+func newHCTR(t *testing.T) *HCTR {
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	w, err := NewHCTR(block)
+	require.NoError(t, err)
+	require.NoError(t, w.SetKey(context.Background(), []byte("0123456789abcdefhash-key-16bytes")))
+	return w
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	w := newHCTR(t)
+	tweak := []byte("row 42")
+
+	for _, size := range []int{16, 17, 31, 32, 100, 512} {
+		plaintext := make([]byte, size)
+		for i := range plaintext {
+			plaintext[i] = byte(i * 7)
+		}
+
+		ciphertext, err := w.Encrypt(ctx, plaintext, tweak)
+		require.NoError(t, err)
+		assert.Len(t, ciphertext, size)
+		assert.NotEqual(t, plaintext, ciphertext)
+
+		decrypted, err := w.Decrypt(ctx, ciphertext, tweak)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted, "size %d", size)
+	}
+}
+
+func TestWideBlockDiffusion(t *testing.T) {
+	ctx := context.Background()
+	w := newHCTR(t)
+	plaintext := bytes.Repeat([]byte{0xAA}, 64)
+
+	base, err := w.Encrypt(ctx, plaintext, nil)
+	require.NoError(t, err)
+
+	flipped := append([]byte{}, plaintext...)
+	flipped[len(flipped)-1] ^= 1
+	changed, err := w.Encrypt(ctx, flipped, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < len(base); i += 16 {
+		assert.NotEqual(t, base[i:i+16], changed[i:i+16], "block %d unchanged", i/16)
+	}
+
+	corrupted := append([]byte{}, base...)
+	corrupted[len(corrupted)-1] ^= 1
+	decrypted, err := w.Decrypt(ctx, corrupted, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, plaintext[:16], decrypted[:16])
+}
+
+func TestTweak(t *testing.T) {
+	ctx := context.Background()
+	w := newHCTR(t)
+	plaintext := []byte("the same record, stored twice")
+
+	first, err := w.Encrypt(ctx, plaintext, []byte("row 1"))
+	require.NoError(t, err)
+	second, err := w.Encrypt(ctx, plaintext, []byte("row 2"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	decrypted, err := w.Decrypt(ctx, first, []byte("row 2"))
+	require.NoError(t, err)
+	assert.NotEqual(t, plaintext, decrypted)
+}
+
+func TestInvalid(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewHCTR(des.NewDES())
+	require.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	w, err := NewHCTR(block)
+	require.NoError(t, err)
+
+	_, err = w.Encrypt(ctx, make([]byte, 16), nil)
+	require.ErrorIs(t, err, errors.ErrInvalidKeySize)
+
+	require.ErrorIs(t, w.SetKey(ctx, make([]byte, 16)), errors.ErrInvalidKeySize)
+	require.NoError(t, w.SetKey(ctx, make([]byte, 32)))
+
+	_, err = w.Encrypt(ctx, make([]byte, 15), nil)
+	require.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
@@ -0,0 +1,121 @@
+package cipher
+
+import (
+	"context"
+	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 64)
+		return &b
+	},
+}
+
+func getBuffer(size int) *[]byte {
+	buf := bufferPool.Get().(*[]byte)
+	if cap(*buf) < size {
+		*buf = make([]byte, size)
+	}
+	*buf = (*buf)[:size]
+	return buf
+}
+
+func putBuffer(buf *[]byte) {
+	bufferPool.Put(buf)
+}
+
+// EncryptBlockTo encrypts src into dst, using the allocation-free path when
+// the cipher implements InPlaceBlockCipher and copying otherwise.
+func EncryptBlockTo(ctx context.Context, cipher BlockCipher, dst, src []byte) error {
+	if inPlace, ok := cipher.(InPlaceBlockCipher); ok {
+		return inPlace.EncryptBlockInPlace(ctx, dst, src)
+	}
+	if len(dst) < cipher.BlockSize() {
+		return errors.ErrInvalidBlockSize
+	}
+
+	out, err := cipher.Encrypt(ctx, src)
+	if err != nil {
+		return err
+	}
+	copy(dst, out)
+	return nil
+}
+
+func DecryptBlockTo(ctx context.Context, cipher BlockCipher, dst, src []byte) error {
+	if inPlace, ok := cipher.(InPlaceBlockCipher); ok {
+		return inPlace.DecryptBlockInPlace(ctx, dst, src)
+	}
+	if len(dst) < cipher.BlockSize() {
+		return errors.ErrInvalidBlockSize
+	}
+
+	out, err := cipher.Decrypt(ctx, src)
+	if err != nil {
+		return err
+	}
+	copy(dst, out)
+	return nil
+}
+
+// XORKeyStream applies the CTR or OFB keystream derived from the context IV
+// to src and writes the result into dst, which must be at least as long.
+// The output matches Encrypt in the same mode, but no result slice is
+// allocated.
+func (c *CipherContext) XORKeyStream(ctx context.Context, dst, src []byte) error {
+	return c.XORKeyStreamWithIV(ctx, dst, src, c.iv)
+}
+
+func (c *CipherContext) XORKeyStreamWithIV(ctx context.Context, dst, src, iv []byte) error {
+	if len(dst) < len(src) {
+		return errors.ErrInvalidDataLength
+	}
+	blockSize := c.cipher.BlockSize()
+	if len(iv) != blockSize {
+		return errors.ErrInvalidIVSize
+	}
+	iv = c.startIV(iv)
+
+	var counter bool
+	switch c.mode.(type) {
+	case *CTRMode:
+		counter = true
+	case *OFBMode:
+	default:
+		return errors.Annotate(errors.ErrInvalidMode, "keystream requires CTR or OFB: %w")
+	}
+
+	register, keystream := getBuffer(blockSize), getBuffer(blockSize)
+	defer putBuffer(register)
+	defer putBuffer(keystream)
+	copy(*register, iv)
+	copy(*keystream, iv)
+
+	for start := 0; start < len(src); start += blockSize {
+		if err := checkCancelled(ctx, start/blockSize, blockSize); err != nil {
+			return err
+		}
+
+		// CTR encrypts the counter register, OFB re-encrypts the previous
+		// keystream block.
+		input := *keystream
+		if counter {
+			input = *register
+		}
+		if err := EncryptBlockTo(ctx, c.cipher, *keystream, input); err != nil {
+			return &errors.BlockError{Index: start / blockSize, Err: err}
+		}
+		if counter {
+			addCounter(*register, 1)
+		}
+
+		end := min(start+blockSize, len(src))
+		for i := start; i < end; i++ {
+			dst[i] = src[i] ^ (*keystream)[i-start]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/aes"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+)
+
+var _ cipher.InPlaceBlockCipher = (*aes.AES)(nil)
+
+func TestBlockToMatchesEncrypt(t *testing.T) {
+	ctx := context.Background()
+	ciphers := map[string]cipher.BlockCipher{"aes": aes.NewAES128(), "des": des.NewDES()}
+	keys := map[string][]byte{"aes": []byte("0123456789abcdef"), "des": []byte("8bytekey")}
+
+	for name, c := range ciphers {
+		require.NoError(t, c.SetKey(ctx, keys[name]))
+		block := bytes.Repeat([]byte{0x5A}, c.BlockSize())
+
+		want, err := c.Encrypt(ctx, block)
+		require.NoError(t, err)
+
+		got := append([]byte(nil), block...)
+		require.NoError(t, cipher.EncryptBlockTo(ctx, c, got, got), name)
+		assert.Equal(t, want, got, name)
+
+		require.NoError(t, cipher.DecryptBlockTo(ctx, c, got, got), name)
+		assert.Equal(t, block, got, name)
+
+		require.ErrorIs(t, cipher.EncryptBlockTo(ctx, c, make([]byte, 1), block), errors.ErrInvalidBlockSize)
+	}
+}
+
+func TestEncryptBlockInPlaceDoesNotAllocate(t *testing.T) {
+	ctx := context.Background()
+	c := aes.NewAES256()
+	require.NoError(t, c.SetKey(ctx, make([]byte, 32)))
+	block := make([]byte, 16)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = c.EncryptBlockInPlace(ctx, block, block)
+		_ = c.DecryptBlockInPlace(ctx, block, block)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestXORKeyStreamMatchesModes(t *testing.T) {
+	ctx := context.Background()
+	iv := bytes.Repeat([]byte{3}, 16)
+	plaintext := []byte("length preserving keystream over an unaligned message")
+
+	for _, mode := range []cipher.CipherMode{&cipher.CTRMode{}, &cipher.OFBMode{}} {
+		c, err := cipher.NewCipherContext(aes.NewAES128(), []byte("0123456789abcdef"), mode, cipher.Zeros, cipher.WithIV(iv))
+		require.NoError(t, err)
+
+		padded, err := collect(c.EncryptBytes(ctx, plaintext))
+		require.NoError(t, err)
+
+		dst := make([]byte, len(plaintext))
+		require.NoError(t, c.XORKeyStream(ctx, dst, plaintext))
+		assert.Equal(t, padded[:len(plaintext)], dst)
+
+		require.NoError(t, c.XORKeyStream(ctx, dst, dst))
+		assert.Equal(t, plaintext, dst)
+	}
+}
+
+func TestXORKeyStreamInvalid(t *testing.T) {
+	ctx := context.Background()
+	iv := make([]byte, 16)
+
+	cbc, err := cipher.NewCipherContext(aes.NewAES128(), make([]byte, 16), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(iv))
+	require.NoError(t, err)
+	require.ErrorIs(t, cbc.XORKeyStream(ctx, make([]byte, 4), make([]byte, 4)), errors.ErrInvalidMode)
+
+	ctr, err := cipher.NewCipherContext(aes.NewAES128(), make([]byte, 16), &cipher.CTRMode{}, cipher.PKCS7, cipher.WithIV(iv))
+	require.NoError(t, err)
+	require.ErrorIs(t, ctr.XORKeyStream(ctx, make([]byte, 2), make([]byte, 4)), errors.ErrInvalidDataLength)
+	require.ErrorIs(t, ctr.XORKeyStreamWithIV(ctx, make([]byte, 4), make([]byte, 4), make([]byte, 8)), errors.ErrInvalidIVSize)
+}
+
+func BenchmarkXORKeyStream(b *testing.B) {
+	ctx := context.Background()
+	c, err := cipher.NewCipherContext(aes.NewAES128(), make([]byte, 16), &cipher.CTRMode{}, cipher.PKCS7, cipher.WithIV(make([]byte, 16)))
+	require.NoError(b, err)
+	data := make([]byte, 64*1024)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = c.XORKeyStream(ctx, data, data)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/masterkusok/crypto/cipher/deal"
 	"github.com/masterkusok/crypto/cipher/des"
 	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,7 +33,7 @@ func TestCipherContextModes(t *testing.T) {
 
 	for i, mode := range modes {
 		t.Run(modeNames[i], func(t *testing.T) {
-			cipherCtx, err := cipher.NewCipherContext(des.NewDES(), key, mode, cipher.PKCS7, iv)
+			cipherCtx, err := cipher.NewCipherContext(des.NewDES(), key, mode, cipher.PKCS7, cipher.WithIV(iv))
 			require.NoError(t, err)
 
 			resultChan, errChan := cipherCtx.EncryptBytes(ctx, plaintext)
@@ -56,6 +57,43 @@ func TestCipherContextModes(t *testing.T) {
 	}
 }
 
+func TestPerCallIV(t *testing.T) {
+	ctx := context.Background()
+	key := []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}
+	plaintext := []byte("Test message for encryption!")
+
+	cipherCtx, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.CBCMode{}, cipher.PKCS7)
+	require.NoError(t, err)
+
+	ivs := [][]byte{
+		{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+		{0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01, 0x00},
+	}
+	var ciphertexts [][]byte
+	for _, iv := range ivs {
+		resultChan, errChan := cipherCtx.EncryptBytesWithIV(ctx, plaintext, iv)
+		var encrypted []byte
+		select {
+		case encrypted = <-resultChan:
+		case err := <-errChan:
+			require.NoError(t, err)
+		}
+		ciphertexts = append(ciphertexts, encrypted)
+
+		resultChan, errChan = cipherCtx.DecryptBytesWithIV(ctx, encrypted, iv)
+		select {
+		case decrypted := <-resultChan:
+			assert.Equal(t, plaintext, decrypted)
+		case err := <-errChan:
+			require.NoError(t, err)
+		}
+	}
+	assert.NotEqual(t, ciphertexts[0], ciphertexts[1])
+
+	_, errChan := cipherCtx.EncryptBytesWithIV(ctx, plaintext, []byte{0x00})
+	assert.ErrorIs(t, <-errChan, errors.ErrInvalidIVSize)
+}
+
 func TestPaddingSchemes(t *testing.T) {
 	blockSize := 8
 	data := []byte("Hello")
@@ -116,7 +154,7 @@ func TestFileEncryption(t *testing.T) {
 			originalData := []byte("Test file encryption with multiple algorithms!")
 			require.NoError(t, os.WriteFile(inputFile, originalData, 0644))
 
-			cipherCtx, err := cipher.NewCipherContext(tt.cipher, tt.key, &cipher.CBCMode{}, cipher.PKCS7, iv[:tt.cipher.BlockSize()])
+			cipherCtx, err := cipher.NewCipherContext(tt.cipher, tt.key, &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(iv[:tt.cipher.BlockSize()]))
 			require.NoError(t, err)
 
 			err = cipherCtx.EncryptFile(ctx, inputFile, encryptedFile)
@@ -16,3 +16,20 @@ type BlockCipher interface {
 	Decrypt(ctx context.Context, block []byte) ([]byte, error)
 	BlockSize() int
 }
+
+type TweakableBlockCipher interface {
+	SetKey(ctx context.Context, key []byte) error
+	Encrypt(ctx context.Context, block, tweak []byte) ([]byte, error)
+	Decrypt(ctx context.Context, block, tweak []byte) ([]byte, error)
+	BlockSize() int
+	TweakSize() int
+}
+
+// InPlaceBlockCipher is implemented by ciphers that can write a block into a
+// caller-provided buffer without allocating. dst and src may be the same
+// slice.
+type InPlaceBlockCipher interface {
+	BlockCipher
+	EncryptBlockInPlace(ctx context.Context, dst, src []byte) error
+	DecryptBlockInPlace(ctx context.Context, dst, src []byte) error
+}
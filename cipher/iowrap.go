@@ -0,0 +1,235 @@
+package cipher
+
+import (
+	"context"
+	"io"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const errClosed = errors.ConstError("write to closed EncryptingWriter")
+
+// EncryptingWriter encrypts everything written to it with the context's
+// mode and writes the ciphertext to the underlying writer as soon as whole
+// blocks are available. Only the trailing partial block is buffered, and it
+// is padded and flushed by Close. The output is identical to EncryptStream.
+type EncryptingWriter struct {
+	ctx     context.Context
+	c       *CipherContext
+	mode    chainingMode
+	w       io.Writer
+	iv      []byte
+	prefix  []byte
+	pending []byte
+	err     error
+}
+
+func (c *CipherContext) NewEncryptingWriter(ctx context.Context, w io.Writer) (*EncryptingWriter, error) {
+	mode, ok := c.mode.(chainingMode)
+	if !ok {
+		return nil, errors.Annotate(errors.ErrInvalidMode, "mode does not support streaming: %w")
+	}
+
+	iv, err := c.messageIV()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = c.withSettings(ctx)
+	header, state, err := c.sealStreamHeader(ctx, c.startIV(iv))
+	if err != nil {
+		return nil, err
+	}
+
+	e := &EncryptingWriter{
+		ctx:    ctx,
+		c:      c,
+		mode:   mode,
+		w:      w,
+		iv:     state,
+		prefix: header,
+	}
+	if c.options.randomIV {
+		e.prefix = append(append([]byte{}, iv...), header...)
+	}
+	return e, nil
+}
+
+func (e *EncryptingWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	e.pending = append(e.pending, p...)
+	blockSize := e.c.cipher.BlockSize()
+	full := len(e.pending) / blockSize * blockSize
+	if full > 0 {
+		if err := e.flush(e.pending[:full], false); err != nil {
+			return 0, err
+		}
+		e.pending = append(e.pending[:0], e.pending[full:]...)
+	}
+	return len(p), nil
+}
+
+// Close pads and writes the final block. It does not close the underlying
+// writer.
+func (e *EncryptingWriter) Close() error {
+	if e.err != nil {
+		if e.err == errClosed {
+			return nil
+		}
+		return e.err
+	}
+
+	data, err := e.c.pad(e.pending)
+	if err != nil {
+		e.err = err
+		return err
+	}
+	if len(data) > 0 || e.prefix != nil {
+		if err := e.flush(data, true); err != nil {
+			return err
+		}
+	}
+	e.err = errClosed
+	return nil
+}
+
+func (e *EncryptingWriter) flush(data []byte, final bool) error {
+	if err := e.ctx.Err(); err != nil {
+		e.err = err
+		return err
+	}
+
+	encrypted, err := e.c.encryptChunk(e.ctx, data, e.iv)
+	if err != nil {
+		e.err = err
+		return err
+	}
+	if _, err := e.w.Write(append(e.prefix, encrypted...)); err != nil {
+		e.err = errors.Annotate(err, "writing output: %w")
+		return e.err
+	}
+	e.prefix = nil
+
+	if !final {
+		e.iv = e.mode.nextIV(e.c.cipher.BlockSize(), e.iv, data, encrypted, true)
+	}
+	return nil
+}
+
+// DecryptingReader decrypts ciphertext read from the underlying reader.
+// It keeps the most recent block back until the end of the input is
+// reached, since only then can the padding be removed.
+type DecryptingReader struct {
+	ctx  context.Context
+	c    *CipherContext
+	mode chainingMode
+	r    io.Reader
+	iv   []byte
+	// ivPending is set under WithRandomIV until the message IV is read,
+	// and headerPending until a headerMode has read its header block.
+	ivPending     bool
+	headerPending bool
+	buf           []byte
+	held          int
+	out           []byte
+	err           error
+}
+
+func (c *CipherContext) NewDecryptingReader(ctx context.Context, r io.Reader) (*DecryptingReader, error) {
+	mode, ok := c.mode.(chainingMode)
+	if !ok {
+		return nil, errors.Annotate(errors.ErrInvalidMode, "mode does not support streaming: %w")
+	}
+
+	return &DecryptingReader{
+		ctx:           c.withSettings(ctx),
+		c:             c,
+		mode:          mode,
+		r:             r,
+		iv:            c.startIV(c.iv),
+		ivPending:     c.options.randomIV,
+		headerPending: true,
+		buf:           make([]byte, c.streamChunkSize()+c.cipher.BlockSize()),
+	}, nil
+}
+
+func (d *DecryptingReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.fill()
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// fill reads more ciphertext and decrypts every complete block except the
+// last one, or everything once the input is exhausted.
+func (d *DecryptingReader) fill() error {
+	if err := d.ctx.Err(); err != nil {
+		return err
+	}
+	if d.ivPending {
+		iv, err := d.c.readIV(d.r)
+		if err != nil {
+			return err
+		}
+		d.iv, d.ivPending = d.c.startIV(iv), false
+	}
+	if d.headerPending {
+		state, err := d.c.openStreamHeader(d.ctx, d.r, d.iv)
+		if err != nil {
+			return err
+		}
+		d.iv, d.headerPending = state, false
+	}
+
+	n, err := d.r.Read(d.buf[d.held:])
+	d.held += n
+	eof := err == io.EOF
+	if err != nil && !eof {
+		return errors.Annotate(err, "reading input: %w")
+	}
+
+	blockSize := d.c.cipher.BlockSize()
+	if eof {
+		if d.held%blockSize != 0 && !d.c.lengthPreserving() {
+			return errors.ErrInvalidDataLength
+		}
+		if err := d.decrypt(d.held, true); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	ready := (d.held - 1) / blockSize * blockSize
+	if ready <= 0 {
+		return nil
+	}
+	return d.decrypt(ready, false)
+}
+
+func (d *DecryptingReader) decrypt(size int, final bool) error {
+	data := d.buf[:size]
+	decrypted, err := d.c.decryptChunk(d.ctx, data, d.iv)
+	if err != nil {
+		return err
+	}
+	if final {
+		if decrypted, err = d.c.unpad(decrypted); err != nil {
+			return err
+		}
+	} else {
+		d.iv = d.mode.nextIV(d.c.cipher.BlockSize(), d.iv, data, decrypted, false)
+	}
+
+	d.out = decrypted
+	d.held = copy(d.buf, d.buf[size:d.held])
+	return nil
+}
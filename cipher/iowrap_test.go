@@ -0,0 +1,127 @@
+package cipher_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptingWriterMatchesStream(t *testing.T) {
+	ctx := context.Background()
+	key := []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}
+	iv := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	for name, mode := range streamModes() {
+		t.Run(name, func(t *testing.T) {
+			for _, size := range []int{0, 7, 8, 64 + 21, 5*64 + 8} {
+				plaintext := make([]byte, size)
+				for i := range plaintext {
+					plaintext[i] = byte(i * 11)
+				}
+
+				cc, err := cipher.NewCipherContext(des.NewDES(), key, mode, cipher.PKCS7, cipher.WithIV(iv), cipher.WithStreamChunkSize(64))
+				require.NoError(t, err)
+
+				var want bytes.Buffer
+				require.NoError(t, cc.EncryptStream(ctx, bytes.NewReader(plaintext), &want))
+
+				var got bytes.Buffer
+				w, err := cc.NewEncryptingWriter(ctx, &got)
+				require.NoError(t, err)
+				for rest := plaintext; len(rest) > 0; {
+					n := min(len(rest), 5)
+					_, err := w.Write(rest[:n])
+					require.NoError(t, err)
+					rest = rest[n:]
+				}
+				require.NoError(t, w.Close())
+				if randomizedMode(mode) {
+					var decrypted bytes.Buffer
+					require.NoError(t, cc.DecryptStream(ctx, bytes.NewReader(got.Bytes()), &decrypted))
+					assert.Equal(t, plaintext, append([]byte{}, decrypted.Bytes()...))
+				} else {
+					assert.Equal(t, want.Bytes(), got.Bytes())
+				}
+
+				r, err := cc.NewDecryptingReader(ctx, iotest.OneByteReader(bytes.NewReader(got.Bytes())))
+				require.NoError(t, err)
+				decrypted, err := io.ReadAll(r)
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, append([]byte{}, decrypted...))
+			}
+		})
+	}
+}
+
+func TestDecryptingReader(t *testing.T) {
+	ctx := context.Background()
+	plaintext := bytes.Repeat([]byte("incremental "), 1000)
+
+	cc, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(make([]byte, 8)), cipher.WithStreamChunkSize(256))
+	require.NoError(t, err)
+	ciphertext, err := cc.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+
+	r, err := cc.NewDecryptingReader(ctx, bytes.NewReader(ciphertext))
+	require.NoError(t, err)
+	require.NoError(t, iotest.TestReader(r, plaintext))
+}
+
+func TestEncryptingWriterThroughGzip(t *testing.T) {
+	ctx := context.Background()
+	plaintext := bytes.Repeat([]byte("compress me, then encrypt me "), 500)
+
+	cc, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.CTRMode{}, cipher.PKCS7, cipher.WithIV(make([]byte, 8)))
+	require.NoError(t, err)
+
+	var sealed bytes.Buffer
+	ew, err := cc.NewEncryptingWriter(ctx, &sealed)
+	require.NoError(t, err)
+	zw := gzip.NewWriter(ew)
+	_, err = zw.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, ew.Close())
+	assert.Less(t, sealed.Len(), len(plaintext))
+
+	dr, err := cc.NewDecryptingReader(ctx, &sealed)
+	require.NoError(t, err)
+	zr, err := gzip.NewReader(dr)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decompressed)
+}
+
+func TestDecryptingReaderRejectsPartialBlock(t *testing.T) {
+	ctx := context.Background()
+	cc, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(make([]byte, 8)))
+	require.NoError(t, err)
+
+	r, err := cc.NewDecryptingReader(ctx, bytes.NewReader(make([]byte, 13)))
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
+
+func TestEncryptingWriterClosed(t *testing.T) {
+	ctx := context.Background()
+	cc, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(make([]byte, 8)))
+	require.NoError(t, err)
+
+	w, err := cc.NewEncryptingWriter(ctx, io.Discard)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	_, err = w.Write([]byte("late"))
+	assert.Error(t, err)
+}
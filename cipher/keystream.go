@@ -0,0 +1,153 @@
+package cipher
+
+import (
+	"context"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+// Keystream is the CTR or OFB keystream of a context, consumed
+// sequentially by XORKeyStream and repositioned with Seek. In CTR mode a
+// seek is a counter addition, so decryption can start anywhere in a large
+// file without touching the preceding blocks. OFB chains every block to the
+// previous one; Precompute keeps a prefix of its keystream so that seeks
+// within it are just as cheap, and seeks past it continue from the last
+// precomputed block.
+type Keystream struct {
+	ctx      context.Context
+	c        *CipherContext
+	counter  bool
+	iv       []byte
+	register []byte
+	block    []byte
+	offset   int
+	index    uint64
+	computed []byte
+}
+
+func (c *CipherContext) NewKeystream(ctx context.Context) (*Keystream, error) {
+	blockSize := c.cipher.BlockSize()
+	iv := c.startIV(c.iv)
+	if len(iv) != blockSize {
+		return nil, errors.ErrInvalidIVSize
+	}
+
+	var counter bool
+	switch c.mode.(type) {
+	case *CTRMode:
+		counter = true
+	case *OFBMode:
+	default:
+		return nil, errors.Annotate(errors.ErrInvalidMode, "keystream requires CTR or OFB: %w")
+	}
+
+	return &Keystream{
+		ctx:      ctx,
+		c:        c,
+		counter:  counter,
+		iv:       iv,
+		register: append([]byte{}, iv...),
+		block:    make([]byte, blockSize),
+		offset:   blockSize,
+	}, nil
+}
+
+// Seek positions the keystream at the start of block blockIndex, counted
+// from the context IV.
+func (k *Keystream) Seek(blockIndex uint64) error {
+	blockSize := len(k.block)
+	k.offset = blockSize
+
+	if k.counter {
+		copy(k.register, k.iv)
+		addCounter(k.register, blockIndex)
+		k.index = blockIndex
+		return nil
+	}
+
+	// Replay OFB from the closest known block at or before the target.
+	if precomputed := uint64(len(k.computed) / blockSize); blockIndex < k.index || k.index < precomputed {
+		k.index = min(blockIndex, precomputed)
+		if k.index == 0 {
+			copy(k.register, k.iv)
+		} else {
+			copy(k.register, k.computed[(k.index-1)*uint64(blockSize):])
+		}
+	}
+	for k.index < blockIndex {
+		if err := k.next(); err != nil {
+			return err
+		}
+	}
+	k.offset = blockSize
+	return nil
+}
+
+// Precompute generates and keeps the first blocks of an OFB keystream. It
+// does not move the current position and is a no-op in CTR mode, which
+// seeks in constant time anyway.
+func (k *Keystream) Precompute(blocks int) error {
+	if blocks < 0 {
+		return errors.ErrInvalidParameters
+	}
+	if k.counter {
+		return nil
+	}
+
+	blockSize := len(k.block)
+	computed := make([]byte, blocks*blockSize)
+	input := k.iv
+	for idx := 0; idx < blocks; idx++ {
+		if err := checkCancelled(k.ctx, idx, blockSize); err != nil {
+			return err
+		}
+		output := computed[idx*blockSize : (idx+1)*blockSize]
+		if err := EncryptBlockTo(k.ctx, k.c.cipher, output, input); err != nil {
+			return &errors.BlockError{Index: idx, Err: err}
+		}
+		input = output
+	}
+	k.computed = computed
+	return nil
+}
+
+// XORKeyStream XORs src with the keystream from the current position into
+// dst, which must be at least as long, and advances the position.
+func (k *Keystream) XORKeyStream(dst, src []byte) error {
+	if len(dst) < len(src) {
+		return errors.ErrInvalidDataLength
+	}
+
+	for i := range src {
+		if k.offset == len(k.block) {
+			if err := k.next(); err != nil {
+				return err
+			}
+		}
+		dst[i] = src[i] ^ k.block[k.offset]
+		k.offset++
+	}
+	return nil
+}
+
+func (k *Keystream) next() error {
+	blockSize := len(k.block)
+	if err := checkCancelled(k.ctx, int(k.index), blockSize); err != nil {
+		return err
+	}
+
+	if !k.counter && k.index < uint64(len(k.computed)/blockSize) {
+		copy(k.block, k.computed[k.index*uint64(blockSize):])
+	} else if err := EncryptBlockTo(k.ctx, k.c.cipher, k.block, k.register); err != nil {
+		return &errors.BlockError{Index: int(k.index), Err: err}
+	}
+
+	if k.counter {
+		addCounter(k.register, 1)
+	} else {
+		copy(k.register, k.block)
+	}
+	k.index++
+	k.offset = 0
+	return nil
+}
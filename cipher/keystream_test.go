@@ -0,0 +1,94 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/aes"
+	"github.com/masterkusok/crypto/errors"
+)
+
+func TestKeystreamSeek(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("0123456789abcdef")
+	iv := bytes.Repeat([]byte{0xFE}, 16)
+	plaintext := make([]byte, 40*16+7)
+	for i := range plaintext {
+		plaintext[i] = byte(i * 13)
+	}
+
+	for _, mode := range []cipher.CipherMode{&cipher.CTRMode{}, &cipher.OFBMode{}} {
+		c, err := cipher.NewCipherContext(aes.NewAES128(), key, mode, cipher.NoPadding, cipher.WithIV(iv))
+		require.NoError(t, err)
+		ciphertext, err := c.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+
+		for _, precompute := range []int{0, 10} {
+			k, err := c.NewKeystream(ctx)
+			require.NoError(t, err)
+			require.NoError(t, k.Precompute(precompute))
+
+			for _, block := range []uint64{25, 3, 0, 12, 39, 10, 40} {
+				require.NoError(t, k.Seek(block))
+				start := int(block) * 16
+				dst := make([]byte, len(ciphertext)-start)
+				require.NoError(t, k.XORKeyStream(dst, ciphertext[start:]))
+				assert.Equal(t, plaintext[start:], dst, "%T block %d", mode, block)
+			}
+		}
+
+		// Sequential calls continue mid-block.
+		k, err := c.NewKeystream(ctx)
+		require.NoError(t, err)
+		dst := make([]byte, len(plaintext))
+		for start := 0; start < len(plaintext); start += 5 {
+			end := min(start+5, len(plaintext))
+			require.NoError(t, k.XORKeyStream(dst[start:end], plaintext[start:end]))
+		}
+		assert.Equal(t, ciphertext, dst, "%T", mode)
+	}
+}
+
+func TestKeystreamSeekCTRCounterWrap(t *testing.T) {
+	ctx := context.Background()
+	iv := append(bytes.Repeat([]byte{0}, 8), bytes.Repeat([]byte{0xFF}, 8)...)
+
+	c, err := cipher.NewCipherContext(aes.NewAES128(), make([]byte, 16), &cipher.CTRMode{}, cipher.NoPadding, cipher.WithIV(iv))
+	require.NoError(t, err)
+	ciphertext, err := c.Encrypt(ctx, make([]byte, 4*16))
+	require.NoError(t, err)
+
+	k, err := c.NewKeystream(ctx)
+	require.NoError(t, err)
+	require.NoError(t, k.Seek(2))
+	dst := make([]byte, 32)
+	require.NoError(t, k.XORKeyStream(dst, make([]byte, 32)))
+	assert.Equal(t, ciphertext[32:], dst)
+}
+
+func TestKeystreamInvalid(t *testing.T) {
+	ctx := context.Background()
+	iv := make([]byte, 16)
+
+	cbc, err := cipher.NewCipherContext(aes.NewAES128(), make([]byte, 16), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(iv))
+	require.NoError(t, err)
+	_, err = cbc.NewKeystream(ctx)
+	assert.ErrorIs(t, err, errors.ErrInvalidMode)
+
+	noIV, err := cipher.NewCipherContext(aes.NewAES128(), make([]byte, 16), &cipher.CTRMode{}, cipher.NoPadding)
+	require.NoError(t, err)
+	_, err = noIV.NewKeystream(ctx)
+	assert.ErrorIs(t, err, errors.ErrInvalidIVSize)
+
+	ofb, err := cipher.NewCipherContext(aes.NewAES128(), make([]byte, 16), &cipher.OFBMode{}, cipher.NoPadding, cipher.WithIV(iv))
+	require.NoError(t, err)
+	k, err := ofb.NewKeystream(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, k.Precompute(-1), errors.ErrInvalidParameters)
+	assert.ErrorIs(t, k.XORKeyStream(make([]byte, 2), make([]byte, 4)), errors.ErrInvalidDataLength)
+}
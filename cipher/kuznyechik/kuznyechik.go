@@ -0,0 +1,169 @@
+package kuznyechik
+
+import (
+	"context"
+	"sync"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/masterkusok/crypto/tables"
+)
+
+const (
+	BlockSize = 16
+	KeySize   = 32
+
+	numRounds = 10
+)
+
+var (
+	initOnce  sync.Once
+	invPi     [256]byte
+	mulTable  [16][256]byte
+	constants [32][BlockSize]byte
+)
+
+func initTables() {
+	for i, v := range tables.KuznyechikPi {
+		invPi[v] = byte(i)
+	}
+
+	for i, coefficient := range tables.KuznyechikLinear {
+		for x := 0; x < 256; x++ {
+			// The polynomial is a fixed irreducible constant, so the
+			// multiplication cannot fail.
+			mulTable[i][x], _ = cryptoMath.GF256Mul(coefficient, byte(x), tables.KuznyechikPolynomial)
+		}
+	}
+
+	for i := range constants {
+		constants[i][BlockSize-1] = byte(i + 1)
+		l(&constants[i])
+	}
+}
+
+type Kuznyechik struct {
+	*cipher.SPNetwork
+}
+
+func NewKuznyechik() *Kuznyechik {
+	initOnce.Do(initTables)
+	return &Kuznyechik{
+		SPNetwork: cipher.NewSPNetwork(&KeyScheduler{}, &SubstitutionLayer{}, &LinearLayer{}, BlockSize),
+	}
+}
+
+type KeyScheduler struct{}
+
+func (k *KeyScheduler) GenerateRoundKeys(ctx context.Context, key []byte) ([][]byte, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	var a1, a0 [BlockSize]byte
+	copy(a1[:], key[:BlockSize])
+	copy(a0[:], key[BlockSize:])
+	roundKeys := make([][]byte, 0, numRounds)
+	roundKeys = append(roundKeys, clone(a1), clone(a0))
+
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			t := a1
+			xor(&t, &constants[8*i+j])
+			s(&t)
+			l(&t)
+			xor(&t, &a0)
+			a1, a0 = t, a1
+		}
+		roundKeys = append(roundKeys, clone(a1), clone(a0))
+	}
+
+	return roundKeys, nil
+}
+
+type SubstitutionLayer struct{}
+
+func (*SubstitutionLayer) Substitute(ctx context.Context, block []byte) ([]byte, error) {
+	return apply(block, s)
+}
+
+func (*SubstitutionLayer) InverseSubstitute(ctx context.Context, block []byte) ([]byte, error) {
+	return apply(block, invS)
+}
+
+type LinearLayer struct{}
+
+func (*LinearLayer) Transform(ctx context.Context, block []byte) ([]byte, error) {
+	return apply(block, l)
+}
+
+func (*LinearLayer) InverseTransform(ctx context.Context, block []byte) ([]byte, error) {
+	return apply(block, invL)
+}
+
+func clone(state [BlockSize]byte) []byte {
+	return state[:]
+}
+
+func apply(block []byte, transform func(*[BlockSize]byte)) ([]byte, error) {
+	if len(block) != BlockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+
+	var state [BlockSize]byte
+	copy(state[:], block)
+	transform(&state)
+	return state[:], nil
+}
+
+func xor(state, key *[BlockSize]byte) {
+	for i := range state {
+		state[i] ^= key[i]
+	}
+}
+
+func s(state *[BlockSize]byte) {
+	for i, v := range state {
+		state[i] = tables.KuznyechikPi[v]
+	}
+}
+
+func invS(state *[BlockSize]byte) {
+	for i, v := range state {
+		state[i] = invPi[v]
+	}
+}
+
+func linear(state *[BlockSize]byte) byte {
+	var sum byte
+	for i, v := range state {
+		sum ^= mulTable[i][v]
+	}
+	return sum
+}
+
+func r(state *[BlockSize]byte) {
+	sum := linear(state)
+	copy(state[1:], state[:BlockSize-1])
+	state[0] = sum
+}
+
+func invR(state *[BlockSize]byte) {
+	first := state[0]
+	copy(state[:BlockSize-1], state[1:])
+	state[BlockSize-1] = first
+	state[BlockSize-1] = linear(state)
+}
+
+func l(state *[BlockSize]byte) {
+	for i := 0; i < BlockSize; i++ {
+		r(state)
+	}
+}
+
+func invL(state *[BlockSize]byte) {
+	for i := 0; i < BlockSize; i++ {
+		invR(state)
+	}
+}
@@ -0,0 +1,111 @@
+package kuznyechik
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/tables"
+)
+
+var _ cipher.BlockCipher = (*Kuznyechik)(nil)
+
+const (
+	testKey        = "8899aabbccddeeff0011223344556677fedcba98765432100123456789abcdef"
+	testPlaintext  = "1122334455667700ffeeddccbbaa9988"
+	testCiphertext = "7f679d90bebc24305a468d42b9d4edcd"
+)
+
+func block(t *testing.T, s string) [BlockSize]byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	var out [BlockSize]byte
+	copy(out[:], b)
+	return out
+}
+
+func slice(t *testing.T, s string) []byte {
+	b := block(t, s)
+	return b[:]
+}
+
+func TestPiIsPermutation(t *testing.T) {
+	seen := make(map[byte]bool)
+	for _, v := range tables.KuznyechikPi {
+		seen[v] = true
+	}
+	assert.Len(t, seen, 256)
+}
+
+func TestTransformations(t *testing.T) {
+	NewKuznyechik()
+
+	state := block(t, "ffeeddccbbaa99881122334455667700")
+	s(&state)
+	assert.Equal(t, block(t, "b66cd8887d38e8d77765aeea0c9a7efc"), state)
+	invS(&state)
+	assert.Equal(t, block(t, "ffeeddccbbaa99881122334455667700"), state)
+
+	state = block(t, "00000000000000000000000000000100")
+	r(&state)
+	assert.Equal(t, block(t, "94000000000000000000000000000001"), state)
+	invR(&state)
+	assert.Equal(t, block(t, "00000000000000000000000000000100"), state)
+
+	state = block(t, "64a59400000000000000000000000000")
+	l(&state)
+	assert.Equal(t, block(t, "d456584dd0e3e84cc3166e4b7fa2890d"), state)
+	invL(&state)
+	assert.Equal(t, block(t, "64a59400000000000000000000000000"), state)
+}
+
+func TestKeySchedule(t *testing.T) {
+	key, err := hex.DecodeString(testKey)
+	require.NoError(t, err)
+
+	k := NewKuznyechik()
+	require.NoError(t, k.SetKey(context.Background(), key))
+
+	assert.Equal(t, slice(t, "8899aabbccddeeff0011223344556677"), k.RoundKeys[0])
+	assert.Equal(t, slice(t, "fedcba98765432100123456789abcdef"), k.RoundKeys[1])
+	assert.Equal(t, slice(t, "db31485315694343228d6aef8cc78c44"), k.RoundKeys[2])
+	assert.Equal(t, slice(t, "72e9dd7416bcf45b755dbaa88e4a4043"), k.RoundKeys[9])
+}
+
+func TestVector(t *testing.T) {
+	ctx := context.Background()
+	key, err := hex.DecodeString(testKey)
+	require.NoError(t, err)
+	plaintext, err := hex.DecodeString(testPlaintext)
+	require.NoError(t, err)
+
+	k := NewKuznyechik()
+	require.NoError(t, k.SetKey(ctx, key))
+
+	ciphertext, err := k.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, testCiphertext, hex.EncodeToString(ciphertext))
+
+	decrypted, err := k.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestInvalid(t *testing.T) {
+	ctx := context.Background()
+	k := NewKuznyechik()
+
+	_, err := k.Encrypt(ctx, make([]byte, BlockSize))
+	require.ErrorIs(t, err, errors.ErrInvalidKeySize)
+
+	require.ErrorIs(t, k.SetKey(ctx, make([]byte, 16)), errors.ErrInvalidKeySize)
+	require.NoError(t, k.SetKey(ctx, make([]byte, KeySize)))
+
+	_, err = k.Decrypt(ctx, make([]byte, 8))
+	require.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
@@ -0,0 +1,186 @@
+package loki97
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	BlockSize = 16
+
+	numRounds  = 16
+	numSubkeys = 3 * numRounds
+	delta      = 0x9E3779B97F4A7C15 // floor((sqrt(5)-1) * 2^63)
+)
+
+var (
+	initOnce sync.Once
+	s1       [1 << 13]byte
+	s2       [1 << 11]byte
+	perm     [8][256]uint64
+)
+
+// initTables builds S1 = (x^0x1FFF)^3 in GF(2^13) and S2 = (x^0x7FF)^3 in
+// GF(2^11), keeping the low byte, and the byte-wise lookup for the bit
+// transposition P.
+func initTables() {
+	for x := range s1 {
+		s1[x] = cube(uint32(x)^0x1FFF, 0x2911, 13)
+	}
+	for x := range s2 {
+		s2[x] = cube(uint32(x)^0x7FF, 0xAA7, 11)
+	}
+
+	// P sends input bit 63-(8*i+j) to output bit 56-8*j+i, i.e. it
+	// transposes the 8x8 bit matrix with a reversal of row order.
+	for i := range perm {
+		for v := 0; v < 256; v++ {
+			var out uint64
+			for j := 0; j < 8; j++ {
+				if v&(0x80>>j) != 0 {
+					out |= 1 << (56 - 8*j + i)
+				}
+			}
+			perm[i][v] = out
+		}
+	}
+}
+
+func cube(x, poly uint32, degree uint) byte {
+	return byte(gfMul(gfMul(x, x, poly, degree), x, poly, degree))
+}
+
+func gfMul(a, b, poly uint32, degree uint) uint32 {
+	var r uint32
+	for ; b != 0; b >>= 1 {
+		if b&1 != 0 {
+			r ^= a
+		}
+		a <<= 1
+		if a>>degree != 0 {
+			a ^= poly
+		}
+	}
+	return r
+}
+
+type LOKI97 struct {
+	subkeys [numSubkeys]uint64
+	keyed   bool
+}
+
+func NewLOKI97() *LOKI97 {
+	initOnce.Do(initTables)
+	return &LOKI97{}
+}
+
+func (l *LOKI97) BlockSize() int {
+	return BlockSize
+}
+
+func (l *LOKI97) SetKey(ctx context.Context, key []byte) error {
+	var k4, k3, k2, k1 uint64
+	switch len(key) {
+	case 16:
+		k4, k3 = binary.BigEndian.Uint64(key), binary.BigEndian.Uint64(key[8:])
+		k2, k1 = f(k3, k4), f(k4, k3)
+	case 24:
+		k4, k3, k2 = binary.BigEndian.Uint64(key), binary.BigEndian.Uint64(key[8:]), binary.BigEndian.Uint64(key[16:])
+		k1 = f(k4, k3)
+	case 32:
+		k4, k3, k2, k1 = binary.BigEndian.Uint64(key), binary.BigEndian.Uint64(key[8:]),
+			binary.BigEndian.Uint64(key[16:]), binary.BigEndian.Uint64(key[24:])
+	default:
+		return errors.ErrInvalidKeySize
+	}
+
+	for i := range l.subkeys {
+		t := k4 ^ f(k1+k3+delta*uint64(i+1), k2)
+		k4, k3, k2, k1 = k3, k2, k1, t
+		l.subkeys[i] = t
+	}
+
+	l.keyed = true
+	return nil
+}
+
+func (l *LOKI97) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := l.check(block); err != nil {
+		return nil, err
+	}
+
+	left, right := binary.BigEndian.Uint64(block), binary.BigEndian.Uint64(block[8:])
+	for i := 0; i < numRounds; i++ {
+		sk := l.subkeys[3*i : 3*i+3]
+		a := right + sk[0]
+		left, right = a+sk[2], left^f(a, sk[1])
+	}
+
+	out := make([]byte, BlockSize)
+	binary.BigEndian.PutUint64(out, right)
+	binary.BigEndian.PutUint64(out[8:], left)
+	return out, nil
+}
+
+func (l *LOKI97) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := l.check(block); err != nil {
+		return nil, err
+	}
+
+	right, left := binary.BigEndian.Uint64(block), binary.BigEndian.Uint64(block[8:])
+	for i := numRounds - 1; i >= 0; i-- {
+		sk := l.subkeys[3*i : 3*i+3]
+		a := left - sk[2]
+		left, right = right^f(a, sk[1]), a-sk[0]
+	}
+
+	out := make([]byte, BlockSize)
+	binary.BigEndian.PutUint64(out, left)
+	binary.BigEndian.PutUint64(out[8:], right)
+	return out, nil
+}
+
+func (l *LOKI97) check(block []byte) error {
+	if len(block) != BlockSize {
+		return errors.ErrInvalidBlockSize
+	}
+	if !l.keyed {
+		return errors.ErrInvalidKeySize
+	}
+	return nil
+}
+
+// f is the round function Sb(P(Sa(E(KP(a, b)))), b).
+func f(a, b uint64) uint64 {
+	// KP swaps the bits of the two halves of a selected by the low half of b.
+	swap := (a>>32 ^ a) & b & 0xFFFFFFFF
+	a ^= swap<<32 | swap
+
+	// E expands to 96 bits and Sa substitutes the 13/11-bit groups.
+	x := uint64(s1[(a&0x1F)<<8|a>>56])<<56 |
+		uint64(s2[a>>48&0x7FF])<<48 |
+		uint64(s1[a>>40&0x1FFF])<<40 |
+		uint64(s2[a>>32&0x7FF])<<32 |
+		uint64(s2[a>>24&0x7FF])<<24 |
+		uint64(s1[a>>16&0x1FFF])<<16 |
+		uint64(s2[a>>8&0x7FF])<<8 |
+		uint64(s1[a&0x1FFF])
+
+	var p uint64
+	for i := 0; i < 8; i++ {
+		p |= perm[i][byte(x>>(56-8*i))]
+	}
+
+	// Sb takes its high input bits from the top half of b.
+	return uint64(s2[(b>>61)<<8|p>>56])<<56 |
+		uint64(s2[(b>>58&0x7)<<8|p>>48&0xFF])<<48 |
+		uint64(s1[(b>>53&0x1F)<<8|p>>40&0xFF])<<40 |
+		uint64(s1[(b>>48&0x1F)<<8|p>>32&0xFF])<<32 |
+		uint64(s2[(b>>45&0x7)<<8|p>>24&0xFF])<<24 |
+		uint64(s2[(b>>42&0x7)<<8|p>>16&0xFF])<<16 |
+		uint64(s1[(b>>37&0x1F)<<8|p>>8&0xFF])<<8 |
+		uint64(s1[(b>>32&0x1F)<<8|p&0xFF])
+}
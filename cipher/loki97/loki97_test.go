@@ -0,0 +1,92 @@
+package loki97
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+var _ cipher.BlockCipher = (*LOKI97)(nil)
+
+func sequence(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestSBoxes(t *testing.T) {
+	NewLOKI97()
+
+	assert.Equal(t, []byte{39, 163, 5, 135}, s1[:4])
+	assert.Equal(t, []byte{45, 111, 239, 171}, s2[:4])
+}
+
+func TestVector(t *testing.T) {
+	ctx := context.Background()
+	l := NewLOKI97()
+	require.NoError(t, l.SetKey(ctx, sequence(32)))
+
+	ciphertext, err := l.Encrypt(ctx, sequence(BlockSize))
+	require.NoError(t, err)
+	assert.Equal(t, "75080e359f10fe640144b35c57128dad", hex.EncodeToString(ciphertext))
+
+	plaintext, err := l.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, sequence(BlockSize), plaintext)
+}
+
+func TestRoundTripKeySizes(t *testing.T) {
+	ctx := context.Background()
+	for _, size := range []int{16, 24, 32} {
+		key := make([]byte, size)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		block := make([]byte, BlockSize)
+		_, err = rand.Read(block)
+		require.NoError(t, err)
+
+		l := NewLOKI97()
+		require.NoError(t, l.SetKey(ctx, key))
+		ciphertext, err := l.Encrypt(ctx, block)
+		require.NoError(t, err)
+		assert.NotEqual(t, block, ciphertext)
+		plaintext, err := l.Decrypt(ctx, ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, block, plaintext, "key size %d", size)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	ctx := context.Background()
+	l := NewLOKI97()
+
+	_, err := l.Encrypt(ctx, make([]byte, BlockSize))
+	require.ErrorIs(t, err, errors.ErrInvalidKeySize)
+
+	require.ErrorIs(t, l.SetKey(ctx, make([]byte, 20)), errors.ErrInvalidKeySize)
+	require.NoError(t, l.SetKey(ctx, make([]byte, 16)))
+
+	_, err = l.Decrypt(ctx, make([]byte, 8))
+	require.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	ctx := context.Background()
+	l := NewLOKI97()
+	require.NoError(b, l.SetKey(ctx, make([]byte, 16)))
+	block := make([]byte, BlockSize)
+	b.SetBytes(BlockSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Encrypt(ctx, block)
+	}
+}
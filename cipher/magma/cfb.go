@@ -0,0 +1,123 @@
+package magma
+
+import (
+	"context"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+// MeshingCFB is GOST 28147-89 CFB with CryptoPro key meshing (RFC 4357,
+// section 2.3.2). Every MeshingInterval bytes the key is replaced by the
+// decryption of a fixed constant under the current key and the feedback
+// register is re-encrypted under the new key. The meshing state belongs to
+// a single message: create a new MeshingCFB for each IV. Bytes must be
+// processed in order, so the stream is never split across workers.
+type MeshingCFB struct {
+	ctx       context.Context
+	m         *Magma
+	decrypt   bool
+	register  []byte
+	block     []byte
+	offset    int
+	processed int
+}
+
+// NewMeshingCFBEncrypter starts encrypting a message under key and iv. opts
+// selects the parameter set; CryptoPro interop needs SBoxCryptoProA and the
+// GOST28147 byte order.
+func NewMeshingCFBEncrypter(ctx context.Context, opts *Options, key, iv []byte) (*MeshingCFB, error) {
+	return newMeshingCFB(ctx, opts, key, iv, false)
+}
+
+// NewMeshingCFBDecrypter starts decrypting a message produced by
+// NewMeshingCFBEncrypter with the same parameters.
+func NewMeshingCFBDecrypter(ctx context.Context, opts *Options, key, iv []byte) (*MeshingCFB, error) {
+	return newMeshingCFB(ctx, opts, key, iv, true)
+}
+
+func newMeshingCFB(ctx context.Context, opts *Options, key, iv []byte, decrypt bool) (*MeshingCFB, error) {
+	if len(iv) != BlockSize {
+		return nil, errors.ErrInvalidIVSize
+	}
+
+	m, err := NewMagma(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.SetKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &MeshingCFB{
+		ctx:      ctx,
+		m:        m,
+		decrypt:  decrypt,
+		register: append([]byte{}, iv...),
+		block:    make([]byte, BlockSize),
+		offset:   BlockSize,
+	}, nil
+}
+
+// XORKeyStream encrypts or decrypts src into dst, which must be at least as
+// long, and continues the message where the previous call stopped.
+func (c *MeshingCFB) XORKeyStream(dst, src []byte) error {
+	if len(dst) < len(src) {
+		return errors.ErrInvalidDataLength
+	}
+
+	for i, b := range src {
+		if c.offset == BlockSize {
+			if err := c.next(); err != nil {
+				return err
+			}
+		}
+
+		dst[i] = b ^ c.block[c.offset]
+		if c.decrypt {
+			c.register[c.offset] = b
+		} else {
+			c.register[c.offset] = dst[i]
+		}
+		c.offset++
+	}
+	return nil
+}
+
+func (c *MeshingCFB) next() error {
+	if c.processed == MeshingInterval {
+		if err := c.mesh(); err != nil {
+			return errors.Annotate(err, "key meshing failed: %w")
+		}
+	}
+
+	block, err := c.m.Encrypt(c.ctx, c.register)
+	if err != nil {
+		return err
+	}
+	copy(c.block, block)
+	c.processed += BlockSize
+	c.offset = 0
+	return nil
+}
+
+func (c *MeshingCFB) mesh() error {
+	key := make([]byte, 0, KeySize)
+	for i := 0; i < len(meshingConstant); i += BlockSize {
+		out, err := c.m.Decrypt(c.ctx, meshingConstant[i:i+BlockSize])
+		if err != nil {
+			return err
+		}
+		key = append(key, out...)
+	}
+	if err := c.m.SetKey(c.ctx, key); err != nil {
+		return err
+	}
+
+	register, err := c.m.Encrypt(c.ctx, c.register)
+	if err != nil {
+		return err
+	}
+	copy(c.register, register)
+	c.processed = 0
+	return nil
+}
@@ -0,0 +1,89 @@
+package magma
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cryptoProOptions = &Options{SBox: SBoxCryptoProA, GOST28147: true}
+
+func cfbPlaintext() []byte {
+	plaintext := make([]byte, 3*MeshingInterval+16)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+	return plaintext
+}
+
+// The expected ciphertext was produced by GnuTLS
+// (GNUTLS_CIPHER_GOST28147_CPA_CFB) and matches libgcrypt's
+// GCRY_CIPHER_GOST28147_MESH in CFB mode byte for byte. It spans two key
+// meshings.
+func TestMeshingCFBCryptoProVector(t *testing.T) {
+	ctx := context.Background()
+	key := mustHex(t, "808386898c8f9295989b9ea1a4a7aaadb0b3b6b9bcbfc2c5c8cbced1d4d7dadd")
+	iv := mustHex(t, "a0a1a2a3a4a5a6a7")
+	plaintext := cfbPlaintext()
+
+	enc, err := NewMeshingCFBEncrypter(ctx, cryptoProOptions, key, iv)
+	require.NoError(t, err)
+	ciphertext := make([]byte, len(plaintext))
+	require.NoError(t, enc.XORKeyStream(ciphertext, plaintext))
+
+	assert.Equal(t, "c53c8c4561d00b6544acb84d29975284", hex.EncodeToString(ciphertext[:16]))
+	assert.Equal(t, "94a752db95cbdb8e75cfd2e6b4fb1c4a049e7d1aa8fefb77", hex.EncodeToString(ciphertext[1016:1040]))
+	assert.Equal(t, "b915fdc72bb03baadebed15f73a1e78797c635415606ad0e", hex.EncodeToString(ciphertext[2040:2064]))
+	assert.Equal(t, "bca45ff8feae6c5c9630f50caf136583", hex.EncodeToString(ciphertext[len(ciphertext)-16:]))
+
+	dec, err := NewMeshingCFBDecrypter(ctx, cryptoProOptions, key, iv)
+	require.NoError(t, err)
+	decrypted := make([]byte, len(ciphertext))
+	require.NoError(t, dec.XORKeyStream(decrypted, ciphertext))
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestMeshingCFBPerMessage(t *testing.T) {
+	ctx := context.Background()
+	key := make([]byte, KeySize)
+	iv := make([]byte, BlockSize)
+	plaintext := cfbPlaintext()
+
+	first, err := NewMeshingCFBEncrypter(ctx, cryptoProOptions, key, iv)
+	require.NoError(t, err)
+	want := make([]byte, len(plaintext))
+	require.NoError(t, first.XORKeyStream(want, plaintext))
+
+	// A second message is independent of the first, and chunk boundaries
+	// do not have to follow blocks or meshing intervals.
+	second, err := NewMeshingCFBEncrypter(ctx, cryptoProOptions, key, iv)
+	require.NoError(t, err)
+	got := make([]byte, len(plaintext))
+	for _, span := range [][2]int{{0, 3}, {3, 1021}, {1021, 1030}, {1030, 2500}, {2500, len(plaintext)}} {
+		require.NoError(t, second.XORKeyStream(got[span[0]:span[1]], plaintext[span[0]:span[1]]))
+	}
+	assert.Equal(t, want, got)
+
+	dec, err := NewMeshingCFBDecrypter(ctx, cryptoProOptions, key, iv)
+	require.NoError(t, err)
+	decrypted := make([]byte, len(want))
+	require.NoError(t, dec.XORKeyStream(decrypted[:7], want[:7]))
+	require.NoError(t, dec.XORKeyStream(decrypted[7:], want[7:]))
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestMeshingCFBInvalid(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewMeshingCFBEncrypter(ctx, nil, make([]byte, KeySize), make([]byte, 4))
+	require.Error(t, err)
+	_, err = NewMeshingCFBEncrypter(ctx, nil, make([]byte, 16), make([]byte, BlockSize))
+	require.Error(t, err)
+
+	c, err := NewMeshingCFBEncrypter(ctx, nil, make([]byte, KeySize), make([]byte, BlockSize))
+	require.NoError(t, err)
+	require.Error(t, c.XORKeyStream(make([]byte, 2), make([]byte, 3)))
+}
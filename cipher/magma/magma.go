@@ -0,0 +1,203 @@
+package magma
+
+import (
+	"context"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	BlockSize = 8
+	KeySize   = 32
+
+	MeshingInterval = 1024
+
+	numRounds = 32
+)
+
+type SBox [8][16]byte
+
+var (
+	SBoxTC26Z = SBox{
+		{12, 4, 6, 2, 10, 5, 11, 9, 14, 8, 13, 7, 0, 3, 15, 1},
+		{6, 8, 2, 3, 9, 10, 5, 12, 1, 14, 4, 7, 11, 13, 0, 15},
+		{11, 3, 5, 8, 2, 15, 10, 13, 14, 1, 7, 4, 12, 9, 6, 0},
+		{12, 8, 2, 1, 13, 4, 15, 6, 7, 0, 10, 5, 3, 14, 9, 11},
+		{7, 15, 5, 10, 8, 1, 6, 13, 0, 9, 3, 14, 11, 4, 2, 12},
+		{5, 13, 15, 6, 9, 2, 12, 10, 11, 7, 8, 1, 4, 3, 14, 0},
+		{8, 14, 2, 5, 6, 9, 1, 12, 15, 4, 11, 0, 13, 10, 3, 7},
+		{1, 7, 14, 13, 0, 5, 8, 3, 4, 15, 10, 6, 9, 12, 11, 2},
+	}
+	SBoxCryptoProA = SBox{
+		{9, 6, 3, 2, 8, 11, 1, 7, 10, 4, 14, 15, 12, 0, 13, 5},
+		{3, 7, 14, 9, 8, 10, 15, 0, 5, 2, 6, 12, 11, 4, 13, 1},
+		{14, 4, 6, 2, 11, 3, 13, 8, 12, 15, 5, 10, 0, 7, 1, 9},
+		{14, 7, 10, 12, 13, 1, 3, 9, 0, 2, 11, 4, 15, 8, 5, 6},
+		{11, 5, 1, 9, 8, 13, 15, 0, 14, 4, 2, 3, 12, 7, 10, 6},
+		{3, 10, 13, 12, 1, 2, 0, 11, 7, 5, 9, 4, 8, 15, 14, 6},
+		{1, 13, 2, 9, 7, 10, 6, 0, 8, 12, 4, 5, 15, 3, 11, 14},
+		{11, 10, 15, 5, 0, 12, 14, 8, 6, 2, 3, 9, 1, 7, 13, 4},
+	}
+)
+
+var meshingConstant = []byte{
+	0x69, 0x00, 0x72, 0x22, 0x64, 0xC9, 0x04, 0x23,
+	0x8D, 0x3A, 0xDB, 0x96, 0x46, 0xE9, 0x2A, 0xC4,
+	0x18, 0xFE, 0xAC, 0x94, 0x00, 0xED, 0x07, 0x12,
+	0xC0, 0x86, 0xDC, 0xC2, 0xEF, 0x4C, 0xA9, 0x2B,
+}
+
+type KeyScheduler struct{}
+
+func (k *KeyScheduler) GenerateRoundKeys(ctx context.Context, key []byte) ([][]byte, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	roundKeys := make([][]byte, numRounds)
+	for round := range roundKeys {
+		i := round % 8
+		if round >= 24 {
+			i = 7 - i
+		}
+		roundKeys[round] = append([]byte{}, key[4*i:4*i+4]...)
+	}
+	return roundKeys, nil
+}
+
+type RoundFunction struct {
+	SBox SBox
+}
+
+func (r *RoundFunction) Transform(ctx context.Context, block, roundKey []byte) ([]byte, error) {
+	if len(block) != 4 || len(roundKey) != 4 {
+		return nil, errors.ErrInvalidBlockSize
+	}
+
+	x := binary.BigEndian.Uint32(block) + binary.BigEndian.Uint32(roundKey)
+	var y uint32
+	for i := 0; i < 8; i++ {
+		y |= uint32(r.SBox[i][(x>>(4*i))&0x0F]) << (4 * i)
+	}
+
+	out := make([]byte, 4)
+	binary.BigEndian.PutUint32(out, bits.RotateLeft32(y, 11))
+	return out, nil
+}
+
+type Options struct {
+	SBox SBox
+	// GOST28147 selects the GOST 28147-89 byte order used by CryptoPro
+	// tooling: key words and block halves are little-endian. GOST R
+	// 34.12-2015 reads both big-endian.
+	GOST28147 bool
+}
+
+func DefaultOptions() *Options {
+	return &Options{SBox: SBoxTC26Z}
+}
+
+type Magma struct {
+	feistel      *cipher.FeistelNetwork
+	littleEndian bool
+}
+
+func NewMagma(opts *Options) (*Magma, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	for i, row := range opts.SBox {
+		for _, v := range row {
+			if v > 0x0F {
+				return nil, errors.Annotate(errors.ErrInvalidParameters, "S-box %d has an output wider than 4 bits: %w", i+1)
+			}
+		}
+	}
+
+	return &Magma{
+		feistel:      cipher.NewFeistelNetwork(&KeyScheduler{}, &RoundFunction{SBox: opts.SBox}, BlockSize),
+		littleEndian: opts.GOST28147,
+	}, nil
+}
+
+func (m *Magma) BlockSize() int {
+	return BlockSize
+}
+
+func (m *Magma) SetKey(ctx context.Context, key []byte) error {
+	if m.littleEndian && len(key) == KeySize {
+		key = reverseWords(key)
+	}
+	return m.feistel.SetKey(ctx, key)
+}
+
+// The last Magma round does not swap halves while the Feistel network
+// always does, so ciphertext halves are exchanged on the way out and in.
+// In the 28147-89 byte order both halves are little-endian and stored low
+// half first: the input is read reversed and each output half is reversed
+// in place.
+func (m *Magma) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if len(block) != BlockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	if m.littleEndian {
+		block = reverse(block)
+	}
+
+	out, err := m.feistel.Encrypt(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	if m.littleEndian {
+		return reverseWords(out), nil
+	}
+	return swapHalves(out), nil
+}
+
+func (m *Magma) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if len(block) != BlockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	if m.littleEndian {
+		block = reverseWords(block)
+	} else {
+		block = swapHalves(block)
+	}
+
+	out, err := m.feistel.Decrypt(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	if m.littleEndian {
+		return reverse(out), nil
+	}
+	return out, nil
+}
+
+func swapHalves(block []byte) []byte {
+	out := make([]byte, len(block))
+	half := len(block) / 2
+	copy(out, block[half:])
+	copy(out[len(block)-half:], block[:half])
+	return out
+}
+
+func reverse(block []byte) []byte {
+	out := make([]byte, len(block))
+	for i, b := range block {
+		out[len(block)-1-i] = b
+	}
+	return out
+}
+
+func reverseWords(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i += 4 {
+		out = append(out, reverse(b[i:i+4])...)
+	}
+	return out
+}
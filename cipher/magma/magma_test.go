@@ -0,0 +1,128 @@
+package magma
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+)
+
+var _ cipher.BlockCipher = (*Magma)(nil)
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestMagmaVector(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewMagma(nil)
+	require.NoError(t, err)
+
+	require.NoError(t, m.SetKey(ctx, mustHex(t, "ffeeddccbbaa99887766554433221100f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")))
+
+	ciphertext, err := m.Encrypt(ctx, mustHex(t, "fedcba9876543210"))
+	require.NoError(t, err)
+	assert.Equal(t, "4ee901e5c2d8ca3d", hex.EncodeToString(ciphertext))
+
+	plaintext, err := m.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "fedcba9876543210", hex.EncodeToString(plaintext))
+}
+
+func TestMagmaRoundFunction(t *testing.T) {
+	ctx := context.Background()
+	r := &RoundFunction{SBox: SBoxTC26Z}
+
+	out, err := r.Transform(ctx, mustHex(t, "fedcba98"), mustHex(t, "87654321"))
+	require.NoError(t, err)
+	assert.Equal(t, "fdcbc20c", hex.EncodeToString(out))
+
+	out, err = r.Transform(ctx, mustHex(t, "87654321"), mustHex(t, "fdcbc20c"))
+	require.NoError(t, err)
+	assert.Equal(t, "7e791a4b", hex.EncodeToString(out))
+}
+
+func TestMagmaKeySchedule(t *testing.T) {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = byte(i / 4)
+	}
+
+	roundKeys, err := (&KeyScheduler{}).GenerateRoundKeys(context.Background(), key)
+	require.NoError(t, err)
+	require.Len(t, roundKeys, numRounds)
+
+	order := make([]byte, numRounds)
+	for i, k := range roundKeys {
+		order[i] = k[0]
+	}
+	assert.Equal(t, []byte{
+		0, 1, 2, 3, 4, 5, 6, 7,
+		0, 1, 2, 3, 4, 5, 6, 7,
+		0, 1, 2, 3, 4, 5, 6, 7,
+		7, 6, 5, 4, 3, 2, 1, 0,
+	}, order)
+}
+
+func TestParameterSets(t *testing.T) {
+	ctx := context.Background()
+	key := make([]byte, KeySize)
+	block := []byte("magma!!!")
+
+	z, err := NewMagma(&Options{SBox: SBoxTC26Z})
+	require.NoError(t, err)
+	a, err := NewMagma(&Options{SBox: SBoxCryptoProA})
+	require.NoError(t, err)
+	require.NoError(t, z.SetKey(ctx, key))
+	require.NoError(t, a.SetKey(ctx, key))
+
+	cz, err := z.Encrypt(ctx, block)
+	require.NoError(t, err)
+	ca, err := a.Encrypt(ctx, block)
+	require.NoError(t, err)
+	assert.NotEqual(t, cz, ca)
+
+	decrypted, err := a.Decrypt(ctx, ca)
+	require.NoError(t, err)
+	assert.Equal(t, block, decrypted)
+
+	var bad SBox
+	bad[2][5] = 0x10
+	_, err = NewMagma(&Options{SBox: bad})
+	require.Error(t, err)
+}
+
+// The expected block comes from libgcrypt's GOST 28147-89 with the
+// CryptoPro-A parameter set.
+func TestGOST28147ByteOrder(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewMagma(&Options{SBox: SBoxCryptoProA, GOST28147: true})
+	require.NoError(t, err)
+	require.NoError(t, m.SetKey(ctx, mustHex(t, "808386898c8f9295989b9ea1a4a7aaadb0b3b6b9bcbfc2c5c8cbced1d4d7dadd")))
+
+	ciphertext, err := m.Encrypt(ctx, mustHex(t, "a0a1a2a3a4a5a6a7"))
+	require.NoError(t, err)
+	assert.Equal(t, "c53d8e4665d50d62", hex.EncodeToString(ciphertext))
+
+	plaintext, err := m.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "a0a1a2a3a4a5a6a7", hex.EncodeToString(plaintext))
+}
+
+func TestInvalidSizes(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewMagma(nil)
+	require.NoError(t, err)
+
+	require.Error(t, m.SetKey(ctx, make([]byte, 16)))
+	require.NoError(t, m.SetKey(ctx, make([]byte, KeySize)))
+
+	_, err = m.Encrypt(ctx, make([]byte, 16))
+	require.Error(t, err)
+}
@@ -0,0 +1,293 @@
+package mars
+
+import (
+	"context"
+	"encoding/binary"
+	"math/bits"
+	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/legacy/sha1"
+)
+
+const (
+	BlockSize  = 16
+	MinKeySize = 16
+	MaxKeySize = 56
+	numSubkeys = 40
+	coreRounds = 16
+)
+
+// Constants from the S-box derivation: c1 and c2 are the binary expansions
+// of e and pi, c3 is the value the designers searched for.
+const (
+	c1 = 0xB7E15162
+	c2 = 0x243F6A88
+	c3 = 0x02917D59
+)
+
+// Fix-up patterns used to repair weak subkeys in the key schedule.
+var fixPatterns = [4]uint32{0xA4A8D57B, 0x5B5D193B, 0xC8A8309B, 0x73F9A978}
+
+var (
+	initOnce sync.Once
+	sbox     [512]uint32
+)
+
+// initSBox rebuilds the published S-box: entries come from SHA-1 over
+// (5i | c1 | c2 | c3), after which every entry that differs from another
+// one in the same half in fewer than three bytes is multiplied by 3.
+func initSBox() {
+	var input [16]byte
+	binary.LittleEndian.PutUint32(input[4:], c1)
+	binary.LittleEndian.PutUint32(input[8:], c2)
+	binary.LittleEndian.PutUint32(input[12:], c3)
+	for i := 0; 5*i < len(sbox); i++ {
+		binary.LittleEndian.PutUint32(input[:], uint32(5*i))
+		digest := sha1.Sum(input[:])
+		for k := 0; k < 5 && 5*i+k < len(sbox); k++ {
+			sbox[5*i+k] = binary.LittleEndian.Uint32(digest[4*k:])
+		}
+	}
+
+	for fixed := true; fixed; {
+		fixed = false
+		for half := 0; half < len(sbox); half += 256 {
+			for i := half; i < half+256; i++ {
+				for j := i + 1; j < half+256; j++ {
+					if differingBytes(sbox[i]^sbox[j]) < 3 {
+						sbox[i] *= 3
+						fixed = true
+					}
+				}
+			}
+		}
+	}
+}
+
+func differingBytes(x uint32) int {
+	n := 0
+	for ; x != 0; x >>= 8 {
+		if x&0xFF != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+type MARS struct {
+	subkeys [numSubkeys]uint32
+	keyed   bool
+}
+
+func NewMARS() *MARS {
+	initOnce.Do(initSBox)
+	return &MARS{}
+}
+
+func (m *MARS) BlockSize() int {
+	return BlockSize
+}
+
+func (m *MARS) SetKey(ctx context.Context, key []byte) error {
+	if len(key) < MinKeySize || len(key) > MaxKeySize || len(key)%4 != 0 {
+		return errors.ErrInvalidKeySize
+	}
+
+	n := len(key) / 4
+	var t [15]uint32
+	for i := 0; i < n; i++ {
+		t[i] = binary.LittleEndian.Uint32(key[4*i:])
+	}
+	t[n] = uint32(n)
+
+	k := &m.subkeys
+	for j := 0; j < 4; j++ {
+		for i := range t {
+			t[i] ^= bits.RotateLeft32(t[(i+8)%15]^t[(i+13)%15], 3) ^ uint32(4*i+j)
+		}
+		for range 4 {
+			for i := range t {
+				t[i] = bits.RotateLeft32(t[i]+sbox[t[(i+14)%15]&511], 9)
+			}
+		}
+		for i := 0; i < 10; i++ {
+			k[10*j+i] = t[(4*i)%15]
+		}
+	}
+
+	for i := 5; i <= 35; i += 2 {
+		w := k[i] | 3
+		p := bits.RotateLeft32(fixPatterns[k[i]&3], int(k[i-1]&31))
+		k[i] = w ^ (p & weakMask(w))
+	}
+
+	m.keyed = true
+	return nil
+}
+
+// weakMask marks the inner bits of every run of ten or more equal bits in w,
+// i.e. the bits the key schedule must flip to break up such runs.
+func weakMask(w uint32) uint32 {
+	var mask uint32
+	for lo := 0; lo < 32; {
+		hi := lo
+		for hi+1 < 32 && (w>>(hi+1))&1 == (w>>lo)&1 {
+			hi++
+		}
+		if hi-lo+1 >= 10 {
+			for b := max(lo+1, 2); b < hi; b++ {
+				mask |= 1 << b
+			}
+		}
+		lo = hi + 1
+	}
+	return mask
+}
+
+func (m *MARS) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := m.check(block); err != nil {
+		return nil, err
+	}
+
+	k := &m.subkeys
+	var d [4]uint32
+	for i := range d {
+		d[i] = binary.LittleEndian.Uint32(block[4*i:]) + k[i]
+	}
+
+	for i := 0; i < 8; i++ {
+		d[1] ^= sbox[byte(d[0])]
+		d[1] += sbox[256+int(byte(d[0]>>8))]
+		d[2] += sbox[byte(d[0]>>16)]
+		d[3] ^= sbox[256+int(byte(d[0]>>24))]
+		d[0] = bits.RotateLeft32(d[0], -24)
+		switch i {
+		case 0, 4:
+			d[0] += d[3]
+		case 1, 5:
+			d[0] += d[1]
+		}
+		d = [4]uint32{d[1], d[2], d[3], d[0]}
+	}
+
+	for i := 0; i < coreRounds; i++ {
+		l, mid, r := expand(d[0], k[2*i+4], k[2*i+5])
+		d[0] = bits.RotateLeft32(d[0], 13)
+		d[2] += mid
+		if i < coreRounds/2 {
+			d[1] += l
+			d[3] ^= r
+		} else {
+			d[3] += l
+			d[1] ^= r
+		}
+		d = [4]uint32{d[1], d[2], d[3], d[0]}
+	}
+
+	for i := 0; i < 8; i++ {
+		switch i {
+		case 2, 6:
+			d[0] -= d[3]
+		case 3, 7:
+			d[0] -= d[1]
+		}
+		d[1] ^= sbox[256+int(byte(d[0]))]
+		d[2] -= sbox[byte(d[0]>>24)]
+		d[3] -= sbox[256+int(byte(d[0]>>16))]
+		d[3] ^= sbox[byte(d[0]>>8)]
+		d[0] = bits.RotateLeft32(d[0], 24)
+		d = [4]uint32{d[1], d[2], d[3], d[0]}
+	}
+
+	out := make([]byte, BlockSize)
+	for i := range d {
+		binary.LittleEndian.PutUint32(out[4*i:], d[i]-k[36+i])
+	}
+	return out, nil
+}
+
+func (m *MARS) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := m.check(block); err != nil {
+		return nil, err
+	}
+
+	k := &m.subkeys
+	var d [4]uint32
+	for i := range d {
+		d[i] = binary.LittleEndian.Uint32(block[4*i:]) + k[36+i]
+	}
+
+	for i := 7; i >= 0; i-- {
+		d = [4]uint32{d[3], d[0], d[1], d[2]}
+		d[0] = bits.RotateLeft32(d[0], -24)
+		d[3] ^= sbox[byte(d[0]>>8)]
+		d[3] += sbox[256+int(byte(d[0]>>16))]
+		d[2] += sbox[byte(d[0]>>24)]
+		d[1] ^= sbox[256+int(byte(d[0]))]
+		switch i {
+		case 2, 6:
+			d[0] += d[3]
+		case 3, 7:
+			d[0] += d[1]
+		}
+	}
+
+	for i := coreRounds - 1; i >= 0; i-- {
+		d = [4]uint32{d[3], d[0], d[1], d[2]}
+		d[0] = bits.RotateLeft32(d[0], -13)
+		l, mid, r := expand(d[0], k[2*i+4], k[2*i+5])
+		d[2] -= mid
+		if i < coreRounds/2 {
+			d[1] -= l
+			d[3] ^= r
+		} else {
+			d[3] -= l
+			d[1] ^= r
+		}
+	}
+
+	for i := 7; i >= 0; i-- {
+		d = [4]uint32{d[3], d[0], d[1], d[2]}
+		switch i {
+		case 0, 4:
+			d[0] -= d[3]
+		case 1, 5:
+			d[0] -= d[1]
+		}
+		d[0] = bits.RotateLeft32(d[0], 24)
+		d[3] ^= sbox[256+int(byte(d[0]>>24))]
+		d[2] -= sbox[byte(d[0]>>16)]
+		d[1] -= sbox[256+int(byte(d[0]>>8))]
+		d[1] ^= sbox[byte(d[0])]
+	}
+
+	out := make([]byte, BlockSize)
+	for i := range d {
+		binary.LittleEndian.PutUint32(out[4*i:], d[i]-k[i])
+	}
+	return out, nil
+}
+
+// expand is the E-function of the cryptographic core.
+func expand(in, key1, key2 uint32) (l, m, r uint32) {
+	m = in + key1
+	r = bits.RotateLeft32(bits.RotateLeft32(in, 13)*key2, 5)
+	l = sbox[m&511]
+	m = bits.RotateLeft32(m, int(r&31))
+	l ^= r
+	r = bits.RotateLeft32(r, 5)
+	l ^= r
+	l = bits.RotateLeft32(l, int(r&31))
+	return l, m, r
+}
+
+func (m *MARS) check(block []byte) error {
+	if len(block) != BlockSize {
+		return errors.ErrInvalidBlockSize
+	}
+	if !m.keyed {
+		return errors.ErrInvalidKeySize
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package mars
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+var _ cipher.BlockCipher = (*MARS)(nil)
+
+func decode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestSBox(t *testing.T) {
+	NewMARS()
+
+	assert.Equal(t, uint32(0x09D0C479), sbox[0])
+	assert.Equal(t, uint32(0x28C8FFE0), sbox[1])
+	// Entry 17 is one of the nine repaired by the fix-up pass.
+	assert.Equal(t, uint32(0x0D72EE46), sbox[17])
+}
+
+func TestVectors(t *testing.T) {
+	ctx := context.Background()
+	vectors := []struct {
+		key, plaintext, ciphertext string
+	}{
+		{
+			"00000000000000000000000000000000",
+			"00000000000000000000000000000000",
+			"dcc07b8dfb0738d6e30a22dfcf27e886",
+		},
+		{
+			"00000000000000000000000000000000",
+			"dcc07b8dfb0738d6e30a22dfcf27e886",
+			"33caffbddc7f1dda0f9c15fa2f30e2ff",
+		},
+		{
+			"cb14a1776abbc1cdafe7243def2cea02",
+			"f94512a9b42d034ec4792204d708a69b",
+			"225da2cb64b73f79069f21a5e3cb8522",
+		},
+	}
+
+	for _, v := range vectors {
+		m := NewMARS()
+		require.NoError(t, m.SetKey(ctx, decode(t, v.key)))
+
+		ciphertext, err := m.Encrypt(ctx, decode(t, v.plaintext))
+		require.NoError(t, err)
+		assert.Equal(t, v.ciphertext, hex.EncodeToString(ciphertext))
+
+		plaintext, err := m.Decrypt(ctx, ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, v.plaintext, hex.EncodeToString(plaintext))
+	}
+}
+
+func TestRoundTripKeySizes(t *testing.T) {
+	ctx := context.Background()
+	for size := MinKeySize; size <= MaxKeySize; size += 4 {
+		key := make([]byte, size)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		block := make([]byte, BlockSize)
+		_, err = rand.Read(block)
+		require.NoError(t, err)
+
+		m := NewMARS()
+		require.NoError(t, m.SetKey(ctx, key))
+		ciphertext, err := m.Encrypt(ctx, block)
+		require.NoError(t, err)
+		assert.NotEqual(t, block, ciphertext)
+		plaintext, err := m.Decrypt(ctx, ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, block, plaintext, "key size %d", size)
+	}
+}
+
+func TestWeakMask(t *testing.T) {
+	assert.Equal(t, uint32(0), weakMask(0xAAAAAAAB))
+	// Ten low zero bits above the forced 11: bits 2..11, inner bits 3..10.
+	assert.Equal(t, uint32(0x7F8), weakMask(0x55555003))
+}
+
+func TestInvalid(t *testing.T) {
+	ctx := context.Background()
+	m := NewMARS()
+
+	_, err := m.Encrypt(ctx, make([]byte, BlockSize))
+	require.ErrorIs(t, err, errors.ErrInvalidKeySize)
+
+	require.ErrorIs(t, m.SetKey(ctx, make([]byte, 12)), errors.ErrInvalidKeySize)
+	require.ErrorIs(t, m.SetKey(ctx, make([]byte, 18)), errors.ErrInvalidKeySize)
+	require.ErrorIs(t, m.SetKey(ctx, make([]byte, 60)), errors.ErrInvalidKeySize)
+	require.NoError(t, m.SetKey(ctx, make([]byte, 32)))
+
+	_, err = m.Decrypt(ctx, make([]byte, 8))
+	require.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	ctx := context.Background()
+	m := NewMARS()
+	require.NoError(b, m.SetKey(ctx, make([]byte, 16)))
+	block := make([]byte, BlockSize)
+	b.SetBytes(BlockSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Encrypt(ctx, block)
+	}
+}
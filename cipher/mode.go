@@ -2,7 +2,12 @@ package cipher
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/binary"
 	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
 )
 
 type CipherMode interface {
@@ -15,30 +20,11 @@ type ECBMode struct{}
 func (m *ECBMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
 	blockSize := cipher.BlockSize()
 	result := make([]byte, len(data))
-	numBlocks := len(data) / blockSize
-
-	var wg sync.WaitGroup
-	errChan := make(chan error, numBlocks)
 
-	for i := 0; i < numBlocks; i++ {
-		wg.Add(1)
-		go func(idx int) {
-			defer wg.Done()
-			start := idx * blockSize
-			end := start + blockSize
-			encrypted, err := cipher.Encrypt(ctx, data[start:end])
-			if err != nil {
-				errChan <- err
-				return
-			}
-			copy(result[start:end], encrypted)
-		}(i)
-	}
-
-	wg.Wait()
-	close(errChan)
-
-	if err := <-errChan; err != nil {
+	err := parallelBlocks(ctx, data, blockSize, func(idx, start, end int) error {
+		return EncryptBlockTo(ctx, cipher, result[start:end], data[start:end])
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -48,30 +34,11 @@ func (m *ECBMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []by
 func (m *ECBMode) Decrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
 	blockSize := cipher.BlockSize()
 	result := make([]byte, len(data))
-	numBlocks := len(data) / blockSize
-
-	var wg sync.WaitGroup
-	errChan := make(chan error, numBlocks)
 
-	for i := 0; i < numBlocks; i++ {
-		wg.Add(1)
-		go func(idx int) {
-			defer wg.Done()
-			start := idx * blockSize
-			end := start + blockSize
-			decrypted, err := cipher.Decrypt(ctx, data[start:end])
-			if err != nil {
-				errChan <- err
-				return
-			}
-			copy(result[start:end], decrypted)
-		}(i)
-	}
-
-	wg.Wait()
-	close(errChan)
-
-	if err := <-errChan; err != nil {
+	err := parallelBlocks(ctx, data, blockSize, func(idx, start, end int) error {
+		return DecryptBlockTo(ctx, cipher, result[start:end], data[start:end])
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -86,13 +53,17 @@ func (m *CBCMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []by
 	prev := iv
 
 	for i := 0; i < len(data); i += blockSize {
-		block := xorBlocks(data[i:i+blockSize], prev)
-		encrypted, err := cipher.Encrypt(ctx, block)
-		if err != nil {
+		if err := checkCancelled(ctx, i/blockSize, blockSize); err != nil {
 			return nil, err
 		}
-		copy(result[i:i+blockSize], encrypted)
-		prev = encrypted
+		block := result[i : i+blockSize]
+		for j := range block {
+			block[j] = data[i+j] ^ prev[j]
+		}
+		if err := EncryptBlockTo(ctx, cipher, block, block); err != nil {
+			return nil, err
+		}
+		prev = block
 	}
 
 	return result, nil
@@ -101,36 +72,22 @@ func (m *CBCMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []by
 func (m *CBCMode) Decrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
 	blockSize := cipher.BlockSize()
 	result := make([]byte, len(data))
-	numBlocks := len(data) / blockSize
-
-	var wg sync.WaitGroup
-	errChan := make(chan error, numBlocks)
 
-	for i := 0; i < numBlocks; i++ {
-		wg.Add(1)
-		go func(idx int) {
-			defer wg.Done()
-			start := idx * blockSize
-			end := start + blockSize
-			decrypted, err := cipher.Decrypt(ctx, data[start:end])
-			if err != nil {
-				errChan <- err
-				return
-			}
-			var prev []byte
-			if idx == 0 {
-				prev = iv
-			} else {
-				prev = data[start-blockSize : start]
-			}
-			copy(result[start:end], xorBlocks(decrypted, prev))
-		}(i)
-	}
-
-	wg.Wait()
-	close(errChan)
-
-	if err := <-errChan; err != nil {
+	err := parallelBlocks(ctx, data, blockSize, func(idx, start, end int) error {
+		decrypted, err := cipher.Decrypt(ctx, data[start:end])
+		if err != nil {
+			return err
+		}
+		var prev []byte
+		if idx == 0 {
+			prev = iv
+		} else {
+			prev = data[start-blockSize : start]
+		}
+		copy(result[start:end], xorBlocks(decrypted, prev))
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -145,6 +102,9 @@ func (m *PCBCMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []b
 	prev := iv
 
 	for i := 0; i < len(data); i += blockSize {
+		if err := checkCancelled(ctx, i/blockSize, blockSize); err != nil {
+			return nil, err
+		}
 		plainBlock := data[i : i+blockSize]
 		block := xorBlocks(plainBlock, prev)
 		encrypted, err := cipher.Encrypt(ctx, block)
@@ -164,6 +124,9 @@ func (m *PCBCMode) Decrypt(ctx context.Context, cipher BlockCipher, data, iv []b
 	prev := iv
 
 	for i := 0; i < len(data); i += blockSize {
+		if err := checkCancelled(ctx, i/blockSize, blockSize); err != nil {
+			return nil, err
+		}
 		encrypted := data[i : i+blockSize]
 		decrypted, err := cipher.Decrypt(ctx, encrypted)
 		if err != nil {
@@ -177,20 +140,30 @@ func (m *PCBCMode) Decrypt(ctx context.Context, cipher BlockCipher, data, iv []b
 	return result, nil
 }
 
-type CFBMode struct{}
+type CFBMode struct {
+	SegmentSize int
+}
 
 func (m *CFBMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
+	if m.segmented(cipher) {
+		return m.segments(ctx, cipher, data, iv, true)
+	}
+
 	blockSize := cipher.BlockSize()
 	result := make([]byte, len(data))
 	prev := iv
 
 	for i := 0; i < len(data); i += blockSize {
+		if err := checkCancelled(ctx, i/blockSize, blockSize); err != nil {
+			return nil, err
+		}
 		encrypted, err := cipher.Encrypt(ctx, prev)
 		if err != nil {
 			return nil, err
 		}
-		cipherBlock := xorBlocks(data[i:i+blockSize], encrypted)
-		copy(result[i:i+blockSize], cipherBlock)
+		end := min(i+blockSize, len(data))
+		cipherBlock := xorBlocks(data[i:end], encrypted[:end-i])
+		copy(result[i:end], cipherBlock)
 		prev = cipherBlock
 	}
 
@@ -198,58 +171,143 @@ func (m *CFBMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []by
 }
 
 func (m *CFBMode) Decrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
+	if m.segmented(cipher) {
+		return m.segments(ctx, cipher, data, iv, false)
+	}
+
 	blockSize := cipher.BlockSize()
 	result := make([]byte, len(data))
-	numBlocks := len(data) / blockSize
 
-	var wg sync.WaitGroup
-	errChan := make(chan error, numBlocks)
+	err := parallelBlocks(ctx, data, blockSize, func(idx, start, end int) error {
+		var prev []byte
+		if idx == 0 {
+			prev = iv
+		} else {
+			prev = data[start-blockSize : start]
+		}
+		encrypted, err := cipher.Encrypt(ctx, prev)
+		if err != nil {
+			return err
+		}
+		copy(result[start:end], xorBlocks(data[start:end], encrypted))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	for i := 0; i < numBlocks; i++ {
-		wg.Add(1)
-		go func(idx int) {
-			defer wg.Done()
-			start := idx * blockSize
-			end := start + blockSize
-			var prev []byte
-			if idx == 0 {
-				prev = iv
-			} else {
-				prev = data[start-blockSize : start]
+	if tail := len(data) % blockSize; tail != 0 {
+		start := len(data) - tail
+		prev := iv
+		if start > 0 {
+			prev = data[start-blockSize : start]
+		}
+		encrypted, err := cipher.Encrypt(ctx, prev)
+		if err != nil {
+			return nil, err
+		}
+		copy(result[start:], xorBlocks(data[start:], encrypted[:tail]))
+	}
+
+	return result, nil
+}
+
+func (m *CFBMode) segmented(cipher BlockCipher) bool {
+	return m.SegmentSize != 0 && m.SegmentSize != 8*cipher.BlockSize()
+}
+
+func (m *CFBMode) segments(ctx context.Context, cipher BlockCipher, data, iv []byte, encrypting bool) ([]byte, error) {
+	blockSize := cipher.BlockSize()
+	segment := m.SegmentSize
+	if segment < 0 || segment > 8*blockSize || (segment != 1 && segment%8 != 0) {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unsupported CFB segment size %d: %w", segment)
+	}
+	if len(data)*8%segment != 0 {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	register := append([]byte{}, iv...)
+	result := make([]byte, len(data))
+
+	if segment == 1 {
+		for bit := 0; bit < 8*len(data); bit++ {
+			if err := checkCancelled(ctx, bit, blockSize); err != nil {
+				return nil, err
 			}
-			encrypted, err := cipher.Encrypt(ctx, prev)
+			encrypted, err := cipher.Encrypt(ctx, register)
 			if err != nil {
-				errChan <- err
-				return
+				return nil, err
 			}
-			copy(result[start:end], xorBlocks(data[start:end], encrypted))
-		}(i)
-	}
 
-	wg.Wait()
-	close(errChan)
+			shift := 7 - bit%8
+			in := data[bit/8] >> shift & 1
+			out := in ^ encrypted[0]>>7
+			result[bit/8] |= out << shift
 
-	if err := <-errChan; err != nil {
-		return nil, err
+			feedback := out
+			if !encrypting {
+				feedback = in
+			}
+			shiftRegisterBit(register, feedback)
+		}
+		return result, nil
 	}
 
+	n := segment / 8
+	for i := 0; i < len(data); i += n {
+		if err := checkCancelled(ctx, i/n, blockSize); err != nil {
+			return nil, err
+		}
+		encrypted, err := cipher.Encrypt(ctx, register)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < n; j++ {
+			result[i+j] = data[i+j] ^ encrypted[j]
+		}
+
+		feedback := result[i : i+n]
+		if !encrypting {
+			feedback = data[i : i+n]
+		}
+		copy(register, register[n:])
+		copy(register[blockSize-n:], feedback)
+	}
 	return result, nil
 }
 
+func shiftRegisterBit(register []byte, bit byte) {
+	for i := 0; i < len(register)-1; i++ {
+		register[i] = register[i]<<1 | register[i+1]>>7
+	}
+	register[len(register)-1] = register[len(register)-1]<<1 | bit
+}
+
 type OFBMode struct{}
 
 func (m *OFBMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
+	settings := SettingsFromContext(ctx)
+	if settings.Workers > 1 && len(data) > settings.ChunkSize {
+		return m.pipeline(ctx, cipher, data, iv, settings)
+	}
+
 	blockSize := cipher.BlockSize()
 	result := make([]byte, len(data))
-	keystream := iv
+	buf := getBuffer(blockSize)
+	defer putBuffer(buf)
+	keystream := *buf
+	copy(keystream, iv)
 
 	for i := 0; i < len(data); i += blockSize {
-		encrypted, err := cipher.Encrypt(ctx, keystream)
-		if err != nil {
+		if err := checkCancelled(ctx, i/blockSize, blockSize); err != nil {
+			return nil, err
+		}
+		if err := EncryptBlockTo(ctx, cipher, keystream, keystream); err != nil {
 			return nil, err
 		}
-		copy(result[i:i+blockSize], xorBlocks(data[i:i+blockSize], encrypted))
-		keystream = encrypted
+		for j, k := range keystream[:min(blockSize, len(data)-i)] {
+			result[i+j] = data[i+j] ^ k
+		}
 	}
 
 	return result, nil
@@ -259,43 +317,112 @@ func (m *OFBMode) Decrypt(ctx context.Context, cipher BlockCipher, data, iv []by
 	return m.Encrypt(ctx, cipher, data, iv)
 }
 
-type CTRMode struct{}
+type keystreamChunk struct {
+	start  int
+	stream []byte
+}
 
-func (m *CTRMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
+func (m *OFBMode) pipeline(ctx context.Context, cipher BlockCipher, data, iv []byte, settings Settings) ([]byte, error) {
 	blockSize := cipher.BlockSize()
+	chunkSize := max(settings.ChunkSize/blockSize, 1) * blockSize
 	result := make([]byte, len(data))
-	numBlocks := len(data) / blockSize
 
-	var wg sync.WaitGroup
-	errChan := make(chan error, numBlocks)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunks := make(chan keystreamChunk, settings.Workers)
+	var generateErr error
+	go func() {
+		defer close(chunks)
+		keystream := iv
+		for start := 0; start < len(data); start += chunkSize {
+			stream := make([]byte, min(chunkSize, len(data)-start))
+			for i := 0; i < len(stream); i += blockSize {
+				encrypted, err := cipher.Encrypt(ctx, keystream)
+				if err != nil {
+					generateErr = err
+					return
+				}
+				copy(stream[i:], encrypted)
+				keystream = encrypted
+			}
 
-	for i := 0; i < numBlocks; i++ {
+			select {
+			case chunks <- keystreamChunk{start: start, stream: stream}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < settings.Workers-1; w++ {
 		wg.Add(1)
-		go func(idx int) {
+		go func() {
 			defer wg.Done()
-			start := idx * blockSize
-			end := start + blockSize
-			counter := make([]byte, blockSize)
-			copy(counter, iv)
-			for j := 0; j < idx; j++ {
-				incrementCounter(counter)
-			}
-			encrypted, err := cipher.Encrypt(ctx, counter)
-			if err != nil {
-				errChan <- err
-				return
+			for chunk := range chunks {
+				for i, k := range chunk.stream {
+					result[chunk.start+i] = data[chunk.start+i] ^ k
+				}
 			}
-			copy(result[start:end], xorBlocks(data[start:end], encrypted))
-		}(i)
+		}()
 	}
-
 	wg.Wait()
-	close(errChan)
 
-	if err := <-errChan; err != nil {
+	if generateErr != nil {
+		return nil, generateErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type CTRMode struct{}
+
+func (m *CTRMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
+	blockSize := cipher.BlockSize()
+	result := make([]byte, len(data))
+
+	err := parallelChunks(ctx, len(data)/blockSize, blockSize, func(first, last int) error {
+		counterBuf, keystream := getBuffer(blockSize), getBuffer(blockSize)
+		defer putBuffer(counterBuf)
+		defer putBuffer(keystream)
+
+		counter, encrypted := *counterBuf, *keystream
+		copy(counter, iv)
+		addCounter(counter, uint64(first))
+		for idx := first; idx < last; idx++ {
+			if err := EncryptBlockTo(ctx, cipher, encrypted, counter); err != nil {
+				return &errors.BlockError{Index: idx, Err: err}
+			}
+			start := idx * blockSize
+			for i := 0; i < blockSize; i++ {
+				result[start+i] = data[start+i] ^ encrypted[i]
+			}
+			addCounter(counter, 1)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	// A trailing partial block uses a truncated keystream block.
+	if tail := len(data) % blockSize; tail != 0 {
+		start := len(data) - tail
+		counter := make([]byte, blockSize)
+		copy(counter, iv)
+		addCounter(counter, uint64(start/blockSize))
+		encrypted, err := cipher.Encrypt(ctx, counter)
+		if err != nil {
+			return nil, err
+		}
+		for i := range data[start:] {
+			result[start+i] = data[start+i] ^ encrypted[i]
+		}
+	}
+
 	return result, nil
 }
 
@@ -303,97 +430,178 @@ func (m *CTRMode) Decrypt(ctx context.Context, cipher BlockCipher, data, iv []by
 	return m.Encrypt(ctx, cipher, data, iv)
 }
 
-type RandomDeltaMode struct{}
+// RandomDeltaMode whitens every block with its own pseudo-random delta
+// before encryption. Each message starts with a fresh random initial value,
+// emitted as E(initial ^ iv) ahead of the ciphertext, so the output is one
+// block longer than the input. The first byte of the initial value records
+// DeltaSize and its last DeltaSize bytes seed the generator: the delta of
+// block i is SHA-256(seed || i || j) for its j-th 32-byte piece, with i and
+// j big-endian 64- and 32-bit integers, truncated to the block size. Any
+// delta can therefore be computed directly, which keeps both directions
+// parallel, and the seed travels inside the ciphertext, so decryption only
+// needs the key, IV and DeltaSize. A stream writes the header once and
+// continues the block index across its chunks.
+type RandomDeltaMode struct {
+	// DeltaSize is the seed length in bytes, between 1 and one less than
+	// the block size; zero means half a block.
+	DeltaSize int
+}
 
 func (m *RandomDeltaMode) Encrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
+	header, state, err := m.sealHeader(ctx, cipher, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := m.encryptBody(ctx, cipher, data, state)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, body...), nil
+}
+
+func (m *RandomDeltaMode) Decrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
 	blockSize := cipher.BlockSize()
-	numBlocks := len(data) / blockSize
-	deltas := make([][]byte, numBlocks)
-	deltas[0] = iv
+	if len(data) < blockSize {
+		return nil, errors.ErrInvalidDataLength
+	}
 
-	for i := 1; i < numBlocks; i++ {
-		deltas[i] = make([]byte, blockSize)
+	state, err := m.openHeader(ctx, cipher, data[:blockSize], iv)
+	if err != nil {
+		return nil, err
 	}
+	return m.decryptBody(ctx, cipher, data[blockSize:], state)
+}
 
-	result := make([]byte, len(data))
-	for i := 0; i < numBlocks; i++ {
-		block := xorBlocks(data[i*blockSize:(i+1)*blockSize], deltas[i])
-		encrypted, err := cipher.Encrypt(ctx, block)
-		if err != nil {
-			return nil, err
-		}
-		copy(result[i*blockSize:(i+1)*blockSize], encrypted)
-		if i+1 < numBlocks {
-			deltas[i+1] = encrypted
-		}
+// sealHeader draws the initial value of a message and returns its header
+// block together with the chaining state of the first data block: the
+// initial value followed by a big-endian 64-bit block index.
+func (m *RandomDeltaMode) sealHeader(ctx context.Context, cipher BlockCipher, iv []byte) ([]byte, []byte, error) {
+	blockSize := cipher.BlockSize()
+	deltaSize, err := m.deltaSize(blockSize)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var wg sync.WaitGroup
-	errChan := make(chan error, numBlocks)
+	initial := make([]byte, blockSize)
+	if _, err := rand.Read(initial); err != nil {
+		return nil, nil, errors.Annotate(err, "generating delta: %w")
+	}
+	initial[0] = byte(deltaSize)
 
-	for i := 0; i < numBlocks; i++ {
-		wg.Add(1)
-		go func(idx int) {
-			defer wg.Done()
-			start := idx * blockSize
-			end := start + blockSize
-			block := xorBlocks(data[start:end], deltas[idx])
-			encrypted, err := cipher.Encrypt(ctx, block)
-			if err != nil {
-				errChan <- err
-				return
-			}
-			copy(result[start:end], encrypted)
-		}(i)
+	header, err := cipher.Encrypt(ctx, xorIV(initial, iv))
+	if err != nil {
+		return nil, nil, err
 	}
+	return header, append(initial, make([]byte, 8)...), nil
+}
 
-	wg.Wait()
-	close(errChan)
+func (m *RandomDeltaMode) openHeader(ctx context.Context, cipher BlockCipher, header, iv []byte) ([]byte, error) {
+	blockSize := cipher.BlockSize()
+	deltaSize, err := m.deltaSize(blockSize)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := <-errChan; err != nil {
+	decrypted, err := cipher.Decrypt(ctx, header)
+	if err != nil {
 		return nil, err
 	}
+	initial := xorIV(decrypted, iv)
+	if int(initial[0]) != deltaSize {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "delta parameters do not match: %w")
+	}
+	return append(initial, make([]byte, 8)...), nil
+}
+
+func (m *RandomDeltaMode) encryptBody(ctx context.Context, cipher BlockCipher, data, state []byte) ([]byte, error) {
+	blockSize := cipher.BlockSize()
+	if len(data)%blockSize != 0 {
+		return nil, errors.ErrInvalidDataLength
+	}
 
+	result := make([]byte, len(data))
+	err := m.blocks(ctx, state, len(data)/blockSize, blockSize, func(idx int, delta []byte) error {
+		start := idx * blockSize
+		encrypted, err := cipher.Encrypt(ctx, xorBlocks(data[start:start+blockSize], delta))
+		if err != nil {
+			return err
+		}
+		copy(result[start:], encrypted)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
-func (m *RandomDeltaMode) Decrypt(ctx context.Context, cipher BlockCipher, data, iv []byte) ([]byte, error) {
+func (m *RandomDeltaMode) decryptBody(ctx context.Context, cipher BlockCipher, data, state []byte) ([]byte, error) {
 	blockSize := cipher.BlockSize()
-	result := make([]byte, len(data))
-	numBlocks := len(data) / blockSize
+	if len(data)%blockSize != 0 {
+		return nil, errors.ErrInvalidDataLength
+	}
 
-	var wg sync.WaitGroup
-	errChan := make(chan error, numBlocks)
+	result := make([]byte, len(data))
+	err := m.blocks(ctx, state, len(data)/blockSize, blockSize, func(idx int, delta []byte) error {
+		start := idx * blockSize
+		decrypted, err := cipher.Decrypt(ctx, data[start:start+blockSize])
+		if err != nil {
+			return err
+		}
+		copy(result[start:], xorBlocks(decrypted, delta))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	for i := 0; i < numBlocks; i++ {
-		wg.Add(1)
-		go func(idx int) {
-			defer wg.Done()
-			start := idx * blockSize
-			end := start + blockSize
-			decrypted, err := cipher.Decrypt(ctx, data[start:end])
-			if err != nil {
-				errChan <- err
-				return
-			}
-			var delta []byte
-			if idx == 0 {
-				delta = iv
-			} else {
-				delta = data[start-blockSize : start]
-			}
-			copy(result[start:end], xorBlocks(decrypted, delta))
-		}(i)
+func (m *RandomDeltaMode) deltaSize(blockSize int) (int, error) {
+	if m.DeltaSize == 0 {
+		return blockSize / 2, nil
 	}
+	if m.DeltaSize < 0 || m.DeltaSize >= blockSize {
+		return 0, errors.Annotate(errors.ErrInvalidParameters, "unsupported delta size %d: %w", m.DeltaSize)
+	}
+	return m.DeltaSize, nil
+}
 
-	wg.Wait()
-	close(errChan)
+// blocks calls fn with the delta of every block of a body that starts at
+// the block index recorded in state.
+func (m *RandomDeltaMode) blocks(ctx context.Context, state []byte, numBlocks, blockSize int, fn func(idx int, delta []byte) error) error {
+	seed := state[blockSize-int(state[0]) : blockSize]
+	offset := binary.BigEndian.Uint64(state[blockSize:])
+
+	return parallelChunks(ctx, numBlocks, blockSize, func(first, last int) error {
+		delta := make([]byte, blockSize)
+		for idx := first; idx < last; idx++ {
+			deltaAt(delta, seed, offset+uint64(idx))
+			if err := fn(idx, delta); err != nil {
+				return &errors.BlockError{Index: idx, Err: err}
+			}
+		}
+		return nil
+	})
+}
 
-	if err := <-errChan; err != nil {
-		return nil, err
+// deltaAt fills delta with the generator output for block idx.
+func deltaAt(delta, seed []byte, idx uint64) {
+	input := append(append([]byte{}, seed...), make([]byte, 12)...)
+	binary.BigEndian.PutUint64(input[len(seed):], idx)
+	for j := 0; j*sha256.Size < len(delta); j++ {
+		binary.BigEndian.PutUint32(input[len(seed)+8:], uint32(j))
+		sum := sha256.Sum256(input)
+		copy(delta[j*sha256.Size:], sum[:])
 	}
+}
 
-	return result, nil
+func xorIV(block, iv []byte) []byte {
+	if iv == nil {
+		return append([]byte{}, block...)
+	}
+	return xorBlocks(block, iv)
 }
 
 func xorBlocks(a, b []byte) []byte {
@@ -404,11 +612,73 @@ func xorBlocks(a, b []byte) []byte {
 	return result
 }
 
-func incrementCounter(counter []byte) {
-	for i := len(counter) - 1; i >= 0; i-- {
-		counter[i]++
-		if counter[i] != 0 {
-			break
-		}
+func addCounter(counter []byte, n uint64) {
+	for i := len(counter) - 1; i >= 0 && n > 0; i-- {
+		sum := uint64(counter[i]) + n&0xFF
+		counter[i] = byte(sum)
+		n = n>>8 + sum>>8
+	}
+}
+
+type chainingMode interface {
+	nextIV(blockSize int, iv, input, output []byte, encrypting bool) []byte
+}
+
+// headerMode is a chainingMode whose messages start with a header block.
+// A stream writes or reads the header once and passes every chunk through
+// encryptBody or decryptBody, starting from the state the header yields
+// and chaining it with nextIV.
+type headerMode interface {
+	chainingMode
+	sealHeader(ctx context.Context, cipher BlockCipher, iv []byte) (header, state []byte, err error)
+	openHeader(ctx context.Context, cipher BlockCipher, header, iv []byte) ([]byte, error)
+	encryptBody(ctx context.Context, cipher BlockCipher, data, state []byte) ([]byte, error)
+	decryptBody(ctx context.Context, cipher BlockCipher, data, state []byte) ([]byte, error)
+}
+
+func (m *ECBMode) nextIV(blockSize int, iv, input, output []byte, encrypting bool) []byte {
+	return iv
+}
+
+func (m *CBCMode) nextIV(blockSize int, iv, input, output []byte, encrypting bool) []byte {
+	return lastCiphertextBlock(blockSize, input, output, encrypting)
+}
+
+func (m *PCBCMode) nextIV(blockSize int, iv, input, output []byte, encrypting bool) []byte {
+	return xorBlocks(input[len(input)-blockSize:], output[len(output)-blockSize:])
+}
+
+func (m *CFBMode) nextIV(blockSize int, iv, input, output []byte, encrypting bool) []byte {
+	ciphertext := input
+	if encrypting {
+		ciphertext = output
+	}
+	register := append(append([]byte{}, iv...), ciphertext...)
+	return register[len(register)-blockSize:]
+}
+
+func (m *OFBMode) nextIV(blockSize int, iv, input, output []byte, encrypting bool) []byte {
+	return xorBlocks(input[len(input)-blockSize:], output[len(output)-blockSize:])
+}
+
+func (m *CTRMode) nextIV(blockSize int, iv, input, output []byte, encrypting bool) []byte {
+	counter := make([]byte, blockSize)
+	copy(counter, iv)
+	addCounter(counter, uint64(len(input)/blockSize))
+	return counter
+}
+
+func (m *RandomDeltaMode) nextIV(blockSize int, state, input, output []byte, encrypting bool) []byte {
+	next := append([]byte{}, state...)
+	index := binary.BigEndian.Uint64(next[blockSize:])
+	binary.BigEndian.PutUint64(next[blockSize:], index+uint64(len(input)/blockSize))
+	return next
+}
+
+func lastCiphertextBlock(blockSize int, input, output []byte, encrypting bool) []byte {
+	ciphertext := input
+	if encrypting {
+		ciphertext = output
 	}
+	return append([]byte{}, ciphertext[len(ciphertext)-blockSize:]...)
 }
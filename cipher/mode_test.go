@@ -0,0 +1,205 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCFBSegmentVectors(t *testing.T) {
+	ctx := context.Background()
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	iv, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	vectors := []struct {
+		segment               int
+		plaintext, ciphertext string
+	}{
+		{1, "6bc1", "68b3"},
+		{8, "6bc1bee22e409f96e93d7e117393172aae2d", "3b79424c9c0dd436bace9e0ed4586a4f32b9"},
+		{128, "6bc1bee22e409f96e93d7e117393172a", "3b3fd92eb72dad20333449f8e83cfb4a"},
+	}
+
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	require.NoError(t, block.SetKey(ctx, key))
+
+	for _, v := range vectors {
+		plaintext, _ := hex.DecodeString(v.plaintext)
+		mode := &cipher.CFBMode{SegmentSize: v.segment}
+
+		encrypted, err := mode.Encrypt(ctx, block, plaintext, iv)
+		require.NoError(t, err)
+		assert.Equal(t, v.ciphertext, hex.EncodeToString(encrypted))
+
+		decrypted, err := mode.Decrypt(ctx, block, encrypted, iv)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
+
+func TestCFBSegmentStreaming(t *testing.T) {
+	ctx := context.Background()
+	plaintext := []byte("byte-wise CFB across stream chunk boundaries")
+
+	for _, segment := range []int{1, 8, 32} {
+		cc, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.CFBMode{SegmentSize: segment}, cipher.PKCS7, cipher.WithIV(make([]byte, 8)), cipher.WithStreamChunkSize(16))
+		require.NoError(t, err)
+
+		encChan, errChan := cc.EncryptBytes(ctx, plaintext)
+		require.NoError(t, <-errChan)
+		want := <-encChan
+
+		var streamed bytes.Buffer
+		require.NoError(t, cc.EncryptStream(ctx, bytes.NewReader(plaintext), &streamed))
+		assert.Equal(t, want, streamed.Bytes())
+	}
+}
+
+func TestCFBSegmentValidation(t *testing.T) {
+	ctx := context.Background()
+	block := des.NewDES()
+	require.NoError(t, block.SetKey(ctx, []byte("8bytekey")))
+
+	_, err := (&cipher.CFBMode{SegmentSize: 12}).Encrypt(ctx, block, make([]byte, 3), make([]byte, 8))
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = (&cipher.CFBMode{SegmentSize: 32}).Encrypt(ctx, block, make([]byte, 3), make([]byte, 8))
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
+
+func TestRandomDeltaDiffersFromCBC(t *testing.T) {
+	ctx := context.Background()
+	block := des.NewDES()
+	require.NoError(t, block.SetKey(ctx, []byte("8bytekey")))
+	iv := make([]byte, 8)
+	plaintext := bytes.Repeat([]byte("samesame"), 4)
+
+	cbc, err := (&cipher.CBCMode{}).Encrypt(ctx, block, plaintext, iv)
+	require.NoError(t, err)
+
+	mode := &cipher.RandomDeltaMode{}
+	first, err := mode.Encrypt(ctx, block, plaintext, iv)
+	require.NoError(t, err)
+	second, err := mode.Encrypt(ctx, block, plaintext, iv)
+	require.NoError(t, err)
+
+	assert.Len(t, first, len(plaintext)+8)
+	assert.NotEqual(t, cbc, first[8:])
+	assert.NotEqual(t, first, second)
+	for i := 8; i < len(first)-8; i += 8 {
+		assert.NotEqual(t, first[i:i+8], first[i+8:i+16])
+	}
+
+	decrypted, err := mode.Decrypt(ctx, block, first, iv)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	decrypted, err = mode.Decrypt(ctx, block, second, iv)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestRandomDeltaLayout(t *testing.T) {
+	ctx := context.Background()
+	block, err := rijndael.NewRijndael(32, 16, 0x1B)
+	require.NoError(t, err)
+	require.NoError(t, block.SetKey(ctx, bytes.Repeat([]byte{7}, 16)))
+	iv := bytes.Repeat([]byte{0x5A}, 32)
+	plaintext := bytes.Repeat([]byte{0x42}, 5*32)
+
+	mode := &cipher.RandomDeltaMode{DeltaSize: 12}
+	encrypted, err := mode.Encrypt(ctx, block, plaintext, iv)
+	require.NoError(t, err)
+	require.Len(t, encrypted, len(plaintext)+32)
+
+	// Rebuild every block from the documented layout alone.
+	header, err := block.Decrypt(ctx, encrypted[:32])
+	require.NoError(t, err)
+	initial := make([]byte, 32)
+	for i := range initial {
+		initial[i] = header[i] ^ iv[i]
+	}
+	assert.Equal(t, byte(12), initial[0])
+	seed := initial[32-12:]
+
+	for idx := 0; idx < 5; idx++ {
+		input := binary.BigEndian.AppendUint64(append([]byte{}, seed...), uint64(idx))
+		delta := sha256.Sum256(binary.BigEndian.AppendUint32(input, 0))
+
+		whitened := make([]byte, 32)
+		for i := range whitened {
+			whitened[i] = plaintext[idx*32+i] ^ delta[i]
+		}
+		expected, err := block.Encrypt(ctx, whitened)
+		require.NoError(t, err)
+		assert.Equal(t, expected, encrypted[32+idx*32:64+idx*32], "block %d", idx)
+	}
+}
+
+func TestRandomDeltaParameters(t *testing.T) {
+	ctx := context.Background()
+	block := des.NewDES()
+	require.NoError(t, block.SetKey(ctx, []byte("8bytekey")))
+	iv := make([]byte, 8)
+	plaintext := bytes.Repeat([]byte{0x42}, 24)
+
+	for _, size := range []int{1, 4, 7} {
+		encrypted, err := (&cipher.RandomDeltaMode{DeltaSize: size}).Encrypt(ctx, block, plaintext, iv)
+		require.NoError(t, err)
+
+		decrypted, err := (&cipher.RandomDeltaMode{DeltaSize: size}).Decrypt(ctx, block, encrypted, iv)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+
+		_, err = (&cipher.RandomDeltaMode{DeltaSize: size%7 + 1}).Decrypt(ctx, block, encrypted, iv)
+		assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+	}
+
+	_, err := (&cipher.RandomDeltaMode{DeltaSize: 8}).Encrypt(ctx, block, plaintext, iv)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = (&cipher.RandomDeltaMode{}).Decrypt(ctx, block, make([]byte, 4), iv)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+
+}
+
+func TestRandomDeltaStreamContinuesBlockIndex(t *testing.T) {
+	ctx := context.Background()
+	iv := make([]byte, 8)
+	plaintext := bytes.Repeat([]byte("random delta streams "), 40)
+
+	cc, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.RandomDeltaMode{}, cipher.PKCS7, cipher.WithIV(iv), cipher.WithStreamChunkSize(64))
+	require.NoError(t, err)
+
+	// A stream of several chunks carries one header block and decrypts as
+	// a single message.
+	var streamed bytes.Buffer
+	require.NoError(t, cc.EncryptStream(ctx, bytes.NewReader(plaintext), &streamed))
+	padded := (len(plaintext)/8 + 1) * 8
+	assert.Equal(t, 8+padded, streamed.Len())
+
+	decrypted, err := cc.Decrypt(ctx, streamed.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// Had every chunk restarted at block 0, the first blocks of two chunks
+	// would share a delta and could be swapped cleanly.
+	swapped := append([]byte{}, streamed.Bytes()...)
+	copy(swapped[8:16], streamed.Bytes()[8+64:8+72])
+	copy(swapped[8+64:8+72], streamed.Bytes()[8:16])
+	tampered, err := cc.Decrypt(ctx, swapped)
+	require.NoError(t, err)
+	assert.NotEqual(t, plaintext[64:72], tampered[:8])
+	assert.NotEqual(t, plaintext[:8], tampered[64:72])
+}
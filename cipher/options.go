@@ -0,0 +1,120 @@
+package cipher
+
+import (
+	"github.com/masterkusok/crypto/errors"
+)
+
+// Option configures a CipherContext. Options are validated together by
+// NewCipherContext, which rejects values and combinations that the chosen
+// cipher and mode cannot honour instead of ignoring them.
+type Option func(*contextOptions)
+
+type contextOptions struct {
+	iv              []byte
+	randomIV        bool
+	counter         uint64
+	counterSet      bool
+	segmentSize     int
+	segmentSizeSet  bool
+	workers         int
+	chunkSize       int
+	streamChunkSize int
+	lenientPadding  bool
+}
+
+// WithIV sets the default IV; a nil IV leaves it unset.
+func WithIV(iv []byte) Option {
+	return func(o *contextOptions) {
+		if iv != nil {
+			o.iv = append([]byte{}, iv...)
+		}
+	}
+}
+
+// WithRandomIV draws a fresh IV from crypto/rand for every message. Encrypt
+// and the streaming encryptors prepend it to the ciphertext, and the
+// matching decryptors read it back from there.
+func WithRandomIV() Option {
+	return func(o *contextOptions) {
+		o.randomIV = true
+	}
+}
+
+// WithCounter starts CTR mode at the given block counter, which is added to
+// every IV the context encrypts with, including explicit ones passed to the
+// WithIV methods (or to an all-zero block when there is none).
+func WithCounter(counter uint64) Option {
+	return func(o *contextOptions) {
+		o.counter = counter
+		o.counterSet = true
+	}
+}
+
+// WithSegmentSize sets the CFB segment size in bits.
+func WithSegmentSize(bits int) Option {
+	return func(o *contextOptions) {
+		o.segmentSize = bits
+		o.segmentSizeSet = true
+	}
+}
+
+// WithParallelism overrides the calibrated number of workers used by the
+// parallel modes.
+func WithParallelism(workers int) Option {
+	return func(o *contextOptions) {
+		o.workers = workers
+	}
+}
+
+// WithChunkSize overrides the calibrated number of bytes handed to a worker
+// at a time.
+func WithChunkSize(size int) Option {
+	return func(o *contextOptions) {
+		o.chunkSize = size
+	}
+}
+
+// WithStreamChunkSize sets how much input the streaming APIs read per step.
+func WithStreamChunkSize(size int) Option {
+	return func(o *contextOptions) {
+		o.streamChunkSize = size
+	}
+}
+
+// WithLenientPadding makes decryption accept PKCS7 padding that does not
+// verify, as UnpadLenient does. Padding is checked strictly by default.
+func WithLenientPadding() Option {
+	return func(o *contextOptions) {
+		o.lenientPadding = true
+	}
+}
+
+func (o *contextOptions) validate(cipher BlockCipher, mode CipherMode) error {
+	blockSize := cipher.BlockSize()
+
+	if o.iv != nil && o.randomIV {
+		return errors.Annotate(errors.ErrInvalidParameters, "WithIV and WithRandomIV are mutually exclusive: %w")
+	}
+	if o.iv != nil && len(o.iv) != blockSize {
+		return errors.ErrInvalidIVSize
+	}
+	if _, ok := mode.(*CTRMode); o.counterSet && !ok {
+		return errors.Annotate(errors.ErrInvalidParameters, "WithCounter requires CTR mode: %w")
+	}
+	if o.segmentSizeSet {
+		cfb, ok := mode.(*CFBMode)
+		if !ok {
+			return errors.Annotate(errors.ErrInvalidParameters, "WithSegmentSize requires CFB mode: %w")
+		}
+		if cfb == nil {
+			return errors.Annotate(errors.ErrInvalidParameters, "nil CFB mode: %w")
+		}
+		if s := o.segmentSize; s <= 0 || s > 8*blockSize || (s != 1 && s%8 != 0) {
+			return errors.Annotate(errors.ErrInvalidParameters, "unsupported CFB segment size %d: %w", s)
+		}
+	}
+	if o.workers < 0 || o.chunkSize < 0 || o.streamChunkSize < 0 {
+		return errors.Annotate(errors.ErrInvalidParameters, "parallelism and chunk sizes must not be negative: %w")
+	}
+	return nil
+}
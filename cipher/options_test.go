@@ -0,0 +1,156 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionValidation(t *testing.T) {
+	key := []byte("8bytekey")
+	tests := []struct {
+		name string
+		mode cipher.CipherMode
+		opts []cipher.Option
+		want error
+	}{
+		{"short IV", &cipher.CBCMode{}, []cipher.Option{cipher.WithIV(make([]byte, 4))}, errors.ErrInvalidIVSize},
+		{"IV and random IV", &cipher.CBCMode{}, []cipher.Option{cipher.WithIV(make([]byte, 8)), cipher.WithRandomIV()}, errors.ErrInvalidParameters},
+		{"counter outside CTR", &cipher.CBCMode{}, []cipher.Option{cipher.WithCounter(5)}, errors.ErrInvalidParameters},
+		{"segment outside CFB", &cipher.OFBMode{}, []cipher.Option{cipher.WithSegmentSize(8)}, errors.ErrInvalidParameters},
+		{"bad segment", &cipher.CFBMode{}, []cipher.Option{cipher.WithSegmentSize(12)}, errors.ErrInvalidParameters},
+		{"nil CFB mode", (*cipher.CFBMode)(nil), []cipher.Option{cipher.WithSegmentSize(8)}, errors.ErrInvalidParameters},
+		{"negative workers", &cipher.CTRMode{}, []cipher.Option{cipher.WithParallelism(-1)}, errors.ErrInvalidParameters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cipher.NewCipherContext(des.NewDES(), key, tt.mode, cipher.PKCS7, tt.opts...)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestWithRandomIV(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("8bytekey")
+	plaintext := []byte("random IV")
+
+	a, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.CBCMode{}, cipher.PKCS7, cipher.WithRandomIV())
+	require.NoError(t, err)
+	b, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.CBCMode{}, cipher.PKCS7, cipher.WithRandomIV())
+	require.NoError(t, err)
+	assert.Nil(t, a.IV())
+
+	first, err := a.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	second, err := a.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	require.Len(t, first, 8+16)
+	assert.NotEqual(t, first[:8], second[:8], "every message must get its own IV")
+	assert.NotEqual(t, first[8:], second[8:])
+
+	for _, ciphertext := range [][]byte{first, second} {
+		got, err := b.Decrypt(ctx, ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got)
+
+		got, err = b.DecryptWithIV(ctx, ciphertext[8:], ciphertext[:8])
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got)
+	}
+
+	_, err = b.Decrypt(ctx, first[:7])
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
+
+func TestWithRandomIVStreams(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("8bytekey")
+	plaintext := bytes.Repeat([]byte("stream "), 100)
+
+	for _, mode := range []cipher.CipherMode{&cipher.CBCMode{}, &cipher.CTRMode{}} {
+		c, err := cipher.NewCipherContext(des.NewDES(), key, mode, cipher.PKCS7, cipher.WithRandomIV(), cipher.WithStreamChunkSize(64))
+		require.NoError(t, err)
+
+		var streamed bytes.Buffer
+		require.NoError(t, c.EncryptStream(ctx, bytes.NewReader(plaintext), &streamed))
+		got, err := c.Decrypt(ctx, streamed.Bytes())
+		require.NoError(t, err, "%T", mode)
+		assert.Equal(t, plaintext, got, "%T", mode)
+
+		var written bytes.Buffer
+		w, err := c.NewEncryptingWriter(ctx, &written)
+		require.NoError(t, err)
+		_, err = w.Write(plaintext)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.NotEqual(t, streamed.Bytes()[:8], written.Bytes()[:8], "%T", mode)
+
+		r, err := c.NewDecryptingReader(ctx, bytes.NewReader(written.Bytes()))
+		require.NoError(t, err)
+		got, err = io.ReadAll(r)
+		require.NoError(t, err, "%T", mode)
+		assert.Equal(t, plaintext, got, "%T", mode)
+
+		var decrypted bytes.Buffer
+		require.NoError(t, c.DecryptStream(ctx, bytes.NewReader(written.Bytes()), &decrypted))
+		assert.Equal(t, plaintext, decrypted.Bytes(), "%T", mode)
+	}
+}
+
+func TestWithCounter(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("8bytekey")
+	iv := bytes.Repeat([]byte{0xFF}, 8)
+	plaintext := bytes.Repeat([]byte{0x5A}, 8*4)
+
+	whole, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.CTRMode{}, cipher.Zeros, cipher.WithIV(iv))
+	require.NoError(t, err)
+	expected, err := whole.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+
+	// Starting two blocks in must reproduce the tail of the full keystream,
+	// including the carry out of the all-ones IV.
+	skipped, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.CTRMode{}, cipher.Zeros, cipher.WithIV(iv), cipher.WithCounter(2))
+	require.NoError(t, err)
+	tail, err := skipped.Encrypt(ctx, plaintext[16:])
+	require.NoError(t, err)
+	assert.Equal(t, expected[16:], tail)
+
+	// Explicit IVs get the same offset.
+	tail, err = skipped.EncryptWithIV(ctx, plaintext[16:], iv)
+	require.NoError(t, err)
+	assert.Equal(t, expected[16:], tail)
+
+	dst := make([]byte, len(tail))
+	require.NoError(t, skipped.XORKeyStreamWithIV(ctx, dst, tail, iv))
+	assert.Equal(t, plaintext[16:], dst)
+}
+
+func TestWithSegmentSize(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("8bytekey")
+	iv := make([]byte, 8)
+	plaintext := []byte("segments")
+
+	explicit, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.CFBMode{SegmentSize: 8}, cipher.Zeros, cipher.WithIV(iv))
+	require.NoError(t, err)
+	mode := &cipher.CFBMode{}
+	viaOption, err := cipher.NewCipherContext(des.NewDES(), key, mode, cipher.Zeros, cipher.WithIV(iv), cipher.WithSegmentSize(8))
+	require.NoError(t, err)
+
+	want, err := explicit.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	got, err := viaOption.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Zero(t, mode.SegmentSize, "the caller's mode must not be modified")
+}
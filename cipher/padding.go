@@ -1,6 +1,10 @@
 package cipher
 
-import "github.com/masterkusok/crypto/errors"
+import (
+	"crypto/subtle"
+
+	"github.com/masterkusok/crypto/errors"
+)
 
 type PaddingScheme int
 
@@ -9,12 +13,29 @@ const (
 	ANSIX923
 	PKCS7
 	ISO10126
+	// ISO7816 is ISO/IEC 7816-4 padding: a 0x80 byte followed by zeros, as
+	// used by smart-card protocols.
+	ISO7816
+	// NoPadding leaves the data untouched, so the block modes require
+	// block-aligned input. The stream-like modes CFB, OFB and CTR are never
+	// padded and accept any length with every scheme.
+	NoPadding
+	// TBC is trailing bit complement padding: the block is filled with the
+	// complement of the last data bit, i.e. 0xFF bytes after data ending in
+	// a zero bit (or no data) and 0x00 bytes otherwise.
+	TBC
 )
 
 func Pad(data []byte, blockSize int, scheme PaddingScheme) ([]byte, error) {
 	if blockSize <= 0 {
 		return nil, errors.ErrInvalidBlockSize
 	}
+	if scheme == NoPadding {
+		if len(data)%blockSize != 0 {
+			return nil, errors.ErrInvalidDataLength
+		}
+		return append([]byte{}, data...), nil
+	}
 
 	padLen := blockSize - (len(data) % blockSize)
 	if padLen == 0 {
@@ -35,6 +56,14 @@ func Pad(data []byte, blockSize int, scheme PaddingScheme) ([]byte, error) {
 		}
 	case ISO10126:
 		padded[len(padded)-1] = byte(padLen)
+	case ISO7816:
+		padded[len(data)] = 0x80
+	case TBC:
+		if len(data) == 0 || data[len(data)-1]&1 == 0 {
+			for i := len(data); i < len(padded); i++ {
+				padded[i] = 0xFF
+			}
+		}
 	default:
 		return nil, errors.ErrInvalidPaddingScheme
 	}
@@ -42,9 +71,35 @@ func Pad(data []byte, blockSize int, scheme PaddingScheme) ([]byte, error) {
 	return padded, nil
 }
 
+// Unpad strictly removes the padding added by Pad. Malformed padding is
+// always reported as ErrInvalidPadding, and the PKCS7 and ANSI X.923 checks
+// run in time that depends only on len(data), so that a service built on it
+// does not become a padding oracle through its errors or timing.
 func Unpad(data []byte, scheme PaddingScheme) ([]byte, error) {
+	return unpad(data, 0, scheme, false)
+}
+
+// UnpadLenient is the historical behaviour: PKCS7 data whose padding does
+// not verify is returned unchanged instead of being rejected. It exists for
+// compatibility with data produced by peers that pad inconsistently and
+// must not be used where decryption failures are observable by an attacker.
+func UnpadLenient(data []byte, scheme PaddingScheme) ([]byte, error) {
+	return unpad(data, 0, scheme, true)
+}
+
+// unpad additionally bounds the padding length by blockSize when it is
+// known.
+func unpad(data []byte, blockSize int, scheme PaddingScheme, lenient bool) ([]byte, error) {
+	if scheme == NoPadding {
+		return data, nil
+	}
 	if len(data) == 0 {
-		return nil, errors.ErrInvalidDataLength
+		return nil, errors.ErrInvalidPadding
+	}
+
+	limit := len(data)
+	if blockSize > 0 {
+		limit = min(limit, blockSize)
 	}
 
 	switch scheme {
@@ -54,24 +109,89 @@ func Unpad(data []byte, scheme PaddingScheme) ([]byte, error) {
 			i--
 		}
 		return data[:i+1], nil
-	case ANSIX923, ISO10126:
-		padLen := int(data[len(data)-1])
-		if padLen > len(data) || padLen == 0 {
-			return nil, errors.ErrInvalidDataLength
-		}
-		return data[:len(data)-padLen], nil
+	case ANSIX923:
+		return checkPadding(data, limit, 0, false)
+	case ISO10126:
+		return checkPadding(data, limit, 0, true)
 	case PKCS7:
-		padLen := int(data[len(data)-1])
-		if padLen > len(data) || padLen == 0 {
+		unpadded, err := checkPadding(data, limit, data[len(data)-1], false)
+		if err != nil && lenient {
 			return data, nil
 		}
-		for i := len(data) - padLen; i < len(data); i++ {
-			if data[i] != byte(padLen) {
-				return data, nil
-			}
-		}
-		return data[:len(data)-padLen], nil
+		return unpadded, err
+	case ISO7816:
+		return checkISO7816(data, limit)
+	case TBC:
+		return checkTBC(data, limit)
 	default:
 		return nil, errors.ErrInvalidPaddingScheme
 	}
 }
+
+// checkPadding verifies that the last byte is a length in [1, limit] and
+// that the filler bytes before it equal filler, unless anyFiller is set.
+// Every byte that could belong to the padding is inspected regardless of
+// the length, so the running time does not reveal where the check failed.
+func checkPadding(data []byte, limit int, filler byte, anyFiller bool) ([]byte, error) {
+	n := len(data)
+	padLen := int(data[n-1])
+
+	good := subtle.ConstantTimeLessOrEq(1, padLen) & subtle.ConstantTimeLessOrEq(padLen, limit)
+	if !anyFiller {
+		for i := 2; i <= min(n, 255); i++ {
+			inPadding := subtle.ConstantTimeLessOrEq(i, padLen)
+			matches := subtle.ConstantTimeByteEq(data[n-i], filler)
+			good &= subtle.ConstantTimeSelect(inPadding, matches, 1)
+		}
+	}
+
+	if good != 1 {
+		return nil, errors.ErrInvalidPadding
+	}
+	return data[:n-padLen], nil
+}
+
+// checkISO7816 looks for the 0x80 marker among the last limit bytes, which
+// may only be preceded (from the end) by zeros. As in checkPadding, all
+// limit bytes are inspected whatever the outcome.
+func checkISO7816(data []byte, limit int) ([]byte, error) {
+	n := len(data)
+	found, invalid, padLen := 0, 0, 0
+
+	for i := 1; i <= limit; i++ {
+		searching := 1 ^ found
+		isZero := subtle.ConstantTimeByteEq(data[n-i], 0)
+		isMarker := subtle.ConstantTimeByteEq(data[n-i], 0x80)
+
+		hit := searching & isMarker
+		padLen = subtle.ConstantTimeSelect(hit, i, padLen)
+		invalid |= searching & (1 ^ isZero) & (1 ^ isMarker)
+		found |= hit
+	}
+
+	if found&(1^invalid) != 1 {
+		return nil, errors.ErrInvalidPadding
+	}
+	return data[:n-padLen], nil
+}
+
+// checkTBC strips the run of 0x00 or 0xFF bytes that ends the data. The
+// last data bit is the complement of the padding, so the run cannot extend
+// into the data; it only has to fit within limit.
+func checkTBC(data []byte, limit int) ([]byte, error) {
+	n := len(data)
+	filler := data[n-1]
+
+	good := subtle.ConstantTimeByteEq(filler, 0) | subtle.ConstantTimeByteEq(filler, 0xFF)
+	running, padLen := 1, 0
+	for i := 1; i <= min(n, limit+1); i++ {
+		running &= subtle.ConstantTimeByteEq(data[n-i], filler)
+		padLen += running
+	}
+	good &= subtle.ConstantTimeLessOrEq(padLen, limit)
+
+	if good != 1 {
+		return nil, errors.ErrInvalidPadding
+	}
+	return data[:n-padLen], nil
+}
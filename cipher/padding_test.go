@@ -0,0 +1,202 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPadUnpadRoundTrip(t *testing.T) {
+	for _, scheme := range []cipher.PaddingScheme{cipher.ANSIX923, cipher.PKCS7, cipher.ISO10126, cipher.ISO7816, cipher.TBC} {
+		for size := 0; size <= 17; size++ {
+			data := bytes.Repeat([]byte{0xA5}, size)
+			padded, err := cipher.Pad(data, 8, scheme)
+			require.NoError(t, err)
+
+			unpadded, err := cipher.Unpad(padded, scheme)
+			require.NoError(t, err)
+			assert.Equal(t, data, unpadded, "scheme %d, size %d", scheme, size)
+		}
+	}
+}
+
+func TestISO7816Padding(t *testing.T) {
+	padded, err := cipher.Pad([]byte{1, 2, 3}, 8, cipher.ISO7816)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3, 0x80, 0, 0, 0, 0}, padded)
+
+	padded, err = cipher.Pad(make([]byte, 8), 8, cipher.ISO7816)
+	require.NoError(t, err)
+	assert.Equal(t, append(make([]byte, 8), 0x80, 0, 0, 0, 0, 0, 0, 0), padded)
+
+	// A 0x80 byte inside the data is not mistaken for the marker.
+	unpadded, err := cipher.Unpad([]byte{0x80, 0, 0x80, 0}, cipher.ISO7816)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x80, 0}, unpadded)
+}
+
+func TestUnpadRejectsMalformedPadding(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		scheme cipher.PaddingScheme
+	}{
+		{"empty", nil, cipher.PKCS7},
+		{"PKCS7 zero length", []byte{1, 2, 3, 0}, cipher.PKCS7},
+		{"PKCS7 too long", []byte{5, 5, 5, 5}, cipher.PKCS7},
+		{"PKCS7 wrong filler", []byte{1, 2, 3, 2, 3, 3}, cipher.PKCS7},
+		{"ANSI X.923 nonzero filler", []byte{1, 2, 7, 0, 4}, cipher.ANSIX923},
+		{"ANSI X.923 zero length", []byte{1, 2, 0}, cipher.ANSIX923},
+		{"ISO 10126 too long", []byte{1, 9}, cipher.ISO10126},
+		{"ISO 7816 no marker", []byte{1, 0, 0, 0}, cipher.ISO7816},
+		{"ISO 7816 junk after marker", []byte{1, 0x80, 0, 1}, cipher.ISO7816},
+		{"TBC bad filler", []byte{1, 2, 3, 7}, cipher.TBC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cipher.Unpad(tt.data, tt.scheme)
+			assert.ErrorIs(t, err, errors.ErrInvalidPadding)
+			assert.EqualError(t, err, errors.ErrInvalidPadding.Error(), "the error must not say what was wrong")
+		})
+	}
+}
+
+func TestUnpadLenient(t *testing.T) {
+	malformed := []byte{1, 2, 3, 2, 3, 3}
+	unpadded, err := cipher.UnpadLenient(malformed, cipher.PKCS7)
+	require.NoError(t, err)
+	assert.Equal(t, malformed, unpadded)
+
+	unpadded, err = cipher.UnpadLenient([]byte{1, 2, 2, 2}, cipher.PKCS7)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, unpadded)
+}
+
+func TestContextPaddingStrictness(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("8bytekey")
+
+	// A 16-byte run of 0x10 is well-formed PKCS7 in isolation but longer
+	// than the DES block, so only the context can tell it is invalid.
+	plaintext := bytes.Repeat([]byte{0x10}, 16)
+	block := des.NewDES()
+	require.NoError(t, block.SetKey(ctx, key))
+	ciphertext, err := (&cipher.ECBMode{}).Encrypt(ctx, block, plaintext, nil)
+	require.NoError(t, err)
+
+	strict, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.ECBMode{}, cipher.PKCS7)
+	require.NoError(t, err)
+	_, err = strict.Decrypt(ctx, ciphertext)
+	assert.ErrorIs(t, err, errors.ErrInvalidPadding)
+
+	lenient, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.ECBMode{}, cipher.PKCS7, cipher.WithLenientPadding())
+	require.NoError(t, err)
+	decrypted, err := lenient.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestTBCPadding(t *testing.T) {
+	padded, err := cipher.Pad([]byte{0x01, 0x02}, 4, cipher.TBC)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02, 0xFF, 0xFF}, padded)
+
+	padded, err = cipher.Pad([]byte{0x01, 0x03}, 4, cipher.TBC)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x03, 0x00, 0x00}, padded)
+
+	padded, err = cipher.Pad(nil, 4, cipher.TBC)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xFF, 0xFF, 0xFF, 0xFF}, padded)
+
+	// Data ending in 0xFE keeps its last byte even though 0xFF padding
+	// follows it.
+	unpadded, err := cipher.Unpad([]byte{0xFF, 0xFE, 0xFF, 0xFF}, cipher.TBC)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xFF, 0xFE}, unpadded)
+}
+
+func TestNoPadding(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("8bytekey")
+	iv := []byte("initvect")
+
+	_, err := cipher.Pad(make([]byte, 5), 8, cipher.NoPadding)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+
+	cbc, err := cipher.NewCipherContext(des.NewDES(), key, &cipher.CBCMode{}, cipher.NoPadding, cipher.WithIV(iv))
+	require.NoError(t, err)
+	_, err = cbc.Encrypt(ctx, make([]byte, 5))
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+	ciphertext, err := cbc.Encrypt(ctx, make([]byte, 16))
+	require.NoError(t, err)
+	assert.Len(t, ciphertext, 16)
+
+	for _, mode := range []cipher.CipherMode{&cipher.CTRMode{}, &cipher.OFBMode{}, &cipher.CFBMode{}, &cipher.CFBMode{SegmentSize: 8}} {
+		c, err := cipher.NewCipherContext(des.NewDES(), key, mode, cipher.NoPadding, cipher.WithIV(iv), cipher.WithStreamChunkSize(8))
+		require.NoError(t, err)
+
+		for size := 0; size <= 19; size++ {
+			plaintext := bytes.Repeat([]byte{byte(size)}, size)
+			ciphertext, err := c.Encrypt(ctx, plaintext)
+			require.NoError(t, err)
+			assert.Len(t, ciphertext, size, "%T", mode)
+
+			// The tail must come from the same keystream as a longer message.
+			longer, err := c.Encrypt(ctx, append(append([]byte{}, plaintext...), make([]byte, 8)...))
+			require.NoError(t, err)
+			assert.Equal(t, longer[:size], ciphertext, "%T", mode)
+
+			decrypted, err := c.Decrypt(ctx, ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, append([]byte{}, decrypted...))
+
+			var streamed bytes.Buffer
+			require.NoError(t, c.EncryptStream(ctx, bytes.NewReader(plaintext), &streamed))
+			assert.Equal(t, ciphertext, append([]byte{}, streamed.Bytes()...), "%T", mode)
+
+			var restored bytes.Buffer
+			require.NoError(t, c.DecryptStream(ctx, bytes.NewReader(ciphertext), &restored))
+			assert.Equal(t, plaintext, append([]byte{}, restored.Bytes()...))
+		}
+	}
+}
+
+func TestStreamModesIgnorePadding(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("8bytekey")
+	iv := []byte("initvect")
+
+	for _, mode := range []cipher.CipherMode{&cipher.CTRMode{}, &cipher.OFBMode{}, &cipher.CFBMode{}} {
+		for _, scheme := range []cipher.PaddingScheme{cipher.PKCS7, cipher.ISO10126, cipher.ANSIX923} {
+			c, err := cipher.NewCipherContext(des.NewDES(), key, mode, scheme, cipher.WithIV(iv))
+			require.NoError(t, err)
+
+			for _, size := range []int{0, 5, 8, 21} {
+				plaintext := bytes.Repeat([]byte{0xA5}, size)
+				ciphertext, err := c.Encrypt(ctx, plaintext)
+				require.NoError(t, err)
+				assert.Len(t, ciphertext, size, "%T", mode)
+
+				var written bytes.Buffer
+				w, err := c.NewEncryptingWriter(ctx, &written)
+				require.NoError(t, err)
+				_, err = w.Write(plaintext)
+				require.NoError(t, err)
+				require.NoError(t, w.Close())
+				assert.Equal(t, append([]byte{}, ciphertext...), append([]byte{}, written.Bytes()...), "%T", mode)
+
+				decrypted, err := c.Decrypt(ctx, ciphertext)
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, append([]byte{}, decrypted...))
+			}
+		}
+	}
+}
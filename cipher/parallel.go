@@ -0,0 +1,103 @@
+package cipher
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const DefaultChunkSize = 4 * 1024
+
+type Settings struct {
+	Workers   int
+	ChunkSize int
+}
+
+type settingsKey struct{}
+
+func WithSettings(ctx context.Context, settings Settings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, settings)
+}
+
+func SettingsFromContext(ctx context.Context) Settings {
+	settings, _ := ctx.Value(settingsKey{}).(Settings)
+	return settings.normalize()
+}
+
+func (s Settings) normalize() Settings {
+	if s.Workers <= 0 {
+		s.Workers = runtime.NumCPU()
+	}
+	if s.ChunkSize <= 0 {
+		s.ChunkSize = DefaultChunkSize
+	}
+	return s
+}
+
+func parallelBlocks(ctx context.Context, data []byte, blockSize int, fn func(idx, start, end int) error) error {
+	return parallelChunks(ctx, len(data)/blockSize, blockSize, func(first, last int) error {
+		var errs errors.MultiError
+		for idx := first; idx < last; idx++ {
+			errs.Add(idx, fn(idx, idx*blockSize, (idx+1)*blockSize))
+		}
+		return errs.ErrorOrNil()
+	})
+}
+
+func parallelChunks(ctx context.Context, numBlocks, blockSize int, fn func(first, last int) error) error {
+	settings := SettingsFromContext(ctx)
+	perTask := max(settings.ChunkSize/blockSize, 1)
+	tasks := (numBlocks + perTask - 1) / perTask
+
+	return parallelFor(ctx, settings.Workers, tasks, perTask, func(task int) error {
+		first := task * perTask
+		return fn(first, min(first+perTask, numBlocks))
+	})
+}
+
+func parallelFor(ctx context.Context, workers, n, blocksPerTask int, fn func(i int) error) error {
+	var (
+		next   atomic.Int64
+		failed atomic.Bool
+		mu     sync.Mutex
+		errs   errors.MultiError
+		wg     sync.WaitGroup
+	)
+
+	work := func() {
+		for !failed.Load() && ctx.Err() == nil {
+			i := int(next.Add(1) - 1)
+			if i >= n {
+				return
+			}
+			if err := fn(i); err != nil {
+				failed.Store(true)
+				mu.Lock()
+				errs.Add(i*blocksPerTask, err)
+				mu.Unlock()
+			}
+		}
+	}
+
+	workers = min(workers, n)
+	if workers <= 1 {
+		work()
+	} else {
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				work()
+			}()
+		}
+		wg.Wait()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errs.ErrorOrNil()
+}
@@ -0,0 +1,130 @@
+package cipher_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOFBPipelineCancellation(t *testing.T) {
+	block := des.NewDES()
+	require.NoError(t, block.SetKey(context.Background(), []byte("01234567")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx = cipher.WithSettings(ctx, cipher.Settings{Workers: 4, ChunkSize: 64})
+
+	_, err := (&cipher.OFBMode{}).Encrypt(ctx, block, make([]byte, 1024), make([]byte, 8))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func benchmarkKeystreamMode(b *testing.B, mode cipher.CipherMode) {
+	block := des.NewDES()
+	require.NoError(b, block.SetKey(context.Background(), []byte("01234567")))
+	data := make([]byte, 256*1024)
+	iv := make([]byte, 8)
+
+	workerCounts := []int{1}
+	if runtime.NumCPU() > 1 {
+		workerCounts = append(workerCounts, runtime.NumCPU())
+	}
+
+	for _, workers := range workerCounts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			ctx := cipher.WithSettings(context.Background(), cipher.Settings{Workers: workers})
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := mode.Encrypt(ctx, block, data, iv); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCTR(b *testing.B) {
+	benchmarkKeystreamMode(b, &cipher.CTRMode{})
+}
+
+func BenchmarkOFB(b *testing.B) {
+	benchmarkKeystreamMode(b, &cipher.OFBMode{})
+}
+
+type failingBlock struct {
+	failures map[byte]bool
+}
+
+func (b *failingBlock) SetKey(context.Context, []byte) error { return nil }
+func (b *failingBlock) BlockSize() int                       { return 8 }
+
+func (b *failingBlock) Encrypt(_ context.Context, block []byte) ([]byte, error) {
+	if b.failures[block[len(block)-1]] {
+		return nil, errors.ErrInvalidDataLength
+	}
+	return append([]byte{}, block...), nil
+}
+
+func (b *failingBlock) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	return b.Encrypt(ctx, block)
+}
+
+func blocksNumbered(n int) []byte {
+	data := make([]byte, 8*n)
+	for i := 0; i < n; i++ {
+		data[8*i+7] = byte(i)
+	}
+	return data
+}
+
+func TestParallelCollectsBlockErrors(t *testing.T) {
+	block := &failingBlock{failures: map[byte]bool{3: true, 9: true, 10: true}}
+	ctx := cipher.WithSettings(context.Background(), cipher.Settings{Workers: 1, ChunkSize: 1024})
+
+	_, err := (&cipher.ECBMode{}).Encrypt(ctx, block, blocksNumbered(16), nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+
+	var multi *errors.MultiError
+	require.ErrorAs(t, err, &multi)
+	var indices []int
+	for _, blockErr := range multi.Errors {
+		indices = append(indices, blockErr.Index)
+	}
+	assert.Equal(t, []int{3, 9, 10}, indices)
+}
+
+func TestParallelEarlyFailureStopsWorkers(t *testing.T) {
+	block := &failingBlock{failures: map[byte]bool{0: true}}
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 20; i++ {
+		ctx := cipher.WithSettings(context.Background(), cipher.Settings{Workers: 8, ChunkSize: 8})
+		_, err := (&cipher.CBCMode{}).Decrypt(ctx, block, blocksNumbered(200), make([]byte, 8))
+
+		var blockErr *errors.BlockError
+		require.ErrorAs(t, err, &blockErr)
+		assert.Equal(t, 0, blockErr.Index)
+	}
+
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestParallelKeystreamBlockIndex(t *testing.T) {
+	block := &failingBlock{failures: map[byte]bool{5: true}}
+	ctx := cipher.WithSettings(context.Background(), cipher.Settings{Workers: 2, ChunkSize: 16})
+
+	_, err := (&cipher.CTRMode{}).Encrypt(ctx, block, make([]byte, 8*5), make([]byte, 8))
+	require.NoError(t, err)
+
+	_, err = (&cipher.CTRMode{}).Encrypt(ctx, block, make([]byte, 8*8), make([]byte, 8))
+	var blockErr *errors.BlockError
+	require.ErrorAs(t, err, &blockErr)
+	assert.Equal(t, 5, blockErr.Index)
+}
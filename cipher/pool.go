@@ -0,0 +1,75 @@
+package cipher
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// workerPool runs the asynchronous EncryptBytes/DecryptBytes calls on a
+// fixed set of long-lived goroutines instead of spawning one per call. When
+// every worker is busy the job falls back to its own goroutine, so a
+// saturated pool never blocks the caller.
+type workerPool struct {
+	once sync.Once
+	jobs chan func()
+}
+
+var sharedPool workerPool
+
+func (p *workerPool) start() {
+	workers := runtime.NumCPU()
+	p.jobs = make(chan func(), workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+}
+
+func (p *workerPool) submit(job func()) {
+	p.once.Do(p.start)
+	select {
+	case p.jobs <- job:
+	default:
+		go job()
+	}
+}
+
+// runAsync adapts a synchronous operation to the channel-based API.
+func runAsync(ctx context.Context, fn func(context.Context) ([]byte, error)) (<-chan []byte, <-chan error) {
+	resultChan := make(chan []byte, 1)
+	errChan := make(chan error, 1)
+
+	if err := ctx.Err(); err != nil {
+		errChan <- err
+		close(resultChan)
+		close(errChan)
+		return resultChan, errChan
+	}
+
+	sharedPool.submit(func() {
+		defer close(resultChan)
+		defer close(errChan)
+
+		result, err := fn(ctx)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		resultChan <- result
+	})
+
+	return resultChan, errChan
+}
+
+// checkCancelled is called by the sequential mode loops before every block
+// and consults ctx once per chunk, keeping the per-block cost negligible.
+func checkCancelled(ctx context.Context, block, blockSize int) error {
+	if block%max(DefaultChunkSize/blockSize, 1) != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,120 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/aes"
+)
+
+// cancellingCipher cancels its context after a fixed number of blocks so the
+// tests can observe cancellation in the middle of a mode loop.
+type cancellingCipher struct {
+	cipher.BlockCipher
+	after  int64
+	count  atomic.Int64
+	cancel context.CancelFunc
+}
+
+func (c *cancellingCipher) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if c.count.Add(1) == c.after {
+		c.cancel()
+	}
+	return c.BlockCipher.Encrypt(ctx, block)
+}
+
+func newAESContext(t testing.TB, mode cipher.CipherMode) *cipher.CipherContext {
+	c, err := cipher.NewCipherContext(aes.NewAES128(), []byte("0123456789abcdef"), mode, cipher.PKCS7, cipher.WithIV(bytes.Repeat([]byte{9}, 16)))
+	require.NoError(t, err)
+	return c
+}
+
+func TestSyncMatchesAsync(t *testing.T) {
+	ctx := context.Background()
+	plaintext := []byte("the synchronous path must agree with the channel API")
+
+	for _, mode := range []cipher.CipherMode{&cipher.ECBMode{}, &cipher.CBCMode{}, &cipher.CTRMode{}, &cipher.OFBMode{}} {
+		c := newAESContext(t, mode)
+
+		want, err := collect(c.EncryptBytes(ctx, plaintext))
+		require.NoError(t, err)
+		got, err := c.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+
+		decrypted, err := c.Decrypt(ctx, got)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
+
+func TestSyncRejectsCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := newAESContext(t, &cipher.CBCMode{})
+	_, err := c.Encrypt(ctx, []byte("data"))
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = collect(c.EncryptBytes(ctx, []byte("data")))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCancellationBetweenBlocks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	block := aes.NewAES128()
+	wrapped := &cancellingCipher{BlockCipher: block, after: 3, cancel: cancel}
+	c, err := cipher.NewCipherContext(wrapped, make([]byte, 16), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(make([]byte, 16)))
+	require.NoError(t, err)
+
+	_, err = c.Encrypt(ctx, make([]byte, 2*cipher.DefaultChunkSize))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, wrapped.count.Load(), int64(2*cipher.DefaultChunkSize/16))
+}
+
+func TestAsyncConcurrentCalls(t *testing.T) {
+	ctx := context.Background()
+	c := newAESContext(t, &cipher.CTRMode{})
+	plaintext := []byte("many concurrent callers share the worker pool")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			encrypted, err := collect(c.EncryptBytes(ctx, plaintext))
+			assert.NoError(t, err)
+			decrypted, err := collect(c.DecryptBytes(ctx, encrypted))
+			assert.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkEncryptSmallAsync(b *testing.B) {
+	ctx := context.Background()
+	c := newAESContext(b, &cipher.CBCMode{})
+	data := make([]byte, 64)
+	for i := 0; i < b.N; i++ {
+		_, _ = collect(c.EncryptBytes(ctx, data))
+	}
+}
+
+func BenchmarkEncryptSmallSync(b *testing.B) {
+	ctx := context.Background()
+	c := newAESContext(b, &cipher.CBCMode{})
+	data := make([]byte, 64)
+	for i := 0; i < b.N; i++ {
+		_, _ = c.Encrypt(ctx, data)
+	}
+}
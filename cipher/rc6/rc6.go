@@ -0,0 +1,202 @@
+package rc6
+
+import (
+	"context"
+	"math/bits"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	DefaultWordSize = 32
+	DefaultRounds   = 20
+
+	maxRounds   = 255
+	maxKeyBytes = 255
+)
+
+// Magic constants Odd((e-2)*2^w) and Odd((phi-1)*2^w) for each word size.
+var magic = map[int][2]uint64{
+	8:  {0xB7, 0x9F},
+	16: {0xB7E1, 0x9E37},
+	32: {0xB7E15163, 0x9E3779B9},
+	64: {0xB7E151628AED2A6B, 0x9E3779B97F4A7C15},
+}
+
+type RC6 struct {
+	wordSize  int
+	wordBytes int
+	lgw       uint
+	mask      uint64
+	rounds    int
+	keySizes  []int
+	roundKeys []uint64
+}
+
+func NewRC6() *RC6 {
+	c, _ := newRC6(DefaultWordSize, DefaultRounds, []int{16, 24, 32})
+	return c
+}
+
+func NewRC6WithParams(wordSize, rounds, keyBytes int) (*RC6, error) {
+	if keyBytes < 0 || keyBytes > maxKeyBytes {
+		return nil, errors.ErrInvalidKeySize
+	}
+	return newRC6(wordSize, rounds, []int{keyBytes})
+}
+
+func newRC6(wordSize, rounds int, keySizes []int) (*RC6, error) {
+	if _, ok := magic[wordSize]; !ok {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unsupported word size %d: %w", wordSize)
+	}
+	if rounds < 1 || rounds > maxRounds {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unsupported round count %d: %w", rounds)
+	}
+
+	return &RC6{
+		wordSize:  wordSize,
+		wordBytes: wordSize / 8,
+		lgw:       uint(bits.TrailingZeros(uint(wordSize))),
+		mask:      ^uint64(0) >> (64 - wordSize),
+		rounds:    rounds,
+		keySizes:  keySizes,
+	}, nil
+}
+
+func (c *RC6) BlockSize() int {
+	return 4 * c.wordBytes
+}
+
+func (c *RC6) WordSize() int {
+	return c.wordSize
+}
+
+func (c *RC6) Rounds() int {
+	return c.rounds
+}
+
+func (c *RC6) SetKey(ctx context.Context, key []byte) error {
+	valid := false
+	for _, size := range c.keySizes {
+		valid = valid || len(key) == size
+	}
+	if !valid {
+		return errors.ErrInvalidKeySize
+	}
+
+	words := max(1, (len(key)+c.wordBytes-1)/c.wordBytes)
+	l := make([]uint64, words)
+	for i := len(key) - 1; i >= 0; i-- {
+		l[i/c.wordBytes] = l[i/c.wordBytes]<<8 | uint64(key[i])
+	}
+
+	p, q := magic[c.wordSize][0], magic[c.wordSize][1]
+	s := make([]uint64, 2*c.rounds+4)
+	s[0] = p
+	for i := 1; i < len(s); i++ {
+		s[i] = (s[i-1] + q) & c.mask
+	}
+
+	var a, b uint64
+	i, j := 0, 0
+	for k := 0; k < 3*max(len(l), len(s)); k++ {
+		a = c.rotl(s[i]+a+b, 3)
+		s[i] = a
+		b = c.rotl(l[j]+a+b, a+b)
+		l[j] = b
+		i = (i + 1) % len(s)
+		j = (j + 1) % len(l)
+	}
+
+	c.roundKeys = s
+	return nil
+}
+
+func (c *RC6) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := c.check(block); err != nil {
+		return nil, err
+	}
+
+	a, b, cc, d := c.load(block)
+	s := c.roundKeys
+
+	b = (b + s[0]) & c.mask
+	d = (d + s[1]) & c.mask
+	for i := 1; i <= c.rounds; i++ {
+		t := c.rotl(b*(2*b+1), uint64(c.lgw))
+		u := c.rotl(d*(2*d+1), uint64(c.lgw))
+		a = (c.rotl(a^t, u) + s[2*i]) & c.mask
+		cc = (c.rotl(cc^u, t) + s[2*i+1]) & c.mask
+		a, b, cc, d = b, cc, d, a
+	}
+	a = (a + s[2*c.rounds+2]) & c.mask
+	cc = (cc + s[2*c.rounds+3]) & c.mask
+
+	return c.store(a, b, cc, d), nil
+}
+
+func (c *RC6) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := c.check(block); err != nil {
+		return nil, err
+	}
+
+	a, b, cc, d := c.load(block)
+	s := c.roundKeys
+
+	cc = (cc - s[2*c.rounds+3]) & c.mask
+	a = (a - s[2*c.rounds+2]) & c.mask
+	for i := c.rounds; i >= 1; i-- {
+		a, b, cc, d = d, a, b, cc
+		u := c.rotl(d*(2*d+1), uint64(c.lgw))
+		t := c.rotl(b*(2*b+1), uint64(c.lgw))
+		cc = c.rotr(cc-s[2*i+1], t) ^ u
+		a = c.rotr(a-s[2*i], u) ^ t
+	}
+	d = (d - s[1]) & c.mask
+	b = (b - s[0]) & c.mask
+
+	return c.store(a, b, cc, d), nil
+}
+
+func (c *RC6) check(block []byte) error {
+	if len(block) != c.BlockSize() {
+		return errors.ErrInvalidBlockSize
+	}
+	if c.roundKeys == nil {
+		return errors.ErrInvalidKeySize
+	}
+	return nil
+}
+
+func (c *RC6) rotl(x, n uint64) uint64 {
+	x &= c.mask
+	n &= uint64(c.wordSize - 1)
+	if n == 0 {
+		return x
+	}
+	return (x<<n | x>>(uint64(c.wordSize)-n)) & c.mask
+}
+
+func (c *RC6) rotr(x, n uint64) uint64 {
+	return c.rotl(x, uint64(c.wordSize)-n&uint64(c.wordSize-1))
+}
+
+func (c *RC6) load(block []byte) (a, b, cc, d uint64) {
+	var w [4]uint64
+	for i := range w {
+		for j := c.wordBytes - 1; j >= 0; j-- {
+			w[i] = w[i]<<8 | uint64(block[i*c.wordBytes+j])
+		}
+	}
+	return w[0], w[1], w[2], w[3]
+}
+
+func (c *RC6) store(a, b, cc, d uint64) []byte {
+	out := make([]byte, c.BlockSize())
+	for i, w := range [4]uint64{a, b, cc, d} {
+		for j := 0; j < c.wordBytes; j++ {
+			out[i*c.wordBytes+j] = byte(w >> (8 * j))
+		}
+	}
+	return out
+}
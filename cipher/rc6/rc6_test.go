@@ -0,0 +1,142 @@
+package rc6
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+var _ cipher.BlockCipher = (*RC6)(nil)
+
+func decode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func sequence(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestRC6Vectors(t *testing.T) {
+	ctx := context.Background()
+	vectors := []struct {
+		key, plaintext, ciphertext string
+	}{
+		{
+			"00000000000000000000000000000000",
+			"00000000000000000000000000000000",
+			"8fc3a53656b1f778c129df4e9848a41e",
+		},
+		{
+			"0123456789abcdef0112233445566778",
+			"02132435465768798a9bacbdcedfe0f1",
+			"524e192f4715c6231f51f6367ea43f18",
+		},
+		{
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"00000000000000000000000000000000",
+			"8f5fbd0510d15fa893fa3fda6e857ec2",
+		},
+	}
+
+	for _, v := range vectors {
+		c := NewRC6()
+		require.NoError(t, c.SetKey(ctx, decode(t, v.key)))
+
+		ct, err := c.Encrypt(ctx, decode(t, v.plaintext))
+		require.NoError(t, err)
+		assert.Equal(t, v.ciphertext, hex.EncodeToString(ct))
+
+		pt, err := c.Decrypt(ctx, ct)
+		require.NoError(t, err)
+		assert.Equal(t, v.plaintext, hex.EncodeToString(pt))
+	}
+}
+
+func TestRC6Params(t *testing.T) {
+	ctx := context.Background()
+	vectors := []struct {
+		wordSize, rounds, keyBytes int
+		ciphertext                 string
+	}{
+		{8, 12, 4, "aefc4612"},
+		{16, 16, 12, "b9b46451d405747c"},
+		{32, 20, 16, "3a96f9c7f6755cfe46f00e3dcd5d2a3c"},
+		{64, 24, 24, "c002de050bd55e5d36864ab9853338e6dc4a1326c6bdaaeb1bc9e4fd67886617"},
+	}
+
+	for _, v := range vectors {
+		c, err := NewRC6WithParams(v.wordSize, v.rounds, v.keyBytes)
+		require.NoError(t, err)
+		assert.Equal(t, v.wordSize/2, c.BlockSize())
+		require.NoError(t, c.SetKey(ctx, sequence(v.keyBytes)))
+
+		plaintext := sequence(c.BlockSize())
+		ct, err := c.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, v.ciphertext, hex.EncodeToString(ct), "RC6-%d/%d/%d", v.wordSize, v.rounds, v.keyBytes)
+
+		pt, err := c.Decrypt(ctx, ct)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, pt)
+	}
+}
+
+func TestRC6ReducedRounds(t *testing.T) {
+	ctx := context.Background()
+	for _, wordSize := range []int{16, 32, 64} {
+		for _, rounds := range []int{1, 2, 4, 8} {
+			c, err := NewRC6WithParams(wordSize, rounds, 0)
+			require.NoError(t, err)
+			require.NoError(t, c.SetKey(ctx, nil))
+			assert.Equal(t, rounds, c.Rounds())
+
+			plaintext := make([]byte, c.BlockSize())
+			_, err = rand.Read(plaintext)
+			require.NoError(t, err)
+
+			ct, err := c.Encrypt(ctx, plaintext)
+			require.NoError(t, err)
+			assert.NotEqual(t, plaintext, ct)
+
+			pt, err := c.Decrypt(ctx, ct)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, pt)
+		}
+	}
+}
+
+func TestRC6Errors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewRC6WithParams(24, 20, 16)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+	_, err = NewRC6WithParams(32, 0, 16)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+	_, err = NewRC6WithParams(32, 20, 256)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+
+	c := NewRC6()
+	_, err = c.Encrypt(ctx, make([]byte, 16))
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+	assert.ErrorIs(t, c.SetKey(ctx, make([]byte, 20)), errors.ErrInvalidKeySize)
+
+	custom, err := NewRC6WithParams(64, 24, 24)
+	require.NoError(t, err)
+	assert.ErrorIs(t, custom.SetKey(ctx, make([]byte, 16)), errors.ErrInvalidKeySize)
+	require.NoError(t, custom.SetKey(ctx, make([]byte, 24)))
+	_, err = custom.Encrypt(ctx, make([]byte, 16))
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
@@ -16,7 +16,10 @@ type Rijndael struct {
 	modulus   byte
 	sbox      [256]byte
 	invSbox   [256]byte
+	te, td    [4][256]uint32
 	roundKeys [][]byte
+	encKeys   [][]uint32
+	decKeys   [][]uint32
 	sboxInit  sync.Once
 }
 
@@ -57,9 +60,13 @@ func (r *Rijndael) SetKey(ctx context.Context, key []byte) error {
 
 	r.initSBox()
 
-	var err error
-	r.roundKeys, err = r.keyExpansion(key)
-	return err
+	roundKeys, err := r.keyExpansion(key)
+	if err != nil {
+		return err
+	}
+	r.roundKeys = roundKeys
+	r.expandTableKeys()
+	return nil
 }
 
 func (r *Rijndael) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
@@ -71,6 +78,52 @@ func (r *Rijndael) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
 		return nil, errors.ErrInvalidKeySize
 	}
 
+	return r.encryptBlock(block), nil
+}
+
+func (r *Rijndael) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if len(block) != r.blockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+
+	if r.roundKeys == nil {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	return r.decryptBlock(block), nil
+}
+
+// EncryptBlockInPlace writes the encryption of src into dst without
+// allocating; dst and src may be the same slice.
+func (r *Rijndael) EncryptBlockInPlace(ctx context.Context, dst, src []byte) error {
+	if err := r.checkInPlace(dst, src); err != nil {
+		return err
+	}
+
+	r.encryptInto(dst, src)
+	return nil
+}
+
+func (r *Rijndael) DecryptBlockInPlace(ctx context.Context, dst, src []byte) error {
+	if err := r.checkInPlace(dst, src); err != nil {
+		return err
+	}
+
+	r.decryptInto(dst, src)
+	return nil
+}
+
+func (r *Rijndael) checkInPlace(dst, src []byte) error {
+	if len(src) != r.blockSize || len(dst) < r.blockSize {
+		return errors.ErrInvalidBlockSize
+	}
+	if r.roundKeys == nil {
+		return errors.ErrInvalidKeySize
+	}
+	return nil
+}
+
+func (r *Rijndael) encryptReference(block []byte) []byte {
 	state := make([]byte, len(block))
 	copy(state, block)
 
@@ -87,18 +140,10 @@ func (r *Rijndael) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
 	r.shiftRows(state)
 	r.addRoundKey(state, r.roundKeys[r.numRounds])
 
-	return state, nil
+	return state
 }
 
-func (r *Rijndael) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
-	if len(block) != r.blockSize {
-		return nil, errors.ErrInvalidBlockSize
-	}
-
-	if r.roundKeys == nil {
-		return nil, errors.ErrInvalidKeySize
-	}
-
+func (r *Rijndael) decryptReference(block []byte) []byte {
 	state := make([]byte, len(block))
 	copy(state, block)
 
@@ -115,7 +160,7 @@ func (r *Rijndael) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
 
 	r.addRoundKey(state, r.roundKeys[0])
 
-	return state, nil
+	return state
 }
 
 func (r *Rijndael) BlockSize() int {
@@ -146,6 +191,8 @@ func (r *Rijndael) initSBox() {
 			}
 			r.invSbox[i] = val
 		}
+
+		r.initTables()
 	})
 }
 
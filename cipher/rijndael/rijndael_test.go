@@ -2,6 +2,8 @@ package rijndael
 
 import (
 	"context"
+	"encoding/hex"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -143,3 +145,51 @@ func TestShiftRowsOffsets(t *testing.T) {
 	shifts256 := r256.getShiftOffsets()
 	assert.Equal(t, [4]int{0, 1, 3, 4}, shifts256, "256-bit block should use shifts [0,1,3,4]")
 }
+
+func TestRijndaelReferenceVectors(t *testing.T) {
+	plaintext, _ := hex.DecodeString("3243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c8")
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c762e7160f38b4da56a784d9045190cfe")
+
+	vectors := []struct {
+		blockSize, keySize int
+		ciphertext         string
+	}{
+		{16, 16, "3925841d02dc09fbdc118597196a0b32"},
+		{16, 24, "f9fb29aefc384a250340d833b87ebc00"},
+		{16, 32, "1a6e6c2c662e7da6501ffb62bc9e93f3"},
+		{24, 16, "b24d275489e82bb8f7375e0d5fcdb1f481757c538b65148a"},
+		{24, 24, "725ae43b5f3161de806a7c93e0bca93c967ec1ae1b71e1cf"},
+		{24, 32, "0ebacf199e3315c2e34b24fcc7c46ef4388aa475d66c194c"},
+		{32, 16, "7d15479076b69a46ffb3b3beae97ad8313f622f67fedb487de9f06b9ed9c8f19"},
+		{32, 24, "5d7101727bb25781bf6715b0e6955282b9610e23a43c2eb062699f0ebf5887b2"},
+		{32, 32, "a49406115dfb30a40418aafa4869b7c6a886ff31602a7dd19c889dc64f7e4e7a"},
+	}
+
+	ctx := context.Background()
+	for _, v := range vectors {
+		t.Run(fmt.Sprintf("b%d_k%d", v.blockSize*8, v.keySize*8), func(t *testing.T) {
+			r, err := NewRijndael(v.blockSize, v.keySize, 0x1B)
+			require.NoError(t, err)
+			require.NoError(t, r.SetKey(ctx, key[:v.keySize]))
+
+			ciphertext, err := r.Encrypt(ctx, plaintext[:v.blockSize])
+			require.NoError(t, err)
+			assert.Equal(t, v.ciphertext, hex.EncodeToString(ciphertext))
+			assert.Equal(t, v.ciphertext, hex.EncodeToString(r.encryptReference(plaintext[:v.blockSize])))
+
+			decrypted, err := r.Decrypt(ctx, ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext[:v.blockSize], decrypted)
+		})
+	}
+}
+
+func TestRijndaelRounds(t *testing.T) {
+	for _, tc := range []struct{ blockSize, keySize, rounds int }{
+		{16, 16, 10}, {16, 24, 12}, {16, 32, 14},
+		{24, 16, 12}, {24, 24, 12}, {24, 32, 14},
+		{32, 16, 14}, {32, 24, 14}, {32, 32, 14},
+	} {
+		assert.Equal(t, tc.rounds, calculateRounds(tc.blockSize, tc.keySize))
+	}
+}
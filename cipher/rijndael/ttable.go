@@ -0,0 +1,130 @@
+package rijndael
+
+import (
+	"encoding/binary"
+
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/masterkusok/crypto/tables"
+)
+
+// maxColumns is the state width in words of the largest (256-bit) block.
+const maxColumns = 8
+
+func (r *Rijndael) initTables() {
+	for x := 0; x < 256; x++ {
+		s, inv := r.sbox[x], r.invSbox[x]
+		for j := 0; j < 4; j++ {
+			var te, td uint32
+			for i := 0; i < 4; i++ {
+				e, _ := cryptoMath.GF256Mul(tables.RijndaelMixColumnMatrix[i][j], s, r.modulus)
+				d, _ := cryptoMath.GF256Mul(tables.RijndaelInvMixColumnMatrix[i][j], inv, r.modulus)
+				te |= uint32(e) << (24 - 8*i)
+				td |= uint32(d) << (24 - 8*i)
+			}
+			r.te[j][x], r.td[j][x] = te, td
+		}
+	}
+}
+
+func (r *Rijndael) expandTableKeys() {
+	nb := r.blockSize / 4
+	r.encKeys = make([][]uint32, r.numRounds+1)
+	r.decKeys = make([][]uint32, r.numRounds+1)
+
+	for round, key := range r.roundKeys {
+		r.encKeys[round] = toWords(key)
+	}
+
+	for round := 0; round <= r.numRounds; round++ {
+		source := r.encKeys[r.numRounds-round]
+		r.decKeys[round] = make([]uint32, nb)
+		for c, w := range source {
+			if round == 0 || round == r.numRounds {
+				r.decKeys[round][c] = w
+				continue
+			}
+			r.decKeys[round][c] = r.td[0][r.sbox[w>>24]] ^ r.td[1][r.sbox[w>>16&0xFF]] ^
+				r.td[2][r.sbox[w>>8&0xFF]] ^ r.td[3][r.sbox[w&0xFF]]
+		}
+	}
+}
+
+func (r *Rijndael) encryptBlock(block []byte) []byte {
+	out := make([]byte, r.blockSize)
+	r.encryptInto(out, block)
+	return out
+}
+
+// encryptInto keeps the state in fixed-size arrays so that it does not
+// allocate; dst and src may be the same slice.
+func (r *Rijndael) encryptInto(dst, src []byte) {
+	nb := r.blockSize / 4
+	shifts := r.getShiftOffsets()
+
+	var s, t [maxColumns]uint32
+	for c := 0; c < nb; c++ {
+		s[c] = binary.BigEndian.Uint32(src[4*c:]) ^ r.encKeys[0][c]
+	}
+
+	for round := 1; round < r.numRounds; round++ {
+		for c := 0; c < nb; c++ {
+			t[c] = r.te[0][s[c]>>24] ^
+				r.te[1][s[(c+shifts[1])%nb]>>16&0xFF] ^
+				r.te[2][s[(c+shifts[2])%nb]>>8&0xFF] ^
+				r.te[3][s[(c+shifts[3])%nb]&0xFF] ^
+				r.encKeys[round][c]
+		}
+		s, t = t, s
+	}
+
+	for c := 0; c < nb; c++ {
+		w := uint32(r.sbox[s[c]>>24])<<24 |
+			uint32(r.sbox[s[(c+shifts[1])%nb]>>16&0xFF])<<16 |
+			uint32(r.sbox[s[(c+shifts[2])%nb]>>8&0xFF])<<8 |
+			uint32(r.sbox[s[(c+shifts[3])%nb]&0xFF])
+		binary.BigEndian.PutUint32(dst[4*c:], w^r.encKeys[r.numRounds][c])
+	}
+}
+
+func (r *Rijndael) decryptBlock(block []byte) []byte {
+	out := make([]byte, r.blockSize)
+	r.decryptInto(out, block)
+	return out
+}
+
+func (r *Rijndael) decryptInto(dst, src []byte) {
+	nb := r.blockSize / 4
+	shifts := r.getShiftOffsets()
+
+	var s, t [maxColumns]uint32
+	for c := 0; c < nb; c++ {
+		s[c] = binary.BigEndian.Uint32(src[4*c:]) ^ r.decKeys[0][c]
+	}
+
+	for round := 1; round < r.numRounds; round++ {
+		for c := 0; c < nb; c++ {
+			t[c] = r.td[0][s[c]>>24] ^
+				r.td[1][s[(c-shifts[1]+nb)%nb]>>16&0xFF] ^
+				r.td[2][s[(c-shifts[2]+nb)%nb]>>8&0xFF] ^
+				r.td[3][s[(c-shifts[3]+nb)%nb]&0xFF] ^
+				r.decKeys[round][c]
+		}
+		s, t = t, s
+	}
+
+	for c := 0; c < nb; c++ {
+		w := uint32(r.invSbox[s[c]>>24])<<24 |
+			uint32(r.invSbox[s[(c-shifts[1]+nb)%nb]>>16&0xFF])<<16 |
+			uint32(r.invSbox[s[(c-shifts[2]+nb)%nb]>>8&0xFF])<<8 |
+			uint32(r.invSbox[s[(c-shifts[3]+nb)%nb]&0xFF])
+		binary.BigEndian.PutUint32(dst[4*c:], w^r.decKeys[r.numRounds][c])
+	}
+}
+
+func toWords(b []byte) []uint32 {
+	words := make([]uint32, len(b)/4)
+	for i := range words {
+		words[i] = binary.BigEndian.Uint32(b[4*i:])
+	}
+	return words
+}
@@ -0,0 +1,83 @@
+package rijndael
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableMatchesReference(t *testing.T) {
+	ctx := context.Background()
+
+	for _, blockSize := range []int{16, 24, 32} {
+		for _, keySize := range []int{16, 24, 32} {
+			for _, mod := range []byte{0x1B, 0x1D} {
+				t.Run(fmt.Sprintf("b%d_k%d_m%02x", blockSize, keySize, mod), func(t *testing.T) {
+					r, err := NewRijndael(blockSize, keySize, mod)
+					require.NoError(t, err)
+
+					key := make([]byte, keySize)
+					_, err = rand.Read(key)
+					require.NoError(t, err)
+					require.NoError(t, r.SetKey(ctx, key))
+
+					for i := 0; i < 8; i++ {
+						block := make([]byte, blockSize)
+						_, err = rand.Read(block)
+						require.NoError(t, err)
+
+						ciphertext, err := r.Encrypt(ctx, block)
+						require.NoError(t, err)
+						assert.Equal(t, r.encryptReference(block), ciphertext)
+
+						decrypted, err := r.Decrypt(ctx, ciphertext)
+						require.NoError(t, err)
+						assert.Equal(t, r.decryptReference(ciphertext), decrypted)
+						assert.Equal(t, block, decrypted)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestEncryptWithoutKey(t *testing.T) {
+	r, err := NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+
+	_, err = r.Encrypt(context.Background(), make([]byte, 16))
+	require.Error(t, err)
+}
+
+func benchmarkRijndael(b *testing.B, encrypt func(r *Rijndael, block []byte)) {
+	r, err := NewRijndael(16, 16, 0x1B)
+	require.NoError(b, err)
+	require.NoError(b, r.SetKey(context.Background(), make([]byte, 16)))
+
+	block := make([]byte, 16)
+	b.SetBytes(int64(len(block)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encrypt(r, block)
+	}
+}
+
+func BenchmarkEncryptTable(b *testing.B) {
+	benchmarkRijndael(b, func(r *Rijndael, block []byte) { r.encryptBlock(block) })
+}
+
+func BenchmarkEncryptReference(b *testing.B) {
+	benchmarkRijndael(b, func(r *Rijndael, block []byte) { r.encryptReference(block) })
+}
+
+func BenchmarkDecryptTable(b *testing.B) {
+	benchmarkRijndael(b, func(r *Rijndael, block []byte) { r.decryptBlock(block) })
+}
+
+func BenchmarkDecryptReference(b *testing.B) {
+	benchmarkRijndael(b, func(r *Rijndael, block []byte) { r.decryptReference(block) })
+}
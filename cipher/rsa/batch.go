@@ -0,0 +1,114 @@
+package rsa
+
+import (
+	"context"
+	"crypto"
+	"math/big"
+	"runtime"
+	"sync"
+
+	cryptoErrors "github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+type Scheme int
+
+const (
+	SchemePKCS1v15 Scheme = iota
+	SchemePSS
+)
+
+type BatchItem struct {
+	Scheme    Scheme
+	Hash      crypto.Hash
+	Digest    []byte
+	Signature []byte
+}
+
+func VerifyBatch(pub *PublicKey, items []BatchItem) error {
+	exp, err := pub.batchOp()
+	if err != nil {
+		return err
+	}
+
+	return forEach(context.Background(), len(items), func(i int) error {
+		item := items[i]
+		return verifyDigest(pub, exp, item.Scheme, item.Hash, item.Digest, item.Signature)
+	})
+}
+
+func (r *RSA) EncryptBatch(ctx context.Context, messages [][]byte) ([][]byte, error) {
+	if r.publicKey == nil {
+		return nil, cryptoErrors.ErrInvalidPublicKey
+	}
+
+	exp, err := r.publicKey.batchOp()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]byte, len(messages))
+	err = forEach(ctx, len(messages), func(i int) error {
+		m := new(big.Int).SetBytes(messages[i])
+		if m.Cmp(r.publicKey.N) >= 0 {
+			return cryptoErrors.ErrInvalidDataLength
+		}
+		results[i] = exp(m).Bytes()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (pub *PublicKey) batchOp() (publicOp, error) {
+	if pub == nil || pub.N == nil || pub.E == nil {
+		return nil, cryptoErrors.ErrInvalidPublicKey
+	}
+
+	m, err := cryptoMath.NewMontgomery(pub.N)
+	if err != nil {
+		return nil, cryptoErrors.Annotate(cryptoErrors.ErrInvalidPublicKey, "modulus: %w")
+	}
+	return func(x *big.Int) *big.Int { return m.Exp(x, pub.E) }, nil
+}
+
+func forEach(ctx context.Context, n int, fn func(i int) error) error {
+	indices := make(chan int)
+	var (
+		mu   sync.Mutex
+		errs cryptoErrors.MultiError
+		wg   sync.WaitGroup
+	)
+
+	for w := 0; w < min(runtime.NumCPU(), n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if err := fn(i); err != nil {
+					mu.Lock()
+					errs.Add(i, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	var cancelled error
+	for i := 0; i < n && cancelled == nil; i++ {
+		select {
+		case <-ctx.Done():
+			cancelled = ctx.Err()
+		case indices <- i:
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if cancelled != nil {
+		return cancelled
+	}
+	return errs.ErrorOrNil()
+}
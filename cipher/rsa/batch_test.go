@@ -0,0 +1,121 @@
+package rsa
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cryptoErrors "github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+func batchItems(t testing.TB, priv *PrivateKey, n int) []BatchItem {
+	items := make([]BatchItem, n)
+	for i := range items {
+		digest := sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+		scheme := Scheme(i % 2)
+
+		var (
+			signature []byte
+			err       error
+		)
+		if scheme == SchemePSS {
+			signature, err = SignPSS(priv, crypto.SHA256, digest[:])
+		} else {
+			signature, err = SignPKCS1v15(priv, crypto.SHA256, digest[:])
+		}
+		require.NoError(t, err)
+
+		items[i] = BatchItem{Scheme: scheme, Hash: crypto.SHA256, Digest: digest[:], Signature: signature}
+	}
+	return items
+}
+
+func TestVerifyBatch(t *testing.T) {
+	priv, _ := newPaddingKey(t)
+	items := batchItems(t, priv, 12)
+
+	require.NoError(t, VerifyBatch(&priv.PublicKey, items))
+	require.NoError(t, VerifyBatch(&priv.PublicKey, nil))
+
+	items[3].Signature[0] ^= 1
+	items[8].Digest = items[9].Digest
+	items[10].Scheme = Scheme(7)
+
+	err := VerifyBatch(&priv.PublicKey, items)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, cryptoErrors.ErrInvalidSignature)
+	assert.ErrorIs(t, err, cryptoErrors.ErrUnknownAlgorithm)
+
+	var multi *cryptoErrors.MultiError
+	require.ErrorAs(t, err, &multi)
+	indices := make([]int, len(multi.Errors))
+	for i, e := range multi.Errors {
+		indices[i] = e.Index
+	}
+	assert.Equal(t, []int{3, 8, 10}, indices)
+
+	err = VerifyBatch(&PublicKey{}, items)
+	assert.ErrorIs(t, err, cryptoErrors.ErrInvalidPublicKey)
+}
+
+func TestEncryptBatch(t *testing.T) {
+	ctx := context.Background()
+	r := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
+	require.NoError(t, r.GenerateKeyPair())
+
+	messages := make([][]byte, 16)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("batched message %d", i))
+	}
+
+	ciphertexts, err := r.EncryptBatch(ctx, messages)
+	require.NoError(t, err)
+	require.Len(t, ciphertexts, len(messages))
+
+	for i, c := range ciphertexts {
+		want, err := r.Encrypt(messages[i])
+		require.NoError(t, err)
+		assert.Equal(t, want, c)
+
+		plaintext, err := r.Decrypt(c)
+		require.NoError(t, err)
+		assert.Equal(t, messages[i], plaintext)
+	}
+
+	tooLarge := append([][]byte{}, messages...)
+	tooLarge[5] = append(r.GetPublicKey().N.Bytes(), 0)
+	_, err = r.EncryptBatch(ctx, tooLarge)
+	assert.ErrorIs(t, err, cryptoErrors.ErrInvalidDataLength)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = r.EncryptBatch(cancelled, messages)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512).EncryptBatch(ctx, messages)
+	assert.ErrorIs(t, err, cryptoErrors.ErrInvalidPublicKey)
+}
+
+func BenchmarkVerify(b *testing.B) {
+	priv, _ := newPaddingKey(b)
+	items := batchItems(b, priv, 64)
+
+	b.Run("individual", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, item := range items {
+				_ = VerifyPKCS1v15(&priv.PublicKey, item.Hash, item.Digest, item.Signature)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = VerifyBatch(&priv.PublicKey, items)
+		}
+	})
+}
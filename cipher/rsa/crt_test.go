@@ -0,0 +1,92 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCRTKey(t testing.TB) *PrivateKey {
+	r := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
+	require.NoError(t, r.GenerateKeyPair())
+	return r.GetPrivateKey()
+}
+
+func TestCRTMatchesSlowPath(t *testing.T) {
+	priv := newCRTKey(t)
+	require.NotNil(t, priv.Dp)
+	require.NotNil(t, priv.Dq)
+	require.NotNil(t, priv.Qinv)
+
+	for i := 0; i < 20; i++ {
+		c, err := rand.Int(rand.Reader, priv.N)
+		require.NoError(t, err)
+		assert.Equal(t, 0, cryptoMath.ModPow(c, priv.D, priv.N).Cmp(priv.privateOp(c)))
+	}
+}
+
+func TestCRTFallsBackOnInconsistentValues(t *testing.T) {
+	priv := newCRTKey(t)
+	message := big.NewInt(0x1234567890)
+	c := cryptoMath.ModPow(message, priv.E, priv.N)
+
+	faulty := *priv
+	faulty.Dp = new(big.Int).Add(priv.Dp, big.NewInt(2))
+	assert.Equal(t, 0, message.Cmp(faulty.privateOp(c)))
+
+	// Keys built without Precompute use the full exponentiation.
+	bare := &PrivateKey{PublicKey: priv.PublicKey, D: priv.D, P: priv.P, Q: priv.Q}
+	assert.Equal(t, 0, message.Cmp(bare.privateOp(c)))
+	bare.Precompute()
+	assert.Equal(t, 0, priv.Qinv.Cmp(bare.Qinv))
+}
+
+func BenchmarkPrivateOp(b *testing.B) {
+	priv := newCRTKey(b)
+	c, err := rand.Int(rand.Reader, priv.N)
+	require.NoError(b, err)
+	bare := &PrivateKey{PublicKey: priv.PublicKey, D: priv.D}
+
+	b.Run("CRT", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			priv.privateOp(c)
+		}
+	})
+	b.Run("Full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bare.privateOp(c)
+		}
+	})
+}
+
+func TestMultiPrimeRSA(t *testing.T) {
+	for _, primes := range []int{3, 4} {
+		r := NewMultiPrimeRSA(cryptoMath.NewMillerRabinTest(), 0.99, 256, primes)
+		require.NoError(t, r.GenerateKeyPair())
+		priv := r.GetPrivateKey()
+		require.Len(t, priv.Extra, primes-2)
+
+		product := new(big.Int).Mul(priv.P, priv.Q)
+		for _, extra := range priv.Extra {
+			product.Mul(product, extra.Prime)
+		}
+		assert.Equal(t, 0, product.Cmp(priv.N))
+
+		for i := 0; i < 10; i++ {
+			c, err := rand.Int(rand.Reader, priv.N)
+			require.NoError(t, err)
+			assert.Equal(t, 0, cryptoMath.ModPow(c, priv.D, priv.N).Cmp(priv.privateOp(c)))
+		}
+
+		message := []byte("multi-prime message")
+		ciphertext, err := r.Encrypt(message)
+		require.NoError(t, err)
+		decrypted, err := r.Decrypt(ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, message, decrypted)
+	}
+}
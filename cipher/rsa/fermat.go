@@ -0,0 +1,81 @@
+package rsa
+
+import "math/big"
+
+// fermatIterations bounds the search of FermatAttack; keys generated by
+// GenerateKeyPair would need vastly more.
+const fermatIterations = 1 << 20
+
+// FermatAttackResult reports a Fermat factorization attempt. P and Q are
+// only set on success; Iterations counts the candidates tried.
+type FermatAttackResult struct {
+	P          *big.Int
+	Q          *big.Int
+	Iterations int
+	Success    bool
+}
+
+// FermatAttack factors N by searching upwards from ceil(sqrt(N)) for an a
+// with a^2 - N a perfect square b^2, so that N = (a+b)(a-b). The number of
+// steps grows with (P-Q)^2 / sqrt(N), which makes keys with close primes
+// fall immediately.
+func FermatAttack(pub *PublicKey) *FermatAttackResult {
+	result := &FermatAttackResult{}
+	if pub == nil || pub.N == nil || pub.N.Cmp(big.NewInt(4)) < 0 {
+		return result
+	}
+	n := pub.N
+	if n.Bit(0) == 0 {
+		result.P = big.NewInt(2)
+		result.Q = new(big.Int).Rsh(n, 1)
+		result.Success = true
+		return result
+	}
+
+	a := new(big.Int).Sqrt(n)
+	if new(big.Int).Mul(a, a).Cmp(n) < 0 {
+		a.Add(a, big.NewInt(1))
+	}
+	b2 := new(big.Int).Mul(a, a)
+	b2.Sub(b2, n)
+
+	b := new(big.Int)
+	for result.Iterations < fermatIterations {
+		result.Iterations++
+		b.Sqrt(b2)
+		if new(big.Int).Mul(b, b).Cmp(b2) == 0 {
+			q := new(big.Int).Sub(a, b)
+			if q.Cmp(big.NewInt(1)) > 0 {
+				result.P = new(big.Int).Add(a, b)
+				result.Q = q
+				result.Success = true
+			}
+			return result
+		}
+
+		// (a+1)^2 - N = a^2 - N + 2a + 1
+		b2.Add(b2, a)
+		b2.Add(b2, a)
+		b2.Add(b2, big.NewInt(1))
+		a.Add(a, big.NewInt(1))
+	}
+	return result
+}
+
+func IsVulnerableToFermat(pub *PublicKey) bool {
+	return FermatAttack(pub).Success
+}
+
+// minPrimeDistance is the smallest |P-Q| GenerateKeyPair accepts for a
+// modulus n = P*Q: 2^(bits/2 - 100) as in FIPS 186-4, and never less than
+// 2*n^(1/4), below which FermatAttack succeeds in its first step.
+func minPrimeDistance(n *big.Int) *big.Int {
+	fourthRoot := new(big.Int).Sqrt(new(big.Int).Sqrt(n))
+	bound := fourthRoot.Lsh(fourthRoot, 1)
+	if shift := n.BitLen()/2 - 100; shift > 0 {
+		if fips := new(big.Int).Lsh(big.NewInt(1), uint(shift)); fips.Cmp(bound) > 0 {
+			bound = fips
+		}
+	}
+	return bound
+}
@@ -0,0 +1,64 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nextPrime(n *big.Int) *big.Int {
+	candidate := new(big.Int).Add(n, big.NewInt(1))
+	for !candidate.ProbablyPrime(20) {
+		candidate.Add(candidate, big.NewInt(1))
+	}
+	return candidate
+}
+
+func TestFermatAttackClosePrimes(t *testing.T) {
+	p, err := rand.Prime(rand.Reader, 256)
+	require.NoError(t, err)
+	q := nextPrime(new(big.Int).Add(p, big.NewInt(1<<20)))
+	n := new(big.Int).Mul(p, q)
+
+	result := FermatAttack(&PublicKey{N: n, E: big.NewInt(65537)})
+	require.True(t, result.Success)
+	assert.Equal(t, 0, result.P.Cmp(q))
+	assert.Equal(t, 0, result.Q.Cmp(p))
+	assert.Equal(t, 1, result.Iterations)
+	assert.True(t, IsVulnerableToFermat(&PublicKey{N: n, E: big.NewInt(65537)}))
+
+	// Primes further apart need more steps but still fall.
+	q = nextPrime(new(big.Int).Add(p, new(big.Int).Lsh(big.NewInt(1), 135)))
+	result = FermatAttack(&PublicKey{N: new(big.Int).Mul(p, q), E: big.NewInt(65537)})
+	require.True(t, result.Success)
+	assert.Greater(t, result.Iterations, 1)
+}
+
+func TestFermatAttackGeneratedKey(t *testing.T) {
+	r := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 256)
+	require.NoError(t, r.GenerateKeyPair())
+	priv := r.GetPrivateKey()
+
+	diff := new(big.Int).Sub(priv.P, priv.Q)
+	assert.Equal(t, 1, diff.Abs(diff).Cmp(minPrimeDistance(priv.N)))
+
+	result := FermatAttack(r.GetPublicKey())
+	assert.False(t, result.Success)
+	assert.Nil(t, result.P)
+	assert.Equal(t, fermatIterations, result.Iterations)
+}
+
+func TestMinPrimeDistance(t *testing.T) {
+	// Small moduli fall back to the 2*N^(1/4) bound.
+	assert.Equal(t, int64(2*10), minPrimeDistance(big.NewInt(10000)).Int64())
+
+	n := new(big.Int).Lsh(big.NewInt(1), 1023)
+	assert.Equal(t, 0, minPrimeDistance(n).Cmp(new(big.Int).Lsh(big.NewInt(1), 412)))
+
+	assert.True(t, FermatAttack(&PublicKey{N: big.NewInt(2 * 101)}).Success)
+	assert.False(t, FermatAttack(nil).Success)
+}
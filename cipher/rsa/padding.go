@@ -0,0 +1,282 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"hash"
+	"math/big"
+
+	cryptoErrors "github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/legacy/md5"
+	"github.com/masterkusok/crypto/hash/legacy/sha1"
+	"github.com/masterkusok/crypto/hash/sha256"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+// ownHashes are the hashes the library implements itself; the others fall
+// back to the standard library.
+var ownHashes = map[crypto.Hash]func() hash.Hash{
+	crypto.MD5:    md5.New,
+	crypto.SHA1:   sha1.New,
+	crypto.SHA256: sha256.New,
+}
+
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.MD5:    {0x30, 0x20, 0x30, 0x0c, 0x06, 0x08, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x0d, 0x02, 0x05, 0x05, 0x00, 0x04, 0x10},
+	crypto.SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+func SignPKCS1v15(priv *PrivateKey, hash crypto.Hash, digest []byte) ([]byte, error) {
+	em, err := pkcs1v15Encode(hash, digest, priv.size())
+	if err != nil {
+		return nil, err
+	}
+	return priv.sign(em), nil
+}
+
+func VerifyPKCS1v15(pub *PublicKey, hash crypto.Hash, digest, signature []byte) error {
+	return verifyPKCS1v15(pub, pub.exp, hash, digest, signature)
+}
+
+func verifyPKCS1v15(pub *PublicKey, exp publicOp, hash crypto.Hash, digest, signature []byte) error {
+	expected, err := pkcs1v15Encode(hash, digest, pub.size())
+	if err != nil {
+		return err
+	}
+
+	em, err := pub.open(exp, signature, pub.size())
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(em, expected) != 1 {
+		return cryptoErrors.ErrInvalidSignature
+	}
+	return nil
+}
+
+func SignPSS(priv *PrivateKey, hash crypto.Hash, digest []byte) ([]byte, error) {
+	if !hashAvailable(hash) || len(digest) != hash.Size() {
+		return nil, cryptoErrors.ErrUnknownAlgorithm
+	}
+
+	emBits := priv.N.BitLen() - 1
+	emLen := (emBits + 7) / 8
+	hLen, sLen := hash.Size(), hash.Size()
+	if emLen < hLen+sLen+2 {
+		return nil, cryptoErrors.ErrInvalidKeySize
+	}
+
+	salt := make([]byte, sLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, cryptoErrors.Annotate(err, "generating salt: %w")
+	}
+	h := pssHash(hash, digest, salt)
+
+	db := make([]byte, emLen-hLen-1)
+	db[len(db)-sLen-1] = 0x01
+	copy(db[len(db)-sLen:], salt)
+	subtle.XORBytes(db, db, mgf1(hash, h, len(db)))
+	db[0] &= 0xFF >> (8*emLen - emBits)
+
+	em := append(append(db, h...), 0xbc)
+	return priv.sign(em), nil
+}
+
+func VerifyPSS(pub *PublicKey, hash crypto.Hash, digest, signature []byte) error {
+	return verifyPSS(pub, pub.exp, hash, digest, signature)
+}
+
+func verifyPSS(pub *PublicKey, exp publicOp, hash crypto.Hash, digest, signature []byte) error {
+	if !hashAvailable(hash) || len(digest) != hash.Size() {
+		return cryptoErrors.ErrUnknownAlgorithm
+	}
+
+	emBits := pub.N.BitLen() - 1
+	emLen := (emBits + 7) / 8
+	hLen, sLen := hash.Size(), hash.Size()
+	if emLen < hLen+sLen+2 {
+		return cryptoErrors.ErrInvalidSignature
+	}
+
+	em, err := pub.open(exp, signature, emLen)
+	if err != nil {
+		return err
+	}
+	if em[emLen-1] != 0xbc || em[0]&^(0xFF>>(8*emLen-emBits)) != 0 {
+		return cryptoErrors.ErrInvalidSignature
+	}
+
+	h := em[emLen-hLen-1 : emLen-1]
+	db := make([]byte, emLen-hLen-1)
+	subtle.XORBytes(db, em[:len(db)], mgf1(hash, h, len(db)))
+	db[0] &= 0xFF >> (8*emLen - emBits)
+
+	separator := len(db) - sLen - 1
+	for _, b := range db[:separator] {
+		if b != 0 {
+			return cryptoErrors.ErrInvalidSignature
+		}
+	}
+	if db[separator] != 0x01 {
+		return cryptoErrors.ErrInvalidSignature
+	}
+
+	if !bytes.Equal(pssHash(hash, digest, db[separator+1:]), h) {
+		return cryptoErrors.ErrInvalidSignature
+	}
+	return nil
+}
+
+func EncryptOAEP(pub *PublicKey, hash crypto.Hash, message, label []byte) ([]byte, error) {
+	if !hashAvailable(hash) {
+		return nil, cryptoErrors.ErrUnknownAlgorithm
+	}
+
+	k, hLen := pub.size(), hash.Size()
+	if len(message) > k-2*hLen-2 {
+		return nil, cryptoErrors.ErrInvalidDataLength
+	}
+
+	db := make([]byte, k-hLen-1)
+	copy(db, digestOf(hash, label))
+	db[len(db)-len(message)-1] = 0x01
+	copy(db[len(db)-len(message):], message)
+
+	seed := make([]byte, hLen)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, cryptoErrors.Annotate(err, "generating seed: %w")
+	}
+
+	subtle.XORBytes(db, db, mgf1(hash, seed, len(db)))
+	subtle.XORBytes(seed, seed, mgf1(hash, db, hLen))
+
+	em := append(append([]byte{0x00}, seed...), db...)
+	c := cryptoMath.ModPow(new(big.Int).SetBytes(em), pub.E, pub.N)
+	return c.FillBytes(make([]byte, k)), nil
+}
+
+func DecryptOAEP(priv *PrivateKey, hash crypto.Hash, ciphertext, label []byte) ([]byte, error) {
+	if !hashAvailable(hash) {
+		return nil, cryptoErrors.ErrUnknownAlgorithm
+	}
+
+	k, hLen := priv.size(), hash.Size()
+	c := new(big.Int).SetBytes(ciphertext)
+	if len(ciphertext) != k || c.Cmp(priv.N) >= 0 || k < 2*hLen+2 {
+		return nil, cryptoErrors.ErrDecryptionFailed
+	}
+
+	em := priv.privateOp(c).FillBytes(make([]byte, k))
+	seed, db := em[1:1+hLen], em[1+hLen:]
+	subtle.XORBytes(seed, seed, mgf1(hash, db, hLen))
+	subtle.XORBytes(db, db, mgf1(hash, seed, len(db)))
+
+	valid := subtle.ConstantTimeByteEq(em[0], 0)
+	valid &= subtle.ConstantTimeCompare(db[:hLen], digestOf(hash, label))
+
+	index, found := 0, 0
+	for i := hLen; i < len(db); i++ {
+		isOne := subtle.ConstantTimeByteEq(db[i], 0x01)
+		isZero := subtle.ConstantTimeByteEq(db[i], 0x00)
+		index = subtle.ConstantTimeSelect(isOne&^found, i, index)
+		found |= isOne
+		valid &= found | isZero
+	}
+
+	if valid&found != 1 {
+		return nil, cryptoErrors.ErrDecryptionFailed
+	}
+	return append([]byte{}, db[index+1:]...), nil
+}
+
+func pkcs1v15Encode(hash crypto.Hash, digest []byte, k int) ([]byte, error) {
+	prefix, ok := digestInfoPrefixes[hash]
+	if !ok || len(digest) != hash.Size() {
+		return nil, cryptoErrors.ErrUnknownAlgorithm
+	}
+
+	tLen := len(prefix) + len(digest)
+	if k < tLen+11 {
+		return nil, cryptoErrors.ErrInvalidKeySize
+	}
+
+	em := make([]byte, k)
+	em[1] = 0x01
+	for i := 2; i < k-tLen-1; i++ {
+		em[i] = 0xFF
+	}
+	copy(em[k-tLen:], prefix)
+	copy(em[k-len(digest):], digest)
+	return em, nil
+}
+
+func pssHash(hash crypto.Hash, digest, salt []byte) []byte {
+	h := newHash(hash)
+	h.Write(make([]byte, 8))
+	h.Write(digest)
+	h.Write(salt)
+	return h.Sum(nil)
+}
+
+func mgf1(hash crypto.Hash, seed []byte, length int) []byte {
+	var mask []byte
+	for counter := uint32(0); len(mask) < length; counter++ {
+		h := newHash(hash)
+		h.Write(seed)
+		h.Write(binary.BigEndian.AppendUint32(nil, counter))
+		mask = h.Sum(mask)
+	}
+	return mask[:length]
+}
+
+func digestOf(hash crypto.Hash, data []byte) []byte {
+	h := newHash(hash)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func hashAvailable(h crypto.Hash) bool {
+	_, ok := ownHashes[h]
+	return ok || h.Available()
+}
+
+func newHash(h crypto.Hash) hash.Hash {
+	if fn, ok := ownHashes[h]; ok {
+		return fn()
+	}
+	return h.New()
+}
+
+func (pub *PublicKey) size() int {
+	return (pub.N.BitLen() + 7) / 8
+}
+
+type publicOp func(x *big.Int) *big.Int
+
+func (pub *PublicKey) exp(x *big.Int) *big.Int {
+	return cryptoMath.ModPow(x, pub.E, pub.N)
+}
+
+func (pub *PublicKey) open(exp publicOp, signature []byte, length int) ([]byte, error) {
+	s := new(big.Int).SetBytes(signature)
+	if len(signature) != pub.size() || s.Cmp(pub.N) >= 0 {
+		return nil, cryptoErrors.ErrInvalidSignature
+	}
+
+	m := exp(s)
+	if m.BitLen() > 8*length {
+		return nil, cryptoErrors.ErrInvalidSignature
+	}
+	return m.FillBytes(make([]byte, length)), nil
+}
+
+func (priv *PrivateKey) sign(em []byte) []byte {
+	s := priv.privateOp(new(big.Int).SetBytes(em))
+	return s.FillBytes(make([]byte, priv.size()))
+}
@@ -0,0 +1,84 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	cryptoErrors "github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPaddingKey(t testing.TB) (*PrivateKey, *stdrsa.PrivateKey) {
+	r := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
+	require.NoError(t, r.GenerateKeyPair())
+	priv := r.GetPrivateKey()
+
+	std := &stdrsa.PrivateKey{
+		PublicKey: stdrsa.PublicKey{N: priv.N, E: int(priv.E.Int64())},
+		D:         priv.D,
+		Primes:    []*big.Int{priv.P, priv.Q},
+	}
+	std.Precompute()
+	return priv, std
+}
+
+func TestPKCS1v15InteropWithStdlib(t *testing.T) {
+	priv, std := newPaddingKey(t)
+	digest := sha256.Sum256([]byte("pkcs1 message"))
+
+	signature, err := SignPKCS1v15(priv, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	require.NoError(t, stdrsa.VerifyPKCS1v15(&std.PublicKey, crypto.SHA256, digest[:], signature))
+
+	stdSignature, err := stdrsa.SignPKCS1v15(nil, std, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	assert.Equal(t, stdSignature, signature)
+
+	digest[0] ^= 1
+	assert.ErrorIs(t, VerifyPKCS1v15(&priv.PublicKey, crypto.SHA256, digest[:], signature), cryptoErrors.ErrInvalidSignature)
+}
+
+func TestPSSInteropWithStdlib(t *testing.T) {
+	priv, std := newPaddingKey(t)
+	digest := sha256.Sum256([]byte("pss message"))
+
+	signature, err := SignPSS(priv, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	require.NoError(t, stdrsa.VerifyPSS(&std.PublicKey, crypto.SHA256, digest[:], signature, &stdrsa.PSSOptions{SaltLength: stdrsa.PSSSaltLengthEqualsHash}))
+
+	stdSignature, err := stdrsa.SignPSS(rand.Reader, std, crypto.SHA256, digest[:], &stdrsa.PSSOptions{SaltLength: stdrsa.PSSSaltLengthEqualsHash})
+	require.NoError(t, err)
+	require.NoError(t, VerifyPSS(&priv.PublicKey, crypto.SHA256, digest[:], stdSignature))
+
+	signature[10] ^= 1
+	assert.ErrorIs(t, VerifyPSS(&priv.PublicKey, crypto.SHA256, digest[:], signature), cryptoErrors.ErrInvalidSignature)
+}
+
+func TestOAEPInteropWithStdlib(t *testing.T) {
+	priv, std := newPaddingKey(t)
+	message := []byte("content encryption key")
+
+	for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256} {
+		ciphertext, err := EncryptOAEP(&priv.PublicKey, hash, message, nil)
+		require.NoError(t, err)
+		plaintext, err := stdrsa.DecryptOAEP(hash.New(), nil, std, ciphertext, nil)
+		require.NoError(t, err)
+		assert.Equal(t, message, plaintext)
+
+		stdCiphertext, err := stdrsa.EncryptOAEP(hash.New(), rand.Reader, &std.PublicKey, message, nil)
+		require.NoError(t, err)
+		plaintext, err = DecryptOAEP(priv, hash, stdCiphertext, nil)
+		require.NoError(t, err)
+		assert.Equal(t, message, plaintext)
+
+		stdCiphertext[5] ^= 1
+		_, err = DecryptOAEP(priv, hash, stdCiphertext, nil)
+		assert.ErrorIs(t, err, cryptoErrors.ErrDecryptionFailed)
+	}
+}
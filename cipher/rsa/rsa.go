@@ -2,17 +2,19 @@ package rsa
 
 import (
 	"context"
-	"crypto/rand"
 	"errors"
+	"io"
 	"math/big"
 	"os"
 
+	cryptoErrors "github.com/masterkusok/crypto/errors"
 	cryptoMath "github.com/masterkusok/crypto/math"
 )
 
 type KeyGenerator struct {
 	minProbability float64
 	bitLength      int
+	primes         int
 	tester         cryptoMath.PrimalityTester
 }
 
@@ -26,6 +28,23 @@ type PrivateKey struct {
 	D *big.Int
 	P *big.Int
 	Q *big.Int
+
+	// CRT values, see Precompute.
+	Dp   *big.Int
+	Dq   *big.Int
+	Qinv *big.Int
+
+	// Extra holds the primes beyond P and Q of a multi-prime key.
+	Extra []*CRTValue
+}
+
+// CRTValue is a prime of a multi-prime key with its CRT values: Exp is D
+// modulo Prime-1 and Coeff the inverse modulo Prime of the product of all
+// the primes before it.
+type CRTValue struct {
+	Prime *big.Int
+	Exp   *big.Int
+	Coeff *big.Int
 }
 
 type RSA struct {
@@ -48,32 +67,73 @@ func NewRSA(
 	}
 }
 
-func (r *RSA) GenerateKeyPair() error {
-	p, err := r.KeyGen.generatePrime()
-	if err != nil {
-		return err
+// NewMultiPrimeRSA generates keys whose modulus is the product of primes
+// primes of bitLength bits each, as described in RFC 8017. More primes make
+// the CRT halves smaller, so the private operations get faster for the same
+// modulus size.
+func NewMultiPrimeRSA(
+	tester cryptoMath.PrimalityTester,
+	minProbability float64,
+	bitLength int,
+	primes int,
+) *RSA {
+	r := NewRSA(tester, minProbability, bitLength)
+	r.KeyGen.primes = primes
+	return r
+}
+
+// NewRSAWithPublicKey wraps a peer's public key. The result can Encrypt
+// and Verify; Decrypt and Sign fail with ErrInvalidPrivateKey, and it has
+// no key generator.
+func NewRSAWithPublicKey(pub *PublicKey) (*RSA, error) {
+	if pub == nil || pub.N == nil || pub.E == nil || pub.N.Sign() <= 0 || pub.E.Sign() <= 0 {
+		return nil, cryptoErrors.ErrInvalidPublicKey
 	}
+	return &RSA{publicKey: &PublicKey{N: pub.N, E: pub.E}}, nil
+}
 
-	q, err := r.KeyGen.generatePrime()
-	if err != nil {
-		return err
+func (r *RSA) GenerateKeyPair() error {
+	return r.GenerateKeyPairFrom(nil)
+}
+
+// GenerateKeyPairFrom draws the primes from source instead of crypto/rand,
+// so a seeded generator such as math/rand/v2's ChaCha8 reproduces the same
+// key on every run. It is meant for tests and exercises; a nil source means
+// crypto/rand.
+func (r *RSA) GenerateKeyPairFrom(source io.Reader) error {
+	if r.KeyGen == nil {
+		return cryptoErrors.Annotate(cryptoErrors.ErrInvalidParameters, "no key generator: %w")
 	}
 
-	n := new(big.Int).Mul(p, q)
+	primes := make([]*big.Int, max(r.KeyGen.primes, 2))
+	for i := range primes {
+		prime, err := r.KeyGen.generatePrime(source)
+		if err != nil {
+			return err
+		}
+		for _, other := range primes[:i] {
+			if prime.Cmp(other) == 0 {
+				return r.GenerateKeyPairFrom(source)
+			}
+		}
+		primes[i] = prime
+	}
+	p, q := primes[0], primes[1]
 
+	// The Fermat check only concerns P and Q: with more primes N^(1/4)
+	// approaches the size of the primes themselves.
 	diff := new(big.Int).Sub(p, q)
 	diff.Abs(diff)
-	nSqrt := new(big.Int).Sqrt(n)
-	nFourthRoot := new(big.Int).Sqrt(nSqrt)
-	minDiff := new(big.Int).Lsh(nFourthRoot, 1)
-	if diff.Cmp(minDiff) <= 0 {
-		return r.GenerateKeyPair()
+	if diff.Cmp(minPrimeDistance(new(big.Int).Mul(p, q))) <= 0 {
+		return r.GenerateKeyPairFrom(source)
 	}
 
-	phi := new(big.Int).Mul(
-		new(big.Int).Sub(p, big.NewInt(1)),
-		new(big.Int).Sub(q, big.NewInt(1)),
-	)
+	n := big.NewInt(1)
+	phi := big.NewInt(1)
+	for _, prime := range primes {
+		n.Mul(n, prime)
+		phi.Mul(phi, new(big.Int).Sub(prime, big.NewInt(1)))
+	}
 
 	e := big.NewInt(65537)
 
@@ -82,12 +142,12 @@ func (r *RSA) GenerateKeyPair() error {
 		return errors.New("failed to compute private exponent")
 	}
 
-	nSqrt = new(big.Int).Sqrt(n)
-	nFourthRoot = new(big.Int).Sqrt(nSqrt)
+	nSqrt := new(big.Int).Sqrt(n)
+	nFourthRoot := new(big.Int).Sqrt(nSqrt)
 	threshold := new(big.Int).Div(nFourthRoot, big.NewInt(3))
 
 	if d.Cmp(threshold) <= 0 {
-		return r.GenerateKeyPair()
+		return r.GenerateKeyPairFrom(source)
 	}
 
 	r.publicKey = &PublicKey{N: n, E: e}
@@ -97,29 +157,25 @@ func (r *RSA) GenerateKeyPair() error {
 		P:         p,
 		Q:         q,
 	}
+	for _, prime := range primes[2:] {
+		r.privateKey.Extra = append(r.privateKey.Extra, &CRTValue{Prime: prime})
+	}
+	r.privateKey.Precompute()
 
 	return nil
 }
 
-func (kg *KeyGenerator) generatePrime() (*big.Int, error) {
-	for {
-		candidate, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(kg.bitLength)))
-		if err != nil {
-			return nil, err
-		}
-
-		candidate.SetBit(candidate, 0, 1)
-		candidate.SetBit(candidate, kg.bitLength-1, 1)
-
-		if kg.tester.IsProbablyPrime(candidate, kg.minProbability) {
-			return candidate, nil
-		}
-	}
+func (kg *KeyGenerator) generatePrime(source io.Reader) (*big.Int, error) {
+	return cryptoMath.GeneratePrime(context.Background(), kg.bitLength, kg.tester, &cryptoMath.PrimeOptions{
+		MinProbability: kg.minProbability,
+		TopTwoBits:     true,
+		Rand:           source,
+	})
 }
 
 func (r *RSA) Encrypt(message []byte) ([]byte, error) {
 	if r.publicKey == nil {
-		return nil, errors.New("no public key available")
+		return nil, cryptoErrors.Annotate(cryptoErrors.ErrInvalidPublicKey, "no public key available: %w")
 	}
 
 	m := new(big.Int).SetBytes(message)
@@ -133,12 +189,11 @@ func (r *RSA) Encrypt(message []byte) ([]byte, error) {
 
 func (r *RSA) Decrypt(ciphertext []byte) ([]byte, error) {
 	if r.privateKey == nil {
-		return nil, errors.New("no private key available")
+		return nil, cryptoErrors.Annotate(cryptoErrors.ErrInvalidPrivateKey, "no private key available: %w")
 	}
 
 	c := new(big.Int).SetBytes(ciphertext)
-	m := cryptoMath.ModPow(c, r.privateKey.D, r.privateKey.N)
-	return m.Bytes(), nil
+	return r.privateKey.privateOp(c).Bytes(), nil
 }
 
 func (r *RSA) GetPublicKey() *PublicKey {
@@ -1,9 +1,12 @@
 package rsa
 
 import (
+	"crypto"
+	"math/rand/v2"
 	"os"
 	"testing"
 
+	cryptoErrors "github.com/masterkusok/crypto/errors"
 	cryptoMath "github.com/masterkusok/crypto/math"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -34,6 +37,23 @@ func TestRSAEncryptDecrypt(t *testing.T) {
 	assert.Equal(t, message, decrypted)
 }
 
+func TestRSAGenerateKeyPairFromSeed(t *testing.T) {
+	seed := [32]byte{0x42}
+
+	first := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 256)
+	require.NoError(t, first.GenerateKeyPairFrom(rand.NewChaCha8(seed)))
+	second := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 256)
+	require.NoError(t, second.GenerateKeyPairFrom(rand.NewChaCha8(seed)))
+
+	assert.Equal(t, 0, first.GetPrivateKey().P.Cmp(second.GetPrivateKey().P))
+	assert.Equal(t, 0, first.GetPrivateKey().Q.Cmp(second.GetPrivateKey().Q))
+	assert.Equal(t, 0, first.GetPrivateKey().D.Cmp(second.GetPrivateKey().D))
+
+	other := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 256)
+	require.NoError(t, other.GenerateKeyPairFrom(rand.NewChaCha8([32]byte{0x43})))
+	assert.NotEqual(t, 0, first.GetPublicKey().N.Cmp(other.GetPublicKey().N))
+}
+
 func TestRSAMultipleKeyGeneration(t *testing.T) {
 	rsa := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
 
@@ -82,10 +102,41 @@ func TestRSANoKeyError(t *testing.T) {
 	rsa := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
 
 	_, err := rsa.Encrypt([]byte("test"))
-	require.Error(t, err)
+	require.ErrorIs(t, err, cryptoErrors.ErrInvalidPublicKey)
 
 	_, err = rsa.Decrypt([]byte("test"))
-	require.Error(t, err)
+	require.ErrorIs(t, err, cryptoErrors.ErrInvalidPrivateKey)
+}
+
+func TestRSAWithPublicKey(t *testing.T) {
+	owner := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
+	require.NoError(t, owner.GenerateKeyPair())
+
+	peer, err := NewRSAWithPublicKey(owner.GetPublicKey())
+	require.NoError(t, err)
+	assert.Nil(t, peer.GetPrivateKey())
+
+	message := []byte("for the key owner only")
+	ciphertext, err := peer.Encrypt(message)
+	require.NoError(t, err)
+	decrypted, err := owner.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, message, decrypted)
+
+	signature, err := owner.Sign(SchemePSS, crypto.SHA256, message)
+	require.NoError(t, err)
+	require.NoError(t, peer.Verify(SchemePSS, crypto.SHA256, message, signature))
+
+	_, err = peer.Decrypt(ciphertext)
+	assert.ErrorIs(t, err, cryptoErrors.ErrInvalidPrivateKey)
+	_, err = peer.Sign(SchemePSS, crypto.SHA256, message)
+	assert.ErrorIs(t, err, cryptoErrors.ErrInvalidPrivateKey)
+	assert.ErrorIs(t, peer.GenerateKeyPair(), cryptoErrors.ErrInvalidParameters)
+
+	_, err = NewRSAWithPublicKey(nil)
+	assert.ErrorIs(t, err, cryptoErrors.ErrInvalidPublicKey)
+	_, err = NewRSAWithPublicKey(&PublicKey{N: owner.GetPublicKey().N})
+	assert.ErrorIs(t, err, cryptoErrors.ErrInvalidPublicKey)
 }
 
 func TestRSAEncryptFile(t *testing.T) {
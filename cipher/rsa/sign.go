@@ -0,0 +1,51 @@
+package rsa
+
+import (
+	"crypto"
+
+	cryptoErrors "github.com/masterkusok/crypto/errors"
+)
+
+// Sign hashes message with the library's own implementation of hash, where
+// there is one, and signs the digest with the private key under scheme.
+func (r *RSA) Sign(scheme Scheme, hash crypto.Hash, message []byte) ([]byte, error) {
+	if r.privateKey == nil {
+		return nil, cryptoErrors.ErrInvalidPrivateKey
+	}
+	if !hashAvailable(hash) {
+		return nil, cryptoErrors.ErrUnknownAlgorithm
+	}
+
+	digest := digestOf(hash, message)
+	switch scheme {
+	case SchemePKCS1v15:
+		return SignPKCS1v15(r.privateKey, hash, digest)
+	case SchemePSS:
+		return SignPSS(r.privateKey, hash, digest)
+	default:
+		return nil, cryptoErrors.ErrUnknownAlgorithm
+	}
+}
+
+// Verify checks a signature produced by Sign and returns
+// ErrInvalidSignature if it does not match message.
+func (r *RSA) Verify(scheme Scheme, hash crypto.Hash, message, signature []byte) error {
+	if r.publicKey == nil {
+		return cryptoErrors.ErrInvalidPublicKey
+	}
+	if !hashAvailable(hash) {
+		return cryptoErrors.ErrUnknownAlgorithm
+	}
+	return verifyDigest(r.publicKey, r.publicKey.exp, scheme, hash, digestOf(hash, message), signature)
+}
+
+func verifyDigest(pub *PublicKey, exp publicOp, scheme Scheme, hash crypto.Hash, digest, signature []byte) error {
+	switch scheme {
+	case SchemePKCS1v15:
+		return verifyPKCS1v15(pub, exp, hash, digest, signature)
+	case SchemePSS:
+		return verifyPSS(pub, exp, hash, digest, signature)
+	default:
+		return cryptoErrors.ErrUnknownAlgorithm
+	}
+}
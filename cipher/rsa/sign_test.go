@@ -0,0 +1,64 @@
+package rsa
+
+import (
+	"crypto"
+	stdrsa "crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"testing"
+
+	cryptoErrors "github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	r := NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
+	require.NoError(t, r.GenerateKeyPair())
+	message := []byte("signed with the library's own hashes")
+
+	for _, scheme := range []Scheme{SchemePKCS1v15, SchemePSS} {
+		for _, hash := range []crypto.Hash{crypto.MD5, crypto.SHA1, crypto.SHA256} {
+			signature, err := r.Sign(scheme, hash, message)
+			require.NoError(t, err)
+			require.NoError(t, r.Verify(scheme, hash, message, signature), "scheme %d hash %v", scheme, hash)
+
+			tampered := append([]byte{}, message...)
+			tampered[0] ^= 1
+			assert.ErrorIs(t, r.Verify(scheme, hash, tampered, signature), cryptoErrors.ErrInvalidSignature)
+			assert.ErrorIs(t, r.Verify(1-scheme, hash, message, signature), cryptoErrors.ErrInvalidSignature)
+		}
+	}
+}
+
+func TestSignMatchesStdlibDigest(t *testing.T) {
+	priv, std := newPaddingKey(t)
+	r := &RSA{privateKey: priv, publicKey: &priv.PublicKey}
+	message := []byte("pkcs1 signatures are deterministic")
+
+	signature, err := r.Sign(SchemePKCS1v15, crypto.SHA256, message)
+	require.NoError(t, err)
+	digest := sha256.Sum256(message)
+	require.NoError(t, stdrsa.VerifyPKCS1v15(&std.PublicKey, crypto.SHA256, digest[:], signature))
+
+	signature, err = r.Sign(SchemePSS, crypto.SHA1, message)
+	require.NoError(t, err)
+	sha1Digest := sha1.Sum(message)
+	require.NoError(t, stdrsa.VerifyPSS(&std.PublicKey, crypto.SHA1, sha1Digest[:], signature, &stdrsa.PSSOptions{SaltLength: stdrsa.PSSSaltLengthEqualsHash}))
+}
+
+func TestSignErrors(t *testing.T) {
+	empty := &RSA{}
+	_, err := empty.Sign(SchemePSS, crypto.SHA256, []byte("m"))
+	assert.ErrorIs(t, err, cryptoErrors.ErrInvalidPrivateKey)
+	assert.ErrorIs(t, empty.Verify(SchemePSS, crypto.SHA256, []byte("m"), nil), cryptoErrors.ErrInvalidPublicKey)
+
+	priv, _ := newPaddingKey(t)
+	r := &RSA{privateKey: priv, publicKey: &priv.PublicKey}
+	_, err = r.Sign(Scheme(7), crypto.SHA256, []byte("m"))
+	assert.ErrorIs(t, err, cryptoErrors.ErrUnknownAlgorithm)
+	_, err = r.Sign(SchemePSS, crypto.Hash(0), []byte("m"))
+	assert.ErrorIs(t, err, cryptoErrors.ErrUnknownAlgorithm)
+	assert.ErrorIs(t, r.Verify(SchemePKCS1v15, crypto.SHA256, []byte("m"), []byte{1, 2, 3}), cryptoErrors.ErrInvalidSignature)
+}
@@ -2,18 +2,27 @@ package rsa
 
 import "math/big"
 
+// Convergent is the fraction Numerator/Denominator obtained by truncating
+// the continued fraction expansion of E/N; for a vulnerable key one of them
+// is k/D.
 type Convergent struct {
 	Numerator   *big.Int
 	Denominator *big.Int
 }
 
+// WienerAttackResult reports a Wiener attack. Convergents always lists
+// the candidates that were tried; D, Phi, P and Q are only set on success.
 type WienerAttackResult struct {
 	D           *big.Int
 	Phi         *big.Int
+	P           *big.Int
+	Q           *big.Int
 	Convergents []Convergent
 	Success     bool
 }
 
+// WienerAttack recovers the private exponent of keys with D < N^(1/4)/3
+// from the continued fraction expansion of E/N, and factors N on the way.
 func WienerAttack(pub *PublicKey) *WienerAttackResult {
 	result := &WienerAttackResult{
 		Convergents: make([]Convergent, 0),
@@ -67,6 +76,8 @@ func WienerAttack(pub *PublicKey) *WienerAttackResult {
 		if new(big.Int).Mul(p, q).Cmp(pub.N) == 0 {
 			result.D = d
 			result.Phi = phi
+			result.P = p
+			result.Q = q
 			result.Success = true
 			return result
 		}
@@ -124,6 +135,13 @@ func continuedFraction(e, n *big.Int) []Convergent {
 	return convergents
 }
 
+// WienerPrivateExponent is WienerAttack reduced to the recovered private
+// exponent.
+func WienerPrivateExponent(pub *PublicKey) (*big.Int, bool) {
+	result := WienerAttack(pub)
+	return result.D, result.Success
+}
+
 func IsVulnerableToWiener(pub *PublicKey) bool {
 	result := WienerAttack(pub)
 	if !result.Success {
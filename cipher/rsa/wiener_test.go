@@ -41,7 +41,13 @@ func TestWienerAttackVulnerable(t *testing.T) {
 	require.True(t, result.Success)
 	assert.Equal(t, 0, result.D.Cmp(d))
 	assert.Equal(t, 0, result.Phi.Cmp(phi))
+	assert.Equal(t, 0, new(big.Int).Mul(result.P, result.Q).Cmp(n))
+	assert.ElementsMatch(t, []int64{p.Int64(), q.Int64()}, []int64{result.P.Int64(), result.Q.Int64()})
 	assert.NotEmpty(t, result.Convergents)
+
+	recovered, ok := WienerPrivateExponent(pub)
+	require.True(t, ok)
+	assert.Equal(t, 0, recovered.Cmp(d))
 }
 
 func TestWienerAttackNotVulnerable(t *testing.T) {
@@ -51,7 +57,11 @@ func TestWienerAttackNotVulnerable(t *testing.T) {
 	result := WienerAttack(rsa.GetPublicKey())
 
 	assert.False(t, result.Success)
+	assert.Nil(t, result.P)
 	assert.NotEmpty(t, result.Convergents)
+
+	_, ok := WienerPrivateExponent(rsa.GetPublicKey())
+	assert.False(t, ok)
 }
 
 func TestIsVulnerableToWiener(t *testing.T) {
@@ -0,0 +1,141 @@
+package salsa20
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	KeySize         = 32
+	NonceSize       = 8
+	XNonceSize      = 24
+	HSalsaInputSize = 16
+	BlockSize       = 64
+)
+
+var sigma = [4]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+
+type Salsa20 struct {
+	state     [16]uint32
+	counter   uint64
+	exhausted bool
+	buf       []byte
+}
+
+func New(key, nonce []byte, counter uint64) (*Salsa20, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	if len(nonce) != NonceSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+
+	c := &Salsa20{counter: counter, state: initialState(key)}
+	c.state[6] = binary.LittleEndian.Uint32(nonce)
+	c.state[7] = binary.LittleEndian.Uint32(nonce[4:])
+	return c, nil
+}
+
+func NewX(key, nonce []byte, counter uint64) (*Salsa20, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	if len(nonce) != XNonceSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+
+	subkey, err := HSalsa20(key, nonce[:HSalsaInputSize])
+	if err != nil {
+		return nil, err
+	}
+	return New(subkey, nonce[HSalsaInputSize:], counter)
+}
+
+func HSalsa20(key, input []byte) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	if len(input) != HSalsaInputSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+
+	x := initialState(key)
+	for i := 0; i < 4; i++ {
+		x[6+i] = binary.LittleEndian.Uint32(input[i*4:])
+	}
+	rounds(&x)
+
+	out := make([]byte, KeySize)
+	for i, word := range []int{0, 5, 10, 15, 6, 7, 8, 9} {
+		binary.LittleEndian.PutUint32(out[i*4:], x[word])
+	}
+	return out, nil
+}
+
+func (c *Salsa20) Block(counter uint64) []byte {
+	x := c.state
+	x[8] = uint32(counter)
+	x[9] = uint32(counter >> 32)
+	initial := x
+
+	rounds(&x)
+
+	out := make([]byte, BlockSize)
+	for i := range x {
+		binary.LittleEndian.PutUint32(out[i*4:], x[i]+initial[i])
+	}
+	return out
+}
+
+func (c *Salsa20) XORKeyStream(dst, src []byte) error {
+	if len(dst) < len(src) {
+		return errors.ErrInvalidDataLength
+	}
+
+	for i := range src {
+		if len(c.buf) == 0 {
+			if c.exhausted {
+				return errors.ErrNonceExhausted
+			}
+			c.buf = c.Block(c.counter)
+			c.counter++
+			c.exhausted = c.counter == 0
+		}
+		dst[i] = src[i] ^ c.buf[0]
+		c.buf = c.buf[1:]
+	}
+
+	return nil
+}
+
+func initialState(key []byte) [16]uint32 {
+	var x [16]uint32
+	x[0], x[5], x[10], x[15] = sigma[0], sigma[1], sigma[2], sigma[3]
+	for i := 0; i < 4; i++ {
+		x[1+i] = binary.LittleEndian.Uint32(key[i*4:])
+		x[11+i] = binary.LittleEndian.Uint32(key[16+i*4:])
+	}
+	return x
+}
+
+func rounds(x *[16]uint32) {
+	for i := 0; i < 10; i++ {
+		quarterRound(x, 0, 4, 8, 12)
+		quarterRound(x, 5, 9, 13, 1)
+		quarterRound(x, 10, 14, 2, 6)
+		quarterRound(x, 15, 3, 7, 11)
+		quarterRound(x, 0, 1, 2, 3)
+		quarterRound(x, 5, 6, 7, 4)
+		quarterRound(x, 10, 11, 8, 9)
+		quarterRound(x, 15, 12, 13, 14)
+	}
+}
+
+func quarterRound(x *[16]uint32, a, b, c, d int) {
+	x[b] ^= bits.RotateLeft32(x[a]+x[d], 7)
+	x[c] ^= bits.RotateLeft32(x[b]+x[a], 9)
+	x[d] ^= bits.RotateLeft32(x[c]+x[b], 13)
+	x[a] ^= bits.RotateLeft32(x[d]+x[c], 18)
+}
@@ -0,0 +1,79 @@
+package salsa20
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockFunction(t *testing.T) {
+	key := make([]byte, KeySize)
+	for i := 0; i < 16; i++ {
+		key[i] = byte(1 + i)
+		key[16+i] = byte(201 + i)
+	}
+	nonce := []byte{101, 102, 103, 104, 105, 106, 107, 108}
+	c, err := New(key, nonce, 0)
+	require.NoError(t, err)
+
+	want := []byte{
+		69, 37, 68, 39, 41, 15, 107, 193, 255, 139, 122, 6, 170, 233, 217, 98,
+		89, 144, 182, 106, 21, 51, 200, 65, 239, 49, 222, 34, 215, 114, 40, 126,
+		104, 197, 7, 225, 197, 153, 31, 2, 102, 78, 76, 176, 84, 245, 246, 184,
+		177, 160, 133, 130, 6, 72, 149, 119, 192, 195, 132, 236, 234, 103, 246, 74,
+	}
+	counter := uint64(109) | 110<<8 | 111<<16 | 112<<24 | 113<<32 | 114<<40 | 115<<48 | 116<<56
+	assert.Equal(t, want, c.Block(counter))
+}
+
+func TestXSalsa20(t *testing.T) {
+	c, err := NewX([]byte("this is 32-byte key for xsalsa20"), []byte("24-byte nonce for xsalsa"), 0)
+	require.NoError(t, err)
+
+	plaintext := []byte("Hello world!")
+	ciphertext := make([]byte, len(plaintext))
+	require.NoError(t, c.XORKeyStream(ciphertext[:5], plaintext[:5]))
+	require.NoError(t, c.XORKeyStream(ciphertext[5:], plaintext[5:]))
+	assert.Equal(t, "002d4513843fc240c401e541", hex.EncodeToString(ciphertext))
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := make([]byte, KeySize)
+	plaintext := make([]byte, 3*BlockSize+7)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	enc, err := New(key, make([]byte, NonceSize), 0)
+	require.NoError(t, err)
+	ciphertext := make([]byte, len(plaintext))
+	require.NoError(t, enc.XORKeyStream(ciphertext, plaintext))
+
+	dec, err := New(key, make([]byte, NonceSize), 0)
+	require.NoError(t, err)
+	decrypted := make([]byte, len(ciphertext))
+	require.NoError(t, dec.XORKeyStream(decrypted, ciphertext))
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestCounterExhausted(t *testing.T) {
+	c, err := New(make([]byte, KeySize), make([]byte, NonceSize), ^uint64(0))
+	require.NoError(t, err)
+
+	buf := make([]byte, BlockSize+1)
+	assert.ErrorIs(t, c.XORKeyStream(buf, buf), errors.ErrNonceExhausted)
+}
+
+func TestInvalidSizes(t *testing.T) {
+	_, err := New(make([]byte, 16), make([]byte, NonceSize), 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+	_, err = New(make([]byte, KeySize), make([]byte, 12), 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+	_, err = NewX(make([]byte, KeySize), make([]byte, NonceSize), 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+	_, err = HSalsa20(make([]byte, KeySize), make([]byte, 8))
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+}
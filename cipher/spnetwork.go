@@ -0,0 +1,146 @@
+package cipher
+
+import (
+	"context"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type SubstitutionLayer interface {
+	Substitute(ctx context.Context, block []byte) ([]byte, error)
+	InverseSubstitute(ctx context.Context, block []byte) ([]byte, error)
+}
+
+type LinearLayer interface {
+	Transform(ctx context.Context, block []byte) ([]byte, error)
+	InverseTransform(ctx context.Context, block []byte) ([]byte, error)
+}
+
+type KeyAddition interface {
+	AddRoundKey(ctx context.Context, block, roundKey []byte) ([]byte, error)
+	RemoveRoundKey(ctx context.Context, block, roundKey []byte) ([]byte, error)
+}
+
+// XORKeyAddition is the key addition used by almost every SP cipher and the
+// default of SPNetwork.
+type XORKeyAddition struct{}
+
+func (XORKeyAddition) AddRoundKey(ctx context.Context, block, roundKey []byte) ([]byte, error) {
+	if len(roundKey) != len(block) {
+		return nil, errors.ErrInvalidKeySize
+	}
+	return xor(block, roundKey), nil
+}
+
+func (a XORKeyAddition) RemoveRoundKey(ctx context.Context, block, roundKey []byte) ([]byte, error) {
+	return a.AddRoundKey(ctx, block, roundKey)
+}
+
+// SPNetwork runs the generic substitution-permutation round loop: a
+// whitening key addition followed by len(RoundKeys)-1 rounds of
+// substitution, linear layer and key addition. OmitFinalLinear drops the
+// linear layer from the last round, as Rijndael does with MixColumns.
+type SPNetwork struct {
+	keyScheduler    KeyScheduler
+	Substitution    SubstitutionLayer
+	Linear          LinearLayer
+	KeyAddition     KeyAddition
+	OmitFinalLinear bool
+	RoundKeys       [][]byte
+	blockSize       int
+}
+
+func NewSPNetwork(keyScheduler KeyScheduler, substitution SubstitutionLayer, linear LinearLayer, blockSize int) *SPNetwork {
+	return &SPNetwork{
+		keyScheduler: keyScheduler,
+		Substitution: substitution,
+		Linear:       linear,
+		KeyAddition:  XORKeyAddition{},
+		blockSize:    blockSize,
+	}
+}
+
+func (s *SPNetwork) SetKey(ctx context.Context, key []byte) error {
+	roundKeys, err := s.keyScheduler.GenerateRoundKeys(ctx, key)
+	if err != nil {
+		return errors.Annotate(err, "failed to generate round keys: %w")
+	}
+	if len(roundKeys) < 2 {
+		return errors.Annotate(errors.ErrInvalidParameters, "SP network needs at least two round keys, got %d: %w", len(roundKeys))
+	}
+
+	s.RoundKeys = roundKeys
+
+	return nil
+}
+
+func (s *SPNetwork) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := s.check(block); err != nil {
+		return nil, err
+	}
+
+	state, err := s.KeyAddition.AddRoundKey(ctx, block, s.RoundKeys[0])
+	if err != nil {
+		return nil, errors.Annotate(err, "key addition failed: %w")
+	}
+
+	last := len(s.RoundKeys) - 1
+	for round := 1; round <= last; round++ {
+		if state, err = s.Substitution.Substitute(ctx, state); err != nil {
+			return nil, errors.Annotate(err, "substitution layer failed: %w")
+		}
+		if round < last || !s.OmitFinalLinear {
+			if state, err = s.Linear.Transform(ctx, state); err != nil {
+				return nil, errors.Annotate(err, "linear layer failed: %w")
+			}
+		}
+		if state, err = s.KeyAddition.AddRoundKey(ctx, state, s.RoundKeys[round]); err != nil {
+			return nil, errors.Annotate(err, "key addition failed: %w")
+		}
+	}
+
+	return state, nil
+}
+
+func (s *SPNetwork) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := s.check(block); err != nil {
+		return nil, err
+	}
+
+	state := block
+	var err error
+	last := len(s.RoundKeys) - 1
+	for round := last; round >= 1; round-- {
+		if state, err = s.KeyAddition.RemoveRoundKey(ctx, state, s.RoundKeys[round]); err != nil {
+			return nil, errors.Annotate(err, "key addition failed: %w")
+		}
+		if round < last || !s.OmitFinalLinear {
+			if state, err = s.Linear.InverseTransform(ctx, state); err != nil {
+				return nil, errors.Annotate(err, "linear layer failed: %w")
+			}
+		}
+		if state, err = s.Substitution.InverseSubstitute(ctx, state); err != nil {
+			return nil, errors.Annotate(err, "substitution layer failed: %w")
+		}
+	}
+
+	state, err = s.KeyAddition.RemoveRoundKey(ctx, state, s.RoundKeys[0])
+	if err != nil {
+		return nil, errors.Annotate(err, "key addition failed: %w")
+	}
+	return state, nil
+}
+
+func (s *SPNetwork) BlockSize() int {
+	return s.blockSize
+}
+
+func (s *SPNetwork) check(block []byte) error {
+	if len(block) != s.blockSize {
+		return errors.ErrInvalidBlockSize
+	}
+	if s.RoundKeys == nil {
+		return errors.ErrInvalidKeySize
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package cipher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+// PRESENT's 4-bit S-box applied to both nibbles of every byte.
+var toySBox = [16]byte{0xC, 0x5, 0x6, 0xB, 0x9, 0x0, 0xA, 0xD, 0x3, 0xE, 0xF, 0x8, 0x4, 0x7, 0x1, 0x2}
+
+type toySubstitution struct{}
+
+func (toySubstitution) Substitute(ctx context.Context, block []byte) ([]byte, error) {
+	out := make([]byte, len(block))
+	for i, v := range block {
+		out[i] = toySBox[v>>4]<<4 | toySBox[v&0x0F]
+	}
+	return out, nil
+}
+
+func (toySubstitution) InverseSubstitute(ctx context.Context, block []byte) ([]byte, error) {
+	var inv [16]byte
+	for i, v := range toySBox {
+		inv[v] = byte(i)
+	}
+	out := make([]byte, len(block))
+	for i, v := range block {
+		out[i] = inv[v>>4]<<4 | inv[v&0x0F]
+	}
+	return out, nil
+}
+
+// toyLinear XORs every byte into its successor, which is invertible by
+// undoing the chain from the end.
+type toyLinear struct{}
+
+func (toyLinear) Transform(ctx context.Context, block []byte) ([]byte, error) {
+	out := append([]byte(nil), block...)
+	for i := 1; i < len(out); i++ {
+		out[i] ^= out[i-1]
+	}
+	return out, nil
+}
+
+func (toyLinear) InverseTransform(ctx context.Context, block []byte) ([]byte, error) {
+	out := append([]byte(nil), block...)
+	for i := len(out) - 1; i >= 1; i-- {
+		out[i] ^= out[i-1]
+	}
+	return out, nil
+}
+
+type toyScheduler struct{ rounds int }
+
+func (s toyScheduler) GenerateRoundKeys(ctx context.Context, key []byte) ([][]byte, error) {
+	if len(key) != 8 {
+		return nil, errors.ErrInvalidKeySize
+	}
+	keys := make([][]byte, s.rounds+1)
+	for r := range keys {
+		keys[r] = make([]byte, len(key))
+		for i := range key {
+			keys[r][i] = key[(i+r)%len(key)] + byte(r)
+		}
+	}
+	return keys, nil
+}
+
+func newToySP(t *testing.T, rounds int) *cipher.SPNetwork {
+	network := cipher.NewSPNetwork(toyScheduler{rounds: rounds}, toySubstitution{}, toyLinear{}, 8)
+	require.NoError(t, network.SetKey(context.Background(), []byte("spnetkey")))
+	return network
+}
+
+func TestSPNetworkRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	plaintext := []byte("8 bytes!")
+
+	for _, omit := range []bool{false, true} {
+		network := newToySP(t, 6)
+		network.OmitFinalLinear = omit
+
+		ciphertext, err := network.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		assert.NotEqual(t, plaintext, ciphertext)
+
+		decrypted, err := network.Decrypt(ctx, ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
+
+func TestSPNetworkOmitFinalLinear(t *testing.T) {
+	ctx := context.Background()
+	plaintext := []byte("8 bytes!")
+
+	full := newToySP(t, 3)
+	want, err := full.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+
+	short := newToySP(t, 3)
+	short.OmitFinalLinear = true
+	got, err := short.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+
+	// Undo the final key addition, reapply the skipped linear layer and the
+	// two outputs must agree.
+	lastKey := full.RoundKeys[3]
+	got, err = cipher.XORKeyAddition{}.RemoveRoundKey(ctx, got, lastKey)
+	require.NoError(t, err)
+	got, err = toyLinear{}.Transform(ctx, got)
+	require.NoError(t, err)
+	got, err = cipher.XORKeyAddition{}.AddRoundKey(ctx, got, lastKey)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestSPNetworkInvalid(t *testing.T) {
+	ctx := context.Background()
+	network := cipher.NewSPNetwork(toyScheduler{rounds: 4}, toySubstitution{}, toyLinear{}, 8)
+
+	_, err := network.Encrypt(ctx, make([]byte, 8))
+	require.ErrorIs(t, err, errors.ErrInvalidKeySize)
+	require.ErrorIs(t, network.SetKey(ctx, make([]byte, 4)), errors.ErrInvalidKeySize)
+
+	require.NoError(t, network.SetKey(ctx, make([]byte, 8)))
+	_, err = network.Decrypt(ctx, make([]byte, 4))
+	require.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+
+	empty := cipher.NewSPNetwork(toyScheduler{rounds: 0}, toySubstitution{}, toyLinear{}, 8)
+	require.ErrorIs(t, empty.SetKey(ctx, make([]byte, 8)), errors.ErrInvalidParameters)
+}
@@ -0,0 +1,111 @@
+package cipher
+
+import (
+	"context"
+	stdcipher "crypto/cipher"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+// StdBlock exposes a keyed BlockCipher as a crypto/cipher.Block so it can be
+// combined with the standard library modes such as GCM or CTR. Like the
+// standard implementations it panics on malformed input, since the
+// crypto/cipher.Block methods cannot return errors.
+type StdBlock struct {
+	cipher BlockCipher
+}
+
+var _ stdcipher.Block = (*StdBlock)(nil)
+
+func NewStdBlock(cipher BlockCipher) *StdBlock {
+	return &StdBlock{cipher: cipher}
+}
+
+func (b *StdBlock) BlockSize() int {
+	return b.cipher.BlockSize()
+}
+
+func (b *StdBlock) Encrypt(dst, src []byte) {
+	b.process(dst, src, b.cipher.Encrypt)
+}
+
+func (b *StdBlock) Decrypt(dst, src []byte) {
+	b.process(dst, src, b.cipher.Decrypt)
+}
+
+func (b *StdBlock) process(dst, src []byte, transform func(context.Context, []byte) ([]byte, error)) {
+	size := b.cipher.BlockSize()
+	if len(src) < size {
+		panic("cipher: input not full block")
+	}
+	if len(dst) < size {
+		panic("cipher: output not full block")
+	}
+
+	out, err := transform(context.Background(), src[:size])
+	if err != nil {
+		panic("cipher: " + err.Error())
+	}
+	copy(dst, out)
+}
+
+// StdBlockCipher adapts the standard library in the other direction: the
+// constructor (for example aes.NewCipher) is invoked from SetKey, so the
+// result can be used wherever this package expects a BlockCipher.
+type StdBlockCipher struct {
+	newBlock func(key []byte) (stdcipher.Block, error)
+	block    stdcipher.Block
+}
+
+func NewStdBlockCipher(newBlock func(key []byte) (stdcipher.Block, error)) *StdBlockCipher {
+	return &StdBlockCipher{newBlock: newBlock}
+}
+
+func (c *StdBlockCipher) SetKey(ctx context.Context, key []byte) error {
+	block, err := c.newBlock(key)
+	if err != nil {
+		return errors.Annotate(errors.ErrInvalidKeySize, "standard library cipher rejected key: %w")
+	}
+
+	c.block = block
+	return nil
+}
+
+func (c *StdBlockCipher) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := c.check(block); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(block))
+	c.block.Encrypt(out, block)
+	return out, nil
+}
+
+func (c *StdBlockCipher) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := c.check(block); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(block))
+	c.block.Decrypt(out, block)
+	return out, nil
+}
+
+// BlockSize reports 0 until a key has been set, because the wrapped
+// constructor only reveals the size once it has produced a block.
+func (c *StdBlockCipher) BlockSize() int {
+	if c.block == nil {
+		return 0
+	}
+	return c.block.BlockSize()
+}
+
+func (c *StdBlockCipher) check(block []byte) error {
+	if c.block == nil {
+		return errors.ErrInvalidKeySize
+	}
+	if len(block) != c.block.BlockSize() {
+		return errors.ErrInvalidBlockSize
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	stdaes "crypto/aes"
+	stdcipher "crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/aes"
+	"github.com/masterkusok/crypto/errors"
+)
+
+var (
+	stdKey   = []byte("0123456789abcdef")
+	stdNonce = []byte("unique nonce")
+)
+
+func TestStdBlockWithGCM(t *testing.T) {
+	local := aes.NewAES128()
+	require.NoError(t, local.SetKey(context.Background(), stdKey))
+	localGCM, err := stdcipher.NewGCM(cipher.NewStdBlock(local))
+	require.NoError(t, err)
+
+	reference, err := stdaes.NewCipher(stdKey)
+	require.NoError(t, err)
+	referenceGCM, err := stdcipher.NewGCM(reference)
+	require.NoError(t, err)
+
+	plaintext := []byte("sealed with the standard library GCM")
+	sealed := localGCM.Seal(nil, stdNonce, plaintext, []byte("header"))
+	assert.Equal(t, referenceGCM.Seal(nil, stdNonce, plaintext, []byte("header")), sealed)
+
+	opened, err := localGCM.Open(nil, stdNonce, sealed, []byte("header"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+}
+
+func TestStdBlockPanicsOnShortInput(t *testing.T) {
+	local := aes.NewAES128()
+	require.NoError(t, local.SetKey(context.Background(), stdKey))
+	block := cipher.NewStdBlock(local)
+
+	assert.Equal(t, 16, block.BlockSize())
+	assert.Panics(t, func() { block.Encrypt(make([]byte, 16), make([]byte, 8)) })
+	assert.Panics(t, func() { block.Decrypt(make([]byte, 8), make([]byte, 16)) })
+}
+
+func TestStdBlockCipherInContext(t *testing.T) {
+	ctx := context.Background()
+	iv := bytes.Repeat([]byte{7}, 16)
+	plaintext := []byte("standard library AES inside a CipherContext")
+
+	std, err := cipher.NewCipherContext(cipher.NewStdBlockCipher(stdaes.NewCipher), stdKey, &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(iv))
+	require.NoError(t, err)
+	local, err := cipher.NewCipherContext(aes.NewAES128(), stdKey, &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(iv))
+	require.NoError(t, err)
+
+	want, err := collect(local.EncryptBytes(ctx, plaintext))
+	require.NoError(t, err)
+	got, err := collect(std.EncryptBytes(ctx, plaintext))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	decrypted, err := collect(std.DecryptBytes(ctx, got))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestStdBlockCipherInvalid(t *testing.T) {
+	ctx := context.Background()
+	c := cipher.NewStdBlockCipher(stdaes.NewCipher)
+
+	assert.Equal(t, 0, c.BlockSize())
+	_, err := c.Encrypt(ctx, make([]byte, 16))
+	require.ErrorIs(t, err, errors.ErrInvalidKeySize)
+	require.ErrorIs(t, c.SetKey(ctx, make([]byte, 5)), errors.ErrInvalidKeySize)
+
+	require.NoError(t, c.SetKey(ctx, stdKey))
+	_, err = c.Decrypt(ctx, make([]byte, 8))
+	require.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
@@ -0,0 +1,216 @@
+package cipher
+
+import (
+	"context"
+	"io"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const streamChunkSize = 1024 * 1024
+
+func (c *CipherContext) EncryptStream(ctx context.Context, r io.Reader, w io.Writer) error {
+	mode, ok := c.mode.(chainingMode)
+	if !ok {
+		return errors.Annotate(errors.ErrInvalidMode, "mode does not support streaming: %w")
+	}
+
+	ctx = c.withSettings(ctx)
+	blockSize := c.cipher.BlockSize()
+	buf := make([]byte, c.streamChunkSize())
+
+	iv, err := c.messageIV()
+	if err != nil {
+		return err
+	}
+	var prefix []byte
+	if c.options.randomIV {
+		prefix = iv
+	}
+	header, iv, err := c.sealStreamHeader(ctx, c.startIV(iv))
+	if err != nil {
+		return err
+	}
+	if prefix = append(prefix, header...); len(prefix) > 0 {
+		if _, err := w.Write(prefix); err != nil {
+			return errors.Annotate(err, "writing output: %w")
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, final, err := readChunk(r, buf)
+		if err != nil {
+			return err
+		}
+
+		data := buf[:n]
+		if final {
+			if data, err = c.pad(data); err != nil {
+				return err
+			}
+		}
+
+		encrypted, err := c.encryptChunk(ctx, data, iv)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(encrypted); err != nil {
+			return errors.Annotate(err, "writing output: %w")
+		}
+
+		if final {
+			return nil
+		}
+		iv = mode.nextIV(blockSize, iv, data, encrypted, true)
+	}
+}
+
+func (c *CipherContext) DecryptStream(ctx context.Context, r io.Reader, w io.Writer) error {
+	mode, ok := c.mode.(chainingMode)
+	if !ok {
+		return errors.Annotate(errors.ErrInvalidMode, "mode does not support streaming: %w")
+	}
+
+	ctx = c.withSettings(ctx)
+	blockSize := c.cipher.BlockSize()
+	current := make([]byte, c.streamChunkSize())
+	next := make([]byte, len(current))
+
+	iv, err := c.readIV(r)
+	if err != nil {
+		return err
+	}
+	if iv, err = c.openStreamHeader(ctx, r, c.startIV(iv)); err != nil {
+		return err
+	}
+
+	n, final, err := readChunk(r, current)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var nextLen int
+		if !final {
+			if nextLen, final, err = readChunk(r, next); err != nil {
+				return err
+			}
+			final = final && nextLen == 0
+		}
+
+		data := current[:n]
+		if len(data)%blockSize != 0 && !(final && c.lengthPreserving()) {
+			return errors.ErrInvalidDataLength
+		}
+
+		decrypted, err := c.decryptChunk(ctx, data, iv)
+		if err != nil {
+			return err
+		}
+		if final {
+			if decrypted, err = c.unpad(decrypted); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(decrypted); err != nil {
+			return errors.Annotate(err, "writing output: %w")
+		}
+
+		if final {
+			return nil
+		}
+		iv = mode.nextIV(blockSize, iv, data, decrypted, false)
+		current, next, n = next, current, nextLen
+		final = n < len(current)
+	}
+}
+
+// readIV returns the configured IV, or reads the message IV that precedes
+// the ciphertext under WithRandomIV.
+func (c *CipherContext) readIV(r io.Reader) ([]byte, error) {
+	if !c.options.randomIV {
+		return c.iv, nil
+	}
+
+	iv := make([]byte, c.cipher.BlockSize())
+	if _, err := io.ReadFull(r, iv); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errors.ErrInvalidDataLength
+		}
+		return nil, errors.Annotate(err, "reading input: %w")
+	}
+	return iv, nil
+}
+
+// sealStreamHeader returns the header block that a headerMode writes once
+// per stream and the state its first chunk starts from. Other modes have
+// no header and start from iv.
+func (c *CipherContext) sealStreamHeader(ctx context.Context, iv []byte) ([]byte, []byte, error) {
+	mode, ok := c.mode.(headerMode)
+	if !ok {
+		return nil, iv, nil
+	}
+	return mode.sealHeader(ctx, c.cipher, iv)
+}
+
+// openStreamHeader reads the header block of a headerMode from r and
+// returns the state of the first chunk; other modes start from iv.
+func (c *CipherContext) openStreamHeader(ctx context.Context, r io.Reader, iv []byte) ([]byte, error) {
+	mode, ok := c.mode.(headerMode)
+	if !ok {
+		return iv, nil
+	}
+
+	header := make([]byte, c.cipher.BlockSize())
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errors.ErrInvalidDataLength
+		}
+		return nil, errors.Annotate(err, "reading input: %w")
+	}
+	return mode.openHeader(ctx, c.cipher, header, iv)
+}
+
+func (c *CipherContext) encryptChunk(ctx context.Context, data, iv []byte) ([]byte, error) {
+	if mode, ok := c.mode.(headerMode); ok {
+		return mode.encryptBody(ctx, c.cipher, data, iv)
+	}
+	return c.mode.Encrypt(ctx, c.cipher, data, iv)
+}
+
+func (c *CipherContext) decryptChunk(ctx context.Context, data, iv []byte) ([]byte, error) {
+	if mode, ok := c.mode.(headerMode); ok {
+		return mode.decryptBody(ctx, c.cipher, data, iv)
+	}
+	return c.mode.Decrypt(ctx, c.cipher, data, iv)
+}
+
+func (c *CipherContext) streamChunkSize() int {
+	size := streamChunkSize
+	if c.options.streamChunkSize > 0 {
+		size = c.options.streamChunkSize
+	}
+
+	blockSize := c.cipher.BlockSize()
+	return max(size/blockSize, 1) * blockSize
+}
+
+func readChunk(r io.Reader, buf []byte) (int, bool, error) {
+	n, err := io.ReadFull(r, buf)
+	switch err {
+	case nil:
+		return n, false, nil
+	case io.EOF, io.ErrUnexpectedEOF:
+		return n, true, nil
+	default:
+		return n, false, errors.Annotate(err, "reading input: %w")
+	}
+}
@@ -0,0 +1,113 @@
+package cipher_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingReader struct {
+	remaining int
+	maxRead   int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+	n := min(len(p), r.remaining)
+	for i := range p[:n] {
+		p[i] = byte(r.remaining - i)
+	}
+	r.remaining -= n
+	r.maxRead = max(r.maxRead, len(p))
+	return n, nil
+}
+
+func streamModes() map[string]cipher.CipherMode {
+	return map[string]cipher.CipherMode{
+		"ECB":         &cipher.ECBMode{},
+		"CBC":         &cipher.CBCMode{},
+		"PCBC":        &cipher.PCBCMode{},
+		"CFB":         &cipher.CFBMode{},
+		"OFB":         &cipher.OFBMode{},
+		"CTR":         &cipher.CTRMode{},
+		"RandomDelta": &cipher.RandomDeltaMode{},
+	}
+}
+
+// randomizedMode reports whether mode draws fresh randomness per message,
+// so that two encryptions of the same input can only be compared by
+// decrypting them.
+func randomizedMode(mode cipher.CipherMode) bool {
+	_, ok := mode.(*cipher.RandomDeltaMode)
+	return ok
+}
+
+func TestStreamMatchesBuffered(t *testing.T) {
+	ctx := context.Background()
+	key := []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}
+	iv := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	for name, mode := range streamModes() {
+		t.Run(name, func(t *testing.T) {
+			for _, size := range []int{0, 13, 64, 64 + 21, 5*64 + 8} {
+				plaintext := make([]byte, size)
+				for i := range plaintext {
+					plaintext[i] = byte(i * 7)
+				}
+
+				cc, err := cipher.NewCipherContext(des.NewDES(), key, mode, cipher.PKCS7, cipher.WithIV(iv), cipher.WithStreamChunkSize(64))
+				require.NoError(t, err)
+
+				var streamed bytes.Buffer
+				require.NoError(t, cc.EncryptStream(ctx, bytes.NewReader(plaintext), &streamed))
+
+				encChan, errChan := cc.EncryptBytes(ctx, plaintext)
+				require.NoError(t, <-errChan)
+				buffered := append([]byte{}, <-encChan...)
+				if randomizedMode(mode) {
+					opened, err := cc.Decrypt(ctx, streamed.Bytes())
+					require.NoError(t, err)
+					assert.Equal(t, plaintext, opened)
+
+					var decrypted bytes.Buffer
+					require.NoError(t, cc.DecryptStream(ctx, bytes.NewReader(buffered), &decrypted))
+					assert.Equal(t, plaintext, append([]byte{}, decrypted.Bytes()...))
+				} else {
+					assert.Equal(t, buffered, append([]byte{}, streamed.Bytes()...))
+				}
+
+				var decrypted bytes.Buffer
+				require.NoError(t, cc.DecryptStream(ctx, bytes.NewReader(streamed.Bytes()), &decrypted))
+				assert.Equal(t, plaintext, append([]byte{}, decrypted.Bytes()...))
+			}
+		})
+	}
+}
+
+func TestStreamBoundedReads(t *testing.T) {
+	ctx := context.Background()
+	cc, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.CTRMode{}, cipher.PKCS7, cipher.WithIV(make([]byte, 8)), cipher.WithStreamChunkSize(256))
+	require.NoError(t, err)
+
+	r := &countingReader{remaining: 40*256 + 5}
+	require.NoError(t, cc.EncryptStream(ctx, r, io.Discard))
+	assert.LessOrEqual(t, r.maxRead, 256)
+}
+
+func TestDecryptStreamRejectsPartialBlock(t *testing.T) {
+	ctx := context.Background()
+	cc, err := cipher.NewCipherContext(des.NewDES(), []byte("8bytekey"), &cipher.CBCMode{}, cipher.PKCS7, cipher.WithIV(make([]byte, 8)))
+	require.NoError(t, err)
+
+	err = cc.DecryptStream(ctx, bytes.NewReader(make([]byte, 13)), io.Discard)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
@@ -0,0 +1,49 @@
+package tea
+
+import (
+	"bytes"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+// Flipping the top bit of both K0 and K1 (or K2 and K3) cancels out in the
+// round function, so every TEA key has three equivalents and the effective
+// key size is 126 bits. Bits 0 and 8 are the top bits of K0/K2 and K1/K3 in
+// big-endian byte order.
+var equivalenceMasks = [][KeySize]byte{
+	{0: 0x80, 4: 0x80},
+	{8: 0x80, 12: 0x80},
+	{0: 0x80, 4: 0x80, 8: 0x80, 12: 0x80},
+}
+
+func EquivalentKeys(key []byte) ([][]byte, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	keys := make([][]byte, len(equivalenceMasks))
+	for i, mask := range equivalenceMasks {
+		keys[i] = make([]byte, KeySize)
+		for j := range mask {
+			keys[i][j] = key[j] ^ mask[j]
+		}
+	}
+	return keys, nil
+}
+
+func Equivalent(a, b []byte) bool {
+	if len(a) != KeySize || len(b) != KeySize {
+		return false
+	}
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	keys, _ := EquivalentKeys(a)
+	for _, key := range keys {
+		if bytes.Equal(key, b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package tea
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+func TestEquivalentKeysEncryptIdentically(t *testing.T) {
+	ctx := context.Background()
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	plaintext := make([]byte, BlockSize)
+	_, err = rand.Read(plaintext)
+	require.NoError(t, err)
+
+	c := NewTEA()
+	require.NoError(t, c.SetKey(ctx, key))
+	want, err := c.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+
+	keys, err := EquivalentKeys(key)
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+
+	for _, other := range keys {
+		assert.NotEqual(t, key, other)
+		assert.True(t, Equivalent(key, other))
+		assert.True(t, Equivalent(other, key))
+
+		require.NoError(t, c.SetKey(ctx, other))
+		got, err := c.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	key := make([]byte, KeySize)
+	assert.True(t, Equivalent(key, key))
+
+	other := make([]byte, KeySize)
+	other[0] = 0x80
+	assert.False(t, Equivalent(key, other))
+	other[15] = 0x01
+	assert.False(t, Equivalent(key, other))
+
+	assert.False(t, Equivalent(key, key[:8]))
+
+	_, err := EquivalentKeys(key[:8])
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
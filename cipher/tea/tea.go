@@ -0,0 +1,93 @@
+package tea
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	BlockSize = 8
+	KeySize   = 16
+
+	numCycles = 32
+	delta     = 0x9E3779B9
+	finalSum  = 0xC6EF3720 // delta * numCycles mod 2^32
+)
+
+type TEA struct {
+	key   [4]uint32
+	keyed bool
+}
+
+func NewTEA() *TEA {
+	return &TEA{}
+}
+
+func (t *TEA) BlockSize() int {
+	return BlockSize
+}
+
+func (t *TEA) SetKey(ctx context.Context, key []byte) error {
+	if len(key) != KeySize {
+		return errors.ErrInvalidKeySize
+	}
+
+	for i := range t.key {
+		t.key[i] = binary.BigEndian.Uint32(key[4*i:])
+	}
+	t.keyed = true
+	return nil
+}
+
+func (t *TEA) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := t.check(block); err != nil {
+		return nil, err
+	}
+
+	v0, v1 := binary.BigEndian.Uint32(block), binary.BigEndian.Uint32(block[4:])
+	k := t.key
+	var sum uint32
+	for i := 0; i < numCycles; i++ {
+		sum += delta
+		v0 += ((v1 << 4) + k[0]) ^ (v1 + sum) ^ ((v1 >> 5) + k[1])
+		v1 += ((v0 << 4) + k[2]) ^ (v0 + sum) ^ ((v0 >> 5) + k[3])
+	}
+
+	return store(v0, v1), nil
+}
+
+func (t *TEA) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := t.check(block); err != nil {
+		return nil, err
+	}
+
+	v0, v1 := binary.BigEndian.Uint32(block), binary.BigEndian.Uint32(block[4:])
+	k := t.key
+	sum := uint32(finalSum)
+	for i := 0; i < numCycles; i++ {
+		v1 -= ((v0 << 4) + k[2]) ^ (v0 + sum) ^ ((v0 >> 5) + k[3])
+		v0 -= ((v1 << 4) + k[0]) ^ (v1 + sum) ^ ((v1 >> 5) + k[1])
+		sum -= delta
+	}
+
+	return store(v0, v1), nil
+}
+
+func (t *TEA) check(block []byte) error {
+	if len(block) != BlockSize {
+		return errors.ErrInvalidBlockSize
+	}
+	if !t.keyed {
+		return errors.ErrInvalidKeySize
+	}
+	return nil
+}
+
+func store(v0, v1 uint32) []byte {
+	out := make([]byte, BlockSize)
+	binary.BigEndian.PutUint32(out, v0)
+	binary.BigEndian.PutUint32(out[4:], v1)
+	return out
+}
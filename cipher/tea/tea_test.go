@@ -0,0 +1,65 @@
+package tea
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+var _ cipher.BlockCipher = (*TEA)(nil)
+
+func TestTEAVector(t *testing.T) {
+	ctx := context.Background()
+	c := NewTEA()
+	require.NoError(t, c.SetKey(ctx, make([]byte, KeySize)))
+
+	ct, err := c.Encrypt(ctx, make([]byte, BlockSize))
+	require.NoError(t, err)
+	assert.Equal(t, "41ea3a0a94baa940", hex.EncodeToString(ct))
+
+	pt, err := c.Decrypt(ctx, ct)
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, BlockSize), pt)
+}
+
+func TestTEARoundTrip(t *testing.T) {
+	ctx := context.Background()
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	c := NewTEA()
+	require.NoError(t, c.SetKey(ctx, key))
+
+	for i := 0; i < 16; i++ {
+		plaintext := make([]byte, BlockSize)
+		_, err := rand.Read(plaintext)
+		require.NoError(t, err)
+
+		ct, err := c.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		pt, err := c.Decrypt(ctx, ct)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, pt)
+	}
+}
+
+func TestTEAErrors(t *testing.T) {
+	ctx := context.Background()
+	c := NewTEA()
+
+	_, err := c.Encrypt(ctx, make([]byte, BlockSize))
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+	assert.ErrorIs(t, c.SetKey(ctx, make([]byte, 8)), errors.ErrInvalidKeySize)
+
+	require.NoError(t, c.SetKey(ctx, make([]byte, KeySize)))
+	_, err = c.Decrypt(ctx, make([]byte, 16))
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
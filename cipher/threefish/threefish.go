@@ -0,0 +1,186 @@
+package threefish
+
+import (
+	"context"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	TweakSize = 16
+
+	numRounds = 72
+	keyParity = 0x1BD11BDAA9FC1A22
+)
+
+var (
+	rotations256 = [8][]int{
+		{14, 16}, {52, 57}, {23, 40}, {5, 37},
+		{25, 33}, {46, 12}, {58, 22}, {32, 32},
+	}
+	rotations512 = [8][]int{
+		{46, 36, 19, 37}, {33, 27, 14, 42}, {17, 49, 36, 39}, {44, 9, 54, 56},
+		{39, 30, 34, 24}, {13, 50, 10, 17}, {25, 29, 39, 43}, {8, 35, 56, 22},
+	}
+	permutation256 = []int{0, 3, 2, 1}
+	permutation512 = []int{2, 1, 4, 7, 6, 5, 0, 3}
+)
+
+type Threefish struct {
+	words       int
+	rotations   [8][]int
+	permutation []int
+	key         []uint64
+}
+
+func NewThreefish(blockSize int) (*Threefish, error) {
+	t := &Threefish{words: blockSize / 8}
+
+	switch blockSize {
+	case 32:
+		t.rotations, t.permutation = rotations256, permutation256
+	case 64:
+		t.rotations, t.permutation = rotations512, permutation512
+	default:
+		return nil, errors.ErrInvalidBlockSize
+	}
+
+	return t, nil
+}
+
+func (t *Threefish) BlockSize() int {
+	return t.words * 8
+}
+
+func (t *Threefish) TweakSize() int {
+	return TweakSize
+}
+
+func (t *Threefish) SetKey(ctx context.Context, key []byte) error {
+	if len(key) != t.BlockSize() {
+		return errors.ErrInvalidKeySize
+	}
+
+	t.key = make([]uint64, t.words+1)
+	t.key[t.words] = keyParity
+	for i := 0; i < t.words; i++ {
+		t.key[i] = binary.LittleEndian.Uint64(key[i*8:])
+		t.key[t.words] ^= t.key[i]
+	}
+
+	return nil
+}
+
+func (t *Threefish) Encrypt(ctx context.Context, block, tweak []byte) ([]byte, error) {
+	v, tw, err := t.prepare(block, tweak)
+	if err != nil {
+		return nil, err
+	}
+
+	f := make([]uint64, t.words)
+	for d := 0; d < numRounds; d++ {
+		if d%4 == 0 {
+			t.addSubkey(v, tw, d/4)
+		}
+
+		rot := t.rotations[d%8]
+		for j := 0; j < t.words/2; j++ {
+			x0, x1 := v[2*j], v[2*j+1]
+			f[2*j] = x0 + x1
+			f[2*j+1] = bits.RotateLeft64(x1, rot[j]) ^ f[2*j]
+		}
+		for i := range v {
+			v[i] = f[t.permutation[i]]
+		}
+	}
+	t.addSubkey(v, tw, numRounds/4)
+
+	return t.encode(v), nil
+}
+
+func (t *Threefish) Decrypt(ctx context.Context, block, tweak []byte) ([]byte, error) {
+	v, tw, err := t.prepare(block, tweak)
+	if err != nil {
+		return nil, err
+	}
+
+	f := make([]uint64, t.words)
+	t.subSubkey(v, tw, numRounds/4)
+	for d := numRounds - 1; d >= 0; d-- {
+		for i := range v {
+			f[t.permutation[i]] = v[i]
+		}
+
+		rot := t.rotations[d%8]
+		for j := 0; j < t.words/2; j++ {
+			y0, y1 := f[2*j], f[2*j+1]
+			x1 := bits.RotateLeft64(y1^y0, -rot[j])
+			v[2*j], v[2*j+1] = y0-x1, x1
+		}
+
+		if d%4 == 0 {
+			t.subSubkey(v, tw, d/4)
+		}
+	}
+
+	return t.encode(v), nil
+}
+
+func (t *Threefish) prepare(block, tweak []byte) ([]uint64, [3]uint64, error) {
+	var tw [3]uint64
+	if t.key == nil {
+		return nil, tw, errors.ErrInvalidKeySize
+	}
+	if len(block) != t.BlockSize() {
+		return nil, tw, errors.ErrInvalidBlockSize
+	}
+	if len(tweak) != TweakSize {
+		return nil, tw, errors.ErrInvalidTweakSize
+	}
+
+	tw[0] = binary.LittleEndian.Uint64(tweak)
+	tw[1] = binary.LittleEndian.Uint64(tweak[8:])
+	tw[2] = tw[0] ^ tw[1]
+
+	v := make([]uint64, t.words)
+	for i := range v {
+		v[i] = binary.LittleEndian.Uint64(block[i*8:])
+	}
+
+	return v, tw, nil
+}
+
+func (t *Threefish) subkey(tw [3]uint64, s, i int) uint64 {
+	k := t.key[(s+i)%(t.words+1)]
+	switch i {
+	case t.words - 3:
+		k += tw[s%3]
+	case t.words - 2:
+		k += tw[(s+1)%3]
+	case t.words - 1:
+		k += uint64(s)
+	}
+	return k
+}
+
+func (t *Threefish) addSubkey(v []uint64, tw [3]uint64, s int) {
+	for i := range v {
+		v[i] += t.subkey(tw, s, i)
+	}
+}
+
+func (t *Threefish) subSubkey(v []uint64, tw [3]uint64, s int) {
+	for i := range v {
+		v[i] -= t.subkey(tw, s, i)
+	}
+}
+
+func (t *Threefish) encode(v []uint64) []byte {
+	out := make([]byte, t.BlockSize())
+	for i, w := range v {
+		binary.LittleEndian.PutUint64(out[i*8:], w)
+	}
+	return out
+}
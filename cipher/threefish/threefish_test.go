@@ -0,0 +1,85 @@
+package threefish
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ cipher.TweakableBlockCipher = (*Threefish)(nil)
+
+func TestKnownAnswer(t *testing.T) {
+	tests := []struct {
+		name      string
+		blockSize int
+		want      string
+	}{
+		{"256", 32, "84da2a1f8beaee947066ae3e3103f1ad536db1f4a1192495116b9f3ce6133fd8"},
+		{"512", 64, "b1a2bbc6ef6025bc40eb3822161f36e375d1bb0aee3186fbd19e47c5d479947b" +
+			"7bc2f8586e35f0cff7e7f03084b0b7b1f1ab3961a580a3e97eb41ea14a6d7bbe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tf, err := NewThreefish(tt.blockSize)
+			require.NoError(t, err)
+			require.NoError(t, tf.SetKey(ctx, make([]byte, tt.blockSize)))
+
+			got, err := tf.Encrypt(ctx, make([]byte, tt.blockSize), make([]byte, TweakSize))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	for _, blockSize := range []int{32, 64} {
+		tf, err := NewThreefish(blockSize)
+		require.NoError(t, err)
+
+		key := make([]byte, blockSize)
+		block := make([]byte, blockSize)
+		for i := range key {
+			key[i] = byte(i)
+			block[i] = byte(0xFF - i)
+		}
+		tweak := []byte("0123456789abcdef")
+		require.NoError(t, tf.SetKey(ctx, key))
+
+		encrypted, err := tf.Encrypt(ctx, block, tweak)
+		require.NoError(t, err)
+		decrypted, err := tf.Decrypt(ctx, encrypted, tweak)
+		require.NoError(t, err)
+		assert.Equal(t, block, decrypted)
+
+		other := []byte("fedcba9876543210")
+		encryptedOther, err := tf.Encrypt(ctx, block, other)
+		require.NoError(t, err)
+		assert.NotEqual(t, encrypted, encryptedOther)
+	}
+}
+
+func TestInvalidSizes(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewThreefish(16)
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+
+	tf, err := NewThreefish(32)
+	require.NoError(t, err)
+	assert.ErrorIs(t, tf.SetKey(ctx, make([]byte, 16)), errors.ErrInvalidKeySize)
+	require.NoError(t, tf.SetKey(ctx, make([]byte, 32)))
+
+	_, err = tf.Encrypt(ctx, make([]byte, 32), make([]byte, 8))
+	assert.ErrorIs(t, err, errors.ErrInvalidTweakSize)
+	_, err = tf.Decrypt(ctx, make([]byte, 31), make([]byte, TweakSize))
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
@@ -14,17 +14,42 @@ const (
 	tripledesKeySize3  = 24
 )
 
+type Mode int
+
+const (
+	// EDE computes E_K3(D_K2(E_K1(P))). With K3 = K1 it is the two-key
+	// variant used by payment HSMs, and with K1 = K2 = K3 it reduces to DES.
+	EDE Mode = iota
+	// EEE computes E_K3(E_K2(E_K1(P))).
+	EEE
+)
+
 type TripleDES struct {
 	des1, des2, des3 cipher.BlockCipher
 	key              []byte
+	mode             Mode
 }
 
 func NewTripleDES() *TripleDES {
+	t, _ := NewTripleDESWithMode(EDE)
+	return t
+}
+
+func NewTripleDESWithMode(mode Mode) (*TripleDES, error) {
+	if mode != EDE && mode != EEE {
+		return nil, errors.ErrInvalidMode
+	}
+
 	return &TripleDES{
 		des1: des.NewDES(),
 		des2: des.NewDES(),
 		des3: des.NewDES(),
-	}
+		mode: mode,
+	}, nil
+}
+
+func (t *TripleDES) Mode() Mode {
+	return t.mode
 }
 
 func (t *TripleDES) SetKey(ctx context.Context, key []byte) error {
@@ -62,17 +87,21 @@ func (t *TripleDES) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
 		return nil, errors.ErrInvalidBlockSize
 	}
 
-	temp, err := t.des3.Encrypt(ctx, block)
+	temp, err := t.des1.Encrypt(ctx, block)
 	if err != nil {
 		return nil, errors.Annotate(err, "first encryption failed: %w")
 	}
 
-	temp, err = t.des2.Decrypt(ctx, temp)
+	if t.mode == EEE {
+		temp, err = t.des2.Encrypt(ctx, temp)
+	} else {
+		temp, err = t.des2.Decrypt(ctx, temp)
+	}
 	if err != nil {
-		return nil, errors.Annotate(err, "decryption failed: %w")
+		return nil, errors.Annotate(err, "second stage failed: %w")
 	}
 
-	return t.des1.Encrypt(ctx, temp)
+	return t.des3.Encrypt(ctx, temp)
 }
 
 func (t *TripleDES) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
@@ -80,17 +109,21 @@ func (t *TripleDES) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
 		return nil, errors.ErrInvalidBlockSize
 	}
 
-	temp, err := t.des1.Decrypt(ctx, block)
+	temp, err := t.des3.Decrypt(ctx, block)
 	if err != nil {
 		return nil, errors.Annotate(err, "first decryption failed: %w")
 	}
 
-	temp, err = t.des2.Encrypt(ctx, temp)
+	if t.mode == EEE {
+		temp, err = t.des2.Decrypt(ctx, temp)
+	} else {
+		temp, err = t.des2.Encrypt(ctx, temp)
+	}
 	if err != nil {
-		return nil, errors.Annotate(err, "encryption failed: %w")
+		return nil, errors.Annotate(err, "second stage failed: %w")
 	}
 
-	return t.des3.Decrypt(ctx, temp)
+	return t.des1.Decrypt(ctx, temp)
 }
 
 func (t *TripleDES) BlockSize() int {
@@ -2,10 +2,13 @@ package tripledes
 
 import (
 	"context"
+	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/errors"
 )
 
 func TestTripleDESThreeKeys(t *testing.T) {
@@ -63,3 +66,66 @@ func TestTripleDESBlockSize(t *testing.T) {
 	tdes := NewTripleDES()
 	assert.Equal(t, 8, tdes.BlockSize())
 }
+
+func TestTripleDESVectors(t *testing.T) {
+	ctx := context.Background()
+	vectors := []struct {
+		mode       Mode
+		key        string
+		ciphertext string
+	}{
+		{EDE, "0123456789abcdef23456789abcdef01456789abcdef0123", "f2afd84ee809e2b5"},
+		{EDE, "0123456789abcdeffedcba9876543210", "1a4d672dca6cb335"},
+		{EEE, "0123456789abcdef23456789abcdef01456789abcdef0123", "738677a86d39d279"},
+		{EEE, "0123456789abcdeffedcba9876543210", "b0f173c4210a7801"},
+		{EDE, "133457799bbcdff1133457799bbcdff1133457799bbcdff1", "85e813540f0ab405"},
+	}
+	plaintext, err := hex.DecodeString("0123456789abcdef")
+	require.NoError(t, err)
+
+	for _, v := range vectors {
+		tdes, err := NewTripleDESWithMode(v.mode)
+		require.NoError(t, err)
+		assert.Equal(t, v.mode, tdes.Mode())
+
+		key, err := hex.DecodeString(v.key)
+		require.NoError(t, err)
+		require.NoError(t, tdes.SetKey(ctx, key))
+
+		encrypted, err := tdes.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, v.ciphertext, hex.EncodeToString(encrypted), "mode %d key %s", v.mode, v.key)
+
+		decrypted, err := tdes.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
+
+func TestTripleDESTwoKeyMatchesExpandedKey(t *testing.T) {
+	ctx := context.Background()
+	k1 := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}
+	k2 := []byte{0xFE, 0xDC, 0xBA, 0x98, 0x76, 0x54, 0x32, 0x10}
+	plaintext := []byte("8 bytes!")
+
+	for _, mode := range []Mode{EDE, EEE} {
+		short, err := NewTripleDESWithMode(mode)
+		require.NoError(t, err)
+		require.NoError(t, short.SetKey(ctx, append(append([]byte{}, k1...), k2...)))
+
+		long, err := NewTripleDESWithMode(mode)
+		require.NoError(t, err)
+		require.NoError(t, long.SetKey(ctx, append(append(append([]byte{}, k1...), k2...), k1...)))
+
+		want, err := long.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		got, err := short.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestTripleDESInvalidMode(t *testing.T) {
+	_, err := NewTripleDESWithMode(Mode(7))
+	assert.ErrorIs(t, err, errors.ErrInvalidMode)
+}
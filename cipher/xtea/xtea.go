@@ -0,0 +1,93 @@
+package xtea
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	BlockSize = 8
+	KeySize   = 16
+
+	numCycles = 32
+	delta     = 0x9E3779B9
+	finalSum  = 0xC6EF3720 // delta * numCycles mod 2^32
+)
+
+type XTEA struct {
+	key   [4]uint32
+	keyed bool
+}
+
+func NewXTEA() *XTEA {
+	return &XTEA{}
+}
+
+func (x *XTEA) BlockSize() int {
+	return BlockSize
+}
+
+func (x *XTEA) SetKey(ctx context.Context, key []byte) error {
+	if len(key) != KeySize {
+		return errors.ErrInvalidKeySize
+	}
+
+	for i := range x.key {
+		x.key[i] = binary.BigEndian.Uint32(key[4*i:])
+	}
+	x.keyed = true
+	return nil
+}
+
+func (x *XTEA) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := x.check(block); err != nil {
+		return nil, err
+	}
+
+	v0, v1 := binary.BigEndian.Uint32(block), binary.BigEndian.Uint32(block[4:])
+	k := x.key
+	var sum uint32
+	for i := 0; i < numCycles; i++ {
+		v0 += (((v1 << 4) ^ (v1 >> 5)) + v1) ^ (sum + k[sum&3])
+		sum += delta
+		v1 += (((v0 << 4) ^ (v0 >> 5)) + v0) ^ (sum + k[(sum>>11)&3])
+	}
+
+	return store(v0, v1), nil
+}
+
+func (x *XTEA) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	if err := x.check(block); err != nil {
+		return nil, err
+	}
+
+	v0, v1 := binary.BigEndian.Uint32(block), binary.BigEndian.Uint32(block[4:])
+	k := x.key
+	sum := uint32(finalSum)
+	for i := 0; i < numCycles; i++ {
+		v1 -= (((v0 << 4) ^ (v0 >> 5)) + v0) ^ (sum + k[(sum>>11)&3])
+		sum -= delta
+		v0 -= (((v1 << 4) ^ (v1 >> 5)) + v1) ^ (sum + k[sum&3])
+	}
+
+	return store(v0, v1), nil
+}
+
+func (x *XTEA) check(block []byte) error {
+	if len(block) != BlockSize {
+		return errors.ErrInvalidBlockSize
+	}
+	if !x.keyed {
+		return errors.ErrInvalidKeySize
+	}
+	return nil
+}
+
+func store(v0, v1 uint32) []byte {
+	out := make([]byte, BlockSize)
+	binary.BigEndian.PutUint32(out, v0)
+	binary.BigEndian.PutUint32(out[4:], v1)
+	return out
+}
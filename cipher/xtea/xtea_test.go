@@ -0,0 +1,77 @@
+package xtea
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+var _ cipher.BlockCipher = (*XTEA)(nil)
+
+func TestXTEAVectors(t *testing.T) {
+	ctx := context.Background()
+	vectors := []struct {
+		key, plaintext, ciphertext string
+	}{
+		{"00000000000000000000000000000000", "0000000000000000", "dee9d4d8f7131ed9"},
+		{"000102030405060708090a0b0c0d0e0f", "4142434445464748", "497df3d072612cb5"},
+	}
+
+	for _, v := range vectors {
+		key, err := hex.DecodeString(v.key)
+		require.NoError(t, err)
+		plaintext, err := hex.DecodeString(v.plaintext)
+		require.NoError(t, err)
+
+		c := NewXTEA()
+		require.NoError(t, c.SetKey(ctx, key))
+
+		ct, err := c.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, v.ciphertext, hex.EncodeToString(ct))
+
+		pt, err := c.Decrypt(ctx, ct)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, pt)
+	}
+}
+
+func TestXTEAHasNoTEAEquivalentKeys(t *testing.T) {
+	ctx := context.Background()
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	plaintext := make([]byte, BlockSize)
+
+	c := NewXTEA()
+	require.NoError(t, c.SetKey(ctx, key))
+	want, err := c.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+
+	key[0] ^= 0x80
+	key[4] ^= 0x80
+	require.NoError(t, c.SetKey(ctx, key))
+	got, err := c.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, want, got)
+}
+
+func TestXTEAErrors(t *testing.T) {
+	ctx := context.Background()
+	c := NewXTEA()
+
+	_, err := c.Encrypt(ctx, make([]byte, BlockSize))
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+	assert.ErrorIs(t, c.SetKey(ctx, make([]byte, 8)), errors.ErrInvalidKeySize)
+
+	require.NoError(t, c.SetKey(ctx, make([]byte, KeySize)))
+	_, err = c.Decrypt(ctx, make([]byte, 16))
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
@@ -0,0 +1,115 @@
+package xts
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	blockSize = 16
+	TweakSize = 16
+)
+
+type XTS struct {
+	data       cipher.BlockCipher
+	tweak      cipher.BlockCipher
+	sectorSize int
+}
+
+var _ cipher.TweakableBlockCipher = (*XTS)(nil)
+
+func NewXTS(data, tweak cipher.BlockCipher, sectorSize int) (*XTS, error) {
+	if data.BlockSize() != blockSize || tweak.BlockSize() != blockSize {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	if sectorSize < blockSize || sectorSize%blockSize != 0 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "sector size must be a multiple of 16: %w")
+	}
+
+	return &XTS{data: data, tweak: tweak, sectorSize: sectorSize}, nil
+}
+
+func SectorTweak(sector uint64) []byte {
+	tweak := make([]byte, TweakSize)
+	binary.LittleEndian.PutUint64(tweak, sector)
+	return tweak
+}
+
+func (x *XTS) BlockSize() int {
+	return x.sectorSize
+}
+
+func (x *XTS) TweakSize() int {
+	return TweakSize
+}
+
+func (x *XTS) SetKey(ctx context.Context, key []byte) error {
+	if len(key)%2 != 0 {
+		return errors.ErrInvalidKeySize
+	}
+
+	half := len(key) / 2
+	if err := x.data.SetKey(ctx, key[:half]); err != nil {
+		return errors.Annotate(err, "failed to set data key: %w")
+	}
+	if err := x.tweak.SetKey(ctx, key[half:]); err != nil {
+		return errors.Annotate(err, "failed to set tweak key: %w")
+	}
+	return nil
+}
+
+func (x *XTS) Encrypt(ctx context.Context, sector, tweak []byte) ([]byte, error) {
+	return x.process(ctx, sector, tweak, x.data.Encrypt)
+}
+
+func (x *XTS) Decrypt(ctx context.Context, sector, tweak []byte) ([]byte, error) {
+	return x.process(ctx, sector, tweak, x.data.Decrypt)
+}
+
+func (x *XTS) process(ctx context.Context, sector, tweak []byte, transform func(context.Context, []byte) ([]byte, error)) ([]byte, error) {
+	if len(sector) != x.sectorSize {
+		return nil, errors.ErrInvalidDataLength
+	}
+	if len(tweak) != TweakSize {
+		return nil, errors.ErrInvalidTweakSize
+	}
+
+	t, err := x.tweak.Encrypt(ctx, tweak)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, len(sector))
+	buf := make([]byte, blockSize)
+	for i := 0; i < len(sector); i += blockSize {
+		for j := range buf {
+			buf[j] = sector[i+j] ^ t[j]
+		}
+
+		out, err := transform(ctx, buf)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range out {
+			result[i+j] = out[j] ^ t[j]
+		}
+		multiplyAlpha(t)
+	}
+
+	return result, nil
+}
+
+func multiplyAlpha(t []byte) {
+	carry := t[blockSize-1] >> 7
+	for i := blockSize - 1; i > 0; i-- {
+		t[i] = t[i]<<1 | t[i-1]>>7
+	}
+	t[0] <<= 1
+	if carry == 1 {
+		t[0] ^= 0x87
+	}
+}
@@ -0,0 +1,72 @@
+package xts
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAESXTS(t *testing.T, sectorSize int) *XTS {
+	data, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	tweak, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+
+	x, err := NewXTS(data, tweak, sectorSize)
+	require.NoError(t, err)
+	return x
+}
+
+func TestIEEE1619Vector(t *testing.T) {
+	ctx := context.Background()
+	x := newAESXTS(t, 32)
+	require.NoError(t, x.SetKey(ctx, make([]byte, 32)))
+
+	encrypted, err := x.Encrypt(ctx, make([]byte, 32), SectorTweak(0))
+	require.NoError(t, err)
+	assert.Equal(t, "917cf69ebd68b2ec9b9fe9a3eadda692cd43d2f59598ed858c02c2652fbf922e", hex.EncodeToString(encrypted))
+
+	decrypted, err := x.Decrypt(ctx, encrypted, SectorTweak(0))
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 32), decrypted)
+}
+
+func TestSectorTweakChangesCiphertext(t *testing.T) {
+	ctx := context.Background()
+	x := newAESXTS(t, 32)
+	require.NoError(t, x.SetKey(ctx, []byte("0123456789abcdeffedcba9876543210")))
+
+	sector := []byte("identical plaintext in 2 sectors")
+	first, err := x.Encrypt(ctx, sector, SectorTweak(1))
+	require.NoError(t, err)
+	second, err := x.Encrypt(ctx, sector, SectorTweak(2))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+}
+
+func TestInvalidParameters(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewXTS(des.NewDES(), des.NewDES(), 512)
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+
+	data, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	_, err = NewXTS(data, data, 100)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	x := newAESXTS(t, 32)
+	assert.ErrorIs(t, x.SetKey(ctx, make([]byte, 31)), errors.ErrInvalidKeySize)
+	require.NoError(t, x.SetKey(ctx, make([]byte, 32)))
+
+	_, err = x.Encrypt(ctx, make([]byte, 16), SectorTweak(0))
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+	_, err = x.Encrypt(ctx, make([]byte, 32), make([]byte, 8))
+	assert.ErrorIs(t, err, errors.ErrInvalidTweakSize)
+}
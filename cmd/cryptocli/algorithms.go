@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"hash"
+	"sort"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/aes"
+	"github.com/masterkusok/crypto/cipher/chacha20"
+	"github.com/masterkusok/crypto/cipher/deal"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/kuznyechik"
+	"github.com/masterkusok/crypto/cipher/loki97"
+	"github.com/masterkusok/crypto/cipher/magma"
+	"github.com/masterkusok/crypto/cipher/mars"
+	"github.com/masterkusok/crypto/cipher/rc6"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/cipher/salsa20"
+	"github.com/masterkusok/crypto/cipher/tea"
+	"github.com/masterkusok/crypto/cipher/threefish"
+	"github.com/masterkusok/crypto/cipher/tripledes"
+	"github.com/masterkusok/crypto/cipher/xtea"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/blake2b"
+	"github.com/masterkusok/crypto/hash/legacy/md5"
+	"github.com/masterkusok/crypto/hash/legacy/sha1"
+	"github.com/masterkusok/crypto/hash/sha256"
+)
+
+const (
+	rijndaelModulus = 0x1B
+
+	rsaAlgorithm   = "rsa"
+	rsaDefaultBits = 2048
+)
+
+// blockAlgorithm builds a block cipher for a key of the given size. The
+// block size is only consulted by the ciphers that support several.
+// Tweakable ciphers get the -tweak flag as a fixed tweak for every block.
+type blockAlgorithm struct {
+	keySize   int
+	newCipher func(keySize, blockSize int, tweak []byte) (cipher.BlockCipher, error)
+}
+
+// aeadAlgorithm is an algorithm from the aead registry. It encrypts the
+// whole input at once and authenticates it together with -aad.
+type aeadAlgorithm struct {
+	keySize int
+}
+
+// streamAlgorithm is a keystream generator keyed with a key and a nonce.
+type streamAlgorithm struct {
+	keySize   int
+	nonceSize int
+	newStream func(key, nonce []byte) (xorKeyStream, error)
+}
+
+type xorKeyStream interface {
+	XORKeyStream(dst, src []byte) error
+}
+
+func fixed(c cipher.BlockCipher) func(int, int, []byte) (cipher.BlockCipher, error) {
+	return func(int, int, []byte) (cipher.BlockCipher, error) {
+		return c, nil
+	}
+}
+
+// fixedTweak turns a tweakable cipher into a plain one by using the same
+// tweak for every block.
+type fixedTweak struct {
+	c     cipher.TweakableBlockCipher
+	tweak []byte
+}
+
+func (f *fixedTweak) SetKey(ctx context.Context, key []byte) error {
+	return f.c.SetKey(ctx, key)
+}
+
+func (f *fixedTweak) Encrypt(ctx context.Context, block []byte) ([]byte, error) {
+	return f.c.Encrypt(ctx, block, f.tweak)
+}
+
+func (f *fixedTweak) Decrypt(ctx context.Context, block []byte) ([]byte, error) {
+	return f.c.Decrypt(ctx, block, f.tweak)
+}
+
+func (f *fixedTweak) BlockSize() int {
+	return f.c.BlockSize()
+}
+
+var blockAlgorithms = map[string]blockAlgorithm{
+	"aes": {32, func(keySize, _ int, _ []byte) (cipher.BlockCipher, error) {
+		switch keySize {
+		case 16:
+			return aes.NewAES128(), nil
+		case 24:
+			return aes.NewAES192(), nil
+		case 32:
+			return aes.NewAES256(), nil
+		}
+		return nil, errors.ErrInvalidKeySize
+	}},
+	"rijndael": {32, func(keySize, blockSize int, _ []byte) (cipher.BlockCipher, error) {
+		return rijndael.NewRijndael(blockSize, keySize, rijndaelModulus)
+	}},
+	"des":        {8, fixed(des.NewDES())},
+	"3des":       {24, fixed(tripledes.NewTripleDES())},
+	"deal":       {32, fixed(deal.NewDEAL())},
+	"kuznyechik": {kuznyechik.KeySize, fixed(kuznyechik.NewKuznyechik())},
+	"magma": {32, func(int, int, []byte) (cipher.BlockCipher, error) {
+		return magma.NewMagma(nil)
+	}},
+	"mars":   {32, fixed(mars.NewMARS())},
+	"loki97": {32, fixed(loki97.NewLOKI97())},
+	"rc6":    {32, fixed(rc6.NewRC6())},
+	"tea":    {tea.KeySize, fixed(tea.NewTEA())},
+	"xtea":   {xtea.KeySize, fixed(xtea.NewXTEA())},
+	// The key size picks Threefish-256 or Threefish-512.
+	"threefish": {64, func(keySize, _ int, tweak []byte) (cipher.BlockCipher, error) {
+		t, err := threefish.NewThreefish(keySize)
+		if err != nil {
+			return nil, err
+		}
+		if tweak == nil {
+			tweak = make([]byte, t.TweakSize())
+		}
+		return &fixedTweak{c: t, tweak: tweak}, nil
+	}},
+}
+
+var aeadAlgorithms = map[string]aeadAlgorithm{
+	aead.AESGCMName:           {32},
+	aead.AESCCMName:           {32},
+	aead.AESEAXName:           {32},
+	aead.AESOCBName:           {32},
+	aead.AESSIVName:           {64},
+	aead.ChaCha20Poly1305Name: {32},
+}
+
+var streamAlgorithms = map[string]streamAlgorithm{
+	"chacha20": {chacha20.KeySize, chacha20.NonceSize, func(key, nonce []byte) (xorKeyStream, error) {
+		return chacha20.New(key, nonce, 0)
+	}},
+	"salsa20": {salsa20.KeySize, salsa20.NonceSize, func(key, nonce []byte) (xorKeyStream, error) {
+		return salsa20.New(key, nonce, 0)
+	}},
+	"xsalsa20": {salsa20.KeySize, salsa20.XNonceSize, func(key, nonce []byte) (xorKeyStream, error) {
+		return salsa20.NewX(key, nonce, 0)
+	}},
+}
+
+var modes = map[string]func(segmentSize int) cipher.CipherMode{
+	"ecb":         func(int) cipher.CipherMode { return &cipher.ECBMode{} },
+	"cbc":         func(int) cipher.CipherMode { return &cipher.CBCMode{} },
+	"pcbc":        func(int) cipher.CipherMode { return &cipher.PCBCMode{} },
+	"cfb":         func(segmentSize int) cipher.CipherMode { return &cipher.CFBMode{SegmentSize: segmentSize} },
+	"ofb":         func(int) cipher.CipherMode { return &cipher.OFBMode{} },
+	"ctr":         func(int) cipher.CipherMode { return &cipher.CTRMode{} },
+	"randomdelta": func(int) cipher.CipherMode { return &cipher.RandomDeltaMode{} },
+}
+
+var paddings = map[string]cipher.PaddingScheme{
+	"zeros":    cipher.Zeros,
+	"ansix923": cipher.ANSIX923,
+	"pkcs7":    cipher.PKCS7,
+	"iso10126": cipher.ISO10126,
+	"iso7816":  cipher.ISO7816,
+	"none":     cipher.NoPadding,
+	"tbc":      cipher.TBC,
+}
+
+var hashes = map[string]func() (hash.Hash, error){
+	"sha256": func() (hash.Hash, error) { return sha256.New(), nil },
+	"sha1":   func() (hash.Hash, error) { return sha1.New(), nil },
+	"md5":    func() (hash.Hash, error) { return md5.New(), nil },
+	"blake2b-256": func() (hash.Hash, error) {
+		return blake2b.New(32, nil)
+	},
+	"blake2b-512": func() (hash.Hash, error) {
+		return blake2b.New(64, nil)
+	},
+}
+
+func names[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,585 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/analysis"
+	"github.com/masterkusok/crypto/cipher"
+	cryptoerrors "github.com/masterkusok/crypto/errors"
+)
+
+// environment carries the standard streams so that run can be tested
+// without touching the process's own.
+type environment struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (e *environment) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+type cryptFlags struct {
+	alg         string
+	mode        string
+	padding     string
+	key         string
+	keyFile     string
+	keyFormat   string
+	iv          string
+	tweak       string
+	aad         string
+	blockSize   int
+	segmentSize int
+	in          string
+	out         string
+	format      string
+}
+
+func (e *environment) crypt(ctx context.Context, args []string, encrypting bool) error {
+	name := "decrypt"
+	if encrypting {
+		name = "encrypt"
+	}
+
+	var f cryptFlags
+	fs := e.flags(name)
+	fs.StringVar(&f.alg, "alg", "aes", "cipher, see list")
+	fs.StringVar(&f.mode, "mode", "cbc", "block cipher mode, see list")
+	fs.StringVar(&f.padding, "padding", "pkcs7", "padding scheme, see list")
+	fs.StringVar(&f.key, "key", "", "key in -key-format")
+	fs.StringVar(&f.keyFile, "key-file", "", "file holding the key in -key-format, or the JSON key for rsa")
+	fs.StringVar(&f.keyFormat, "key-format", "hex", "encoding of the key: raw, hex or base64")
+	fs.StringVar(&f.iv, "iv", "", "IV or nonce in hex; random and stored with the ciphertext if empty")
+	fs.StringVar(&f.tweak, "tweak", "", "tweak in hex for threefish; all zeros if empty")
+	fs.StringVar(&f.aad, "aad", "", "additional authenticated data for the AEAD algorithms")
+	fs.IntVar(&f.blockSize, "block-size", 16, "block size in bytes for rijndael")
+	fs.IntVar(&f.segmentSize, "segment-size", 0, "CFB segment size in bits")
+	fs.StringVar(&f.in, "in", "-", "input file, - for stdin")
+	fs.StringVar(&f.out, "out", "-", "output file, - for stdout")
+	fs.StringVar(&f.format, "format", "raw", "encoding of the ciphertext: raw, hex or base64")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	keyFormat := f.keyFormat
+	if f.alg == rsaAlgorithm {
+		keyFormat = "raw"
+	}
+	key, err := e.readKey(f.key, f.keyFile, keyFormat)
+	if err != nil {
+		return err
+	}
+	var iv []byte
+	if f.iv != "" {
+		if iv, err = hex.DecodeString(f.iv); err != nil {
+			return fmt.Errorf("decoding IV: %w", err)
+		}
+	}
+
+	in, closeIn, err := e.open(f.in)
+	if err != nil {
+		return err
+	}
+	defer closeIn()
+	out, closeOut, err := e.create(f.out)
+	if err != nil {
+		return err
+	}
+
+	if encrypting {
+		err = encodeTo(out, f.format, func(w io.Writer) error {
+			return encrypt(ctx, &f, key, iv, in, w, e.stderr)
+		})
+	} else {
+		var r io.Reader
+		if r, err = decoder(in, f.format); err == nil {
+			err = decrypt(ctx, &f, key, iv, r, out)
+		}
+	}
+	if closeErr := closeOut(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// encrypt writes the ciphertext of r to w. In ECB mode it warns on stderr
+// when the output repeats blocks, since those reveal equal plaintext blocks.
+func encrypt(ctx context.Context, f *cryptFlags, key, iv []byte, r io.Reader, w, stderr io.Writer) error {
+	if f.alg == rsaAlgorithm {
+		return rsaEncrypt(ctx, key, r, w)
+	}
+	if _, ok := aeadAlgorithms[f.alg]; ok {
+		return sealAEAD(ctx, f, key, iv, r, w)
+	}
+	if stream, ok := streamAlgorithms[f.alg]; ok {
+		if iv == nil {
+			nonce, err := randomBytes(stream.nonceSize)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(nonce); err != nil {
+				return err
+			}
+			iv = nonce
+		}
+		return xorStream(stream, key, iv, r, w)
+	}
+
+	c, blockSize, err := newContext(f, key, nil)
+	if err != nil {
+		return err
+	}
+	if iv == nil && f.mode != "ecb" {
+		if iv, err = randomBytes(blockSize); err != nil {
+			return err
+		}
+		if _, err := w.Write(iv); err != nil {
+			return err
+		}
+	}
+	if iv != nil {
+		if c, _, err = newContext(f, key, iv); err != nil {
+			return err
+		}
+	}
+
+	var sample *ecbSample
+	if f.mode == "ecb" {
+		sample = &ecbSample{w: w}
+		w = sample
+	}
+
+	ew, err := c.NewEncryptingWriter(ctx, w)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(ew, r); err != nil {
+		return err
+	}
+	if err := ew.Close(); err != nil {
+		return err
+	}
+
+	if sample != nil {
+		warnRepeatedBlocks(stderr, sample.data, blockSize)
+	}
+	return nil
+}
+
+// maxECBSample bounds the ciphertext kept for the ECB check.
+const maxECBSample = 1 << 20
+
+// ecbSample passes ciphertext through and keeps its first maxECBSample
+// bytes.
+type ecbSample struct {
+	w    io.Writer
+	data []byte
+}
+
+func (s *ecbSample) Write(p []byte) (int, error) {
+	if n := min(len(p), maxECBSample-len(s.data)); n > 0 {
+		s.data = append(s.data, p[:n]...)
+	}
+	return s.w.Write(p)
+}
+
+func warnRepeatedBlocks(stderr io.Writer, ciphertext []byte, blockSize int) {
+	ciphertext = ciphertext[:len(ciphertext)-len(ciphertext)%blockSize]
+	score, err := analysis.DetectECB(ciphertext, blockSize)
+	if err != nil || score <= analysis.ECBThreshold {
+		return
+	}
+	fmt.Fprintf(stderr, "cryptocli: warning: %.0f%% of the ECB ciphertext blocks repeat, so equal plaintext blocks are visible; use another mode\n", 100*score)
+}
+
+func decrypt(ctx context.Context, f *cryptFlags, key, iv []byte, r io.Reader, w io.Writer) error {
+	if f.alg == rsaAlgorithm {
+		return rsaDecrypt(ctx, key, r, w)
+	}
+	if _, ok := aeadAlgorithms[f.alg]; ok {
+		return openAEAD(ctx, f, key, iv, r, w)
+	}
+	if stream, ok := streamAlgorithms[f.alg]; ok {
+		if iv == nil {
+			iv = make([]byte, stream.nonceSize)
+			if _, err := io.ReadFull(r, iv); err != nil {
+				return fmt.Errorf("reading nonce: %w", err)
+			}
+		}
+		return xorStream(stream, key, iv, r, w)
+	}
+
+	c, blockSize, err := newContext(f, key, nil)
+	if err != nil {
+		return err
+	}
+	if iv == nil && f.mode != "ecb" {
+		iv = make([]byte, blockSize)
+		if _, err := io.ReadFull(r, iv); err != nil {
+			return fmt.Errorf("reading IV: %w", err)
+		}
+	}
+	if iv != nil {
+		if c, _, err = newContext(f, key, iv); err != nil {
+			return err
+		}
+	}
+
+	dr, err := c.NewDecryptingReader(ctx, r)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, dr)
+	return err
+}
+
+// newContext builds the cipher context described by f and reports the
+// block size, which is needed to size a random IV.
+func newContext(f *cryptFlags, key, iv []byte) (*cipher.CipherContext, int, error) {
+	algorithm, ok := blockAlgorithms[f.alg]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown algorithm %q", f.alg)
+	}
+	newMode, ok := modes[f.mode]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown mode %q", f.mode)
+	}
+	padding, ok := paddings[f.padding]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown padding %q", f.padding)
+	}
+
+	var tweak []byte
+	if f.tweak != "" {
+		var err error
+		if tweak, err = hex.DecodeString(f.tweak); err != nil {
+			return nil, 0, fmt.Errorf("decoding tweak: %w", err)
+		}
+	}
+
+	block, err := algorithm.newCipher(len(key), f.blockSize, tweak)
+	if err != nil {
+		return nil, 0, err
+	}
+	c, err := cipher.NewCipherContext(block, key, newMode(f.segmentSize), padding, cipher.WithIV(iv))
+	if err != nil {
+		return nil, 0, err
+	}
+	return c, block.BlockSize(), nil
+}
+
+// sealAEAD encrypts the whole input with an AEAD algorithm. Unless -iv is
+// given, the random nonce is written in front of the ciphertext.
+func sealAEAD(ctx context.Context, f *cryptFlags, key, nonce []byte, r io.Reader, w io.Writer) error {
+	a, err := aead.New(f.alg, key)
+	if err != nil {
+		return err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if nonce == nil {
+		if nonce, err = randomBytes(a.NonceSize()); err != nil {
+			return err
+		}
+		if _, err := w.Write(nonce); err != nil {
+			return err
+		}
+	}
+
+	sealed, err := a.Seal(ctx, nonce, plaintext, []byte(f.aad))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sealed)
+	return err
+}
+
+func openAEAD(ctx context.Context, f *cryptFlags, key, nonce []byte, r io.Reader, w io.Writer) error {
+	a, err := aead.New(f.alg, key)
+	if err != nil {
+		return err
+	}
+	if nonce == nil {
+		nonce = make([]byte, a.NonceSize())
+		if _, err := io.ReadFull(r, nonce); err != nil {
+			return fmt.Errorf("reading nonce: %w", err)
+		}
+	}
+	sealed, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := a.Open(ctx, nonce, sealed, []byte(f.aad))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(plaintext)
+	return err
+}
+
+func xorStream(algorithm streamAlgorithm, key, nonce []byte, r io.Reader, w io.Writer) error {
+	stream, err := algorithm.newStream(key, nonce)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := stream.XORKeyStream(buf[:n], buf[:n]); err != nil {
+				return err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (e *environment) keygen(args []string) error {
+	fs := e.flags("keygen")
+	alg := fs.String("alg", "aes", "algorithm to size the key for, see list")
+	size := fs.Int("size", 0, "key size in bytes, or modulus size in bits for rsa, overriding the algorithm default")
+	format := fs.String("format", "hex", "encoding of the key: raw, hex or base64; rsa keys are always JSON")
+	pubOut := fs.String("pub-out", "", "file to write the public half of an rsa key to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *alg == rsaAlgorithm {
+		return e.keygenRSA(*size, *pubOut)
+	}
+
+	n := *size
+	if n == 0 {
+		if algorithm, ok := blockAlgorithms[*alg]; ok {
+			n = algorithm.keySize
+		} else if algorithm, ok := streamAlgorithms[*alg]; ok {
+			n = algorithm.keySize
+		} else if algorithm, ok := aeadAlgorithms[*alg]; ok {
+			n = algorithm.keySize
+		} else {
+			return fmt.Errorf("unknown algorithm %q", *alg)
+		}
+	}
+	if n < 0 {
+		return cryptoerrors.ErrInvalidKeySize
+	}
+
+	key, err := randomBytes(n)
+	if err != nil {
+		return err
+	}
+	return encodeTo(e.stdout, *format, func(w io.Writer) error {
+		_, err := w.Write(key)
+		return err
+	})
+}
+
+// keygenRSA prints a private key and optionally saves its public half, which
+// is all that encryption needs.
+func (e *environment) keygenRSA(bits int, pubOut string) error {
+	if bits == 0 {
+		bits = rsaDefaultBits
+	}
+	priv, err := generateRSAKey(bits)
+	if err != nil {
+		return err
+	}
+
+	if pubOut != "" {
+		if err := saveRSAPublicKey(pubOut, priv); err != nil {
+			return err
+		}
+	}
+	return writeRSAKey(e.stdout, &rsaKeyFile{N: priv.N, E: priv.E, D: priv.D, P: priv.P, Q: priv.Q})
+}
+
+func (e *environment) hash(args []string) error {
+	fs := e.flags("hash")
+	alg := fs.String("alg", "sha256", "hash function, see list")
+	in := fs.String("in", "-", "input file, - for stdin")
+	format := fs.String("format", "hex", "encoding of the digest: raw, hex or base64")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	newHash, ok := hashes[*alg]
+	if !ok {
+		return fmt.Errorf("unknown hash %q", *alg)
+	}
+	h, err := newHash()
+	if err != nil {
+		return err
+	}
+
+	r, closeIn, err := e.open(*in)
+	if err != nil {
+		return err
+	}
+	defer closeIn()
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+
+	return encodeTo(e.stdout, *format, func(w io.Writer) error {
+		_, err := w.Write(h.Sum(nil))
+		return err
+	})
+}
+
+func (e *environment) list() error {
+	w := bufio.NewWriter(e.stdout)
+	fmt.Fprintln(w, "block ciphers: ", strings.Join(names(blockAlgorithms), " "))
+	fmt.Fprintln(w, "stream ciphers:", strings.Join(names(streamAlgorithms), " "))
+	fmt.Fprintln(w, "aead:          ", strings.Join(names(aeadAlgorithms), " "))
+	fmt.Fprintln(w, "public key:    ", rsaAlgorithm)
+	fmt.Fprintln(w, "modes:         ", strings.Join(names(modes), " "))
+	fmt.Fprintln(w, "paddings:      ", strings.Join(names(paddings), " "))
+	fmt.Fprintln(w, "hashes:        ", strings.Join(names(hashes), " "))
+	return w.Flush()
+}
+
+func (e *environment) readKey(value, path, format string) ([]byte, error) {
+	switch {
+	case value != "" && path != "":
+		return nil, fmt.Errorf("-key and -key-file are mutually exclusive")
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading key: %w", err)
+		}
+		if format != "raw" {
+			data = bytes.TrimSpace(data)
+		}
+		value = string(data)
+	case value == "":
+		return nil, fmt.Errorf("a key is required, use -key or -key-file")
+	}
+
+	r, err := decoder(strings.NewReader(value), format)
+	if err != nil {
+		return nil, err
+	}
+	key, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decoding key: %w", err)
+	}
+	return key, nil
+}
+
+func (e *environment) open(path string) (io.Reader, func(), error) {
+	if path == "-" {
+		return e.stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
+
+func (e *environment) create(path string) (io.Writer, func() error, error) {
+	if path == "-" {
+		return e.stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// encodeTo runs write against an encoder for format. The text formats end
+// with a newline so that the output is pleasant in a terminal.
+func encodeTo(w io.Writer, format string, write func(io.Writer) error) error {
+	switch format {
+	case "raw":
+		return write(w)
+	case "hex":
+		if err := write(hex.NewEncoder(w)); err != nil {
+			return err
+		}
+	case "base64":
+		enc := base64.NewEncoder(base64.StdEncoding, w)
+		if err := write(enc); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func decoder(r io.Reader, format string) (io.Reader, error) {
+	switch format {
+	case "raw":
+		return r, nil
+	case "hex":
+		return hex.NewDecoder(&spaceSkipper{r: r}), nil
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// spaceSkipper drops whitespace, such as the trailing newline written by
+// encodeTo, which hex.NewDecoder would reject.
+type spaceSkipper struct {
+	r io.Reader
+}
+
+func (s *spaceSkipper) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			if b != ' ' && b != '\n' && b != '\r' && b != '\t' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+func randomBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("reading random bytes: %w", err)
+	}
+	return buf, nil
+}
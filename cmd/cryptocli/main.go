@@ -0,0 +1,78 @@
+// Command cryptocli exposes the library's ciphers and hashes on the command
+// line:
+//
+//	cryptocli keygen -alg aes > key.hex
+//	cryptocli encrypt -alg aes -mode cbc -key-file key.hex -in plain.txt -out sealed.bin
+//	cryptocli decrypt -alg aes -mode cbc -key-file key.hex -in sealed.bin
+//	cryptocli encrypt -alg aes-gcm -key-file key.hex -aad header -in plain.txt
+//	cryptocli keygen -alg rsa -pub-out rsa.pub > rsa.key
+//	cryptocli encrypt -alg rsa -key-file rsa.pub -in plain.txt -out sealed.bin
+//	cryptocli hash -alg sha256 -in plain.txt
+//	cryptocli list
+//
+// Unless -iv is given, encrypt generates a random IV or nonce and writes it
+// in front of the ciphertext, where decrypt expects to find it. RSA keys
+// are JSON files, and rsa encryption is hybrid: the data is sealed under a
+// fresh session key that is wrapped with RSA-OAEP.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+const usage = `usage: cryptocli <command> [flags]
+
+commands:
+  encrypt   encrypt -in (default stdin) to -out (default stdout)
+  decrypt   decrypt -in to -out
+  keygen    print a random key for -alg, or an rsa key pair
+  hash      print the digest of -in
+  list      list the supported algorithms, modes and paddings
+
+run "cryptocli <command> -h" for the flags of a command
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "cryptocli:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+
+	env := &environment{stdin: stdin, stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "encrypt":
+		return env.crypt(ctx, args[1:], true)
+	case "decrypt":
+		return env.crypt(ctx, args[1:], false)
+	case "keygen":
+		return env.keygen(args[1:])
+	case "hash":
+		return env.hash(args[1:])
+	case "list":
+		return env.list()
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cryptoerrors "github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCLI(t *testing.T, stdin []byte, args ...string) ([]byte, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), args, bytes.NewReader(stdin), &stdout, &stderr)
+	return stdout.Bytes(), err
+}
+
+func keygen(t *testing.T, alg string) string {
+	t.Helper()
+
+	out, err := runCLI(t, nil, "keygen", "-alg", alg)
+	require.NoError(t, err)
+	return strings.TrimSpace(string(out))
+}
+
+func TestRoundTrip(t *testing.T) {
+	plaintext := []byte("exercising the library from the command line")
+
+	tests := []struct {
+		alg, mode, format string
+	}{
+		{"aes", "cbc", "raw"},
+		{"aes", "ctr", "hex"},
+		{"des", "ecb", "base64"},
+		{"3des", "pcbc", "raw"},
+		{"kuznyechik", "ofb", "hex"},
+		{"magma", "cfb", "raw"},
+		{"mars", "randomdelta", "base64"},
+		{"chacha20", "", "hex"},
+		{"xsalsa20", "", "raw"},
+		{"threefish", "cbc", "raw"},
+		{"threefish", "ctr", "hex"},
+		{"aes-gcm", "", "raw"},
+		{"aes-ccm", "", "hex"},
+		{"aes-eax", "", "base64"},
+		{"aes-ocb", "", "raw"},
+		{"aes-siv", "", "raw"},
+		{"chacha20-poly1305", "", "hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg+"/"+tt.mode, func(t *testing.T) {
+			key := keygen(t, tt.alg)
+			args := []string{"-alg", tt.alg, "-key", key, "-format", tt.format}
+			if tt.mode != "" {
+				args = append(args, "-mode", tt.mode)
+			}
+
+			ciphertext, err := runCLI(t, plaintext, append([]string{"encrypt"}, args...)...)
+			require.NoError(t, err)
+			assert.NotContains(t, string(ciphertext), "command line")
+
+			decrypted, err := runCLI(t, ciphertext, append([]string{"decrypt"}, args...)...)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+		})
+	}
+}
+
+func TestFilesAndExplicitIV(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "plain")
+	sealed := filepath.Join(dir, "sealed")
+	keyFile := filepath.Join(dir, "key")
+	require.NoError(t, os.WriteFile(input, []byte("file based"), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, []byte(keygen(t, "aes")+"\n"), 0o600))
+
+	iv := strings.Repeat("ab", 16)
+	_, err := runCLI(t, nil, "encrypt", "-key-file", keyFile, "-iv", iv, "-in", input, "-out", sealed)
+	require.NoError(t, err)
+
+	ciphertext, err := os.ReadFile(sealed)
+	require.NoError(t, err)
+	assert.Len(t, ciphertext, 16, "an explicit IV is not stored with the ciphertext")
+
+	plaintext, err := runCLI(t, nil, "decrypt", "-key-file", keyFile, "-iv", iv, "-in", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("file based"), plaintext)
+}
+
+func TestAEADAuthentication(t *testing.T) {
+	key := keygen(t, "aes-gcm")
+	args := []string{"-alg", "aes-gcm", "-key", key, "-aad", "header"}
+
+	ciphertext, err := runCLI(t, []byte("authenticated"), append([]string{"encrypt"}, args...)...)
+	require.NoError(t, err)
+
+	_, err = runCLI(t, ciphertext, "decrypt", "-alg", "aes-gcm", "-key", key, "-aad", "other")
+	assert.ErrorIs(t, err, cryptoerrors.ErrAuthenticationFailed)
+
+	ciphertext[len(ciphertext)-1] ^= 0x01
+	_, err = runCLI(t, ciphertext, append([]string{"decrypt"}, args...)...)
+	assert.ErrorIs(t, err, cryptoerrors.ErrAuthenticationFailed)
+}
+
+func TestThreefishTweak(t *testing.T) {
+	key := keygen(t, "threefish")
+	args := []string{"encrypt", "-alg", "threefish", "-mode", "ecb", "-key", key}
+
+	zero, err := runCLI(t, []byte("tweaked"), args...)
+	require.NoError(t, err)
+	tweaked, err := runCLI(t, []byte("tweaked"), append(args, "-tweak", strings.Repeat("01", 16))...)
+	require.NoError(t, err)
+	assert.NotEqual(t, zero, tweaked)
+
+	_, err = runCLI(t, []byte("tweaked"), append(args, "-tweak", "01")...)
+	assert.ErrorIs(t, err, cryptoerrors.ErrInvalidTweakSize)
+}
+
+func TestRSA(t *testing.T) {
+	dir := t.TempDir()
+	privFile := filepath.Join(dir, "rsa.key")
+	pubFile := filepath.Join(dir, "rsa.pub")
+
+	priv, err := runCLI(t, nil, "keygen", "-alg", "rsa", "-size", "1024", "-pub-out", pubFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(privFile, priv, 0o600))
+
+	plaintext := []byte("sealed for a public key")
+	ciphertext, err := runCLI(t, plaintext, "encrypt", "-alg", "rsa", "-key-file", pubFile)
+	require.NoError(t, err)
+
+	decrypted, err := runCLI(t, ciphertext, "decrypt", "-alg", "rsa", "-key-file", privFile)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	_, err = runCLI(t, ciphertext, "decrypt", "-alg", "rsa", "-key-file", pubFile)
+	assert.ErrorIs(t, err, cryptoerrors.ErrInvalidPrivateKey)
+
+	_, err = runCLI(t, nil, "keygen", "-alg", "rsa", "-size", "512")
+	assert.ErrorIs(t, err, cryptoerrors.ErrInvalidKeySize)
+}
+
+func TestHash(t *testing.T) {
+	out, err := runCLI(t, []byte("abc"), "hash", "-alg", "sha256")
+	require.NoError(t, err)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n", string(out))
+
+	out, err = runCLI(t, []byte("abc"), "hash", "-alg", "md5", "-format", "raw")
+	require.NoError(t, err)
+	assert.Equal(t, "900150983cd24fb0d6963f7d28e17f72", hex.EncodeToString(out))
+}
+
+func TestKeygenSizes(t *testing.T) {
+	assert.Len(t, keygen(t, "des"), 16)
+	assert.Len(t, keygen(t, "chacha20"), 64)
+	assert.Len(t, keygen(t, "aes-siv"), 128)
+
+	out, err := runCLI(t, nil, "keygen", "-size", "5", "-format", "raw")
+	require.NoError(t, err)
+	assert.Len(t, out, 5)
+}
+
+func TestErrors(t *testing.T) {
+	_, err := runCLI(t, nil, "frobnicate")
+	assert.Error(t, err)
+
+	_, err = runCLI(t, nil, "encrypt", "-alg", "nope", "-key", "00")
+	assert.ErrorContains(t, err, "unknown algorithm")
+
+	_, err = runCLI(t, nil, "encrypt", "-alg", "aes")
+	assert.ErrorContains(t, err, "key is required")
+
+	_, err = runCLI(t, []byte("garbage!"), "decrypt", "-key", keygen(t, "aes"), "-mode", "ecb")
+	assert.Error(t, err)
+}
+
+func TestList(t *testing.T) {
+	out, err := runCLI(t, nil, "list")
+	require.NoError(t, err)
+	for _, name := range []string{"aes", "loki97", "threefish", "chacha20", "aes-gcm", "chacha20-poly1305", "rsa", "randomdelta", "iso10126", "blake2b-512"} {
+		assert.Contains(t, string(out), name)
+	}
+}
+
+func TestECBWarning(t *testing.T) {
+	key := keygen(t, "aes")
+	encryptECB := func(plaintext []byte) string {
+		var stdout, stderr bytes.Buffer
+		err := run(context.Background(), []string{"encrypt", "-key", key, "-mode", "ecb"}, bytes.NewReader(plaintext), &stdout, &stderr)
+		require.NoError(t, err)
+		return stderr.String()
+	}
+
+	assert.Contains(t, encryptECB(bytes.Repeat([]byte("sixteen byte blk"), 4)), "warning")
+	assert.Empty(t, encryptECB([]byte("no block of this text repeats an earlier one")))
+
+	var stderr bytes.Buffer
+	err := run(context.Background(), []string{"encrypt", "-key", key, "-mode", "cbc"}, bytes.NewReader(bytes.Repeat([]byte("sixteen byte blk"), 4)), io.Discard, &stderr)
+	require.NoError(t, err)
+	assert.Empty(t, stderr.String())
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	cryptoerrors "github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hybrid"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+const (
+	rsaMinBits          = 1024
+	rsaPrimeProbability = 0.999999
+)
+
+// rsaKeyFile is the JSON form of an RSA key. A public key file has only N
+// and E, so a private key file can be used for encryption as well.
+type rsaKeyFile struct {
+	N *big.Int `json:"n"`
+	E *big.Int `json:"e"`
+	D *big.Int `json:"d,omitempty"`
+	P *big.Int `json:"p,omitempty"`
+	Q *big.Int `json:"q,omitempty"`
+}
+
+func generateRSAKey(bits int) (*rsa.PrivateKey, error) {
+	if bits < rsaMinBits || bits%2 != 0 {
+		return nil, cryptoerrors.ErrInvalidKeySize
+	}
+
+	r := rsa.NewRSA(cryptoMath.NewMillerRabinTest(), rsaPrimeProbability, bits/2)
+	if err := r.GenerateKeyPair(); err != nil {
+		return nil, fmt.Errorf("generating key pair: %w", err)
+	}
+	return r.GetPrivateKey(), nil
+}
+
+func writeRSAKey(w io.Writer, key *rsaKeyFile) error {
+	data, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func saveRSAPublicKey(path string, priv *rsa.PrivateKey) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeRSAKey(f, &rsaKeyFile{N: priv.N, E: priv.E}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func parseRSAKey(data []byte) (*rsaKeyFile, error) {
+	var key rsaKeyFile
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("decoding RSA key: %w", err)
+	}
+	if key.N == nil || key.E == nil {
+		return nil, cryptoerrors.ErrInvalidPublicKey
+	}
+	return &key, nil
+}
+
+func (k *rsaKeyFile) public() *rsa.PublicKey {
+	return &rsa.PublicKey{N: k.N, E: k.E}
+}
+
+func (k *rsaKeyFile) private() (*rsa.PrivateKey, error) {
+	if k.D == nil || k.P == nil || k.Q == nil {
+		return nil, fmt.Errorf("decryption needs a private key: %w", cryptoerrors.ErrInvalidPrivateKey)
+	}
+
+	priv := &rsa.PrivateKey{PublicKey: *k.public(), D: k.D, P: k.P, Q: k.Q}
+	priv.Precompute()
+	return priv, nil
+}
+
+func rsaEncrypt(ctx context.Context, keyData []byte, r io.Reader, w io.Writer) error {
+	key, err := parseRSAKey(keyData)
+	if err != nil {
+		return err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := hybrid.Seal(ctx, key.public(), plaintext, nil)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sealed)
+	return err
+}
+
+func rsaDecrypt(ctx context.Context, keyData []byte, r io.Reader, w io.Writer) error {
+	key, err := parseRSAKey(keyData)
+	if err != nil {
+		return err
+	}
+	priv, err := key.private()
+	if err != nil {
+		return err
+	}
+	sealed, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := hybrid.Open(ctx, priv, sealed)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(plaintext)
+	return err
+}
@@ -0,0 +1,189 @@
+package cryptofs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	ChunkSize = 64 * 1024
+
+	counterSize    = 4
+	minNonceSize   = counterSize + 1 + 4
+	maxFieldLength = 255
+)
+
+// Every chunk is authenticated with the file header and the file's path
+// inside the FS, so the content of one file does not open under another
+// name.
+var contentMagic = []byte("MKCFS002")
+
+type contentHeader struct {
+	aead   string
+	keyID  string
+	prefix []byte
+	raw    []byte
+}
+
+func encryptContent(ctx context.Context, aeadName string, keys KeyStore, name string, plaintext []byte, chunkSize int) ([]byte, error) {
+	id, key, err := keys.NewKey()
+	if err != nil {
+		return nil, errors.Annotate(err, "creating data key: %w")
+	}
+	if len(aeadName) > maxFieldLength || len(id) > maxFieldLength {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	cipher, err := aead.New(aeadName, key)
+	if err != nil {
+		return nil, err
+	}
+	if cipher.NonceSize() < minNonceSize {
+		return nil, errors.ErrInvalidNonceSize
+	}
+
+	prefix := make([]byte, cipher.NonceSize()-counterSize-1)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, errors.Annotate(err, "generating nonce prefix: %w")
+	}
+
+	out := append([]byte{}, contentMagic...)
+	out = append(out, byte(len(aeadName)))
+	out = append(out, aeadName...)
+	out = append(out, byte(len(id)))
+	out = append(out, id...)
+	out = append(out, prefix...)
+	ad := contentAD(out, name)
+
+	for counter := uint32(0); ; counter++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n := min(chunkSize, len(plaintext))
+		final := n == len(plaintext)
+		sealed, err := cipher.Seal(ctx, chunkNonce(prefix, counter, final), plaintext[:n], ad)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sealed...)
+		plaintext = plaintext[n:]
+
+		if final {
+			return out, nil
+		}
+	}
+}
+
+func decryptContent(ctx context.Context, keys KeyStore, name string, data []byte, chunkSize int) ([]byte, error) {
+	header, cipher, err := openHeader(keys, data)
+	if err != nil {
+		return nil, err
+	}
+
+	ad := contentAD(header.raw, name)
+	body := data[len(header.raw):]
+	sealedSize := chunkSize + cipher.Overhead()
+	var plaintext []byte
+	for counter := uint32(0); ; counter++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n := min(sealedSize, len(body))
+		final := n < sealedSize || n == len(body)
+		opened, err := cipher.Open(ctx, chunkNonce(header.prefix, counter, final), body[:n], ad)
+		if err != nil {
+			return nil, err
+		}
+		plaintext = append(plaintext, opened...)
+		body = body[n:]
+
+		if final {
+			return plaintext, nil
+		}
+	}
+}
+
+func contentSize(keys KeyStore, r io.Reader, fileSize int64, chunkSize int) (int64, error) {
+	buf := make([]byte, len(contentMagic)+2*(1+maxFieldLength)+maxFieldLength)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, errors.Annotate(err, "reading header: %w")
+	}
+
+	header, cipher, err := openHeader(keys, buf[:n])
+	if err != nil {
+		return 0, err
+	}
+
+	body := fileSize - int64(len(header.raw))
+	sealedSize := int64(chunkSize + cipher.Overhead())
+	chunks := body/sealedSize + 1
+	if body > 0 && body%sealedSize == 0 {
+		chunks--
+	}
+	return body - chunks*int64(cipher.Overhead()), nil
+}
+
+func openHeader(keys KeyStore, data []byte) (*contentHeader, aead.AEAD, error) {
+	if len(data) < len(contentMagic) || string(data[:len(contentMagic)]) != string(contentMagic) {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	rest := data[len(contentMagic):]
+	aeadName, rest, ok := readField(rest)
+	if !ok {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+	keyID, rest, ok := readField(rest)
+	if !ok {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	key, err := keys.Key(string(keyID))
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "loading data key: %w")
+	}
+	cipher, err := aead.New(string(aeadName), key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prefixSize := cipher.NonceSize() - counterSize - 1
+	if prefixSize < 0 || len(rest) < prefixSize {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	headerSize := len(data) - len(rest) + prefixSize
+	return &contentHeader{
+		aead:   string(aeadName),
+		keyID:  string(keyID),
+		prefix: rest[:prefixSize],
+		raw:    data[:headerSize],
+	}, cipher, nil
+}
+
+func contentAD(header []byte, name string) []byte {
+	return append(append([]byte{}, header...), name...)
+}
+
+func readField(data []byte) ([]byte, []byte, bool) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return nil, nil, false
+	}
+	return data[1 : 1+int(data[0])], data[1+int(data[0]):], true
+}
+
+func chunkNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := binary.BigEndian.AppendUint32(append([]byte{}, prefix...), counter)
+	if final {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
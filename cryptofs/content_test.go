@@ -0,0 +1,72 @@
+package cryptofs
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hdkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeys(t *testing.T) *DerivedKeys {
+	master, err := hdkey.NewMaster([]byte("cryptofs test master secret"))
+	require.NoError(t, err)
+	keys, err := NewDerivedKeys(master)
+	require.NoError(t, err)
+	return keys
+}
+
+func TestContentRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	keys := newKeys(t)
+	const chunkSize = 16
+
+	for _, size := range []int{0, 1, 15, 16, 17, 32, 100} {
+		plaintext := bytes.Repeat([]byte{0xA5}, size)
+
+		encrypted, err := encryptContent(ctx, aead.ChaCha20Poly1305Name, keys, "dir/file", plaintext, chunkSize)
+		require.NoError(t, err)
+
+		decrypted, err := decryptContent(ctx, keys, "dir/file", encrypted, chunkSize)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, append([]byte{}, decrypted...), size)
+
+		n, err := contentSize(keys, bytes.NewReader(encrypted), int64(len(encrypted)), chunkSize)
+		require.NoError(t, err)
+		assert.Equal(t, int64(size), n)
+	}
+}
+
+func TestContentDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	keys := newKeys(t)
+	const chunkSize = 16
+
+	encrypted, err := encryptContent(ctx, aead.ChaCha20Poly1305Name, keys, "dir/file", bytes.Repeat([]byte("x"), 40), chunkSize)
+	require.NoError(t, err)
+	sealedChunk := chunkSize + 16
+
+	truncated := encrypted[:len(encrypted)-(40-2*chunkSize)-16]
+	_, err = decryptContent(ctx, keys, "dir/file", truncated, chunkSize)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	header := len(encrypted) - 40 - 3*16
+	reordered := append([]byte{}, encrypted[:header]...)
+	reordered = append(reordered, encrypted[header+sealedChunk:header+2*sealedChunk]...)
+	reordered = append(reordered, encrypted[header:header+sealedChunk]...)
+	reordered = append(reordered, encrypted[header+2*sealedChunk:]...)
+	_, err = decryptContent(ctx, keys, "dir/file", reordered, chunkSize)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	flipped := append([]byte{}, encrypted...)
+	flipped[len(contentMagic)+2] ^= 1
+	_, err = decryptContent(ctx, keys, "dir/file", flipped, chunkSize)
+	assert.Error(t, err)
+
+	_, err = decryptContent(ctx, keys, "dir/file", []byte("not a cryptofs file"), chunkSize)
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
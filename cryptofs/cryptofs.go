@@ -0,0 +1,392 @@
+package cryptofs
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Options struct {
+	AEAD         string
+	EncryptNames bool
+}
+
+func DefaultOptions() Options {
+	return Options{AEAD: aead.ChaCha20Poly1305Name, EncryptNames: true}
+}
+
+type WritableFS interface {
+	fs.FS
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	Mkdir(name string, perm fs.FileMode) error
+	Remove(name string) error
+}
+
+type FS struct {
+	dir       string
+	keys      KeyStore
+	aead      string
+	names     aead.AEAD
+	chunkSize int
+}
+
+var (
+	_ WritableFS    = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+)
+
+func New(dir string, keys KeyStore, opts *Options) (*FS, error) {
+	options := DefaultOptions()
+	if opts != nil {
+		options = *opts
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Annotate(err, "opening root: %w")
+	}
+	if !info.IsDir() {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "%s is not a directory: %w", dir)
+	}
+
+	f := &FS{dir: dir, keys: keys, aead: options.AEAD, chunkSize: ChunkSize}
+	if options.EncryptNames {
+		key, err := keys.Key(NameKeyID)
+		if err != nil {
+			return nil, errors.Annotate(err, "loading filename key: %w")
+		}
+		if f.names, err = aead.New(aead.AESSIVName, key); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (f *FS) Open(name string) (fs.File, error) {
+	real, info, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return &dir{fs: f, name: name, real: real, info: info}, nil
+	}
+
+	data, err := f.readContent(name, real)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &file{Reader: bytes.NewReader(data), info: &fileInfo{FileInfo: info, name: path.Base(name), size: int64(len(data))}}, nil
+}
+
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	real, info, err := f.stat("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errors.Annotate(errors.ErrInvalidParameters, "is a directory: %w")}
+	}
+
+	data, err := f.readContent(name, real)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	real, info, err := f.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.fileInfo(real, path.Base(name), info)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return fi, nil
+}
+
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	real, info, err := f.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.Annotate(errors.ErrInvalidParameters, "not a directory: %w")}
+	}
+
+	entries, err := f.readDir(name, real)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return entries, nil
+}
+
+func (f *FS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	real, err := f.writablePath("writefile", name)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := encryptContent(context.Background(), f.aead, f.keys, name, data, f.chunkSize)
+	if err != nil {
+		return &fs.PathError{Op: "writefile", Path: name, Err: err}
+	}
+
+	tmp := real + ".partial"
+	if err := os.WriteFile(tmp, encrypted, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, real); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (f *FS) Mkdir(name string, perm fs.FileMode) error {
+	real, err := f.writablePath("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.Mkdir(real, perm)
+}
+
+func (f *FS) Remove(name string) error {
+	real, err := f.writablePath("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(real)
+}
+
+func (f *FS) stat(op, name string) (string, fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	real, err := f.realPath(name)
+	if err != nil {
+		return "", nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		return "", nil, &fs.PathError{Op: op, Path: name, Err: unwrapPathError(err)}
+	}
+	return real, info, nil
+}
+
+func (f *FS) writablePath(op, name string) (string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	real, err := f.realPath(name)
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return real, nil
+}
+
+func (f *FS) realPath(name string) (string, error) {
+	if name == "." {
+		return f.dir, nil
+	}
+
+	parent := "."
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		encrypted, err := f.encryptName(parent, part)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = encrypted
+		parent = path.Join(parent, part)
+	}
+	return filepath.Join(f.dir, filepath.Join(parts...)), nil
+}
+
+func (f *FS) encryptName(parent, name string) (string, error) {
+	if f.names == nil {
+		return name, nil
+	}
+
+	sealed, err := f.names.Seal(context.Background(), nil, []byte(name), []byte(parent))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (f *FS) decryptName(parent, name string) (string, error) {
+	if f.names == nil {
+		return name, nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(name)
+	if err != nil {
+		return "", errors.Annotate(errors.ErrInvalidFormat, "decoding name: %w")
+	}
+	plain, err := f.names.Open(context.Background(), nil, sealed, []byte(parent))
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func (f *FS) readContent(name, real string) ([]byte, error) {
+	data, err := os.ReadFile(real)
+	if err != nil {
+		return nil, unwrapPathError(err)
+	}
+	return decryptContent(context.Background(), f.keys, name, data, f.chunkSize)
+}
+
+func (f *FS) readDir(name, real string) ([]fs.DirEntry, error) {
+	raw, err := os.ReadDir(real)
+	if err != nil {
+		return nil, unwrapPathError(err)
+	}
+
+	entries := make([]fs.DirEntry, 0, len(raw))
+	for _, entry := range raw {
+		if strings.HasSuffix(entry.Name(), ".partial") {
+			continue
+		}
+
+		plain, err := f.decryptName(name, entry.Name())
+		if err != nil {
+			return nil, errors.Annotate(err, "decrypting name %q: %w", entry.Name())
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		fi, err := f.fileInfo(filepath.Join(real, entry.Name()), plain, info)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(fi))
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+func (f *FS) fileInfo(real, name string, info fs.FileInfo) (fs.FileInfo, error) {
+	fi := &fileInfo{FileInfo: info, name: name, size: info.Size()}
+	if info.IsDir() {
+		return fi, nil
+	}
+
+	file, err := os.Open(real)
+	if err != nil {
+		return nil, unwrapPathError(err)
+	}
+	defer file.Close()
+
+	if fi.size, err = contentSize(f.keys, file, info.Size(), f.chunkSize); err != nil {
+		return nil, err
+	}
+	return fi, nil
+}
+
+func unwrapPathError(err error) error {
+	if pathErr, ok := err.(*fs.PathError); ok {
+		return pathErr.Err
+	}
+	return err
+}
+
+type fileInfo struct {
+	fs.FileInfo
+	name string
+	size int64
+}
+
+func (i *fileInfo) Name() string {
+	return i.name
+}
+
+func (i *fileInfo) Size() int64 {
+	return i.size
+}
+
+func (i *fileInfo) Sys() any {
+	return nil
+}
+
+type file struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *file) Close() error {
+	return nil
+}
+
+type dir struct {
+	fs      *FS
+	name    string
+	real    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	loaded  bool
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) {
+	return &fileInfo{FileInfo: d.info, name: path.Base(d.name), size: d.info.Size()}, nil
+}
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.Annotate(errors.ErrInvalidParameters, "is a directory: %w")}
+}
+
+func (d *dir) Close() error {
+	return nil
+}
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.loaded {
+		entries, err := d.fs.readDir(d.name, d.real)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries, d.loaded = entries, true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,101 @@
+package cryptofs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func populate(t *testing.T, fsys *FS) {
+	require.NoError(t, fsys.WriteFile("readme.txt", []byte("hello"), 0o600))
+	require.NoError(t, fsys.WriteFile("empty", nil, 0o600))
+	require.NoError(t, fsys.Mkdir("docs", 0o700))
+	require.NoError(t, fsys.Mkdir("docs/nested", 0o700))
+	require.NoError(t, fsys.WriteFile("docs/a.txt", []byte(strings.Repeat("chunked content ", 10)), 0o600))
+	require.NoError(t, fsys.WriteFile("docs/nested/b.txt", []byte("b"), 0o600))
+}
+
+func TestFSConformance(t *testing.T) {
+	for _, encryptNames := range []bool{false, true} {
+		dir := t.TempDir()
+		fsys, err := New(dir, newKeys(t), &Options{AEAD: aead.ChaCha20Poly1305Name, EncryptNames: encryptNames})
+		require.NoError(t, err)
+		fsys.chunkSize = 32
+		populate(t, fsys)
+
+		require.NoError(t, fstest.TestFS(fsys, "readme.txt", "empty", "docs/a.txt", "docs/nested/b.txt"))
+
+		data, err := fs.ReadFile(fsys, "docs/a.txt")
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("chunked content ", 10), string(data))
+
+		info, err := fsys.Stat("docs/a.txt")
+		require.NoError(t, err)
+		assert.Equal(t, int64(160), info.Size())
+		assert.Equal(t, "a.txt", info.Name())
+	}
+}
+
+func TestFSEncryptsOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	fsys, err := New(dir, newKeys(t), nil)
+	require.NoError(t, err)
+	populate(t, fsys)
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		require.NoError(t, err)
+		assert.NotContains(t, d.Name(), ".txt")
+		assert.NotEqual(t, "docs", d.Name())
+		if !d.IsDir() {
+			raw, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.NotContains(t, string(raw), "hello")
+			assert.NotContains(t, string(raw), "chunked content")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	reopened, err := New(dir, newKeys(t), nil)
+	require.NoError(t, err)
+	data, err := reopened.ReadFile("readme.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), data)
+
+	require.NoError(t, reopened.Remove("readme.txt"))
+	_, err = reopened.Stat("readme.txt")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestFSRejectsInvalidPaths(t *testing.T) {
+	fsys, err := New(t.TempDir(), newKeys(t), nil)
+	require.NoError(t, err)
+
+	_, err = fsys.Open("../escape")
+	assert.ErrorIs(t, err, fs.ErrInvalid)
+	assert.ErrorIs(t, fsys.WriteFile("/abs", nil, 0o600), fs.ErrInvalid)
+	assert.ErrorIs(t, fsys.Remove("."), fs.ErrInvalid)
+}
+
+func TestFSRejectsSwappedContent(t *testing.T) {
+	dir := t.TempDir()
+	fsys, err := New(dir, newKeys(t), &Options{AEAD: aead.ChaCha20Poly1305Name})
+	require.NoError(t, err)
+	require.NoError(t, fsys.WriteFile("public.txt", []byte("public"), 0o600))
+	require.NoError(t, fsys.WriteFile("secret.txt", []byte("secret"), 0o600))
+
+	raw, err := os.ReadFile(filepath.Join(dir, "secret.txt"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "public.txt"), raw, 0o600))
+
+	_, err = fsys.ReadFile("public.txt")
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+}
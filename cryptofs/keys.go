@@ -0,0 +1,58 @@
+package cryptofs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hdkey"
+)
+
+const (
+	KeySize   = 32
+	NameKeyID = "filenames"
+
+	keyIDSize   = 16
+	derivedRoot = "cryptofs"
+)
+
+type KeyStore interface {
+	NewKey() (string, []byte, error)
+	Key(id string) ([]byte, error)
+}
+
+type DerivedKeys struct {
+	root *hdkey.Key
+}
+
+var _ KeyStore = (*DerivedKeys)(nil)
+
+func NewDerivedKeys(master *hdkey.Key) (*DerivedKeys, error) {
+	root, err := master.Child(derivedRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &DerivedKeys{root: root}, nil
+}
+
+func (d *DerivedKeys) NewKey() (string, []byte, error) {
+	raw := make([]byte, keyIDSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, errors.Annotate(err, "generating key id: %w")
+	}
+
+	id := hex.EncodeToString(raw)
+	key, err := d.Key(id)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, key, nil
+}
+
+func (d *DerivedKeys) Key(id string) ([]byte, error) {
+	child, err := d.root.Child(id)
+	if err != nil {
+		return nil, err
+	}
+	return child.SymmetricKey(KeySize)
+}
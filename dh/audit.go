@@ -0,0 +1,143 @@
+package dh
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+const (
+	MinimumSecureBits = 2048
+
+	auditProbability = 0.999999
+	smallFactorBound = 1 << 16
+	minSubgroupBits  = 160
+)
+
+type Issue int
+
+const (
+	IssueCompositeModulus Issue = iota
+	IssueNotSafePrime
+	IssueSmallSubgroup
+	IssueWeakGenerator
+	IssueKnownModulus
+	IssueShortModulus
+)
+
+func (i Issue) String() string {
+	switch i {
+	case IssueCompositeModulus:
+		return "modulus is composite"
+	case IssueNotSafePrime:
+		return "modulus is not a safe prime"
+	case IssueSmallSubgroup:
+		return "generator lies in a group with small subgroups"
+	case IssueWeakGenerator:
+		return "generator has trivial order"
+	case IssueKnownModulus:
+		return "modulus is a published precomputation target"
+	case IssueShortModulus:
+		return "modulus is too short"
+	default:
+		return "unknown issue"
+	}
+}
+
+type AuditReport struct {
+	BitLength    int
+	Issues       []Issue
+	SmallFactors []*big.Int
+	KnownName    string
+}
+
+func (r *AuditReport) Has(issue Issue) bool {
+	for _, i := range r.Issues {
+		if i == issue {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *AuditReport) Secure() bool {
+	return len(r.Issues) == 0
+}
+
+var knownModuli = map[string]string{
+	"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B139B22514A08798E3404DDEF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9A63A3620FFFFFFFFFFFFFFFF":                                                                 "RFC 2409 Oakley Group 1",
+	"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B139B22514A08798E3404DDEF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7EDEE386BFB5A899FA5AE9F24117C4B1FE649286651ECE65381FFFFFFFFFFFFFFFF": "RFC 2409 Oakley Group 2",
+}
+
+func AuditParameters(params *Parameters) (*AuditReport, error) {
+	if params == nil || params.P == nil || params.G == nil || params.P.Sign() <= 0 {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	p, g := params.P, params.G
+	report := &AuditReport{BitLength: p.BitLen()}
+
+	if report.BitLength < MinimumSecureBits {
+		report.Issues = append(report.Issues, IssueShortModulus)
+	}
+
+	if name, ok := knownModuli[strings.ToUpper(p.Text(16))]; ok {
+		report.KnownName = name
+		report.Issues = append(report.Issues, IssueKnownModulus)
+	}
+
+	tester := cryptoMath.NewMillerRabinTest()
+	if !tester.IsProbablyPrime(p, auditProbability) {
+		report.Issues = append(report.Issues, IssueCompositeModulus)
+		return report, nil
+	}
+
+	one := big.NewInt(1)
+	pMinus1 := new(big.Int).Sub(p, one)
+	if g.Cmp(one) <= 0 || g.Cmp(pMinus1) >= 0 || new(big.Int).Exp(g, big.NewInt(2), p).Cmp(one) == 0 {
+		report.Issues = append(report.Issues, IssueWeakGenerator)
+	}
+
+	q := new(big.Int).Rsh(pMinus1, 1)
+	if !tester.IsProbablyPrime(q, auditProbability) {
+		report.Issues = append(report.Issues, IssueNotSafePrime)
+	}
+
+	factors, cofactor := smallFactors(q)
+	report.SmallFactors = factors
+
+	exposed := cofactor.BitLen() < minSubgroupBits
+	for _, f := range factors {
+		exp := new(big.Int).Div(pMinus1, f)
+		if new(big.Int).Exp(g, exp, p).Cmp(one) != 0 {
+			exposed = true
+			break
+		}
+	}
+	if exposed && len(factors) > 0 {
+		report.Issues = append(report.Issues, IssueSmallSubgroup)
+	}
+
+	return report, nil
+}
+
+func smallFactors(n *big.Int) ([]*big.Int, *big.Int) {
+	var factors []*big.Int
+	rest := new(big.Int).Set(n)
+	mod := new(big.Int)
+
+	for f := int64(2); f < smallFactorBound && rest.Cmp(big.NewInt(f)) >= 0; f++ {
+		divisor := big.NewInt(f)
+		if mod.Mod(rest, divisor).Sign() != 0 {
+			continue
+		}
+		factors = append(factors, divisor)
+		for mod.Mod(rest, divisor).Sign() == 0 {
+			rest.Div(rest, divisor)
+		}
+	}
+
+	return factors, rest
+}
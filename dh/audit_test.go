@@ -0,0 +1,65 @@
+package dh
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func oakleyGroup2() *big.Int {
+	p, _ := new(big.Int).SetString("FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B139B22514A08798E3404DDEF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7EDEE386BFB5A899FA5AE9F24117C4B1FE649286651ECE65381FFFFFFFFFFFFFFFF", 16)
+	return p
+}
+
+func TestAuditParametersKnownModulus(t *testing.T) {
+	report, err := AuditParameters(&Parameters{P: oakleyGroup2(), G: big.NewInt(2)})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1024, report.BitLength)
+	assert.Equal(t, "RFC 2409 Oakley Group 2", report.KnownName)
+	assert.True(t, report.Has(IssueKnownModulus))
+	assert.True(t, report.Has(IssueShortModulus))
+	assert.False(t, report.Has(IssueCompositeModulus))
+	assert.False(t, report.Has(IssueNotSafePrime))
+	assert.False(t, report.Has(IssueSmallSubgroup))
+	assert.False(t, report.Secure())
+}
+
+func TestAuditParametersCompositeModulus(t *testing.T) {
+	p := new(big.Int).Mul(oakleyGroup2(), big.NewInt(3))
+
+	report, err := AuditParameters(&Parameters{P: p, G: big.NewInt(2)})
+	require.NoError(t, err)
+
+	assert.True(t, report.Has(IssueCompositeModulus))
+}
+
+func TestAuditParametersNonSafePrime(t *testing.T) {
+	report, err := AuditParameters(&Parameters{P: big.NewInt(1000003), G: big.NewInt(2)})
+	require.NoError(t, err)
+
+	assert.True(t, report.Has(IssueNotSafePrime))
+	assert.True(t, report.Has(IssueSmallSubgroup))
+	assert.Contains(t, report.SmallFactors, big.NewInt(3))
+}
+
+func TestAuditParametersWeakGenerator(t *testing.T) {
+	p := oakleyGroup2()
+
+	for _, g := range []*big.Int{big.NewInt(1), new(big.Int).Sub(p, big.NewInt(1))} {
+		report, err := AuditParameters(&Parameters{P: p, G: g})
+		require.NoError(t, err)
+		assert.True(t, report.Has(IssueWeakGenerator))
+	}
+}
+
+func TestAuditParametersInvalid(t *testing.T) {
+	_, err := AuditParameters(nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = AuditParameters(&Parameters{P: big.NewInt(23)})
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
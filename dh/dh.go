@@ -1,7 +1,9 @@
 package dh
 
 import (
+	"context"
 	"crypto/rand"
+	"io"
 	"math/big"
 
 	"github.com/masterkusok/crypto/errors"
@@ -24,7 +26,11 @@ type PublicKey struct {
 }
 
 func GenerateParameters(bits int, tester cryptoMath.PrimalityTester, minProb float64) (*Parameters, error) {
-	p, err := generateSafePrime(bits, tester, minProb)
+	return generateParameters(context.Background(), bits, tester, minProb)
+}
+
+func generateParameters(ctx context.Context, bits int, tester cryptoMath.PrimalityTester, minProb float64) (*Parameters, error) {
+	p, err := generateSafePrime(ctx, bits, tester, minProb)
 	if err != nil {
 		return nil, err
 	}
@@ -35,18 +41,26 @@ func GenerateParameters(bits int, tester cryptoMath.PrimalityTester, minProb flo
 }
 
 func GenerateKey(params *Parameters) (*PrivateKey, *PublicKey, error) {
+	return GenerateKeyFrom(params, rand.Reader)
+}
+
+// GenerateKeyFrom draws the private exponent from source, so that a seeded
+// generator reproduces the same key pair. It is meant for tests and
+// exercises.
+func GenerateKeyFrom(params *Parameters, source io.Reader) (*PrivateKey, *PublicKey, error) {
 	if params == nil || params.P == nil || params.G == nil {
 		return nil, nil, errors.ErrInvalidParameters
 	}
+	if source == nil {
+		source = rand.Reader
+	}
 
-	pMinus2 := new(big.Int).Sub(params.P, big.NewInt(2))
-	x, err := rand.Int(rand.Reader, pMinus2)
+	x, err := generatePrivate(params, source)
 	if err != nil {
-		return nil, nil, errors.Annotate(err, "failed to generate private key: %w")
+		return nil, nil, err
 	}
-	x.Add(x, big.NewInt(1))
 
-	y := new(big.Int).Exp(params.G, x, params.P)
+	y := cryptoMath.ModPowConstantTime(params.G, x, params.P)
 
 	priv := &PrivateKey{Params: params, X: x}
 	pub := &PublicKey{Params: params, Y: y}
@@ -54,6 +68,15 @@ func GenerateKey(params *Parameters) (*PrivateKey, *PublicKey, error) {
 	return priv, pub, nil
 }
 
+func generatePrivate(params *Parameters, source io.Reader) (*big.Int, error) {
+	pMinus2 := new(big.Int).Sub(params.P, big.NewInt(2))
+	x, err := rand.Int(source, pMinus2)
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to generate private key: %w")
+	}
+	return x.Add(x, big.NewInt(1)), nil
+}
+
 func ComputeSharedSecret(priv *PrivateKey, peerPub *PublicKey) (*big.Int, error) {
 	if priv == nil || priv.X == nil || priv.Params == nil {
 		return nil, errors.ErrInvalidPrivateKey
@@ -62,34 +85,37 @@ func ComputeSharedSecret(priv *PrivateKey, peerPub *PublicKey) (*big.Int, error)
 		return nil, errors.ErrInvalidPublicKey
 	}
 
-	if priv.Params.P.Cmp(peerPub.Params.P) != 0 || priv.Params.G.Cmp(peerPub.Params.G) != 0 {
-		return nil, errors.ErrParameterMismatch
-	}
-
-	if peerPub.Y.Cmp(big.NewInt(1)) <= 0 || peerPub.Y.Cmp(priv.Params.P) >= 0 {
-		return nil, errors.ErrInvalidPublicKey
+	if err := checkPeer(priv.Params, peerPub); err != nil {
+		return nil, err
 	}
 
-	secret := new(big.Int).Exp(peerPub.Y, priv.X, priv.Params.P)
+	secret := cryptoMath.ModPowConstantTime(peerPub.Y, priv.X, priv.Params.P)
 
 	return secret, nil
 }
 
-func generateSafePrime(bits int, tester cryptoMath.PrimalityTester, minProb float64) (*big.Int, error) {
+func checkPeer(params *Parameters, peerPub *PublicKey) error {
+	if params.P.Cmp(peerPub.Params.P) != 0 || params.G.Cmp(peerPub.Params.G) != 0 {
+		return errors.ErrParameterMismatch
+	}
+
+	if peerPub.Y.Cmp(big.NewInt(1)) <= 0 || peerPub.Y.Cmp(params.P) >= 0 {
+		return errors.ErrInvalidPublicKey
+	}
+	return nil
+}
+
+func generateSafePrime(ctx context.Context, bits int, tester cryptoMath.PrimalityTester, minProb float64) (*big.Int, error) {
+	opts := &cryptoMath.PrimeOptions{MinProbability: minProb, BlumPrime: true}
 	for {
-		p, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		p, err := cryptoMath.GeneratePrime(ctx, bits, tester, opts)
 		if err != nil {
-			return nil, errors.Annotate(err, "failed to generate random number: %w")
+			return nil, errors.Annotate(err, "failed to generate prime: %w")
 		}
-		p.SetBit(p, bits-1, 1)
-		p.Or(p, big.NewInt(1))
-
-		if tester.IsProbablyPrime(p, minProb) {
-			q := new(big.Int).Sub(p, big.NewInt(1))
-			q.Div(q, big.NewInt(2))
-			if tester.IsProbablyPrime(q, minProb) {
-				return p, nil
-			}
+
+		q := new(big.Int).Rsh(p, 1)
+		if tester.IsProbablyPrime(q, minProb) {
+			return p, nil
 		}
 	}
 }
@@ -2,6 +2,7 @@ package dh
 
 import (
 	"math/big"
+	"math/rand/v2"
 	"testing"
 
 	cryptoMath "github.com/masterkusok/crypto/math"
@@ -35,6 +36,22 @@ func TestGenerateKey(t *testing.T) {
 	assert.True(t, pub.Y.Cmp(params.P) < 0)
 }
 
+func TestGenerateKeyFromSeed(t *testing.T) {
+	params := Group14()
+	seed := [32]byte{1, 2, 3}
+
+	priv, pub, err := GenerateKeyFrom(params, rand.NewChaCha8(seed))
+	require.NoError(t, err)
+	again, _, err := GenerateKeyFrom(params, rand.NewChaCha8(seed))
+	require.NoError(t, err)
+	assert.Equal(t, 0, priv.X.Cmp(again.X))
+	assert.Equal(t, 0, pub.Y.Cmp(cryptoMath.ModPow(params.G, priv.X, params.P)))
+
+	other, _, err := GenerateKeyFrom(params, rand.NewChaCha8([32]byte{4}))
+	require.NoError(t, err)
+	assert.NotEqual(t, 0, priv.X.Cmp(other.X))
+}
+
 func TestKeyExchange(t *testing.T) {
 	params, err := GenerateParameters(256, cryptoMath.NewMillerRabinTest(), 0.99)
 	require.NoError(t, err)
@@ -0,0 +1,18 @@
+package dh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup14(t *testing.T) {
+	params := Group14()
+	assert.NotSame(t, params.P, Group14().P)
+
+	report, err := AuditParameters(params)
+	require.NoError(t, err)
+	assert.Equal(t, 2048, report.BitLength)
+	assert.True(t, report.Secure(), "%v", report.Issues)
+}
@@ -0,0 +1,44 @@
+package dh
+
+import (
+	"crypto/rand"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+// KeyGenerator precomputes powers of the generator once so that servers
+// creating many ephemeral keys for the same group skip the squarings.
+type KeyGenerator struct {
+	params *Parameters
+	base   *cryptoMath.FixedBase
+}
+
+func NewKeyGenerator(params *Parameters, windowSize int) (*KeyGenerator, error) {
+	if params == nil || params.P == nil || params.G == nil {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	base, err := cryptoMath.NewFixedBase(params.G, params.P, windowSize)
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to precompute generator powers: %w")
+	}
+
+	return &KeyGenerator{params: params, base: base}, nil
+}
+
+func (g *KeyGenerator) Parameters() *Parameters {
+	return g.params
+}
+
+func (g *KeyGenerator) GenerateKey() (*PrivateKey, *PublicKey, error) {
+	x, err := generatePrivate(g.params, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	priv := &PrivateKey{Params: g.params, X: x}
+	pub := &PublicKey{Params: g.params, Y: g.base.Exp(x)}
+
+	return priv, pub, nil
+}
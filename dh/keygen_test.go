@@ -0,0 +1,43 @@
+package dh
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+func TestKeyGenerator(t *testing.T) {
+	params, err := GenerateParameters(256, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	gen, err := NewKeyGenerator(params, 4)
+	require.NoError(t, err)
+	assert.Same(t, params, gen.Parameters())
+
+	alice, alicePub, err := gen.GenerateKey()
+	require.NoError(t, err)
+	assert.Equal(t, 0, new(big.Int).Exp(params.G, alice.X, params.P).Cmp(alicePub.Y))
+
+	bob, bobPub, err := GenerateKey(params)
+	require.NoError(t, err)
+
+	s1, err := ComputeSharedSecret(alice, bobPub)
+	require.NoError(t, err)
+	s2, err := ComputeSharedSecret(bob, alicePub)
+	require.NoError(t, err)
+	assert.Equal(t, 0, s1.Cmp(s2))
+}
+
+func TestKeyGeneratorInvalid(t *testing.T) {
+	_, err := NewKeyGenerator(nil, 4)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	params := &Parameters{P: big.NewInt(23), G: big.NewInt(5)}
+	_, err = NewKeyGenerator(params, 0)
+	assert.ErrorIs(t, err, cryptoMath.ErrInvalidWindowSize)
+}
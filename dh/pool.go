@@ -0,0 +1,254 @@
+package dh
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+type ParameterPool struct {
+	bits    int
+	tester  cryptoMath.PrimalityTester
+	minProb float64
+	items   chan *Parameters
+
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	overflow []*Parameters
+	err      error
+	failed   chan struct{}
+}
+
+type storedParameters struct {
+	P string `json:"p"`
+	G string `json:"g"`
+}
+
+func NewParameterPool(bits, capacity int, tester cryptoMath.PrimalityTester, minProb float64) *ParameterPool {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	return &ParameterPool{
+		bits:    bits,
+		tester:  tester,
+		minProb: minProb,
+		items:   make(chan *Parameters, capacity),
+		failed:  make(chan struct{}),
+	}
+}
+
+func (p *ParameterPool) Start(ctx context.Context, workers int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		return
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.generate(ctx)
+	}
+}
+
+func (p *ParameterPool) generate(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		params, err := generateParameters(ctx, p.bits, p.tester, p.minProb)
+		if err != nil {
+			if ctx.Err() == nil {
+				p.fail(err)
+			}
+			return
+		}
+
+		select {
+		case p.items <- params:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Get returns queued parameters first. Once a worker has failed the pool
+// stops generating, and Get keeps returning that error after the queue is
+// empty.
+func (p *ParameterPool) Get(ctx context.Context) (*Parameters, error) {
+	if params := p.takeOverflow(); params != nil {
+		return params, nil
+	}
+
+	select {
+	case params := <-p.items:
+		return params, nil
+	default:
+	}
+
+	select {
+	case params := <-p.items:
+		return params, nil
+	case <-p.failed:
+		return nil, errors.Annotate(p.err, "parameter generation failed: %w")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *ParameterPool) Ready() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.items) + len(p.overflow)
+}
+
+func (p *ParameterPool) Close() {
+	p.mu.Lock()
+	cancel := p.cancel
+	p.cancel = nil
+	p.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	p.wg.Wait()
+}
+
+// Save writes every queued parameter set to path and leaves the queue as it
+// was. Entries that no longer fit because workers refilled the pool while
+// it was being read are kept aside and handed out by Get first.
+func (p *ParameterPool) Save(path string) error {
+	p.mu.Lock()
+	queued := p.overflow
+	p.overflow = nil
+	for done := false; !done; {
+		select {
+		case params := <-p.items:
+			queued = append(queued, params)
+		default:
+			done = true
+		}
+	}
+
+	stored := make([]storedParameters, 0, len(queued))
+	for _, params := range queued {
+		if err := p.put(params); err != nil {
+			p.overflow = append(p.overflow, params)
+		}
+		stored = append(stored, storedParameters{P: params.P.Text(16), G: params.G.Text(16)})
+	}
+	p.mu.Unlock()
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return errors.Annotate(err, "encoding parameters: %w")
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return errors.Annotate(err, "writing parameters: %w")
+	}
+
+	return nil
+}
+
+// Load queues the parameters saved at path. Every entry is audited first
+// and the whole file is rejected if any of them is malformed, of the wrong
+// size or fails AuditParameters for anything but its length. Load returns
+// the number of parameters queued, which is short of the file when the
+// pool fills up, together with ErrPoolFull.
+func (p *ParameterPool) Load(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, errors.Annotate(err, "reading parameters: %w")
+	}
+
+	var stored []storedParameters
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return 0, errors.Annotate(err, "decoding parameters: %w")
+	}
+
+	loaded := make([]*Parameters, 0, len(stored))
+	for i, s := range stored {
+		prime, okP := new(big.Int).SetString(s.P, 16)
+		g, okG := new(big.Int).SetString(s.G, 16)
+		if !okP || !okG || prime.BitLen() != p.bits {
+			return 0, errors.Annotate(errors.ErrInvalidParameters, "parameters %d: %w", i)
+		}
+
+		params := &Parameters{P: prime, G: g}
+		if err := auditStored(params); err != nil {
+			return 0, errors.Annotate(err, "parameters %d: %w", i)
+		}
+		loaded = append(loaded, params)
+	}
+
+	for i, params := range loaded {
+		if err := p.put(params); err != nil {
+			return i, errors.Annotate(err, "loaded %d of %d parameters: %w", i, len(loaded))
+		}
+	}
+
+	return len(loaded), nil
+}
+
+// auditStored accepts parameters whose only issue is a short modulus, since
+// Load already holds the modulus to the pool's own bit length.
+func auditStored(params *Parameters) error {
+	report, err := AuditParameters(params)
+	if err != nil {
+		return err
+	}
+	for _, issue := range report.Issues {
+		if issue != IssueShortModulus {
+			return errors.Annotate(errors.ErrInvalidParameters, "%s: %w", issue)
+		}
+	}
+	return nil
+}
+
+func (p *ParameterPool) takeOverflow() *Parameters {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.overflow) == 0 {
+		return nil
+	}
+	params := p.overflow[0]
+	p.overflow = p.overflow[1:]
+	return params
+}
+
+// fail records the first worker error and stops the remaining workers.
+func (p *ParameterPool) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return
+	}
+	p.err = err
+	close(p.failed)
+	if p.cancel != nil {
+		p.cancel()
+	}
+}
+
+func (p *ParameterPool) put(params *Parameters) error {
+	select {
+	case p.items <- params:
+		return nil
+	default:
+		return errors.ErrPoolFull
+	}
+}
@@ -0,0 +1,137 @@
+package dh
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameterPool(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pool := NewParameterPool(64, 2, cryptoMath.NewMillerRabinTest(), 0.99)
+	pool.Start(ctx, 2)
+	defer pool.Close()
+
+	for i := 0; i < 3; i++ {
+		params, err := pool.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 64, params.P.BitLen())
+
+		priv, pub, err := GenerateKey(params)
+		require.NoError(t, err)
+		_, err = ComputeSharedSecret(priv, pub)
+		require.NoError(t, err)
+	}
+}
+
+func TestParameterPoolPersistence(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pool := NewParameterPool(64, 2, cryptoMath.NewMillerRabinTest(), 0.99)
+	pool.Start(ctx, 1)
+	require.Eventually(t, func() bool { return pool.Ready() == 2 }, 30*time.Second, 10*time.Millisecond)
+	pool.Close()
+
+	path := filepath.Join(t.TempDir(), "params.json")
+	require.NoError(t, pool.Save(path))
+	assert.Equal(t, 2, pool.Ready())
+
+	restored := NewParameterPool(64, 4, cryptoMath.NewMillerRabinTest(), 0.99)
+	loaded, err := restored.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded)
+	assert.Equal(t, 2, restored.Ready())
+
+	params, err := restored.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), params.G.Int64())
+
+	small := NewParameterPool(64, 1, cryptoMath.NewMillerRabinTest(), 0.99)
+	loaded, err = small.Load(path)
+	assert.ErrorIs(t, err, errors.ErrPoolFull)
+	assert.Equal(t, 1, loaded)
+	assert.Equal(t, 1, small.Ready())
+}
+
+func TestParameterPoolLoadRejectsUnsafe(t *testing.T) {
+	for name, stored := range map[string]storedParameters{
+		"composite":      {P: "ffffffffffffffff", G: "2"},
+		"not safe prime": {P: "ffffffffffffffc5", G: "2"},
+		"wrong size":     {P: "17", G: "5"},
+		"malformed":      {P: "xyz", G: "2"},
+	} {
+		path := filepath.Join(t.TempDir(), "params.json")
+		data, err := json.Marshal([]storedParameters{stored})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		pool := NewParameterPool(64, 2, cryptoMath.NewMillerRabinTest(), 0.99)
+		loaded, err := pool.Load(path)
+		assert.ErrorIs(t, err, errors.ErrInvalidParameters, name)
+		assert.Zero(t, loaded, name)
+		assert.Zero(t, pool.Ready(), name)
+	}
+}
+
+func TestParameterPoolGetCancelled(t *testing.T) {
+	pool := NewParameterPool(64, 1, cryptoMath.NewMillerRabinTest(), 0.99)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := pool.Get(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParameterPoolSaveKeepsOverflow(t *testing.T) {
+	pool := NewParameterPool(64, 1, cryptoMath.NewMillerRabinTest(), 0.99)
+	queued := &Parameters{P: big.NewInt(23), G: big.NewInt(2)}
+	aside := &Parameters{P: big.NewInt(47), G: big.NewInt(2)}
+	require.NoError(t, pool.put(queued))
+	pool.overflow = append(pool.overflow, aside)
+
+	path := filepath.Join(t.TempDir(), "params.json")
+	require.NoError(t, pool.Save(path))
+	assert.Equal(t, 2, pool.Ready())
+
+	var stored []storedParameters
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &stored))
+	assert.Len(t, stored, 2)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		params, err := pool.Get(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, []*Parameters{queued, aside}, params)
+	}
+	assert.Zero(t, pool.Ready())
+}
+
+func TestParameterPoolGetStickyError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool := NewParameterPool(1, 1, cryptoMath.NewMillerRabinTest(), 0.99)
+	pool.Start(ctx, 2)
+	defer pool.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err := pool.Get(ctx)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,84 @@
+package dh
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+// SplitKey holds a private exponent as x = x1 + x2 mod p-1. The shares are
+// refreshed before every operation and only ever meet as exponents of two
+// separate exponentiations.
+type SplitKey struct {
+	Params *Parameters
+
+	mu    sync.Mutex
+	order *big.Int
+	x1    *big.Int
+	x2    *big.Int
+}
+
+func NewSplitKey(priv *PrivateKey) (*SplitKey, error) {
+	if priv == nil || priv.X == nil || priv.Params == nil || priv.Params.P == nil {
+		return nil, errors.ErrInvalidPrivateKey
+	}
+
+	order := new(big.Int).Sub(priv.Params.P, big.NewInt(1))
+	x1, err := rand.Int(rand.Reader, order)
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to split private key: %w")
+	}
+	x2 := new(big.Int).Sub(priv.X, x1)
+	x2.Mod(x2, order)
+
+	return &SplitKey{Params: priv.Params, order: order, x1: x1, x2: x2}, nil
+}
+
+func GenerateSplitKey(params *Parameters) (*SplitKey, *PublicKey, error) {
+	priv, pub, err := GenerateKey(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := NewSplitKey(priv)
+	priv.X.SetInt64(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, pub, nil
+}
+
+func (k *SplitKey) ComputeSharedSecret(peerPub *PublicKey) (*big.Int, error) {
+	if peerPub == nil || peerPub.Y == nil || peerPub.Params == nil {
+		return nil, errors.ErrInvalidPublicKey
+	}
+	if err := checkPeer(k.Params, peerPub); err != nil {
+		return nil, err
+	}
+
+	x1, x2, err := k.refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := cryptoMath.ModPowConstantTime(peerPub.Y, x1, k.Params.P)
+	secret.Mul(secret, cryptoMath.ModPowConstantTime(peerPub.Y, x2, k.Params.P))
+	return secret.Mod(secret, k.Params.P), nil
+}
+
+func (k *SplitKey) refresh() (*big.Int, *big.Int, error) {
+	r, err := rand.Int(rand.Reader, k.order)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "failed to re-randomize key shares: %w")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.x1.Add(k.x1, r).Mod(k.x1, k.order)
+	k.x2.Sub(k.x2, r).Mod(k.x2, k.order)
+	return new(big.Int).Set(k.x1), new(big.Int).Set(k.x2), nil
+}
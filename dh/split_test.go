@@ -0,0 +1,93 @@
+package dh
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+func TestSplitKeyMatchesPlainKey(t *testing.T) {
+	params, err := GenerateParameters(256, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	alice, alicePub, err := GenerateKey(params)
+	require.NoError(t, err)
+	bob, bobPub, err := GenerateKey(params)
+	require.NoError(t, err)
+
+	want, err := ComputeSharedSecret(bob, alicePub)
+	require.NoError(t, err)
+
+	split, err := NewSplitKey(alice)
+	require.NoError(t, err)
+
+	var previous *big.Int
+	for i := 0; i < 4; i++ {
+		got, err := split.ComputeSharedSecret(bobPub)
+		require.NoError(t, err)
+		assert.Equal(t, 0, want.Cmp(got))
+
+		sum := new(big.Int).Add(split.x1, split.x2)
+		assert.Equal(t, 0, sum.Mod(sum, split.order).Cmp(alice.X))
+		if previous != nil {
+			assert.NotEqual(t, 0, previous.Cmp(split.x1), "shares were not refreshed")
+		}
+		previous = new(big.Int).Set(split.x1)
+	}
+}
+
+func TestGenerateSplitKey(t *testing.T) {
+	params, err := GenerateParameters(256, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	alice, alicePub, err := GenerateSplitKey(params)
+	require.NoError(t, err)
+	bob, bobPub, err := GenerateSplitKey(params)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	secrets := make([]*big.Int, 8)
+	for i := range secrets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				secrets[i], _ = alice.ComputeSharedSecret(bobPub)
+			} else {
+				secrets[i], _ = bob.ComputeSharedSecret(alicePub)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, secret := range secrets {
+		require.NotNil(t, secret)
+		assert.Equal(t, 0, secrets[0].Cmp(secret))
+	}
+}
+
+func TestSplitKeyInvalid(t *testing.T) {
+	_, err := NewSplitKey(nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidPrivateKey)
+
+	params, err := GenerateParameters(256, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+	key, _, err := GenerateSplitKey(params)
+	require.NoError(t, err)
+
+	_, err = key.ComputeSharedSecret(nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidPublicKey)
+
+	_, err = key.ComputeSharedSecret(&PublicKey{Params: params, Y: big.NewInt(1)})
+	assert.ErrorIs(t, err, errors.ErrInvalidPublicKey)
+
+	other := &Parameters{P: params.P, G: big.NewInt(5)}
+	_, err = key.ComputeSharedSecret(&PublicKey{Params: other, Y: big.NewInt(4)})
+	assert.ErrorIs(t, err, errors.ErrParameterMismatch)
+}
@@ -0,0 +1,111 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/masterkusok/crypto/cipher/xts"
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Options struct {
+	Progress func(done, total int64)
+	Verify   bool
+}
+
+type sectorFunc func(ctx context.Context, sector uint64, data []byte) ([]byte, error)
+
+func EncryptImage(ctx context.Context, path string, x *xts.XTS, opts Options) error {
+	return process(ctx, path, x.BlockSize(), opts, func(ctx context.Context, sector uint64, data []byte) ([]byte, error) {
+		return encryptSector(ctx, x, sector, data, opts.Verify)
+	})
+}
+
+func DecryptImage(ctx context.Context, path string, x *xts.XTS, opts Options) error {
+	return process(ctx, path, x.BlockSize(), opts, func(ctx context.Context, sector uint64, data []byte) ([]byte, error) {
+		plaintext, err := x.Decrypt(ctx, data, xts.SectorTweak(sector))
+		if err != nil || !opts.Verify {
+			return plaintext, err
+		}
+		return plaintext, verify(ctx, x.Encrypt, sector, plaintext, data)
+	})
+}
+
+func Reencrypt(ctx context.Context, path string, from, to *xts.XTS, opts Options) error {
+	if from.BlockSize() != to.BlockSize() {
+		return errors.Annotate(errors.ErrParameterMismatch, "sector sizes differ: %w")
+	}
+
+	return process(ctx, path, to.BlockSize(), opts, func(ctx context.Context, sector uint64, data []byte) ([]byte, error) {
+		plaintext, err := from.Decrypt(ctx, data, xts.SectorTweak(sector))
+		if err != nil {
+			return nil, err
+		}
+		return encryptSector(ctx, to, sector, plaintext, opts.Verify)
+	})
+}
+
+func encryptSector(ctx context.Context, x *xts.XTS, sector uint64, plaintext []byte, check bool) ([]byte, error) {
+	ciphertext, err := x.Encrypt(ctx, plaintext, xts.SectorTweak(sector))
+	if err != nil || !check {
+		return ciphertext, err
+	}
+	return ciphertext, verify(ctx, x.Decrypt, sector, ciphertext, plaintext)
+}
+
+func verify(ctx context.Context, inverse func(context.Context, []byte, []byte) ([]byte, error), sector uint64, output, input []byte) error {
+	restored, err := inverse(ctx, output, xts.SectorTweak(sector))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(restored, input) {
+		return errors.Annotate(errors.ErrRoundTripMismatch, "sector %d: %w", sector)
+	}
+	return nil
+}
+
+func process(ctx context.Context, path string, sectorSize int, opts Options, transform sectorFunc) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return errors.Annotate(err, "opening image: %w")
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return errors.Annotate(err, "stat image: %w")
+	}
+	total := info.Size()
+	if total%int64(sectorSize) != 0 {
+		return errors.Annotate(errors.ErrInvalidDataLength, "image is not a whole number of sectors: %w")
+	}
+
+	buf := make([]byte, sectorSize)
+	sectors := total / int64(sectorSize)
+	for sector := int64(0); sector < sectors; sector++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		offset := sector * int64(sectorSize)
+		if _, err := f.ReadAt(buf, offset); err != nil {
+			return errors.Annotate(err, "reading sector %d: %w", sector)
+		}
+
+		out, err := transform(ctx, uint64(sector), buf)
+		if err != nil {
+			return err
+		}
+
+		if _, err := f.WriteAt(out, offset); err != nil {
+			return errors.Annotate(err, "writing sector %d: %w", sector)
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(offset+int64(sectorSize), total)
+		}
+	}
+
+	return f.Sync()
+}
@@ -0,0 +1,93 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/cipher/xts"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sectorSize = 512
+
+func newXTS(t *testing.T, key string) *xts.XTS {
+	data, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	tweak, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+
+	x, err := xts.NewXTS(data, tweak, sectorSize)
+	require.NoError(t, err)
+	require.NoError(t, x.SetKey(context.Background(), []byte(key)))
+	return x
+}
+
+func writeImage(t *testing.T, sectors int) (string, []byte) {
+	image := bytes.Repeat([]byte("sector data!"), sectors*sectorSize/12+1)[:sectors*sectorSize]
+	path := filepath.Join(t.TempDir(), "disk.img")
+	require.NoError(t, os.WriteFile(path, image, 0o600))
+	return path, image
+}
+
+func TestEncryptDecryptImage(t *testing.T) {
+	ctx := context.Background()
+	path, image := writeImage(t, 3)
+	x := newXTS(t, "0123456789abcdeffedcba9876543210")
+
+	var progress []int64
+	opts := Options{Verify: true, Progress: func(done, total int64) {
+		assert.Equal(t, int64(len(image)), total)
+		progress = append(progress, done)
+	}}
+	require.NoError(t, EncryptImage(ctx, path, x, opts))
+	assert.Equal(t, []int64{512, 1024, 1536}, progress)
+
+	encrypted, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, encrypted, len(image))
+	assert.NotEqual(t, image, encrypted)
+	assert.NotEqual(t, encrypted[:sectorSize], encrypted[sectorSize:2*sectorSize])
+
+	require.NoError(t, DecryptImage(ctx, path, x, Options{Verify: true}))
+	decrypted, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, image, decrypted)
+}
+
+func TestReencrypt(t *testing.T) {
+	ctx := context.Background()
+	path, image := writeImage(t, 2)
+	oldKey := newXTS(t, "0123456789abcdeffedcba9876543210")
+	newKey := newXTS(t, "ffffffffffffffff0000000000000000")
+
+	require.NoError(t, EncryptImage(ctx, path, oldKey, Options{}))
+	require.NoError(t, Reencrypt(ctx, path, oldKey, newKey, Options{Verify: true}))
+	require.NoError(t, DecryptImage(ctx, path, newKey, Options{}))
+
+	decrypted, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, image, decrypted)
+}
+
+func TestPartialSector(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disk.img")
+	require.NoError(t, os.WriteFile(path, make([]byte, sectorSize+1), 0o600))
+
+	err := EncryptImage(context.Background(), path, newXTS(t, "0123456789abcdeffedcba9876543210"), Options{})
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
+
+func TestCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	path, _ := writeImage(t, 1)
+
+	err := EncryptImage(ctx, path, newXTS(t, "0123456789abcdeffedcba9876543210"), Options{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,293 @@
+package dkg
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/vss"
+)
+
+type Phase int
+
+const (
+	PhaseDealing Phase = iota
+	PhaseSharing
+	PhaseComplaints
+	PhaseFinished
+)
+
+type CommitmentMessage struct {
+	Dealer      int
+	Commitments []*big.Int
+}
+
+type ShareMessage struct {
+	Dealer    int
+	Recipient int
+	Share     *big.Int
+}
+
+type ComplaintMessage struct {
+	Dealer      int
+	Complainant int
+}
+
+type JustificationMessage struct {
+	Dealer    int
+	Recipient int
+	Share     *big.Int
+}
+
+type Result struct {
+	Index              int
+	Share              *big.Int
+	PublicKey          *dh.PublicKey
+	Qualified          []int
+	VerificationShares map[int]*big.Int
+}
+
+type Party struct {
+	group     *vss.Group
+	index     int
+	threshold int
+	parties   int
+	phase     Phase
+
+	poly        *vss.Polynomial
+	commitments map[int][]*big.Int
+	shares      map[int]*big.Int
+	complaints  map[int]map[int]bool
+	revealed    map[int]map[int]*big.Int
+}
+
+func NewParty(params *dh.Parameters, index, threshold, parties int) (*Party, error) {
+	if threshold < 1 || threshold > parties || index < 1 || index > parties {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	group, err := vss.NewGroup(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Party{
+		group:       group,
+		index:       index,
+		threshold:   threshold,
+		parties:     parties,
+		commitments: make(map[int][]*big.Int),
+		shares:      make(map[int]*big.Int),
+		complaints:  make(map[int]map[int]bool),
+		revealed:    make(map[int]map[int]*big.Int),
+	}, nil
+}
+
+func (p *Party) Index() int {
+	return p.index
+}
+
+func (p *Party) Phase() Phase {
+	return p.phase
+}
+
+func (p *Party) Deal() (*CommitmentMessage, []*ShareMessage, error) {
+	if p.phase != PhaseDealing {
+		return nil, nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+
+	poly, err := vss.NewPolynomial(p.group, p.threshold, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.poly = poly
+
+	commitment := &CommitmentMessage{Dealer: p.index, Commitments: poly.Commitments()}
+	p.commitments[p.index] = commitment.Commitments
+	p.shares[p.index] = poly.Evaluate(p.index)
+
+	var shares []*ShareMessage
+	for j := 1; j <= p.parties; j++ {
+		if j != p.index {
+			shares = append(shares, &ShareMessage{Dealer: p.index, Recipient: j, Share: poly.Evaluate(j)})
+		}
+	}
+
+	p.phase = PhaseSharing
+	return commitment, shares, nil
+}
+
+func (p *Party) HandleCommitment(msg *CommitmentMessage) error {
+	if p.phase != PhaseSharing {
+		return errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+	if !p.validDealer(msg.Dealer) || len(msg.Commitments) != p.threshold {
+		return errors.ErrInvalidFormat
+	}
+	if _, ok := p.commitments[msg.Dealer]; ok {
+		return errors.Annotate(errors.ErrInvalidFormat, "duplicate commitment from %d: %w", msg.Dealer)
+	}
+	for i, c := range msg.Commitments {
+		if !p.inSubgroup(c) {
+			return errors.Annotate(errors.ErrInvalidFormat, "commitment %d from %d is not in the group: %w", i, msg.Dealer)
+		}
+	}
+
+	p.commitments[msg.Dealer] = append([]*big.Int{}, msg.Commitments...)
+	return nil
+}
+
+func (p *Party) HandleShare(msg *ShareMessage) error {
+	if p.phase != PhaseSharing {
+		return errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+	if !p.validDealer(msg.Dealer) || msg.Recipient != p.index || msg.Share == nil {
+		return errors.ErrInvalidFormat
+	}
+	if _, ok := p.shares[msg.Dealer]; ok {
+		return errors.Annotate(errors.ErrInvalidFormat, "duplicate share from %d: %w", msg.Dealer)
+	}
+
+	p.shares[msg.Dealer] = msg.Share
+	return nil
+}
+
+func (p *Party) Complaints() ([]*ComplaintMessage, error) {
+	if p.phase != PhaseSharing {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+
+	var complaints []*ComplaintMessage
+	for dealer := 1; dealer <= p.parties; dealer++ {
+		commitments, ok := p.commitments[dealer]
+		share := p.shares[dealer]
+		if !ok || !vss.VerifyShare(p.group, p.index, share, commitments) {
+			complaints = append(complaints, &ComplaintMessage{Dealer: dealer, Complainant: p.index})
+			p.recordComplaint(dealer, p.index)
+		}
+	}
+
+	p.phase = PhaseComplaints
+	return complaints, nil
+}
+
+func (p *Party) HandleComplaint(msg *ComplaintMessage) (*JustificationMessage, error) {
+	if p.phase != PhaseComplaints {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+	if !p.validDealer(msg.Dealer) || !p.validDealer(msg.Complainant) {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	p.recordComplaint(msg.Dealer, msg.Complainant)
+	if msg.Dealer != p.index {
+		return nil, nil
+	}
+
+	return &JustificationMessage{
+		Dealer:    p.index,
+		Recipient: msg.Complainant,
+		Share:     p.poly.Evaluate(msg.Complainant),
+	}, nil
+}
+
+func (p *Party) HandleJustification(msg *JustificationMessage) error {
+	if p.phase != PhaseComplaints {
+		return errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+	if !p.complaints[msg.Dealer][msg.Recipient] {
+		return errors.Annotate(errors.ErrInvalidFormat, "justification without complaint: %w")
+	}
+
+	commitments, ok := p.commitments[msg.Dealer]
+	if !ok || !vss.VerifyShare(p.group, msg.Recipient, msg.Share, commitments) {
+		return nil
+	}
+
+	if p.revealed[msg.Dealer] == nil {
+		p.revealed[msg.Dealer] = make(map[int]*big.Int)
+	}
+	p.revealed[msg.Dealer][msg.Recipient] = msg.Share
+	if msg.Recipient == p.index {
+		p.shares[msg.Dealer] = msg.Share
+	}
+	return nil
+}
+
+func (p *Party) Finalize() (*Result, error) {
+	if p.phase != PhaseComplaints {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+
+	var qualified []int
+	for dealer := 1; dealer <= p.parties; dealer++ {
+		if p.qualified(dealer) {
+			qualified = append(qualified, dealer)
+		}
+	}
+	if len(qualified) < p.threshold {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "too few qualified dealers: %w")
+	}
+	sort.Ints(qualified)
+
+	share := new(big.Int)
+	y := big.NewInt(1)
+	for _, dealer := range qualified {
+		share.Add(share, p.shares[dealer])
+		y.Mul(y, p.commitments[dealer][0])
+		y.Mod(y, p.group.P)
+	}
+	share.Mod(share, p.group.Q)
+
+	verification := make(map[int]*big.Int, p.parties)
+	for j := 1; j <= p.parties; j++ {
+		v := big.NewInt(1)
+		for _, dealer := range qualified {
+			v.Mul(v, vss.ExpectedCommitment(p.group, j, p.commitments[dealer]))
+			v.Mod(v, p.group.P)
+		}
+		verification[j] = v
+	}
+
+	p.phase = PhaseFinished
+	return &Result{
+		Index:              p.index,
+		Share:              share,
+		PublicKey:          &dh.PublicKey{Params: p.group.Params(), Y: y},
+		Qualified:          qualified,
+		VerificationShares: verification,
+	}, nil
+}
+
+func (p *Party) qualified(dealer int) bool {
+	if _, ok := p.commitments[dealer]; !ok {
+		return false
+	}
+	for complainant := range p.complaints[dealer] {
+		if _, ok := p.revealed[dealer][complainant]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Party) recordComplaint(dealer, complainant int) {
+	if p.complaints[dealer] == nil {
+		p.complaints[dealer] = make(map[int]bool)
+	}
+	p.complaints[dealer][complainant] = true
+}
+
+// inSubgroup reports whether c is a non-identity element of the order-q
+// subgroup that commitments live in.
+func (p *Party) inSubgroup(c *big.Int) bool {
+	if c == nil || c.Cmp(big.NewInt(1)) <= 0 || c.Cmp(p.group.P) >= 0 {
+		return false
+	}
+	return new(big.Int).Exp(c, p.group.Q, p.group.P).Cmp(big.NewInt(1)) == 0
+}
+
+func (p *Party) validDealer(index int) bool {
+	return index >= 1 && index <= p.parties
+}
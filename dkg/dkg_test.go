@@ -0,0 +1,208 @@
+package dkg
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/masterkusok/crypto/vss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	threshold = 2
+	parties   = 3
+)
+
+type tamper struct {
+	share         func(msg *ShareMessage)
+	justification func(msg *JustificationMessage)
+}
+
+func newParams(t *testing.T) *dh.Parameters {
+	params, err := dh.GenerateParameters(128, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+	return params
+}
+
+func run(t *testing.T, params *dh.Parameters, tm tamper) []*Result {
+	players := make([]*Party, parties)
+	for i := range players {
+		p, err := NewParty(params, i+1, threshold, parties)
+		require.NoError(t, err)
+		players[i] = p
+	}
+
+	var commitments []*CommitmentMessage
+	var shares []*ShareMessage
+	for _, p := range players {
+		c, s, err := p.Deal()
+		require.NoError(t, err)
+		commitments = append(commitments, c)
+		shares = append(shares, s...)
+	}
+
+	for _, p := range players {
+		for _, c := range commitments {
+			if c.Dealer != p.Index() {
+				require.NoError(t, p.HandleCommitment(c))
+			}
+		}
+	}
+	for _, s := range shares {
+		if tm.share != nil {
+			tm.share(s)
+		}
+		require.NoError(t, players[s.Recipient-1].HandleShare(s))
+	}
+
+	var complaints []*ComplaintMessage
+	for _, p := range players {
+		c, err := p.Complaints()
+		require.NoError(t, err)
+		complaints = append(complaints, c...)
+	}
+
+	var justifications []*JustificationMessage
+	for _, c := range complaints {
+		for _, p := range players {
+			if p.Index() == c.Complainant {
+				continue
+			}
+			j, err := p.HandleComplaint(c)
+			require.NoError(t, err)
+			if j != nil {
+				justifications = append(justifications, j)
+			}
+		}
+	}
+	for _, j := range justifications {
+		if tm.justification != nil {
+			tm.justification(j)
+		}
+		for _, p := range players {
+			require.NoError(t, p.HandleJustification(j))
+		}
+	}
+
+	results := make([]*Result, parties)
+	for i, p := range players {
+		r, err := p.Finalize()
+		require.NoError(t, err)
+		assert.Equal(t, PhaseFinished, p.Phase())
+		results[i] = r
+	}
+	return results
+}
+
+func assertConsistent(t *testing.T, params *dh.Parameters, results []*Result) {
+	group, err := vss.NewGroup(params)
+	require.NoError(t, err)
+
+	for _, r := range results[1:] {
+		assert.Equal(t, results[0].PublicKey.Y, r.PublicKey.Y)
+		assert.Equal(t, results[0].Qualified, r.Qualified)
+	}
+	for _, r := range results {
+		assert.Equal(t, group.Exp(r.Share), r.VerificationShares[r.Index])
+	}
+
+	secret, err := vss.Recover(group, map[int]*big.Int{1: results[0].Share, 3: results[2].Share})
+	require.NoError(t, err)
+	assert.Equal(t, results[0].PublicKey.Y, group.Exp(secret))
+}
+
+func TestHonestRun(t *testing.T) {
+	params := newParams(t)
+	results := run(t, params, tamper{})
+	assert.Equal(t, []int{1, 2, 3}, results[0].Qualified)
+	assertConsistent(t, params, results)
+}
+
+func TestJustifiedComplaint(t *testing.T) {
+	params := newParams(t)
+	results := run(t, params, tamper{
+		share: func(msg *ShareMessage) {
+			if msg.Dealer == 1 && msg.Recipient == 2 {
+				msg.Share = new(big.Int).Add(msg.Share, big.NewInt(1))
+			}
+		},
+	})
+	assert.Equal(t, []int{1, 2, 3}, results[0].Qualified)
+	assertConsistent(t, params, results)
+}
+
+func TestCheatingDealerDisqualified(t *testing.T) {
+	params := newParams(t)
+	results := run(t, params, tamper{
+		share: func(msg *ShareMessage) {
+			if msg.Dealer == 3 && msg.Recipient == 1 {
+				msg.Share = new(big.Int).Add(msg.Share, big.NewInt(1))
+			}
+		},
+		justification: func(msg *JustificationMessage) {
+			msg.Share = new(big.Int).Add(msg.Share, big.NewInt(1))
+		},
+	})
+	assert.Equal(t, []int{1, 2}, results[0].Qualified)
+	assertConsistent(t, params, results)
+}
+
+func TestPhaseOrder(t *testing.T) {
+	p, err := NewParty(newParams(t), 1, threshold, parties)
+	require.NoError(t, err)
+
+	_, err = p.Finalize()
+	assert.Error(t, err)
+	_, _, err = p.Deal()
+	require.NoError(t, err)
+	_, _, err = p.Deal()
+	assert.Error(t, err)
+}
+
+func TestRejectsMalformedMessages(t *testing.T) {
+	params := newParams(t)
+	dealer, err := NewParty(params, 1, threshold, parties)
+	require.NoError(t, err)
+	commitment, shares, err := dealer.Deal()
+	require.NoError(t, err)
+
+	p, err := NewParty(params, 2, threshold, parties)
+	require.NoError(t, err)
+	_, _, err = p.Deal()
+	require.NoError(t, err)
+
+	nonResidue := new(big.Int).Sub(params.P, big.NewInt(1))
+	for name, bad := range map[string]*big.Int{
+		"nil":                 nil,
+		"zero":                big.NewInt(0),
+		"identity":            big.NewInt(1),
+		"modulus":             new(big.Int).Set(params.P),
+		"not in the subgroup": nonResidue,
+	} {
+		forged := &CommitmentMessage{Dealer: 1, Commitments: append([]*big.Int{}, commitment.Commitments...)}
+		forged.Commitments[1] = bad
+		assert.ErrorIs(t, p.HandleCommitment(forged), errors.ErrInvalidFormat, name)
+	}
+	require.NoError(t, p.HandleCommitment(commitment))
+
+	var share *ShareMessage
+	for _, s := range shares {
+		if s.Recipient == 2 {
+			share = s
+		}
+	}
+	assert.ErrorIs(t, p.HandleShare(&ShareMessage{Dealer: 1, Recipient: 2}), errors.ErrInvalidFormat)
+	require.NoError(t, p.HandleShare(share))
+	overwrite := &ShareMessage{Dealer: 1, Recipient: 2, Share: new(big.Int).Add(share.Share, big.NewInt(1))}
+	assert.ErrorIs(t, p.HandleShare(overwrite), errors.ErrInvalidFormat)
+
+	complaints, err := p.Complaints()
+	require.NoError(t, err)
+	for _, c := range complaints {
+		assert.NotEqual(t, 1, c.Dealer)
+	}
+}
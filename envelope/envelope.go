@@ -0,0 +1,213 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/deal"
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/cipher/tripledes"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	Version = 3
+
+	DES       = "des"
+	TripleDES = "3des"
+	DEAL      = "deal"
+	Rijndael  = "rijndael"
+
+	ModeECB         = "ecb"
+	ModeCBC         = "cbc"
+	ModePCBC        = "pcbc"
+	ModeCFB         = "cfb"
+	ModeOFB         = "ofb"
+	ModeCTR         = "ctr"
+	ModeRandomDelta = "random-delta"
+
+	rijndaelModulus = 0x1B
+	maxHeaderSize   = 64 * 1024
+)
+
+var magic = []byte("MKENV001")
+
+type Header struct {
+	Version     int                  `json:"version"`
+	Algorithm   string               `json:"algorithm"`
+	BlockSize   int                  `json:"block_size,omitempty"`
+	Mode        string               `json:"mode"`
+	SegmentSize int                  `json:"segment_size,omitempty"`
+	DeltaSize   int                  `json:"delta_size,omitempty"`
+	Padding     cipher.PaddingScheme `json:"padding"`
+	IV          []byte               `json:"iv,omitempty"`
+	Salt        []byte               `json:"salt,omitempty"`
+}
+
+func Seal(ctx context.Context, header Header, key, plaintext []byte) ([]byte, error) {
+	c, prefix, err := prepare(&header, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := c.EncryptWithIV(ctx, plaintext, header.IV)
+	if err != nil {
+		return nil, err
+	}
+	return append(prefix, ciphertext...), nil
+}
+
+func Open(ctx context.Context, key, envelope []byte) ([]byte, *Header, error) {
+	header, ciphertext, err := Parse(envelope)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := header.CipherContext(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plaintext, err := c.DecryptWithIV(ctx, ciphertext, header.IV)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plaintext, header, nil
+}
+
+func Parse(envelope []byte) (*Header, []byte, error) {
+	if len(envelope) < len(magic)+4 || !bytes.Equal(envelope[:len(magic)], magic) {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	rest := envelope[len(magic):]
+	size := binary.BigEndian.Uint32(rest)
+	rest = rest[4:]
+	if size > maxHeaderSize || uint64(size) > uint64(len(rest)) {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	var header Header
+	if err := json.Unmarshal(rest[:size], &header); err != nil {
+		return nil, nil, errors.Annotate(errors.ErrInvalidFormat, "decoding header: %w")
+	}
+	if !header.supportedVersion() {
+		return nil, nil, errors.Annotate(errors.ErrInvalidFormat, "unsupported version %d: %w", header.Version)
+	}
+
+	return &header, rest[size:], nil
+}
+
+// supportedVersion accepts the current version and those older envelopes
+// that it reads identically: version 2 stopped padding the stream modes and
+// version 3 changed how random-delta derives its deltas, so only the layout
+// of those modes changed.
+func (h *Header) supportedVersion() bool {
+	switch h.Version {
+	case Version:
+		return true
+	case 2:
+		return h.Mode != ModeRandomDelta
+	case 1:
+		return h.Mode != ModeRandomDelta && h.Mode != ModeCFB && h.Mode != ModeOFB && h.Mode != ModeCTR
+	default:
+		return false
+	}
+}
+
+func prepare(header *Header, key []byte) (*cipher.CipherContext, []byte, error) {
+	header.Version = Version
+
+	block, err := newBlockCipher(header.Algorithm, header.BlockSize, len(key))
+	if err != nil {
+		return nil, nil, err
+	}
+	header.BlockSize = block.BlockSize()
+
+	if header.Mode != ModeECB {
+		header.IV = make([]byte, header.BlockSize)
+		if _, err := rand.Read(header.IV); err != nil {
+			return nil, nil, errors.Annotate(err, "generating IV: %w")
+		}
+	}
+
+	c, err := header.cipherContext(block, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "encoding header: %w")
+	}
+
+	prefix := append([]byte{}, magic...)
+	prefix = binary.BigEndian.AppendUint32(prefix, uint32(len(encoded)))
+	return c, append(prefix, encoded...), nil
+}
+
+func (h *Header) CipherContext(key []byte) (*cipher.CipherContext, error) {
+	block, err := newBlockCipher(h.Algorithm, h.BlockSize, len(key))
+	if err != nil {
+		return nil, err
+	}
+	if block.BlockSize() != h.BlockSize {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "block size mismatch: %w")
+	}
+	return h.cipherContext(block, key)
+}
+
+func (h *Header) cipherContext(block cipher.BlockCipher, key []byte) (*cipher.CipherContext, error) {
+	mode, err := newMode(h)
+	if err != nil {
+		return nil, err
+	}
+	if h.Padding < cipher.Zeros || h.Padding > cipher.TBC {
+		return nil, errors.ErrInvalidPaddingScheme
+	}
+	return cipher.NewCipherContext(block, key, mode, h.Padding, cipher.WithIV(h.IV))
+}
+
+func newBlockCipher(algorithm string, blockSize, keySize int) (cipher.BlockCipher, error) {
+	switch algorithm {
+	case DES:
+		return des.NewDES(), nil
+	case TripleDES:
+		return tripledes.NewTripleDES(), nil
+	case DEAL:
+		return deal.NewDEAL(), nil
+	case Rijndael:
+		if blockSize == 0 {
+			blockSize = 16
+		}
+		return rijndael.NewRijndael(blockSize, keySize, rijndaelModulus)
+	default:
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", algorithm)
+	}
+}
+
+func newMode(h *Header) (cipher.CipherMode, error) {
+	switch h.Mode {
+	case ModeECB:
+		return &cipher.ECBMode{}, nil
+	case ModeCBC:
+		return &cipher.CBCMode{}, nil
+	case ModePCBC:
+		return &cipher.PCBCMode{}, nil
+	case ModeCFB:
+		return &cipher.CFBMode{SegmentSize: h.SegmentSize}, nil
+	case ModeOFB:
+		return &cipher.OFBMode{}, nil
+	case ModeCTR:
+		return &cipher.CTRMode{}, nil
+	case ModeRandomDelta:
+		return &cipher.RandomDeltaMode{DeltaSize: h.DeltaSize}, nil
+	default:
+		return nil, errors.Annotate(errors.ErrInvalidMode, "%s: %w", h.Mode)
+	}
+}
@@ -0,0 +1,79 @@
+package envelope
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealOpen(t *testing.T) {
+	ctx := context.Background()
+	plaintext := []byte("self-describing envelope")
+
+	cases := []struct {
+		name   string
+		header Header
+		key    []byte
+	}{
+		{"des-cbc", Header{Algorithm: DES, Mode: ModeCBC, Padding: cipher.PKCS7}, make([]byte, 8)},
+		{"des-ecb", Header{Algorithm: DES, Mode: ModeECB, Padding: cipher.ANSIX923}, make([]byte, 8)},
+		{"des-cfb8", Header{Algorithm: DES, Mode: ModeCFB, SegmentSize: 8, Padding: cipher.PKCS7}, make([]byte, 8)},
+		{"3des-ctr", Header{Algorithm: TripleDES, Mode: ModeCTR, Padding: cipher.PKCS7, Salt: []byte("salt")}, make([]byte, 24)},
+		{"des-random-delta", Header{Algorithm: DES, Mode: ModeRandomDelta, DeltaSize: 3, Padding: cipher.PKCS7}, make([]byte, 8)},
+		{"rijndael-ofb", Header{Algorithm: Rijndael, Mode: ModeOFB, Padding: cipher.ISO10126}, make([]byte, 16)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sealed, err := Seal(ctx, tc.header, tc.key, plaintext)
+			require.NoError(t, err)
+
+			header, _, err := Parse(sealed)
+			require.NoError(t, err)
+			assert.Equal(t, tc.header.Salt, header.Salt)
+			if tc.header.Mode == ModeECB {
+				assert.Nil(t, header.IV)
+			} else {
+				assert.Len(t, header.IV, header.BlockSize)
+			}
+
+			opened, header, err := Open(ctx, tc.key, sealed)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, opened)
+			assert.Equal(t, tc.header.Algorithm, header.Algorithm)
+		})
+	}
+}
+
+func TestSealUsesFreshIV(t *testing.T) {
+	ctx := context.Background()
+	header := Header{Algorithm: DES, Mode: ModeCBC, Padding: cipher.PKCS7}
+
+	first, err := Seal(ctx, header, make([]byte, 8), []byte("message"))
+	require.NoError(t, err)
+	second, err := Seal(ctx, header, make([]byte, 8), []byte("message"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+}
+
+func TestInvalidEnvelopes(t *testing.T) {
+	ctx := context.Background()
+
+	_, _, err := Open(ctx, make([]byte, 8), []byte("garbage"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = Seal(ctx, Header{Algorithm: "blowfish", Mode: ModeCBC}, make([]byte, 8), nil)
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+
+	_, err = Seal(ctx, Header{Algorithm: DES, Mode: "xex"}, make([]byte, 8), nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidMode)
+
+	sealed, err := Seal(ctx, Header{Algorithm: DES, Mode: ModeCBC, Padding: cipher.PKCS7}, make([]byte, 8), []byte("message"))
+	require.NoError(t, err)
+	_, _, err = Parse(sealed[:len(magic)+6])
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
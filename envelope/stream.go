@@ -0,0 +1,65 @@
+package envelope
+
+import (
+	"context"
+	"encoding/binary"
+	"hash"
+	"io"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Digests struct {
+	Plaintext  []byte
+	Ciphertext []byte
+}
+
+func SealStream(ctx context.Context, header Header, key []byte, r io.Reader, w io.Writer, newHash func() hash.Hash) (*Digests, error) {
+	c, prefix, err := prepare(&header, key)
+	if err != nil {
+		return nil, err
+	}
+
+	plainHash, cipherHash := newHash(), newHash()
+	out := io.MultiWriter(w, cipherHash)
+	if _, err := out.Write(prefix); err != nil {
+		return nil, errors.Annotate(err, "writing header: %w")
+	}
+	if err := c.EncryptStream(ctx, io.TeeReader(r, plainHash), out); err != nil {
+		return nil, err
+	}
+
+	return &Digests{Plaintext: plainHash.Sum(nil), Ciphertext: cipherHash.Sum(nil)}, nil
+}
+
+func OpenStream(ctx context.Context, key []byte, r io.Reader, w io.Writer, newHash func() hash.Hash) (*Header, *Digests, error) {
+	plainHash, cipherHash := newHash(), newHash()
+	in := io.TeeReader(r, cipherHash)
+
+	prefix := make([]byte, len(magic)+4)
+	if _, err := io.ReadFull(in, prefix); err != nil {
+		return nil, nil, errors.Annotate(errors.ErrInvalidFormat, "reading header: %w")
+	}
+	size := binary.BigEndian.Uint32(prefix[len(magic):])
+	if size > maxHeaderSize {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+	prefix = append(prefix, make([]byte, size)...)
+	if _, err := io.ReadFull(in, prefix[len(magic)+4:]); err != nil {
+		return nil, nil, errors.Annotate(errors.ErrInvalidFormat, "reading header: %w")
+	}
+
+	header, _, err := Parse(prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := header.CipherContext(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := c.DecryptStream(ctx, in, io.MultiWriter(w, plainHash)); err != nil {
+		return nil, nil, err
+	}
+	return header, &Digests{Plaintext: plainHash.Sum(nil), Ciphertext: cipherHash.Sum(nil)}, nil
+}
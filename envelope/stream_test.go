@@ -0,0 +1,70 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealStreamDigests(t *testing.T) {
+	ctx := context.Background()
+	key := make([]byte, 8)
+	plaintext := bytes.Repeat([]byte("streamed through a tee "), 5000)
+	header := Header{Algorithm: DES, Mode: ModeCTR, Padding: cipher.PKCS7}
+
+	var sealed bytes.Buffer
+	digests, err := SealStream(ctx, header, key, bytes.NewReader(plaintext), &sealed, sha256.New)
+	require.NoError(t, err)
+
+	plainDigest := sha256.Sum256(plaintext)
+	cipherDigest := sha256.Sum256(sealed.Bytes())
+	assert.Equal(t, plainDigest[:], digests.Plaintext)
+	assert.Equal(t, cipherDigest[:], digests.Ciphertext)
+
+	opened, parsed, err := Open(ctx, key, sealed.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+	assert.Equal(t, ModeCTR, parsed.Mode)
+
+	var decrypted bytes.Buffer
+	parsed, openDigests, err := OpenStream(ctx, key, bytes.NewReader(sealed.Bytes()), &decrypted, sha256.New)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted.Bytes())
+	assert.Equal(t, digests, openDigests)
+	assert.Equal(t, DES, parsed.Algorithm)
+}
+
+func TestStreamRejectsInvalidInput(t *testing.T) {
+	ctx := context.Background()
+
+	_, _, err := OpenStream(ctx, make([]byte, 8), bytes.NewReader([]byte("MKENV")), &bytes.Buffer{}, sha256.New)
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = SealStream(ctx, Header{Algorithm: DES, Mode: "bogus", Padding: cipher.PKCS7}, make([]byte, 8), bytes.NewReader(nil), &bytes.Buffer{}, sha256.New)
+	assert.ErrorIs(t, err, errors.ErrInvalidMode)
+}
+
+func TestSealStreamRandomDelta(t *testing.T) {
+	ctx := context.Background()
+	key := make([]byte, 8)
+	plaintext := bytes.Repeat([]byte("random delta through a stream "), 3000)
+
+	var sealed bytes.Buffer
+	_, err := SealStream(ctx, Header{Algorithm: DES, Mode: ModeRandomDelta, Padding: cipher.PKCS7}, key, bytes.NewReader(plaintext), &sealed, sha256.New)
+	require.NoError(t, err)
+
+	opened, _, err := Open(ctx, key, sealed.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	var decrypted bytes.Buffer
+	_, _, err = OpenStream(ctx, key, bytes.NewReader(sealed.Bytes()), &decrypted, sha256.New)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted.Bytes())
+}
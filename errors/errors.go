@@ -25,10 +25,33 @@ const (
 	ErrInvalidBitIndex      ConstError = "invalid bit index"
 	ErrInvalidDataLength    ConstError = "invalid data length"
 	ErrInvalidIVSize        ConstError = "invalid IV size"
+	ErrInvalidTweakSize     ConstError = "invalid tweak size"
 	ErrInvalidPaddingScheme ConstError = "invalid padding scheme"
+	ErrInvalidPadding       ConstError = "invalid padding"
 	ErrInvalidMode          ConstError = "invalid cipher mode"
 	ErrInvalidParameters    ConstError = "invalid parameters"
 	ErrInvalidPrivateKey    ConstError = "invalid private key"
 	ErrInvalidPublicKey     ConstError = "invalid public key"
 	ErrParameterMismatch    ConstError = "parameter mismatch"
+	ErrFactorNotFound       ConstError = "factor not found"
+	ErrTamperingUndetected  ConstError = "tampering went undetected"
+	ErrNonceExhausted       ConstError = "nonce space exhausted"
+	ErrInvalidNonceState    ConstError = "invalid nonce state"
+	ErrInvalidSignature     ConstError = "invalid signature"
+	ErrInvalidFormat        ConstError = "invalid format"
+	ErrDigestMismatch       ConstError = "digest mismatch"
+	ErrUnsafePath           ConstError = "unsafe path"
+	ErrNoMatchingRecipient  ConstError = "no matching recipient"
+	ErrInvalidMAC           ConstError = "invalid message authentication code"
+	ErrAuthenticationFailed ConstError = "message authentication failed"
+	ErrUnknownAlgorithm     ConstError = "unknown algorithm"
+	ErrInvalidNonceSize     ConstError = "invalid nonce size"
+	ErrRoundTripMismatch    ConstError = "round-trip verification failed"
+	ErrKeyExhausted         ConstError = "one-time key already used"
+	ErrCommitmentMismatch   ConstError = "commitment does not match opening"
+	ErrDecryptionFailed     ConstError = "decryption failed"
+	ErrTokenExpired         ConstError = "token expired"
+	ErrTokenNotYetValid     ConstError = "token not yet valid"
+	ErrNotFound             ConstError = "not found"
+	ErrPoolFull             ConstError = "pool is full"
 )
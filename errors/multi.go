@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type BlockError struct {
+	Index int
+	Err   error
+}
+
+func (e *BlockError) Error() string {
+	return fmt.Sprintf("block %d: %v", e.Index, e.Err)
+}
+
+func (e *BlockError) Unwrap() error {
+	return e.Err
+}
+
+type MultiError struct {
+	Errors []*BlockError
+}
+
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d blocks failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+func (e *MultiError) Add(index int, err error) {
+	switch err := err.(type) {
+	case nil:
+	case *MultiError:
+		e.Errors = append(e.Errors, err.Errors...)
+	case *BlockError:
+		e.Errors = append(e.Errors, err)
+	default:
+		e.Errors = append(e.Errors, &BlockError{Index: index, Err: err})
+	}
+}
+
+func (e *MultiError) ErrorOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+
+	slices.SortStableFunc(e.Errors, func(a, b *BlockError) int {
+		return a.Index - b.Index
+	})
+	return e
+}
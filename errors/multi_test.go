@@ -0,0 +1,45 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiError(t *testing.T) {
+	var multi errors.MultiError
+	assert.NoError(t, multi.ErrorOrNil())
+
+	multi.Add(7, errors.ErrInvalidDataLength)
+	multi.Add(2, nil)
+	multi.Add(0, &errors.BlockError{Index: 3, Err: fmt.Errorf("wrapped: %w", errors.ErrInvalidBlockSize)})
+
+	err := multi.ErrorOrNil()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+	assert.NotErrorIs(t, err, errors.ErrInvalidIVSize)
+	assert.Equal(t, "2 blocks failed: block 3: wrapped: invalid block size; block 7: invalid data length", err.Error())
+
+	var blockErr *errors.BlockError
+	require.True(t, stderrors.As(err, &blockErr))
+	assert.Equal(t, 3, blockErr.Index)
+
+	var nested errors.MultiError
+	nested.Add(1, errors.ErrInvalidMAC)
+	nested.Add(0, err)
+	assert.Len(t, nested.Errors, 3)
+	assert.Equal(t, []int{1, 3, 7}, indices(nested.ErrorOrNil().(*errors.MultiError)))
+}
+
+func indices(multi *errors.MultiError) []int {
+	out := make([]int, len(multi.Errors))
+	for i, err := range multi.Errors {
+		out[i] = err.Index
+	}
+	return out
+}
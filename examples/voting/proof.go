@@ -0,0 +1,134 @@
+package voting
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/blake2b"
+	"github.com/masterkusok/crypto/paillier"
+)
+
+const challengeBits = 128
+
+var challengeModulus = new(big.Int).Lsh(big.NewInt(1), challengeBits)
+
+type MembershipProof struct {
+	Commitments []*big.Int
+	Challenges  []*big.Int
+	Responses   []*big.Int
+}
+
+func proveMembership(pk *paillier.PublicKey, c, nonce *big.Int, value int, allowed []int, context []byte) (*MembershipProof, error) {
+	actual := -1
+	for k, v := range allowed {
+		if v == value {
+			actual = k
+		}
+	}
+	if actual < 0 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "value outside the allowed set: %w")
+	}
+
+	proof := &MembershipProof{
+		Commitments: make([]*big.Int, len(allowed)),
+		Challenges:  make([]*big.Int, len(allowed)),
+		Responses:   make([]*big.Int, len(allowed)),
+	}
+
+	sum := new(big.Int)
+	for k := range allowed {
+		if k == actual {
+			continue
+		}
+		e, err := rand.Int(rand.Reader, challengeModulus)
+		if err != nil {
+			return nil, errors.Annotate(err, "generating challenge: %w")
+		}
+		z, err := pk.RandomNonce()
+		if err != nil {
+			return nil, err
+		}
+
+		u := shifted(pk, c, allowed[k])
+		a := new(big.Int).Exp(z, pk.N, pk.NSquared)
+		a.Mul(a, new(big.Int).ModInverse(new(big.Int).Exp(u, e, pk.NSquared), pk.NSquared))
+		proof.Commitments[k] = a.Mod(a, pk.NSquared)
+		proof.Challenges[k] = e
+		proof.Responses[k] = z
+		sum.Add(sum, e)
+	}
+
+	rho, err := pk.RandomNonce()
+	if err != nil {
+		return nil, err
+	}
+	proof.Commitments[actual] = new(big.Int).Exp(rho, pk.N, pk.NSquared)
+
+	e := challenge(pk, c, proof.Commitments, context)
+	e.Sub(e, sum)
+	proof.Challenges[actual] = e.Mod(e, challengeModulus)
+
+	z := new(big.Int).Exp(nonce, proof.Challenges[actual], pk.N)
+	z.Mul(z, rho)
+	proof.Responses[actual] = z.Mod(z, pk.N)
+
+	return proof, nil
+}
+
+func verifyMembership(pk *paillier.PublicKey, c *big.Int, allowed []int, proof *MembershipProof, context []byte) error {
+	if proof == nil || len(proof.Commitments) != len(allowed) || len(proof.Challenges) != len(allowed) || len(proof.Responses) != len(allowed) {
+		return errors.ErrInvalidFormat
+	}
+
+	sum := new(big.Int)
+	for k, v := range allowed {
+		a, e, z := proof.Commitments[k], proof.Challenges[k], proof.Responses[k]
+		if a == nil || e == nil || z == nil || e.Sign() < 0 || e.Cmp(challengeModulus) >= 0 {
+			return errors.ErrInvalidSignature
+		}
+		// a = z = 0 satisfies the equation below for any challenge, so
+		// both must be units of their groups.
+		if !unit(a, pk.NSquared, pk.N) || !unit(z, pk.N, pk.N) {
+			return errors.ErrInvalidSignature
+		}
+
+		left := new(big.Int).Exp(z, pk.N, pk.NSquared)
+		right := new(big.Int).Exp(shifted(pk, c, v), e, pk.NSquared)
+		right.Mul(right, a)
+		right.Mod(right, pk.NSquared)
+		if left.Cmp(right) != 0 {
+			return errors.ErrInvalidSignature
+		}
+		sum.Add(sum, e)
+	}
+
+	if sum.Mod(sum, challengeModulus).Cmp(challenge(pk, c, proof.Commitments, context)) != 0 {
+		return errors.ErrInvalidSignature
+	}
+	return nil
+}
+
+// unit reports whether 0 < x < bound and x is coprime to n.
+func unit(x, bound, n *big.Int) bool {
+	if x.Sign() <= 0 || x.Cmp(bound) >= 0 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, x, n).Cmp(big.NewInt(1)) == 0
+}
+
+func shifted(pk *paillier.PublicKey, c *big.Int, value int) *big.Int {
+	gm := new(big.Int).Exp(pk.G, big.NewInt(int64(value)), pk.NSquared)
+	u := new(big.Int).Mul(c, gm.ModInverse(gm, pk.NSquared))
+	return u.Mod(u, pk.NSquared)
+}
+
+func challenge(pk *paillier.PublicKey, c *big.Int, commitments []*big.Int, context []byte) *big.Int {
+	data := append([]byte("voting-membership"), context...)
+	for _, v := range append([]*big.Int{pk.N, c}, commitments...) {
+		data = binary.BigEndian.AppendUint32(data, uint32(len(v.Bytes())))
+		data = append(data, v.Bytes()...)
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(blake2b.Sum256(data)), challengeModulus)
+}
@@ -0,0 +1,166 @@
+package voting
+
+import (
+	"math/big"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/paillier"
+)
+
+type Election struct {
+	ID         []byte
+	Candidates int
+	key        *paillier.ThresholdKey
+}
+
+type Ballot struct {
+	Votes      []*big.Int
+	VoteProofs []*MembershipProof
+	SumProof   *MembershipProof
+}
+
+type Trustee struct {
+	election *Election
+	share    *paillier.KeyShare
+}
+
+func NewElection(id []byte, candidates int, key *paillier.ThresholdKey) (*Election, error) {
+	if candidates < 2 || key == nil {
+		return nil, errors.ErrInvalidParameters
+	}
+	return &Election{ID: id, Candidates: candidates, key: key}, nil
+}
+
+func (e *Election) NewTrustee(share *paillier.KeyShare) *Trustee {
+	return &Trustee{election: e, share: share}
+}
+
+func (e *Election) Cast(choice int) (*Ballot, error) {
+	if choice < 0 || choice >= e.Candidates {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	pk := &e.key.PublicKey
+	ballot := &Ballot{}
+	sum := big.NewInt(1)
+	nonceProduct := big.NewInt(1)
+
+	for i := 0; i < e.Candidates; i++ {
+		value := 0
+		if i == choice {
+			value = 1
+		}
+
+		nonce, err := pk.RandomNonce()
+		if err != nil {
+			return nil, err
+		}
+		vote, err := pk.EncryptWithNonce(big.NewInt(int64(value)), nonce)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := proveMembership(pk, vote, nonce, value, []int{0, 1}, e.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		ballot.Votes = append(ballot.Votes, vote)
+		ballot.VoteProofs = append(ballot.VoteProofs, proof)
+		sum = pk.Add(sum, vote)
+		nonceProduct.Mul(nonceProduct, nonce)
+		nonceProduct.Mod(nonceProduct, pk.N)
+	}
+
+	proof, err := proveMembership(pk, sum, nonceProduct, 1, []int{1}, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	ballot.SumProof = proof
+
+	return ballot, nil
+}
+
+func (e *Election) VerifyBallot(ballot *Ballot) error {
+	if ballot == nil || len(ballot.Votes) != e.Candidates || len(ballot.VoteProofs) != e.Candidates {
+		return errors.ErrInvalidFormat
+	}
+
+	pk := &e.key.PublicKey
+	sum := big.NewInt(1)
+	for i, vote := range ballot.Votes {
+		if vote == nil || vote.Sign() <= 0 || vote.Cmp(pk.NSquared) >= 0 {
+			return errors.ErrInvalidFormat
+		}
+		if err := verifyMembership(pk, vote, []int{0, 1}, ballot.VoteProofs[i], e.ID); err != nil {
+			return errors.Annotate(err, "vote %d: %w", i)
+		}
+		sum = pk.Add(sum, vote)
+	}
+
+	if err := verifyMembership(pk, sum, []int{1}, ballot.SumProof, e.ID); err != nil {
+		return errors.Annotate(err, "ballot sum: %w")
+	}
+	return nil
+}
+
+func (e *Election) Tally(ballots []*Ballot) ([]*big.Int, error) {
+	pk := &e.key.PublicKey
+	tally := make([]*big.Int, e.Candidates)
+	for i := range tally {
+		encryptedZero, err := pk.EncryptWithNonce(big.NewInt(0), big.NewInt(1))
+		if err != nil {
+			return nil, err
+		}
+		tally[i] = encryptedZero
+	}
+
+	// Fresh encryptions never repeat, so a ciphertext seen twice is a
+	// replayed ballot or a vote copied out of one.
+	seen := make(map[string]bool)
+	for n, ballot := range ballots {
+		if err := e.VerifyBallot(ballot); err != nil {
+			return nil, errors.Annotate(err, "ballot %d: %w", n)
+		}
+		for _, vote := range ballot.Votes {
+			if seen[vote.String()] {
+				return nil, errors.Annotate(errors.ErrInvalidParameters, "ballot %d repeats an earlier vote: %w", n)
+			}
+			seen[vote.String()] = true
+		}
+		for i, vote := range ballot.Votes {
+			tally[i] = pk.Add(tally[i], vote)
+		}
+	}
+	return tally, nil
+}
+
+func (t *Trustee) Decrypt(tally []*big.Int) []*paillier.DecryptionShare {
+	shares := make([]*paillier.DecryptionShare, len(tally))
+	for i, c := range tally {
+		shares[i] = t.election.key.DecryptShare(t.share, c)
+	}
+	return shares
+}
+
+func (e *Election) Result(partials [][]*paillier.DecryptionShare) ([]int64, error) {
+	result := make([]int64, e.Candidates)
+	for i := range result {
+		var shares []*paillier.DecryptionShare
+		for _, partial := range partials {
+			if len(partial) != e.Candidates {
+				return nil, errors.ErrInvalidFormat
+			}
+			shares = append(shares, partial[i])
+		}
+
+		count, err := e.key.Combine(shares)
+		if err != nil {
+			return nil, err
+		}
+		if !count.IsInt64() {
+			return nil, errors.ErrInvalidFormat
+		}
+		result[i] = count.Int64()
+	}
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package voting
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/masterkusok/crypto/paillier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newElection(t *testing.T) (*Election, []*Trustee) {
+	key, shares, err := paillier.GenerateThresholdKey(256, 2, 3, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	election, err := NewElection([]byte("board-2026"), 3, key)
+	require.NoError(t, err)
+
+	trustees := make([]*Trustee, len(shares))
+	for i, share := range shares {
+		trustees[i] = election.NewTrustee(share)
+	}
+	return election, trustees
+}
+
+func TestElection(t *testing.T) {
+	election, trustees := newElection(t)
+
+	var ballots []*Ballot
+	for _, choice := range []int{0, 2, 2, 1, 2, 0} {
+		ballot, err := election.Cast(choice)
+		require.NoError(t, err)
+		ballots = append(ballots, ballot)
+	}
+
+	tally, err := election.Tally(ballots)
+	require.NoError(t, err)
+
+	result, err := election.Result([][]*paillier.DecryptionShare{
+		trustees[2].Decrypt(tally),
+		trustees[0].Decrypt(tally),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 1, 3}, result)
+
+	_, err = election.Result([][]*paillier.DecryptionShare{trustees[1].Decrypt(tally)})
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
+
+func TestRejectsInvalidBallots(t *testing.T) {
+	election, _ := newElection(t)
+	pk := &election.key.PublicKey
+
+	ballot, err := election.Cast(1)
+	require.NoError(t, err)
+	require.NoError(t, election.VerifyBallot(ballot))
+
+	nonce, err := pk.RandomNonce()
+	require.NoError(t, err)
+	stuffed, err := pk.EncryptWithNonce(big.NewInt(5), nonce)
+	require.NoError(t, err)
+
+	forged := *ballot
+	forged.Votes = append([]*big.Int{}, ballot.Votes...)
+	forged.Votes[1] = stuffed
+	assert.ErrorIs(t, election.VerifyBallot(&forged), errors.ErrInvalidSignature)
+
+	other, err := NewElection([]byte("another"), 3, election.key)
+	require.NoError(t, err)
+	assert.ErrorIs(t, other.VerifyBallot(ballot), errors.ErrInvalidSignature)
+
+	_, err = election.Tally([]*Ballot{ballot, &forged})
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+}
+
+func TestDoubleVoteRejected(t *testing.T) {
+	election, _ := newElection(t)
+	pk := &election.key.PublicKey
+
+	nonce, err := pk.RandomNonce()
+	require.NoError(t, err)
+	one, err := pk.EncryptWithNonce(big.NewInt(1), nonce)
+	require.NoError(t, err)
+	proof, err := proveMembership(pk, one, nonce, 1, []int{0, 1}, election.ID)
+	require.NoError(t, err)
+
+	ballot, err := election.Cast(0)
+	require.NoError(t, err)
+	ballot.Votes[1], ballot.VoteProofs[1] = one, proof
+
+	assert.ErrorIs(t, election.VerifyBallot(ballot), errors.ErrInvalidSignature)
+}
+
+// forgeProof builds a proof with zero commitments and responses, which
+// satisfies z^N = a*u^e for every challenge.
+func forgeProof(pk *paillier.PublicKey, c *big.Int, allowed int, context []byte) *MembershipProof {
+	proof := &MembershipProof{}
+	for k := 0; k < allowed; k++ {
+		proof.Commitments = append(proof.Commitments, big.NewInt(0))
+		proof.Challenges = append(proof.Challenges, big.NewInt(0))
+		proof.Responses = append(proof.Responses, big.NewInt(0))
+	}
+	proof.Challenges[0] = challenge(pk, c, proof.Commitments, context)
+	return proof
+}
+
+func TestRejectsZeroProof(t *testing.T) {
+	election, _ := newElection(t)
+	pk := &election.key.PublicKey
+
+	ballot := &Ballot{}
+	sum := big.NewInt(1)
+	for i := 0; i < election.Candidates; i++ {
+		value := int64(0)
+		if i == 0 {
+			value = 1000
+		}
+		nonce, err := pk.RandomNonce()
+		require.NoError(t, err)
+		vote, err := pk.EncryptWithNonce(big.NewInt(value), nonce)
+		require.NoError(t, err)
+
+		ballot.Votes = append(ballot.Votes, vote)
+		ballot.VoteProofs = append(ballot.VoteProofs, forgeProof(pk, vote, 2, election.ID))
+		sum = pk.Add(sum, vote)
+	}
+	ballot.SumProof = forgeProof(pk, sum, 1, election.ID)
+
+	assert.ErrorIs(t, election.VerifyBallot(ballot), errors.ErrInvalidSignature)
+	_, err := election.Tally([]*Ballot{ballot})
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+}
+
+func TestTallyRejectsReplayedBallot(t *testing.T) {
+	election, _ := newElection(t)
+
+	ballot, err := election.Cast(1)
+	require.NoError(t, err)
+	other, err := election.Cast(0)
+	require.NoError(t, err)
+
+	_, err = election.Tally([]*Ballot{ballot, other, ballot})
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/kdf/hkdf"
+)
+
+const exchangeWindow = 6
+
+var exchangeInfo = []byte("crypto key exchange")
+
+var (
+	exchangeOnce sync.Once
+	exchangeGen  *dh.KeyGenerator
+	exchangeErr  error
+)
+
+func exchangeGenerator() (*dh.KeyGenerator, error) {
+	exchangeOnce.Do(func() {
+		exchangeGen, exchangeErr = dh.NewKeyGenerator(dh.Group14(), exchangeWindow)
+	})
+	return exchangeGen, exchangeErr
+}
+
+// KeyExchange is an ephemeral Diffie-Hellman share over the RFC 3526 2048-bit
+// group. SharedKey derives a KeySize key that can be passed to Encrypt.
+type KeyExchange struct {
+	priv *dh.PrivateKey
+	pub  *dh.PublicKey
+}
+
+func NewKeyExchange() (*KeyExchange, error) {
+	gen, err := exchangeGenerator()
+	if err != nil {
+		return nil, err
+	}
+
+	priv, pub, err := gen.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &KeyExchange{priv: priv, pub: pub}, nil
+}
+
+func (k *KeyExchange) PublicValue() []byte {
+	return k.pub.Y.FillBytes(make([]byte, k.valueSize()))
+}
+
+func (k *KeyExchange) SharedKey(peerPublic []byte) ([]byte, error) {
+	if len(peerPublic) != k.valueSize() {
+		return nil, errors.ErrInvalidPublicKey
+	}
+
+	peer := &dh.PublicKey{Params: k.priv.Params, Y: new(big.Int).SetBytes(peerPublic)}
+	secret, err := dh.ComputeSharedSecret(k.priv, peer)
+	if err != nil {
+		return nil, err
+	}
+
+	return hkdf.Key(sha256.New, secret.FillBytes(make([]byte, k.valueSize())), nil, exchangeInfo, KeySize)
+}
+
+func (k *KeyExchange) valueSize() int {
+	return (k.priv.Params.P.BitLen() + 7) / 8
+}
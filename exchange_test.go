@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyExchange(t *testing.T) {
+	alice, err := NewKeyExchange()
+	require.NoError(t, err)
+	bob, err := NewKeyExchange()
+	require.NoError(t, err)
+
+	require.Len(t, alice.PublicValue(), 256)
+	assert.NotEqual(t, alice.PublicValue(), bob.PublicValue())
+
+	aliceKey, err := alice.SharedKey(bob.PublicValue())
+	require.NoError(t, err)
+	bobKey, err := bob.SharedKey(alice.PublicValue())
+	require.NoError(t, err)
+	require.Equal(t, aliceKey, bobKey)
+	require.Len(t, aliceKey, KeySize)
+
+	ctx := context.Background()
+	sealed, err := Encrypt(ctx, aliceKey, []byte("hello bob"), nil)
+	require.NoError(t, err)
+	got, err := Decrypt(ctx, bobKey, sealed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello bob"), got)
+}
+
+func TestKeyExchangeRejectsInvalidPeer(t *testing.T) {
+	k, err := NewKeyExchange()
+	require.NoError(t, err)
+
+	_, err = k.SharedKey(make([]byte, 16))
+	assert.ErrorIs(t, err, errors.ErrInvalidPublicKey)
+
+	one := make([]byte, 256)
+	one[255] = 1
+	_, err = k.SharedKey(one)
+	assert.ErrorIs(t, err, errors.ErrInvalidPublicKey)
+
+	_, err = k.SharedKey(make([]byte, 256))
+	assert.ErrorIs(t, err, errors.ErrInvalidPublicKey)
+}
@@ -0,0 +1,175 @@
+package blake2b
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	BlockSize = 128
+	Size      = 64
+	Size256   = 32
+	KeySize   = 64
+)
+
+var iv = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var sigma = [12][16]byte{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+}
+
+type Digest struct {
+	h       [8]uint64
+	counter [2]uint64
+	buf     []byte
+	size    int
+	key     []byte
+}
+
+var _ hash.Hash = (*Digest)(nil)
+
+func New(size int, key []byte) (*Digest, error) {
+	if size < 1 || size > Size {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "digest size must be between 1 and 64: %w")
+	}
+	if len(key) > KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	d := &Digest{size: size, key: append([]byte{}, key...)}
+	d.Reset()
+	return d, nil
+}
+
+func Sum512(data []byte) []byte {
+	d, _ := New(Size, nil)
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+func Sum256(data []byte) []byte {
+	d, _ := New(Size256, nil)
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+func (d *Digest) Size() int {
+	return d.size
+}
+
+func (d *Digest) BlockSize() int {
+	return BlockSize
+}
+
+func (d *Digest) Reset() {
+	d.h = iv
+	d.h[0] ^= 0x01010000 ^ uint64(len(d.key))<<8 ^ uint64(d.size)
+	d.counter = [2]uint64{}
+	d.buf = d.buf[:0]
+
+	if len(d.key) > 0 {
+		block := make([]byte, BlockSize)
+		copy(block, d.key)
+		d.buf = append(d.buf, block...)
+	}
+}
+
+func (d *Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if len(d.buf) == BlockSize {
+			d.compress(d.buf, false)
+			d.buf = d.buf[:0]
+		}
+		take := min(BlockSize-len(d.buf), len(p))
+		d.buf = append(d.buf, p[:take]...)
+		p = p[take:]
+	}
+	return n, nil
+}
+
+func (d *Digest) Sum(b []byte) []byte {
+	clone := *d
+	block := make([]byte, BlockSize)
+	copy(block, d.buf)
+
+	clone.addCounter(uint64(len(d.buf)))
+	clone.process(block, true)
+
+	out := make([]byte, Size)
+	for i, w := range clone.h {
+		binary.LittleEndian.PutUint64(out[i*8:], w)
+	}
+	return append(b, out[:d.size]...)
+}
+
+func (d *Digest) compress(block []byte, final bool) {
+	d.addCounter(BlockSize)
+	d.process(block, final)
+}
+
+func (d *Digest) addCounter(n uint64) {
+	var carry uint64
+	d.counter[0], carry = bits.Add64(d.counter[0], n, 0)
+	d.counter[1] += carry
+}
+
+func (d *Digest) process(block []byte, final bool) {
+	var m [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(block[i*8:])
+	}
+
+	var v [16]uint64
+	copy(v[:8], d.h[:])
+	copy(v[8:], iv[:])
+	v[12] ^= d.counter[0]
+	v[13] ^= d.counter[1]
+	if final {
+		v[14] = ^v[14]
+	}
+
+	for r := 0; r < 12; r++ {
+		s := &sigma[r]
+		g(&v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(&v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(&v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(&v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(&v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(&v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(&v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(&v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := range d.h {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+func g(v *[16]uint64, a, b, c, d int, x, y uint64) {
+	v[a] += v[b] + x
+	v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+	v[a] += v[b] + y
+	v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+}
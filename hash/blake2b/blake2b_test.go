@@ -0,0 +1,66 @@
+package blake2b
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSum512(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419" +
+			"d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+		{"abc", "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d1" +
+			"7d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, hex.EncodeToString(Sum512([]byte(tt.input))), tt.input)
+	}
+}
+
+func TestStreamingAcrossBlocks(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 50)
+	want := Sum512(data)
+
+	for _, step := range []int{1, 7, 128, 129} {
+		d, err := New(Size, nil)
+		require.NoError(t, err)
+		for i := 0; i < len(data); i += step {
+			d.Write(data[i:min(i+step, len(data))])
+		}
+		assert.Equal(t, want, d.Sum(nil), step)
+		assert.Equal(t, want, d.Sum(nil), step)
+	}
+
+	exact, err := New(Size, nil)
+	require.NoError(t, err)
+	exact.Write(data[:BlockSize])
+	assert.Equal(t, Sum512(data[:BlockSize]), exact.Sum(nil))
+}
+
+func TestKeyedAndTruncated(t *testing.T) {
+	keyed, err := New(Size, []byte("secret"))
+	require.NoError(t, err)
+	keyed.Write([]byte("abc"))
+	assert.NotEqual(t, Sum512([]byte("abc")), keyed.Sum(nil))
+
+	short, err := New(Size256, nil)
+	require.NoError(t, err)
+	short.Write([]byte("abc"))
+	assert.Len(t, short.Sum(nil), Size256)
+	assert.Equal(t, Sum256([]byte("abc")), short.Sum(nil))
+	assert.NotEqual(t, Sum512([]byte("abc"))[:Size256], short.Sum(nil))
+
+	_, err = New(65, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+	_, err = New(Size, make([]byte, 65))
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
@@ -0,0 +1,198 @@
+package blockhash
+
+import (
+	"context"
+	"encoding/binary"
+	"hash"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Construction int
+
+const (
+	DaviesMeyer Construction = iota
+	MatyasMeyerOseas
+	MiyaguchiPreneel
+)
+
+func (c Construction) String() string {
+	switch c {
+	case DaviesMeyer:
+		return "Davies-Meyer"
+	case MatyasMeyerOseas:
+		return "Matyas-Meyer-Oseas"
+	case MiyaguchiPreneel:
+		return "Miyaguchi-Preneel"
+	default:
+		return "unknown"
+	}
+}
+
+type Hash struct {
+	ctx          context.Context
+	block        cipher.BlockCipher
+	keySize      int
+	construction Construction
+	iv           []byte
+	state        []byte
+	buf          []byte
+	length       uint64
+	err          error
+}
+
+var _ hash.Hash = (*Hash)(nil)
+
+func New(ctx context.Context, block cipher.BlockCipher, keySize int, construction Construction, iv []byte) (*Hash, error) {
+	blockSize := block.BlockSize()
+	if construction < DaviesMeyer || construction > MiyaguchiPreneel {
+		return nil, errors.ErrInvalidParameters
+	}
+	if iv == nil {
+		iv = make([]byte, blockSize)
+	}
+	if len(iv) != blockSize {
+		return nil, errors.ErrInvalidIVSize
+	}
+	if err := block.SetKey(ctx, make([]byte, keySize)); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+
+	h := &Hash{
+		ctx:          ctx,
+		block:        block,
+		keySize:      keySize,
+		construction: construction,
+		iv:           append([]byte{}, iv...),
+	}
+	if h.BlockSize() < 8 {
+		return nil, errors.ErrInvalidBlockSize
+	}
+	h.Reset()
+
+	return h, nil
+}
+
+func Sum(ctx context.Context, block cipher.BlockCipher, keySize int, construction Construction, data []byte) ([]byte, error) {
+	h, err := New(ctx, block, keySize, construction, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(data); err != nil {
+		return nil, err
+	}
+
+	return h.digest()
+}
+
+func (h *Hash) Size() int {
+	return h.block.BlockSize()
+}
+
+func (h *Hash) BlockSize() int {
+	if h.construction == DaviesMeyer {
+		return h.keySize
+	}
+	return h.block.BlockSize()
+}
+
+func (h *Hash) Reset() {
+	h.state = append([]byte{}, h.iv...)
+	h.buf = h.buf[:0]
+	h.length = 0
+	h.err = nil
+}
+
+func (h *Hash) Write(p []byte) (int, error) {
+	if h.err != nil {
+		return 0, h.err
+	}
+
+	h.length += uint64(len(p))
+	h.buf = append(h.buf, p...)
+
+	blockSize := h.BlockSize()
+	for len(h.buf) >= blockSize {
+		state, err := h.compress(h.state, h.buf[:blockSize])
+		if err != nil {
+			h.err = err
+			return 0, err
+		}
+		h.state = state
+		h.buf = h.buf[blockSize:]
+	}
+	h.buf = append([]byte{}, h.buf...)
+
+	return len(p), nil
+}
+
+func (h *Hash) Sum(b []byte) []byte {
+	digest, err := h.digest()
+	if err != nil {
+		return nil
+	}
+	return append(b, digest...)
+}
+
+func (h *Hash) digest() ([]byte, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	blockSize := h.BlockSize()
+	tail := append(append([]byte{}, h.buf...), 0x80)
+	for len(tail)%blockSize != blockSize-8 {
+		tail = append(tail, 0)
+	}
+	tail = binary.BigEndian.AppendUint64(tail, h.length*8)
+
+	state := h.state
+	for i := 0; i < len(tail); i += blockSize {
+		var err error
+		state, err = h.compress(state, tail[i:i+blockSize])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+func (h *Hash) compress(state, message []byte) ([]byte, error) {
+	var key, input, feedforward []byte
+	switch h.construction {
+	case DaviesMeyer:
+		key, input, feedforward = message, state, state
+	case MatyasMeyerOseas:
+		key, input, feedforward = h.deriveKey(state), message, message
+	case MiyaguchiPreneel:
+		key, input, feedforward = h.deriveKey(state), message, xorBytes(message, state)
+	}
+
+	if err := h.block.SetKey(h.ctx, key); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+	encrypted, err := h.block.Encrypt(h.ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return xorBytes(encrypted, feedforward), nil
+}
+
+func (h *Hash) deriveKey(state []byte) []byte {
+	key := make([]byte, h.keySize)
+	for i := range key {
+		key[i] = state[i%len(state)]
+	}
+	return key
+}
+
+func xorBytes(a, b []byte) []byte {
+	result := make([]byte, len(a))
+	for i := range a {
+		result[i] = a[i] ^ b[i]
+	}
+	return result
+}
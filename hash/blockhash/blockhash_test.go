@@ -0,0 +1,82 @@
+package blockhash
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstructions(t *testing.T) {
+	ctx := context.Background()
+	message := []byte("the quick brown fox jumps over the lazy dog")
+
+	digests := make(map[string]bool)
+	for _, construction := range []Construction{DaviesMeyer, MatyasMeyerOseas, MiyaguchiPreneel} {
+		t.Run(construction.String(), func(t *testing.T) {
+			digest, err := Sum(ctx, des.NewDES(), 8, construction, message)
+			require.NoError(t, err)
+			assert.Len(t, digest, 8)
+
+			again, err := Sum(ctx, des.NewDES(), 8, construction, message)
+			require.NoError(t, err)
+			assert.Equal(t, digest, again)
+
+			other, err := Sum(ctx, des.NewDES(), 8, construction, append([]byte{}, message[:len(message)-1]...))
+			require.NoError(t, err)
+			assert.NotEqual(t, digest, other)
+
+			assert.False(t, digests[string(digest)])
+			digests[string(digest)] = true
+		})
+	}
+}
+
+func TestStreamingMatchesSum(t *testing.T) {
+	ctx := context.Background()
+	message := bytes.Repeat([]byte("0123456789"), 7)
+
+	want, err := Sum(ctx, des.NewDES(), 8, MiyaguchiPreneel, message)
+	require.NoError(t, err)
+
+	h, err := New(ctx, des.NewDES(), 8, MiyaguchiPreneel, nil)
+	require.NoError(t, err)
+	for i := 0; i < len(message); i += 3 {
+		_, err := h.Write(message[i:min(i+3, len(message))])
+		require.NoError(t, err)
+	}
+	assert.Equal(t, want, h.Sum(nil))
+	assert.Equal(t, want, h.Sum(nil))
+
+	h.Reset()
+	_, err = h.Write(message)
+	require.NoError(t, err)
+	assert.Equal(t, want, h.Sum(nil))
+}
+
+func TestLengthPadding(t *testing.T) {
+	ctx := context.Background()
+
+	empty, err := Sum(ctx, des.NewDES(), 8, DaviesMeyer, nil)
+	require.NoError(t, err)
+	zero, err := Sum(ctx, des.NewDES(), 8, DaviesMeyer, []byte{0})
+	require.NoError(t, err)
+	assert.NotEqual(t, empty, zero)
+}
+
+func TestInvalidParameters(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(ctx, des.NewDES(), 16, DaviesMeyer, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+
+	_, err = New(ctx, des.NewDES(), 8, DaviesMeyer, make([]byte, 4))
+	assert.ErrorIs(t, err, errors.ErrInvalidIVSize)
+
+	_, err = New(ctx, des.NewDES(), 8, Construction(7), nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
@@ -0,0 +1,139 @@
+package md5
+
+import (
+	"encoding/binary"
+	"hash"
+	"math"
+	"math/bits"
+)
+
+const (
+	BlockSize = 64
+	Size      = 16
+)
+
+var iv = [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+
+var shifts = [64]int{
+	7, 12, 17, 22, 7, 12, 17, 22, 7, 12, 17, 22, 7, 12, 17, 22,
+	5, 9, 14, 20, 5, 9, 14, 20, 5, 9, 14, 20, 5, 9, 14, 20,
+	4, 11, 16, 23, 4, 11, 16, 23, 4, 11, 16, 23, 4, 11, 16, 23,
+	6, 10, 15, 21, 6, 10, 15, 21, 6, 10, 15, 21, 6, 10, 15, 21,
+}
+
+var k = func() [64]uint32 {
+	var table [64]uint32
+	for i := range table {
+		table[i] = uint32(math.Floor(math.Abs(math.Sin(float64(i+1))) * (1 << 32)))
+	}
+	return table
+}()
+
+type Digest struct {
+	h      [4]uint32
+	buf    []byte
+	length uint64
+}
+
+var _ hash.Hash = (*Digest)(nil)
+
+func New() hash.Hash {
+	d := &Digest{}
+	d.Reset()
+	return d
+}
+
+func Sum(data []byte) [Size]byte {
+	d := New()
+	d.Write(data)
+
+	var out [Size]byte
+	copy(out[:], d.Sum(nil))
+	return out
+}
+
+func (d *Digest) Size() int {
+	return Size
+}
+
+func (d *Digest) BlockSize() int {
+	return BlockSize
+}
+
+func (d *Digest) Reset() {
+	d.h = iv
+	d.buf = d.buf[:0]
+	d.length = 0
+}
+
+func (d *Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.length += uint64(n)
+
+	if len(d.buf) > 0 {
+		take := min(BlockSize-len(d.buf), len(p))
+		d.buf = append(d.buf, p[:take]...)
+		p = p[take:]
+		if len(d.buf) < BlockSize {
+			return n, nil
+		}
+		d.block(d.buf)
+		d.buf = d.buf[:0]
+	}
+
+	for len(p) >= BlockSize {
+		d.block(p[:BlockSize])
+		p = p[BlockSize:]
+	}
+	d.buf = append(d.buf, p...)
+	return n, nil
+}
+
+func (d *Digest) Sum(b []byte) []byte {
+	clone := *d
+	clone.buf = append([]byte{}, d.buf...)
+
+	padding := make([]byte, BlockSize+8)
+	padding[0] = 0x80
+	padLen := BlockSize - int((d.length+8)%BlockSize)
+	binary.LittleEndian.PutUint64(padding[padLen:], d.length*8)
+	clone.Write(padding[:padLen+8])
+
+	out := make([]byte, Size)
+	for i, w := range clone.h {
+		binary.LittleEndian.PutUint32(out[i*4:], w)
+	}
+	return append(b, out...)
+}
+
+func (d *Digest) block(p []byte) {
+	var m [16]uint32
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint32(p[i*4:])
+	}
+
+	a, b, c, dd := d.h[0], d.h[1], d.h[2], d.h[3]
+	for i := 0; i < 64; i++ {
+		var f uint32
+		var g int
+		switch {
+		case i < 16:
+			f, g = (b&c)|(^b&dd), i
+		case i < 32:
+			f, g = (dd&b)|(^dd&c), (5*i+1)%16
+		case i < 48:
+			f, g = b^c^dd, (3*i+5)%16
+		default:
+			f, g = c^(b|^dd), (7*i)%16
+		}
+
+		f += a + k[i] + m[g]
+		a, dd, c = dd, c, b
+		b += bits.RotateLeft32(f, shifts[i])
+	}
+
+	d.h[0] += a
+	d.h[1] += b
+	d.h[2] += c
+	d.h[3] += dd
+}
@@ -0,0 +1,50 @@
+package md5
+
+import (
+	"bytes"
+	stdmd5 "crypto/md5"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/mac/hmac"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "d41d8cd98f00b204e9800998ecf8427e"},
+		{"abc", "900150983cd24fb0d6963f7d28e17f72"},
+		{"message digest", "f96b697d7cb7938d525a2f31aaf161d0"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "57edf4a22be3c955ac49da2e2107b67a"},
+	}
+
+	for _, tt := range tests {
+		sum := Sum([]byte(tt.input))
+		assert.Equal(t, tt.want, hex.EncodeToString(sum[:]), tt.input)
+	}
+}
+
+func TestStreamingMatchesStandardLibrary(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 50)
+
+	for length := 0; length <= len(data); length += 11 {
+		assert.Equal(t, stdmd5.Sum(data[:length]), Sum(data[:length]), length)
+	}
+
+	want := stdmd5.Sum(data)
+	for _, step := range []int{1, 7, 55, 56, 64, 65} {
+		d := New()
+		for i := 0; i < len(data); i += step {
+			d.Write(data[i:min(i+step, len(data))])
+		}
+		assert.Equal(t, want[:], d.Sum(nil), step)
+	}
+}
+
+func TestHMACRFC2202(t *testing.T) {
+	tag := hmac.Sum(New, []byte("Jefe"), []byte("what do ya want for nothing?"))
+	assert.Equal(t, "750c783e6ab0b503eaa86e310a5db738", hex.EncodeToString(tag))
+}
@@ -0,0 +1,125 @@
+package sha1
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	BlockSize = 64
+	Size      = 20
+)
+
+var iv = [5]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476, 0xc3d2e1f0}
+
+type Digest struct {
+	h      [5]uint32
+	buf    []byte
+	length uint64
+}
+
+var _ hash.Hash = (*Digest)(nil)
+
+func New() hash.Hash {
+	d := &Digest{}
+	d.Reset()
+	return d
+}
+
+func Sum(data []byte) [Size]byte {
+	d := New()
+	d.Write(data)
+
+	var out [Size]byte
+	copy(out[:], d.Sum(nil))
+	return out
+}
+
+func (d *Digest) Size() int {
+	return Size
+}
+
+func (d *Digest) BlockSize() int {
+	return BlockSize
+}
+
+func (d *Digest) Reset() {
+	d.h = iv
+	d.buf = d.buf[:0]
+	d.length = 0
+}
+
+func (d *Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.length += uint64(n)
+
+	if len(d.buf) > 0 {
+		take := min(BlockSize-len(d.buf), len(p))
+		d.buf = append(d.buf, p[:take]...)
+		p = p[take:]
+		if len(d.buf) < BlockSize {
+			return n, nil
+		}
+		d.block(d.buf)
+		d.buf = d.buf[:0]
+	}
+
+	for len(p) >= BlockSize {
+		d.block(p[:BlockSize])
+		p = p[BlockSize:]
+	}
+	d.buf = append(d.buf, p...)
+	return n, nil
+}
+
+func (d *Digest) Sum(b []byte) []byte {
+	clone := *d
+	clone.buf = append([]byte{}, d.buf...)
+
+	padding := make([]byte, BlockSize+8)
+	padding[0] = 0x80
+	padLen := BlockSize - int((d.length+8)%BlockSize)
+	binary.BigEndian.PutUint64(padding[padLen:], d.length*8)
+	clone.Write(padding[:padLen+8])
+
+	out := make([]byte, Size)
+	for i, w := range clone.h {
+		binary.BigEndian.PutUint32(out[i*4:], w)
+	}
+	return append(b, out...)
+}
+
+func (d *Digest) block(p []byte) {
+	var w [80]uint32
+	for i := 0; i < 16; i++ {
+		w[i] = binary.BigEndian.Uint32(p[i*4:])
+	}
+	for i := 16; i < 80; i++ {
+		w[i] = bits.RotateLeft32(w[i-3]^w[i-8]^w[i-14]^w[i-16], 1)
+	}
+
+	a, b, c, dd, e := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4]
+	for i := 0; i < 80; i++ {
+		var f, k uint32
+		switch {
+		case i < 20:
+			f, k = (b&c)|(^b&dd), 0x5a827999
+		case i < 40:
+			f, k = b^c^dd, 0x6ed9eba1
+		case i < 60:
+			f, k = (b&c)|(b&dd)|(c&dd), 0x8f1bbcdc
+		default:
+			f, k = b^c^dd, 0xca62c1d6
+		}
+
+		t := bits.RotateLeft32(a, 5) + f + e + k + w[i]
+		e, dd, c, b, a = dd, c, bits.RotateLeft32(b, 30), a, t
+	}
+
+	d.h[0] += a
+	d.h[1] += b
+	d.h[2] += c
+	d.h[3] += dd
+	d.h[4] += e
+}
@@ -0,0 +1,43 @@
+package sha1
+
+import (
+	"bytes"
+	stdsha1 "crypto/sha1"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{"abc", "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq", "84983e441c3bd26ebaae4aa1f95129e5e54670f1"},
+	}
+
+	for _, tt := range tests {
+		sum := Sum([]byte(tt.input))
+		assert.Equal(t, tt.want, hex.EncodeToString(sum[:]), tt.input)
+	}
+}
+
+func TestStreamingMatchesStandardLibrary(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 50)
+
+	for length := 0; length <= len(data); length += 11 {
+		assert.Equal(t, stdsha1.Sum(data[:length]), Sum(data[:length]), length)
+	}
+
+	want := stdsha1.Sum(data)
+	for _, step := range []int{1, 7, 55, 56, 64, 65} {
+		d := New()
+		for i := 0; i < len(data); i += step {
+			d.Write(data[i:min(i+step, len(data))])
+		}
+		assert.Equal(t, want[:], d.Sum(nil), step)
+	}
+}
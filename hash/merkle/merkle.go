@@ -0,0 +1,89 @@
+package merkle
+
+import (
+	"bytes"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type HashFunc func(data []byte) []byte
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+type Tree struct {
+	hash   HashFunc
+	levels [][][]byte
+}
+
+func New(leaves [][]byte, hash HashFunc) (*Tree, error) {
+	if len(leaves) == 0 || len(leaves)&(len(leaves)-1) != 0 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "leaf count must be a power of two: %w")
+	}
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = LeafHash(hash, leaf)
+	}
+
+	t := &Tree{hash: hash, levels: [][][]byte{level}}
+	for len(level) > 1 {
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			next[i] = NodeHash(hash, level[2*i], level[2*i+1])
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+
+	return t, nil
+}
+
+func LeafHash(hash HashFunc, leaf []byte) []byte {
+	return hash(append([]byte{leafPrefix}, leaf...))
+}
+
+func NodeHash(hash HashFunc, left, right []byte) []byte {
+	data := append([]byte{nodePrefix}, left...)
+	return hash(append(data, right...))
+}
+
+func (t *Tree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+func (t *Tree) Height() int {
+	return len(t.levels) - 1
+}
+
+func (t *Tree) AuthPath(index int) ([][]byte, error) {
+	if index < 0 || index >= len(t.levels[0]) {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	path := make([][]byte, 0, t.Height())
+	for _, level := range t.levels[:len(t.levels)-1] {
+		path = append(path, level[index^1])
+		index >>= 1
+	}
+	return path, nil
+}
+
+func RootFromPath(hash HashFunc, leaf []byte, index int, path [][]byte) []byte {
+	node := LeafHash(hash, leaf)
+	for _, sibling := range path {
+		if index&1 == 0 {
+			node = NodeHash(hash, node, sibling)
+		} else {
+			node = NodeHash(hash, sibling, node)
+		}
+		index >>= 1
+	}
+	return node
+}
+
+func Verify(hash HashFunc, root, leaf []byte, index int, path [][]byte) bool {
+	return bytes.Equal(RootFromPath(hash, leaf, index, path), root)
+}
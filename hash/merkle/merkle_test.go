@@ -0,0 +1,56 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/blake2b"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func leaves(n int) [][]byte {
+	out := make([][]byte, n)
+	for i := range out {
+		out[i] = []byte(fmt.Sprintf("leaf %d", i))
+	}
+	return out
+}
+
+func TestAuthPaths(t *testing.T) {
+	data := leaves(8)
+	tree, err := New(data, blake2b.Sum256)
+	require.NoError(t, err)
+	assert.Equal(t, 3, tree.Height())
+
+	for i, leaf := range data {
+		path, err := tree.AuthPath(i)
+		require.NoError(t, err)
+		assert.Len(t, path, 3)
+		assert.True(t, Verify(blake2b.Sum256, tree.Root(), leaf, i, path))
+		assert.False(t, Verify(blake2b.Sum256, tree.Root(), leaf, i^1, path))
+		assert.False(t, Verify(blake2b.Sum256, tree.Root(), []byte("forged"), i, path))
+	}
+}
+
+func TestLeafNodeSeparation(t *testing.T) {
+	data := leaves(2)
+	tree, err := New(data, blake2b.Sum256)
+	require.NoError(t, err)
+
+	inner := append(LeafHash(blake2b.Sum256, data[0]), LeafHash(blake2b.Sum256, data[1])...)
+	single, err := New([][]byte{inner}, blake2b.Sum256)
+	require.NoError(t, err)
+	assert.NotEqual(t, tree.Root(), single.Root())
+}
+
+func TestInvalidTree(t *testing.T) {
+	_, err := New(leaves(3), blake2b.Sum256)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	tree, err := New(leaves(4), blake2b.Sum256)
+	require.NoError(t, err)
+	_, err = tree.AuthPath(4)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
@@ -0,0 +1,52 @@
+package sha256
+
+import (
+	"bytes"
+	stdsha256 "crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/mac/hmac"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum256(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq", "248d6a61d20638b8e5c026930c3e6039a33ce45964ff2167f6ecedd419db06c1"},
+	}
+
+	for _, tt := range tests {
+		sum := Sum256([]byte(tt.input))
+		assert.Equal(t, tt.want, hex.EncodeToString(sum[:]), tt.input)
+	}
+}
+
+func TestStreamingMatchesStandardLibrary(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 50)
+
+	for length := 0; length <= len(data); length += 11 {
+		want := stdsha256.Sum256(data[:length])
+		got := Sum256(data[:length])
+		assert.Equal(t, want, got, length)
+	}
+
+	want := stdsha256.Sum256(data)
+	for _, step := range []int{1, 7, 55, 56, 64, 65} {
+		d := New()
+		for i := 0; i < len(data); i += step {
+			d.Write(data[i:min(i+step, len(data))])
+		}
+		assert.Equal(t, want[:], d.Sum(nil), step)
+		assert.Equal(t, want[:], d.Sum(nil), step)
+	}
+}
+
+func TestHMAC(t *testing.T) {
+	tag := hmac.Sum(New, []byte("Jefe"), []byte("what do ya want for nothing?"))
+	assert.Equal(t, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", hex.EncodeToString(tag))
+}
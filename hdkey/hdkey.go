@@ -0,0 +1,87 @@
+package hdkey
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"strings"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/kdf/hkdf"
+	"github.com/masterkusok/crypto/mac/hmac"
+)
+
+const (
+	MinSecretSize = 16
+
+	root       = "m"
+	nodeSize   = 32
+	masterSalt = "hdkey master"
+)
+
+type Key struct {
+	path   string
+	secret []byte
+	chain  []byte
+}
+
+func NewMaster(secret []byte) (*Key, error) {
+	if len(secret) < MinSecretSize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	return newKey(root, hmac.Sum(sha512.New, []byte(masterSalt), secret)), nil
+}
+
+func newKey(path string, digest []byte) *Key {
+	return &Key{path: path, secret: digest[:nodeSize], chain: digest[nodeSize:]}
+}
+
+func (k *Key) Path() string {
+	return k.path
+}
+
+func (k *Key) Child(label string) (*Key, error) {
+	if label == "" || strings.Contains(label, "/") {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "invalid path segment %q: %w", label)
+	}
+
+	h := hmac.New(sha512.New, k.chain)
+	h.Write([]byte{0})
+	h.Write(k.secret)
+	h.Write([]byte(label))
+	return newKey(k.path+"/"+label, h.Sum(nil)), nil
+}
+
+func (k *Key) Derive(path string) (*Key, error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != root {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "path %q must start at %s: %w", path, root)
+	}
+	if k.path != root {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "absolute path from non-root key: %w")
+	}
+
+	key := k
+	for _, segment := range segments[1:] {
+		child, err := key.Child(segment)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+func (k *Key) SymmetricKey(size int) ([]byte, error) {
+	return k.expand("symmetric", size)
+}
+
+func (k *Key) Seed(size int) ([]byte, error) {
+	return k.expand("seed", size)
+}
+
+func (k *Key) expand(purpose string, size int) ([]byte, error) {
+	if size <= 0 {
+		return nil, errors.ErrInvalidKeySize
+	}
+	return hkdf.Expand(sha256.New, k.secret, []byte("hdkey "+purpose+" "+k.path), size)
+}
@@ -0,0 +1,88 @@
+package hdkey
+
+import (
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveIsDeterministic(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	first, err := NewMaster(secret)
+	require.NoError(t, err)
+	second, err := NewMaster(secret)
+	require.NoError(t, err)
+
+	a, err := first.Derive("m/app/1/enc")
+	require.NoError(t, err)
+	b, err := second.Derive("m/app/1/enc")
+	require.NoError(t, err)
+	assert.Equal(t, "m/app/1/enc", a.Path())
+
+	keyA, err := a.SymmetricKey(32)
+	require.NoError(t, err)
+	keyB, err := b.SymmetricKey(32)
+	require.NoError(t, err)
+	assert.Equal(t, keyA, keyB)
+
+	app, err := first.Child("app")
+	require.NoError(t, err)
+	one, err := app.Child("1")
+	require.NoError(t, err)
+	enc, err := one.Child("enc")
+	require.NoError(t, err)
+	keyC, err := enc.SymmetricKey(32)
+	require.NoError(t, err)
+	assert.Equal(t, keyA, keyC)
+}
+
+func TestDerivedKeysAreIndependent(t *testing.T) {
+	master, err := NewMaster([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	seen := make(map[string]string)
+	for _, path := range []string{"m", "m/app", "m/app/1/enc", "m/app/1/mac", "m/app/2/enc", "m/other/1/enc"} {
+		key, err := master.Derive(path)
+		require.NoError(t, err)
+
+		for _, purpose := range []string{"symmetric", "seed"} {
+			material, err := key.expand(purpose, 32)
+			require.NoError(t, err)
+			previous, ok := seen[string(material)]
+			assert.False(t, ok, "%s %s collides with %s", path, purpose, previous)
+			seen[string(material)] = path + " " + purpose
+		}
+	}
+
+	other, err := NewMaster([]byte("fedcba9876543210"))
+	require.NoError(t, err)
+	key, err := other.Derive("m/app/1/enc")
+	require.NoError(t, err)
+	material, err := key.SymmetricKey(32)
+	require.NoError(t, err)
+	assert.NotContains(t, seen, string(material))
+}
+
+func TestInvalidPaths(t *testing.T) {
+	_, err := NewMaster([]byte("short"))
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+
+	master, err := NewMaster([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	for _, path := range []string{"", "app/1", "m//enc", "m/app/"} {
+		_, err := master.Derive(path)
+		assert.ErrorIs(t, err, errors.ErrInvalidParameters, path)
+	}
+
+	child, err := master.Child("app")
+	require.NoError(t, err)
+	_, err = child.Derive("m/enc")
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = child.Seed(0)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
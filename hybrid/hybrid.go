@@ -0,0 +1,126 @@
+// Package hybrid encrypts data for an RSA key pair: a fresh session key
+// encrypts the data with an AEAD, and the session key itself is wrapped with
+// RSA-OAEP.
+package hybrid
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+)
+
+// A hybrid envelope is magic || AEAD name length (u8) || AEAD name ||
+// wrapped key length (u16) || wrapped key || nonce || AEAD ciphertext. The
+// magic and AEAD name are the OAEP label, so the session key only unwraps
+// for the algorithm it was sealed with, and everything in front of the nonce
+// is the associated data, so any change to the envelope fails to open.
+const (
+	oaepHash       = crypto.SHA256
+	sessionKeySize = 32
+	maxNameLength  = 255
+)
+
+var magic = []byte("MKHYB002")
+
+type Options struct {
+	AEAD string
+}
+
+func DefaultOptions() Options {
+	return Options{AEAD: aead.AESGCMName}
+}
+
+// Seal encrypts plaintext for pub with the AEAD named in opts.
+func Seal(ctx context.Context, pub *rsa.PublicKey, plaintext []byte, opts *Options) ([]byte, error) {
+	options := DefaultOptions()
+	if opts != nil {
+		options = *opts
+	}
+
+	if pub == nil || pub.N == nil || pub.E == nil {
+		return nil, errors.ErrInvalidPublicKey
+	}
+	if len(options.AEAD) > maxNameLength {
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", options.AEAD)
+	}
+
+	key := make([]byte, sessionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Annotate(err, "generating session key: %w")
+	}
+	c, err := aead.New(options.AEAD, key)
+	if err != nil {
+		return nil, err
+	}
+
+	label := append(append([]byte{}, magic...), byte(len(options.AEAD)))
+	label = append(label, options.AEAD...)
+	wrapped, err := rsa.EncryptOAEP(pub, oaepHash, key, label)
+	if err != nil {
+		return nil, errors.Annotate(err, "wrapping session key: %w")
+	}
+
+	result := binary.BigEndian.AppendUint16(label, uint16(len(wrapped)))
+	result = append(result, wrapped...)
+
+	nonce := make([]byte, c.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Annotate(err, "generating nonce: %w")
+	}
+	sealed, err := c.Seal(ctx, nonce, plaintext, result)
+	if err != nil {
+		return nil, err
+	}
+
+	result = append(result, nonce...)
+	return append(result, sealed...), nil
+}
+
+// Open unwraps the session key with priv and decrypts the payload. A wrong
+// key and a modified label are reported as ErrDecryptionFailed, a modified
+// payload as ErrAuthenticationFailed.
+func Open(ctx context.Context, priv *rsa.PrivateKey, data []byte) ([]byte, error) {
+	if priv == nil || priv.N == nil || priv.D == nil {
+		return nil, errors.ErrInvalidPrivateKey
+	}
+	if len(data) < len(magic)+1 || !bytes.Equal(data[:len(magic)], magic) {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	rest := data[len(magic):]
+	nameLength := int(rest[0])
+	if len(rest) < 1+nameLength+2 {
+		return nil, errors.ErrInvalidFormat
+	}
+	name := string(rest[1 : 1+nameLength])
+	label := data[:len(magic)+1+nameLength]
+
+	rest = rest[1+nameLength:]
+	size := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if size > len(rest) {
+		return nil, errors.ErrInvalidFormat
+	}
+	wrapped, payload := rest[:size], rest[size:]
+
+	key, err := rsa.DecryptOAEP(priv, oaepHash, wrapped, label)
+	if err != nil {
+		return nil, err
+	}
+	c, err := aead.New(name, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < c.NonceSize() {
+		return nil, errors.ErrInvalidFormat
+	}
+	additionalData := data[:len(data)-len(payload)]
+	return c.Open(ctx, payload[:c.NonceSize()], payload[c.NonceSize():], additionalData)
+}
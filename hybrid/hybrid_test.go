@@ -0,0 +1,69 @@
+package hybrid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	r := rsa.NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
+	require.NoError(t, r.GenerateKeyPair())
+	return r.GetPrivateKey()
+}
+
+func TestSealOpen(t *testing.T) {
+	ctx := context.Background()
+	priv := newKey(t)
+	plaintext := []byte("bulk data for an RSA recipient")
+
+	for _, name := range []string{aead.AESGCMName, aead.ChaCha20Poly1305Name, aead.AESSIVName} {
+		sealed, err := Seal(ctx, &priv.PublicKey, plaintext, &Options{AEAD: name})
+		require.NoError(t, err, name)
+
+		opened, err := Open(ctx, priv, sealed)
+		require.NoError(t, err, name)
+		assert.Equal(t, plaintext, opened, name)
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	ctx := context.Background()
+	priv := newKey(t)
+	sealed, err := Seal(ctx, &priv.PublicKey, []byte("message"), nil)
+	require.NoError(t, err)
+
+	_, err = Open(ctx, newKey(t), sealed)
+	assert.ErrorIs(t, err, errors.ErrDecryptionFailed)
+
+	// Any change to the body, including its last byte, fails to open
+	// instead of decrypting to modified plaintext.
+	for _, i := range []int{len(sealed) - 1, len(sealed) - 20} {
+		tampered := append([]byte{}, sealed...)
+		tampered[i] ^= 0x01
+		_, err = Open(ctx, priv, tampered)
+		assert.ErrorIs(t, err, errors.ErrAuthenticationFailed, i)
+	}
+
+	// The AEAD name is the OAEP label.
+	relabeled := append([]byte{}, sealed...)
+	relabeled[len(magic)+1] ^= 0x01
+	_, err = Open(ctx, priv, relabeled)
+	assert.ErrorIs(t, err, errors.ErrDecryptionFailed)
+
+	_, err = Open(ctx, priv, sealed[:len(magic)+3])
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+	_, err = Open(ctx, priv, []byte("not an envelope"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = Seal(ctx, &priv.PublicKey, nil, &Options{AEAD: "rot13"})
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+	_, err = Seal(ctx, nil, nil, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidPublicKey)
+}
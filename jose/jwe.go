@@ -0,0 +1,177 @@
+package jose
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"strings"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/mac/hmac"
+)
+
+const (
+	RSAOAEP      = "RSA-OAEP"
+	RSAOAEP256   = "RSA-OAEP-256"
+	A128CBCHS256 = "A128CBC-HS256"
+
+	cbcHS256KeySize = 32
+	cbcHS256IVSize  = 16
+	cbcHS256TagSize = 16
+)
+
+func Encrypt(ctx context.Context, plaintext []byte, algorithm string, key *rsa.PublicKey, header Header) (string, error) {
+	hash, err := oaepHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	header.Algorithm = algorithm
+	header.Encryption = A128CBCHS256
+
+	cek := make([]byte, cbcHS256KeySize)
+	iv := make([]byte, cbcHS256IVSize)
+	if _, err := rand.Read(cek); err != nil {
+		return "", errors.Annotate(err, "generating content key: %w")
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return "", errors.Annotate(err, "generating IV: %w")
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(key, hash, cek, nil)
+	if err != nil {
+		return "", err
+	}
+
+	encodedHeader, err := encodeSegment(header)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, tag, err := sealCBCHS256(ctx, cek, iv, plaintext, []byte(encodedHeader))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		encodedHeader,
+		encoding.EncodeToString(encryptedKey),
+		encoding.EncodeToString(iv),
+		encoding.EncodeToString(ciphertext),
+		encoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+func Decrypt(ctx context.Context, token string, key *rsa.PrivateKey) ([]byte, *Header, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	var header Header
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, nil, err
+	}
+	hash, err := oaepHash(header.Algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Encryption != A128CBCHS256 {
+		return nil, nil, errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", header.Encryption)
+	}
+
+	decoded := make([][]byte, 4)
+	for i := range decoded {
+		if decoded[i], err = encoding.DecodeString(parts[i+1]); err != nil {
+			return nil, nil, errors.ErrInvalidFormat
+		}
+	}
+
+	cek, err := rsa.DecryptOAEP(key, hash, decoded[0], nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cek) != cbcHS256KeySize {
+		return nil, nil, errors.ErrDecryptionFailed
+	}
+
+	plaintext, err := openCBCHS256(ctx, cek, decoded[1], decoded[2], decoded[3], []byte(parts[0]))
+	if err != nil {
+		return nil, nil, err
+	}
+	return plaintext, &header, nil
+}
+
+func oaepHash(algorithm string) (crypto.Hash, error) {
+	switch algorithm {
+	case RSAOAEP:
+		return crypto.SHA1, nil
+	case RSAOAEP256:
+		return crypto.SHA256, nil
+	default:
+		return 0, errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", algorithm)
+	}
+}
+
+func sealCBCHS256(ctx context.Context, key, iv, plaintext, additionalData []byte) ([]byte, []byte, error) {
+	block, err := cbcHS256Block(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	padded, err := cipher.Pad(plaintext, block.BlockSize(), cipher.PKCS7)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, err := (&cipher.CBCMode{}).Encrypt(ctx, block, padded, iv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ciphertext, cbcHS256Tag(key, iv, ciphertext, additionalData), nil
+}
+
+func openCBCHS256(ctx context.Context, key, iv, ciphertext, tag, additionalData []byte) ([]byte, error) {
+	if len(iv) != cbcHS256IVSize || len(ciphertext) == 0 || len(ciphertext)%cbcHS256IVSize != 0 {
+		return nil, errors.ErrInvalidFormat
+	}
+	if subtle.ConstantTimeCompare(cbcHS256Tag(key, iv, ciphertext, additionalData), tag) != 1 {
+		return nil, errors.ErrAuthenticationFailed
+	}
+
+	block, err := cbcHS256Block(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	padded, err := (&cipher.CBCMode{}).Decrypt(ctx, block, ciphertext, iv)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.Unpad(padded, cipher.PKCS7)
+}
+
+func cbcHS256Block(ctx context.Context, key []byte) (cipher.BlockCipher, error) {
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	if err != nil {
+		return nil, err
+	}
+	if err := block.SetKey(ctx, key[cbcHS256KeySize/2:]); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func cbcHS256Tag(key, iv, ciphertext, additionalData []byte) []byte {
+	full := hmac.Sum(sha256.New, key[:cbcHS256KeySize/2], cbcHS256Input(iv, ciphertext, additionalData))
+	return full[:cbcHS256TagSize]
+}
+
+func cbcHS256Input(iv, ciphertext, additionalData []byte) []byte {
+	input := append(append(append([]byte{}, additionalData...), iv...), ciphertext...)
+	return binary.BigEndian.AppendUint64(input, uint64(len(additionalData))*8)
+}
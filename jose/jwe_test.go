@@ -0,0 +1,65 @@
+package jose
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCBCHS256RFC7518Vector(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	iv, _ := hex.DecodeString("1af38c2dc2b96ffdd86694092341bc04")
+	plaintext := []byte("A cipher system must not be required to be secret, and it must be able to fall into the hands of the enemy without inconvenience")
+	additionalData := []byte("The second principle of Auguste Kerckhoffs")
+
+	ciphertext, tag, err := sealCBCHS256(context.Background(), key, iv, plaintext, additionalData)
+	require.NoError(t, err)
+	assert.Equal(t, "c80edfa32ddf39d5ef00c0b468834279", hex.EncodeToString(ciphertext[:16]))
+	assert.Equal(t, "652c3fa36b0a7c5b3219fab3a30bc1c4", hex.EncodeToString(tag))
+
+	opened, err := openCBCHS256(context.Background(), key, iv, ciphertext, tag, additionalData)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+}
+
+func TestJWERoundTrip(t *testing.T) {
+	ctx := context.Background()
+	key := newRSAKey(t)
+
+	for _, algorithm := range []string{RSAOAEP, RSAOAEP256} {
+		token, err := Encrypt(ctx, []byte("secret claims"), algorithm, &key.PublicKey, Header{KeyID: "k1"})
+		require.NoError(t, err)
+		assert.Len(t, strings.Split(token, "."), 5)
+
+		plaintext, header, err := Decrypt(ctx, token, key)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret claims"), plaintext)
+		assert.Equal(t, A128CBCHS256, header.Encryption)
+		assert.Equal(t, "k1", header.KeyID)
+	}
+}
+
+func TestJWETampering(t *testing.T) {
+	ctx := context.Background()
+	key := newRSAKey(t)
+
+	token, err := Encrypt(ctx, []byte("secret claims"), RSAOAEP256, &key.PublicKey, Header{})
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	ciphertext, err := encoding.DecodeString(parts[3])
+	require.NoError(t, err)
+	ciphertext[0] ^= 1
+	parts[3] = encoding.EncodeToString(ciphertext)
+
+	_, _, err = Decrypt(ctx, strings.Join(parts, "."), key)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = Encrypt(ctx, nil, "RSA1_5", &key.PublicKey, Header{})
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+}
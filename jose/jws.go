@@ -0,0 +1,175 @@
+package jose
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/mac/hmac"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	PS256 = "PS256"
+)
+
+var encoding = base64.RawURLEncoding
+
+type Header struct {
+	Algorithm  string `json:"alg"`
+	Encryption string `json:"enc,omitempty"`
+	Type       string `json:"typ,omitempty"`
+	KeyID      string `json:"kid,omitempty"`
+}
+
+type Signer interface {
+	Algorithm() string
+	Sign(input []byte) ([]byte, error)
+}
+
+type Verifier interface {
+	Algorithm() string
+	Verify(input, signature []byte) error
+}
+
+type HMACKey struct {
+	key []byte
+}
+
+func NewHMACKey(key []byte) (*HMACKey, error) {
+	if len(key) < sha256.Size {
+		return nil, errors.ErrInvalidKeySize
+	}
+	return &HMACKey{key: append([]byte{}, key...)}, nil
+}
+
+func (k *HMACKey) Algorithm() string {
+	return HS256
+}
+
+func (k *HMACKey) Sign(input []byte) ([]byte, error) {
+	return hmac.Sum(sha256.New, k.key, input), nil
+}
+
+func (k *HMACKey) Verify(input, signature []byte) error {
+	if err := hmac.Verify(sha256.New, k.key, input, signature); err != nil {
+		return errors.ErrInvalidSignature
+	}
+	return nil
+}
+
+type RSASigner struct {
+	algorithm string
+	key       *rsa.PrivateKey
+}
+
+func NewRSASigner(algorithm string, key *rsa.PrivateKey) (*RSASigner, error) {
+	if algorithm != RS256 && algorithm != PS256 {
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", algorithm)
+	}
+	return &RSASigner{algorithm: algorithm, key: key}, nil
+}
+
+func (s *RSASigner) Algorithm() string {
+	return s.algorithm
+}
+
+func (s *RSASigner) Sign(input []byte) ([]byte, error) {
+	digest := sha256.Sum256(input)
+	if s.algorithm == PS256 {
+		return rsa.SignPSS(s.key, crypto.SHA256, digest[:])
+	}
+	return rsa.SignPKCS1v15(s.key, crypto.SHA256, digest[:])
+}
+
+type RSAVerifier struct {
+	algorithm string
+	key       *rsa.PublicKey
+}
+
+func NewRSAVerifier(algorithm string, key *rsa.PublicKey) (*RSAVerifier, error) {
+	if algorithm != RS256 && algorithm != PS256 {
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", algorithm)
+	}
+	return &RSAVerifier{algorithm: algorithm, key: key}, nil
+}
+
+func (v *RSAVerifier) Algorithm() string {
+	return v.algorithm
+}
+
+func (v *RSAVerifier) Verify(input, signature []byte) error {
+	digest := sha256.Sum256(input)
+	if v.algorithm == PS256 {
+		return rsa.VerifyPSS(v.key, crypto.SHA256, digest[:], signature)
+	}
+	return rsa.VerifyPKCS1v15(v.key, crypto.SHA256, digest[:], signature)
+}
+
+func Sign(payload []byte, signer Signer, header Header) (string, error) {
+	header.Algorithm = signer.Algorithm()
+	encodedHeader, err := encodeSegment(header)
+	if err != nil {
+		return "", err
+	}
+
+	input := encodedHeader + "." + encoding.EncodeToString(payload)
+	signature, err := signer.Sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + encoding.EncodeToString(signature), nil
+}
+
+func Verify(token string, verifier Verifier) ([]byte, *Header, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	var header Header
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, nil, err
+	}
+	if header.Algorithm != verifier.Algorithm() {
+		return nil, nil, errors.Annotate(errors.ErrUnknownAlgorithm, "unexpected algorithm %q: %w", header.Algorithm)
+	}
+
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+	if err := verifier.Verify([]byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, nil, err
+	}
+
+	payload, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+	return payload, &header, nil
+}
+
+func encodeSegment(v any) (string, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.Annotate(err, "encoding header: %w")
+	}
+	return encoding.EncodeToString(encoded), nil
+}
+
+func decodeSegment(segment string, v any) error {
+	decoded, err := encoding.DecodeString(segment)
+	if err != nil {
+		return errors.ErrInvalidFormat
+	}
+	if err := json.Unmarshal(decoded, v); err != nil {
+		return errors.Annotate(errors.ErrInvalidFormat, "decoding header: %w")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package jose
+
+import (
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/mac/hmac"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	r := rsa.NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
+	require.NoError(t, r.GenerateKeyPair())
+	return r.GetPrivateKey()
+}
+
+func TestHS256KnownToken(t *testing.T) {
+	key := []byte("a-string-secret-at-least-256-bits-long")
+	signer, err := NewHMACKey(key)
+	require.NoError(t, err)
+
+	token, err := Sign([]byte(`{"sub":"1234567890"}`), signer, Header{Type: "JWT"})
+	require.NoError(t, err)
+
+	input := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJzdWIiOiIxMjM0NTY3ODkwIn0"
+	expected := input + "." + encoding.EncodeToString(hmac.Sum(sha256.New, key, []byte(input)))
+	assert.Equal(t, expected, token)
+
+	payload, header, err := Verify(token, signer)
+	require.NoError(t, err)
+	assert.Equal(t, `{"sub":"1234567890"}`, string(payload))
+	assert.Equal(t, "JWT", header.Type)
+
+	_, err = NewHMACKey([]byte("short"))
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
+
+func TestRSASignatures(t *testing.T) {
+	key := newRSAKey(t)
+
+	for _, algorithm := range []string{RS256, PS256} {
+		signer, err := NewRSASigner(algorithm, key)
+		require.NoError(t, err)
+		verifier, err := NewRSAVerifier(algorithm, &key.PublicKey)
+		require.NoError(t, err)
+
+		token, err := Sign([]byte("payload"), signer, Header{})
+		require.NoError(t, err)
+		payload, header, err := Verify(token, verifier)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("payload"), payload)
+		assert.Equal(t, algorithm, header.Algorithm)
+
+		tampered := token[:len(token)-2] + "AA"
+		_, _, err = Verify(tampered, verifier)
+		assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+	}
+}
+
+func TestAlgorithmConfusionRejected(t *testing.T) {
+	key := newRSAKey(t)
+	hmacKey, err := NewHMACKey(make([]byte, 32))
+	require.NoError(t, err)
+	verifier, err := NewRSAVerifier(RS256, &key.PublicKey)
+	require.NoError(t, err)
+
+	token, err := Sign([]byte("payload"), hmacKey, Header{})
+	require.NoError(t, err)
+
+	_, _, err = Verify(token, verifier)
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+
+	_, err = NewRSASigner("none", key)
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+}
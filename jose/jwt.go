@@ -0,0 +1,60 @@
+package jose
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Claims map[string]any
+
+func IssueJWT(claims Claims, signer Signer) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", errors.Annotate(err, "encoding claims: %w")
+	}
+	return Sign(payload, signer, Header{Type: "JWT"})
+}
+
+func ValidateJWT(token string, verifier Verifier, now time.Time) (Claims, error) {
+	payload, _, err := Verify(token, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "decoding claims: %w")
+	}
+
+	exp, hasExp, err := claims.time("exp")
+	if err != nil {
+		return nil, err
+	}
+	if hasExp && !now.Before(exp) {
+		return nil, errors.ErrTokenExpired
+	}
+
+	nbf, hasNbf, err := claims.time("nbf")
+	if err != nil {
+		return nil, err
+	}
+	if hasNbf && now.Before(nbf) {
+		return nil, errors.ErrTokenNotYetValid
+	}
+	return claims, nil
+}
+
+func (c Claims) time(name string) (time.Time, bool, error) {
+	value, ok := c[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false, errors.Annotate(errors.ErrInvalidFormat, "claim %q is not numeric: %w", name)
+	}
+	return time.Unix(int64(seconds), 0), true, nil
+}
@@ -0,0 +1,34 @@
+package jose
+
+import (
+	"testing"
+	"time"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTValidation(t *testing.T) {
+	key, err := NewHMACKey(make([]byte, 32))
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+
+	token, err := IssueJWT(Claims{"sub": "alice", "nbf": now.Unix() - 10, "exp": now.Unix() + 60}, key)
+	require.NoError(t, err)
+
+	claims, err := ValidateJWT(token, key, now)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims["sub"])
+
+	_, err = ValidateJWT(token, key, now.Add(time.Minute))
+	assert.ErrorIs(t, err, errors.ErrTokenExpired)
+
+	_, err = ValidateJWT(token, key, now.Add(-time.Minute))
+	assert.ErrorIs(t, err, errors.ErrTokenNotYetValid)
+
+	token, err = IssueJWT(Claims{"exp": "tomorrow"}, key)
+	require.NoError(t, err)
+	_, err = ValidateJWT(token, key, now)
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
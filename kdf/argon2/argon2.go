@@ -0,0 +1,293 @@
+package argon2
+
+import (
+	"context"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/blake2b"
+)
+
+const (
+	Version = 0x13
+
+	typeArgon2id = 2
+
+	blockWords    = 128
+	syncPoints    = 4
+	addressesSize = blockWords
+)
+
+type block [blockWords]uint64
+
+type Params struct {
+	Time        uint32
+	Memory      uint32
+	Parallelism uint8
+	KeyLength   uint32
+	Secret      []byte
+	Data        []byte
+}
+
+func DefaultParams() Params {
+	return Params{Time: 3, Memory: 64 * 1024, Parallelism: 4, KeyLength: 32}
+}
+
+func IDKey(ctx context.Context, password, salt []byte, params Params) ([]byte, error) {
+	if params.Time < 1 || params.Parallelism < 1 || params.KeyLength < 4 {
+		return nil, errors.ErrInvalidParameters
+	}
+	if params.Memory < 8*uint32(params.Parallelism) {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "memory must be at least 8 KiB per lane: %w")
+	}
+	if len(salt) < 8 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "salt must be at least 8 bytes: %w")
+	}
+
+	lanes := uint32(params.Parallelism)
+	memory := params.Memory / (syncPoints * lanes) * (syncPoints * lanes)
+	laneLength := memory / lanes
+	segmentLength := laneLength / syncPoints
+
+	h0 := initialHash(password, salt, params)
+	blocks := make([]block, memory)
+	for lane := uint32(0); lane < lanes; lane++ {
+		for i := uint32(0); i < 2; i++ {
+			input := binary.LittleEndian.AppendUint32(append(h0[:], 0, 0, 0, 0), lane)
+			binary.LittleEndian.PutUint32(input[blake2b.Size:], i)
+			blocks[lane*laneLength+i] = decodeBlock(variableHash(input, blockWords*8))
+		}
+	}
+
+	s := &state{
+		blocks:        blocks,
+		lanes:         lanes,
+		laneLength:    laneLength,
+		segmentLength: segmentLength,
+		passes:        params.Time,
+		memory:        memory,
+	}
+
+	for pass := uint32(0); pass < params.Time; pass++ {
+		for slice := uint32(0); slice < syncPoints; slice++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for lane := uint32(0); lane < lanes; lane++ {
+				s.fillSegment(pass, slice, lane)
+			}
+		}
+	}
+
+	final := blocks[laneLength-1]
+	for lane := uint32(1); lane < lanes; lane++ {
+		last := &blocks[lane*laneLength+laneLength-1]
+		for i := range final {
+			final[i] ^= last[i]
+		}
+	}
+
+	return variableHash(encodeBlock(&final), params.KeyLength), nil
+}
+
+type state struct {
+	blocks        []block
+	lanes         uint32
+	laneLength    uint32
+	segmentLength uint32
+	passes        uint32
+	memory        uint32
+}
+
+func (s *state) fillSegment(pass, slice, lane uint32) {
+	independent := pass == 0 && slice < syncPoints/2
+
+	var address, input, zero block
+	if independent {
+		input[0] = uint64(pass)
+		input[1] = uint64(lane)
+		input[2] = uint64(slice)
+		input[3] = uint64(s.memory)
+		input[4] = uint64(s.passes)
+		input[5] = typeArgon2id
+	}
+
+	start := uint32(0)
+	if pass == 0 && slice == 0 {
+		start = 2
+		if independent {
+			nextAddresses(&address, &input, &zero)
+		}
+	}
+
+	offset := lane*s.laneLength + slice*s.segmentLength + start
+	for index := start; index < s.segmentLength; index, offset = index+1, offset+1 {
+		prev := offset - 1
+		if offset%s.laneLength == 0 {
+			prev = offset + s.laneLength - 1
+		}
+
+		var random uint64
+		if independent {
+			if index%addressesSize == 0 {
+				nextAddresses(&address, &input, &zero)
+			}
+			random = address[index%addressesSize]
+		} else {
+			random = s.blocks[prev][0]
+		}
+
+		refLane := uint32(random>>32) % s.lanes
+		if pass == 0 && slice == 0 {
+			refLane = lane
+		}
+		refIndex := s.indexAlpha(pass, slice, index, uint32(random), refLane == lane)
+
+		ref := &s.blocks[refLane*s.laneLength+refIndex]
+		fillBlock(&s.blocks[prev], ref, &s.blocks[offset], pass > 0)
+	}
+}
+
+func (s *state) indexAlpha(pass, slice, index, random uint32, sameLane bool) uint32 {
+	var area uint32
+	switch {
+	case pass == 0 && slice == 0:
+		area = index - 1
+	case pass == 0 && sameLane:
+		area = slice*s.segmentLength + index - 1
+	case pass == 0:
+		area = slice * s.segmentLength
+		if index == 0 {
+			area--
+		}
+	case sameLane:
+		area = s.laneLength - s.segmentLength + index - 1
+	default:
+		area = s.laneLength - s.segmentLength
+		if index == 0 {
+			area--
+		}
+	}
+
+	relative := uint64(random)
+	relative = relative * relative >> 32
+	relative = uint64(area) - 1 - (uint64(area) * relative >> 32)
+
+	startPosition := uint32(0)
+	if pass != 0 && slice != syncPoints-1 {
+		startPosition = (slice + 1) * s.segmentLength
+	}
+
+	return uint32((uint64(startPosition) + relative) % uint64(s.laneLength))
+}
+
+func nextAddresses(address, input, zero *block) {
+	input[6]++
+	fillBlock(zero, input, address, false)
+	fillBlock(zero, address, address, false)
+}
+
+func fillBlock(prev, ref, next *block, xor bool) {
+	var r, tmp block
+	for i := range r {
+		r[i] = prev[i] ^ ref[i]
+	}
+	tmp = r
+	if xor {
+		for i := range tmp {
+			tmp[i] ^= next[i]
+		}
+	}
+
+	for i := 0; i < 8; i++ {
+		row := r[16*i : 16*i+16]
+		permute(&row[0], &row[1], &row[2], &row[3], &row[4], &row[5], &row[6], &row[7],
+			&row[8], &row[9], &row[10], &row[11], &row[12], &row[13], &row[14], &row[15])
+	}
+	for i := 0; i < 8; i++ {
+		c := 2 * i
+		permute(&r[c], &r[c+1], &r[c+16], &r[c+17], &r[c+32], &r[c+33], &r[c+48], &r[c+49],
+			&r[c+64], &r[c+65], &r[c+80], &r[c+81], &r[c+96], &r[c+97], &r[c+112], &r[c+113])
+	}
+
+	for i := range next {
+		next[i] = tmp[i] ^ r[i]
+	}
+}
+
+func permute(v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15 *uint64) {
+	mix(v0, v4, v8, v12)
+	mix(v1, v5, v9, v13)
+	mix(v2, v6, v10, v14)
+	mix(v3, v7, v11, v15)
+	mix(v0, v5, v10, v15)
+	mix(v1, v6, v11, v12)
+	mix(v2, v7, v8, v13)
+	mix(v3, v4, v9, v14)
+}
+
+func mix(a, b, c, d *uint64) {
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = bits.RotateLeft64(*d^*a, -32)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = bits.RotateLeft64(*b^*c, -24)
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = bits.RotateLeft64(*d^*a, -16)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = bits.RotateLeft64(*b^*c, -63)
+}
+
+func initialHash(password, salt []byte, params Params) [blake2b.Size]byte {
+	var buf []byte
+	for _, v := range []uint32{uint32(params.Parallelism), params.KeyLength, params.Memory, params.Time, Version, typeArgon2id} {
+		buf = binary.LittleEndian.AppendUint32(buf, v)
+	}
+	for _, field := range [][]byte{password, salt, params.Secret, params.Data} {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(field)))
+		buf = append(buf, field...)
+	}
+
+	var h0 [blake2b.Size]byte
+	copy(h0[:], blake2b.Sum512(buf))
+	return h0
+}
+
+func variableHash(input []byte, size uint32) []byte {
+	prefixed := binary.LittleEndian.AppendUint32(nil, size)
+	prefixed = append(prefixed, input...)
+
+	if size <= blake2b.Size {
+		d, _ := blake2b.New(int(size), nil)
+		d.Write(prefixed)
+		return d.Sum(nil)
+	}
+
+	out := make([]byte, 0, size)
+	v := blake2b.Sum512(prefixed)
+	out = append(out, v[:32]...)
+	for remaining := size - 32; remaining > blake2b.Size; remaining -= 32 {
+		v = blake2b.Sum512(v)
+		out = append(out, v[:32]...)
+	}
+
+	d, _ := blake2b.New(int(size)-len(out), nil)
+	d.Write(v)
+	return d.Sum(out)
+}
+
+func decodeBlock(b []byte) block {
+	var out block
+	for i := range out {
+		out[i] = binary.LittleEndian.Uint64(b[i*8:])
+	}
+	return out
+}
+
+func encodeBlock(b *block) []byte {
+	out := make([]byte, blockWords*8)
+	for i, w := range b {
+		binary.LittleEndian.PutUint64(out[i*8:], w)
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package argon2
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRFC9106Vector(t *testing.T) {
+	params := Params{
+		Time:        3,
+		Memory:      32,
+		Parallelism: 4,
+		KeyLength:   32,
+		Secret:      bytes.Repeat([]byte{0x03}, 8),
+		Data:        bytes.Repeat([]byte{0x04}, 12),
+	}
+
+	key, err := IDKey(context.Background(), bytes.Repeat([]byte{0x01}, 32), bytes.Repeat([]byte{0x02}, 16), params)
+	require.NoError(t, err)
+	assert.Equal(t, "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659", hex.EncodeToString(key))
+}
+
+func TestDifferentInputs(t *testing.T) {
+	ctx := context.Background()
+	params := Params{Time: 1, Memory: 64, Parallelism: 1, KeyLength: 100}
+	salt := []byte("saltsalt")
+
+	a, err := IDKey(ctx, []byte("password"), salt, params)
+	require.NoError(t, err)
+	assert.Len(t, a, 100)
+
+	b, err := IDKey(ctx, []byte("passw0rd"), salt, params)
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b)
+
+	params.Time = 2
+	c, err := IDKey(ctx, []byte("password"), salt, params)
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+}
+
+func TestInvalidParameters(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := IDKey(ctx, nil, []byte("short"), DefaultParams())
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = IDKey(ctx, nil, []byte("saltsalt"), Params{Time: 1, Memory: 8, Parallelism: 2, KeyLength: 32})
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = IDKey(cancelled, nil, []byte("saltsalt"), Params{Time: 1, Memory: 8, Parallelism: 1, KeyLength: 32})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,39 @@
+package hkdf
+
+import (
+	"hash"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/mac/hmac"
+)
+
+func Extract(newHash func() hash.Hash, secret, salt []byte) []byte {
+	if salt == nil {
+		salt = make([]byte, newHash().Size())
+	}
+	return hmac.Sum(newHash, salt, secret)
+}
+
+func Expand(newHash func() hash.Hash, prk, info []byte, length int) ([]byte, error) {
+	size := newHash().Size()
+	if length < 0 || length > 255*size {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "output length %d: %w", length)
+	}
+
+	h := hmac.New(newHash, prk)
+	out := make([]byte, 0, length+size)
+	var block []byte
+	for counter := byte(1); len(out) < length; counter++ {
+		h.Reset()
+		h.Write(block)
+		h.Write(info)
+		h.Write([]byte{counter})
+		block = h.Sum(nil)
+		out = append(out, block...)
+	}
+	return out[:length], nil
+}
+
+func Key(newHash func() hash.Hash, secret, salt, info []byte, length int) ([]byte, error) {
+	return Expand(newHash, Extract(newHash, secret, salt), info, length)
+}
@@ -0,0 +1,213 @@
+package keyfile
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+)
+
+type Encoding string
+
+const (
+	Version = 1
+
+	Binary Encoding = "binary"
+	Hex    Encoding = "hex"
+	Base64 Encoding = "base64"
+
+	idSize        = 8
+	checksumSize  = 4
+	maxHeaderSize = 64 * 1024
+)
+
+var magic = []byte("MKKEY001")
+
+type Key struct {
+	Algorithm string
+	Created   time.Time
+	ID        string
+	Material  []byte
+}
+
+type header struct {
+	Version   int       `json:"version"`
+	Algorithm string    `json:"algorithm"`
+	Created   time.Time `json:"created"`
+	ID        string    `json:"id"`
+	Encoding  Encoding  `json:"encoding"`
+	Checksum  string    `json:"checksum"`
+}
+
+func Generate(algorithm string, size int) (*Key, error) {
+	if size <= 0 {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	material := make([]byte, size)
+	if _, err := rand.Read(material); err != nil {
+		return nil, errors.Annotate(err, "failed to generate key: %w")
+	}
+	id := make([]byte, idSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.Annotate(err, "failed to generate key id: %w")
+	}
+
+	return &Key{
+		Algorithm: algorithm,
+		Created:   time.Now().UTC().Truncate(time.Second),
+		ID:        hex.EncodeToString(id),
+		Material:  material,
+	}, nil
+}
+
+func (k *Key) Checksum() string {
+	sum := sha256.Sum256(k.Material)
+	return hex.EncodeToString(sum[:checksumSize])
+}
+
+func (k *Key) Marshal(encoding Encoding) ([]byte, error) {
+	if len(k.Material) == 0 {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	h, err := json.Marshal(header{
+		Version:   Version,
+		Algorithm: k.Algorithm,
+		Created:   k.Created,
+		ID:        k.ID,
+		Encoding:  encoding,
+		Checksum:  k.Checksum(),
+	})
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to encode key header: %w")
+	}
+
+	var out bytes.Buffer
+	switch encoding {
+	case Binary:
+		out.Write(magic)
+		_ = binary.Write(&out, binary.BigEndian, uint32(len(h)))
+		out.Write(h)
+		out.Write(k.Material)
+	case Hex:
+		out.Write(h)
+		out.WriteByte('\n')
+		out.WriteString(hex.EncodeToString(k.Material))
+		out.WriteByte('\n')
+	case Base64:
+		out.Write(h)
+		out.WriteByte('\n')
+		out.WriteString(base64.StdEncoding.EncodeToString(k.Material))
+		out.WriteByte('\n')
+	default:
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unknown key encoding %q: %w", encoding)
+	}
+	return out.Bytes(), nil
+}
+
+func Unmarshal(data []byte) (*Key, error) {
+	var (
+		h        header
+		material []byte
+		err      error
+	)
+
+	if bytes.HasPrefix(data, magic) {
+		h, material, err = parseBinary(data[len(magic):])
+	} else {
+		h, material, err = parseText(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Version != Version {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "unsupported key file version %d: %w", h.Version)
+	}
+
+	k := &Key{Algorithm: h.Algorithm, Created: h.Created, ID: h.ID, Material: material}
+	if len(material) == 0 {
+		return nil, errors.ErrInvalidKeySize
+	}
+	if subtle.ConstantTimeCompare([]byte(k.Checksum()), []byte(h.Checksum)) != 1 {
+		return nil, errors.Annotate(errors.ErrDigestMismatch, "key checksum: %w")
+	}
+	return k, nil
+}
+
+func parseBinary(data []byte) (header, []byte, error) {
+	var h header
+	if len(data) < 4 {
+		return h, nil, errors.ErrInvalidFormat
+	}
+
+	size := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if size > maxHeaderSize || uint64(size) > uint64(len(data)) {
+		return h, nil, errors.Annotate(errors.ErrInvalidFormat, "truncated key header: %w")
+	}
+	if err := json.Unmarshal(data[:size], &h); err != nil {
+		return h, nil, errors.Annotate(errors.ErrInvalidFormat, "malformed key header: %w")
+	}
+	if h.Encoding != Binary {
+		return h, nil, errors.Annotate(errors.ErrInvalidFormat, "binary key file declares %q encoding: %w", h.Encoding)
+	}
+	return h, append([]byte{}, data[size:]...), nil
+}
+
+func parseText(data []byte) (header, []byte, error) {
+	var h header
+	line, body, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return h, nil, errors.Annotate(errors.ErrInvalidFormat, "missing key material: %w")
+	}
+	if err := json.Unmarshal(line, &h); err != nil {
+		return h, nil, errors.Annotate(errors.ErrInvalidFormat, "malformed key header: %w")
+	}
+
+	body = bytes.TrimSpace(body)
+	var (
+		material []byte
+		err      error
+	)
+	switch h.Encoding {
+	case Hex:
+		material, err = hex.DecodeString(string(body))
+	case Base64:
+		material, err = base64.StdEncoding.DecodeString(string(body))
+	default:
+		return h, nil, errors.Annotate(errors.ErrInvalidFormat, "unknown key encoding %q: %w", h.Encoding)
+	}
+	if err != nil {
+		return h, nil, errors.Annotate(errors.ErrInvalidFormat, "malformed key material: %w")
+	}
+	return h, material, nil
+}
+
+func Save(path string, k *Key, encoding Encoding) error {
+	data, err := k.Marshal(encoding)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return errors.Annotate(err, "failed to write key file: %w")
+	}
+	return nil
+}
+
+func Load(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to read key file: %w")
+	}
+	return Unmarshal(data)
+}
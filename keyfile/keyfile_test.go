@@ -0,0 +1,91 @@
+package keyfile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+func TestRoundTrip(t *testing.T) {
+	key, err := Generate("aes-256", 32)
+	require.NoError(t, err)
+	assert.Len(t, key.ID, 2*idSize)
+
+	for _, encoding := range []Encoding{Binary, Hex, Base64} {
+		t.Run(string(encoding), func(t *testing.T) {
+			data, err := key.Marshal(encoding)
+			require.NoError(t, err)
+
+			loaded, err := Unmarshal(data)
+			require.NoError(t, err)
+			assert.Equal(t, key.Algorithm, loaded.Algorithm)
+			assert.Equal(t, key.ID, loaded.ID)
+			assert.True(t, key.Created.Equal(loaded.Created))
+			assert.Equal(t, key.Material, loaded.Material)
+		})
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	key := &Key{Algorithm: "des", ID: "0011223344556677", Material: []byte("8bytekey")}
+
+	data, err := key.Marshal(Hex)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), `"algorithm":"des"`)
+	assert.Contains(t, string(lines[0]), `"checksum":"`+key.Checksum()+`"`)
+	assert.Equal(t, "3862797465", string(lines[1][:10]))
+}
+
+func TestSaveLoad(t *testing.T) {
+	key, err := Generate("chacha20", 32)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.b64")
+	require.NoError(t, Save(path, key, Base64))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, key.Material, loaded.Material)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func TestCorruption(t *testing.T) {
+	key := &Key{Algorithm: "des", ID: "0011223344556677", Material: []byte("8bytekey")}
+
+	data, err := key.Marshal(Binary)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 1
+	_, err = Unmarshal(data)
+	require.ErrorIs(t, err, errors.ErrDigestMismatch)
+
+	data, err = key.Marshal(Hex)
+	require.NoError(t, err)
+	_, err = Unmarshal(bytes.Replace(data, []byte("\n38"), []byte("\nzz"), 1))
+	require.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = Unmarshal(data[:len(magic)/2])
+	require.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = Unmarshal(append(append([]byte{}, magic...), 0, 0, 0xFF, 0xFF))
+	require.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = key.Marshal(Encoding("pem"))
+	require.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = Generate("des", 0)
+	require.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
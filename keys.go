@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	stdcrypto "crypto"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/masterkusok/crypto/sign"
+)
+
+const (
+	MinKeyBits     = 1024
+	DefaultKeyBits = 2048
+
+	keyPrimeProbability = 0.999999
+)
+
+var (
+	_ sign.Signer   = (*PrivateKey)(nil)
+	_ sign.Verifier = (*PrivateKey)(nil)
+	_ sign.Verifier = (*PublicKey)(nil)
+)
+
+// PublicKey and PrivateKey hide the RSA key layout so the facade can change
+// algorithms without breaking callers. Signatures are RSA-PSS over SHA-256.
+type PublicKey struct {
+	key *rsa.PublicKey
+}
+
+type PrivateKey struct {
+	PublicKey
+	key *rsa.PrivateKey
+}
+
+func GenerateKeyPair(bits int) (*PrivateKey, error) {
+	if bits < MinKeyBits || bits%2 != 0 {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	r := rsa.NewRSA(cryptoMath.NewMillerRabinTest(), keyPrimeProbability, bits/2)
+	if err := r.GenerateKeyPair(); err != nil {
+		return nil, errors.Annotate(err, "generating key pair: %w")
+	}
+
+	priv := r.GetPrivateKey()
+	return &PrivateKey{PublicKey: PublicKey{key: &priv.PublicKey}, key: priv}, nil
+}
+
+func (k *PrivateKey) Public() *PublicKey {
+	return &k.PublicKey
+}
+
+func (k *PrivateKey) Sign(message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+	return rsa.SignPSS(k.key, stdcrypto.SHA256, digest[:])
+}
+
+func (k *PublicKey) Verify(message, signature []byte) error {
+	digest := sha256.Sum256(message)
+	return rsa.VerifyPSS(k.key, stdcrypto.SHA256, digest[:], signature)
+}
+
+func (k *PublicKey) Bits() int {
+	return k.key.N.BitLen()
+}
@@ -0,0 +1,34 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	priv, err := GenerateKeyPair(MinKeyBits)
+	require.NoError(t, err)
+	pub := priv.Public()
+	assert.Equal(t, MinKeyBits, pub.Bits())
+
+	message := []byte("signed statement")
+	signature, err := priv.Sign(message)
+	require.NoError(t, err)
+
+	require.NoError(t, pub.Verify(message, signature))
+	require.NoError(t, priv.Verify(message, signature))
+	assert.Error(t, pub.Verify([]byte("other statement"), signature))
+
+	signature[0] ^= 1
+	assert.Error(t, pub.Verify(message, signature))
+}
+
+func TestGenerateKeyPairInvalid(t *testing.T) {
+	_, err := GenerateKeyPair(512)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+	_, err = GenerateKeyPair(MinKeyBits + 1)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
@@ -0,0 +1,93 @@
+package kvstore
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/mac/hmac"
+)
+
+const blindedKeySize = sha256.Size
+
+// The index records the ID of the log it describes and is only used with
+// that log.
+var indexMagic = []byte("MKKVI002")
+
+func (s *Store) indexPath() string {
+	return s.path + ".idx"
+}
+
+func (s *Store) writeIndex() error {
+	out := append([]byte{}, indexMagic...)
+	out = append(out, s.logID...)
+	out = binary.BigEndian.AppendUint64(out, uint64(s.size))
+	out = binary.BigEndian.AppendUint64(out, s.seq)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(s.index)))
+	for blinded, offset := range s.index {
+		out = append(out, blinded...)
+		out = binary.BigEndian.AppendUint64(out, uint64(offset))
+	}
+	out = append(out, hmac.Sum(sha256.New, s.indexKey, out)...)
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".kvstore-index-*")
+	if err != nil {
+		return errors.Annotate(err, "creating index: %w")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return errors.Annotate(err, "writing index: %w")
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Annotate(err, "syncing index: %w")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Annotate(err, "closing index: %w")
+	}
+	if err := os.Rename(tmp.Name(), s.indexPath()); err != nil {
+		return errors.Annotate(err, "replacing index: %w")
+	}
+	return nil
+}
+
+func (s *Store) loadIndex() (map[string]int64, int64, uint64, error) {
+	data, err := os.ReadFile(s.indexPath())
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	headerSize := len(indexMagic) + logIDSize + 8 + 8 + 4
+	if len(data) < headerSize+sha256.Size || string(data[:len(indexMagic)]) != string(indexMagic) {
+		return nil, 0, 0, errors.ErrInvalidFormat
+	}
+
+	body, tag := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if err := hmac.Verify(sha256.New, s.indexKey, body, tag); err != nil {
+		return nil, 0, 0, err
+	}
+
+	rest := body[len(indexMagic):]
+	if string(rest[:logIDSize]) != string(s.logID) {
+		return nil, 0, 0, errors.Annotate(errors.ErrInvalidFormat, "index belongs to another log: %w")
+	}
+	rest = rest[logIDSize:]
+	size := int64(binary.BigEndian.Uint64(rest))
+	seq := binary.BigEndian.Uint64(rest[8:])
+	count := int(binary.BigEndian.Uint32(rest[16:]))
+	rest = rest[20:]
+	if len(rest) != count*(blindedKeySize+8) {
+		return nil, 0, 0, errors.ErrInvalidFormat
+	}
+
+	index := make(map[string]int64, count)
+	for i := 0; i < count; i++ {
+		entry := rest[i*(blindedKeySize+8):]
+		index[string(entry[:blindedKeySize])] = int64(binary.BigEndian.Uint64(entry[blindedKeySize:]))
+	}
+	return index, size, seq, nil
+}
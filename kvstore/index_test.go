@@ -0,0 +1,54 @@
+package kvstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTamperedIndexIsRebuilt(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store")
+
+	s := openStore(t, path)
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("1")))
+	require.NoError(t, s.Put(ctx, []byte("beta"), []byte("2")))
+	require.NoError(t, s.Close())
+
+	raw, err := os.ReadFile(path + ".idx")
+	require.NoError(t, err)
+	raw[len(indexMagic)+logIDSize+20+blindedKeySize+7] ^= 0x08
+	require.NoError(t, os.WriteFile(path+".idx", raw, 0o600))
+
+	_, _, _, err = s.loadIndex()
+	assert.ErrorIs(t, err, errors.ErrInvalidMAC)
+
+	s = openStore(t, path)
+	defer s.Close()
+	for key, want := range map[string]string{"alpha": "1", "beta": "2"} {
+		value, err := s.Get(ctx, []byte(key))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(value))
+	}
+}
+
+func TestTruncatedLogDetectedByIndex(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store")
+
+	s := openStore(t, path)
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("1")))
+	offset := s.size
+	require.NoError(t, s.Put(ctx, []byte("beta"), []byte("2")))
+	require.NoError(t, s.Close())
+
+	require.NoError(t, os.Truncate(path, offset))
+
+	_, err := Open(path, testKey, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
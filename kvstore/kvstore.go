@@ -0,0 +1,389 @@
+package kvstore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/sha256"
+	"github.com/masterkusok/crypto/kdf/hkdf"
+	"github.com/masterkusok/crypto/mac/hmac"
+)
+
+const (
+	KeySize = 32
+
+	opPut    = 1
+	opDelete = 2
+
+	recordHeaderSize = 4 + 8
+	maxRecordSize    = 64 * 1024 * 1024
+
+	logIDSize = 16
+)
+
+// The log header is the magic followed by a random log ID. Every record
+// binds the ID and its sequence number as associated data, so records
+// cannot be moved between logs even though Compact restarts the sequence.
+var logMagic = []byte("MKKVS002")
+
+func logHeaderSize() int64 {
+	return int64(len(logMagic) + logIDSize)
+}
+
+type Options struct {
+	AEAD string
+}
+
+func DefaultOptions() Options {
+	return Options{AEAD: aead.ChaCha20Poly1305Name}
+}
+
+type Store struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	cipher   aead.AEAD
+	indexKey []byte
+	logID    []byte
+	index    map[string]int64
+	size     int64
+	seq      uint64
+}
+
+func Open(path string, key []byte, opts *Options) (*Store, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	options := DefaultOptions()
+	if opts != nil {
+		options = *opts
+	}
+
+	recordKey, err := hkdf.Key(sha256.New, key, nil, []byte("kvstore record"), KeySize)
+	if err != nil {
+		return nil, err
+	}
+	indexKey, err := hkdf.Key(sha256.New, key, nil, []byte("kvstore index"), KeySize)
+	if err != nil {
+		return nil, err
+	}
+	cipher, err := aead.New(options.AEAD, recordKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{path: path, cipher: cipher, indexKey: indexKey}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) open() error {
+	file, err := os.OpenFile(s.path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return errors.Annotate(err, "opening log: %w")
+	}
+	s.file = file
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Annotate(err, "reading log: %w")
+	}
+
+	if info.Size() == 0 {
+		if err := s.writeHeader(file); err != nil {
+			file.Close()
+			return err
+		}
+		s.size, s.seq, s.index = logHeaderSize(), 0, make(map[string]int64)
+		return nil
+	}
+
+	header := make([]byte, logHeaderSize())
+	if _, err := file.ReadAt(header, 0); err != nil || string(header[:len(logMagic)]) != string(logMagic) {
+		file.Close()
+		return errors.ErrInvalidFormat
+	}
+	s.logID = header[len(logMagic):]
+
+	index, size, seq, err := s.loadIndex()
+	if err == nil && size > info.Size() {
+		file.Close()
+		return errors.Annotate(errors.ErrInvalidFormat, "log is shorter than its index: %w")
+	}
+	if err == nil && size == info.Size() {
+		s.index, s.size, s.seq = index, size, seq
+		return nil
+	}
+
+	if err := s.rebuild(info.Size()); err != nil {
+		file.Close()
+		return err
+	}
+	return nil
+}
+
+func (s *Store) Put(ctx context.Context, key, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset, err := s.append(ctx, opPut, key, value)
+	if err != nil {
+		return err
+	}
+	s.index[s.blind(key)] = offset
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset, ok := s.index[s.blind(key)]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	rec, _, err := s.readRecord(ctx, s.file, offset, s.size)
+	if err != nil {
+		return nil, err
+	}
+	if string(rec.key) != string(key) {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "index points at a different key: %w")
+	}
+	return rec.value, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blinded := s.blind(key)
+	if _, ok := s.index[blinded]; !ok {
+		return errors.ErrNotFound
+	}
+
+	if _, err := s.append(ctx, opDelete, key, nil); err != nil {
+		return err
+	}
+	delete(s.index, blinded)
+	return nil
+}
+
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.index)
+}
+
+func (s *Store) Compact(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".kvstore-*")
+	if err != nil {
+		return errors.Annotate(err, "creating compacted log: %w")
+	}
+	defer os.Remove(tmp.Name())
+
+	compacted := &Store{file: tmp, cipher: s.cipher, indexKey: s.indexKey, index: make(map[string]int64)}
+	if err := compacted.writeHeader(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	compacted.size = logHeaderSize()
+
+	for _, offset := range s.index {
+		rec, _, err := s.readRecord(ctx, s.file, offset, s.size)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		newOffset, err := compacted.append(ctx, opPut, rec.key, rec.value)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		compacted.index[s.blind(rec.key)] = newOffset
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Annotate(err, "syncing compacted log: %w")
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		tmp.Close()
+		return errors.Annotate(err, "replacing log: %w")
+	}
+
+	s.file.Close()
+	s.file, s.index, s.size, s.seq = tmp, compacted.index, compacted.size, compacted.seq
+	s.logID = compacted.logID
+	return s.writeIndex()
+}
+
+// writeHeader starts a log under a fresh log ID.
+func (s *Store) writeHeader(file *os.File) error {
+	s.logID = make([]byte, logIDSize)
+	if _, err := rand.Read(s.logID); err != nil {
+		return errors.Annotate(err, "generating log ID: %w")
+	}
+	if _, err := file.WriteAt(append(append([]byte{}, logMagic...), s.logID...), 0); err != nil {
+		return errors.Annotate(err, "writing log header: %w")
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return errors.Annotate(err, "syncing log: %w")
+	}
+	if err := s.writeIndex(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+type record struct {
+	op    byte
+	seq   uint64
+	key   []byte
+	value []byte
+}
+
+func (s *Store) append(ctx context.Context, op byte, key, value []byte) (int64, error) {
+	if len(key) == 0 {
+		return 0, errors.Annotate(errors.ErrInvalidParameters, "empty key: %w")
+	}
+
+	payload := []byte{op}
+	payload = binary.BigEndian.AppendUint32(payload, uint32(len(key)))
+	payload = append(payload, key...)
+	payload = append(payload, value...)
+
+	nonce := make([]byte, s.cipher.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return 0, errors.Annotate(err, "generating nonce: %w")
+	}
+	sealed, err := s.cipher.Seal(ctx, nonce, payload, s.recordAAD(s.seq))
+	if err != nil {
+		return 0, err
+	}
+
+	body := append(nonce, sealed...)
+	if len(body) > maxRecordSize {
+		return 0, errors.ErrInvalidDataLength
+	}
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+	out = binary.BigEndian.AppendUint64(out, s.seq)
+	out = append(out, body...)
+
+	offset := s.size
+	if _, err := s.file.WriteAt(out, offset); err != nil {
+		return 0, errors.Annotate(err, "appending record: %w")
+	}
+	s.size += int64(len(out))
+	s.seq++
+	return offset, nil
+}
+
+func (s *Store) readRecord(ctx context.Context, r io.ReaderAt, offset, limit int64) (*record, int64, error) {
+	header := make([]byte, recordHeaderSize)
+	if limit-offset < recordHeaderSize {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if _, err := r.ReadAt(header, offset); err != nil {
+		return nil, 0, errors.Annotate(err, "reading record: %w")
+	}
+
+	length := int64(binary.BigEndian.Uint32(header))
+	if length > maxRecordSize {
+		return nil, 0, errors.ErrInvalidFormat
+	}
+	if limit-offset-recordHeaderSize < length {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+
+	body := make([]byte, length)
+	if _, err := r.ReadAt(body, offset+recordHeaderSize); err != nil {
+		return nil, 0, errors.Annotate(err, "reading record: %w")
+	}
+
+	nonceSize := s.cipher.NonceSize()
+	if len(body) < nonceSize {
+		return nil, 0, errors.ErrInvalidFormat
+	}
+	seq := binary.BigEndian.Uint64(header[4:])
+	payload, err := s.cipher.Open(ctx, body[:nonceSize], body[nonceSize:], s.recordAAD(seq))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(payload) < 5 || (payload[0] != opPut && payload[0] != opDelete) {
+		return nil, 0, errors.ErrInvalidFormat
+	}
+
+	keyLen := int(binary.BigEndian.Uint32(payload[1:]))
+	if keyLen > len(payload)-5 {
+		return nil, 0, errors.ErrInvalidFormat
+	}
+
+	rec := &record{op: payload[0], seq: seq, key: payload[5 : 5+keyLen], value: payload[5+keyLen:]}
+	return rec, recordHeaderSize + length, nil
+}
+
+func (s *Store) rebuild(size int64) error {
+	ctx := context.Background()
+	s.index = make(map[string]int64)
+	s.size = logHeaderSize()
+	s.seq = 0
+
+	for s.size < size {
+		rec, recordSize, err := s.readRecord(ctx, s.file, s.size, size)
+		if err == io.ErrUnexpectedEOF {
+			if err := s.file.Truncate(s.size); err != nil {
+				return errors.Annotate(err, "truncating torn record: %w")
+			}
+			break
+		}
+		if err != nil {
+			return errors.Annotate(err, "record %d: %w", s.seq)
+		}
+		if rec.seq != s.seq {
+			return errors.Annotate(errors.ErrInvalidFormat, "record %d out of sequence: %w", s.seq)
+		}
+
+		switch rec.op {
+		case opPut:
+			s.index[s.blind(rec.key)] = s.size
+		case opDelete:
+			delete(s.index, s.blind(rec.key))
+		}
+		s.size += recordSize
+		s.seq++
+	}
+	return nil
+}
+
+func (s *Store) blind(key []byte) string {
+	return string(hmac.Sum(sha256.New, s.indexKey, key))
+}
+
+func (s *Store) recordAAD(seq uint64) []byte {
+	aad := append(append([]byte{}, logMagic...), s.logID...)
+	return binary.BigEndian.AppendUint64(aad, seq)
+}
@@ -0,0 +1,189 @@
+package kvstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, KeySize)
+
+func openStore(t *testing.T, path string) *Store {
+	s, err := Open(path, testKey, nil)
+	require.NoError(t, err)
+	return s
+}
+
+func TestPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	s := openStore(t, filepath.Join(t.TempDir(), "store"))
+	defer s.Close()
+
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("1")))
+	require.NoError(t, s.Put(ctx, []byte("beta"), []byte("2")))
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("3")))
+
+	value, err := s.Get(ctx, []byte("alpha"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("3"), value)
+	assert.Equal(t, 2, s.Len())
+
+	require.NoError(t, s.Delete(ctx, []byte("beta")))
+	_, err = s.Get(ctx, []byte("beta"))
+	assert.ErrorIs(t, err, errors.ErrNotFound)
+	assert.ErrorIs(t, s.Delete(ctx, []byte("beta")), errors.ErrNotFound)
+	assert.ErrorIs(t, s.Put(ctx, nil, []byte("x")), errors.ErrInvalidParameters)
+}
+
+func TestPersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store")
+
+	s := openStore(t, path)
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("1")))
+	require.NoError(t, s.Put(ctx, []byte("beta"), []byte("2")))
+	require.NoError(t, s.Delete(ctx, []byte("alpha")))
+	require.NoError(t, s.Close())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "beta")
+
+	for _, withIndex := range []bool{true, false} {
+		if !withIndex {
+			require.NoError(t, os.Remove(path+".idx"))
+		}
+
+		s = openStore(t, path)
+		_, err = s.Get(ctx, []byte("alpha"))
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+		value, err := s.Get(ctx, []byte("beta"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("2"), value)
+
+		require.NoError(t, s.Put(ctx, []byte("gamma"), []byte("3")))
+		require.NoError(t, s.Close())
+	}
+}
+
+func TestWrongKeyRejected(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store")
+
+	s := openStore(t, path)
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("1")))
+	require.NoError(t, s.Close())
+
+	_, err := Open(path, bytes.Repeat([]byte{0x24}, KeySize), nil)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = Open(path, []byte("short"), nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
+
+func TestTornTailIsDropped(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store")
+
+	s := openStore(t, path)
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("1")))
+	require.NoError(t, s.Put(ctx, []byte("beta"), []byte("2")))
+	require.NoError(t, s.file.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-3))
+
+	s = openStore(t, path)
+	defer s.Close()
+	value, err := s.Get(ctx, []byte("alpha"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+	_, err = s.Get(ctx, []byte("beta"))
+	assert.ErrorIs(t, err, errors.ErrNotFound)
+}
+
+func TestTamperedRecordDetected(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store")
+
+	s := openStore(t, path)
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("1")))
+	require.NoError(t, s.Close())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	raw[len(raw)-1] ^= 1
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+
+	s = openStore(t, path)
+	defer s.Close()
+	_, err = s.Get(ctx, []byte("alpha"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+}
+
+func TestCompact(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store")
+
+	s := openStore(t, path)
+	for i := 0; i < 20; i++ {
+		require.NoError(t, s.Put(ctx, []byte(fmt.Sprintf("key-%d", i%4)), []byte(fmt.Sprintf("value-%d", i))))
+	}
+	require.NoError(t, s.Delete(ctx, []byte("key-0")))
+
+	before, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Compact(ctx))
+	after, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size())
+
+	require.NoError(t, s.Put(ctx, []byte("key-9"), []byte("fresh")))
+	require.NoError(t, s.Close())
+
+	s = openStore(t, path)
+	defer s.Close()
+	assert.Equal(t, 4, s.Len())
+	for i, want := range map[int]string{1: "value-17", 2: "value-18", 3: "value-19"} {
+		value, err := s.Get(ctx, []byte(fmt.Sprintf("key-%d", i)))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(value))
+	}
+	_, err = s.Get(ctx, []byte("key-0"))
+	assert.ErrorIs(t, err, errors.ErrNotFound)
+}
+
+func TestCompactRejectsRecordsFromOlderLog(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store")
+
+	s := openStore(t, path)
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("revoked")))
+	require.NoError(t, s.Put(ctx, []byte("alpha"), []byte("current")))
+	require.NoError(t, s.Close())
+	old, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	s = openStore(t, path)
+	require.NoError(t, s.Compact(ctx))
+	require.NoError(t, s.Close())
+	compacted, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// The compacted log restarts at sequence number 0, the same as the
+	// first record of the older log.
+	spliced := append(compacted[:logHeaderSize()], old[logHeaderSize():]...)
+	require.NoError(t, os.WriteFile(path, spliced, 0o600))
+	require.NoError(t, os.Remove(path+".idx"))
+
+	_, err = Open(path, testKey, nil)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+}
@@ -0,0 +1,117 @@
+package mac
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Padding int
+
+const (
+	PaddingZero Padding = iota + 1
+	PaddingBit
+)
+
+type Protection struct {
+	fixedLength int
+	prepend     bool
+}
+
+func FixedLength(length int) Protection {
+	return Protection{fixedLength: length}
+}
+
+var LengthPrepend = Protection{prepend: true}
+
+func (p Protection) valid() bool {
+	return p.prepend != (p.fixedLength > 0)
+}
+
+type CBCMAC struct {
+	block      cipher.BlockCipher
+	protection Protection
+	padding    Padding
+}
+
+func NewCBCMAC(ctx context.Context, block cipher.BlockCipher, key []byte, protection Protection, padding Padding) (*CBCMAC, error) {
+	if !protection.valid() {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "length protection is required: %w")
+	}
+	if padding != PaddingZero && padding != PaddingBit {
+		return nil, errors.ErrInvalidPaddingScheme
+	}
+	if err := block.SetKey(ctx, key); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+
+	return &CBCMAC{block: block, protection: protection, padding: padding}, nil
+}
+
+func (m *CBCMAC) Size() int {
+	return m.block.BlockSize()
+}
+
+func (m *CBCMAC) Sum(ctx context.Context, message []byte) ([]byte, error) {
+	if m.protection.fixedLength > 0 && len(message) != m.protection.fixedLength {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	data := message
+	if m.protection.prepend {
+		data = lengthBlock(len(message), m.block.BlockSize())
+		data = append(data, message...)
+	}
+
+	return chain(ctx, m.block, pad(data, m.block.BlockSize(), m.padding))
+}
+
+func (m *CBCMAC) Verify(ctx context.Context, message, tag []byte) error {
+	expected, err := m.Sum(ctx, message)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return errors.ErrInvalidMAC
+	}
+	return nil
+}
+
+func chain(ctx context.Context, block cipher.BlockCipher, data []byte) ([]byte, error) {
+	blockSize := block.BlockSize()
+	state := make([]byte, blockSize)
+
+	for i := 0; i < len(data); i += blockSize {
+		for j := range state {
+			state[j] ^= data[i+j]
+		}
+
+		encrypted, err := block.Encrypt(ctx, state)
+		if err != nil {
+			return nil, err
+		}
+		state = encrypted
+	}
+
+	return state, nil
+}
+
+func pad(data []byte, blockSize int, padding Padding) []byte {
+	padded := append([]byte{}, data...)
+	if padding == PaddingBit {
+		padded = append(padded, 0x80)
+	}
+	for len(padded) == 0 || len(padded)%blockSize != 0 {
+		padded = append(padded, 0)
+	}
+	return padded
+}
+
+func lengthBlock(length, blockSize int) []byte {
+	block := make([]byte, max(blockSize, 8))
+	binary.BigEndian.PutUint64(block[len(block)-8:], uint64(length)*8)
+	return block
+}
@@ -0,0 +1,74 @@
+package mac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var desKey = []byte("01234567")
+
+func TestCBCMACRequiresProtection(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewCBCMAC(ctx, des.NewDES(), desKey, Protection{}, PaddingBit)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = NewCBCMAC(ctx, des.NewDES(), desKey, LengthPrepend, Padding(0))
+	assert.ErrorIs(t, err, errors.ErrInvalidPaddingScheme)
+}
+
+func TestCBCMACFixedLength(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewCBCMAC(ctx, des.NewDES(), desKey, FixedLength(16), PaddingZero)
+	require.NoError(t, err)
+
+	message := []byte("transfer 100 EUR")
+	tag, err := m.Sum(ctx, message)
+	require.NoError(t, err)
+	assert.Len(t, tag, 8)
+	assert.NoError(t, m.Verify(ctx, message, tag))
+
+	tampered := []byte("transfer 900 EUR")
+	assert.ErrorIs(t, m.Verify(ctx, tampered, tag), errors.ErrInvalidMAC)
+
+	_, err = m.Sum(ctx, append(message, message...))
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
+
+func TestCBCMACLengthPrependStopsExtension(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewCBCMAC(ctx, des.NewDES(), desKey, LengthPrepend, PaddingZero)
+	require.NoError(t, err)
+
+	message := []byte("pay bob")
+	tag, err := m.Sum(ctx, message)
+	require.NoError(t, err)
+
+	padded := pad(message, 8, PaddingZero)
+	forged := append(append([]byte{}, padded...), xorBytes(padded, tag)...)
+	assert.ErrorIs(t, m.Verify(ctx, forged, tag), errors.ErrInvalidMAC)
+
+	other, err := m.Sum(ctx, append(message, 0))
+	require.NoError(t, err)
+	assert.NotEqual(t, tag, other)
+}
+
+func TestPaddingMethods(t *testing.T) {
+	assert.Equal(t, make([]byte, 8), pad(nil, 8, PaddingZero))
+	assert.Equal(t, []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, pad(nil, 8, PaddingBit))
+	assert.Len(t, pad(make([]byte, 8), 8, PaddingZero), 8)
+	assert.Len(t, pad(make([]byte, 8), 8, PaddingBit), 16)
+}
+
+func xorBytes(a, b []byte) []byte {
+	result := make([]byte, len(a))
+	for i := range a {
+		result[i] = a[i] ^ b[i]
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package mac
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+type CMAC struct {
+	block  cipher.BlockCipher
+	k1, k2 []byte
+}
+
+func NewCMAC(ctx context.Context, block cipher.BlockCipher, key []byte) (*CMAC, error) {
+	rb, err := reductionConstant(block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+	if err := block.SetKey(ctx, key); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+
+	l, err := block.Encrypt(ctx, make([]byte, block.BlockSize()))
+	if err != nil {
+		return nil, err
+	}
+	k1 := double(l, rb)
+	return &CMAC{block: block, k1: k1, k2: double(k1, rb)}, nil
+}
+
+func (m *CMAC) Size() int {
+	return m.block.BlockSize()
+}
+
+func (m *CMAC) Sum(ctx context.Context, message []byte) ([]byte, error) {
+	size := m.block.BlockSize()
+
+	var last []byte
+	key := m.k1
+	if len(message) == 0 || len(message)%size != 0 {
+		last = pad(message[len(message)/size*size:], size, PaddingBit)
+		key = m.k2
+	} else {
+		last = message[len(message)-size:]
+	}
+
+	state, err := chain(ctx, m.block, message[:max(len(message)-1, 0)/size*size])
+	if err != nil {
+		return nil, err
+	}
+	for j := range state {
+		state[j] ^= last[j] ^ key[j]
+	}
+	return m.block.Encrypt(ctx, state)
+}
+
+func (m *CMAC) Verify(ctx context.Context, message, tag []byte) error {
+	expected, err := m.Sum(ctx, message)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return errors.ErrInvalidMAC
+	}
+	return nil
+}
+
+func reductionConstant(blockSize int) (byte, error) {
+	switch blockSize {
+	case 8:
+		return 0x1B, nil
+	case 16:
+		return 0x87, nil
+	default:
+		return 0, errors.ErrInvalidBlockSize
+	}
+}
+
+func double(b []byte, rb byte) []byte {
+	out := make([]byte, len(b))
+	carry := b[0] >> 7
+	for i := 0; i < len(b)-1; i++ {
+		out[i] = b[i]<<1 | b[i+1]>>7
+	}
+	out[len(b)-1] = b[len(b)-1]<<1 ^ rb*carry
+	return out
+}
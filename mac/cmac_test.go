@@ -0,0 +1,84 @@
+package mac
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCMACAES128(t *testing.T) {
+	ctx := context.Background()
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	message, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	m, err := NewCMAC(ctx, block, key)
+	require.NoError(t, err)
+	assert.Equal(t, "fbeed618357133667c85e08f7236a8de", hex.EncodeToString(m.k1))
+	assert.Equal(t, "f7ddac306ae266ccf90bc11ee46d513b", hex.EncodeToString(m.k2))
+
+	tests := []struct {
+		length int
+		want   string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+		{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+	for _, tt := range tests {
+		tag, err := m.Sum(ctx, message[:tt.length])
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, hex.EncodeToString(tag), tt.length)
+		assert.NoError(t, m.Verify(ctx, message[:tt.length], tag))
+	}
+
+	tag, err := m.Sum(ctx, message)
+	require.NoError(t, err)
+	assert.ErrorIs(t, m.Verify(ctx, message[:63], tag), errors.ErrInvalidMAC)
+}
+
+func TestCMACSubkeysGF64(t *testing.T) {
+	l, _ := hex.DecodeString("8000000000000001")
+
+	k1 := double(l, 0x1B)
+	assert.Equal(t, "0000000000000019", hex.EncodeToString(k1))
+	assert.Equal(t, "0000000000000032", hex.EncodeToString(double(k1, 0x1B)))
+}
+
+func TestCMAC64BitBlock(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewCMAC(ctx, des.NewDES(), desKey)
+	require.NoError(t, err)
+	assert.Equal(t, 8, m.Size())
+
+	for _, message := range [][]byte{nil, []byte("8 bytes!"), []byte("not a multiple")} {
+		tag, err := m.Sum(ctx, message)
+		require.NoError(t, err)
+		assert.Len(t, tag, 8)
+		assert.NoError(t, m.Verify(ctx, message, tag))
+	}
+
+	full, err := m.Sum(ctx, []byte("8 bytes!"))
+	require.NoError(t, err)
+	padded, err := m.Sum(ctx, []byte("8 bytes"))
+	require.NoError(t, err)
+	assert.NotEqual(t, full, padded)
+
+	_, err = NewCMAC(ctx, &wideBlock{}, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidBlockSize)
+}
+
+type wideBlock struct{}
+
+func (*wideBlock) SetKey(context.Context, []byte) error                { return nil }
+func (*wideBlock) Encrypt(_ context.Context, b []byte) ([]byte, error) { return b, nil }
+func (*wideBlock) Decrypt(_ context.Context, b []byte) ([]byte, error) { return b, nil }
+func (*wideBlock) BlockSize() int                                      { return 32 }
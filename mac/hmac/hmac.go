@@ -0,0 +1,82 @@
+package hmac
+
+import (
+	"crypto/subtle"
+	"hash"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type HMAC struct {
+	inner hash.Hash
+	outer hash.Hash
+	ipad  []byte
+	opad  []byte
+}
+
+var _ hash.Hash = (*HMAC)(nil)
+
+func New(newHash func() hash.Hash, key []byte) *HMAC {
+	h := &HMAC{inner: newHash(), outer: newHash()}
+
+	blockSize := h.inner.BlockSize()
+	if len(key) > blockSize {
+		h.outer.Write(key)
+		key = h.outer.Sum(nil)
+	}
+
+	h.ipad = make([]byte, blockSize)
+	h.opad = make([]byte, blockSize)
+	copy(h.ipad, key)
+	copy(h.opad, key)
+	for i := range h.ipad {
+		h.ipad[i] ^= 0x36
+		h.opad[i] ^= 0x5c
+	}
+
+	h.Reset()
+	return h
+}
+
+func Sum(newHash func() hash.Hash, key, message []byte) []byte {
+	h := New(newHash, key)
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+func Verify(newHash func() hash.Hash, key, message, tag []byte) error {
+	h := New(newHash, key)
+	h.Write(message)
+	return h.Verify(tag)
+}
+
+func (h *HMAC) Size() int {
+	return h.outer.Size()
+}
+
+func (h *HMAC) BlockSize() int {
+	return h.inner.BlockSize()
+}
+
+func (h *HMAC) Reset() {
+	h.inner.Reset()
+	h.inner.Write(h.ipad)
+}
+
+func (h *HMAC) Write(p []byte) (int, error) {
+	return h.inner.Write(p)
+}
+
+func (h *HMAC) Sum(b []byte) []byte {
+	h.outer.Reset()
+	h.outer.Write(h.opad)
+	h.outer.Write(h.inner.Sum(nil))
+	return h.outer.Sum(b)
+}
+
+func (h *HMAC) Verify(tag []byte) error {
+	if subtle.ConstantTimeCompare(h.Sum(nil), tag) != 1 {
+		return errors.ErrInvalidMAC
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package hmac
+
+import (
+	stdhmac "crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/blake2b"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRFC4231(t *testing.T) {
+	message := []byte("what do ya want for nothing?")
+	tag := Sum(sha256.New, []byte("Jefe"), message)
+	assert.Equal(t, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", hex.EncodeToString(tag))
+	assert.NoError(t, Verify(sha256.New, []byte("Jefe"), message, tag))
+	assert.ErrorIs(t, Verify(sha256.New, []byte("Jefe"), []byte("what do ya want for something?"), tag), errors.ErrInvalidMAC)
+
+	long := make([]byte, 131)
+	for i := range long {
+		long[i] = 0xaa
+	}
+	tag = Sum(sha512.New, long, []byte("Test Using Larger Than Block-Size Key - Hash Key First"))
+	assert.Equal(t, "80b24263c7c1a3ebb71493c1dd7be8b49b46d1f41b4aeec1121b013783f8f3526b56d037e05f2598bd0fd2215d6a1e5295e64f73f63f0aec8b915a985d786598", hex.EncodeToString(tag))
+}
+
+func TestStreamingMatchesStandardLibrary(t *testing.T) {
+	newBlake2b := func() hash.Hash {
+		d, err := blake2b.New(32, nil)
+		require.NoError(t, err)
+		return d
+	}
+
+	key := []byte("encrypt-then-mac key")
+	message := []byte("a message written in several pieces to the MAC")
+
+	for _, newHash := range []func() hash.Hash{sha256.New, sha512.New, newBlake2b} {
+		h := New(newHash, key)
+		h.Write(message[:10])
+		h.Write(message[10:])
+		tag := h.Sum(nil)
+
+		expected := stdhmac.New(newHash, key)
+		expected.Write(message)
+		assert.Equal(t, expected.Sum(nil), tag)
+		assert.Equal(t, tag, h.Sum(nil))
+		assert.NoError(t, h.Verify(tag))
+
+		h.Reset()
+		h.Write(message)
+		assert.Equal(t, tag, h.Sum(nil))
+	}
+}
@@ -0,0 +1,72 @@
+package poly1305
+
+import (
+	"crypto/subtle"
+	"math/big"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	KeySize = 32
+	TagSize = 16
+)
+
+var (
+	prime     = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 130), big.NewInt(5))
+	clampMask = leToInt([]byte{
+		0xff, 0xff, 0xff, 0x0f, 0xfc, 0xff, 0xff, 0x0f,
+		0xfc, 0xff, 0xff, 0x0f, 0xfc, 0xff, 0xff, 0x0f,
+	})
+	tagMask = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+)
+
+func Sum(key, message []byte) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+
+	r := new(big.Int).And(leToInt(key[:16]), clampMask)
+	s := leToInt(key[16:])
+
+	acc := new(big.Int)
+	for i := 0; i < len(message); i += 16 {
+		chunk := append(append([]byte{}, message[i:min(i+16, len(message))]...), 0x01)
+		acc.Add(acc, leToInt(chunk))
+		acc.Mul(acc, r)
+		acc.Mod(acc, prime)
+	}
+
+	acc.Add(acc, s)
+	acc.And(acc, tagMask)
+
+	return intToLE(acc, TagSize), nil
+}
+
+func Verify(key, message, tag []byte) error {
+	expected, err := Sum(key, message)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return errors.ErrInvalidMAC
+	}
+	return nil
+}
+
+func leToInt(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+func intToLE(n *big.Int, size int) []byte {
+	be := n.FillBytes(make([]byte, size))
+	le := make([]byte, size)
+	for i := range be {
+		le[size-1-i] = be[i]
+	}
+	return le
+}
@@ -0,0 +1,28 @@
+package poly1305
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSum(t *testing.T) {
+	key, _ := hex.DecodeString("85d6be7857556d337f4452fe42d506a80103808afb0db2fd4abff6af4149f51b")
+	message := []byte("Cryptographic Forum Research Group")
+
+	tag, err := Sum(key, message)
+	require.NoError(t, err)
+	assert.Equal(t, "a8061dc1305136c6c22b8baf0c0127a9", hex.EncodeToString(tag))
+
+	assert.NoError(t, Verify(key, message, tag))
+	tag[0] ^= 1
+	assert.ErrorIs(t, Verify(key, message, tag), errors.ErrInvalidMAC)
+}
+
+func TestInvalidKeySize(t *testing.T) {
+	_, err := Sum(make([]byte, 16), nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
@@ -0,0 +1,63 @@
+package mac
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const retailKeySize = 16
+
+type RetailMAC struct {
+	k1, k2  *des.DES
+	padding Padding
+}
+
+func NewRetailMAC(ctx context.Context, key []byte, padding Padding) (*RetailMAC, error) {
+	if len(key) != retailKeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	if padding != PaddingZero && padding != PaddingBit {
+		return nil, errors.ErrInvalidPaddingScheme
+	}
+
+	m := &RetailMAC{k1: des.NewDES(), k2: des.NewDES(), padding: padding}
+	if err := m.k1.SetKey(ctx, key[:8]); err != nil {
+		return nil, errors.Annotate(err, "failed to set K1: %w")
+	}
+	if err := m.k2.SetKey(ctx, key[8:]); err != nil {
+		return nil, errors.Annotate(err, "failed to set K2: %w")
+	}
+
+	return m, nil
+}
+
+func (m *RetailMAC) Size() int {
+	return m.k1.BlockSize()
+}
+
+func (m *RetailMAC) Sum(ctx context.Context, message []byte) ([]byte, error) {
+	state, err := chain(ctx, m.k1, pad(message, m.k1.BlockSize(), m.padding))
+	if err != nil {
+		return nil, err
+	}
+
+	state, err = m.k2.Decrypt(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	return m.k1.Encrypt(ctx, state)
+}
+
+func (m *RetailMAC) Verify(ctx context.Context, message, tag []byte) error {
+	expected, err := m.Sum(ctx, message)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return errors.ErrInvalidMAC
+	}
+	return nil
+}
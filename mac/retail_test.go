@@ -0,0 +1,43 @@
+package mac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/des"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetailMACMatchesCBCMACWithEqualKeys(t *testing.T) {
+	ctx := context.Background()
+	message := []byte("retail banking message")
+
+	retail, err := NewRetailMAC(ctx, append(append([]byte{}, desKey...), desKey...), PaddingBit)
+	require.NoError(t, err)
+	cbc, err := NewCBCMAC(ctx, des.NewDES(), desKey, FixedLength(len(message)), PaddingBit)
+	require.NoError(t, err)
+
+	want, err := cbc.Sum(ctx, message)
+	require.NoError(t, err)
+	got, err := retail.Sum(ctx, message)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestRetailMAC(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewRetailMAC(ctx, []byte("0123456789ABCDEF"), PaddingBit)
+	require.NoError(t, err)
+
+	message := []byte("retail banking message")
+	tag, err := m.Sum(ctx, message)
+	require.NoError(t, err)
+	assert.Len(t, tag, m.Size())
+	assert.NoError(t, m.Verify(ctx, message, tag))
+	assert.ErrorIs(t, m.Verify(ctx, message[1:], tag), errors.ErrInvalidMAC)
+
+	_, err = NewRetailMAC(ctx, desKey, PaddingBit)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
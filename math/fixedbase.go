@@ -0,0 +1,97 @@
+package math
+
+import (
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidWindowSize = errors.New("window size must be between 1 and 8")
+
+const maxFixedBaseWindow = 8
+
+// FixedBase holds g^(d * 2^(w*i)) for every window position i and digit d,
+// so an exponentiation is one table scan and one multiplication per window
+// with no squarings.
+type FixedBase struct {
+	mont   *Montgomery
+	base   *big.Int
+	window int
+	bits   int
+	table  [][][]uint64
+}
+
+func NewFixedBase(g, p *big.Int, windowSize int) (*FixedBase, error) {
+	if windowSize < 1 || windowSize > maxFixedBaseWindow {
+		return nil, ErrInvalidWindowSize
+	}
+
+	mont, err := NewMontgomery(p)
+	if err != nil {
+		return nil, err
+	}
+
+	words := len(mont.n)
+	f := &FixedBase{
+		mont:   mont,
+		base:   new(big.Int).Mod(g, p),
+		window: windowSize,
+		bits:   p.BitLen(),
+	}
+
+	rows := (f.bits + windowSize - 1) / windowSize
+	f.table = make([][][]uint64, rows)
+	x := mont.mul(toLimbs(f.base, words), mont.rr)
+	for i := range f.table {
+		row := make([][]uint64, 1<<windowSize)
+		row[0] = mont.one
+		row[1] = x
+		for d := 2; d < len(row); d++ {
+			row[d] = mont.mul(row[d-1], x)
+		}
+		f.table[i] = row
+
+		for j := 0; j < windowSize; j++ {
+			x = mont.mul(x, x)
+		}
+	}
+
+	return f, nil
+}
+
+func (f *FixedBase) Base() *big.Int {
+	return new(big.Int).Set(f.base)
+}
+
+func (f *FixedBase) Modulus() *big.Int {
+	return f.mont.Modulus()
+}
+
+// Exp runs in time independent of exp for exponents no wider than the
+// modulus. Wider exponents fall back to ExpConstantTime.
+func (f *FixedBase) Exp(exp *big.Int) *big.Int {
+	if exp.BitLen() > f.bits {
+		return f.mont.ExpConstantTime(f.base, exp)
+	}
+
+	words := len(f.mont.n)
+	e := exp.FillBytes(make([]byte, (len(f.table)*f.window+7)/8))
+	acc := append([]uint64{}, f.mont.one...)
+	selected := make([]uint64, words)
+	for i, row := range f.table {
+		lookup(selected, row, digit(e, i*f.window, f.window))
+		acc = f.mont.mul(acc, selected)
+	}
+
+	unit := make([]uint64, words)
+	unit[0] = 1
+	return fromLimbs(f.mont.mul(acc, unit))
+}
+
+func digit(e []byte, offset, width int) uint64 {
+	var d uint64
+	for i := 0; i < width; i++ {
+		bit := offset + i
+		d |= uint64(e[len(e)-1-bit/8]>>(bit%8)&1) << i
+	}
+	return d
+}
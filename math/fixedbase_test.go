@@ -0,0 +1,63 @@
+package math
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedBaseMatchesExp(t *testing.T) {
+	for _, bits := range []int{3, 17, 64, 65, 127, 512, 1031} {
+		modulus := randomOdd(t, bits)
+		g, err := rand.Int(rand.Reader, new(big.Int).Lsh(modulus, 2))
+		require.NoError(t, err)
+
+		for _, window := range []int{1, 3, 4, 8} {
+			f, err := NewFixedBase(g, modulus, window)
+			require.NoError(t, err)
+			assert.Equal(t, 0, f.Base().Cmp(new(big.Int).Mod(g, modulus)))
+			assert.Equal(t, 0, f.Modulus().Cmp(modulus))
+
+			for i := 0; i < 8; i++ {
+				exp, err := rand.Int(rand.Reader, modulus)
+				require.NoError(t, err)
+
+				want := new(big.Int).Exp(g, exp, modulus)
+				assert.Equal(t, 0, want.Cmp(f.Exp(exp)), "bits=%d window=%d exp=%s", bits, window, exp)
+			}
+
+			wide, err := rand.Int(rand.Reader, new(big.Int).Lsh(modulus, 70))
+			require.NoError(t, err)
+			assert.Equal(t, 0, new(big.Int).Exp(g, wide, modulus).Cmp(f.Exp(wide)))
+
+			assert.Equal(t, 0, f.Exp(big.NewInt(0)).Cmp(new(big.Int).Mod(big.NewInt(1), modulus)))
+			allOnes := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+			assert.Equal(t, 0, new(big.Int).Exp(g, allOnes, modulus).Cmp(f.Exp(allOnes)))
+		}
+	}
+}
+
+func TestNewFixedBaseInvalid(t *testing.T) {
+	modulus := big.NewInt(101)
+
+	_, err := NewFixedBase(big.NewInt(2), modulus, 0)
+	assert.ErrorIs(t, err, ErrInvalidWindowSize)
+	_, err = NewFixedBase(big.NewInt(2), modulus, 9)
+	assert.ErrorIs(t, err, ErrInvalidWindowSize)
+	_, err = NewFixedBase(big.NewInt(2), big.NewInt(100), 4)
+	assert.ErrorIs(t, err, ErrInvalidModulus)
+}
+
+func BenchmarkFixedBase2048(b *testing.B) {
+	modulus, _ := new(big.Int).SetString("c7970ceedcc3b0754490201a7aa613cd73911081c790f5f1a8726f463550bb5b7ff0db8e1ea1189ec72f93d1650011bd721aeeacc2acde32a04107f0648c2813a31f5b0b7765ff8b44b4b6ffc93384b646eb09c7cf5e8592d40ea33c80039f35b4f14a04b51f7bfd781be4d1673164ba8eb991c2c4d730bbbe35f592bdef524af7e8daefd26c66fc02c479af89d64d373f442709439de66ceb955f3ea37d5159f6135809f85334b5cb1813addc80cd05609f10ac6a95ad65872c909525bdad32bc729592642920f24c61dc5b3c3b7923e56b16a4d9d373d8721f24a3fc0f1b3131f55615172866bccc30f95054c824e733a5eb6817f7bc16399d48c6361cc7e5", 16)
+	f, _ := NewFixedBase(big.NewInt(3), modulus, 6)
+	exp := new(big.Int).Sub(modulus, big.NewInt(2))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Exp(exp)
+	}
+}
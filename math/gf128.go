@@ -0,0 +1,38 @@
+package math
+
+import "errors"
+
+func GF128Mul(x, y [2]uint64) [2]uint64 {
+	var z [2]uint64
+	v := y
+
+	for i := 0; i < 128; i++ {
+		if x[i/64]>>(63-i%64)&1 == 1 {
+			z[0] ^= v[0]
+			z[1] ^= v[1]
+		}
+
+		lsb := v[1] & 1
+		v[1] = v[1]>>1 | v[0]<<63
+		v[0] >>= 1
+		if lsb == 1 {
+			v[0] ^= 0xE1 << 56
+		}
+	}
+
+	return z
+}
+
+func GF128Inv(x [2]uint64) ([2]uint64, error) {
+	if x == [2]uint64{} {
+		return x, errors.New("zero has no inverse")
+	}
+
+	result := [2]uint64{1 << 63, 0}
+	power := x
+	for i := 1; i < 128; i++ {
+		power = GF128Mul(power, power)
+		result = GF128Mul(result, power)
+	}
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGF128Mul(t *testing.T) {
+	one := [2]uint64{1 << 63, 0}
+	x := [2]uint64{0x66e94bd4ef8a2c3b, 0x884cfa59ca342b2e}
+
+	assert.Equal(t, x, GF128Mul(x, one))
+	assert.Equal(t, x, GF128Mul(one, x))
+	assert.Equal(t, [2]uint64{}, GF128Mul(x, [2]uint64{}))
+	assert.Equal(t, GF128Mul(x, [2]uint64{0x0388dace60b6a392, 0xf328c2b971b2fe78}), GF128Mul([2]uint64{0x0388dace60b6a392, 0xf328c2b971b2fe78}, x))
+}
+
+func TestGF128Inv(t *testing.T) {
+	one := [2]uint64{1 << 63, 0}
+
+	for _, x := range [][2]uint64{one, {0x66e94bd4ef8a2c3b, 0x884cfa59ca342b2e}, {0, 1}} {
+		inv, err := GF128Inv(x)
+		require.NoError(t, err)
+		assert.Equal(t, one, GF128Mul(x, inv))
+	}
+
+	_, err := GF128Inv([2]uint64{})
+	assert.Error(t, err)
+}
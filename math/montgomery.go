@@ -0,0 +1,183 @@
+package math
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const ErrInvalidModulus errors.ConstError = "modulus must be odd and greater than one"
+
+const expWindow = 4
+
+type Montgomery struct {
+	modulus *big.Int
+	n       []uint64
+	n0inv   uint64
+	rr      []uint64
+	one     []uint64
+}
+
+func NewMontgomery(modulus *big.Int) (*Montgomery, error) {
+	if modulus.Cmp(big.NewInt(1)) <= 0 || modulus.Bit(0) == 0 {
+		return nil, ErrInvalidModulus
+	}
+
+	words := (modulus.BitLen() + 63) / 64
+	m := &Montgomery{
+		modulus: new(big.Int).Set(modulus),
+		n:       toLimbs(modulus, words),
+	}
+
+	inv := m.n[0]
+	for i := 0; i < 6; i++ {
+		inv *= 2 - m.n[0]*inv
+	}
+	m.n0inv = -inv
+
+	r := new(big.Int).Lsh(big.NewInt(1), uint(64*words))
+	m.one = toLimbs(new(big.Int).Mod(r, modulus), words)
+	m.rr = toLimbs(new(big.Int).Mod(new(big.Int).Mul(r, r), modulus), words)
+
+	return m, nil
+}
+
+func (m *Montgomery) Modulus() *big.Int {
+	return new(big.Int).Set(m.modulus)
+}
+
+// ExpConstantTime runs the same sequence of squarings, multiplications and
+// table scans for every exponent that fits in the modulus width, so its
+// timing depends only on the modulus size.
+func (m *Montgomery) ExpConstantTime(base, exp *big.Int) *big.Int {
+	words := len(m.n)
+	expWords := max(words, (exp.BitLen()+63)/64)
+
+	var table [1 << expWindow][]uint64
+	table[0] = m.one
+	table[1] = m.mul(toLimbs(new(big.Int).Mod(base, m.modulus), words), m.rr)
+	for i := 2; i < len(table); i++ {
+		table[i] = m.mul(table[i-1], table[1])
+	}
+
+	e := exp.FillBytes(make([]byte, 8*expWords))
+	acc := append([]uint64{}, m.one...)
+	selected := make([]uint64, words)
+	for _, b := range e {
+		for shift := 8 - expWindow; shift >= 0; shift -= expWindow {
+			for i := 0; i < expWindow; i++ {
+				acc = m.mul(acc, acc)
+			}
+			lookup(selected, table[:], uint64(b>>shift)&(1<<expWindow-1))
+			acc = m.mul(acc, selected)
+		}
+	}
+
+	unit := make([]uint64, words)
+	unit[0] = 1
+	return fromLimbs(m.mul(acc, unit))
+}
+
+func ModPowConstantTime(base, exp, modulus *big.Int) *big.Int {
+	m, err := NewMontgomery(modulus)
+	if err != nil {
+		return ModPow(base, exp, modulus)
+	}
+	return m.ExpConstantTime(base, exp)
+}
+
+func (m *Montgomery) mul(a, b []uint64) []uint64 {
+	words := len(m.n)
+	t := make([]uint64, words+2)
+
+	for i := 0; i < words; i++ {
+		var c, cc uint64
+		for j := 0; j < words; j++ {
+			hi, lo := bits.Mul64(a[j], b[i])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j], c = lo, hi
+		}
+		t[words], cc = bits.Add64(t[words], c, 0)
+		t[words+1] = cc
+
+		q := t[0] * m.n0inv
+		hi, lo := bits.Mul64(q, m.n[0])
+		_, cc = bits.Add64(lo, t[0], 0)
+		c = hi + cc
+		for j := 1; j < words; j++ {
+			hi, lo = bits.Mul64(q, m.n[j])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j-1], c = lo, hi
+		}
+		t[words-1], cc = bits.Add64(t[words], c, 0)
+		t[words] = t[words+1] + cc
+	}
+
+	reduced := make([]uint64, words)
+	var borrow uint64
+	for j := 0; j < words; j++ {
+		reduced[j], borrow = bits.Sub64(t[j], m.n[j], borrow)
+	}
+
+	mask := -(t[words] | (borrow ^ 1))
+	out := make([]uint64, words)
+	for j := range out {
+		out[j] = reduced[j]&mask | t[j]&^mask
+	}
+	return out
+}
+
+func lookup(dst []uint64, table [][]uint64, index uint64) {
+	for j := range dst {
+		dst[j] = 0
+	}
+	for i, entry := range table {
+		v := uint64(i) ^ index
+		mask := ((v | -v) >> 63) - 1
+		for j := range dst {
+			dst[j] |= entry[j] & mask
+		}
+	}
+}
+
+func toLimbs(x *big.Int, words int) []uint64 {
+	b := x.FillBytes(make([]byte, 8*words))
+	limbs := make([]uint64, words)
+	for i := range limbs {
+		limbs[i] = binary.BigEndian.Uint64(b[len(b)-8*(i+1):])
+	}
+	return limbs
+}
+
+func fromLimbs(limbs []uint64) *big.Int {
+	b := make([]byte, 8*len(limbs))
+	for i, limb := range limbs {
+		binary.BigEndian.PutUint64(b[len(b)-8*(i+1):], limb)
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+func (m *Montgomery) Exp(base, exp *big.Int) *big.Int {
+	words := len(m.n)
+	x := m.mul(toLimbs(new(big.Int).Mod(base, m.modulus), words), m.rr)
+
+	acc := append([]uint64{}, m.one...)
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		acc = m.mul(acc, acc)
+		if exp.Bit(i) == 1 {
+			acc = m.mul(acc, x)
+		}
+	}
+
+	unit := make([]uint64, words)
+	unit[0] = 1
+	return fromLimbs(m.mul(acc, unit))
+}
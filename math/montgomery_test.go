@@ -0,0 +1,111 @@
+package math
+
+import (
+	"crypto/rand"
+	"math/big"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomOdd(t *testing.T, bits int) *big.Int {
+	n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+	require.NoError(t, err)
+	n.SetBit(n, bits-1, 1)
+	return n.SetBit(n, 0, 1)
+}
+
+func TestExpConstantTimeMatchesExp(t *testing.T) {
+	for _, bits := range []int{3, 17, 64, 65, 127, 512, 1031} {
+		modulus := randomOdd(t, bits)
+		m, err := NewMontgomery(modulus)
+		require.NoError(t, err)
+
+		for i := 0; i < 8; i++ {
+			base, err := rand.Int(rand.Reader, new(big.Int).Lsh(modulus, 2))
+			require.NoError(t, err)
+			exp, err := rand.Int(rand.Reader, new(big.Int).Lsh(modulus, 70))
+			require.NoError(t, err)
+
+			want := new(big.Int).Exp(base, exp, modulus)
+			assert.Equal(t, 0, want.Cmp(m.ExpConstantTime(base, exp)), "bits=%d base=%s exp=%s", bits, base, exp)
+			assert.Equal(t, 0, want.Cmp(m.Exp(base, exp)), "bits=%d base=%s exp=%s", bits, base, exp)
+		}
+
+		assert.Equal(t, 0, m.ExpConstantTime(big.NewInt(5), big.NewInt(0)).Cmp(new(big.Int).Mod(big.NewInt(1), modulus)))
+		assert.Equal(t, 0, m.ExpConstantTime(big.NewInt(0), big.NewInt(5)).Sign())
+	}
+}
+
+func TestNewMontgomeryInvalid(t *testing.T) {
+	for _, modulus := range []int64{-3, 0, 1, 2, 1024} {
+		_, err := NewMontgomery(big.NewInt(modulus))
+		assert.ErrorIs(t, err, ErrInvalidModulus)
+	}
+
+	assert.Equal(t, int64(4), ModPowConstantTime(big.NewInt(2), big.NewInt(2), big.NewInt(10)).Int64())
+	assert.Equal(t, int64(1), ModPowConstantTime(big.NewInt(2), big.NewInt(4), big.NewInt(5)).Int64())
+}
+
+func medianDuration(samples []time.Duration) time.Duration {
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return samples[len(samples)/2]
+}
+
+// A short exponent finishes early with variable-time exponentiation, which
+// leaks its length; the constant-time path takes the same time for both.
+// Wall-clock ratios depend on the machine and its load, so the test only
+// runs when CRYPTO_TIMING_TESTS is set.
+func TestExpConstantTimeTimingVariance(t *testing.T) {
+	if os.Getenv("CRYPTO_TIMING_TESTS") == "" {
+		t.Skip("timing measurement; set CRYPTO_TIMING_TESTS to run")
+	}
+
+	modulus := randomOdd(t, 1024)
+	m, err := NewMontgomery(modulus)
+	require.NoError(t, err)
+	base := randomOdd(t, 1000)
+
+	short := new(big.Int).Lsh(big.NewInt(1), 255)
+	long := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 1023), big.NewInt(1))
+
+	measure := func(fn func(exp *big.Int)) float64 {
+		var shortTimes, longTimes []time.Duration
+		for i := 0; i < 21; i++ {
+			for _, exp := range []*big.Int{short, long} {
+				start := time.Now()
+				fn(exp)
+				elapsed := time.Since(start)
+				if exp == short {
+					shortTimes = append(shortTimes, elapsed)
+				} else {
+					longTimes = append(longTimes, elapsed)
+				}
+			}
+		}
+		return float64(medianDuration(shortTimes)) / float64(medianDuration(longTimes))
+	}
+
+	variable := measure(func(exp *big.Int) { new(big.Int).Exp(base, exp, modulus) })
+	constant := measure(func(exp *big.Int) { m.ExpConstantTime(base, exp) })
+	t.Logf("short/long exponent time ratio: big.Int %.2f, constant-time %.2f", variable, constant)
+
+	assert.Less(t, variable, 0.5)
+	assert.InDelta(t, 1.0, constant, 0.25)
+}
+
+func BenchmarkExpConstantTime2048(b *testing.B) {
+	modulus, _ := new(big.Int).SetString("c7970ceedcc3b0754490201a7aa613cd73911081c790f5f1a8726f463550bb5b7ff0db8e1ea1189ec72f93d1650011bd721aeeacc2acde32a04107f0648c2813a31f5b0b7765ff8b44b4b6ffc93384b646eb09c7cf5e8592d40ea33c80039f35b4f14a04b51f7bfd781be4d1673164ba8eb991c2c4d730bbbe35f592bdef524af7e8daefd26c66fc02c479af89d64d373f442709439de66ceb955f3ea37d5159f6135809f85334b5cb1813addc80cd05609f10ac6a95ad65872c909525bdad32bc729592642920f24c61dc5b3c3b7923e56b16a4d9d373d8721f24a3fc0f1b3131f55615172866bccc30f95054c824e733a5eb6817f7bc16399d48c6361cc7e5", 16)
+	m, _ := NewMontgomery(modulus)
+	exp := new(big.Int).Sub(modulus, big.NewInt(2))
+	base := big.NewInt(3)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ExpConstantTime(base, exp)
+	}
+}
@@ -1,53 +1,215 @@
 package math
 
 import (
+	"context"
 	cryptoRand "crypto/rand"
+	"io"
 	"math"
 	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/masterkusok/crypto/errors"
 )
 
 type PrimalityTester interface {
 	IsProbablyPrime(n *big.Int, minProbability float64) bool
 }
 
+type ContextPrimalityTester interface {
+	PrimalityTester
+	IsProbablyPrimeContext(ctx context.Context, n *big.Int, minProbability float64) (bool, error)
+}
+
+type TesterOption func(*primalityTest)
+
+func WithParallelism(workers int) TesterOption {
+	return func(p *primalityTest) {
+		p.workers = workers
+	}
+}
+
+func WithWitnessSource(source io.Reader) TesterOption {
+	return func(p *primalityTest) {
+		p.source = source
+	}
+}
+
+// WithWitnesses replaces random witnesses with a fixed list. The list must
+// be long enough for the requested probability, and every witness must lie
+// in [2, n-2] for the n under test; the tester reports an error otherwise.
+func WithWitnesses(witnesses ...*big.Int) TesterOption {
+	return func(p *primalityTest) {
+		p.witnesses = make([]*big.Int, len(witnesses))
+		for i, w := range witnesses {
+			p.witnesses[i] = new(big.Int).Set(w)
+		}
+	}
+}
+
 type testFunc func(n, witness *big.Int) bool
 
 type primalityTest struct {
 	test      testFunc
 	errorProb float64
+	workers   int
+	witnesses []*big.Int
+	source    io.Reader
+	sourceMu  sync.Mutex
 }
 
+func newPrimalityTest(test testFunc, errorProb float64, opts []TesterOption) *primalityTest {
+	p := &primalityTest{
+		test:      test,
+		errorProb: errorProb,
+		workers:   1,
+		source:    cryptoRand.Reader,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// IsProbablyPrime reports false when the test cannot complete, for example
+// because the witness source failed; IsProbablyPrimeContext returns that
+// error instead.
 func (p *primalityTest) IsProbablyPrime(n *big.Int, minProbability float64) bool {
+	prime, err := p.IsProbablyPrimeContext(context.Background(), n, minProbability)
+	if err != nil {
+		return false
+	}
+	return prime
+}
+
+func (p *primalityTest) IsProbablyPrimeContext(ctx context.Context, n *big.Int, minProbability float64) (bool, error) {
+	if minProbability <= 0 || minProbability >= 1 {
+		return false, errors.Annotate(errors.ErrInvalidParameters, "probability %v outside (0, 1): %w", minProbability)
+	}
+	k := int(math.Ceil(math.Log(1-minProbability) / math.Log(p.errorProb)))
+	if p.witnesses != nil {
+		if len(p.witnesses) == 0 || len(p.witnesses) < k {
+			return false, errors.Annotate(errors.ErrInvalidParameters, "%d witnesses cannot reach probability %v: %w", len(p.witnesses), minProbability)
+		}
+		k = len(p.witnesses)
+	}
+
 	two := big.NewInt(2)
 	if n.Cmp(two) < 0 {
-		return false
+		return false, nil
 	}
 	if n.Cmp(two) == 0 || n.Cmp(big.NewInt(3)) == 0 {
-		return true
+		return true, nil
 	}
 	if new(big.Int).Mod(n, two).Cmp(big.NewInt(0)) == 0 {
-		return false
+		return false, nil
 	}
 
-	k := int(math.Ceil(math.Log(1-minProbability) / math.Log(p.errorProb)))
-	for i := 0; i < k; i++ {
-		witness, _ := cryptoRand.Int(cryptoRand.Reader, new(big.Int).Sub(n, big.NewInt(3)))
-		witness.Add(witness, two)
-		if GCD(witness, n).Cmp(big.NewInt(1)) != 0 {
-			return false
+	if p.workers <= 1 || k <= 1 {
+		for i := 0; i < k; i++ {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			passed, err := p.checkWitness(n, i)
+			if err != nil || !passed {
+				return false, err
+			}
 		}
-		
-		if !p.test(n, witness) {
-			return false
+		return true, nil
+	}
+
+	return p.checkWitnessesParallel(ctx, n, k)
+}
+
+func (p *primalityTest) checkWitnessesParallel(parent context.Context, n *big.Int, rounds int) (bool, error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	workers := p.workers
+	if workers > rounds {
+		workers = rounds
+	}
+
+	var (
+		next      int64
+		composite atomic.Bool
+		firstErr  error
+		errOnce   sync.Once
+		wg        sync.WaitGroup
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := atomic.AddInt64(&next, 1) - 1; i < int64(rounds); i = atomic.AddInt64(&next, 1) - 1 {
+				if ctx.Err() != nil {
+					return
+				}
+				passed, err := p.checkWitness(n, int(i))
+				if err != nil {
+					errOnce.Do(func() { firstErr = err })
+					cancel()
+					return
+				}
+				if !passed {
+					composite.Store(true)
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return false, firstErr
+	}
+	if composite.Load() {
+		return false, nil
+	}
+	if err := parent.Err(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *primalityTest) checkWitness(n *big.Int, round int) (bool, error) {
+	witness, err := p.witness(n, round)
+	if err != nil {
+		return false, err
+	}
+	if GCD(witness, n).Cmp(big.NewInt(1)) != 0 {
+		return false, nil
+	}
+
+	return p.test(n, witness), nil
+}
+
+func (p *primalityTest) witness(n *big.Int, round int) (*big.Int, error) {
+	if p.witnesses != nil {
+		witness := p.witnesses[round]
+		if witness.Cmp(big.NewInt(2)) < 0 || witness.Cmp(new(big.Int).Sub(n, big.NewInt(1))) >= 0 {
+			return nil, errors.Annotate(errors.ErrInvalidParameters, "witness %s outside [2, n-2]: %w", witness)
 		}
+		return new(big.Int).Set(witness), nil
+	}
+
+	p.sourceMu.Lock()
+	witness, err := cryptoRand.Int(p.source, new(big.Int).Sub(n, big.NewInt(3)))
+	p.sourceMu.Unlock()
+	if err != nil {
+		return nil, err
 	}
-	return true
+
+	return witness.Add(witness, big.NewInt(2)), nil
 }
 
-func NewMillerRabinTest() PrimalityTester {
-	return &primalityTest{
-		errorProb: 0.25,
-		test: func(n, a *big.Int) bool {
+func NewMillerRabinTest(opts ...TesterOption) PrimalityTester {
+	return newPrimalityTest(
+		func(n, a *big.Int) bool {
 			d := new(big.Int).Sub(n, big.NewInt(1))
 			r := 0
 			for new(big.Int).Mod(d, big.NewInt(2)).Cmp(big.NewInt(0)) == 0 {
@@ -69,22 +231,24 @@ func NewMillerRabinTest() PrimalityTester {
 			}
 			return false
 		},
-	}
+		0.25,
+		opts,
+	)
 }
 
-func NewFermatTest() PrimalityTester {
-	return &primalityTest{
-		errorProb: 0.5,
-		test: func(n, a *big.Int) bool {
+func NewFermatTest(opts ...TesterOption) PrimalityTester {
+	return newPrimalityTest(
+		func(n, a *big.Int) bool {
 			return new(big.Int).Exp(a, new(big.Int).Sub(n, big.NewInt(1)), n).Cmp(big.NewInt(1)) == 0
 		},
-	}
+		0.5,
+		opts,
+	)
 }
 
-func NewSolovayStrassenTest() PrimalityTester {
-	return &primalityTest{
-		errorProb: 0.5,
-		test: func(n, a *big.Int) bool {
+func NewSolovayStrassenTest(opts ...TesterOption) PrimalityTester {
+	return newPrimalityTest(
+		func(n, a *big.Int) bool {
 			jacobi := int64(Jacobi(a, n))
 			if jacobi == -1 {
 				jacobi = new(big.Int).Sub(n, big.NewInt(1)).Int64()
@@ -92,5 +256,7 @@ func NewSolovayStrassenTest() PrimalityTester {
 
 			return new(big.Int).Exp(a, new(big.Int).Div(new(big.Int).Sub(n, big.NewInt(1)), big.NewInt(2)), n).Cmp(big.NewInt(jacobi)) == 0
 		},
-	}
+		0.5,
+		opts,
+	)
 }
@@ -1,9 +1,14 @@
 package math
 
 import (
+	"bytes"
+	"context"
 	"math/big"
+	"math/rand/v2"
+	"runtime"
 	"testing"
 
+	"github.com/masterkusok/crypto/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -71,3 +76,106 @@ func TestLargePrimes(t *testing.T) {
 		}
 	}
 }
+
+func TestParallelPrimalityTesters(t *testing.T) {
+	testPrimalityTester(t, NewMillerRabinTest(WithParallelism(4)), "parallel Miller-Rabin")
+	testPrimalityTester(t, NewFermatTest(WithParallelism(4)), "parallel Fermat")
+	testPrimalityTester(t, NewSolovayStrassenTest(WithParallelism(4)), "parallel Solovay-Strassen")
+}
+
+func TestParallelPrimalityCancellation(t *testing.T) {
+	tester := NewMillerRabinTest(WithParallelism(4)).(ContextPrimalityTester)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	prime, err := tester.IsProbablyPrimeContext(ctx, big.NewInt(15485863), 0.999999)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, prime)
+}
+
+func BenchmarkMillerRabinSequential(b *testing.B) {
+	benchmarkPrimality(b, NewMillerRabinTest())
+}
+
+func BenchmarkMillerRabinParallel(b *testing.B) {
+	benchmarkPrimality(b, NewMillerRabinTest(WithParallelism(runtime.NumCPU())))
+}
+
+func benchmarkPrimality(b *testing.B, tester PrimalityTester) {
+	n, _ := new(big.Int).SetString("170141183460469231731687303715884105727", 10)
+	for i := 0; i < b.N; i++ {
+		tester.IsProbablyPrime(n, 0.999999999999)
+	}
+}
+
+func TestExplicitWitnesses(t *testing.T) {
+	carmichael := big.NewInt(561)
+
+	fermat := NewFermatTest(WithWitnesses(big.NewInt(2), big.NewInt(5), big.NewInt(7)))
+	assert.True(t, fermat.IsProbablyPrime(carmichael, 0.8), "561 fools Fermat for coprime bases")
+
+	millerRabin := NewMillerRabinTest(WithWitnesses(big.NewInt(2)))
+	assert.False(t, millerRabin.IsProbablyPrime(carmichael, 0.75))
+
+	// Every witness must be below n-1, so the small cases are left out.
+	deterministic := NewMillerRabinTest(WithWitnesses(big.NewInt(2), big.NewInt(3), big.NewInt(5), big.NewInt(7)))
+	for _, list := range [][]string{primes, composites} {
+		for _, v := range list {
+			n, _ := new(big.Int).SetString(v, 10)
+			if n.Int64() < 11 {
+				continue
+			}
+			assert.Equal(t, n.ProbablyPrime(20), deterministic.IsProbablyPrime(n, 0.99), "deterministic Miller-Rabin: %s", v)
+		}
+	}
+}
+
+func TestExplicitWitnessesInvalid(t *testing.T) {
+	ctx := context.Background()
+	n := big.NewInt(1000003)
+
+	for name, tester := range map[string]PrimalityTester{
+		"empty":        NewMillerRabinTest(WithWitnesses()),
+		"too few":      NewMillerRabinTest(WithWitnesses(big.NewInt(2))),
+		"below range":  NewMillerRabinTest(WithWitnesses(big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(5))),
+		"above range":  NewMillerRabinTest(WithWitnesses(big.NewInt(2), big.NewInt(3), big.NewInt(5), big.NewInt(1000002))),
+		"zero witness": NewFermatTest(WithWitnesses(big.NewInt(0), big.NewInt(2), big.NewInt(3), big.NewInt(5), big.NewInt(7), big.NewInt(11), big.NewInt(13))),
+	} {
+		prime, err := tester.(ContextPrimalityTester).IsProbablyPrimeContext(ctx, n, 0.99)
+		assert.ErrorIs(t, err, errors.ErrInvalidParameters, name)
+		assert.False(t, prime, name)
+
+		// An odd composite must not pass either.
+		assert.False(t, tester.IsProbablyPrime(big.NewInt(1000001), 0.99), name)
+	}
+
+	_, err := NewMillerRabinTest().(ContextPrimalityTester).IsProbablyPrimeContext(ctx, n, 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
+
+func TestWitnessSourceReproducible(t *testing.T) {
+	newTester := func() PrimalityTester {
+		seed := [32]byte{1, 2, 3}
+		return NewFermatTest(WithWitnessSource(rand.NewChaCha8(seed)))
+	}
+
+	first, second := newTester(), newTester()
+	for n := int64(5); n < 2000; n += 2 {
+		assert.Equal(t,
+			first.IsProbablyPrime(big.NewInt(n), 0.5),
+			second.IsProbablyPrime(big.NewInt(n), 0.5),
+			"results for %d should match", n,
+		)
+	}
+}
+
+func TestWitnessSourceExhausted(t *testing.T) {
+	tester := NewMillerRabinTest(WithWitnessSource(bytes.NewReader(nil))).(ContextPrimalityTester)
+
+	prime, err := tester.IsProbablyPrimeContext(context.Background(), big.NewInt(104729), 0.99)
+	assert.Error(t, err)
+	assert.False(t, prime)
+
+	assert.False(t, tester.IsProbablyPrime(big.NewInt(104729), 0.99))
+}
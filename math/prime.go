@@ -0,0 +1,124 @@
+package math
+
+import (
+	"context"
+	cryptoRand "crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	defaultPrimeProbability = 0.999999
+	sieveBound              = 1000
+)
+
+type PrimeOptions struct {
+	MinProbability  float64
+	BlumPrime       bool
+	TopTwoBits      bool
+	SmoothnessBound int
+	Rand            io.Reader
+}
+
+var sievePrimes = func() []*big.Int {
+	composite := make([]bool, sieveBound)
+	var primes []*big.Int
+	for i := 3; i < sieveBound; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, big.NewInt(int64(i)))
+		for j := i * i; j < sieveBound; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}()
+
+func GeneratePrime(ctx context.Context, bits int, tester PrimalityTester, opts *PrimeOptions) (*big.Int, error) {
+	if opts == nil {
+		opts = &PrimeOptions{}
+	}
+	if bits < 3 || tester == nil {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	minProbability := opts.MinProbability
+	if minProbability <= 0 {
+		minProbability = defaultPrimeProbability
+	}
+	source := opts.Rand
+	if source == nil {
+		source = cryptoRand.Reader
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		candidate, err := cryptoRand.Int(source, limit)
+		if err != nil {
+			return nil, errors.Annotate(err, "failed to generate random number: %w")
+		}
+
+		candidate.SetBit(candidate, bits-1, 1)
+		candidate.SetBit(candidate, 0, 1)
+		if opts.TopTwoBits {
+			candidate.SetBit(candidate, bits-2, 1)
+		}
+		if opts.BlumPrime {
+			candidate.SetBit(candidate, 1, 1)
+		}
+
+		if hasSmallFactor(candidate) {
+			continue
+		}
+		if opts.SmoothnessBound > 0 && isSmooth(candidate, opts.SmoothnessBound) {
+			continue
+		}
+
+		prime, err := isProbablyPrime(ctx, tester, candidate, minProbability)
+		if err != nil {
+			return nil, err
+		}
+		if prime {
+			return candidate, nil
+		}
+	}
+}
+
+func isProbablyPrime(ctx context.Context, tester PrimalityTester, n *big.Int, minProbability float64) (bool, error) {
+	if ctxTester, ok := tester.(ContextPrimalityTester); ok {
+		return ctxTester.IsProbablyPrimeContext(ctx, n, minProbability)
+	}
+	return tester.IsProbablyPrime(n, minProbability), nil
+}
+
+func hasSmallFactor(n *big.Int) bool {
+	mod := new(big.Int)
+	for _, p := range sievePrimes {
+		if n.Cmp(p) <= 0 {
+			return false
+		}
+		if mod.Mod(n, p).Sign() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isSmooth(p *big.Int, bound int) bool {
+	rest := new(big.Int).Sub(p, big.NewInt(1))
+	mod := new(big.Int)
+	for f := int64(2); f < int64(bound); f++ {
+		divisor := big.NewInt(f)
+		for mod.Mod(rest, divisor).Sign() == 0 {
+			rest.Div(rest, divisor)
+		}
+	}
+	return rest.BitLen() < p.BitLen()/2
+}
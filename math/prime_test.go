@@ -0,0 +1,65 @@
+package math
+
+import (
+	"context"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePrime(t *testing.T) {
+	ctx := context.Background()
+	tester := NewMillerRabinTest()
+
+	p, err := GeneratePrime(ctx, 128, tester, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 128, p.BitLen())
+	assert.True(t, p.ProbablyPrime(20))
+}
+
+func TestGeneratePrimeConstraints(t *testing.T) {
+	ctx := context.Background()
+	opts := &PrimeOptions{
+		BlumPrime:       true,
+		TopTwoBits:      true,
+		SmoothnessBound: 1000,
+	}
+
+	for i := 0; i < 5; i++ {
+		p, err := GeneratePrime(ctx, 96, NewMillerRabinTest(), opts)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(3), new(big.Int).Mod(p, big.NewInt(4)).Int64())
+		assert.Equal(t, uint(1), p.Bit(94))
+		assert.False(t, isSmooth(p, 1000))
+		assert.True(t, p.ProbablyPrime(20))
+	}
+}
+
+func TestGeneratePrimeReproducible(t *testing.T) {
+	ctx := context.Background()
+	generate := func() *big.Int {
+		p, err := GeneratePrime(ctx, 64, NewMillerRabinTest(WithWitnesses(big.NewInt(2), big.NewInt(3))), &PrimeOptions{
+			Rand:           rand.NewChaCha8([32]byte{7}),
+			MinProbability: 0.9,
+		})
+		require.NoError(t, err)
+		return p
+	}
+
+	assert.Equal(t, generate(), generate())
+}
+
+func TestGeneratePrimeErrors(t *testing.T) {
+	_, err := GeneratePrime(context.Background(), 2, NewMillerRabinTest(), nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GeneratePrime(ctx, 256, NewMillerRabinTest(), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
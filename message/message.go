@@ -0,0 +1,178 @@
+package message
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/archive"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/sign"
+)
+
+const (
+	version       = 1
+	dataKeySize   = 32
+	maxHeaderSize = 64 * 1024
+)
+
+var (
+	magic         = []byte("MKMSG001")
+	signatureInfo = []byte("mkmsg-signature")
+)
+
+type Options struct {
+	AEAD string
+	Text bool
+}
+
+func DefaultOptions() Options {
+	return Options{AEAD: aead.AESGCMName}
+}
+
+type header struct {
+	Version   int             `json:"version"`
+	AEAD      string          `json:"aead"`
+	Text      bool            `json:"text"`
+	Recipient *archive.Stanza `json:"recipient"`
+	Nonce     []byte          `json:"nonce"`
+}
+
+func SignAndEncrypt(ctx context.Context, signer sign.Signer, recipient archive.Recipient, data []byte, opts *Options) ([]byte, error) {
+	options := DefaultOptions()
+	if opts != nil {
+		options = *opts
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, errors.Annotate(err, "generating data key: %w")
+	}
+
+	stanza, err := recipient.Wrap(dataKey)
+	if err != nil {
+		return nil, errors.Annotate(err, "wrapping data key: %w")
+	}
+
+	c, err := aead.New(options.AEAD, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	h := header{Version: version, AEAD: options.AEAD, Text: options.Text, Recipient: stanza, Nonce: make([]byte, c.NonceSize())}
+	if _, err := rand.Read(h.Nonce); err != nil {
+		return nil, errors.Annotate(err, "generating nonce: %w")
+	}
+
+	content := data
+	if options.Text {
+		content = Canonicalize(data)
+	}
+
+	signature, err := signer.Sign(signedData(&h, content))
+	if err != nil {
+		return nil, errors.Annotate(err, "signing message: %w")
+	}
+
+	encoded, err := json.Marshal(h)
+	if err != nil {
+		return nil, errors.Annotate(err, "encoding header: %w")
+	}
+	prefix := append([]byte{}, magic...)
+	prefix = binary.BigEndian.AppendUint32(prefix, uint32(len(encoded)))
+	prefix = append(prefix, encoded...)
+
+	inner := binary.BigEndian.AppendUint32(nil, uint32(len(signature)))
+	inner = append(inner, signature...)
+	inner = append(inner, content...)
+
+	sealed, err := c.Seal(ctx, h.Nonce, inner, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return append(prefix, sealed...), nil
+}
+
+func DecryptAndVerify(ctx context.Context, identity archive.Identity, verifier sign.Verifier, envelope []byte) ([]byte, error) {
+	h, headerLen, err := readHeader(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	inner, err := h.open(ctx, identity, envelope[headerLen:], envelope[:headerLen])
+	if err != nil {
+		return nil, errors.Annotate(err, "%w: %w", errors.ErrDecryptionFailed)
+	}
+
+	if len(inner) < 4 || uint64(binary.BigEndian.Uint32(inner)) > uint64(len(inner)-4) {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "%w: %w", errors.ErrDecryptionFailed)
+	}
+	size := binary.BigEndian.Uint32(inner)
+	signature, content := inner[4:4+size], inner[4+size:]
+
+	if err := verifier.Verify(signedData(h, content), signature); err != nil {
+		return nil, errors.Annotate(err, "verifying signature: %w")
+	}
+	return content, nil
+}
+
+func Canonicalize(text []byte) []byte {
+	normalized := bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+	normalized = bytes.ReplaceAll(normalized, []byte("\r"), []byte("\n"))
+	return bytes.ReplaceAll(normalized, []byte("\n"), []byte("\r\n"))
+}
+
+func readHeader(envelope []byte) (*header, int, error) {
+	r := bytes.NewReader(envelope)
+	prefix := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, prefix); err != nil || !bytes.Equal(prefix, magic) {
+		return nil, 0, errors.ErrInvalidFormat
+	}
+
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil || size > maxHeaderSize {
+		return nil, 0, errors.ErrInvalidFormat
+	}
+	encoded := make([]byte, size)
+	if _, err := io.ReadFull(r, encoded); err != nil {
+		return nil, 0, errors.ErrInvalidFormat
+	}
+
+	var h header
+	if err := json.Unmarshal(encoded, &h); err != nil {
+		return nil, 0, errors.Annotate(errors.ErrInvalidFormat, "decoding header: %w")
+	}
+	if h.Version != version || h.Recipient == nil {
+		return nil, 0, errors.ErrInvalidFormat
+	}
+	return &h, len(envelope) - r.Len(), nil
+}
+
+func (h *header) open(ctx context.Context, identity archive.Identity, sealed, additionalData []byte) ([]byte, error) {
+	dataKey, err := identity.Unwrap(h.Recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := aead.New(h.AEAD, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return c.Open(ctx, h.Nonce, sealed, additionalData)
+}
+
+func signedData(h *header, content []byte) []byte {
+	data := append([]byte{}, signatureInfo...)
+	for _, field := range [][]byte{[]byte(h.AEAD), h.Recipient.ID, content} {
+		data = binary.BigEndian.AppendUint32(data, uint32(len(field)))
+		data = append(data, field...)
+	}
+	if h.Text {
+		data = append(data, 1)
+	}
+	return data
+}
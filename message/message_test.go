@@ -0,0 +1,102 @@
+package message
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/archive"
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/masterkusok/crypto/sign/hashsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDHRecipient(t *testing.T) (*archive.DHRecipient, *archive.DHIdentity) {
+	params, err := dh.GenerateParameters(128, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+	priv, pub, err := dh.GenerateKey(params)
+	require.NoError(t, err)
+	return archive.NewDHRecipient(pub), archive.NewDHIdentity(priv, pub)
+}
+
+func TestSignAndEncrypt(t *testing.T) {
+	ctx := context.Background()
+	recipient, identity := newDHRecipient(t)
+
+	for _, name := range []string{aead.ChaCha20Poly1305Name, aead.AESGCMName} {
+		signer, err := hashsig.GenerateLamport()
+		require.NoError(t, err)
+
+		envelope, err := SignAndEncrypt(ctx, signer, recipient, []byte("quarterly report"), &Options{AEAD: name})
+		require.NoError(t, err)
+
+		content, err := DecryptAndVerify(ctx, identity, signer.PublicKey(), envelope)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("quarterly report"), content)
+	}
+}
+
+func TestRSARecipient(t *testing.T) {
+	ctx := context.Background()
+	r := rsa.NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 1024)
+	require.NoError(t, r.GenerateKeyPair())
+
+	signer, err := hashsig.GenerateLamport()
+	require.NoError(t, err)
+
+	envelope, err := SignAndEncrypt(ctx, signer, archive.NewRSARecipient(r.GetPublicKey()), []byte("hi"), &Options{AEAD: aead.ChaCha20Poly1305Name})
+	require.NoError(t, err)
+
+	content, err := DecryptAndVerify(ctx, archive.NewRSAIdentity(r.GetPrivateKey()), signer.PublicKey(), envelope)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hi"), content)
+}
+
+func TestTextCanonicalization(t *testing.T) {
+	ctx := context.Background()
+	recipient, identity := newDHRecipient(t)
+	signer, err := hashsig.GenerateLamport()
+	require.NoError(t, err)
+
+	envelope, err := SignAndEncrypt(ctx, signer, recipient, []byte("line one\nline two\r\n"), &Options{AEAD: aead.ChaCha20Poly1305Name, Text: true})
+	require.NoError(t, err)
+
+	content, err := DecryptAndVerify(ctx, identity, signer.PublicKey(), envelope)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("line one\r\nline two\r\n"), content)
+	assert.Equal(t, []byte("a\r\nb\r\nc"), Canonicalize([]byte("a\rb\nc")))
+}
+
+func TestDistinguishesFailures(t *testing.T) {
+	ctx := context.Background()
+	recipient, identity := newDHRecipient(t)
+	_, stranger := newDHRecipient(t)
+
+	signer, err := hashsig.GenerateLamport()
+	require.NoError(t, err)
+	impostor, err := hashsig.GenerateLamport()
+	require.NoError(t, err)
+
+	envelope, err := SignAndEncrypt(ctx, signer, recipient, []byte("payload"), &Options{AEAD: aead.ChaCha20Poly1305Name})
+	require.NoError(t, err)
+
+	_, err = DecryptAndVerify(ctx, identity, impostor.PublicKey(), envelope)
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+	assert.NotErrorIs(t, err, errors.ErrDecryptionFailed)
+
+	_, err = DecryptAndVerify(ctx, stranger, signer.PublicKey(), envelope)
+	assert.ErrorIs(t, err, errors.ErrDecryptionFailed)
+	assert.ErrorIs(t, err, errors.ErrNoMatchingRecipient)
+
+	envelope[len(envelope)-1] ^= 1
+	_, err = DecryptAndVerify(ctx, identity, signer.PublicKey(), envelope)
+	assert.ErrorIs(t, err, errors.ErrDecryptionFailed)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = DecryptAndVerify(ctx, identity, signer.PublicKey(), []byte("garbage"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
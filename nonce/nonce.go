@@ -0,0 +1,215 @@
+package nonce
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/masterkusok/crypto/cipher"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const DefaultSize = 12
+
+type NonceSource interface {
+	NonceSize() int
+	Nonce(message []byte) ([]byte, error)
+}
+
+type Random struct {
+	size   int
+	reader io.Reader
+}
+
+var _ NonceSource = (*Random)(nil)
+
+func NewRandom(size int) *Random {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Random{size: size, reader: rand.Reader}
+}
+
+func (r *Random) NonceSize() int {
+	return r.size
+}
+
+func (r *Random) Nonce(message []byte) ([]byte, error) {
+	nonce := make([]byte, r.size)
+	if _, err := io.ReadFull(r.reader, nonce); err != nil {
+		return nil, errors.Annotate(err, "failed to read random nonce: %w")
+	}
+	return nonce, nil
+}
+
+type Counter struct {
+	mu      sync.Mutex
+	size    int
+	path    string
+	current []byte
+}
+
+var _ NonceSource = (*Counter)(nil)
+
+func NewCounter(path string, size int) (*Counter, error) {
+	if size <= 0 {
+		size = DefaultSize
+	}
+
+	c := &Counter{size: size, path: path, current: make([]byte, size)}
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, errors.Annotate(err, "reading counter state: %w")
+	}
+
+	state, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(state) != size {
+		return nil, errors.ErrInvalidNonceState
+	}
+	c.current = state
+
+	return c, nil
+}
+
+func (c *Counter) NonceSize() int {
+	return c.size
+}
+
+func (c *Counter) Current() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]byte{}, c.current...)
+}
+
+func (c *Counter) Nonce(message []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next := append([]byte{}, c.current...)
+	if !increment(next) {
+		return nil, errors.ErrNonceExhausted
+	}
+
+	if err := c.persist(next); err != nil {
+		return nil, err
+	}
+	c.current = next
+
+	return append([]byte{}, next...), nil
+}
+
+func (c *Counter) persist(state []byte) error {
+	if c.path == "" {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".nonce-*")
+	if err != nil {
+		return errors.Annotate(err, "creating counter state: %w")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(hex.EncodeToString(state)); err != nil {
+		tmp.Close()
+		return errors.Annotate(err, "writing counter state: %w")
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Annotate(err, "syncing counter state: %w")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Annotate(err, "closing counter state: %w")
+	}
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return errors.Annotate(err, "replacing counter state: %w")
+	}
+
+	return nil
+}
+
+func increment(counter []byte) bool {
+	for i := len(counter) - 1; i >= 0; i-- {
+		counter[i]++
+		if counter[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Deterministic reports whether source derives each nonce from the message
+// alone, so that callers must pass everything that makes a message unique.
+func Deterministic(source NonceSource) bool {
+	_, ok := source.(*Derived)
+	return ok
+}
+
+type Derived struct {
+	mu    sync.Mutex
+	block cipher.BlockCipher
+	size  int
+}
+
+var _ NonceSource = (*Derived)(nil)
+
+func NewDerived(ctx context.Context, block cipher.BlockCipher, key []byte, size int) (*Derived, error) {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	if size > block.BlockSize() {
+		return nil, errors.ErrInvalidParameters
+	}
+	if err := block.SetKey(ctx, key); err != nil {
+		return nil, errors.Annotate(err, "failed to set key: %w")
+	}
+
+	return &Derived{block: block, size: size}, nil
+}
+
+func (d *Derived) NonceSize() int {
+	return d.size
+}
+
+func (d *Derived) Nonce(message []byte) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ctx := context.Background()
+	blockSize := d.block.BlockSize()
+
+	prefixed := make([]byte, 8, 8+len(message)+blockSize)
+	binary.BigEndian.PutUint64(prefixed, uint64(len(message)))
+	prefixed = append(prefixed, message...)
+	if rem := len(prefixed) % blockSize; rem != 0 {
+		prefixed = append(prefixed, make([]byte, blockSize-rem)...)
+	}
+
+	state := make([]byte, blockSize)
+	for i := 0; i < len(prefixed); i += blockSize {
+		for j := range state {
+			state[j] ^= prefixed[i+j]
+		}
+
+		encrypted, err := d.block.Encrypt(ctx, state)
+		if err != nil {
+			return nil, errors.Annotate(err, "failed to derive nonce: %w")
+		}
+		state = encrypted
+	}
+
+	return state[:d.size], nil
+}
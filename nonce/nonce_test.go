@@ -0,0 +1,100 @@
+package nonce
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandom(t *testing.T) {
+	source := NewRandom(0)
+	assert.Equal(t, 12, source.NonceSize())
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		n, err := source.Nonce(nil)
+		require.NoError(t, err)
+		require.Len(t, n, 12)
+		assert.False(t, seen[string(n)])
+		seen[string(n)] = true
+	}
+}
+
+func TestCounterPersistsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+
+	counter, err := NewCounter(path, 4)
+	require.NoError(t, err)
+
+	first, err := counter.Nonce(nil)
+	require.NoError(t, err)
+	second, err := counter.Nonce(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 1}, first)
+	assert.Equal(t, []byte{0, 0, 0, 2}, second)
+	assert.Equal(t, second, counter.Current())
+
+	reopened, err := NewCounter(path, 4)
+	require.NoError(t, err)
+	third, err := reopened.Nonce(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 3}, third)
+}
+
+func TestCounterExhausted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+	require.NoError(t, os.WriteFile(path, []byte("ff"), 0o600))
+
+	counter, err := NewCounter(path, 1)
+	require.NoError(t, err)
+
+	_, err = counter.Nonce(nil)
+	assert.ErrorIs(t, err, errors.ErrNonceExhausted)
+}
+
+func TestCounterInvalidState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+	require.NoError(t, os.WriteFile(path, []byte("zz"), 0o600))
+
+	_, err := NewCounter(path, 1)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceState)
+}
+
+func TestDerived(t *testing.T) {
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+
+	source, err := NewDerived(context.Background(), block, make([]byte, 16), 12)
+	require.NoError(t, err)
+
+	a1, err := source.Nonce([]byte("message one"))
+	require.NoError(t, err)
+	a2, err := source.Nonce([]byte("message one"))
+	require.NoError(t, err)
+	b, err := source.Nonce([]byte("message two"))
+	require.NoError(t, err)
+	empty, err := source.Nonce(nil)
+	require.NoError(t, err)
+
+	assert.Len(t, a1, 12)
+	assert.Equal(t, a1, a2)
+	assert.NotEqual(t, a1, b)
+	assert.NotEqual(t, a1, empty)
+
+	assert.True(t, Deterministic(source))
+	assert.False(t, Deterministic(NewRandom(12)))
+}
+
+func TestDerivedSizeTooLarge(t *testing.T) {
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+
+	_, err = NewDerived(context.Background(), block, make([]byte, 16), 24)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
@@ -0,0 +1,117 @@
+package paillier
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+var one = big.NewInt(1)
+
+type PublicKey struct {
+	N        *big.Int
+	NSquared *big.Int
+	G        *big.Int
+}
+
+type PrivateKey struct {
+	PublicKey
+	Lambda *big.Int
+	Mu     *big.Int
+}
+
+func NewPublicKey(n *big.Int) *PublicKey {
+	return &PublicKey{
+		N:        n,
+		NSquared: new(big.Int).Mul(n, n),
+		G:        new(big.Int).Add(n, one),
+	}
+}
+
+func GenerateKey(bits int, tester cryptoMath.PrimalityTester, minProbability float64) (*PrivateKey, error) {
+	opts := &cryptoMath.PrimeOptions{MinProbability: minProbability, TopTwoBits: true}
+
+	for {
+		p, err := cryptoMath.GeneratePrime(context.Background(), bits/2, tester, opts)
+		if err != nil {
+			return nil, err
+		}
+		q, err := cryptoMath.GeneratePrime(context.Background(), bits/2, tester, opts)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+
+		pub := NewPublicKey(new(big.Int).Mul(p, q))
+		pMinus, qMinus := new(big.Int).Sub(p, one), new(big.Int).Sub(q, one)
+		lambda := new(big.Int).Div(new(big.Int).Mul(pMinus, qMinus), cryptoMath.GCD(pMinus, qMinus))
+
+		mu := cryptoMath.ModInverse(pub.l(new(big.Int).Exp(pub.G, lambda, pub.NSquared)), pub.N)
+		if mu == nil {
+			continue
+		}
+
+		return &PrivateKey{PublicKey: *pub, Lambda: lambda, Mu: mu}, nil
+	}
+}
+
+func (pk *PublicKey) RandomNonce() (*big.Int, error) {
+	for {
+		r, err := rand.Int(rand.Reader, pk.N)
+		if err != nil {
+			return nil, errors.Annotate(err, "generating nonce: %w")
+		}
+		if r.Sign() > 0 && cryptoMath.GCD(r, pk.N).Cmp(one) == 0 {
+			return r, nil
+		}
+	}
+}
+
+func (pk *PublicKey) Encrypt(m *big.Int) (*big.Int, error) {
+	r, err := pk.RandomNonce()
+	if err != nil {
+		return nil, err
+	}
+	return pk.EncryptWithNonce(m, r)
+}
+
+func (pk *PublicKey) EncryptWithNonce(m, r *big.Int) (*big.Int, error) {
+	if m.Sign() < 0 || m.Cmp(pk.N) >= 0 {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	gm := new(big.Int).Mul(m, pk.N)
+	gm.Add(gm, one)
+
+	c := new(big.Int).Exp(r, pk.N, pk.NSquared)
+	c.Mul(c, gm)
+	return c.Mod(c, pk.NSquared), nil
+}
+
+func (pk *PublicKey) Add(a, b *big.Int) *big.Int {
+	sum := new(big.Int).Mul(a, b)
+	return sum.Mod(sum, pk.NSquared)
+}
+
+func (pk *PublicKey) MulPlain(c, k *big.Int) *big.Int {
+	return new(big.Int).Exp(c, k, pk.NSquared)
+}
+
+func (pk *PublicKey) l(u *big.Int) *big.Int {
+	return new(big.Int).Div(new(big.Int).Sub(u, one), pk.N)
+}
+
+func (sk *PrivateKey) Decrypt(c *big.Int) (*big.Int, error) {
+	if c.Sign() <= 0 || c.Cmp(sk.NSquared) >= 0 {
+		return nil, errors.ErrInvalidDataLength
+	}
+
+	m := sk.l(new(big.Int).Exp(c, sk.Lambda, sk.NSquared))
+	m.Mul(m, sk.Mu)
+	return m.Mod(m, sk.N), nil
+}
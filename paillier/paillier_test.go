@@ -0,0 +1,43 @@
+package paillier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	sk, err := GenerateKey(256, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	c, err := sk.Encrypt(big.NewInt(42))
+	require.NoError(t, err)
+	m, err := sk.Decrypt(c)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), m)
+
+	_, err = sk.Encrypt(sk.N)
+	assert.ErrorIs(t, err, errors.ErrInvalidDataLength)
+}
+
+func TestHomomorphism(t *testing.T) {
+	sk, err := GenerateKey(256, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	a, err := sk.Encrypt(big.NewInt(17))
+	require.NoError(t, err)
+	b, err := sk.Encrypt(big.NewInt(25))
+	require.NoError(t, err)
+
+	sum, err := sk.Decrypt(sk.Add(a, b))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), sum)
+
+	product, err := sk.Decrypt(sk.MulPlain(a, big.NewInt(3)))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(51), product)
+}
@@ -0,0 +1,156 @@
+package paillier
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+type ThresholdKey struct {
+	PublicKey
+	Threshold int
+	Parties   int
+	delta     *big.Int
+}
+
+type KeyShare struct {
+	Index int
+	Value *big.Int
+}
+
+type DecryptionShare struct {
+	Index int
+	Value *big.Int
+}
+
+func GenerateThresholdKey(bits, threshold, parties int, tester cryptoMath.PrimalityTester, minProbability float64) (*ThresholdKey, []*KeyShare, error) {
+	if threshold < 1 || threshold > parties {
+		return nil, nil, errors.ErrInvalidParameters
+	}
+
+	p, err := safePrime(bits/2, tester, minProbability)
+	if err != nil {
+		return nil, nil, err
+	}
+	q, err := safePrime(bits/2, tester, minProbability)
+	if err != nil {
+		return nil, nil, err
+	}
+	for p.Cmp(q) == 0 {
+		if q, err = safePrime(bits/2, tester, minProbability); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pub := NewPublicKey(new(big.Int).Mul(p, q))
+	m := new(big.Int).Mul(new(big.Int).Rsh(p, 1), new(big.Int).Rsh(q, 1))
+	modulus := new(big.Int).Mul(pub.N, m)
+
+	d := cryptoMath.ModInverse(m, pub.N)
+	if d == nil {
+		return nil, nil, errors.ErrInvalidParameters
+	}
+	d.Mul(d, m)
+
+	coeffs := []*big.Int{d}
+	for i := 1; i < threshold; i++ {
+		c, err := rand.Int(rand.Reader, modulus)
+		if err != nil {
+			return nil, nil, errors.Annotate(err, "generating coefficient: %w")
+		}
+		coeffs = append(coeffs, c)
+	}
+
+	shares := make([]*KeyShare, parties)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		value := new(big.Int)
+		for j := len(coeffs) - 1; j >= 0; j-- {
+			value.Mul(value, x)
+			value.Add(value, coeffs[j])
+			value.Mod(value, modulus)
+		}
+		shares[i] = &KeyShare{Index: i + 1, Value: value}
+	}
+
+	key := &ThresholdKey{PublicKey: *pub, Threshold: threshold, Parties: parties, delta: factorial(parties)}
+	return key, shares, nil
+}
+
+func (k *ThresholdKey) DecryptShare(share *KeyShare, c *big.Int) *DecryptionShare {
+	exponent := new(big.Int).Mul(big.NewInt(2), k.delta)
+	exponent.Mul(exponent, share.Value)
+	return &DecryptionShare{Index: share.Index, Value: new(big.Int).Exp(c, exponent, k.NSquared)}
+}
+
+func (k *ThresholdKey) Combine(shares []*DecryptionShare) (*big.Int, error) {
+	if len(shares) < k.Threshold {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "not enough decryption shares: %w")
+	}
+	shares = shares[:k.Threshold]
+
+	seen := make(map[int]bool, len(shares))
+	for _, s := range shares {
+		if s.Index < 1 || s.Index > k.Parties || seen[s.Index] {
+			return nil, errors.ErrInvalidFormat
+		}
+		seen[s.Index] = true
+	}
+
+	combined := big.NewInt(1)
+	for _, s := range shares {
+		exponent := new(big.Int).Mul(big.NewInt(2), k.lagrange(s.Index, shares))
+		base := s.Value
+		if exponent.Sign() < 0 {
+			if base = new(big.Int).ModInverse(base, k.NSquared); base == nil {
+				return nil, errors.ErrInvalidFormat
+			}
+			exponent.Neg(exponent)
+		}
+		combined.Mul(combined, new(big.Int).Exp(base, exponent, k.NSquared))
+		combined.Mod(combined, k.NSquared)
+	}
+
+	scale := new(big.Int).Mul(k.delta, k.delta)
+	scale.Lsh(scale, 2)
+	inverse := cryptoMath.ModInverse(new(big.Int).Mod(scale, k.N), k.N)
+	if inverse == nil {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	m := k.l(combined)
+	m.Mul(m, inverse)
+	return m.Mod(m, k.N), nil
+}
+
+func (k *ThresholdKey) lagrange(i int, shares []*DecryptionShare) *big.Int {
+	num := new(big.Int).Set(k.delta)
+	den := big.NewInt(1)
+	for _, s := range shares {
+		if s.Index == i {
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(s.Index)))
+		den.Mul(den, big.NewInt(int64(s.Index-i)))
+	}
+	return num.Quo(num, den)
+}
+
+func safePrime(bits int, tester cryptoMath.PrimalityTester, minProbability float64) (*big.Int, error) {
+	params, err := dh.GenerateParameters(bits, tester, minProbability)
+	if err != nil {
+		return nil, err
+	}
+	return params.P, nil
+}
+
+func factorial(n int) *big.Int {
+	result := big.NewInt(1)
+	for i := 2; i <= n; i++ {
+		result.Mul(result, big.NewInt(int64(i)))
+	}
+	return result
+}
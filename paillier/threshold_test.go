@@ -0,0 +1,37 @@
+package paillier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdDecryption(t *testing.T) {
+	key, shares, err := GenerateThresholdKey(256, 3, 5, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	c, err := key.Encrypt(big.NewInt(1234))
+	require.NoError(t, err)
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+		var partials []*DecryptionShare
+		for _, i := range subset {
+			partials = append(partials, key.DecryptShare(shares[i], c))
+		}
+
+		m, err := key.Combine(partials)
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(1234), m)
+	}
+
+	_, err = key.Combine([]*DecryptionShare{key.DecryptShare(shares[0], c), key.DecryptShare(shares[1], c)})
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	duplicate := key.DecryptShare(shares[0], c)
+	_, err = key.Combine([]*DecryptionShare{duplicate, duplicate, duplicate})
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
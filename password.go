@@ -0,0 +1,198 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/kdf/argon2"
+)
+
+const (
+	passwordVersion  = 1
+	passwordKDF      = "argon2id"
+	passwordKeySize  = 32
+	passwordSaltSize = 16
+
+	maxPasswordHeaderSize = 64 * 1024
+
+	// The KDF parameters come from the file being decrypted, so they are
+	// capped before Argon2 allocates Memory KiB and runs Time passes.
+	maxPasswordTime        = 16
+	maxPasswordMemory      = 1024 * 1024
+	maxPasswordParallelism = 64
+)
+
+var passwordMagic = []byte("MKPWENC1")
+
+type PasswordOptions struct {
+	KDF  argon2.Params
+	AEAD string
+}
+
+func DefaultPasswordOptions() PasswordOptions {
+	return PasswordOptions{KDF: argon2.DefaultParams(), AEAD: aead.AESGCMName}
+}
+
+type passwordHeader struct {
+	Version     int    `json:"version"`
+	KDF         string `json:"kdf"`
+	Time        uint32 `json:"time"`
+	Memory      uint32 `json:"memory"`
+	Parallelism uint8  `json:"parallelism"`
+	Salt        []byte `json:"salt"`
+	AEAD        string `json:"aead"`
+	Nonce       []byte `json:"nonce"`
+}
+
+func EncryptFileWithPassword(ctx context.Context, in, out string, password []byte, opts *PasswordOptions) error {
+	plaintext, err := os.ReadFile(in)
+	if err != nil {
+		return errors.Annotate(err, "reading file: %w")
+	}
+
+	sealed, err := EncryptWithPassword(ctx, plaintext, password, opts)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(out, sealed)
+}
+
+func DecryptFileWithPassword(ctx context.Context, in, out string, password []byte) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return errors.Annotate(err, "reading file: %w")
+	}
+
+	plaintext, err := DecryptWithPassword(ctx, data, password)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(out, plaintext)
+}
+
+func EncryptWithPassword(ctx context.Context, plaintext, password []byte, opts *PasswordOptions) ([]byte, error) {
+	options := DefaultPasswordOptions()
+	if opts != nil {
+		options = *opts
+	}
+
+	h := passwordHeader{
+		Version:     passwordVersion,
+		KDF:         passwordKDF,
+		Time:        options.KDF.Time,
+		Memory:      options.KDF.Memory,
+		Parallelism: options.KDF.Parallelism,
+		Salt:        make([]byte, passwordSaltSize),
+		AEAD:        options.AEAD,
+	}
+	if _, err := rand.Read(h.Salt); err != nil {
+		return nil, errors.Annotate(err, "generating salt: %w")
+	}
+	if err := h.validateKDF(); err != nil {
+		return nil, err
+	}
+
+	cipher, err := h.cipher(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Nonce = make([]byte, cipher.NonceSize())
+	if _, err := rand.Read(h.Nonce); err != nil {
+		return nil, errors.Annotate(err, "generating nonce: %w")
+	}
+
+	encoded, err := json.Marshal(h)
+	if err != nil {
+		return nil, errors.Annotate(err, "encoding header: %w")
+	}
+	prefix := append([]byte{}, passwordMagic...)
+	prefix = binary.BigEndian.AppendUint32(prefix, uint32(len(encoded)))
+	prefix = append(prefix, encoded...)
+
+	sealed, err := cipher.Seal(ctx, h.Nonce, plaintext, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(prefix, sealed...), nil
+}
+
+func DecryptWithPassword(ctx context.Context, data, password []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	magic := make([]byte, len(passwordMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, passwordMagic) {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil || size > maxPasswordHeaderSize {
+		return nil, errors.ErrInvalidFormat
+	}
+	encoded := make([]byte, size)
+	if _, err := io.ReadFull(r, encoded); err != nil {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	var h passwordHeader
+	if err := json.Unmarshal(encoded, &h); err != nil {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "decoding header: %w")
+	}
+	if h.Version != passwordVersion || h.KDF != passwordKDF {
+		return nil, errors.ErrInvalidFormat
+	}
+	if err := h.validateKDF(); err != nil {
+		return nil, err
+	}
+
+	cipher, err := h.cipher(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+
+	headerLen := len(data) - r.Len()
+	return cipher.Open(ctx, h.Nonce, data[headerLen:], data[:headerLen])
+}
+
+func (h *passwordHeader) validateKDF() error {
+	if h.Time > maxPasswordTime || h.Memory > maxPasswordMemory || h.Parallelism > maxPasswordParallelism {
+		return errors.Annotate(errors.ErrInvalidParameters, "KDF parameters time=%d memory=%d parallelism=%d exceed the limits: %w", h.Time, h.Memory, h.Parallelism)
+	}
+	return nil
+}
+
+func (h *passwordHeader) cipher(ctx context.Context, password []byte) (aead.AEAD, error) {
+	key, err := argon2.IDKey(ctx, password, h.Salt, argon2.Params{
+		Time:        h.Time,
+		Memory:      h.Memory,
+		Parallelism: h.Parallelism,
+		KeyLength:   passwordKeySize,
+	})
+	if err != nil {
+		return nil, errors.Annotate(err, "deriving key: %w")
+	}
+
+	return aead.New(h.AEAD, key)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".partial"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		os.Remove(tmp)
+		return errors.Annotate(err, "writing file: %w")
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return errors.Annotate(err, "renaming file: %w")
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package crypto
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/kdf/argon2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions(name string) *PasswordOptions {
+	return &PasswordOptions{
+		KDF:  argon2.Params{Time: 1, Memory: 64, Parallelism: 1},
+		AEAD: name,
+	}
+}
+
+func TestPasswordRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.txt")
+	sealed := filepath.Join(dir, "plain.txt.enc")
+	restored := filepath.Join(dir, "restored.txt")
+	content := []byte("nobody should need a fixed IV to encrypt a file")
+	require.NoError(t, os.WriteFile(plain, content, 0o600))
+
+	for _, name := range []string{aead.AESGCMName, aead.ChaCha20Poly1305Name} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, EncryptFileWithPassword(ctx, plain, sealed, []byte("hunter2"), testOptions(name)))
+
+			encrypted, err := os.ReadFile(sealed)
+			require.NoError(t, err)
+			assert.NotContains(t, string(encrypted), string(content))
+
+			require.NoError(t, DecryptFileWithPassword(ctx, sealed, restored, []byte("hunter2")))
+			got, err := os.ReadFile(restored)
+			require.NoError(t, err)
+			assert.Equal(t, content, got)
+		})
+	}
+}
+
+func TestPasswordWrongPassword(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.txt")
+	sealed := filepath.Join(dir, "plain.txt.enc")
+	require.NoError(t, os.WriteFile(plain, []byte("secret"), 0o600))
+	require.NoError(t, EncryptFileWithPassword(ctx, plain, sealed, []byte("right"), testOptions(aead.ChaCha20Poly1305Name)))
+
+	err := DecryptFileWithPassword(ctx, sealed, filepath.Join(dir, "out"), []byte("wrong"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+	assert.NoFileExists(t, filepath.Join(dir, "out"))
+}
+
+func TestPasswordTamperedHeader(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.txt")
+	sealed := filepath.Join(dir, "plain.txt.enc")
+	require.NoError(t, os.WriteFile(plain, []byte("secret"), 0o600))
+	require.NoError(t, EncryptFileWithPassword(ctx, plain, sealed, []byte("pw"), testOptions(aead.ChaCha20Poly1305Name)))
+
+	data, err := os.ReadFile(sealed)
+	require.NoError(t, err)
+
+	bad := append([]byte{}, data...)
+	bad[0] = 'X'
+	require.NoError(t, os.WriteFile(sealed, bad, 0o600))
+	err = DecryptFileWithPassword(ctx, sealed, filepath.Join(dir, "out"), []byte("pw"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
+
+func TestPasswordInMemory(t *testing.T) {
+	ctx := context.Background()
+	content := []byte("in-memory payloads use the same format as files")
+
+	sealed, err := EncryptWithPassword(ctx, content, []byte("pw"), testOptions(aead.AESGCMName))
+	require.NoError(t, err)
+	assert.Equal(t, passwordMagic, sealed[:len(passwordMagic)])
+
+	got, err := DecryptWithPassword(ctx, sealed, []byte("pw"))
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	_, err = DecryptWithPassword(ctx, sealed, []byte("other"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+	_, err = DecryptWithPassword(ctx, sealed[:4], []byte("pw"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
+
+func TestPasswordKDFLimits(t *testing.T) {
+	ctx := context.Background()
+
+	opts := testOptions(aead.AESGCMName)
+	opts.KDF.Memory = maxPasswordMemory + 1
+	_, err := EncryptWithPassword(ctx, []byte("secret"), []byte("pw"), opts)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	sealed, err := EncryptWithPassword(ctx, []byte("secret"), []byte("pw"), testOptions(aead.AESGCMName))
+	require.NoError(t, err)
+	size := binary.BigEndian.Uint32(sealed[len(passwordMagic):])
+	start := len(passwordMagic) + 4
+
+	var h passwordHeader
+	require.NoError(t, json.Unmarshal(sealed[start:start+int(size)], &h))
+	for name, tamper := range map[string]func(*passwordHeader){
+		"memory":      func(h *passwordHeader) { h.Memory = 1 << 31 },
+		"time":        func(h *passwordHeader) { h.Time = 1 << 30 },
+		"parallelism": func(h *passwordHeader) { h.Parallelism = 255 },
+	} {
+		crafted := h
+		tamper(&crafted)
+		encoded, err := json.Marshal(crafted)
+		require.NoError(t, err)
+		data := append([]byte{}, passwordMagic...)
+		data = binary.BigEndian.AppendUint32(data, uint32(len(encoded)))
+		data = append(append(data, encoded...), sealed[start+int(size):]...)
+
+		_, err = DecryptWithPassword(ctx, data, []byte("pw"))
+		assert.ErrorIs(t, err, errors.ErrInvalidParameters, name)
+	}
+}
+
+func TestPasswordRemovesPartialFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.txt")
+	require.NoError(t, os.WriteFile(plain, []byte("secret"), 0o600))
+
+	// A non-empty directory in the way makes the final rename fail.
+	out := filepath.Join(dir, "out")
+	require.NoError(t, os.MkdirAll(filepath.Join(out, "occupied"), 0o700))
+
+	err := EncryptFileWithPassword(ctx, plain, out, []byte("pw"), testOptions(aead.AESGCMName))
+	assert.Error(t, err)
+	assert.NoFileExists(t, out+".partial")
+
+	err = EncryptFileWithPassword(ctx, plain, filepath.Join(dir, "missing", "out"), []byte("pw"), testOptions(aead.AESGCMName))
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "missing", "out.partial"))
+}
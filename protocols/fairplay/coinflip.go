@@ -0,0 +1,107 @@
+package fairplay
+
+import (
+	"crypto/rand"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type CommitMessage struct {
+	Commitment []byte
+}
+
+type GuessMessage struct {
+	Bit byte
+}
+
+type RevealMessage struct {
+	Opening *Opening
+}
+
+type CoinFlipInitiator struct {
+	opening *Opening
+	done    bool
+}
+
+type CoinFlipResponder struct {
+	commitment []byte
+	bit        byte
+	done       bool
+}
+
+func NewCoinFlipInitiator() *CoinFlipInitiator {
+	return &CoinFlipInitiator{}
+}
+
+func (c *CoinFlipInitiator) Start() (*CommitMessage, error) {
+	if c.opening != nil {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+
+	bit, err := randomBit()
+	if err != nil {
+		return nil, err
+	}
+
+	commitment, opening, err := Commit([]byte{bit})
+	if err != nil {
+		return nil, err
+	}
+	c.opening = opening
+
+	return &CommitMessage{Commitment: commitment}, nil
+}
+
+func (c *CoinFlipInitiator) HandleGuess(msg *GuessMessage) (*RevealMessage, byte, error) {
+	if c.opening == nil || c.done {
+		return nil, 0, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+	if msg.Bit > 1 {
+		return nil, 0, errors.ErrInvalidFormat
+	}
+	c.done = true
+
+	return &RevealMessage{Opening: c.opening}, c.opening.Value[0] ^ msg.Bit, nil
+}
+
+func NewCoinFlipResponder() *CoinFlipResponder {
+	return &CoinFlipResponder{}
+}
+
+func (c *CoinFlipResponder) HandleCommit(msg *CommitMessage) (*GuessMessage, error) {
+	if c.commitment != nil {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+
+	bit, err := randomBit()
+	if err != nil {
+		return nil, err
+	}
+	c.commitment = msg.Commitment
+	c.bit = bit
+
+	return &GuessMessage{Bit: bit}, nil
+}
+
+func (c *CoinFlipResponder) HandleReveal(msg *RevealMessage) (byte, error) {
+	if c.commitment == nil || c.done {
+		return 0, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+	if err := VerifyCommitment(c.commitment, msg.Opening); err != nil {
+		return 0, err
+	}
+	if len(msg.Opening.Value) != 1 || msg.Opening.Value[0] > 1 {
+		return 0, errors.ErrInvalidFormat
+	}
+	c.done = true
+
+	return msg.Opening.Value[0] ^ c.bit, nil
+}
+
+func randomBit() (byte, error) {
+	b := make([]byte, 1)
+	if _, err := rand.Read(b); err != nil {
+		return 0, errors.Annotate(err, "generating coin: %w")
+	}
+	return b[0] & 1, nil
+}
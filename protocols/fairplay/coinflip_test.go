@@ -0,0 +1,58 @@
+package fairplay
+
+import (
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoinFlip(t *testing.T) {
+	seen := make(map[byte]bool)
+	for i := 0; i < 32; i++ {
+		alice, bob := NewCoinFlipInitiator(), NewCoinFlipResponder()
+
+		commit, err := alice.Start()
+		require.NoError(t, err)
+		guess, err := bob.HandleCommit(commit)
+		require.NoError(t, err)
+		reveal, aliceResult, err := alice.HandleGuess(guess)
+		require.NoError(t, err)
+		bobResult, err := bob.HandleReveal(reveal)
+		require.NoError(t, err)
+
+		assert.Equal(t, aliceResult, bobResult)
+		seen[aliceResult] = true
+	}
+	assert.Len(t, seen, 2)
+}
+
+func TestCoinFlipCheatingInitiator(t *testing.T) {
+	alice, bob := NewCoinFlipInitiator(), NewCoinFlipResponder()
+
+	commit, err := alice.Start()
+	require.NoError(t, err)
+	guess, err := bob.HandleCommit(commit)
+	require.NoError(t, err)
+	reveal, _, err := alice.HandleGuess(guess)
+	require.NoError(t, err)
+
+	reveal.Opening.Value = []byte{reveal.Opening.Value[0] ^ 1}
+	_, err = bob.HandleReveal(reveal)
+	assert.ErrorIs(t, err, errors.ErrCommitmentMismatch)
+}
+
+func TestCoinFlipPhases(t *testing.T) {
+	alice := NewCoinFlipInitiator()
+	_, _, err := alice.HandleGuess(&GuessMessage{})
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = alice.Start()
+	require.NoError(t, err)
+	_, err = alice.Start()
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = NewCoinFlipResponder().HandleReveal(&RevealMessage{})
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
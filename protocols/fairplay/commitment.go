@@ -0,0 +1,41 @@
+package fairplay
+
+import (
+	"bytes"
+	"crypto/rand"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/blake2b"
+)
+
+const commitmentNonceSize = 32
+
+type Opening struct {
+	Value []byte
+	Nonce []byte
+}
+
+func Commit(value []byte) ([]byte, *Opening, error) {
+	nonce := make([]byte, commitmentNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, errors.Annotate(err, "generating commitment nonce: %w")
+	}
+
+	opening := &Opening{Value: append([]byte{}, value...), Nonce: nonce}
+	return opening.commitment(), opening, nil
+}
+
+func VerifyCommitment(commitment []byte, opening *Opening) error {
+	if opening == nil || len(opening.Nonce) != commitmentNonceSize {
+		return errors.ErrInvalidFormat
+	}
+	if !bytes.Equal(commitment, opening.commitment()) {
+		return errors.ErrCommitmentMismatch
+	}
+	return nil
+}
+
+func (o *Opening) commitment() []byte {
+	data := append([]byte("fairplay-commit"), o.Nonce...)
+	return blake2b.Sum256(append(data, o.Value...))
+}
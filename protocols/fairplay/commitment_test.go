@@ -0,0 +1,23 @@
+package fairplay
+
+import (
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitment(t *testing.T) {
+	commitment, opening, err := Commit([]byte("heads"))
+	require.NoError(t, err)
+	require.NoError(t, VerifyCommitment(commitment, opening))
+
+	other, _, err := Commit([]byte("heads"))
+	require.NoError(t, err)
+	assert.NotEqual(t, commitment, other)
+
+	opening.Value = []byte("tails")
+	assert.ErrorIs(t, VerifyCommitment(commitment, opening), errors.ErrCommitmentMismatch)
+	assert.ErrorIs(t, VerifyCommitment(commitment, &Opening{}), errors.ErrInvalidFormat)
+}
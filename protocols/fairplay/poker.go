@@ -0,0 +1,209 @@
+package fairplay
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+)
+
+type DeckMessage struct {
+	Cards []*big.Int
+}
+
+type DealMessage struct {
+	DealerCards []*big.Int
+	PlayerCards []*big.Int
+}
+
+type UnlockMessage struct {
+	Cards []*big.Int
+}
+
+type sraKey struct {
+	p *big.Int
+	e *big.Int
+	d *big.Int
+}
+
+type deck struct {
+	encodings map[string]int
+	cards     []*big.Int
+}
+
+type PokerDealer struct {
+	key      *sraKey
+	deck     *deck
+	handSize int
+	dealt    []*big.Int
+	hand     []int
+}
+
+type PokerPlayer struct {
+	key      *sraKey
+	deck     *deck
+	handSize int
+	hand     []int
+}
+
+func NewPokerDealer(params *dh.Parameters, deckSize, handSize int) (*PokerDealer, error) {
+	if deckSize < 2*handSize || handSize < 1 {
+		return nil, errors.ErrInvalidParameters
+	}
+	key, err := newSRAKey(params)
+	if err != nil {
+		return nil, err
+	}
+	return &PokerDealer{key: key, deck: newDeck(params.P, deckSize), handSize: handSize}, nil
+}
+
+func (d *PokerDealer) Start() (*DeckMessage, error) {
+	if d.dealt != nil {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+
+	cards, err := d.key.shuffle(d.deck.cards)
+	if err != nil {
+		return nil, err
+	}
+	return &DeckMessage{Cards: cards}, nil
+}
+
+func (d *PokerDealer) HandleDeck(msg *DeckMessage) (*DealMessage, error) {
+	if d.dealt != nil {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+	if len(msg.Cards) != len(d.deck.cards) {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	d.dealt = msg.Cards[:d.handSize]
+	return &DealMessage{
+		DealerCards: d.dealt,
+		PlayerCards: d.key.decrypt(msg.Cards[d.handSize : 2*d.handSize]),
+	}, nil
+}
+
+func (d *PokerDealer) HandleUnlock(msg *UnlockMessage) ([]int, error) {
+	if d.dealt == nil || d.hand != nil {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+	if len(msg.Cards) != d.handSize {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	hand, err := d.deck.decode(d.key.decrypt(msg.Cards))
+	if err != nil {
+		return nil, err
+	}
+	d.hand = hand
+	return hand, nil
+}
+
+func NewPokerPlayer(params *dh.Parameters, deckSize, handSize int) (*PokerPlayer, error) {
+	if deckSize < 2*handSize || handSize < 1 {
+		return nil, errors.ErrInvalidParameters
+	}
+	key, err := newSRAKey(params)
+	if err != nil {
+		return nil, err
+	}
+	return &PokerPlayer{key: key, deck: newDeck(params.P, deckSize), handSize: handSize}, nil
+}
+
+func (p *PokerPlayer) HandleDeck(msg *DeckMessage) (*DeckMessage, error) {
+	if len(msg.Cards) != len(p.deck.cards) {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	cards, err := p.key.shuffle(msg.Cards)
+	if err != nil {
+		return nil, err
+	}
+	return &DeckMessage{Cards: cards}, nil
+}
+
+func (p *PokerPlayer) HandleDeal(msg *DealMessage) (*UnlockMessage, []int, error) {
+	if p.hand != nil {
+		return nil, nil, errors.Annotate(errors.ErrInvalidParameters, "unexpected phase: %w")
+	}
+	if len(msg.DealerCards) != p.handSize || len(msg.PlayerCards) != p.handSize {
+		return nil, nil, errors.ErrInvalidFormat
+	}
+
+	hand, err := p.deck.decode(p.key.decrypt(msg.PlayerCards))
+	if err != nil {
+		return nil, nil, err
+	}
+	p.hand = hand
+
+	return &UnlockMessage{Cards: p.key.decrypt(msg.DealerCards)}, hand, nil
+}
+
+func newSRAKey(params *dh.Parameters) (*sraKey, error) {
+	if params == nil || params.P == nil || params.P.BitLen() < 16 {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	order := new(big.Int).Sub(params.P, big.NewInt(1))
+	for {
+		e, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return nil, errors.Annotate(err, "generating exponent: %w")
+		}
+		if e.Cmp(big.NewInt(3)) < 0 {
+			continue
+		}
+		if d := cryptoMath.ModInverse(e, order); d != nil {
+			return &sraKey{p: params.P, e: e, d: d}, nil
+		}
+	}
+}
+
+func (k *sraKey) shuffle(cards []*big.Int) ([]*big.Int, error) {
+	out := make([]*big.Int, len(cards))
+	for i, c := range cards {
+		out[i] = new(big.Int).Exp(c, k.e, k.p)
+	}
+
+	for i := len(out) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, errors.Annotate(err, "shuffling deck: %w")
+		}
+		out[i], out[j.Int64()] = out[j.Int64()], out[i]
+	}
+	return out, nil
+}
+
+func (k *sraKey) decrypt(cards []*big.Int) []*big.Int {
+	out := make([]*big.Int, len(cards))
+	for i, c := range cards {
+		out[i] = new(big.Int).Exp(c, k.d, k.p)
+	}
+	return out
+}
+
+func newDeck(p *big.Int, size int) *deck {
+	d := &deck{encodings: make(map[string]int, size), cards: make([]*big.Int, size)}
+	for i := range d.cards {
+		base := big.NewInt(int64(i + 2))
+		d.cards[i] = base.Exp(base, big.NewInt(2), p)
+		d.encodings[string(d.cards[i].Bytes())] = i
+	}
+	return d
+}
+
+func (d *deck) decode(cards []*big.Int) ([]int, error) {
+	hand := make([]int, len(cards))
+	for i, c := range cards {
+		card, ok := d.encodings[string(c.Bytes())]
+		if !ok {
+			return nil, errors.Annotate(errors.ErrInvalidFormat, "unknown card: %w")
+		}
+		hand[i] = card
+	}
+	return hand, nil
+}
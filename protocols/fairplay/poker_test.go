@@ -0,0 +1,79 @@
+package fairplay
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	deckSize = 52
+	handSize = 5
+)
+
+func newPokerTable(t *testing.T) (*PokerDealer, *PokerPlayer) {
+	params, err := dh.GenerateParameters(128, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	dealer, err := NewPokerDealer(params, deckSize, handSize)
+	require.NoError(t, err)
+	player, err := NewPokerPlayer(params, deckSize, handSize)
+	require.NoError(t, err)
+	return dealer, player
+}
+
+func TestMentalPokerDeal(t *testing.T) {
+	dealer, player := newPokerTable(t)
+
+	deck, err := dealer.Start()
+	require.NoError(t, err)
+	deck, err = player.HandleDeck(deck)
+	require.NoError(t, err)
+	deal, err := dealer.HandleDeck(deck)
+	require.NoError(t, err)
+	unlock, playerHand, err := player.HandleDeal(deal)
+	require.NoError(t, err)
+	dealerHand, err := dealer.HandleUnlock(unlock)
+	require.NoError(t, err)
+
+	assert.Len(t, dealerHand, handSize)
+	assert.Len(t, playerHand, handSize)
+
+	seen := make(map[int]bool)
+	for _, card := range append(dealerHand, playerHand...) {
+		assert.False(t, seen[card])
+		assert.GreaterOrEqual(t, card, 0)
+		assert.Less(t, card, deckSize)
+		seen[card] = true
+	}
+}
+
+func TestMentalPokerRejectsForgedCards(t *testing.T) {
+	dealer, player := newPokerTable(t)
+
+	deck, err := dealer.Start()
+	require.NoError(t, err)
+	deck, err = player.HandleDeck(deck)
+	require.NoError(t, err)
+	deal, err := dealer.HandleDeck(deck)
+	require.NoError(t, err)
+
+	deal.PlayerCards[0] = big.NewInt(12345)
+	_, _, err = player.HandleDeal(deal)
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
+
+func TestMentalPokerParameters(t *testing.T) {
+	params, err := dh.GenerateParameters(128, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	_, err = NewPokerDealer(params, 4, 3)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+	_, err = NewPokerPlayer(nil, deckSize, handSize)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
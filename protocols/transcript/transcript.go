@@ -0,0 +1,147 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+type Entry struct {
+	Seq     int             `json:"seq"`
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func (e *Entry) Decode(v any) error {
+	if got := typeName(v); got != e.Type {
+		return errors.Annotate(errors.ErrInvalidFormat, "entry %d holds "+e.Type+", not "+got+": %w", e.Seq)
+	}
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return errors.Annotate(errors.ErrInvalidFormat, "malformed payload in entry %d: %w", e.Seq)
+	}
+	return nil
+}
+
+type Transcript struct {
+	mu       sync.Mutex
+	Protocol string  `json:"protocol"`
+	Entries  []Entry `json:"entries"`
+}
+
+func New(protocol string) *Transcript {
+	return &Transcript{Protocol: protocol}
+}
+
+func (t *Transcript) Record(from, to string, msg any) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Annotate(err, "failed to encode message: %w")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Entries = append(t.Entries, Entry{
+		Seq:     len(t.Entries),
+		From:    from,
+		To:      to,
+		Type:    typeName(msg),
+		Payload: payload,
+	})
+	return nil
+}
+
+func (t *Transcript) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.Entries)
+}
+
+func (t *Transcript) Save(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(t); err != nil {
+		return errors.Annotate(err, "failed to write transcript: %w")
+	}
+	return nil
+}
+
+func Load(r io.Reader) (*Transcript, error) {
+	t := &Transcript{}
+	if err := json.NewDecoder(r).Decode(t); err != nil {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "malformed transcript: %w")
+	}
+	for i, entry := range t.Entries {
+		if entry.Seq != i {
+			return nil, errors.Annotate(errors.ErrInvalidFormat, "entry %d is out of sequence: %w", i)
+		}
+	}
+	return t, nil
+}
+
+func (t *Transcript) WriteText(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\n", t.Protocol)
+	fmt.Fprintln(tw, "#\tfrom\tto\tmessage\tpayload")
+	for _, entry := range t.Entries {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", entry.Seq, entry.From, entry.To, entry.Type, entry.Payload)
+	}
+	return tw.Flush()
+}
+
+type Replayer struct {
+	transcript *Transcript
+	next       int
+}
+
+func NewReplayer(t *Transcript) *Replayer {
+	return &Replayer{transcript: t}
+}
+
+func (r *Replayer) Remaining() int {
+	return r.transcript.Len() - r.next
+}
+
+func (r *Replayer) Peek() (*Entry, error) {
+	r.transcript.mu.Lock()
+	defer r.transcript.mu.Unlock()
+
+	if r.next >= len(r.transcript.Entries) {
+		return nil, io.EOF
+	}
+	entry := r.transcript.Entries[r.next]
+	return &entry, nil
+}
+
+func (r *Replayer) Next(from, to string, v any) error {
+	entry, err := r.Peek()
+	if err != nil {
+		return err
+	}
+	if entry.From != from || entry.To != to {
+		return errors.Annotate(errors.ErrInvalidFormat, "entry %d was sent "+entry.From+" -> "+entry.To+": %w", entry.Seq)
+	}
+	if err := entry.Decode(v); err != nil {
+		return err
+	}
+
+	r.next++
+	return nil
+}
+
+func typeName(v any) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", v), "*")
+}
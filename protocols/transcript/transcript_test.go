@@ -0,0 +1,101 @@
+package transcript
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/protocols/fairplay"
+)
+
+func recordCoinFlip(t *testing.T) (*Transcript, byte) {
+	tr := New("coin flip")
+	alice, bob := fairplay.NewCoinFlipInitiator(), fairplay.NewCoinFlipResponder()
+
+	commit, err := alice.Start()
+	require.NoError(t, err)
+	require.NoError(t, tr.Record("alice", "bob", commit))
+
+	guess, err := bob.HandleCommit(commit)
+	require.NoError(t, err)
+	require.NoError(t, tr.Record("bob", "alice", guess))
+
+	reveal, result, err := alice.HandleGuess(guess)
+	require.NoError(t, err)
+	require.NoError(t, tr.Record("alice", "bob", reveal))
+
+	return tr, result
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	tr, _ := recordCoinFlip(t)
+	require.Equal(t, 3, tr.Len())
+	assert.Equal(t, "fairplay.CommitMessage", tr.Entries[0].Type)
+
+	var buf bytes.Buffer
+	require.NoError(t, tr.Save(&buf))
+	loaded, err := Load(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "coin flip", loaded.Protocol)
+
+	replay := NewReplayer(loaded)
+	bob := fairplay.NewCoinFlipResponder()
+
+	var commit fairplay.CommitMessage
+	require.NoError(t, replay.Next("alice", "bob", &commit))
+	_, err = bob.HandleCommit(&commit)
+	require.NoError(t, err)
+
+	var guess fairplay.GuessMessage
+	require.NoError(t, replay.Next("bob", "alice", &guess))
+
+	var reveal fairplay.RevealMessage
+	require.NoError(t, replay.Next("alice", "bob", &reveal))
+	_, err = bob.HandleReveal(&reveal)
+	require.NoError(t, err)
+
+	assert.Zero(t, replay.Remaining())
+	_, err = replay.Peek()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestReplayMismatch(t *testing.T) {
+	tr, _ := recordCoinFlip(t)
+	replay := NewReplayer(tr)
+
+	var guess fairplay.GuessMessage
+	require.ErrorIs(t, replay.Next("alice", "bob", &guess), errors.ErrInvalidFormat)
+
+	var commit fairplay.CommitMessage
+	require.ErrorIs(t, replay.Next("bob", "alice", &commit), errors.ErrInvalidFormat)
+	assert.Equal(t, 3, replay.Remaining())
+
+	require.NoError(t, replay.Next("alice", "bob", &commit))
+	assert.Equal(t, 2, replay.Remaining())
+}
+
+func TestWriteText(t *testing.T) {
+	tr, _ := recordCoinFlip(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, tr.WriteText(&buf))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "coin flip", lines[0])
+	assert.Contains(t, lines[2], "alice")
+	assert.Contains(t, lines[3], "fairplay.GuessMessage")
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load(strings.NewReader("{"))
+	require.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = Load(strings.NewReader(`{"protocol":"x","entries":[{"seq":1}]}`))
+	require.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
@@ -0,0 +1,59 @@
+package seal
+
+import (
+	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+)
+
+const KeySize = 32
+
+type KeyStore interface {
+	Current(name string) (uint32, []byte, error)
+	Key(name string, version uint32) ([]byte, error)
+}
+
+type Keyring struct {
+	mu   sync.RWMutex
+	keys map[string][][]byte
+}
+
+var _ KeyStore = (*Keyring)(nil)
+
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string][][]byte)}
+}
+
+func (k *Keyring) Rotate(name string, key []byte) (uint32, error) {
+	if len(key) != KeySize {
+		return 0, errors.ErrInvalidKeySize
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys[name] = append(k.keys[name], append([]byte{}, key...))
+	return uint32(len(k.keys[name])), nil
+}
+
+func (k *Keyring) Current(name string) (uint32, []byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	versions := k.keys[name]
+	if len(versions) == 0 {
+		return 0, nil, errors.Annotate(errors.ErrNotFound, "key %q: %w", name)
+	}
+	return uint32(len(versions)), versions[len(versions)-1], nil
+}
+
+func (k *Keyring) Key(name string, version uint32) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	versions := k.keys[name]
+	if version == 0 || int(version) > len(versions) {
+		return nil, errors.Annotate(errors.ErrNotFound, "key %q version %d: %w", name, version)
+	}
+	return versions[version-1], nil
+}
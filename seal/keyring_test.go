@@ -0,0 +1,43 @@
+package seal
+
+import (
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyringVersions(t *testing.T) {
+	keys := NewKeyring()
+
+	_, _, err := keys.Current("session")
+	assert.ErrorIs(t, err, errors.ErrNotFound)
+
+	first := make([]byte, KeySize)
+	second := make([]byte, KeySize)
+	second[0] = 1
+
+	v1, err := keys.Rotate("session", first)
+	require.NoError(t, err)
+	v2, err := keys.Rotate("session", second)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{1, 2}, []uint32{v1, v2})
+
+	version, key, err := keys.Current("session")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), version)
+	assert.Equal(t, second, key)
+
+	key, err = keys.Key("session", 1)
+	require.NoError(t, err)
+	assert.Equal(t, first, key)
+
+	_, err = keys.Key("session", 3)
+	assert.ErrorIs(t, err, errors.ErrNotFound)
+	_, err = keys.Key("session", 0)
+	assert.ErrorIs(t, err, errors.ErrNotFound)
+
+	_, err = keys.Rotate("session", make([]byte, 16))
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
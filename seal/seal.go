@@ -0,0 +1,149 @@
+package seal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"time"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/nonce"
+)
+
+const (
+	Version = 1
+
+	headerSize = 1 + 4 + 8 + 8
+)
+
+// Options configures a Sealer. Nonces, when set, supplies the nonce of
+// every token and must match the nonce size of the AEAD; a persistent
+// nonce.Counter rules out reuse under a long-lived key. Nil draws random
+// nonces.
+type Options struct {
+	AEAD   string
+	Skew   time.Duration
+	Now    func() time.Time
+	Nonces nonce.NonceSource
+}
+
+func DefaultOptions() Options {
+	return Options{AEAD: aead.ChaCha20Poly1305Name, Skew: 30 * time.Second, Now: time.Now}
+}
+
+type Sealer struct {
+	keys    KeyStore
+	options Options
+}
+
+func New(keys KeyStore, opts *Options) *Sealer {
+	options := DefaultOptions()
+	if opts != nil {
+		options = *opts
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	return &Sealer{keys: keys, options: options}
+}
+
+func (s *Sealer) Seal(ctx context.Context, keyName string, payload []byte, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", errors.Annotate(errors.ErrInvalidParameters, "ttl must be positive: %w")
+	}
+
+	version, key, err := s.keys.Current(keyName)
+	if err != nil {
+		return "", err
+	}
+	cipher, err := aead.New(s.options.AEAD, key)
+	if err != nil {
+		return "", err
+	}
+
+	now := s.options.Now()
+	header := make([]byte, 0, headerSize)
+	header = append(header, Version)
+	header = binary.BigEndian.AppendUint32(header, version)
+	header = binary.BigEndian.AppendUint64(header, uint64(now.Unix()))
+	header = binary.BigEndian.AppendUint64(header, uint64(now.Add(ttl).Unix()))
+
+	ad := additionalData(header, keyName)
+	n, err := s.nonce(cipher.NonceSize(), ad, payload)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := cipher.Seal(ctx, n, payload, ad)
+	if err != nil {
+		return "", err
+	}
+
+	token := append(header, n...)
+	return base64.RawURLEncoding.EncodeToString(append(token, sealed...)), nil
+}
+
+func (s *Sealer) Open(ctx context.Context, keyName, token string) ([]byte, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "decoding token: %w")
+	}
+	if len(raw) < headerSize || raw[0] != Version {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	header := raw[:headerSize]
+	key, err := s.keys.Key(keyName, binary.BigEndian.Uint32(header[1:]))
+	if err != nil {
+		return nil, err
+	}
+	cipher, err := aead.New(s.options.AEAD, key)
+	if err != nil {
+		return nil, err
+	}
+
+	body := raw[headerSize:]
+	if len(body) < cipher.NonceSize()+cipher.Overhead() {
+		return nil, errors.ErrInvalidFormat
+	}
+	payload, err := cipher.Open(ctx, body[:cipher.NonceSize()], body[cipher.NonceSize():], additionalData(header, keyName))
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.options.Now()
+	issued := time.Unix(int64(binary.BigEndian.Uint64(header[5:])), 0)
+	expires := time.Unix(int64(binary.BigEndian.Uint64(header[13:])), 0)
+	if !now.Before(expires) {
+		return nil, errors.ErrTokenExpired
+	}
+	if now.Add(s.options.Skew).Before(issued) {
+		return nil, errors.ErrTokenNotYetValid
+	}
+	return payload, nil
+}
+
+// nonce draws the nonce of a token. A deterministic source sees the
+// associated data as well as the payload, so the same payload sealed at
+// another time or under another key name gets a different nonce.
+func (s *Sealer) nonce(size int, additionalData, payload []byte) ([]byte, error) {
+	if s.options.Nonces == nil {
+		n := make([]byte, size)
+		if _, err := rand.Read(n); err != nil {
+			return nil, errors.Annotate(err, "generating nonce: %w")
+		}
+		return n, nil
+	}
+
+	if s.options.Nonces.NonceSize() != size {
+		return nil, errors.ErrInvalidNonceSize
+	}
+	input := binary.BigEndian.AppendUint32(nil, uint32(len(additionalData)))
+	input = append(append(input, additionalData...), payload...)
+	return s.options.Nonces.Nonce(input)
+}
+
+func additionalData(header []byte, keyName string) []byte {
+	return append(append([]byte{}, header...), keyName...)
+}
@@ -0,0 +1,191 @@
+package seal
+
+import (
+	"context"
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masterkusok/crypto/cipher/rijndael"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/nonce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newSealer(t *testing.T) (*Sealer, *Keyring, *clock) {
+	keys := NewKeyring()
+	_, err := keys.Rotate("session", make([]byte, KeySize))
+	require.NoError(t, err)
+
+	c := &clock{now: time.Unix(1_700_000_000, 0)}
+	opts := DefaultOptions()
+	opts.Now = c.Now
+	return New(keys, &opts), keys, c
+}
+
+func TestSealOpen(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newSealer(t)
+
+	token, err := s.Seal(ctx, "session", []byte(`{"user":42}`), time.Hour)
+	require.NoError(t, err)
+	assert.NotContains(t, token, "user")
+
+	payload, err := s.Open(ctx, "session", token)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"user":42}`), payload)
+}
+
+func TestOpenExpiry(t *testing.T) {
+	ctx := context.Background()
+	s, _, c := newSealer(t)
+
+	token, err := s.Seal(ctx, "session", []byte("payload"), time.Minute)
+	require.NoError(t, err)
+
+	c.now = c.now.Add(time.Minute)
+	_, err = s.Open(ctx, "session", token)
+	assert.ErrorIs(t, err, errors.ErrTokenExpired)
+
+	c.now = c.now.Add(-2 * time.Minute)
+	_, err = s.Open(ctx, "session", token)
+	assert.ErrorIs(t, err, errors.ErrTokenNotYetValid)
+
+	c.now = c.now.Add(time.Minute - time.Second)
+	_, err = s.Open(ctx, "session", token)
+	assert.NoError(t, err)
+}
+
+func TestOpenAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	s, keys, _ := newSealer(t)
+
+	old, err := s.Seal(ctx, "session", []byte("old"), time.Hour)
+	require.NoError(t, err)
+
+	key := make([]byte, KeySize)
+	key[0] = 1
+	version, err := keys.Rotate("session", key)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), version)
+
+	fresh, err := s.Seal(ctx, "session", []byte("new"), time.Hour)
+	require.NoError(t, err)
+
+	for token, want := range map[string]string{old: "old", fresh: "new"} {
+		payload, err := s.Open(ctx, "session", token)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(payload))
+	}
+}
+
+func TestOpenRejectsTampering(t *testing.T) {
+	ctx := context.Background()
+	s, keys, _ := newSealer(t)
+	_, err := keys.Rotate("csrf", make([]byte, KeySize))
+	require.NoError(t, err)
+
+	token, err := s.Seal(ctx, "session", []byte("payload"), time.Hour)
+	require.NoError(t, err)
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+
+	extended := append([]byte{}, raw...)
+	extended[headerSize-1]++
+	_, err = s.Open(ctx, "session", base64.RawURLEncoding.EncodeToString(extended))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = s.Open(ctx, "csrf", token)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	_, err = s.Open(ctx, "session", token[:10])
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = s.Open(ctx, "session", "not base64!")
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = s.Open(ctx, "unknown", token)
+	assert.ErrorIs(t, err, errors.ErrNotFound)
+}
+
+func TestSealValidation(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newSealer(t)
+
+	_, err := s.Seal(ctx, "session", nil, 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+
+	_, err = s.Seal(ctx, "unknown", nil, time.Hour)
+	assert.ErrorIs(t, err, errors.ErrNotFound)
+}
+
+func TestSealCounterNonces(t *testing.T) {
+	ctx := context.Background()
+	keys := NewKeyring()
+	_, err := keys.Rotate("session", make([]byte, KeySize))
+	require.NoError(t, err)
+
+	counter, err := nonce.NewCounter(filepath.Join(t.TempDir(), "counter"), 12)
+	require.NoError(t, err)
+	opts := DefaultOptions()
+	opts.Nonces = counter
+	s := New(keys, &opts)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := s.Seal(ctx, "session", []byte("same payload"), time.Hour)
+		require.NoError(t, err)
+		raw, err := base64.RawURLEncoding.DecodeString(token)
+		require.NoError(t, err)
+
+		n := string(raw[headerSize : headerSize+12])
+		assert.False(t, seen[n], "nonce repeated at seal %d", i)
+		seen[n] = true
+
+		payload, err := s.Open(ctx, "session", token)
+		require.NoError(t, err)
+		assert.Equal(t, "same payload", string(payload))
+	}
+
+	opts.Nonces = nonce.NewRandom(8)
+	_, err = New(keys, &opts).Seal(ctx, "session", nil, time.Hour)
+	assert.ErrorIs(t, err, errors.ErrInvalidNonceSize)
+}
+
+func TestSealDerivedNoncesBindHeader(t *testing.T) {
+	ctx := context.Background()
+	s, _, c := newSealer(t)
+
+	block, err := rijndael.NewRijndael(16, 16, 0x1B)
+	require.NoError(t, err)
+	derived, err := nonce.NewDerived(ctx, block, make([]byte, 16), 12)
+	require.NoError(t, err)
+	s.options.Nonces = derived
+
+	first, err := s.Seal(ctx, "session", []byte("same payload"), time.Hour)
+	require.NoError(t, err)
+	c.now = c.now.Add(time.Second)
+	second, err := s.Seal(ctx, "session", []byte("same payload"), time.Hour)
+	require.NoError(t, err)
+
+	nonceOf := func(token string) []byte {
+		raw, err := base64.RawURLEncoding.DecodeString(token)
+		require.NoError(t, err)
+		return raw[headerSize : headerSize+12]
+	}
+	assert.NotEqual(t, nonceOf(first), nonceOf(second))
+
+	payload, err := s.Open(ctx, "session", second)
+	require.NoError(t, err)
+	assert.Equal(t, "same payload", string(payload))
+}
@@ -0,0 +1,89 @@
+package hashsig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/blake2b"
+	"github.com/masterkusok/crypto/sign"
+)
+
+const (
+	n          = blake2b.Size256
+	digestBits = n * 8
+)
+
+func hash(parts ...[]byte) []byte {
+	return blake2b.Sum256(bytes.Join(parts, nil))
+}
+
+type LamportPublicKey struct {
+	hashes [digestBits][2][]byte
+}
+
+type LamportKey struct {
+	mu     sync.Mutex
+	secret [digestBits][2][]byte
+	public *LamportPublicKey
+	used   bool
+}
+
+var (
+	_ sign.Signer   = (*LamportKey)(nil)
+	_ sign.Verifier = (*LamportPublicKey)(nil)
+)
+
+func GenerateLamport() (*LamportKey, error) {
+	k := &LamportKey{public: &LamportPublicKey{}}
+	for i := range k.secret {
+		for b := 0; b < 2; b++ {
+			k.secret[i][b] = make([]byte, n)
+			if _, err := rand.Read(k.secret[i][b]); err != nil {
+				return nil, errors.Annotate(err, "generating secret: %w")
+			}
+			k.public.hashes[i][b] = hash(k.secret[i][b])
+		}
+	}
+	return k, nil
+}
+
+func (k *LamportKey) PublicKey() *LamportPublicKey {
+	return k.public
+}
+
+func (k *LamportKey) Sign(message []byte) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.used {
+		return nil, errors.ErrKeyExhausted
+	}
+	k.used = true
+
+	digest := hash(message)
+	signature := make([]byte, 0, digestBits*n)
+	for i := 0; i < digestBits; i++ {
+		signature = append(signature, k.secret[i][bit(digest, i)]...)
+	}
+	return signature, nil
+}
+
+func (p *LamportPublicKey) Verify(message, signature []byte) error {
+	if len(signature) != digestBits*n {
+		return errors.ErrInvalidSignature
+	}
+
+	digest := hash(message)
+	for i := 0; i < digestBits; i++ {
+		if !bytes.Equal(hash(signature[i*n:(i+1)*n]), p.hashes[i][bit(digest, i)]) {
+			return errors.ErrInvalidSignature
+		}
+	}
+	return nil
+}
+
+func bit(digest []byte, i int) int {
+	return int(digest[i/8]>>(7-i%8)) & 1
+}
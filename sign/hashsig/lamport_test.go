@@ -0,0 +1,23 @@
+package hashsig
+
+import (
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLamport(t *testing.T) {
+	key, err := GenerateLamport()
+	require.NoError(t, err)
+
+	signature, err := key.Sign([]byte("message"))
+	require.NoError(t, err)
+	assert.NoError(t, key.PublicKey().Verify([]byte("message"), signature))
+	assert.ErrorIs(t, key.PublicKey().Verify([]byte("massage"), signature), errors.ErrInvalidSignature)
+	assert.ErrorIs(t, key.PublicKey().Verify([]byte("message"), signature[1:]), errors.ErrInvalidSignature)
+
+	_, err = key.Sign([]byte("second message"))
+	assert.ErrorIs(t, err, errors.ErrKeyExhausted)
+}
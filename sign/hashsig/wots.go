@@ -0,0 +1,154 @@
+package hashsig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/sign"
+)
+
+const (
+	w        = 16
+	wotsLen1 = digestBits / 4
+	wotsLen2 = 3
+	wotsLen  = wotsLen1 + wotsLen2
+
+	WOTSSignatureSize = wotsLen * n
+)
+
+type WOTSPublicKey struct {
+	pubSeed  []byte
+	address  uint32
+	elements [][]byte
+}
+
+type WOTSKey struct {
+	mu      sync.Mutex
+	seed    []byte
+	pubSeed []byte
+	address uint32
+	used    bool
+}
+
+var (
+	_ sign.Signer   = (*WOTSKey)(nil)
+	_ sign.Verifier = (*WOTSPublicKey)(nil)
+)
+
+func GenerateWOTS() (*WOTSKey, error) {
+	seed := make([]byte, 2*n)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, errors.Annotate(err, "generating seed: %w")
+	}
+	return newWOTS(seed[:n], seed[n:], 0), nil
+}
+
+func newWOTS(seed, pubSeed []byte, address uint32) *WOTSKey {
+	return &WOTSKey{seed: seed, pubSeed: pubSeed, address: address}
+}
+
+func (k *WOTSKey) secret(i int) []byte {
+	return hash([]byte("wots-sk"), k.seed, uint32Bytes(k.address), uint32Bytes(uint32(i)))
+}
+
+func (k *WOTSKey) PublicKey() *WOTSPublicKey {
+	elements := make([][]byte, wotsLen)
+	for i := range elements {
+		elements[i] = chain(k.secret(i), 0, w-1, k.pubSeed, k.address, i)
+	}
+	return &WOTSPublicKey{pubSeed: k.pubSeed, address: k.address, elements: elements}
+}
+
+func (k *WOTSKey) Sign(message []byte) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.used {
+		return nil, errors.ErrKeyExhausted
+	}
+	k.used = true
+
+	return k.sign(message), nil
+}
+
+func (k *WOTSKey) sign(message []byte) []byte {
+	digits := messageDigits(message)
+	signature := make([]byte, 0, WOTSSignatureSize)
+	for i, d := range digits {
+		signature = append(signature, chain(k.secret(i), 0, d, k.pubSeed, k.address, i)...)
+	}
+	return signature
+}
+
+func (p *WOTSPublicKey) Verify(message, signature []byte) error {
+	elements, err := publicFromSignature(message, signature, p.pubSeed, p.address)
+	if err != nil {
+		return err
+	}
+
+	for i := range elements {
+		if !bytes.Equal(elements[i], p.elements[i]) {
+			return errors.ErrInvalidSignature
+		}
+	}
+	return nil
+}
+
+func (p *WOTSPublicKey) compress() []byte {
+	return hash(p.elements...)
+}
+
+func publicFromSignature(message, signature, pubSeed []byte, address uint32) ([][]byte, error) {
+	if len(signature) != WOTSSignatureSize {
+		return nil, errors.ErrInvalidSignature
+	}
+
+	digits := messageDigits(message)
+	elements := make([][]byte, wotsLen)
+	for i, d := range digits {
+		elements[i] = chain(signature[i*n:(i+1)*n], d, w-1-d, pubSeed, address, i)
+	}
+	return elements, nil
+}
+
+func messageDigits(message []byte) []int {
+	digest := hash(message)
+	digits := make([]int, 0, wotsLen)
+
+	checksum := 0
+	for _, b := range digest {
+		for _, d := range []int{int(b >> 4), int(b & 0x0F)} {
+			digits = append(digits, d)
+			checksum += w - 1 - d
+		}
+	}
+
+	for shift := 4 * (wotsLen2 - 1); shift >= 0; shift -= 4 {
+		digits = append(digits, (checksum>>shift)&0x0F)
+	}
+	return digits
+}
+
+func chain(x []byte, start, steps int, pubSeed []byte, address uint32, index int) []byte {
+	out := append([]byte{}, x...)
+	for j := start; j < start+steps; j++ {
+		position := uint32Bytes(address, uint32(index), uint32(j))
+		mask := hash([]byte("wots-mask"), pubSeed, position)
+		for i := range out {
+			out[i] ^= mask[i]
+		}
+		out = hash([]byte("wots-chain"), pubSeed, position, out)
+	}
+	return out
+}
+
+func uint32Bytes(values ...uint32) []byte {
+	out := make([]byte, 0, 4*len(values))
+	for _, v := range values {
+		out = binary.BigEndian.AppendUint32(out, v)
+	}
+	return out
+}
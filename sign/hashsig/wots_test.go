@@ -0,0 +1,36 @@
+package hashsig
+
+import (
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWOTS(t *testing.T) {
+	key, err := GenerateWOTS()
+	require.NoError(t, err)
+	public := key.PublicKey()
+
+	signature, err := key.Sign([]byte("message"))
+	require.NoError(t, err)
+	assert.Len(t, signature, WOTSSignatureSize)
+	assert.NoError(t, public.Verify([]byte("message"), signature))
+	assert.ErrorIs(t, public.Verify([]byte("massage"), signature), errors.ErrInvalidSignature)
+
+	_, err = key.Sign([]byte("message"))
+	assert.ErrorIs(t, err, errors.ErrKeyExhausted)
+}
+
+func TestWOTSChecksumBlocksForgery(t *testing.T) {
+	digits := messageDigits([]byte("anything"))
+	require.Len(t, digits, wotsLen)
+
+	sum := 0
+	for _, d := range digits[:wotsLen1] {
+		sum += w - 1 - d
+	}
+	checksum := digits[wotsLen1]<<8 | digits[wotsLen1+1]<<4 | digits[wotsLen1+2]
+	assert.Equal(t, sum, checksum)
+}
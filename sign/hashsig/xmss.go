@@ -0,0 +1,140 @@
+package hashsig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/blake2b"
+	"github.com/masterkusok/crypto/hash/merkle"
+	"github.com/masterkusok/crypto/nonce"
+	"github.com/masterkusok/crypto/sign"
+)
+
+const (
+	SeedSize  = 2 * n
+	MaxHeight = 20
+
+	indexSize = 4
+)
+
+type XMSSPublicKey struct {
+	Root    []byte
+	PubSeed []byte
+	Height  int
+}
+
+type XMSS struct {
+	mu      sync.Mutex
+	seed    []byte
+	pubSeed []byte
+	height  int
+	tree    *merkle.Tree
+	state   *nonce.Counter
+}
+
+var (
+	_ sign.Signer   = (*XMSS)(nil)
+	_ sign.Verifier = (*XMSSPublicKey)(nil)
+)
+
+func NewXMSS(seed []byte, height int, statePath string) (*XMSS, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	if height < 1 || height > MaxHeight {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	state, err := nonce.NewCounter(statePath, indexSize)
+	if err != nil {
+		return nil, errors.Annotate(err, "loading signature state: %w")
+	}
+
+	x := &XMSS{
+		seed:    append([]byte{}, seed[:n]...),
+		pubSeed: append([]byte{}, seed[n:]...),
+		height:  height,
+		state:   state,
+	}
+
+	leaves := make([][]byte, 1<<height)
+	for i := range leaves {
+		leaves[i] = x.wots(uint32(i)).PublicKey().compress()
+	}
+	if x.tree, err = merkle.New(leaves, blake2b.Sum256); err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (x *XMSS) wots(index uint32) *WOTSKey {
+	return newWOTS(x.seed, x.pubSeed, index)
+}
+
+func (x *XMSS) PublicKey() *XMSSPublicKey {
+	return &XMSSPublicKey{Root: x.tree.Root(), PubSeed: x.pubSeed, Height: x.height}
+}
+
+func (x *XMSS) Remaining() int {
+	used := int(binary.BigEndian.Uint32(x.state.Current()))
+	return max((1<<x.height)-used, 0)
+}
+
+func (x *XMSS) Sign(message []byte) ([]byte, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.Remaining() == 0 {
+		return nil, errors.ErrKeyExhausted
+	}
+
+	next, err := x.state.Nonce(nil)
+	if err != nil {
+		return nil, errors.Annotate(err, "advancing signature state: %w")
+	}
+	index := binary.BigEndian.Uint32(next) - 1
+
+	path, err := x.tree.AuthPath(int(index))
+	if err != nil {
+		return nil, err
+	}
+
+	signature := binary.BigEndian.AppendUint32(nil, index)
+	signature = append(signature, x.wots(index).sign(message)...)
+	for _, node := range path {
+		signature = append(signature, node...)
+	}
+	return signature, nil
+}
+
+func (p *XMSSPublicKey) Verify(message, signature []byte) error {
+	if len(signature) != indexSize+WOTSSignatureSize+p.Height*n {
+		return errors.ErrInvalidSignature
+	}
+
+	index := binary.BigEndian.Uint32(signature)
+	if index >= 1<<p.Height {
+		return errors.ErrInvalidSignature
+	}
+
+	wotsSignature := signature[indexSize : indexSize+WOTSSignatureSize]
+	elements, err := publicFromSignature(message, wotsSignature, p.PubSeed, index)
+	if err != nil {
+		return err
+	}
+	leaf := (&WOTSPublicKey{elements: elements}).compress()
+
+	rest := signature[indexSize+WOTSSignatureSize:]
+	path := make([][]byte, p.Height)
+	for i := range path {
+		path[i] = rest[i*n : (i+1)*n]
+	}
+
+	if !bytes.Equal(merkle.RootFromPath(blake2b.Sum256, leaf, int(index), path), p.Root) {
+		return errors.ErrInvalidSignature
+	}
+	return nil
+}
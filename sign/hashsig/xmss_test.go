@@ -0,0 +1,65 @@
+package hashsig
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXMSSSignAndVerify(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, SeedSize)
+	x, err := NewXMSS(seed, 2, "")
+	require.NoError(t, err)
+	public := x.PublicKey()
+	assert.Equal(t, 4, x.Remaining())
+
+	var signatures [][]byte
+	for i := 0; i < 4; i++ {
+		signature, err := x.Sign([]byte("message"))
+		require.NoError(t, err)
+		assert.NoError(t, public.Verify([]byte("message"), signature))
+		signatures = append(signatures, signature)
+	}
+	assert.NotEqual(t, signatures[0], signatures[1])
+	assert.Equal(t, 0, x.Remaining())
+
+	_, err = x.Sign([]byte("message"))
+	assert.ErrorIs(t, err, errors.ErrKeyExhausted)
+
+	assert.ErrorIs(t, public.Verify([]byte("other"), signatures[0]), errors.ErrInvalidSignature)
+
+	tampered := append([]byte{}, signatures[2]...)
+	tampered[3] = 1
+	assert.ErrorIs(t, public.Verify([]byte("message"), tampered), errors.ErrInvalidSignature)
+}
+
+func TestXMSSStatePersists(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x07}, SeedSize)
+	path := filepath.Join(t.TempDir(), "xmss.state")
+
+	first, err := NewXMSS(seed, 2, path)
+	require.NoError(t, err)
+	signature, err := first.Sign([]byte("one"))
+	require.NoError(t, err)
+
+	reopened, err := NewXMSS(seed, 2, path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reopened.Remaining())
+	assert.Equal(t, first.PublicKey(), reopened.PublicKey())
+
+	next, err := reopened.Sign([]byte("two"))
+	require.NoError(t, err)
+	assert.NotEqual(t, signature[:indexSize], next[:indexSize])
+	assert.NoError(t, reopened.PublicKey().Verify([]byte("two"), next))
+}
+
+func TestXMSSInvalidParameters(t *testing.T) {
+	_, err := NewXMSS(make([]byte, 10), 2, "")
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+	_, err = NewXMSS(make([]byte, SeedSize), 0, "")
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
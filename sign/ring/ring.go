@@ -0,0 +1,274 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	"github.com/masterkusok/crypto/hash/blake2b"
+	"github.com/masterkusok/crypto/sign"
+	"github.com/masterkusok/crypto/vss"
+)
+
+var one = big.NewInt(1)
+
+type PublicKey struct {
+	Y *big.Int
+}
+
+type PrivateKey struct {
+	PublicKey
+	X *big.Int
+}
+
+type Ring struct {
+	group    *vss.Group
+	keys     []*PublicKey
+	linkable bool
+	encoded  []byte
+	base     *big.Int
+}
+
+type Signer struct {
+	ring  *Ring
+	key   *PrivateKey
+	index int
+}
+
+var (
+	_ sign.Signer   = (*Signer)(nil)
+	_ sign.Verifier = (*Ring)(nil)
+)
+
+func GenerateKey(params *dh.Parameters) (*PrivateKey, error) {
+	group, err := vss.NewGroup(params)
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := randomScalar(group)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{PublicKey: PublicKey{Y: group.Exp(x)}, X: x}, nil
+}
+
+func NewRing(params *dh.Parameters, keys []*PublicKey, linkable bool) (*Ring, error) {
+	group, err := vss.NewGroup(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) < 2 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "ring needs at least two members: %w")
+	}
+
+	r := &Ring{group: group, keys: keys, linkable: linkable}
+	for _, key := range keys {
+		if key == nil || key.Y == nil || key.Y.Cmp(one) <= 0 || key.Y.Cmp(group.P) >= 0 {
+			return nil, errors.ErrInvalidPublicKey
+		}
+		r.encoded = append(r.encoded, r.element(key.Y)...)
+	}
+	r.base = r.hashToGroup()
+
+	return r, nil
+}
+
+func (r *Ring) Size() int {
+	return len(r.keys)
+}
+
+func (r *Ring) Signer(key *PrivateKey) (*Signer, error) {
+	for i, member := range r.keys {
+		if member.Y.Cmp(key.Y) == 0 {
+			return &Signer{ring: r, key: key, index: i}, nil
+		}
+	}
+	return nil, errors.Annotate(errors.ErrInvalidPrivateKey, "key is not a ring member: %w")
+}
+
+func (s *Signer) Sign(message []byte) ([]byte, error) {
+	r := s.ring
+	n := len(r.keys)
+
+	var image *big.Int
+	if r.linkable {
+		image = new(big.Int).Exp(r.base, s.key.X, r.group.P)
+	}
+
+	u, err := randomScalar(r.group)
+	if err != nil {
+		return nil, err
+	}
+
+	challenges := make([]*big.Int, n)
+	responses := make([]*big.Int, n)
+	var imageCommitment *big.Int
+	if r.linkable {
+		imageCommitment = new(big.Int).Exp(r.base, u, r.group.P)
+	}
+	challenges[(s.index+1)%n] = r.challenge(message, image, r.group.Exp(u), imageCommitment)
+
+	for k := 1; k < n; k++ {
+		i := (s.index + k) % n
+		if responses[i], err = randomScalar(r.group); err != nil {
+			return nil, err
+		}
+		a, b := r.commitments(i, challenges[i], responses[i], image)
+		challenges[(i+1)%n] = r.challenge(message, image, a, b)
+	}
+
+	responses[s.index] = new(big.Int).Mul(s.key.X, challenges[s.index])
+	responses[s.index].Sub(u, responses[s.index])
+	responses[s.index].Mod(responses[s.index], r.group.Q)
+
+	signature := r.element(challenges[0])
+	for _, response := range responses {
+		signature = append(signature, r.element(response)...)
+	}
+	if r.linkable {
+		signature = append(signature, r.element(image)...)
+	}
+	return signature, nil
+}
+
+func (r *Ring) Verify(message, signature []byte) error {
+	c0, responses, image, err := r.decode(signature)
+	if err != nil {
+		return err
+	}
+
+	c := c0
+	for i := range r.keys {
+		a, b := r.commitments(i, c, responses[i], image)
+		c = r.challenge(message, image, a, b)
+	}
+
+	if c.Cmp(c0) != 0 {
+		return errors.ErrInvalidSignature
+	}
+	return nil
+}
+
+func (r *Ring) KeyImage(signature []byte) ([]byte, error) {
+	if !r.linkable {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "ring is not linkable: %w")
+	}
+
+	_, _, image, err := r.decode(signature)
+	if err != nil {
+		return nil, err
+	}
+	return r.element(image), nil
+}
+
+func (r *Ring) Linked(first, second []byte) (bool, error) {
+	a, err := r.KeyImage(first)
+	if err != nil {
+		return false, err
+	}
+	b, err := r.KeyImage(second)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(a, b), nil
+}
+
+func (r *Ring) commitments(i int, c, s, image *big.Int) (*big.Int, *big.Int) {
+	p := r.group.P
+
+	a := r.group.Exp(s)
+	a.Mul(a, new(big.Int).Exp(r.keys[i].Y, c, p))
+	a.Mod(a, p)
+
+	if image == nil {
+		return a, nil
+	}
+
+	b := new(big.Int).Exp(r.base, s, p)
+	b.Mul(b, new(big.Int).Exp(image, c, p))
+	b.Mod(b, p)
+	return a, b
+}
+
+func (r *Ring) challenge(message []byte, image, a, b *big.Int) *big.Int {
+	data := append([]byte("ring-challenge"), r.encoded...)
+	for _, v := range []*big.Int{image, a, b} {
+		if v != nil {
+			data = append(data, r.element(v)...)
+		}
+	}
+	data = append(data, message...)
+
+	return r.hashToScalar(data)
+}
+
+func (r *Ring) hashToScalar(data []byte) *big.Int {
+	var digest []byte
+	for counter := uint32(0); len(digest)*8 < r.group.Q.BitLen()+128; counter++ {
+		digest = append(digest, blake2b.Sum512(binary.BigEndian.AppendUint32(append([]byte{}, data...), counter))...)
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(digest), r.group.Q)
+}
+
+func (r *Ring) hashToGroup() *big.Int {
+	data := append([]byte("ring-base"), r.encoded...)
+	for {
+		t := r.hashToScalar(data)
+		h := new(big.Int).Exp(t, big.NewInt(2), r.group.P)
+		if h.Cmp(one) > 0 {
+			return h
+		}
+		data = append(data, 0)
+	}
+}
+
+func (r *Ring) decode(signature []byte) (*big.Int, []*big.Int, *big.Int, error) {
+	size := r.elementSize()
+	count := len(r.keys) + 1
+	if r.linkable {
+		count++
+	}
+	if len(signature) != count*size {
+		return nil, nil, nil, errors.ErrInvalidSignature
+	}
+
+	values := make([]*big.Int, count)
+	for i := range values {
+		values[i] = new(big.Int).SetBytes(signature[i*size : (i+1)*size])
+	}
+
+	for _, v := range values[:len(r.keys)+1] {
+		if v.Cmp(r.group.Q) >= 0 {
+			return nil, nil, nil, errors.ErrInvalidSignature
+		}
+	}
+
+	var image *big.Int
+	if r.linkable {
+		image = values[count-1]
+		if image.Cmp(one) <= 0 || image.Cmp(r.group.P) >= 0 || new(big.Int).Exp(image, r.group.Q, r.group.P).Cmp(one) != 0 {
+			return nil, nil, nil, errors.ErrInvalidSignature
+		}
+	}
+	return values[0], values[1 : len(r.keys)+1], image, nil
+}
+
+func (r *Ring) elementSize() int {
+	return (r.group.P.BitLen() + 7) / 8
+}
+
+func (r *Ring) element(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, r.elementSize()))
+}
+
+func randomScalar(group *vss.Group) (*big.Int, error) {
+	x, err := rand.Int(rand.Reader, new(big.Int).Sub(group.Q, one))
+	if err != nil {
+		return nil, errors.Annotate(err, "generating scalar: %w")
+	}
+	return x.Add(x, one), nil
+}
@@ -0,0 +1,92 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMembers(t *testing.T, n int) (*dh.Parameters, []*PrivateKey, []*PublicKey) {
+	params, err := dh.GenerateParameters(128, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+
+	keys := make([]*PrivateKey, n)
+	public := make([]*PublicKey, n)
+	for i := range keys {
+		keys[i], err = GenerateKey(params)
+		require.NoError(t, err)
+		public[i] = &keys[i].PublicKey
+	}
+	return params, keys, public
+}
+
+func TestSignVerify(t *testing.T) {
+	params, keys, public := newMembers(t, 4)
+
+	for _, linkable := range []bool{false, true} {
+		r, err := NewRing(params, public, linkable)
+		require.NoError(t, err)
+
+		for _, key := range keys {
+			signer, err := r.Signer(key)
+			require.NoError(t, err)
+
+			signature, err := signer.Sign([]byte("ring message"))
+			require.NoError(t, err)
+			require.NoError(t, r.Verify([]byte("ring message"), signature))
+			assert.ErrorIs(t, r.Verify([]byte("other message"), signature), errors.ErrInvalidSignature)
+
+			signature[len(signature)/2] ^= 1
+			assert.ErrorIs(t, r.Verify([]byte("ring message"), signature), errors.ErrInvalidSignature)
+		}
+	}
+}
+
+func TestLinkability(t *testing.T) {
+	params, keys, public := newMembers(t, 3)
+	r, err := NewRing(params, public, true)
+	require.NoError(t, err)
+
+	first, err := r.Signer(keys[0])
+	require.NoError(t, err)
+	second, err := r.Signer(keys[1])
+	require.NoError(t, err)
+
+	a, err := first.Sign([]byte("vote A"))
+	require.NoError(t, err)
+	b, err := first.Sign([]byte("vote B"))
+	require.NoError(t, err)
+	c, err := second.Sign([]byte("vote A"))
+	require.NoError(t, err)
+
+	linked, err := r.Linked(a, b)
+	require.NoError(t, err)
+	assert.True(t, linked)
+
+	linked, err = r.Linked(a, c)
+	require.NoError(t, err)
+	assert.False(t, linked)
+
+	unlinkable, err := NewRing(params, public, false)
+	require.NoError(t, err)
+	_, err = unlinkable.KeyImage(a)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
+
+func TestRingMembership(t *testing.T) {
+	params, _, public := newMembers(t, 2)
+	outsider, err := GenerateKey(params)
+	require.NoError(t, err)
+
+	r, err := NewRing(params, public, false)
+	require.NoError(t, err)
+	_, err = r.Signer(outsider)
+	assert.ErrorIs(t, err, errors.ErrInvalidPrivateKey)
+
+	_, err = NewRing(params, public[:1], false)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
@@ -0,0 +1,9 @@
+package sign
+
+type Signer interface {
+	Sign(message []byte) ([]byte, error)
+}
+
+type Verifier interface {
+	Verify(message, signature []byte) error
+}
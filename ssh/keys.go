@@ -0,0 +1,150 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	KeyAlgoRSA     = "ssh-rsa"
+	KeyAlgoED25519 = "ssh-ed25519"
+)
+
+func MarshalPublicKey(key any) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		out := appendString(nil, []byte(KeyAlgoRSA))
+		out = appendMPInt(out, k.E)
+		return appendMPInt(out, k.N), nil
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return nil, errors.ErrInvalidPublicKey
+		}
+		out := appendString(nil, []byte(KeyAlgoED25519))
+		return appendString(out, k), nil
+	default:
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "unsupported key type %T: %w", key)
+	}
+}
+
+func ParsePublicKey(blob []byte) (any, error) {
+	r := &reader{data: blob}
+	algorithm := string(r.string())
+
+	var key any
+	switch algorithm {
+	case KeyAlgoRSA:
+		e, n := r.mpint(), r.mpint()
+		key = &rsa.PublicKey{N: n, E: e}
+	case KeyAlgoED25519:
+		pub := r.string()
+		if r.err == nil && len(pub) != ed25519.PublicKeySize {
+			return nil, errors.ErrInvalidPublicKey
+		}
+		key = ed25519.PublicKey(append([]byte{}, pub...))
+	default:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", algorithm)
+	}
+
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func MarshalAuthorizedKey(key any, comment string) ([]byte, error) {
+	blob, err := MarshalPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.Write(blob[4 : 4+binary.BigEndian.Uint32(blob)])
+	out.WriteByte(' ')
+	out.WriteString(base64.StdEncoding.EncodeToString(blob))
+	if comment != "" {
+		out.WriteByte(' ')
+		out.WriteString(comment)
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func ParseAuthorizedKey(line []byte) (any, string, error) {
+	fields := bytes.Fields(line)
+	if len(fields) < 2 {
+		return nil, "", errors.ErrInvalidFormat
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(string(fields[1]))
+	if err != nil {
+		return nil, "", errors.Annotate(errors.ErrInvalidFormat, "decoding key: %w")
+	}
+
+	key, err := ParsePublicKey(blob)
+	if err != nil {
+		return nil, "", err
+	}
+	if algorithm := string(blob[4 : 4+binary.BigEndian.Uint32(blob)]); algorithm != string(fields[0]) {
+		return nil, "", errors.Annotate(errors.ErrInvalidFormat, "key type mismatch: %w")
+	}
+
+	return key, string(bytes.Join(fields[2:], []byte(" "))), nil
+}
+
+func appendString(out, s []byte) []byte {
+	out = binary.BigEndian.AppendUint32(out, uint32(len(s)))
+	return append(out, s...)
+}
+
+func appendMPInt(out []byte, n *big.Int) []byte {
+	b := n.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return appendString(out, b)
+}
+
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) string() []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < 4 || uint64(binary.BigEndian.Uint32(r.data)) > uint64(len(r.data)-4) {
+		r.err = errors.ErrInvalidFormat
+		return nil
+	}
+
+	size := binary.BigEndian.Uint32(r.data)
+	s := r.data[4 : 4+size]
+	r.data = r.data[4+size:]
+	return s
+}
+
+func (r *reader) mpint() *big.Int {
+	b := r.string()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		r.err = errors.Annotate(errors.ErrInvalidFormat, "negative mpint: %w")
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+func (r *reader) done() error {
+	if r.err == nil && len(r.data) != 0 {
+		return errors.Annotate(errors.ErrInvalidFormat, "trailing data: %w")
+	}
+	return r.err
+}
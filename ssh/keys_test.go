@@ -0,0 +1,63 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureAuthorizedKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAINJJHaq+uiBDYTwBDIS5FAKBNLRp8vlfMatrIWQoK3T/ fixture\n"
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	r := rsa.NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 512)
+	require.NoError(t, r.GenerateKeyPair())
+	return r.GetPrivateKey()
+}
+
+func TestAuthorizedKeyFixture(t *testing.T) {
+	key, comment, err := ParseAuthorizedKey([]byte(fixtureAuthorizedKey))
+	require.NoError(t, err)
+	assert.Equal(t, "fixture", comment)
+	assert.IsType(t, ed25519.PublicKey{}, key)
+
+	line, err := MarshalAuthorizedKey(key, comment)
+	require.NoError(t, err)
+	assert.Equal(t, fixtureAuthorizedKey, string(line))
+}
+
+func TestRSAAuthorizedKeyRoundTrip(t *testing.T) {
+	priv := newRSAKey(t)
+
+	line, err := MarshalAuthorizedKey(&priv.PublicKey, "user@host")
+	require.NoError(t, err)
+	assert.Contains(t, string(line), "ssh-rsa AAAAB3NzaC1yc2E")
+
+	key, comment, err := ParseAuthorizedKey(line)
+	require.NoError(t, err)
+	assert.Equal(t, "user@host", comment)
+	assert.Equal(t, 0, key.(*rsa.PublicKey).N.Cmp(priv.N))
+	assert.Equal(t, 0, key.(*rsa.PublicKey).E.Cmp(priv.E))
+}
+
+func TestParseInvalidKeys(t *testing.T) {
+	_, _, err := ParseAuthorizedKey([]byte("ssh-rsa"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, _, err = ParseAuthorizedKey([]byte("ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAINJJHaq+uiBDYTwBDIS5FAKBNLRp8vlfMatrIWQoK3T/"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = ParsePublicKey(appendString(nil, []byte("ecdsa-sha2-nistp256")))
+	assert.ErrorIs(t, err, errors.ErrUnknownAlgorithm)
+
+	blob, err := MarshalPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	require.NoError(t, err)
+	_, err = ParsePublicKey(blob[:len(blob)-1])
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+	_, err = ParsePublicKey(append(blob, 0))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+}
@@ -0,0 +1,207 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"strings"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	SigAlgoRSASHA256 = "rsa-sha2-256"
+	SigAlgoRSASHA512 = "rsa-sha2-512"
+
+	sshsigVersion = 1
+	sshsigHash    = "sha512"
+	armorBegin    = "-----BEGIN SSH SIGNATURE-----"
+	armorEnd      = "-----END SSH SIGNATURE-----"
+	armorWidth    = 70
+)
+
+var sshsigMagic = []byte("SSHSIG")
+
+type Signature struct {
+	Format string
+	Blob   []byte
+}
+
+func MarshalSignature(sig *Signature) []byte {
+	out := appendString(nil, []byte(sig.Format))
+	return appendString(out, sig.Blob)
+}
+
+func ParseSignature(data []byte) (*Signature, error) {
+	r := &reader{data: data}
+	sig := &Signature{Format: string(r.string()), Blob: r.string()}
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+func Sign(key any, data []byte) (*Signature, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		digest := sha512.Sum512(data)
+		blob, err := rsa.SignPKCS1v15(k, crypto.SHA512, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		return &Signature{Format: SigAlgoRSASHA512, Blob: blob}, nil
+	case ed25519.PrivateKey:
+		return &Signature{Format: KeyAlgoED25519, Blob: ed25519.Sign(k, data)}, nil
+	default:
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "unsupported key type %T: %w", key)
+	}
+}
+
+func Verify(key any, data []byte, sig *Signature) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		switch sig.Format {
+		case SigAlgoRSASHA256:
+			digest := sha256.Sum256(data)
+			return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig.Blob)
+		case SigAlgoRSASHA512:
+			digest := sha512.Sum512(data)
+			return rsa.VerifyPKCS1v15(k, crypto.SHA512, digest[:], sig.Blob)
+		}
+	case ed25519.PublicKey:
+		if sig.Format == KeyAlgoED25519 {
+			if !ed25519.Verify(k, data, sig.Blob) {
+				return errors.ErrInvalidSignature
+			}
+			return nil
+		}
+	default:
+		return errors.Annotate(errors.ErrUnknownAlgorithm, "unsupported key type %T: %w", key)
+	}
+	return errors.Annotate(errors.ErrUnknownAlgorithm, "signature format %s: %w", sig.Format)
+}
+
+func SignMessage(key any, namespace string, message []byte) ([]byte, error) {
+	if namespace == "" {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "empty namespace: %w")
+	}
+
+	pub, err := publicKeyOf(key)
+	if err != nil {
+		return nil, err
+	}
+	pubBlob, err := MarshalPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := Sign(key, signedData(namespace, message))
+	if err != nil {
+		return nil, err
+	}
+
+	blob := append([]byte{}, sshsigMagic...)
+	blob = append(blob, 0, 0, 0, sshsigVersion)
+	blob = appendString(blob, pubBlob)
+	blob = appendString(blob, []byte(namespace))
+	blob = appendString(blob, nil)
+	blob = appendString(blob, []byte(sshsigHash))
+	blob = appendString(blob, MarshalSignature(sig))
+	return armor(blob), nil
+}
+
+func VerifyMessage(key any, namespace string, message, armored []byte) error {
+	blob, err := dearmor(armored)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(blob, sshsigMagic) || len(blob) < len(sshsigMagic)+4 {
+		return errors.ErrInvalidFormat
+	}
+
+	version := blob[len(sshsigMagic) : len(sshsigMagic)+4]
+	if !bytes.Equal(version, []byte{0, 0, 0, sshsigVersion}) {
+		return errors.Annotate(errors.ErrInvalidFormat, "unsupported version: %w")
+	}
+
+	r := &reader{data: blob[len(sshsigMagic)+4:]}
+	pubBlob, signedNamespace := r.string(), string(r.string())
+	r.string()
+	hashAlgorithm, sigBlob := string(r.string()), r.string()
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	expected, err := MarshalPublicKey(key)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(pubBlob, expected) {
+		return errors.Annotate(errors.ErrInvalidSignature, "signed by a different key: %w")
+	}
+	if signedNamespace != namespace {
+		return errors.Annotate(errors.ErrInvalidSignature, "namespace mismatch: %w")
+	}
+	if hashAlgorithm != sshsigHash {
+		return errors.Annotate(errors.ErrUnknownAlgorithm, "%s: %w", hashAlgorithm)
+	}
+
+	sig, err := ParseSignature(sigBlob)
+	if err != nil {
+		return err
+	}
+	return Verify(key, signedData(namespace, message), sig)
+}
+
+func signedData(namespace string, message []byte) []byte {
+	digest := sha512.Sum512(message)
+
+	out := append([]byte{}, sshsigMagic...)
+	out = appendString(out, []byte(namespace))
+	out = appendString(out, nil)
+	out = appendString(out, []byte(sshsigHash))
+	return appendString(out, digest[:])
+}
+
+func publicKeyOf(key any) (any, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey, nil
+	case ed25519.PrivateKey:
+		return k.Public(), nil
+	default:
+		return nil, errors.Annotate(errors.ErrUnknownAlgorithm, "unsupported key type %T: %w", key)
+	}
+}
+
+func armor(blob []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+
+	var out strings.Builder
+	out.WriteString(armorBegin + "\n")
+	for len(encoded) > 0 {
+		n := min(armorWidth, len(encoded))
+		out.WriteString(encoded[:n] + "\n")
+		encoded = encoded[n:]
+	}
+	out.WriteString(armorEnd + "\n")
+	return []byte(out.String())
+}
+
+func dearmor(armored []byte) ([]byte, error) {
+	text := strings.TrimSpace(string(armored))
+	if !strings.HasPrefix(text, armorBegin) || !strings.HasSuffix(text, armorEnd) {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	body := strings.Join(strings.Fields(text[len(armorBegin):len(text)-len(armorEnd)]), "")
+	blob, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, errors.Annotate(errors.ErrInvalidFormat, "decoding signature: %w")
+	}
+	return blob, nil
+}
@@ -0,0 +1,68 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureSignature = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAg0kkdqr66IENhPAEMhLkUAoE0tG
+ny+V8xq2shZCgrdP8AAAAEZmlsZQAAAAAAAAAGc2hhNTEyAAAAUwAAAAtzc2gtZWQyNTUx
+OQAAAEBHD52+yt9rv+H4ug05stk1hIgJVIEVZfkPNtBN1WuLAj0Y9J/owyO364d0F78jTU
+w2LEIPRQlBWRWORtVRKPoM
+-----END SSH SIGNATURE-----
+`
+
+func TestVerifySSHKeygenSignature(t *testing.T) {
+	key, _, err := ParseAuthorizedKey([]byte(fixtureAuthorizedKey))
+	require.NoError(t, err)
+
+	message := []byte("hello ssh\n")
+	require.NoError(t, VerifyMessage(key, "file", message, []byte(fixtureSignature)))
+	assert.ErrorIs(t, VerifyMessage(key, "git", message, []byte(fixtureSignature)), errors.ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyMessage(key, "file", []byte("hello ssh"), []byte(fixtureSignature)), errors.ErrInvalidSignature)
+}
+
+func TestSignMessageRoundTrip(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	rsaKey := newRSAKey(t)
+
+	for _, priv := range []any{edKey, rsaKey} {
+		pub, err := publicKeyOf(priv)
+		require.NoError(t, err)
+
+		armored, err := SignMessage(priv, "file", []byte("message"))
+		require.NoError(t, err)
+		assert.Contains(t, string(armored), armorBegin)
+
+		require.NoError(t, VerifyMessage(pub, "file", []byte("message"), armored))
+		assert.ErrorIs(t, VerifyMessage(pub, "file", []byte("massage"), armored), errors.ErrInvalidSignature)
+	}
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	armored, err := SignMessage(edKey, "file", []byte("message"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, VerifyMessage(otherPub, "file", []byte("message"), armored), errors.ErrInvalidSignature)
+}
+
+func TestSignatureBlobRoundTrip(t *testing.T) {
+	priv := newRSAKey(t)
+
+	sig, err := Sign(priv, []byte("data"))
+	require.NoError(t, err)
+	assert.Equal(t, SigAlgoRSASHA512, sig.Format)
+
+	parsed, err := ParseSignature(MarshalSignature(sig))
+	require.NoError(t, err)
+	assert.Equal(t, sig, parsed)
+	require.NoError(t, Verify(&priv.PublicKey, []byte("data"), parsed))
+
+	parsed.Format = "ssh-dss"
+	assert.ErrorIs(t, Verify(&priv.PublicKey, []byte("data"), parsed), errors.ErrUnknownAlgorithm)
+}
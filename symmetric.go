@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const (
+	KeySize = 32
+
+	symmetricVersion byte = 1
+	symmetricAEAD         = aead.AESGCMName
+)
+
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Annotate(err, "generating key: %w")
+	}
+	return key, nil
+}
+
+// Encrypt seals plaintext under a KeySize key as version | nonce | ciphertext.
+// The version byte is authenticated along with additionalData.
+func Encrypt(ctx context.Context, key, plaintext, additionalData []byte) ([]byte, error) {
+	cipher, err := symmetricCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 1+cipher.NonceSize())
+	out[0] = symmetricVersion
+	nonce := out[1:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Annotate(err, "generating nonce: %w")
+	}
+
+	sealed, err := cipher.Seal(ctx, nonce, plaintext, symmetricAD(out[0], additionalData))
+	if err != nil {
+		return nil, err
+	}
+	return append(out, sealed...), nil
+}
+
+func Decrypt(ctx context.Context, key, ciphertext, additionalData []byte) ([]byte, error) {
+	cipher, err := symmetricCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	headerLen := 1 + cipher.NonceSize()
+	if len(ciphertext) < headerLen+cipher.Overhead() || ciphertext[0] != symmetricVersion {
+		return nil, errors.ErrInvalidFormat
+	}
+
+	return cipher.Open(ctx, ciphertext[1:headerLen], ciphertext[headerLen:], symmetricAD(ciphertext[0], additionalData))
+}
+
+func symmetricCipher(key []byte) (aead.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	return aead.New(symmetricAEAD, key)
+}
+
+func symmetricAD(version byte, additionalData []byte) []byte {
+	return append([]byte{version}, additionalData...)
+}
@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/masterkusok/crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	require.Len(t, key, KeySize)
+
+	plaintext := []byte("attack at dawn")
+	ad := []byte("header")
+
+	first, err := Encrypt(ctx, key, plaintext, ad)
+	require.NoError(t, err)
+	second, err := Encrypt(ctx, key, plaintext, ad)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, symmetricVersion, first[0])
+
+	got, err := Decrypt(ctx, key, first, ad)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	empty, err := Encrypt(ctx, key, nil, nil)
+	require.NoError(t, err)
+	got, err = Decrypt(ctx, key, empty, nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	ctx := context.Background()
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	other, err := GenerateKey()
+	require.NoError(t, err)
+
+	sealed, err := Encrypt(ctx, key, []byte("payload"), []byte("ad"))
+	require.NoError(t, err)
+
+	_, err = Decrypt(ctx, other, sealed, []byte("ad"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+	_, err = Decrypt(ctx, key, sealed, []byte("other ad"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	flipped := append([]byte{}, sealed...)
+	flipped[len(flipped)-1] ^= 1
+	_, err = Decrypt(ctx, key, flipped, []byte("ad"))
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+
+	versioned := append([]byte{}, sealed...)
+	versioned[0] = 2
+	_, err = Decrypt(ctx, key, versioned, []byte("ad"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+
+	_, err = Decrypt(ctx, key, sealed[:8], []byte("ad"))
+	assert.ErrorIs(t, err, errors.ErrInvalidFormat)
+	_, err = Encrypt(ctx, key[:16], []byte("payload"), nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidKeySize)
+}
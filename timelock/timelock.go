@@ -0,0 +1,159 @@
+package timelock
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"time"
+
+	"github.com/masterkusok/crypto/aead"
+	"github.com/masterkusok/crypto/cipher/chacha20"
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+)
+
+const checkInterval = 1024
+
+var (
+	one = big.NewInt(1)
+	two = big.NewInt(2)
+)
+
+type Puzzle struct {
+	N            *big.Int
+	A            *big.Int
+	Squarings    uint64
+	EncryptedKey *big.Int
+	Ciphertext   []byte
+}
+
+func Create(ctx context.Context, key *rsa.PrivateKey, message []byte, squarings uint64) (*Puzzle, error) {
+	if key == nil || key.P == nil || key.Q == nil {
+		return nil, errors.ErrInvalidPrivateKey
+	}
+	if key.N.BitLen() <= 8*chacha20.KeySize {
+		return nil, errors.ErrInvalidKeySize
+	}
+	if squarings == 0 {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	phi := new(big.Int).Mul(new(big.Int).Sub(key.P, one), new(big.Int).Sub(key.Q, one))
+	for _, extra := range key.Extra {
+		phi.Mul(phi, new(big.Int).Sub(extra.Prime, one))
+	}
+	exponent := new(big.Int).Exp(two, new(big.Int).SetUint64(squarings), phi)
+
+	a, err := rand.Int(rand.Reader, new(big.Int).Sub(key.N, two))
+	if err != nil {
+		return nil, errors.Annotate(err, "generating base: %w")
+	}
+	a.Add(a, two)
+
+	secret := make([]byte, chacha20.KeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Annotate(err, "generating key: %w")
+	}
+
+	ciphertext, err := seal(ctx, secret, message)
+	if err != nil {
+		return nil, err
+	}
+
+	b := new(big.Int).Exp(a, exponent, key.N)
+	encryptedKey := new(big.Int).Add(new(big.Int).SetBytes(secret), b)
+	encryptedKey.Mod(encryptedKey, key.N)
+
+	return &Puzzle{
+		N:            new(big.Int).Set(key.N),
+		A:            a,
+		Squarings:    squarings,
+		EncryptedKey: encryptedKey,
+		Ciphertext:   ciphertext,
+	}, nil
+}
+
+func Solve(ctx context.Context, p *Puzzle) ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	b := new(big.Int).Set(p.A)
+	for i := uint64(0); i < p.Squarings; i++ {
+		if i%checkInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		b.Mul(b, b)
+		b.Mod(b, p.N)
+	}
+
+	k := new(big.Int).Sub(p.EncryptedKey, b)
+	k.Mod(k, p.N)
+	if k.BitLen() > 8*chacha20.KeySize {
+		return nil, errors.ErrAuthenticationFailed
+	}
+
+	return open(ctx, k.FillBytes(make([]byte, chacha20.KeySize)), p.Ciphertext)
+}
+
+// validate holds a puzzle to the ranges Create produces, so that a zero or
+// negative modulus cannot reach the squaring loop.
+func (p *Puzzle) validate() error {
+	if p == nil || p.N == nil || p.A == nil || p.EncryptedKey == nil {
+		return errors.ErrInvalidParameters
+	}
+	if p.N.BitLen() <= 8*chacha20.KeySize || p.Squarings == 0 {
+		return errors.ErrInvalidParameters
+	}
+	if p.A.Cmp(two) < 0 || p.A.Cmp(p.N) >= 0 {
+		return errors.Annotate(errors.ErrInvalidParameters, "base out of range: %w")
+	}
+	if p.EncryptedKey.Sign() < 0 || p.EncryptedKey.Cmp(p.N) >= 0 {
+		return errors.Annotate(errors.ErrInvalidParameters, "encrypted key out of range: %w")
+	}
+	return nil
+}
+
+func Calibrate(ctx context.Context, n *big.Int, sample time.Duration) (uint64, error) {
+	if n == nil || n.Sign() <= 0 || sample <= 0 {
+		return 0, errors.ErrInvalidParameters
+	}
+
+	b := big.NewInt(3)
+	var count uint64
+	start := time.Now()
+	for time.Since(start) < sample {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		for i := 0; i < checkInterval; i++ {
+			b.Mul(b, b)
+			b.Mod(b, n)
+		}
+		count += checkInterval
+	}
+
+	return uint64(float64(count) / time.Since(start).Seconds()), nil
+}
+
+func SquaringsFor(rate uint64, delay time.Duration) uint64 {
+	return uint64(float64(rate) * delay.Seconds())
+}
+
+func seal(ctx context.Context, key, message []byte) ([]byte, error) {
+	c, err := aead.New(aead.ChaCha20Poly1305Name, key)
+	if err != nil {
+		return nil, err
+	}
+	return c.Seal(ctx, make([]byte, c.NonceSize()), message, nil)
+}
+
+func open(ctx context.Context, key, ciphertext []byte) ([]byte, error) {
+	c, err := aead.New(aead.ChaCha20Poly1305Name, key)
+	if err != nil {
+		return nil, err
+	}
+	return c.Open(ctx, make([]byte, c.NonceSize()), ciphertext, nil)
+}
@@ -0,0 +1,89 @@
+package timelock
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/masterkusok/crypto/cipher/rsa"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	r := rsa.NewRSA(cryptoMath.NewMillerRabinTest(), 0.99, 256)
+	require.NoError(t, r.GenerateKeyPair())
+	return r.GetPrivateKey()
+}
+
+func TestCreateSolve(t *testing.T) {
+	ctx := context.Background()
+	message := []byte("open after the delay")
+
+	puzzle, err := Create(ctx, newKey(t), message, 5000)
+	require.NoError(t, err)
+
+	solved, err := Solve(ctx, puzzle)
+	require.NoError(t, err)
+	assert.Equal(t, message, solved)
+}
+
+func TestSolveWrongSquarings(t *testing.T) {
+	ctx := context.Background()
+	puzzle, err := Create(ctx, newKey(t), []byte("secret"), 100)
+	require.NoError(t, err)
+
+	puzzle.Squarings--
+	_, err = Solve(ctx, puzzle)
+	assert.ErrorIs(t, err, errors.ErrAuthenticationFailed)
+}
+
+func TestSolveInvalidPuzzle(t *testing.T) {
+	ctx := context.Background()
+	puzzle, err := Create(ctx, newKey(t), []byte("secret"), 100)
+	require.NoError(t, err)
+
+	for name, mutate := range map[string]func(p *Puzzle){
+		"zero modulus":     func(p *Puzzle) { p.N = big.NewInt(0) },
+		"negative modulus": func(p *Puzzle) { p.N = big.NewInt(-7) },
+		"small modulus":    func(p *Puzzle) { p.N = big.NewInt(1 << 20) },
+		"no squarings":     func(p *Puzzle) { p.Squarings = 0 },
+		"trivial base":     func(p *Puzzle) { p.A = big.NewInt(1) },
+		"base too large":   func(p *Puzzle) { p.A = new(big.Int).Set(p.N) },
+		"negative key":     func(p *Puzzle) { p.EncryptedKey = big.NewInt(-1) },
+		"key too large":    func(p *Puzzle) { p.EncryptedKey = new(big.Int).Add(p.N, big.NewInt(1)) },
+		"missing base":     func(p *Puzzle) { p.A = nil },
+	} {
+		invalid := *puzzle
+		mutate(&invalid)
+		_, err := Solve(ctx, &invalid)
+		assert.ErrorIs(t, err, errors.ErrInvalidParameters, name)
+	}
+
+	_, err = Solve(ctx, nil)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
+
+func TestSolveCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	puzzle, err := Create(ctx, newKey(t), []byte("secret"), 1<<40)
+	require.NoError(t, err)
+
+	cancel()
+	_, err = Solve(ctx, puzzle)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCalibrate(t *testing.T) {
+	key := newKey(t)
+	rate, err := Calibrate(context.Background(), key.N, 20*time.Millisecond)
+	require.NoError(t, err)
+	assert.Greater(t, rate, uint64(0))
+	assert.Equal(t, 2*rate, SquaringsFor(rate, 2*time.Second))
+
+	_, err = Calibrate(context.Background(), big.NewInt(0), time.Millisecond)
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}
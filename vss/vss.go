@@ -0,0 +1,138 @@
+package vss
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+)
+
+var one = big.NewInt(1)
+
+type Group struct {
+	P *big.Int
+	Q *big.Int
+	G *big.Int
+}
+
+func NewGroup(params *dh.Parameters) (*Group, error) {
+	if params == nil || params.P == nil || params.G == nil {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	q := new(big.Int).Rsh(params.P, 1)
+	g := new(big.Int).Exp(params.G, big.NewInt(2), params.P)
+	if g.Cmp(one) == 0 {
+		return nil, errors.Annotate(errors.ErrInvalidParameters, "generator has trivial order: %w")
+	}
+
+	return &Group{P: params.P, Q: q, G: g}, nil
+}
+
+func (g *Group) Params() *dh.Parameters {
+	return &dh.Parameters{P: g.P, G: g.G}
+}
+
+func (g *Group) Exp(x *big.Int) *big.Int {
+	return new(big.Int).Exp(g.G, x, g.P)
+}
+
+type Polynomial struct {
+	group  *Group
+	coeffs []*big.Int
+}
+
+func NewPolynomial(group *Group, threshold int, secret *big.Int) (*Polynomial, error) {
+	if threshold < 1 {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	coeffs := make([]*big.Int, threshold)
+	for i := range coeffs {
+		c, err := rand.Int(rand.Reader, group.Q)
+		if err != nil {
+			return nil, errors.Annotate(err, "generating coefficient: %w")
+		}
+		coeffs[i] = c
+	}
+	if secret != nil {
+		coeffs[0] = new(big.Int).Mod(secret, group.Q)
+	}
+
+	return &Polynomial{group: group, coeffs: coeffs}, nil
+}
+
+func (p *Polynomial) Secret() *big.Int {
+	return new(big.Int).Set(p.coeffs[0])
+}
+
+func (p *Polynomial) Evaluate(index int) *big.Int {
+	x := big.NewInt(int64(index))
+	result := new(big.Int)
+	for i := len(p.coeffs) - 1; i >= 0; i-- {
+		result.Mul(result, x)
+		result.Add(result, p.coeffs[i])
+		result.Mod(result, p.group.Q)
+	}
+	return result
+}
+
+func (p *Polynomial) Commitments() []*big.Int {
+	commitments := make([]*big.Int, len(p.coeffs))
+	for i, c := range p.coeffs {
+		commitments[i] = p.group.Exp(c)
+	}
+	return commitments
+}
+
+func VerifyShare(group *Group, index int, share *big.Int, commitments []*big.Int) bool {
+	if share == nil || share.Sign() < 0 || share.Cmp(group.Q) >= 0 {
+		return false
+	}
+	return group.Exp(share).Cmp(ExpectedCommitment(group, index, commitments)) == 0
+}
+
+func ExpectedCommitment(group *Group, index int, commitments []*big.Int) *big.Int {
+	x := big.NewInt(int64(index))
+	power := big.NewInt(1)
+	result := big.NewInt(1)
+
+	for _, c := range commitments {
+		result.Mul(result, new(big.Int).Exp(c, power, group.P))
+		result.Mod(result, group.P)
+		power.Mul(power, x)
+		power.Mod(power, group.Q)
+	}
+	return result
+}
+
+func Recover(group *Group, shares map[int]*big.Int) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.ErrInvalidParameters
+	}
+
+	secret := new(big.Int)
+	for i, share := range shares {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for j := range shares {
+			if i == j {
+				continue
+			}
+			num.Mul(num, big.NewInt(int64(-j)))
+			den.Mul(den, big.NewInt(int64(i-j)))
+		}
+
+		inv := new(big.Int).ModInverse(den.Mod(den, group.Q), group.Q)
+		if inv == nil {
+			return nil, errors.ErrInvalidParameters
+		}
+
+		term := new(big.Int).Mul(share, num)
+		term.Mul(term, inv)
+		secret.Add(secret, term)
+		secret.Mod(secret, group.Q)
+	}
+
+	return secret, nil
+}
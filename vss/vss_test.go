@@ -0,0 +1,54 @@
+package vss
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/masterkusok/crypto/dh"
+	"github.com/masterkusok/crypto/errors"
+	cryptoMath "github.com/masterkusok/crypto/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGroup(t *testing.T) *Group {
+	params, err := dh.GenerateParameters(128, cryptoMath.NewMillerRabinTest(), 0.99)
+	require.NoError(t, err)
+	group, err := NewGroup(params)
+	require.NoError(t, err)
+	return group
+}
+
+func TestShareAndRecover(t *testing.T) {
+	group := newGroup(t)
+	secret := big.NewInt(424242)
+
+	poly, err := NewPolynomial(group, 3, secret)
+	require.NoError(t, err)
+	commitments := poly.Commitments()
+
+	shares := make(map[int]*big.Int)
+	for i := 1; i <= 5; i++ {
+		shares[i] = poly.Evaluate(i)
+		assert.True(t, VerifyShare(group, i, shares[i], commitments))
+	}
+	assert.False(t, VerifyShare(group, 1, shares[2], commitments))
+
+	subset := map[int]*big.Int{1: shares[1], 3: shares[3], 5: shares[5]}
+	recovered, err := Recover(group, subset)
+	require.NoError(t, err)
+	assert.Equal(t, secret, recovered)
+
+	tooFew := map[int]*big.Int{2: shares[2], 4: shares[4]}
+	recovered, err = Recover(group, tooFew)
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, recovered)
+}
+
+func TestGroupGeneratorHasPrimeOrder(t *testing.T) {
+	group := newGroup(t)
+	assert.Equal(t, 0, new(big.Int).Exp(group.G, group.Q, group.P).Cmp(big.NewInt(1)))
+
+	_, err := NewGroup(&dh.Parameters{P: big.NewInt(23), G: big.NewInt(1)})
+	assert.ErrorIs(t, err, errors.ErrInvalidParameters)
+}